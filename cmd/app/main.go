@@ -2,10 +2,77 @@ package main
 
 import (
 	"blog-backend/internal/app"
+	"errors"
+	"flag"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"os"
 )
 
-const configPath = "config/config.yaml"
+const defaultConfigPath = "config/config.yaml"
+
+const usage = `usage: app [-config PATH] <command> [arguments]
+
+commands:
+  serve [-migrate]         run the server, -migrate applies pending migrations first
+  migrate up|down|status   manage the schema with the migrations built into the binary
+  migrate to N             migrate up or down to version N
+  seed                     fill the database with demo data
+  user create-admin        create an admin user, see "app user" for flags
+  counters reconcile       recompute counters from the source tables, -fix writes them
+
+without a command the server is started`
 
 func main() {
+	flag.Usage = func() { fmt.Fprintln(flag.CommandLine.Output(), usage) }
+	configPath := flag.String("config", defaultConfigPath, "path to the config file")
+	flag.Parse()
+
+	command, args := "serve", flag.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = serve(*configPath, args)
+	case "migrate":
+		err = app.Migrate(*configPath, args)
+	case "seed":
+		err = app.Seed(*configPath)
+	case "user":
+		err = app.User(*configPath, args)
+	case "counters":
+		err = app.Counters(*configPath, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var usageErr app.UsageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintln(os.Stderr, usageErr)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func serve(configPath string, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	migrate := flags.Bool("migrate", false, "apply pending migrations before start")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *migrate {
+		if err := app.Migrate(configPath, []string{"up"}); err != nil {
+			return err
+		}
+	}
+
 	app.Run(configPath)
+	return nil
 }
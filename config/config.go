@@ -1,20 +1,40 @@
 package config
 
 import (
+	"blog-backend/internal/policy"
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"path"
 	"time"
 )
 
+// StorageMemory, StoragePostgres - хранилище репозиториев, в памяти данные теряются при остановке
+const (
+	StorageMemory   = "memory"
+	StoragePostgres = "postgres"
+)
+
 type (
 	Config struct {
-		App    `yaml:"app"`
-		HTTP   `yaml:"http"`
-		Log    `yaml:"log"`
-		PG     `yaml:"postgres"`
-		JWT    `yaml:"jwt"`
-		Hasher `yaml:"hasher"`
+		Storage   string `yaml:"storage" env:"STORAGE" env-default:"postgres"`
+		App       `yaml:"app"`
+		HTTP      `yaml:"http"`
+		Metrics   `yaml:"metrics"`
+		Tracing   `yaml:"tracing"`
+		Health    `yaml:"health"`
+		Log       `yaml:"log"`
+		PG        `yaml:"postgres"`
+		Cache     `yaml:"cache"`
+		JWT       `yaml:"jwt"`
+		Hasher    `yaml:"hasher"`
+		Outbox    `yaml:"outbox"`
+		Webhooks  `yaml:"webhooks"`
+		Retention `yaml:"retention"`
+		Counters  `yaml:"counters"`
+		Account   `yaml:"account"`
+		Policy    `yaml:"policy"`
+		GraphQL   `yaml:"graphql"`
+		GRPC      `yaml:"grpc"`
 	}
 
 	App struct {
@@ -26,13 +46,54 @@ type (
 		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
 	}
 
+	Metrics struct {
+		Port string `env-required:"true" yaml:"port" env:"METRICS_PORT"`
+	}
+
+	Tracing struct {
+		Endpoint    string  `                    yaml:"endpoint"     env:"TRACING_ENDPOINT"`
+		Insecure    bool    `                    yaml:"insecure"     env:"TRACING_INSECURE"`
+		SampleRatio float64 `                    yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	}
+
+	Health struct {
+		Timeout       time.Duration `env-required:"true" yaml:"timeout"        env:"HEALTH_TIMEOUT"`
+		ShutdownDelay time.Duration `                    yaml:"shutdown_delay" env:"HEALTH_SHUTDOWN_DELAY"`
+	}
+
 	Log struct {
 		Level string `env-required:"true" yaml:"level" env:"LOG_LEVEL"`
 	}
 
+	// PG - чтения списков и карточек идут в реплики, если они заданы, после записи пользователь
+	// читает из primary в течение sticky_window. Нулевые значения настроек пула оставляют значения pgx
 	PG struct {
-		MaxPoolSize int    `env-required:"true" yaml:"max_pool_size" env:"PG_MAX_POOL_SIZE"`
-		URL         string `env-required:"true"                      env:"PG_URL"`
+		MaxPoolSize        int           `env-required:"true" yaml:"max_pool_size"        env:"PG_MAX_POOL_SIZE"`
+		URL                string        `                                                env:"PG_URL"` // required for postgres storage
+		ReplicaURLs        []string      `                                                env:"PG_REPLICA_URLS" env-separator:","`
+		ReplicaCheckPeriod time.Duration `                    yaml:"replica_check_period" env:"PG_REPLICA_CHECK_PERIOD"`
+		StickyWindow       time.Duration `                    yaml:"sticky_window"        env:"PG_STICKY_WINDOW"`
+		MinConns           int           `                    yaml:"min_conns"            env:"PG_MIN_CONNS"`
+		MaxConnLifetime    time.Duration `                    yaml:"max_conn_lifetime"    env:"PG_MAX_CONN_LIFETIME"`
+		MaxConnIdleTime    time.Duration `                    yaml:"max_conn_idle_time"   env:"PG_MAX_CONN_IDLE_TIME"`
+		StatementTimeout   time.Duration `                    yaml:"statement_timeout"    env:"PG_STATEMENT_TIMEOUT"`
+		ApplicationName    string        `                    yaml:"application_name"     env:"PG_APPLICATION_NAME"` // app name by default
+		SlowQueryThreshold time.Duration `                    yaml:"slow_query_threshold" env:"PG_SLOW_QUERY_THRESHOLD"`
+		ConnAttempts       int           `                    yaml:"conn_attempts"        env:"PG_CONN_ATTEMPTS"     env-default:"10"`
+		ConnDelay          time.Duration `                    yaml:"conn_delay"           env:"PG_CONN_DELAY"        env-default:"1s"`
+		ConnMaxDelay       time.Duration `                    yaml:"conn_max_delay"       env:"PG_CONN_MAX_DELAY"    env-default:"10s"`
+		SSLMode            string        `                                                env:"PG_SSL_MODE"`
+		SSLRootCert        string        `                                                env:"PG_SSL_ROOT_CERT"`
+		SSLCert            string        `                                                env:"PG_SSL_CERT"`
+		SSLKey             string        `                                                env:"PG_SSL_KEY"`
+	}
+
+	// Cache - без ttl чтения идут мимо кеша, max_age задает Cache-Control публичных GET ответов независимо от ttl
+	Cache struct {
+		TTL      time.Duration `yaml:"ttl"      env:"CACHE_TTL"`
+		LRUSize  int           `yaml:"lru_size" env:"CACHE_LRU_SIZE"`
+		RedisURL string        `                env:"CACHE_REDIS_URL"` // shared by all instances, LRU per process otherwise
+		MaxAge   time.Duration `yaml:"max_age"  env:"CACHE_MAX_AGE"`
 	}
 
 	JWT struct {
@@ -43,6 +104,66 @@ type (
 	Hasher struct {
 		Salt string `env-required:"true" env:"HASHER_SALT"`
 	}
+
+	Outbox struct {
+		PollInterval  time.Duration `env-required:"true" yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+		BatchSize     int           `env-required:"true" yaml:"batch_size"    env:"OUTBOX_BATCH_SIZE"`
+		MaxAttempts   int           `env-required:"true" yaml:"max_attempts"  env:"OUTBOX_MAX_ATTEMPTS"`
+		WebhookURL    string        `                                         env:"OUTBOX_WEBHOOK_URL"`
+		WebhookSecret string        `                                         env:"OUTBOX_WEBHOOK_SECRET"`
+		NATSURL       string        `                                         env:"OUTBOX_NATS_URL"`
+		NATSSubject   string        `                    yaml:"nats_subject"  env:"OUTBOX_NATS_SUBJECT"`
+		KafkaBrokers  []string      `                                         env:"OUTBOX_KAFKA_BROKERS" env-separator:","`
+		KafkaTopic    string        `                    yaml:"kafka_topic"   env:"OUTBOX_KAFKA_TOPIC"`
+	}
+
+	Webhooks struct {
+		PollInterval time.Duration `env-required:"true" yaml:"poll_interval" env:"WEBHOOKS_POLL_INTERVAL"`
+		Workers      int           `env-required:"true" yaml:"workers"       env:"WEBHOOKS_WORKERS"`
+		MaxAttempts  int           `env-required:"true" yaml:"max_attempts"  env:"WEBHOOKS_MAX_ATTEMPTS"`
+		Timeout      time.Duration `env-required:"true" yaml:"timeout"       env:"WEBHOOKS_TIMEOUT"`
+	}
+
+	Retention struct {
+		Period    time.Duration `env-required:"true" yaml:"period"     env:"RETENTION_PERIOD"`
+		Interval  time.Duration `env-required:"true" yaml:"interval"   env:"RETENTION_INTERVAL"`
+		BatchSize int           `env-required:"true" yaml:"batch_size" env:"RETENTION_BATCH_SIZE"`
+	}
+
+	// Counters - без interval сверка счетчиков не запускается, без fix расхождения только попадают в лог
+	Counters struct {
+		Interval time.Duration `yaml:"interval" env:"COUNTERS_INTERVAL"`
+		Fix      bool          `yaml:"fix"      env:"COUNTERS_FIX"`
+	}
+
+	Account struct {
+		DeletionGracePeriod time.Duration `env-required:"true" yaml:"deletion_grace_period" env:"ACCOUNT_DELETION_GRACE_PERIOD"`
+		ExportPollInterval  time.Duration `env-required:"true" yaml:"export_poll_interval"  env:"ACCOUNT_EXPORT_POLL_INTERVAL"`
+		ExportTTL           time.Duration `env-required:"true" yaml:"export_ttl"            env:"ACCOUNT_EXPORT_TTL"`
+	}
+
+	// Policy - правила доступа, без правил используются policy.DefaultRules
+	Policy struct {
+		Rules []policy.Rule `yaml:"rules"`
+	}
+
+	// GraphQL - операции глубже max_depth или сложнее max_complexity отклоняются до выполнения
+	GraphQL struct {
+		MaxDepth      int `yaml:"max_depth"      env:"GRAPHQL_MAX_DEPTH"      env-default:"8"`
+		MaxComplexity int `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" env-default:"1000"`
+	}
+
+	// GRPC - без port сервер не запускается. Сервисы входят по токену или по клиентскому сертификату,
+	// подписанному GRPC_TLS_CLIENT_CA, сертификаты проверяются только поверх TLS.
+	// Токены без TLS передаются открытым текстом, такой сервер запускается только с allow_insecure
+	GRPC struct {
+		Port          string            `yaml:"port"           env:"GRPC_PORT"`
+		ServiceTokens map[string]string `                      env:"GRPC_SERVICE_TOKENS" env-separator:","` // service name to token
+		TLSCert       string            `                      env:"GRPC_TLS_CERT"`
+		TLSKey        string            `                      env:"GRPC_TLS_KEY"`
+		TLSClientCA   string            `                      env:"GRPC_TLS_CLIENT_CA"`
+		AllowInsecure bool              `yaml:"allow_insecure" env:"GRPC_ALLOW_INSECURE"`
+	}
 )
 
 func NewConfig(configPath string) (*Config, error) {
@@ -58,5 +179,41 @@ func NewConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("error updating env: %w", err)
 	}
 
+	switch cfg.Storage {
+	case StoragePostgres:
+		if cfg.PG.URL == "" {
+			return nil, fmt.Errorf("PG_URL is required for %s storage", StoragePostgres)
+		}
+	case StorageMemory:
+	default:
+		return nil, fmt.Errorf("unknown storage %q, expected %s or %s", cfg.Storage, StorageMemory, StoragePostgres)
+	}
+
+	err = cfg.GRPC.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
+
+func (c GRPC) validate() error {
+	if c.Port == "" {
+		return nil
+	}
+
+	if len(c.ServiceTokens) == 0 && c.TLSClientCA == "" {
+		return fmt.Errorf("GRPC_SERVICE_TOKENS or GRPC_TLS_CLIENT_CA is required for grpc server")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("GRPC_TLS_CERT and GRPC_TLS_KEY must be set together")
+	}
+	if c.TLSClientCA != "" && c.TLSCert == "" {
+		return fmt.Errorf("GRPC_TLS_CERT and GRPC_TLS_KEY are required for GRPC_TLS_CLIENT_CA")
+	}
+	if len(c.ServiceTokens) > 0 && c.TLSCert == "" && !c.AllowInsecure {
+		return fmt.Errorf("GRPC_TLS_CERT and GRPC_TLS_KEY are required for GRPC_SERVICE_TOKENS, set GRPC_ALLOW_INSECURE to send tokens in plain text")
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestGRPC_validate(t *testing.T) {
+	tokens := map[string]string{"feed": "token"}
+
+	tests := []struct {
+		name    string
+		cfg     GRPC
+		wantErr bool
+	}{
+		{name: "disabled", cfg: GRPC{ServiceTokens: tokens}},
+		{name: "no credentials", cfg: GRPC{Port: "9000"}, wantErr: true},
+		{name: "tokens over tls", cfg: GRPC{Port: "9000", ServiceTokens: tokens, TLSCert: "cert.pem", TLSKey: "key.pem"}},
+		{name: "tokens without tls", cfg: GRPC{Port: "9000", ServiceTokens: tokens}, wantErr: true},
+		{name: "tokens without tls, insecure allowed", cfg: GRPC{Port: "9000", ServiceTokens: tokens, AllowInsecure: true}},
+		{name: "cert without key", cfg: GRPC{Port: "9000", ServiceTokens: tokens, TLSCert: "cert.pem"}, wantErr: true},
+		{name: "client ca", cfg: GRPC{Port: "9000", TLSCert: "cert.pem", TLSKey: "key.pem", TLSClientCA: "ca.pem"}},
+		{name: "client ca without tls", cfg: GRPC{Port: "9000", TLSClientCA: "ca.pem", AllowInsecure: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,19 +2,33 @@ package app
 
 import (
 	"blog-backend/config"
+	"blog-backend/internal/controller/graphql"
 	v1 "blog-backend/internal/controller/http/v1"
+	"blog-backend/internal/counter"
+	"blog-backend/internal/export"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/outbox"
+	"blog-backend/internal/policy"
 	"blog-backend/internal/repo"
+	"blog-backend/internal/retention"
 	"blog-backend/internal/usecase"
+	"blog-backend/internal/webhook"
+	"blog-backend/pkg/cache"
+	"blog-backend/pkg/grpcserver"
 	"blog-backend/pkg/hasher"
 	"blog-backend/pkg/httpserver"
-	"blog-backend/pkg/postgres"
+	"blog-backend/pkg/tracing"
 	"blog-backend/pkg/validator"
+	"context"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func Run(configPath string) {
@@ -27,39 +41,201 @@ func Run(configPath string) {
 	// Logger
 	SetLogrus(cfg.Log.Level)
 
-	// Repositories
-	log.Info("Initializing postgres...")
-	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.MaxPoolSize))
+	// Metrics
+	m := metrics.New()
+
+	// Tracing
+	log.Info("Initializing tracing...")
+	tracer, err := tracing.New(cfg.App.Name, cfg.App.Version,
+		tracing.Endpoint(cfg.Tracing.Endpoint),
+		tracing.Insecure(cfg.Tracing.Insecure),
+		tracing.SampleRatio(cfg.Tracing.SampleRatio),
+	)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - tracing.New: %w", err))
+	}
+	defer func() {
+		if err := tracer.Shutdown(context.Background()); err != nil {
+			log.Error(fmt.Errorf("app - Run - tracer.Shutdown: %w", err))
+		}
+	}()
+
+	passwordHasher := hasher.NewSHA1Hasher(cfg.Hasher.Salt)
+
+	// Storage
+	log.Infof("Initializing %s storage...", cfg.Storage)
+	store, err := newStorage(context.Background(), cfg, passwordHasher)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - newStorage: %w", err))
+	}
+	defer store.Close()
+
+	if store.pg != nil {
+		for _, node := range store.pg.Nodes() {
+			err = m.Register(metrics.NewPoolCollector(node))
+			if err != nil {
+				log.Fatal(fmt.Errorf("app - Run - m.Register: %w", err))
+			}
+		}
+	}
+
+	// Health checks
+	h, err := newHealth(cfg.Health, store.pg)
 	if err != nil {
-		log.Fatal(fmt.Errorf("app - Run - pgdb.NewUseCases: %w", err))
+		log.Fatal(fmt.Errorf("app - Run - newHealth: %w", err))
 	}
-	defer pg.Close()
 
 	// Repositories
 	log.Info("Initializing repositories...")
-	repositories := repo.NewRepositories(pg)
+	repositories := repo.WithMetrics(store.repos, m)
+
+	if cfg.Cache.TTL > 0 {
+		log.Info("Initializing cache...")
+		c, err := newCache(cfg.Cache)
+		if err != nil {
+			log.Fatal(fmt.Errorf("app - Run - newCache: %w", err))
+		}
+		if closer, ok := c.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		// metrics stay below the cache and measure only the queries that reach the storage
+		repositories = repo.WithCache(repositories, c, cfg.Cache.TTL, m)
+	}
+
+	// Outbox relay
+	log.Info("Initializing outbox relay...")
+	sinks, err := newOutboxSinks(cfg.Outbox)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - newOutboxSinks: %w", err))
+	}
+	relay := outbox.NewRelay(
+		repositories,
+		outbox.PollInterval(cfg.Outbox.PollInterval),
+		outbox.BatchSize(cfg.Outbox.BatchSize),
+		outbox.MaxAttempts(cfg.Outbox.MaxAttempts),
+		outbox.Sinks(sinks...),
+	)
+
+	// Policy
+	log.Info("Initializing policy...")
+	rules := cfg.Policy.Rules
+	if len(rules) == 0 {
+		rules = policy.DefaultRules()
+	}
+	authorizer, err := policy.New(rules)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - policy.New: %w", err))
+	}
 
 	// UseCases dependencies
 	log.Info("Initializing useCases...")
 	deps := usecase.UseCasesDependencies{
 		Repos:    repositories,
-		Hasher:   hasher.NewSHA1Hasher(cfg.Hasher.Salt),
+		Tx:       store.tx,
+		Hasher:   passwordHasher,
+		PubSub:   store.pubSub,
+		Events:   relay,
+		Policy:   authorizer,
+		Metrics:  m,
 		SignKey:  cfg.JWT.SignKey,
 		TokenTTL: cfg.JWT.TokenTTL,
+
+		AccountDeletionGracePeriod: cfg.Account.DeletionGracePeriod,
+	}
+	useCases := usecase.WithTracing(usecase.NewUseCases(deps))
+
+	// relay starts after use cases have subscribed to events
+	relay.Start()
+	defer relay.Close()
+
+	// Webhook deliveries
+	log.Info("Initializing webhook dispatcher...")
+	dispatcher := webhook.NewDispatcher(
+		repositories,
+		webhook.PollInterval(cfg.Webhooks.PollInterval),
+		webhook.Workers(cfg.Webhooks.Workers),
+		webhook.MaxAttempts(cfg.Webhooks.MaxAttempts),
+		webhook.Timeout(cfg.Webhooks.Timeout),
+	)
+	defer dispatcher.Close()
+
+	// Purge of soft deleted content
+	log.Info("Initializing retention purger...")
+	purger := retention.NewPurger(
+		repositories,
+		retention.Period(cfg.Retention.Period),
+		retention.Interval(cfg.Retention.Interval),
+		retention.BatchSize(cfg.Retention.BatchSize),
+	)
+	defer purger.Close()
+
+	// Reconciliation of denormalized counters
+	if cfg.Counters.Interval > 0 {
+		log.Info("Initializing counter reconciler...")
+		reconciler := counter.NewReconciler(
+			repositories,
+			counter.Interval(cfg.Counters.Interval),
+			counter.Fix(cfg.Counters.Fix),
+			counter.Metrics(m),
+		)
+		defer reconciler.Close()
 	}
-	useCases := usecase.NewUseCases(deps)
+
+	// Account data exports
+	log.Info("Initializing exporter...")
+	exporter := export.NewExporter(
+		repositories,
+		repositories,
+		repositories,
+		repositories,
+		export.PollInterval(cfg.Account.ExportPollInterval),
+		export.TTL(cfg.Account.ExportTTL),
+	)
+	defer exporter.Close()
 
 	// Echo handler
 	log.Info("Initializing handlers and routes...")
 	handler := echo.New()
 	// setup handler validator as lib validator
 	handler.Validator = validator.NewCustomValidator()
-	v1.NewRouter(handler, useCases)
+	v1.NewRouter(handler, useCases, authorizer, m, h, cfg.Cache.MaxAge)
+
+	gqlHandler, err := graphql.NewHandler(useCases,
+		graphql.MaxDepth(cfg.GraphQL.MaxDepth),
+		graphql.MaxComplexity(cfg.GraphQL.MaxComplexity),
+	)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - graphql.NewHandler: %w", err))
+	}
+	handler.POST("/graphql", gqlHandler.Serve, v1.NewAuthMiddleware(useCases.Auth, authorizer).Authorize)
 
 	// HTTP server
 	log.Info("Starting http server...")
 	log.Debugf("Server port: %s", cfg.HTTP.Port)
-	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
+	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port), httpserver.OnShutdown(store.pubSub.Close))
+
+	// Metrics server
+	log.Info("Starting metrics server...")
+	log.Debugf("Metrics port: %s", cfg.Metrics.Port)
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", m.Handler())
+	metricsServer := httpserver.New(metricsMux, httpserver.Port(cfg.Metrics.Port))
+
+	// gRPC server
+	var (
+		grpcServer *grpcserver.Server
+		grpcNotify <-chan error // nil channel blocks the select when grpc is off
+	)
+	if cfg.GRPC.Port != "" {
+		log.Info("Starting grpc server...")
+		log.Debugf("GRPC port: %s", cfg.GRPC.Port)
+		grpcServer, err = newGRPCServer(cfg.GRPC, useCases, m, h)
+		if err != nil {
+			log.Fatal(fmt.Errorf("app - Run - newGRPCServer: %w", err))
+		}
+		grpcNotify = grpcServer.Notify()
+	}
 
 	// Waiting signal
 	log.Info("Configuring graceful shutdown...")
@@ -71,12 +247,62 @@ func Run(configPath string) {
 		log.Info("app - Run - signal: " + s.String())
 	case err = <-httpServer.Notify():
 		log.Error(fmt.Errorf("app - Run - httpServer.Notify: %w", err))
+	case err = <-metricsServer.Notify():
+		log.Error(fmt.Errorf("app - Run - metricsServer.Notify: %w", err))
+	case err = <-grpcNotify:
+		log.Error(fmt.Errorf("app - Run - grpcServer.Notify: %w", err))
 	}
 
 	// Graceful shutdown
 	log.Info("Shutting down...")
+	h.Shutdown()
+	time.Sleep(cfg.Health.ShutdownDelay)
+
 	err = httpServer.Shutdown()
 	if err != nil {
 		log.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
+
+	if grpcServer != nil {
+		err = grpcServer.Shutdown()
+		if err != nil {
+			log.Error(fmt.Errorf("app - Run - grpcServer.Shutdown: %w", err))
+		}
+	}
+
+	err = metricsServer.Shutdown()
+	if err != nil {
+		log.Error(fmt.Errorf("app - Run - metricsServer.Shutdown: %w", err))
+	}
+}
+
+func newOutboxSinks(cfg config.Outbox) ([]outbox.Sink, error) {
+	var sinks []outbox.Sink
+
+	if cfg.WebhookURL != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(cfg.WebhookURL, cfg.WebhookSecret, nil))
+	}
+
+	if cfg.NATSURL != "" {
+		sink, err := outbox.NewNATSSink(cfg.NATSURL, cfg.NATSSubject)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if len(cfg.KafkaBrokers) > 0 {
+		sinks = append(sinks, outbox.NewKafkaSink(cfg.KafkaBrokers, cfg.KafkaTopic))
+	}
+
+	return sinks, nil
+}
+
+// newCache - redis, если задан url, иначе LRU в памяти процесса
+func newCache(cfg config.Cache) (cache.Cache, error) {
+	if cfg.RedisURL != "" {
+		return cache.NewRedisURL(cfg.RedisURL)
+	}
+
+	return cache.NewLRU(cfg.LRUSize), nil
 }
@@ -0,0 +1,8 @@
+package app
+
+// UsageError - неверные аргументы команды, текст ошибки - справка по команде
+type UsageError string
+
+func (e UsageError) Error() string {
+	return string(e)
+}
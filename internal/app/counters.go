@@ -0,0 +1,80 @@
+package app
+
+import (
+	"blog-backend/config"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+const countersUsage = `usage: app counters <command>
+
+commands:
+  reconcile [-fix]
+      recompute counters from the source tables and print the drifted ones, -fix writes the recomputed values`
+
+// Counters - команда counters, сверка счетчиков по требованию, например после ручных правок в базе
+func Counters(configPath string, args []string) error {
+	if len(args) == 0 || args[0] != "reconcile" {
+		return UsageError(countersUsage)
+	}
+
+	flags := flag.NewFlagSet("counters reconcile", flag.ContinueOnError)
+	fix := flags.Bool("fix", false, "write the recomputed values")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("counters reconcile: config error: %w", err)
+	}
+
+	// memory storage isn't shared with serve, there is nothing to reconcile
+	if cfg.Storage == config.StorageMemory {
+		return errors.New("counters reconcile: memory storage isn't shared with serve")
+	}
+
+	pg, err := postgres.New(cfg.PG.URL, connOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("counters reconcile: postgres.New: %w", err)
+	}
+	defer pg.Close()
+
+	drift, err := repo.NewRepositories(pg).ReconcileCounters(context.Background(), *fix)
+	if err != nil {
+		return fmt.Errorf("counters reconcile: %w", err)
+	}
+
+	return printCounterDrift(os.Stdout, drift, *fix)
+}
+
+func printCounterDrift(w io.Writer, drift []entity.CounterDrift, fixed bool) error {
+	if len(drift) == 0 {
+		_, err := fmt.Fprintln(w, "all counters match their source tables")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tCOLUMN\tID\tSTORED\tACTUAL")
+	for _, d := range drift {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\n", d.Table, d.Column, d.ID, d.Stored, d.Actual)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	state := "run with -fix to write the actual values"
+	if fixed {
+		state = "fixed"
+	}
+	_, err := fmt.Fprintf(w, "%d drifted counters, %s\n", len(drift), state)
+	return err
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"blog-backend/config"
+	grpcv1 "blog-backend/internal/controller/grpc/v1"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/grpcserver"
+	"blog-backend/pkg/health"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"os"
+)
+
+// newGRPCServer - без сертификата сервер работает без TLS и принимает только токены,
+// конфигурация допускает это только с allow_insecure
+func newGRPCServer(cfg config.GRPC, useCases *usecase.UseCases, m *metrics.Metrics, h *health.Health) (*grpcserver.Server, error) {
+	opts := []grpcserver.Option{
+		grpcserver.Port(cfg.Port),
+		grpcserver.ServerOptions(grpcv1.Interceptors(grpcv1.NewAuthenticator(cfg.ServiceTokens), m)...),
+	}
+
+	if cfg.TLSCert == "" {
+		log.Warn("GRPC server without TLS, service tokens are sent in plain text")
+	} else {
+		tlsConfig, err := grpcTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpcserver.Credentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	return grpcserver.New(func(s *grpc.Server) {
+		grpcv1.NewRouter(s, useCases, h)
+	}, opts...), nil
+}
+
+// grpcTLSConfig - клиентский сертификат не обязателен, чтобы сервисы с токеном подключались без него
+func grpcTLSConfig(cfg config.GRPC) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("tls.LoadX509KeyPair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.TLSClientCA != "" {
+		pem, err := os.ReadFile(cfg.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", cfg.TLSClientCA)
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, nil
+}
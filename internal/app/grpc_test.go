@@ -0,0 +1,270 @@
+package app
+
+import (
+	blogv1 "blog-backend/api/blog/v1"
+	"blog-backend/config"
+	grpcv1 "blog-backend/internal/controller/grpc/v1"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/usecase"
+	"blog-backend/internal/usecase/mocks"
+	"blog-backend/pkg/health"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testServiceToken = "test-token"
+
+// testCert - сертификат и ключ, подписанные ca или самоподписанные, если ca нет
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, name string, ca *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	parent, parentKey := template, key
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		parent, parentKey = ca.cert, ca.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) tls(t *testing.T) tls.Certificate {
+	t.Helper()
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// write - сертификат и ключ в PEM файлах, как их читает grpcTLSConfig
+func (c *testCert) write(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", c.der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", key)
+
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, blockType string, data []byte) {
+	t.Helper()
+
+	err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type grpcTestServer struct {
+	ca       *testCert
+	user     *mocks.MockUser
+	listener *bufconn.Listener
+}
+
+// newGRPCTestServer - сервер с TLS конфигурацией из grpcTLSConfig и токеном testServiceToken
+func newGRPCTestServer(t *testing.T) *grpcTestServer {
+	t.Helper()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "localhost", ca).write(t, dir, "server")
+
+	tlsConfig, err := grpcTLSConfig(config.GRPC{TLSCert: certFile, TLSKey: keyFile, TLSClientCA: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &grpcTestServer{
+		ca:       ca,
+		user:     mocks.NewMockUser(gomock.NewController(t)),
+		listener: bufconn.Listen(1 << 20),
+	}
+
+	auth := grpcv1.NewAuthenticator(map[string]string{"feed": testServiceToken})
+	opts := append(grpcv1.Interceptors(auth, metrics.New()), grpc.Creds(credentials.NewTLS(tlsConfig)))
+	server := grpc.NewServer(opts...)
+	grpcv1.NewRouter(server, &usecase.UseCases{User: s.user}, health.New())
+
+	go func() {
+		_ = server.Serve(s.listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return s
+}
+
+// dial - соединение, проверяющее сервер по ca, с клиентским сертификатом cert, если он есть
+func (s *grpcTestServer) dial(t *testing.T, cert *testCert) *grpc.ClientConn {
+	t.Helper()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(s.ca.cert)
+	tlsConfig := &tls.Config{RootCAs: roots, ServerName: "localhost", MinVersion: tls.VersionTLS12}
+	if cert != nil {
+		// the certificate is sent even if the server does not list its ca as acceptable
+		clientCert := cert.tls(t)
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &clientCert, nil
+		}
+	}
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestGRPCServer_Auth(t *testing.T) {
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	tests := []struct {
+		name        string
+		cert        func(ca *testCert) *testCert
+		ctx         context.Context
+		wantCode    codes.Code
+		wantService string
+	}{
+		{
+			name:        "client certificate",
+			cert:        func(ca *testCert) *testCert { return newTestCert(t, "search", ca) },
+			ctx:         context.Background(),
+			wantService: "search",
+		},
+		{
+			name:     "certificate of another ca",
+			cert:     func(*testCert) *testCert { return newTestCert(t, "search", newTestCert(t, "other-ca", nil)) },
+			ctx:      context.Background(),
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "certificate of another ca with a token",
+			cert:     func(*testCert) *testCert { return newTestCert(t, "search", newTestCert(t, "other-ca", nil)) },
+			ctx:      withToken(testServiceToken),
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:        "token",
+			cert:        func(*testCert) *testCert { return nil },
+			ctx:         withToken(testServiceToken),
+			wantService: "feed",
+		},
+		{
+			name:     "wrong token",
+			cert:     func(*testCert) *testCert { return nil },
+			ctx:      withToken("other-token"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "no credentials",
+			cert:     func(*testCert) *testCert { return nil },
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newGRPCTestServer(t)
+			conn := s.dial(t, tt.cert(s.ca))
+
+			if tt.wantCode == codes.OK {
+				s.user.EXPECT().GetUserByUsername(gomock.Any(), usecase.UserGetUserByUsernameInput{Username: "alice"}).
+					DoAndReturn(func(ctx context.Context, _ usecase.UserGetUserByUsernameInput) (entity.User, error) {
+						if service, _ := grpcv1.ServiceFromContext(ctx); service != tt.wantService {
+							t.Errorf("service = %q, want %q", service, tt.wantService)
+						}
+						return entity.User{Username: "alice"}, nil
+					})
+			}
+
+			ctx, cancel := context.WithTimeout(tt.ctx, 5*time.Second)
+			defer cancel()
+
+			_, err := blogv1.NewUserServiceClient(conn).GetUser(ctx, &blogv1.GetUserRequest{Username: "alice"})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("code = %s, want %s: %v", code, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+// probes connect over tls without a client certificate or a token
+func TestGRPCServer_Health(t *testing.T) {
+	s := newGRPCTestServer(t)
+
+	resp, err := healthpb.NewHealthClient(s.dial(t, nil)).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %s, want SERVING", resp.GetStatus())
+	}
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"blog-backend/config"
+	"blog-backend/migrations"
+	"blog-backend/pkg/health"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+)
+
+// newHealth - readiness проверяет postgres и версию схемы, liveness зависит только от самого процесса.
+// Хранилищу в памяти нечего проверять, pg в этом случае nil
+func newHealth(cfg config.Health, pg *postgres.Postgres) (*health.Health, error) {
+	h := health.New(health.Timeout(cfg.Timeout))
+	if pg == nil {
+		return h, nil
+	}
+
+	expected, err := migrations.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	h.AddReadinessCheck("postgres", pg.Pool.Ping)
+	h.AddReadinessCheck("migrations", migrationsCheck(pg, expected))
+
+	return h, nil
+}
+
+// migrationsCheck - схема не должна отставать от встроенных миграций. Схема новее допустима:
+// при выкатке миграции применяются раньше, чем останавливаются экземпляры старой версии
+func migrationsCheck(pg *postgres.Postgres, expected uint) health.CheckFunc {
+	return func(ctx context.Context) error {
+		var (
+			version int64
+			dirty   bool
+		)
+
+		err := pg.Pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no migrations applied")
+		}
+		if err != nil {
+			return fmt.Errorf("schema_migrations: %w", err)
+		}
+
+		if dirty {
+			return fmt.Errorf("migration %d failed and left the schema dirty", version)
+		}
+		if uint(version) < expected {
+			return fmt.Errorf("schema version %d is behind embedded migrations %d", version, expected)
+		}
+
+		return nil
+	}
+}
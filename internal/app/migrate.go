@@ -1,63 +1,186 @@
-//go:build migrate
-
 package app
 
 import (
+	"blog-backend/config"
+	"blog-backend/migrations"
+	"blog-backend/pkg/postgres"
 	"errors"
-	log "github.com/sirupsen/logrus"
-	"os"
-	"time"
-
+	"fmt"
 	"github.com/golang-migrate/migrate/v4"
 	// migrate tools
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"strconv"
+	"time"
 )
 
-const (
-	defaultAttempts = 20
-	defaultTimeout  = time.Second
-)
+const migrateUsage = `usage: app migrate <command>
+
+commands:
+  up      apply all pending migrations
+  down    roll back the last applied migration
+  status  show the schema version and pending migrations
+  to N    migrate up or down to version N, 0 rolls back everything`
+
+// Migrate - команда migrate, миграции берутся из встроенного в бинарник FS,
+// подключение - из того же конфига, что и у serve, вместе с настройками TLS
+func Migrate(configPath string, args []string) error {
+	var run func(m *migrate.Migrate) error
+
+	switch {
+	case len(args) == 1 && args[0] == "up":
+		run = (*migrate.Migrate).Up
+	case len(args) == 1 && args[0] == "down":
+		run = func(m *migrate.Migrate) error { return m.Steps(-1) }
+	case len(args) == 1 && args[0] == "status":
+		run = migrateStatus
+	case len(args) == 2 && args[0] == "to":
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return UsageError(migrateUsage)
+		}
+		run = func(m *migrate.Migrate) error {
+			// version 0 is not a migration, it means the empty schema
+			if version == 0 {
+				return m.Down()
+			}
+			return m.Migrate(uint(version))
+		}
+	default:
+		return UsageError(migrateUsage)
+	}
+
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("migrate: config error: %w", err)
+	}
+
+	// memory storage has no schema
+	if cfg.Storage == config.StorageMemory {
+		return errors.New("migrate: memory storage has no migrations")
+	}
+
+	m, err := newMigrate(cfg.PG)
+	if err != nil {
+		return err
+	}
+	defer func() { _, _ = m.Close() }()
+
+	err = run(m)
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Info("Migrate: no change")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: %s error: %w", args[0], err)
+	}
 
-func init() {
-	databaseURL, ok := os.LookupEnv("PG_URL")
-	if !ok || len(databaseURL) == 0 {
-		log.Fatalf("migrate: environment variable not declared: PG_URL")
+	if args[0] != "status" {
+		log.Infof("Migrate: %s success", args[0])
 	}
+	return nil
+}
 
-	databaseURL += "?sslmode=disable"
+// newMigrate - база при старте контейнеров может быть еще не готова, поэтому подключение повторяется
+func newMigrate(cfg config.PG) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: iofs.New: %w", err)
+	}
+
+	databaseURLs, err := migrateURLs(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	var (
-		attempts = defaultAttempts
-		err      error
+		attempts = max(cfg.ConnAttempts, 1)
 		m        *migrate.Migrate
 	)
 
 	for attempts > 0 {
-		m, err = migrate.New("file://migrations", databaseURL)
-		if err == nil {
-			break
+		for _, databaseURL := range databaseURLs {
+			m, err = migrate.NewWithSourceInstance("iofs", source, databaseURL)
+			if err == nil {
+				return m, nil
+			}
 		}
 
 		log.Printf("Migrate: pgdb is trying to connect, attempts left: %d", attempts)
-		time.Sleep(defaultTimeout)
+		time.Sleep(cfg.ConnDelay)
 		attempts--
 	}
 
+	return nil, fmt.Errorf("migrate: pgdb connect error: %w", err)
+}
+
+// migrateURLs - строки подключения драйвера migrate с теми же параметрами TLS, что у пулов.
+// Драйвер работает через lib/pq, который не знает prefer и allow, поэтому они, как и sslmode
+// по умолчанию в pgx, заменяются попытками с TLS и без в порядке этих режимов
+func migrateURLs(cfg config.PG) ([]string, error) {
+	databaseURL := postgres.ConnString(cfg.URL, postgres.TLS(cfg.SSLMode, cfg.SSLRootCert, cfg.SSLCert, cfg.SSLKey))
+
+	u, err := url.Parse(databaseURL)
 	if err != nil {
-		log.Fatalf("Migrate: pgdb connect error: %s", err)
+		return nil, fmt.Errorf("migrate: PG_URL must be a url: %w", err)
 	}
 
-	err = m.Up()
-	defer func() { _, _ = m.Close() }()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalf("Migrate: up error: %s", err)
+	var modes []string
+	query := u.Query()
+	switch query.Get("sslmode") {
+	case "", "prefer":
+		modes = []string{"require", "disable"}
+	case "allow":
+		modes = []string{"disable", "require"}
+	default:
+		return []string{databaseURL}, nil
 	}
 
-	if errors.Is(err, migrate.ErrNoChange) {
-		log.Printf("Migrate: no change")
-		return
+	databaseURLs := make([]string, 0, len(modes))
+	for _, mode := range modes {
+		query.Set("sslmode", mode)
+		u.RawQuery = query.Encode()
+		databaseURLs = append(databaseURLs, u.String())
+	}
+
+	return databaseURLs, nil
+}
+
+func migrateStatus(m *migrate.Migrate) error {
+	list, err := migrations.List()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		version, err = 0, nil
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: status error: %w", err)
+	}
+
+	var pending []migrations.Migration
+	for _, migration := range list {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+
+	state := ""
+	if dirty {
+		state = " (dirty, the last migration failed and must be fixed by hand)"
+	}
+	fmt.Printf("current version: %d%s\n", version, state)
+	if len(list) > 0 {
+		fmt.Printf("latest version:  %d\n", list[len(list)-1].Version)
+	}
+	fmt.Printf("pending:         %d\n", len(pending))
+	for _, migration := range pending {
+		fmt.Println("  " + migration.Name)
 	}
 
-	log.Printf("Migrate: up success")
+	return nil
 }
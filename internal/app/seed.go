@@ -0,0 +1,170 @@
+package app
+
+import (
+	"blog-backend/config"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/hasher"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// seedPassword - пароль всех демо-пользователей, проходит правило валидации password
+const seedPassword = "Demo-pass1!"
+
+type seedArticle struct {
+	title       string
+	description string
+	content     string
+	comments    []string
+}
+
+type seedUser struct {
+	name     string
+	username string
+	email    string
+	articles []seedArticle
+}
+
+var seedUsers = []seedUser{
+	{
+		name:     "Alice Walker",
+		username: "alice",
+		email:    "alice@example.com",
+		articles: []seedArticle{
+			{
+				title:       "Getting started with Go",
+				description: "A short tour for backend developers",
+				content:     "Go is a small language with a big standard library. Start with the tour, then write a CLI.",
+				comments:    []string{"Great intro, thanks!", "Could you cover generics next?"},
+			},
+			{
+				title:       "Structuring a clean architecture service",
+				description: "Entities, use cases, repositories and controllers",
+				content:     "Keep the domain free of frameworks and let the outer layers depend on the inner ones.",
+				comments:    []string{"Where do transactions belong in this picture?"},
+			},
+		},
+	},
+	{
+		name:     "Bob Miller",
+		username: "bobby",
+		email:    "bob@example.com",
+		articles: []seedArticle{
+			{
+				title:       "PostgreSQL indexes in practice",
+				description: "When a btree helps and when it does not",
+				content:     "Look at the query plan before adding an index, and at the write load after.",
+				comments:    []string{"EXPLAIN ANALYZE is my best friend."},
+			},
+		},
+	},
+	{
+		name:     "Carol Smith",
+		username: "carol",
+		email:    "carol@example.com",
+	},
+}
+
+// Seed - демо-данные для локальной разработки: пользователи, статьи, комментарии, подписки и избранное.
+// Если демо-пользователи уже есть, ничего не делает
+func Seed(configPath string) error {
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("seed: config error: %w", err)
+	}
+
+	if cfg.Storage == config.StorageMemory {
+		return errors.New("seed: memory storage is seeded by serve on start")
+	}
+
+	pg, err := postgres.New(cfg.PG.URL, connOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("seed: postgres.New: %w", err)
+	}
+	defer pg.Close()
+
+	return seed(context.Background(), repo.NewRepositories(pg), hasher.NewSHA1Hasher(cfg.Hasher.Salt))
+}
+
+func seed(ctx context.Context, repos *repo.Repositories, passwordHasher hasher.PasswordHasher) error {
+	var (
+		userIDs    []uuid.UUID
+		articleIDs []uuid.UUID
+	)
+
+	for _, u := range seedUsers {
+		userID, err := repos.CreateUser(ctx, entity.User{
+			Name:     u.name,
+			Username: u.username,
+			Password: passwordHasher.Hash(seedPassword),
+			Email:    u.email,
+		})
+		if errors.Is(err, repoerrs.ErrUserAlreadyExists) {
+			log.Infof("Seed: user %s already exists, database is already seeded", u.username)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("seed: CreateUser %s: %w", u.username, err)
+		}
+		userIDs = append(userIDs, userID)
+
+		for _, a := range u.articles {
+			articleID, err := repos.CreateArticle(ctx, entity.Article{
+				AuthorID:    userID,
+				Title:       a.title,
+				Description: a.description,
+				Content:     a.content,
+			})
+			if err != nil {
+				return fmt.Errorf("seed: CreateArticle %q: %w", a.title, err)
+			}
+			articleIDs = append(articleIDs, articleID)
+		}
+	}
+
+	// everyone comments, follows and favorites the content of the others
+	article := 0
+	for authorIdx, u := range seedUsers {
+		for _, a := range u.articles {
+			for i, content := range a.comments {
+				commenterID := userIDs[(authorIdx+i+1)%len(userIDs)]
+				_, err := repos.CreateComment(ctx, entity.Comment{
+					AuthorID:  commenterID,
+					ArticleID: articleIDs[article],
+					Content:   content,
+				})
+				if err != nil {
+					return fmt.Errorf("seed: CreateComment: %w", err)
+				}
+			}
+
+			for i, userID := range userIDs {
+				if i == authorIdx {
+					continue
+				}
+				_, err := repos.SetArticleFavorite(ctx, userID, articleIDs[article])
+				if err != nil {
+					return fmt.Errorf("seed: SetArticleFavorite: %w", err)
+				}
+			}
+
+			article++
+		}
+	}
+
+	for i, followerID := range userIDs {
+		_, err := repos.SetUserFollower(ctx, followerID, userIDs[(i+1)%len(userIDs)])
+		if err != nil {
+			return fmt.Errorf("seed: SetUserFollower: %w", err)
+		}
+	}
+
+	log.Infof("Seed: created %d users and %d articles, password of every user: %s", len(userIDs), len(articleIDs), seedPassword)
+	return nil
+}
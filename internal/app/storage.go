@@ -0,0 +1,101 @@
+package app
+
+import (
+	"blog-backend/config"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/memdb"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/hasher"
+	"blog-backend/pkg/postgres"
+	"blog-backend/pkg/pubsub"
+	"context"
+	"fmt"
+)
+
+// storage - репозитории выбранного хранилища и то, что зависит от него
+type storage struct {
+	pg     *postgres.Postgres // nil for memory storage
+	repos  *repo.Repositories
+	tx     usecase.TxManager
+	pubSub *pubsub.PubSub
+}
+
+// newStorage - хранилище в памяти заполняется демо-данными, иначе после старта в нем нельзя даже войти
+func newStorage(ctx context.Context, cfg *config.Config, passwordHasher hasher.PasswordHasher) (*storage, error) {
+	if cfg.Storage == config.StorageMemory {
+		db := memdb.New()
+		repos := repo.NewMemoryRepositories(db)
+
+		err := seed(ctx, repos, passwordHasher)
+		if err != nil {
+			return nil, fmt.Errorf("seed: %w", err)
+		}
+
+		return &storage{
+			repos:  repos,
+			tx:     memdb.NewTxManager(db),
+			pubSub: pubsub.New(nil),
+		}, nil
+	}
+
+	opts := append(connOptions(cfg),
+		postgres.MaxPoolSize(cfg.PG.MaxPoolSize),
+		postgres.MinConns(cfg.PG.MinConns),
+		postgres.MaxConnLifetime(cfg.PG.MaxConnLifetime),
+		postgres.MaxConnIdleTime(cfg.PG.MaxConnIdleTime),
+		postgres.Tracing(),
+		postgres.Replicas(cfg.PG.ReplicaURLs...),
+		postgres.ReplicaCheckPeriod(cfg.PG.ReplicaCheckPeriod),
+		postgres.StickyWindow(cfg.PG.StickyWindow),
+		postgres.StickyKey(subjectKey),
+	)
+	if cfg.PG.SlowQueryThreshold > 0 {
+		opts = append(opts, postgres.SlowQueryThreshold(cfg.PG.SlowQueryThreshold))
+	}
+
+	pg, err := postgres.New(cfg.PG.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres.New: %w", err)
+	}
+
+	return &storage{
+		pg:     pg,
+		repos:  repo.NewRepositories(pg),
+		tx:     postgres.NewTxManager(pg),
+		pubSub: pubsub.New(pg.Pool),
+	}, nil
+}
+
+func (s *storage) Close() {
+	s.pubSub.Close()
+	if s.pg != nil {
+		s.pg.Close()
+	}
+}
+
+// connOptions - как подключаться к postgres, общие для serve и команд
+func connOptions(cfg *config.Config) []postgres.Option {
+	applicationName := cfg.PG.ApplicationName
+	if applicationName == "" {
+		applicationName = cfg.App.Name
+	}
+
+	return []postgres.Option{
+		postgres.ApplicationName(applicationName),
+		postgres.StatementTimeout(cfg.PG.StatementTimeout),
+		postgres.TLS(cfg.PG.SSLMode, cfg.PG.SSLRootCert, cfg.PG.SSLCert, cfg.PG.SSLKey),
+		postgres.ConnAttempts(cfg.PG.ConnAttempts),
+		postgres.ConnTimeout(cfg.PG.ConnDelay),
+		postgres.ConnMaxDelay(cfg.PG.ConnMaxDelay),
+	}
+}
+
+// subjectKey - чтения привязываются к записям пользователя, от имени которого выполняется запрос
+func subjectKey(ctx context.Context) string {
+	subject, ok := policy.SubjectFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return subject.ID.String()
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"blog-backend/config"
+	"blog-backend/internal/audit"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/hasher"
+	"blog-backend/pkg/postgres"
+	"blog-backend/pkg/validator"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"os"
+)
+
+const userUsage = `usage: app user <command>
+
+commands:
+  create-admin -username NAME -email EMAIL [-name FULL_NAME] [-password PASSWORD]
+      create a user with the admin role, the password may be passed in ADMIN_PASSWORD instead`
+
+// createAdminInput - те же правила, что и при регистрации через API
+type createAdminInput struct {
+	Name     string `json:"name" validate:"required,min=4,max=256"`
+	Username string `json:"username" validate:"required,min=4,max=256"`
+	Password string `json:"password" validate:"required,password"`
+	Email    string `json:"email" validate:"required,email"`
+}
+
+// User - команда user. Через API роль администратора может выдать только администратор,
+// поэтому первый администратор создается отсюда
+func User(configPath string, args []string) error {
+	if len(args) == 0 || args[0] != "create-admin" {
+		return UsageError(userUsage)
+	}
+
+	var input createAdminInput
+	flags := flag.NewFlagSet("user create-admin", flag.ContinueOnError)
+	flags.StringVar(&input.Username, "username", "", "username")
+	flags.StringVar(&input.Email, "email", "", "email")
+	flags.StringVar(&input.Name, "name", "", "full name, defaults to the username")
+	flags.StringVar(&input.Password, "password", os.Getenv("ADMIN_PASSWORD"), "password, defaults to ADMIN_PASSWORD")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if input.Name == "" {
+		input.Name = input.Username
+	}
+
+	if err := validator.NewCustomValidator().Validate(input); err != nil {
+		return fmt.Errorf("user create-admin: %w", err)
+	}
+
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("user create-admin: config error: %w", err)
+	}
+
+	// the admin would be lost as soon as the command exits
+	if cfg.Storage == config.StorageMemory {
+		return errors.New("user create-admin: memory storage isn't shared with serve")
+	}
+
+	pg, err := postgres.New(cfg.PG.URL, connOptions(cfg)...)
+	if err != nil {
+		return fmt.Errorf("user create-admin: postgres.New: %w", err)
+	}
+	defer pg.Close()
+
+	return createAdmin(context.Background(), postgres.NewTxManager(pg), repo.NewRepositories(pg), hasher.NewSHA1Hasher(cfg.Hasher.Salt), input)
+}
+
+// createAdmin - пользователь создается с обычной ролью и повышается до администратора с записью в журнале аудита,
+// оба шага в одной транзакции, чтобы не оставить пользователя без роли
+func createAdmin(ctx context.Context, txManager usecase.TxManager, repos *repo.Repositories, passwordHasher hasher.PasswordHasher, input createAdminInput) error {
+	var userID uuid.UUID
+	err := txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		userID, err = repos.CreateUser(ctx, entity.User{
+			Name:     input.Name,
+			Username: input.Username,
+			Password: passwordHasher.Hash(input.Password),
+			Email:    input.Email,
+		})
+		if errors.Is(err, repoerrs.ErrUserAlreadyExists) {
+			return fmt.Errorf("user create-admin: user %s already exists", input.Username)
+		}
+		if err != nil {
+			return fmt.Errorf("user create-admin: CreateUser: %w", err)
+		}
+
+		details := audit.Change(entity.RoleUser, entity.RoleAdmin)
+		details["source"] = "cli"
+
+		err = repos.SetUserRole(ctx, userID, entity.RoleAdmin, audit.NewEntry(ctx, entity.AuditRoleChange, entity.AuditTargetUser, userID, details))
+		if err != nil {
+			return fmt.Errorf("user create-admin: SetUserRole: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("User: admin %s created with id %s", input.Username, userID)
+	return nil
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+)
+
+// Request - данные HTTP-запроса, в котором выполнено действие
+type Request struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type requestKey struct{}
+
+func WithRequest(ctx context.Context, request Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, request)
+}
+
+// RequestFromContext - вне HTTP-запроса (фоновые задачи, CLI) данные запроса пустые
+func RequestFromContext(ctx context.Context) Request {
+	request, _ := ctx.Value(requestKey{}).(Request)
+	return request
+}
+
+// NewEntry - запись о действии субъекта из контекста над целью, uuid.Nil - цели нет.
+// Без субъекта (вход в систему) актор не заполняется, его задает вызывающий
+func NewEntry(
+	ctx context.Context,
+	action entity.AuditAction,
+	targetType entity.AuditTargetType,
+	targetID uuid.UUID,
+	details map[string]interface{},
+) entity.AuditEntry {
+	request := RequestFromContext(ctx)
+
+	entry := entity.AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   uuid.NullUUID{UUID: targetID, Valid: targetID != uuid.Nil},
+		IP:         request.IP,
+		UserAgent:  request.UserAgent,
+		RequestID:  request.RequestID,
+	}
+
+	if subject, ok := policy.SubjectFromContext(ctx); ok {
+		entry.ActorID = uuid.NullUUID{UUID: subject.ID, Valid: true}
+		entry.ImpersonatorID = subject.ImpersonatorID
+	}
+
+	if details != nil {
+		// details are built from plain values and always encode
+		entry.Details, _ = json.Marshal(details)
+	}
+
+	return entry
+}
+
+// Change - значения поля до и после изменения, для details записей об изменениях
+func Change(before, after interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"before": before,
+		"after":  after,
+	}
+}
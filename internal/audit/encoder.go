@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"blog-backend/internal/entity"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format - формат выгрузки журнала
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+var ErrUnknownFormat = fmt.Errorf("unknown audit log format")
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// Encoder - построчная запись журнала, Flush обязателен после последней записи
+type Encoder interface {
+	Encode(entry entity.AuditEntry) error
+	Flush() error
+}
+
+func NewEncoder(w io.Writer, format Format) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w)
+	case FormatJSONL:
+		return &jsonlEncoder{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+var csvHeader = []string{
+	"id", "created_at", "actor_id", "impersonator_id", "action", "target_type", "target_id",
+	"ip", "user_agent", "request_id", "details",
+}
+
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) (*csvEncoder, error) {
+	e := &csvEncoder{writer: csv.NewWriter(w)}
+
+	err := e.writer.Write(csvHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *csvEncoder) Encode(entry entity.AuditEntry) error {
+	details := string(entry.Details)
+	if details == "" {
+		details = "{}"
+	}
+
+	return e.writer.Write([]string{
+		entry.ID.String(),
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		nullUUIDString(entry.ActorID),
+		nullUUIDString(entry.ImpersonatorID),
+		string(entry.Action),
+		string(entry.TargetType),
+		nullUUIDString(entry.TargetID),
+		csvSafe(entry.IP),
+		csvSafe(entry.UserAgent),
+		csvSafe(entry.RequestID),
+		details,
+	})
+}
+
+func (e *csvEncoder) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type jsonlEntry struct {
+	ID             uuid.UUID              `json:"id"`
+	CreatedAt      time.Time              `json:"created_at"`
+	ActorID        uuid.NullUUID          `json:"actor_id"`
+	ImpersonatorID uuid.NullUUID          `json:"impersonator_id"`
+	Action         entity.AuditAction     `json:"action"`
+	TargetType     entity.AuditTargetType `json:"target_type"`
+	TargetID       uuid.NullUUID          `json:"target_id"`
+	IP             string                 `json:"ip"`
+	UserAgent      string                 `json:"user_agent"`
+	RequestID      string                 `json:"request_id"`
+	Details        json.RawMessage        `json:"details"`
+}
+
+type jsonlEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *jsonlEncoder) Encode(entry entity.AuditEntry) error {
+	details := entry.Details
+	if len(details) == 0 {
+		details = json.RawMessage("{}")
+	}
+
+	// json.Encoder ends every value with a newline
+	return e.encoder.Encode(jsonlEntry{
+		ID:             entry.ID,
+		CreatedAt:      entry.CreatedAt.UTC(),
+		ActorID:        entry.ActorID,
+		ImpersonatorID: entry.ImpersonatorID,
+		Action:         entry.Action,
+		TargetType:     entry.TargetType,
+		TargetID:       entry.TargetID,
+		IP:             entry.IP,
+		UserAgent:      entry.UserAgent,
+		RequestID:      entry.RequestID,
+		Details:        details,
+	})
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return nil
+}
+
+func nullUUIDString(id uuid.NullUUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return id.UUID.String()
+}
+
+// csvSafe - значения от клиента не должны выполняться как формулы при открытии выгрузки в табличном редакторе
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
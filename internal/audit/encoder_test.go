@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"blog-backend/internal/entity"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEntry() entity.AuditEntry {
+	return entity.AuditEntry{
+		ID:         uuid.MustParse("6f1c2a9e-4d0b-4c55-9f2d-1b7e5a3c8d01"),
+		ActorID:    uuid.NullUUID{UUID: uuid.MustParse("0b8e4f7a-2c31-4d9e-8a6b-5f0c1d2e3a4b"), Valid: true},
+		Action:     entity.AuditBan,
+		TargetType: entity.AuditTargetUser,
+		TargetID:   uuid.NullUUID{UUID: uuid.MustParse("9a7b6c5d-4e3f-4a1b-8c9d-0e1f2a3b4c5d"), Valid: true},
+		Details:    json.RawMessage(`{"note":"said \"hi\", then left"}`),
+		IP:         "=cmd|'/c calc'!A1",
+		UserAgent:  "agent, with comma\nand newline",
+		RequestID:  "-1+1",
+		CreatedAt:  time.Date(2022, 11, 14, 10, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+	}
+}
+
+func TestEncoder_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := NewEncoder(&buf, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Encode(testEntry()); err != nil {
+		t.Fatal(err)
+	}
+	// failed sign-in: no actor, no details
+	if err := e.Encode(entity.AuditEntry{Action: entity.AuditSignInFailed, TargetType: entity.AuditTargetUser}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// quotes, commas and newlines survive a round trip through a csv reader
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || !reflect.DeepEqual(records[0], csvHeader) {
+		t.Fatalf("records = %v, want the header and 2 entries", records)
+	}
+
+	want := []string{
+		"6f1c2a9e-4d0b-4c55-9f2d-1b7e5a3c8d01",
+		"2022-11-14T07:00:00Z",
+		"0b8e4f7a-2c31-4d9e-8a6b-5f0c1d2e3a4b",
+		"",
+		string(entity.AuditBan),
+		string(entity.AuditTargetUser),
+		"9a7b6c5d-4e3f-4a1b-8c9d-0e1f2a3b4c5d",
+		"'=cmd|'/c calc'!A1",
+		"agent, with comma\nand newline",
+		"'-1+1",
+		`{"note":"said \"hi\", then left"}`,
+	}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Errorf("record = %q, want %q", records[1], want)
+	}
+
+	if got := records[2]; got[2] != "" || got[6] != "" || got[10] != "{}" {
+		t.Errorf("empty values = %q, %q, %q, want empty ids and {}", got[2], got[6], got[10])
+	}
+}
+
+func TestEncoder_JSONL(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := NewEncoder(&buf, FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Encode(testEntry()); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(entity.AuditEntry{Action: entity.AuditSignInFailed}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// one entry per line, newlines inside values are escaped
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	// json is not interpreted by spreadsheets, values are kept as is
+	if got["ip"] != "=cmd|'/c calc'!A1" || got["user_agent"] != "agent, with comma\nand newline" {
+		t.Errorf("entry = %v", got)
+	}
+	if got["created_at"] != "2022-11-14T07:00:00Z" || got["impersonator_id"] != nil {
+		t.Errorf("created_at = %v, impersonator_id = %v", got["created_at"], got["impersonator_id"])
+	}
+	if details, ok := got["details"].(map[string]interface{}); !ok || details["note"] != `said "hi", then left` {
+		t.Errorf("details = %v, want an embedded object", got["details"])
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if details, ok := got["details"].(map[string]interface{}); !ok || len(details) != 0 || got["actor_id"] != nil {
+		t.Errorf("entry without details = %v, want {} and a null actor", got)
+	}
+}
+
+func TestNewEncoder_UnknownFormat(t *testing.T) {
+	_, err := NewEncoder(&bytes.Buffer{}, "xml")
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("err = %v, want %v", err, ErrUnknownFormat)
+	}
+}
@@ -0,0 +1,197 @@
+package graphql
+
+import (
+	"blog-backend/internal/policy"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/apperror"
+	"context"
+	"encoding/json"
+	"fmt"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mime"
+	"net/http"
+)
+
+const (
+	defaultMaxDepth      = 8
+	defaultMaxComplexity = 1000
+
+	maxRequestBytes = 1 << 20
+)
+
+var (
+	errUnsupportedMediaType = apperror.New("unsupported_media_type", http.StatusUnsupportedMediaType, "content type must be application/json")
+	errInvalidRequest       = apperror.New("invalid_request", http.StatusBadRequest, "invalid request")
+	errQueryTooDeep         = apperror.New("query_too_deep", http.StatusBadRequest, "query is too deep")
+	errQueryTooComplex      = apperror.New("query_too_complex", http.StatusBadRequest, "query is too complex")
+)
+
+// Handler - POST /graphql поверх тех же use cases, что и REST. Связанные сущности (авторы, теги, избранное)
+// загружаются пакетно на каждый уровень запроса, слишком глубокие и сложные операции не выполняются
+type Handler struct {
+	useCases      *usecase.UseCases
+	schema        gql.Schema
+	maxDepth      int
+	maxComplexity int
+}
+
+func NewHandler(useCases *usecase.UseCases, opts ...Option) (*Handler, error) {
+	h := &Handler{
+		useCases:      useCases,
+		maxDepth:      defaultMaxDepth,
+		maxComplexity: defaultMaxComplexity,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	schema, err := newSchema(useCases)
+	if err != nil {
+		return nil, fmt.Errorf("graphql - NewHandler - newSchema: %w", err)
+	}
+	h.schema = schema
+
+	return h, nil
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Serve - принимается только application/json: такой запрос браузер не отправит с чужого сайта без preflight,
+// поэтому cookie с токеном не позволяет выполнить мутацию из чужой формы. Ошибки самого HTTP запроса
+// отдаются как в REST, ошибки операции - в поле errors ответа со статусом 200
+func (h *Handler) Serve(c echo.Context) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != echo.MIMEApplicationJSON {
+		return errUnsupportedMediaType
+	}
+
+	var req request
+	err := json.NewDecoder(http.MaxBytesReader(c.Response(), c.Request().Body, maxRequestBytes)).Decode(&req)
+	if err != nil {
+		return errInvalidRequest.Wrap(err)
+	}
+
+	return c.JSON(http.StatusOK, h.execute(c.Request().Context(), req))
+}
+
+func (h *Handler) execute(ctx context.Context, req request) *gql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return &gql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := gql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		return &gql.Result{Errors: validation.Errors}
+	}
+
+	// an unknown operation is reported by the executor
+	if operation := findOperation(doc, req.OperationName); operation != nil {
+		name := req.OperationName
+		if name == "" && operation.Name != nil {
+			name = operation.Name.Value
+		}
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.String("graphql.operation.type", operation.Operation),
+			attribute.String("graphql.operation.name", name),
+		)
+
+		depth, complexity := measure(&h.schema, doc, operation, req.Variables)
+		if depth > h.maxDepth {
+			return errorResult(errQueryTooDeep.WithDetails(map[string]interface{}{"depth": depth, "max_depth": h.maxDepth}))
+		}
+		if complexity > h.maxComplexity {
+			return errorResult(errQueryTooComplex.WithDetails(map[string]interface{}{"complexity": complexity, "max_complexity": h.maxComplexity}))
+		}
+	}
+
+	// without a subject the viewer has no favorites, the resolvers that need it reject the request
+	subject, _ := policy.SubjectFromContext(ctx)
+
+	result := gql.Execute(gql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, newLoaders(h.useCases, subject.ID)),
+	})
+
+	for i, formatted := range result.Errors {
+		if appErr, ok := appErrorOf(formatted); ok {
+			formatted.Message = appErr.Message
+			formatted.Extensions = extensions(appErr)
+			result.Errors[i] = formatted
+		}
+	}
+
+	return result
+}
+
+// findOperation - операция по имени, без имени - единственная операция документа
+func findOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+			continue
+		}
+		if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
+
+// appErrorOf - ошибка резолвера оборачивается graphql-go в свои ошибки, отложенные значения - дважды
+func appErrorOf(err error) (*apperror.Error, bool) {
+	for err != nil {
+		switch e := err.(type) {
+		case *apperror.Error:
+			return e, true
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func errorResult(err *apperror.Error) *gql.Result {
+	return &gql.Result{Errors: []gqlerrors.FormattedError{{
+		Message:    err.Message,
+		Locations:  []location.SourceLocation{},
+		Extensions: extensions(err),
+	}}}
+}
+
+// extensions - код ошибки как в REST и ее детали
+func extensions(err *apperror.Error) map[string]interface{} {
+	ext := map[string]interface{}{"code": err.Code}
+	for key, value := range err.Details {
+		ext[key] = value
+	}
+	return ext
+}
@@ -0,0 +1,218 @@
+package graphql
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/usecase"
+	"blog-backend/internal/usecase/mocks"
+	"blog-backend/pkg/apperror"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type testDeps struct {
+	user    *mocks.MockUser
+	article *mocks.MockArticle
+	comment *mocks.MockComment
+}
+
+func newTestHandler(t *testing.T, opts ...Option) (*Handler, testDeps) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	d := testDeps{
+		user:    mocks.NewMockUser(ctrl),
+		article: mocks.NewMockArticle(ctrl),
+		comment: mocks.NewMockComment(ctrl),
+	}
+
+	h, err := NewHandler(&usecase.UseCases{User: d.user, Article: d.article, Comment: d.comment}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h, d
+}
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func serve(t *testing.T, h *Handler, viewerID uuid.UUID, body string) response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	req = req.WithContext(policy.WithSubject(req.Context(), policy.Subject{ID: viewerID, Role: entity.RoleUser}))
+	rec := httptest.NewRecorder()
+
+	err := h.Serve(echo.New().NewContext(req, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp response
+	err = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func query(q string, variables map[string]interface{}) string {
+	body, _ := json.Marshal(map[string]interface{}{"query": q, "variables": variables})
+	return string(body)
+}
+
+func sortedIDs(ids []uuid.UUID) []uuid.UUID {
+	sorted := append([]uuid.UUID(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].String() < sorted[j].String() })
+	return sorted
+}
+
+func TestHandler_BatchesNestedFields(t *testing.T) {
+	h, d := newTestHandler(t)
+	viewerID := uuid.New()
+
+	alice := entity.User{ID: uuid.New(), Username: "alice"}
+	bob := entity.User{ID: uuid.New(), Username: "bob"}
+	articles := []entity.Article{
+		{Id: uuid.New(), AuthorID: alice.ID, Title: "first"},
+		{Id: uuid.New(), AuthorID: bob.ID, Title: "second"},
+		{Id: uuid.New(), AuthorID: alice.ID, Title: "third"},
+	}
+	tag := entity.Tag{Id: uuid.New(), Description: "go"}
+
+	d.article.EXPECT().GetNewestArticles(gomock.Any(), usecase.ArticleGetNewestArticlesInput{Limit: 3, Offset: 0}).Return(articles, nil)
+	d.user.EXPECT().GetUsersByIDs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input usecase.UserGetUsersByIDsInput) ([]entity.User, error) {
+			if got, want := sortedIDs(input.IDs), sortedIDs([]uuid.UUID{alice.ID, bob.ID}); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+				t.Errorf("user ids = %v, want %v", got, want)
+			}
+			return []entity.User{alice, bob}, nil
+		})
+	d.article.EXPECT().GetArticlesTags(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input usecase.ArticleGetArticlesTagsInput) (map[uuid.UUID][]entity.Tag, error) {
+			if len(input.ArticleIDs) != len(articles) {
+				t.Errorf("tags of %d articles requested, want %d", len(input.ArticleIDs), len(articles))
+			}
+			return map[uuid.UUID][]entity.Tag{articles[0].Id: {tag}}, nil
+		})
+	d.article.EXPECT().GetFavoritedArticleIDs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input usecase.ArticleGetFavoritedArticleIDsInput) ([]uuid.UUID, error) {
+			if input.UserID != viewerID || len(input.ArticleIDs) != len(articles) {
+				t.Errorf("favorites input = %+v", input)
+			}
+			return []uuid.UUID{articles[1].Id}, nil
+		})
+
+	resp := serve(t, h, viewerID, query(`query Newest($limit: Int) {
+		articles(limit: $limit) { title author { username } tags { description } favorited }
+	}`, map[string]interface{}{"limit": 3}))
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+
+	got, _ := json.Marshal(resp.Data["articles"])
+	want := `[` +
+		`{"author":{"username":"alice"},"favorited":false,"tags":[{"description":"go"}],"title":"first"},` +
+		`{"author":{"username":"bob"},"favorited":true,"tags":[],"title":"second"},` +
+		`{"author":{"username":"alice"},"favorited":false,"tags":[],"title":"third"}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("articles = %s, want %s", got, want)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	h, d := newTestHandler(t)
+	viewerID := uuid.New()
+
+	d.user.EXPECT().GetUserByUsername(gomock.Any(), usecase.UserGetUserByUsernameInput{Username: "nobody"}).Return(entity.User{}, usecase.ErrUserNotFound)
+	resp := serve(t, h, viewerID, query(`{ user(username: "nobody") { username } }`, nil))
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != usecase.ErrUserNotFound.Code {
+		t.Errorf("errors = %+v, want %s", resp.Errors, usecase.ErrUserNotFound.Code)
+	}
+
+	// internal errors are logged, the client gets no details
+	d.article.EXPECT().GetNewestArticles(gomock.Any(), gomock.Any()).Return([]entity.Article{{Id: uuid.New()}}, nil)
+	d.user.EXPECT().GetUsersByIDs(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection reset"))
+	resp = serve(t, h, viewerID, query(`{ articles { author { username } } }`, nil))
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != apperror.Internal.Message || resp.Errors[0].Extensions["code"] != apperror.Internal.Code {
+		t.Errorf("errors = %+v, want %s", resp.Errors, apperror.Internal.Code)
+	}
+
+	resp = serve(t, h, viewerID, query(`{ articles(limit: 500) { title } }`, nil))
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != errInvalidArgument.Code || resp.Errors[0].Extensions["argument"] != "limit" {
+		t.Errorf("errors = %+v, want %s of limit", resp.Errors, errInvalidArgument.Code)
+	}
+}
+
+func TestHandler_Limits(t *testing.T) {
+	h, _ := newTestHandler(t, MaxDepth(3), MaxComplexity(100))
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{
+			name:  "too deep",
+			query: `{ articles(limit: 1) { author { articles { author { username } } } } }`,
+			code:  errQueryTooDeep.Code,
+		},
+		{
+			name:  "too deep through a fragment",
+			query: `{ articles(limit: 1) { ...A } } fragment A on Article { author { articles { title } } }`,
+			code:  errQueryTooDeep.Code,
+		},
+		{
+			name:  "too complex",
+			query: `{ articles(limit: 50) { title author { username } } }`,
+			code:  errQueryTooComplex.Code,
+		},
+		{
+			name:  "invalid",
+			query: `{ articles { unknown } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := serve(t, h, uuid.New(), query(tt.query, nil))
+			if resp.Data != nil || len(resp.Errors) == 0 {
+				t.Fatalf("response = %+v, want errors only", resp)
+			}
+			if tt.code != "" && resp.Errors[0].Extensions["code"] != tt.code {
+				t.Errorf("code = %v, want %s", resp.Errors[0].Extensions["code"], tt.code)
+			}
+		})
+	}
+}
+
+func TestHandler_RequiresJSON(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`query=%7B%20me%20%7B%20id%20%7D%20%7D`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+
+	err := h.Serve(echo.New().NewContext(req, httptest.NewRecorder()))
+	if err != errUnsupportedMediaType {
+		t.Errorf("err = %v, want %v", err, errUnsupportedMediaType)
+	}
+}
@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"encoding/json"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"strconv"
+	"strings"
+)
+
+// defaultListSize - оценка длины списков без аргумента limit, например тегов статьи
+const defaultListSize = 10
+
+// measurer - глубина и сложность операции по документу, до выполнения запроса.
+// Каждое поле стоит 1, поля внутри списка умножаются на его limit. Поля интроспекции не считаются:
+// их объем ограничен самой схемой
+type measurer struct {
+	schema    *gql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// measure - документ должен пройти валидацию, в том числе на циклы фрагментов
+func measure(schema *gql.Schema, doc *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}) (depth, complexity int) {
+	m := &measurer{
+		schema:    schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	var root gql.Type = schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	return m.selectionSet(root, operation.SelectionSet)
+}
+
+func (m *measurer) selectionSet(parent gql.Type, set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var d, c int
+		switch s := selection.(type) {
+		case *ast.Field:
+			d, c = m.field(parent, s)
+		case *ast.InlineFragment:
+			d, c = m.selectionSet(m.typeCondition(parent, s.TypeCondition), s.SelectionSet)
+		case *ast.FragmentSpread:
+			fragment, ok := m.fragments[s.Name.Value]
+			if !ok {
+				continue
+			}
+			d, c = m.selectionSet(m.typeCondition(parent, fragment.TypeCondition), fragment.SelectionSet)
+		}
+
+		depth = max(depth, d)
+		complexity += c
+	}
+
+	return depth, complexity
+}
+
+func (m *measurer) field(parent gql.Type, field *ast.Field) (depth, complexity int) {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return 0, 0
+	}
+
+	object, ok := parent.(*gql.Object)
+	if !ok {
+		return 1, 1
+	}
+	definition, ok := object.Fields()[name]
+	if !ok {
+		return 1, 1
+	}
+
+	typ, list := unwrapType(definition.Type)
+	depth, complexity = m.selectionSet(typ, field.SelectionSet)
+	if list {
+		complexity *= m.listSize(definition, field)
+	}
+
+	return depth + 1, complexity + 1
+}
+
+// listSize - limit из запроса или переменной, без него - limit по умолчанию, если поле его принимает
+func (m *measurer) listSize(definition *gql.FieldDefinition, field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if limit, err := strconv.Atoi(value.Value); err == nil {
+				return clampLimit(limit)
+			}
+		case *ast.Variable:
+			switch limit := m.variables[value.Name.Value].(type) {
+			case float64:
+				return clampLimit(int(limit))
+			case json.Number:
+				if limit, err := limit.Int64(); err == nil {
+					return clampLimit(int(limit))
+				}
+			}
+		}
+	}
+
+	for _, arg := range definition.Args {
+		if arg.Name() == "limit" {
+			return defaultLimit
+		}
+	}
+	return defaultListSize
+}
+
+func (m *measurer) typeCondition(parent gql.Type, condition *ast.Named) gql.Type {
+	if condition == nil || condition.Name == nil {
+		return parent
+	}
+	if typ := m.schema.Type(condition.Name.Value); typ != nil {
+		return typ
+	}
+	return parent
+}
+
+// unwrapType - именованный тип поля и признак списка
+func unwrapType(typ gql.Type) (gql.Type, bool) {
+	list := false
+	for {
+		switch t := typ.(type) {
+		case *gql.NonNull:
+			typ = t.OfType
+		case *gql.List:
+			list = true
+			typ = t.OfType
+		default:
+			return typ, list
+		}
+	}
+}
+
+// clampLimit - limit вне допустимого диапазона отклонит резолвер, в оценке он не должен ее обнулять
+func clampLimit(limit int) int {
+	return min(max(limit, 1), maxLimit)
+}
@@ -0,0 +1,80 @@
+package graphql
+
+import (
+	"blog-backend/internal/usecase"
+	"github.com/graphql-go/graphql/language/parser"
+	"testing"
+)
+
+func TestMeasure(t *testing.T) {
+	schema, err := newSchema(&usecase.UseCases{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		variables  map[string]interface{}
+		depth      int
+		complexity int
+	}{
+		{
+			name:       "scalar fields",
+			query:      `{ me { id username } }`,
+			depth:      2,
+			complexity: 3,
+		},
+		{
+			name:       "list with limit",
+			query:      `{ articles(limit: 5) { title author { username } } }`,
+			depth:      3,
+			complexity: 1 + 5*(1+2),
+		},
+		{
+			name:       "limit from a variable",
+			query:      `query($n: Int) { feed(limit: $n) { title } }`,
+			variables:  map[string]interface{}{"n": float64(7)},
+			depth:      2,
+			complexity: 1 + 7,
+		},
+		{
+			name:       "default limit and list without limit",
+			query:      `{ search(query: "go") { tags { id } } }`,
+			depth:      3,
+			complexity: 1 + defaultLimit*(1+defaultListSize),
+		},
+		{
+			name:       "limit above the maximum",
+			query:      `{ articles(limit: 100000) { id } }`,
+			depth:      2,
+			complexity: 1 + maxLimit,
+		},
+		{
+			name:       "fragments",
+			query:      `{ article(id: "x") { ...F ... on Article { content } } } fragment F on Article { title comments(limit: 2) { id } }`,
+			depth:      3,
+			complexity: 1 + 1 + (1 + 2) + 1,
+		},
+		{
+			name:       "introspection is free",
+			query:      `{ __schema { types { name fields { name } } } me { id } }`,
+			depth:      2,
+			complexity: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			depth, complexity := measure(&schema, doc, findOperation(doc, ""), tt.variables)
+			if depth != tt.depth || complexity != tt.complexity {
+				t.Errorf("depth, complexity = %d, %d, want %d, %d", depth, complexity, tt.depth, tt.complexity)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/usecase"
+	"context"
+	"github.com/google/uuid"
+	"sync"
+)
+
+// loader - ключи, запрошенные резолверами одного уровня запроса, загружаются одним вызовом.
+// Резолвер регистрирует ключ и возвращает отложенное значение, graphql-go вычисляет отложенные значения
+// уровня после того, как пройдены все поля уровня, поэтому первое чтение забирает ключи всех соседей
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:  fetch,
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// load - значение отсутствующего ключа нулевое, ключи кешируются до конца запроса
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	_, loaded := l.values[key]
+	_, failed := l.errs[key]
+	if !loaded && !failed {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			l.flush(ctx)
+		}
+
+		return l.values[key], l.errs[key]
+	}
+}
+
+func (l *loader[K, V]) flush(ctx context.Context) {
+	keys := make([]K, 0, len(l.pending))
+	seen := make(map[K]struct{}, len(l.pending))
+	for _, key := range l.pending {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	l.pending = nil
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.values[key] = values[key]
+	}
+}
+
+// loaders - на каждый запрос свои, чтобы данные одного пользователя не попадали в ответы другим
+type loaders struct {
+	users     *loader[uuid.UUID, *entity.User]
+	tags      *loader[uuid.UUID, []entity.Tag]
+	favorited *loader[uuid.UUID, bool]
+}
+
+func newLoaders(useCases *usecase.UseCases, viewerID uuid.UUID) *loaders {
+	return &loaders{
+		users: newLoader(func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*entity.User, error) {
+			users, err := useCases.User.GetUsersByIDs(ctx, usecase.UserGetUsersByIDsInput{IDs: ids})
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[uuid.UUID]*entity.User, len(users))
+			for i := range users {
+				byID[users[i].ID] = &users[i]
+			}
+			return byID, nil
+		}),
+		tags: newLoader(func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]entity.Tag, error) {
+			return useCases.Article.GetArticlesTags(ctx, usecase.ArticleGetArticlesTagsInput{ArticleIDs: ids})
+		}),
+		favorited: newLoader(func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+			favorited, err := useCases.Article.GetFavoritedArticleIDs(ctx, usecase.ArticleGetFavoritedArticleIDsInput{
+				UserID:     viewerID,
+				ArticleIDs: ids,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[uuid.UUID]bool, len(favorited))
+			for _, id := range favorited {
+				byID[id] = true
+			}
+			return byID, nil
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,15 @@
+package graphql
+
+type Option func(*Handler)
+
+func MaxDepth(depth int) Option {
+	return func(h *Handler) {
+		h.maxDepth = depth
+	}
+}
+
+func MaxComplexity(complexity int) Option {
+	return func(h *Handler) {
+		h.maxComplexity = complexity
+	}
+}
@@ -0,0 +1,532 @@
+package graphql
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/logger"
+	"context"
+	"github.com/google/uuid"
+	gql "github.com/graphql-go/graphql"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+
+	maxTitleLength = 256
+)
+
+var (
+	errInvalidArgument = apperror.New("invalid_argument", http.StatusBadRequest, "invalid argument")
+	errForbidden       = apperror.New("forbidden", http.StatusForbidden, "forbidden")
+)
+
+// resolver - резолверы схемы, поля сущностей без своего резолвера читаются из полей структур по имени
+type resolver struct {
+	useCases *usecase.UseCases
+}
+
+func newSchema(useCases *usecase.UseCases) (gql.Schema, error) {
+	r := &resolver{useCases: useCases}
+
+	tagType := gql.NewObject(gql.ObjectConfig{
+		Name: "Tag",
+		Fields: gql.Fields{
+			"id":          &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"description": &gql.Field{Type: gql.NewNonNull(gql.String)},
+		},
+	})
+
+	// user and article refer to each other, their fields are built on first use
+	var userType, articleType *gql.Object
+
+	userType = gql.NewObject(gql.ObjectConfig{
+		Name: "User",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":             &gql.Field{Type: gql.NewNonNull(gql.ID)},
+				"username":       &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"name":           &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"email":          &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"role":           &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"description":    &gql.Field{Type: gql.NewNonNull(gql.String)},
+				"createdAt":      &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+				"articlesCount":  &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"commentsCount":  &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"followersCount": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"followingCount": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+				"articles": &gql.Field{
+					Type:    gql.NewNonNull(gql.NewList(gql.NewNonNull(articleType))),
+					Resolve: resolve(r.userArticles),
+				},
+			}
+		}),
+	})
+
+	commentType := gql.NewObject(gql.ObjectConfig{
+		Name: "Comment",
+		Fields: gql.Fields{
+			"id":             &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"articleId":      &gql.Field{Type: gql.NewNonNull(gql.ID), Resolve: resolve(commentArticleID)},
+			"parentId":       &gql.Field{Type: gql.ID, Resolve: resolve(commentParentID)},
+			"content":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"createdAt":      &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+			"updatedAt":      &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+			"votesUpCount":   &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"votesDownCount": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"author":         &gql.Field{Type: userType, Resolve: resolve(commentAuthor)},
+		},
+	})
+
+	articleType = gql.NewObject(gql.ObjectConfig{
+		Name: "Article",
+		Fields: gql.Fields{
+			"id":             &gql.Field{Type: gql.NewNonNull(gql.ID)},
+			"title":          &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"description":    &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"content":        &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"createdAt":      &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+			"updatedAt":      &gql.Field{Type: gql.NewNonNull(gql.DateTime)},
+			"viewsCount":     &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"commentsCount":  &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"favoritesCount": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"votesUpCount":   &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			"votesDownCount": &gql.Field{Type: gql.NewNonNull(gql.Int)},
+			// author is null for an author deleted after the article was read
+			"author": &gql.Field{Type: userType, Resolve: resolve(articleAuthor)},
+			"tags": &gql.Field{
+				Type:    gql.NewNonNull(gql.NewList(gql.NewNonNull(tagType))),
+				Resolve: resolve(articleTags),
+			},
+			"favorited": &gql.Field{Type: gql.NewNonNull(gql.Boolean), Resolve: resolve(articleFavorited)},
+			"comments": &gql.Field{
+				Type:    gql.NewNonNull(gql.NewList(gql.NewNonNull(commentType))),
+				Args:    pageArgs(nil),
+				Resolve: resolve(r.articleComments),
+			},
+		},
+	})
+
+	articleList := gql.NewNonNull(gql.NewList(gql.NewNonNull(articleType)))
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"me": &gql.Field{Type: userType, Resolve: resolve(me)},
+			"user": &gql.Field{
+				Type:    gql.NewNonNull(userType),
+				Args:    gql.FieldConfigArgument{"username": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+				Resolve: resolve(r.user),
+			},
+			"article": &gql.Field{
+				Type:    gql.NewNonNull(articleType),
+				Args:    gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+				Resolve: resolve(r.article),
+			},
+			"articles": &gql.Field{Type: articleList, Args: pageArgs(nil), Resolve: resolve(r.articles)},
+			"feed":     &gql.Field{Type: articleList, Args: pageArgs(nil), Resolve: resolve(r.feed)},
+			"search": &gql.Field{
+				Type:    articleList,
+				Args:    pageArgs(gql.FieldConfigArgument{"query": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}}),
+				Resolve: resolve(r.search),
+			},
+			"comments": &gql.Field{
+				Type:    gql.NewNonNull(gql.NewList(gql.NewNonNull(commentType))),
+				Args:    pageArgs(gql.FieldConfigArgument{"articleId": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}}),
+				Resolve: resolve(r.comments),
+			},
+		},
+	})
+
+	articleIDArgs := gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}}
+
+	mutation := gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createArticle": &gql.Field{
+				Type: gql.NewNonNull(articleType),
+				Args: gql.FieldConfigArgument{
+					"title":       &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"description": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"content":     &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: resolve(r.createArticle),
+			},
+			"updateArticle": &gql.Field{
+				Type: gql.NewNonNull(articleType),
+				Args: gql.FieldConfigArgument{
+					"id":          &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"title":       &gql.ArgumentConfig{Type: gql.String},
+					"description": &gql.ArgumentConfig{Type: gql.String},
+					"content":     &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: resolve(r.updateArticle),
+			},
+			"favoriteArticle":   &gql.Field{Type: gql.NewNonNull(articleType), Args: articleIDArgs, Resolve: resolve(r.favoriteArticle)},
+			"unfavoriteArticle": &gql.Field{Type: gql.NewNonNull(articleType), Args: articleIDArgs, Resolve: resolve(r.unfavoriteArticle)},
+			"followUser": &gql.Field{
+				Type:    gql.NewNonNull(userType),
+				Args:    gql.FieldConfigArgument{"username": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+				Resolve: resolve(r.followUser),
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// resolve - ошибки резолвера и его отложенного значения приводятся к ошибкам приложения,
+// внутренние ошибки логируются и клиенту отдаются без подробностей
+func resolve(fn gql.FieldResolveFn) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		result, err := fn(p)
+		if err != nil {
+			return nil, publicError(p, err)
+		}
+
+		if thunk, ok := result.(func() (interface{}, error)); ok {
+			return func() (interface{}, error) {
+				result, err := thunk()
+				if err != nil {
+					return nil, publicError(p, err)
+				}
+				return result, nil
+			}, nil
+		}
+
+		return result, nil
+	}
+}
+
+func publicError(p gql.ResolveParams, err error) error {
+	appErr := apperror.From(err)
+	if appErr.Code == apperror.Internal.Code {
+		logger.FromContext(p.Context).Errorf("graphql - %s.%s: %v", p.Info.ParentType.Name(), p.Info.FieldName, err)
+	}
+
+	// the cause stays in the log, the client gets the code and the message only
+	return apperror.New(appErr.Code, appErr.Status, appErr.Message).WithDetails(appErr.Details)
+}
+
+func (r *resolver) userArticles(p gql.ResolveParams) (interface{}, error) {
+	return r.useCases.Article.GetArticlesByAuthorID(p.Context, usecase.ArticleGetArticlesByAuthorIDInput{
+		AuthorID: p.Source.(entity.User).ID,
+	})
+}
+
+func commentArticleID(p gql.ResolveParams) (interface{}, error) {
+	return p.Source.(entity.Comment).ArticleID, nil
+}
+
+func commentParentID(p gql.ResolveParams) (interface{}, error) {
+	parentID := p.Source.(entity.Comment).ParentID
+	if !parentID.Valid {
+		return nil, nil
+	}
+	return parentID.UUID, nil
+}
+
+func commentAuthor(p gql.ResolveParams) (interface{}, error) {
+	return loadUser(p.Context, p.Source.(entity.Comment).AuthorID), nil
+}
+
+func articleAuthor(p gql.ResolveParams) (interface{}, error) {
+	return loadUser(p.Context, p.Source.(entity.Article).AuthorID), nil
+}
+
+func articleTags(p gql.ResolveParams) (interface{}, error) {
+	thunk := loadersFromContext(p.Context).tags.load(p.Context, p.Source.(entity.Article).Id)
+	return func() (interface{}, error) {
+		tags, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if tags == nil {
+			return []entity.Tag{}, nil
+		}
+		return tags, nil
+	}, nil
+}
+
+func articleFavorited(p gql.ResolveParams) (interface{}, error) {
+	thunk := loadersFromContext(p.Context).favorited.load(p.Context, p.Source.(entity.Article).Id)
+	return func() (interface{}, error) {
+		return thunk()
+	}, nil
+}
+
+func (r *resolver) articleComments(p gql.ResolveParams) (interface{}, error) {
+	limit, offset, err := page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.useCases.Comment.GetCommentsByArticleID(p.Context, usecase.CommentGetCommentsByArticleIDInput{
+		ArticleID: p.Source.(entity.Article).Id,
+		Limit:     limit,
+		Offset:    offset,
+	})
+}
+
+func me(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := viewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	return loadUser(p.Context, viewerID), nil
+}
+
+func (r *resolver) user(p gql.ResolveParams) (interface{}, error) {
+	return r.useCases.User.GetUserByUsername(p.Context, usecase.UserGetUserByUsernameInput{
+		Username: p.Args["username"].(string),
+	})
+}
+
+func (r *resolver) article(p gql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	return r.useCases.Article.GetArticleByID(p.Context, usecase.ArticleGetArticleByIDInput{ID: id})
+}
+
+func (r *resolver) articles(p gql.ResolveParams) (interface{}, error) {
+	limit, offset, err := page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	return r.useCases.Article.GetNewestArticles(p.Context, usecase.ArticleGetNewestArticlesInput{Limit: limit, Offset: offset})
+}
+
+func (r *resolver) feed(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := viewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.useCases.Article.GetFeed(p.Context, usecase.ArticleGetFeedInput{
+		UserID: viewerID,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (r *resolver) search(p gql.ResolveParams) (interface{}, error) {
+	limit, offset, err := page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.useCases.Article.SearchArticles(p.Context, usecase.ArticleSearchArticlesInput{
+		Query:  p.Args["query"].(string),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (r *resolver) comments(p gql.ResolveParams) (interface{}, error) {
+	articleID, err := idArg(p.Args, "articleId")
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.useCases.Comment.GetCommentsByArticleID(p.Context, usecase.CommentGetCommentsByArticleIDInput{
+		ArticleID: articleID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+}
+
+func (r *resolver) createArticle(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := viewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	title, err := stringArg(p.Args, "title", maxTitleLength)
+	if err != nil {
+		return nil, err
+	}
+	description, err := stringArg(p.Args, "description", maxTitleLength)
+	if err != nil {
+		return nil, err
+	}
+	content, err := stringArg(p.Args, "content", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	articleID, err := r.useCases.Article.CreateArticle(p.Context, usecase.ArticleCreateArticleInput{
+		AuthorID:    viewerID,
+		Title:       *title,
+		Description: *description,
+		Content:     *content,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.useCases.Article.GetArticleByID(p.Context, usecase.ArticleGetArticleByIDInput{ID: articleID})
+}
+
+func (r *resolver) updateArticle(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := viewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	articleID, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	title, err := stringArg(p.Args, "title", maxTitleLength)
+	if err != nil {
+		return nil, err
+	}
+	description, err := stringArg(p.Args, "description", maxTitleLength)
+	if err != nil {
+		return nil, err
+	}
+	content, err := stringArg(p.Args, "content", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.useCases.Article.UpdateArticle(p.Context, usecase.ArticleUpdateArticleInput{
+		RequestedUserID: viewerID,
+		ID:              articleID,
+		NewTitle:        title,
+		NewDescription:  description,
+		NewContent:      content,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.useCases.Article.GetArticleByID(p.Context, usecase.ArticleGetArticleByIDInput{ID: articleID})
+}
+
+func (r *resolver) favoriteArticle(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := viewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	articleID, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.useCases.Article.SetArticleFavorite(p.Context, usecase.ArticleSetArticleFavoriteInput{UserID: viewerID, ArticleID: articleID})
+	if err != nil {
+		return nil, err
+	}
+	return r.useCases.Article.GetArticleByID(p.Context, usecase.ArticleGetArticleByIDInput{ID: articleID})
+}
+
+func (r *resolver) unfavoriteArticle(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := viewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	articleID, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.useCases.Article.RemoveArticleFavorite(p.Context, usecase.ArticleRemoveArticleFavoriteInput{UserID: viewerID, ArticleID: articleID})
+	if err != nil {
+		return nil, err
+	}
+	return r.useCases.Article.GetArticleByID(p.Context, usecase.ArticleGetArticleByIDInput{ID: articleID})
+}
+
+func (r *resolver) followUser(p gql.ResolveParams) (interface{}, error) {
+	viewerID, err := viewer(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	username := p.Args["username"].(string)
+
+	err = r.useCases.User.FollowUser(p.Context, usecase.UserFollowUserInput{FollowerID: viewerID, Username: username})
+	if err != nil {
+		return nil, err
+	}
+	return r.useCases.User.GetUserByUsername(p.Context, usecase.UserGetUserByUsernameInput{Username: username})
+}
+
+// loadUser - удаленный пользователь возвращается как null
+func loadUser(ctx context.Context, userID uuid.UUID) func() (interface{}, error) {
+	thunk := loadersFromContext(ctx).users.load(ctx, userID)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil || user == nil {
+			return nil, err
+		}
+		return *user, nil
+	}
+}
+
+// viewer - субъект добавляет AuthMiddleware.Authorize
+func viewer(ctx context.Context) (uuid.UUID, error) {
+	subject, ok := policy.SubjectFromContext(ctx)
+	if !ok {
+		return uuid.UUID{}, errForbidden
+	}
+	return subject.ID, nil
+}
+
+func pageArgs(args gql.FieldConfigArgument) gql.FieldConfigArgument {
+	if args == nil {
+		args = gql.FieldConfigArgument{}
+	}
+	args["limit"] = &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultLimit}
+	args["offset"] = &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 0}
+	return args
+}
+
+func page(args map[string]interface{}) (limit, offset int, err error) {
+	limit, _ = args["limit"].(int)
+	if limit < 1 || limit > maxLimit {
+		return 0, 0, invalidArgument("limit")
+	}
+	offset, _ = args["offset"].(int)
+	if offset < 0 {
+		return 0, 0, invalidArgument("offset")
+	}
+	return limit, offset, nil
+}
+
+func idArg(args map[string]interface{}, name string) (uuid.UUID, error) {
+	s, _ := args[name].(string)
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.UUID{}, invalidArgument(name)
+	}
+	return id, nil
+}
+
+// stringArg - nil для отсутствующего аргумента, пустая строка и строка длиннее maxLength (0 - без ограничения) отклоняются
+func stringArg(args map[string]interface{}, name string, maxLength int) (*string, error) {
+	s, ok := args[name].(string)
+	if !ok {
+		return nil, nil
+	}
+	if s == "" || maxLength > 0 && utf8.RuneCountInString(s) > maxLength {
+		return nil, invalidArgument(name)
+	}
+	return &s, nil
+}
+
+func invalidArgument(name string) error {
+	return errInvalidArgument.WithDetails(map[string]interface{}{"argument": name})
+}
@@ -0,0 +1,229 @@
+package v1
+
+import (
+	blogv1 "blog-backend/api/blog/v1"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/usecase"
+	"context"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type articleService struct {
+	blogv1.UnimplementedArticleServiceServer
+
+	articleUseCase usecase.Article
+}
+
+func (s *articleService) GetArticle(ctx context.Context, req *blogv1.GetArticleRequest) (*blogv1.GetArticleResponse, error) {
+	id, err := parseID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := s.articleUseCase.GetArticleByID(ctx, usecase.ArticleGetArticleByIDInput{ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return &blogv1.GetArticleResponse{Article: articleToProto(article)}, nil
+}
+
+func (s *articleService) ListNewestArticles(ctx context.Context, req *blogv1.ListNewestArticlesRequest) (*blogv1.ListArticlesResponse, error) {
+	limit, offset, err := page(req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := s.articleUseCase.GetNewestArticles(ctx, usecase.ArticleGetNewestArticlesInput{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+
+	return articlesResponse(articles), nil
+}
+
+func (s *articleService) ListArticlesByAuthor(ctx context.Context, req *blogv1.ListArticlesByAuthorRequest) (*blogv1.ListArticlesResponse, error) {
+	authorID, err := parseID("author_id", req.GetAuthorId())
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := s.articleUseCase.GetArticlesByAuthorID(ctx, usecase.ArticleGetArticlesByAuthorIDInput{AuthorID: authorID})
+	if err != nil {
+		return nil, err
+	}
+
+	return articlesResponse(articles), nil
+}
+
+func (s *articleService) ListFavoriteArticles(ctx context.Context, req *blogv1.ListFavoriteArticlesRequest) (*blogv1.ListArticlesResponse, error) {
+	userID, err := parseID("user_id", req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := s.articleUseCase.GetFavoriteArticles(ctx, usecase.ArticleGetFavoriteArticlesInput{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return articlesResponse(articles), nil
+}
+
+func (s *articleService) ListFeed(ctx context.Context, req *blogv1.ListFeedRequest) (*blogv1.ListArticlesResponse, error) {
+	userID, err := parseID("user_id", req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := page(req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := s.articleUseCase.GetFeed(ctx, usecase.ArticleGetFeedInput{UserID: userID, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+
+	return articlesResponse(articles), nil
+}
+
+func (s *articleService) SearchArticles(ctx context.Context, req *blogv1.SearchArticlesRequest) (*blogv1.ListArticlesResponse, error) {
+	limit, offset, err := page(req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := s.articleUseCase.SearchArticles(ctx, usecase.ArticleSearchArticlesInput{
+		Query:  req.GetQuery(),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return articlesResponse(articles), nil
+}
+
+func (s *articleService) GetArticlesTags(ctx context.Context, req *blogv1.GetArticlesTagsRequest) (*blogv1.GetArticlesTagsResponse, error) {
+	articleIDs, err := parseIDs("article_ids", req.GetArticleIds())
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.articleUseCase.GetArticlesTags(ctx, usecase.ArticleGetArticlesTagsInput{ArticleIDs: articleIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &blogv1.GetArticlesTagsResponse{Tags: make(map[string]*blogv1.Tags, len(tags))}
+	for articleID, articleTags := range tags {
+		t := &blogv1.Tags{Tags: make([]*blogv1.Tag, 0, len(articleTags))}
+		for _, tag := range articleTags {
+			t.Tags = append(t.Tags, &blogv1.Tag{Id: tag.Id.String(), Description: tag.Description})
+		}
+		resp.Tags[articleID.String()] = t
+	}
+
+	return resp, nil
+}
+
+func (s *articleService) GetFavoritedArticleIDs(ctx context.Context, req *blogv1.GetFavoritedArticleIDsRequest) (*blogv1.GetFavoritedArticleIDsResponse, error) {
+	userID, err := parseID("user_id", req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	articleIDs, err := parseIDs("article_ids", req.GetArticleIds())
+	if err != nil {
+		return nil, err
+	}
+
+	favorited, err := s.articleUseCase.GetFavoritedArticleIDs(ctx, usecase.ArticleGetFavoritedArticleIDsInput{
+		UserID:     userID,
+		ArticleIDs: articleIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &blogv1.GetFavoritedArticleIDsResponse{ArticleIds: idsToProto(favorited)}, nil
+}
+
+func articlesResponse(articles []entity.Article) *blogv1.ListArticlesResponse {
+	resp := &blogv1.ListArticlesResponse{Articles: make([]*blogv1.Article, 0, len(articles))}
+	for _, article := range articles {
+		resp.Articles = append(resp.Articles, articleToProto(article))
+	}
+	return resp
+}
+
+func articleToProto(article entity.Article) *blogv1.Article {
+	return &blogv1.Article{
+		Id:             article.Id.String(),
+		AuthorId:       article.AuthorID.String(),
+		Title:          article.Title,
+		Description:    article.Description,
+		Content:        article.Content,
+		CreatedAt:      timestamppb.New(article.CreatedAt),
+		UpdatedAt:      timestamppb.New(article.UpdatedAt),
+		ViewsCount:     int32(article.ViewsCount),
+		CommentsCount:  int32(article.CommentsCount),
+		FavoritesCount: int32(article.FavoritesCount),
+		VotesUpCount:   int32(article.VotesUpCount),
+		VotesDownCount: int32(article.VotesDownCount),
+	}
+}
+
+// page - ограничения те же, что у списков в REST, нулевой limit - limit по умолчанию
+func page(limit, offset int32) (int, int, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit < 0 || limit > maxLimit {
+		return 0, 0, invalidArgument("limit")
+	}
+	if offset < 0 {
+		return 0, 0, invalidArgument("offset")
+	}
+	return int(limit), int(offset), nil
+}
+
+func parseID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.UUID{}, invalidArgument(field)
+	}
+	return id, nil
+}
+
+// parseIDs - не больше maxLimit id, как у пакетных чтений в GraphQL
+func parseIDs(field string, values []string) ([]uuid.UUID, error) {
+	if len(values) > maxLimit {
+		return nil, invalidArgument(field)
+	}
+
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := parseID(field, value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func idsToProto(ids []uuid.UUID) []string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
+	}
+	return values
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"blog-backend/pkg/apperror"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// errorDomain - домен кодов ошибок в ErrorInfo, коды те же, что в REST
+const errorDomain = "blog-backend"
+
+var errInvalidArgument = apperror.New("invalid_argument", http.StatusBadRequest, "invalid argument")
+
+// appStatusError - статус для клиента и исходная ошибка для лога запроса
+type appStatusError struct {
+	status *status.Status
+	cause  error
+}
+
+func (e *appStatusError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *appStatusError) Unwrap() error {
+	return e.cause
+}
+
+func (e *appStatusError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// statusError - код gRPC по статусу HTTP ошибки приложения, постоянный код и детали ошибки передаются в ErrorInfo.
+// Все остальные ошибки отдаются как internal_error без подробностей
+func statusError(err error) error {
+	appErr := apperror.From(err)
+
+	info := &errdetails.ErrorInfo{Reason: appErr.Code, Domain: errorDomain}
+	for key, value := range appErr.Details {
+		if info.Metadata == nil {
+			info.Metadata = make(map[string]string, len(appErr.Details))
+		}
+		info.Metadata[key] = fmt.Sprint(value)
+	}
+
+	s := status.New(grpcCode(appErr.Status), appErr.Message)
+	if withDetails, detailsErr := s.WithDetails(info); detailsErr == nil {
+		s = withDetails
+	}
+
+	return &appStatusError{status: s, cause: err}
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+
+	if httpStatus >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.FailedPrecondition
+}
+
+func invalidArgument(field string) error {
+	return errInvalidArgument.WithDetails(map[string]interface{}{"field": field})
+}
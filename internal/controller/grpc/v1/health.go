@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"blog-backend/pkg/health"
+	"context"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthService - grpc.health.v1 по тем же проверкам readiness, что и /readyz: во время остановки
+// и при недоступной базе экземпляр NOT_SERVING. Пустое имя сервиса - весь сервер
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+
+	h        *health.Health
+	services map[string]struct{}
+}
+
+func (s *healthService) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if _, ok := s.services[req.GetService()]; !ok && req.GetService() != "" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	if s.h.Ready(ctx).Status != health.StatusOK {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
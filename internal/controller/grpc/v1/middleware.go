@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"blog-backend/internal/metrics"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/logger"
+	"context"
+	"crypto/subtle"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const (
+	headerAuthorization = "authorization"
+	headerRequestID     = "x-request-id"
+
+	fieldService = "service"
+
+	// health checks come from probes and load balancers without credentials
+	healthServicePrefix = "/grpc.health.v1.Health/"
+)
+
+var errUnauthenticated = apperror.New("unauthenticated", http.StatusUnauthorized, "service token or client certificate required")
+
+// interceptor - общая часть unary и stream перехватчиков, next вызывает следующий перехватчик или метод
+type interceptor func(ctx context.Context, method string, next func(ctx context.Context) error) error
+
+// Interceptors - порядок важен: лог и метрики видят итоговый код ответа, а ошибки
+// доступа проходят через Errors так же, как ошибки use cases
+func Interceptors(auth *Authenticator, m *metrics.Metrics) []grpc.ServerOption {
+	chain := []interceptor{auth.Identify, RequestLogger, RequestMetrics(m), Errors, auth.Authenticate}
+
+	unary := make([]grpc.UnaryServerInterceptor, 0, len(chain))
+	stream := make([]grpc.StreamServerInterceptor, 0, len(chain))
+	for _, i := range chain {
+		unary = append(unary, unaryInterceptor(i))
+		stream = append(stream, streamInterceptor(i))
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+func unaryInterceptor(i interceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var resp interface{}
+		err := i(ctx, info.FullMethod, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+func streamInterceptor(i interceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return i(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+// serverStream - поток с контекстом, дополненным перехватчиками
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// Authenticator - сервис входит по клиентскому сертификату, проверенному при TLS рукопожатии,
+// или по токену из заголовка authorization: Bearer <token>
+type Authenticator struct {
+	tokens map[string]string // service name to token
+}
+
+func NewAuthenticator(tokens map[string]string) *Authenticator {
+	return &Authenticator{tokens: tokens}
+}
+
+type serviceKey struct{}
+
+// ServiceFromContext - имя сервиса: CN клиентского сертификата или имя токена из конфигурации
+func ServiceFromContext(ctx context.Context) (string, bool) {
+	service, ok := ctx.Value(serviceKey{}).(string)
+	return service, ok
+}
+
+// Identify - id запроса из x-request-id клиента, если это uuid, иначе новый, и сервис, если он определен,
+// добавляются в контекст и логгер. Вызов без сервиса отклоняет Authenticate
+func (a *Authenticator) Identify(ctx context.Context, method string, next func(ctx context.Context) error) error {
+	requestID := ""
+	if values := metadata.ValueFromIncomingContext(ctx, headerRequestID); len(values) > 0 {
+		if _, err := uuid.Parse(values[0]); err == nil {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+
+	fields := log.Fields{
+		logger.FieldRequestID: requestID,
+		logger.FieldMethod:    method,
+	}
+	if service, ok := a.service(ctx); ok {
+		ctx = context.WithValue(ctx, serviceKey{}, service)
+		fields[fieldService] = service
+	}
+
+	return next(logger.WithFields(ctx, fields))
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, method string, next func(ctx context.Context) error) error {
+	if _, ok := ServiceFromContext(ctx); !ok && !strings.HasPrefix(method, healthServicePrefix) {
+		return errUnauthenticated
+	}
+	return next(ctx)
+}
+
+func (a *Authenticator) service(ctx context.Context) (string, bool) {
+	// chains are verified only when the server is configured with a client CA
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			cert := info.State.VerifiedChains[0][0]
+			if cert.Subject.CommonName != "" {
+				return cert.Subject.CommonName, true
+			}
+			if len(cert.DNSNames) > 0 {
+				return cert.DNSNames[0], true
+			}
+		}
+	}
+
+	for _, value := range metadata.ValueFromIncomingContext(ctx, headerAuthorization) {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if !ok || token == "" {
+			continue
+		}
+		for name, t := range a.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// RequestLogger - одна запись на вызов, ошибка пишется полностью, клиенту отдается только ее статус
+func RequestLogger(ctx context.Context, _ string, next func(ctx context.Context) error) error {
+	start := time.Now()
+	err := next(ctx)
+
+	code := status.Code(err)
+	entry := logger.FromContext(ctx).WithFields(log.Fields{
+		"code":       code.String(),
+		"latency_ms": time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		entry.Error("grpc call failed")
+	default:
+		entry.Info("grpc call")
+	}
+
+	return err
+}
+
+// RequestMetrics - время вызова по методу и коду ответа
+func RequestMetrics(m *metrics.Metrics) interceptor {
+	return func(ctx context.Context, method string, next func(ctx context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+		m.ObserveGRPCRequest(method, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
+
+// Errors - единственное место, где ошибки превращаются в статусы, как ErrorHandler в REST.
+// Паника в методе отдается как внутренняя ошибка и не останавливает сервер
+func Errors(ctx context.Context, _ string, next func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).Errorf("grpc panic: %v\n%s", r, debug.Stack())
+			err = statusError(apperror.Internal)
+		}
+	}()
+
+	err = next(ctx)
+	if err == nil {
+		return nil
+	}
+
+	// errors of grpc itself, health and reflection services already carry a status
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	return statusError(err)
+}
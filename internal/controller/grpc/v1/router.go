@@ -0,0 +1,21 @@
+package v1
+
+import (
+	blogv1 "blog-backend/api/blog/v1"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/health"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// NewRouter - сервисы для других внутренних сервисов, перехватчики задаются при создании сервера, см. Interceptors
+func NewRouter(server *grpc.Server, useCases *usecase.UseCases, h *health.Health) {
+	blogv1.RegisterArticleServiceServer(server, &articleService{articleUseCase: useCases.Article})
+	blogv1.RegisterUserServiceServer(server, &userService{userUseCase: useCases.User})
+
+	services := make(map[string]struct{})
+	for name := range server.GetServiceInfo() {
+		services[name] = struct{}{}
+	}
+	healthpb.RegisterHealthServer(server, &healthService{h: h, services: services})
+}
@@ -0,0 +1,259 @@
+package v1
+
+import (
+	blogv1 "blog-backend/api/blog/v1"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/usecase"
+	"blog-backend/internal/usecase/mocks"
+	"blog-backend/pkg/health"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+)
+
+const testToken = "test-token"
+
+type testDeps struct {
+	user    *mocks.MockUser
+	article *mocks.MockArticle
+	health  *health.Health
+	conn    *grpc.ClientConn
+}
+
+func newTestServer(t *testing.T) testDeps {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	d := testDeps{
+		user:    mocks.NewMockUser(ctrl),
+		article: mocks.NewMockArticle(ctrl),
+		health:  health.New(),
+	}
+
+	auth := NewAuthenticator(map[string]string{"feed": testToken})
+	server := grpc.NewServer(Interceptors(auth, metrics.New())...)
+	NewRouter(server, &usecase.UseCases{User: d.user, Article: d.article}, d.health)
+
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	d.conn = conn
+
+	return d
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), headerAuthorization, "Bearer "+token)
+}
+
+func errorInfo(t *testing.T, err error) *errdetails.ErrorInfo {
+	t.Helper()
+
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", err)
+	return nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{name: "no token", ctx: context.Background(), wantCode: codes.Unauthenticated},
+		{name: "unknown token", ctx: withToken("other"), wantCode: codes.Unauthenticated},
+		{name: "service token", ctx: withToken(testToken), wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestServer(t)
+			if tt.wantCode == codes.OK {
+				d.user.EXPECT().GetUserByUsername(gomock.Any(), usecase.UserGetUserByUsernameInput{Username: "alice"}).
+					DoAndReturn(func(ctx context.Context, _ usecase.UserGetUserByUsernameInput) (entity.User, error) {
+						if service, _ := ServiceFromContext(ctx); service != "feed" {
+							t.Errorf("service = %q, want feed", service)
+						}
+						return entity.User{Username: "alice"}, nil
+					})
+			}
+
+			resp, err := blogv1.NewUserServiceClient(d.conn).GetUser(tt.ctx, &blogv1.GetUserRequest{Username: "alice"})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %s, want %s", code, tt.wantCode)
+			}
+			if err != nil {
+				if reason := errorInfo(t, err).Reason; reason != "unauthenticated" {
+					t.Errorf("reason = %q, want unauthenticated", reason)
+				}
+				return
+			}
+			if resp.GetUser().GetUsername() != "alice" {
+				t.Errorf("username = %q, want alice", resp.GetUser().GetUsername())
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	articleID := uuid.New()
+
+	tests := []struct {
+		name       string
+		id         string
+		mock       func(d testDeps)
+		wantCode   codes.Code
+		wantReason string
+		wantField  string
+	}{
+		{
+			name: "not found",
+			id:   articleID.String(),
+			mock: func(d testDeps) {
+				d.article.EXPECT().GetArticleByID(gomock.Any(), usecase.ArticleGetArticleByIDInput{ID: articleID}).
+					Return(entity.Article{}, usecase.ErrArticleNotFound)
+			},
+			wantCode:   codes.NotFound,
+			wantReason: "article_not_found",
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			mock:       func(d testDeps) {},
+			wantCode:   codes.InvalidArgument,
+			wantReason: "invalid_argument",
+			wantField:  "id",
+		},
+		{
+			name: "internal error",
+			id:   articleID.String(),
+			mock: func(d testDeps) {
+				d.article.EXPECT().GetArticleByID(gomock.Any(), gomock.Any()).
+					Return(entity.Article{}, errors.New("connection refused"))
+			},
+			wantCode:   codes.Internal,
+			wantReason: "internal_error",
+		},
+		{
+			name: "panic",
+			id:   articleID.String(),
+			mock: func(d testDeps) {
+				d.article.EXPECT().GetArticleByID(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(context.Context, usecase.ArticleGetArticleByIDInput) (entity.Article, error) {
+						panic("boom")
+					})
+			},
+			wantCode:   codes.Internal,
+			wantReason: "internal_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestServer(t)
+			tt.mock(d)
+
+			_, err := blogv1.NewArticleServiceClient(d.conn).GetArticle(withToken(testToken), &blogv1.GetArticleRequest{Id: tt.id})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %s, want %s", code, tt.wantCode)
+			}
+
+			info := errorInfo(t, err)
+			if info.Reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", info.Reason, tt.wantReason)
+			}
+			if info.Metadata["field"] != tt.wantField {
+				t.Errorf("field = %q, want %q", info.Metadata["field"], tt.wantField)
+			}
+			if tt.wantCode == codes.Internal && status.Convert(err).Message() != "internal server error" {
+				t.Errorf("message = %q, cause must not reach the client", status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	d := newTestServer(t)
+	client := healthpb.NewHealthClient(d.conn)
+
+	// probes have no credentials
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %s, want SERVING", resp.GetStatus())
+	}
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown.Service"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("unknown service code = %s, want %s", code, codes.NotFound)
+	}
+
+	d.health.Shutdown()
+	resp, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: blogv1.ArticleService_ServiceDesc.ServiceName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("status after shutdown = %s, want NOT_SERVING", resp.GetStatus())
+	}
+}
+
+func TestPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int32
+		offset     int32
+		wantLimit  int
+		wantOffset int
+		wantErr    bool
+	}{
+		{name: "default limit", limit: 0, offset: 5, wantLimit: defaultLimit, wantOffset: 5},
+		{name: "max limit", limit: maxLimit, wantLimit: maxLimit},
+		{name: "limit too big", limit: maxLimit + 1, wantErr: true},
+		{name: "negative limit", limit: -1, wantErr: true},
+		{name: "negative offset", limit: 10, offset: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset, err := page(tt.limit, tt.offset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("page = %d, %d, want %d, %d", limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
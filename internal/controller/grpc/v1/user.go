@@ -0,0 +1,62 @@
+package v1
+
+import (
+	blogv1 "blog-backend/api/blog/v1"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/usecase"
+	"context"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type userService struct {
+	blogv1.UnimplementedUserServiceServer
+
+	userUseCase usecase.User
+}
+
+func (s *userService) GetUser(ctx context.Context, req *blogv1.GetUserRequest) (*blogv1.GetUserResponse, error) {
+	if req.GetUsername() == "" {
+		return nil, invalidArgument("username")
+	}
+
+	user, err := s.userUseCase.GetUserByUsername(ctx, usecase.UserGetUserByUsernameInput{Username: req.GetUsername()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &blogv1.GetUserResponse{User: userToProto(user)}, nil
+}
+
+func (s *userService) GetUsers(ctx context.Context, req *blogv1.GetUsersRequest) (*blogv1.GetUsersResponse, error) {
+	ids, err := parseIDs("ids", req.GetIds())
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userUseCase.GetUsersByIDs(ctx, usecase.UserGetUsersByIDsInput{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &blogv1.GetUsersResponse{Users: make([]*blogv1.User, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, userToProto(user))
+	}
+	return resp, nil
+}
+
+func userToProto(user entity.User) *blogv1.User {
+	return &blogv1.User{
+		Id:             user.ID.String(),
+		Username:       user.Username,
+		Name:           user.Name,
+		Email:          user.Email,
+		Role:           string(user.Role),
+		Description:    user.Description,
+		CreatedAt:      timestamppb.New(user.CreatedAt),
+		ArticlesCount:  int32(user.ArticlesCount),
+		CommentsCount:  int32(user.CommentsCount),
+		FollowersCount: int32(user.FollowersCount),
+		FollowingCount: int32(user.FollowingCount),
+	}
+}
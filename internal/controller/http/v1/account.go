@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/usecase"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type accountRoutes struct {
+	accountUseCase usecase.Account
+}
+
+func newAccountRoutes(g *echo.Group, accountUseCase usecase.Account) {
+	r := &accountRoutes{
+		accountUseCase: accountUseCase,
+	}
+
+	g.POST("/users/me/export", r.requestExport)
+	g.GET("/users/me/exports/:id", r.getExport)
+	g.GET("/users/me/exports/:id/download", r.downloadExport)
+
+	g.DELETE("/users/me", r.scheduleDeletion)
+	g.POST("/users/me/cancel-deletion", r.cancelDeletion)
+}
+
+func (r *accountRoutes) requestExport(c echo.Context) error {
+	export, err := r.accountUseCase.RequestExport(c.Request().Context(), usecase.AccountRequestExportInput{
+		UserID: c.Get(userIDCtx).(uuid.UUID),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"export": exportResponse(export),
+	})
+}
+
+type getExportInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *accountRoutes) getExport(c echo.Context) error {
+	var input getExportInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	export, err := r.accountUseCase.GetExport(c.Request().Context(), usecase.AccountGetExportInput{
+		UserID: c.Get(userIDCtx).(uuid.UUID),
+		ID:     input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"export": exportResponse(export),
+	})
+}
+
+func (r *accountRoutes) downloadExport(c echo.Context) error {
+	var input getExportInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	archive, err := r.accountUseCase.GetExportArchive(c.Request().Context(), usecase.AccountGetExportInput{
+		UserID: c.Get(userIDCtx).(uuid.UUID),
+		ID:     input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "export-"+input.ID.String()+".zip"))
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
+
+type scheduleDeletionInput struct {
+	Password string `json:"password" validate:"required"`
+}
+
+func (r *accountRoutes) scheduleDeletion(c echo.Context) error {
+	var input scheduleDeletionInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	scheduledAt, err := r.accountUseCase.ScheduleDeletion(c.Request().Context(), usecase.AccountScheduleDeletionInput{
+		UserID:   c.Get(userIDCtx).(uuid.UUID),
+		Password: input.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"deletion_scheduled_at": scheduledAt,
+	})
+}
+
+func (r *accountRoutes) cancelDeletion(c echo.Context) error {
+	err := r.accountUseCase.CancelDeletion(c.Request().Context(), usecase.AccountCancelDeletionInput{
+		UserID: c.Get(userIDCtx).(uuid.UUID),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+func exportResponse(export entity.UserExport) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           export.ID,
+		"status":       export.Status,
+		"size":         export.Size,
+		"created_at":   export.CreatedAt,
+		"completed_at": export.CompletedAt,
+		"expires_at":   export.ExpiresAt,
+	}
+}
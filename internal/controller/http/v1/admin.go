@@ -0,0 +1,444 @@
+package v1
+
+import (
+	"blog-backend/internal/audit"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/apperror"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+const defaultAdminLimit = 20
+
+type adminRoutes struct {
+	adminUseCase   usecase.Admin
+	userUseCase    usecase.User
+	articleUseCase usecase.Article
+	commentUseCase usecase.Comment
+}
+
+func newAdminRoutes(
+	g *echo.Group,
+	adminUseCase usecase.Admin,
+	userUseCase usecase.User,
+	articleUseCase usecase.Article,
+	commentUseCase usecase.Comment,
+) {
+	r := &adminRoutes{
+		adminUseCase:   adminUseCase,
+		userUseCase:    userUseCase,
+		articleUseCase: articleUseCase,
+		commentUseCase: commentUseCase,
+	}
+
+	g.GET("/users", r.searchUsers)
+	g.POST("/users/bulk", r.bulkAction)
+	g.PUT("/users/:id/role", r.setUserRole)
+	g.POST("/users/:id/reset-password", r.resetUserPassword)
+	g.POST("/users/:id/revoke-sessions", r.revokeUserSessions)
+	g.POST("/users/:id/ban", r.banUser)
+	g.POST("/users/:id/unban", r.unbanUser)
+	g.POST("/users/:id/impersonate", r.impersonate)
+	g.GET("/audit", r.getAuditLog)
+	g.GET("/audit/export", r.exportAuditLog)
+
+	g.POST("/users/:id/restore", r.restoreUser)
+	g.POST("/articles/:id/restore", r.restoreArticle)
+	g.POST("/comments/:id/restore", r.restoreComment)
+}
+
+type searchUsersInput struct {
+	Query   string           `query:"q" validate:"max=256"`
+	Role    *entity.RoleType `query:"role" validate:"omitempty,oneof=user moderator admin"`
+	Banned  *bool            `query:"banned"`
+	Deleted bool             `query:"deleted"`
+	Limit   int              `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset  int              `query:"offset" validate:"omitempty,min=0"`
+}
+
+func (r *adminRoutes) searchUsers(c echo.Context) error {
+	var input searchUsersInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultAdminLimit
+	}
+
+	users, err := r.adminUseCase.SearchUsers(c.Request().Context(), usecase.AdminSearchUsersInput{
+		Filter: entity.UserFilter{
+			Query:   input.Query,
+			Role:    input.Role,
+			Banned:  input.Banned,
+			Deleted: input.Deleted,
+			Limit:   input.Limit,
+			Offset:  input.Offset,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(users))
+	for _, user := range users {
+		result = append(result, map[string]interface{}{
+			"id":                      user.ID,
+			"name":                    user.Name,
+			"username":                user.Username,
+			"email":                   user.Email,
+			"role":                    user.Role,
+			"created_at":              user.CreatedAt,
+			"banned_at":               user.BannedAt,
+			"deletion_scheduled_at":   user.DeletionScheduledAt,
+			"sessions_revoked_at":     user.SessionsRevokedAt,
+			"password_reset_required": user.PasswordResetRequired,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"users": result,
+	})
+}
+
+type adminUserInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+type setUserRoleInput struct {
+	ID   uuid.UUID       `param:"id" validate:"required"`
+	Role entity.RoleType `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+func (r *adminRoutes) setUserRole(c echo.Context) error {
+	var input setUserRoleInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.adminUseCase.SetUserRole(c.Request().Context(), usecase.AdminSetUserRoleInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+		UserID:          input.ID,
+		Role:            input.Role,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+func (r *adminRoutes) resetUserPassword(c echo.Context) error {
+	input, err := bindAdminUserInput(c)
+	if err != nil {
+		return err
+	}
+
+	password, err := r.adminUseCase.ResetUserPassword(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"temporary_password": password,
+	})
+}
+
+func (r *adminRoutes) revokeUserSessions(c echo.Context) error {
+	input, err := bindAdminUserInput(c)
+	if err != nil {
+		return err
+	}
+
+	return adminActionResponse(c, r.adminUseCase.RevokeUserSessions(c.Request().Context(), input))
+}
+
+func (r *adminRoutes) banUser(c echo.Context) error {
+	input, err := bindAdminUserInput(c)
+	if err != nil {
+		return err
+	}
+
+	return adminActionResponse(c, r.adminUseCase.BanUser(c.Request().Context(), input))
+}
+
+func (r *adminRoutes) unbanUser(c echo.Context) error {
+	input, err := bindAdminUserInput(c)
+	if err != nil {
+		return err
+	}
+
+	return adminActionResponse(c, r.adminUseCase.UnbanUser(c.Request().Context(), input))
+}
+
+// вход от имени пользователя, токен возвращается в ответе и не заменяет cookie администратора
+func (r *adminRoutes) impersonate(c echo.Context) error {
+	input, err := bindAdminUserInput(c)
+	if err != nil {
+		return err
+	}
+
+	token, err := r.adminUseCase.Impersonate(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"access_token": token,
+	})
+}
+
+type bulkActionInput struct {
+	Action  usecase.AdminBulkAction `json:"action" validate:"required,oneof=ban unban revoke_sessions delete restore"`
+	UserIDs []uuid.UUID             `json:"user_ids" validate:"required,min=1,max=100"`
+}
+
+func (r *adminRoutes) bulkAction(c echo.Context) error {
+	var input bulkActionInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	results, err := r.adminUseCase.BulkAction(c.Request().Context(), usecase.AdminBulkActionInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+		Action:          input.Action,
+		UserIDs:         input.UserIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(results))
+	for _, res := range results {
+		item := map[string]interface{}{
+			"id": res.UserID,
+			"ok": res.Err == nil,
+		}
+		if res.Err != nil {
+			appErr := apperror.From(res.Err)
+			item["code"] = appErr.Code
+			item["error"] = appErr.Message
+		}
+		result = append(result, item)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"results": result,
+	})
+}
+
+type auditLogInput struct {
+	ActorID    *uuid.UUID              `query:"actor_id"`
+	TargetID   *uuid.UUID              `query:"target_id"`
+	TargetType *entity.AuditTargetType `query:"target_type" validate:"omitempty,oneof=user article comment moderation_case"`
+	Action     *entity.AuditAction     `query:"action" validate:"omitempty,oneof=sign_in sign_in_failed password_change user_update role_change password_reset sessions_revoke impersonate ban unban delete restore moderation_claim moderation_resolve"`
+	RequestID  string                  `query:"request_id" validate:"max=128"`
+	IP         string                  `query:"ip" validate:"omitempty,ip"`
+	From       *time.Time              `query:"from"`
+	To         *time.Time              `query:"to"`
+	Limit      int                     `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset     int                     `query:"offset" validate:"omitempty,min=0"`
+	Format     audit.Format            `query:"format" validate:"omitempty,oneof=csv jsonl"` // export only
+}
+
+func (i auditLogInput) filter() entity.AuditFilter {
+	filter := entity.AuditFilter{
+		TargetType: i.TargetType,
+		Action:     i.Action,
+		RequestID:  i.RequestID,
+		IP:         i.IP,
+		From:       i.From,
+		To:         i.To,
+	}
+	if i.ActorID != nil {
+		filter.ActorID = uuid.NullUUID{UUID: *i.ActorID, Valid: true}
+	}
+	if i.TargetID != nil {
+		filter.TargetID = uuid.NullUUID{UUID: *i.TargetID, Valid: true}
+	}
+	return filter
+}
+
+func (r *adminRoutes) getAuditLog(c echo.Context) error {
+	var input auditLogInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultAdminLimit
+	}
+
+	entries, err := r.adminUseCase.GetAuditLog(c.Request().Context(), usecase.AdminGetAuditLogInput{
+		Filter: input.filter(),
+		Limit:  input.Limit,
+		Offset: input.Offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, map[string]interface{}{
+			"id":              entry.ID,
+			"actor_id":        entry.ActorID,
+			"impersonator_id": entry.ImpersonatorID,
+			"action":          entry.Action,
+			"target_type":     entry.TargetType,
+			"target_id":       entry.TargetID,
+			"details":         entry.Details,
+			"ip":              entry.IP,
+			"user_agent":      entry.UserAgent,
+			"request_id":      entry.RequestID,
+			"created_at":      entry.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"log": result,
+	})
+}
+
+// exportAuditLog - журнал целиком по фильтру, без пагинации, в CSV или JSON Lines
+func (r *adminRoutes) exportAuditLog(c echo.Context) error {
+	var input auditLogInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Format == "" {
+		input.Format = audit.FormatCSV
+	}
+
+	w := &attachmentWriter{
+		c:           c,
+		contentType: input.Format.ContentType(),
+		filename:    fmt.Sprintf("audit-log-%s.%s", time.Now().UTC().Format("20060102-150405"), input.Format),
+	}
+
+	err = r.adminUseCase.ExportAuditLog(c.Request().Context(), usecase.AdminExportAuditLogInput{
+		Filter: input.filter(),
+		Format: input.Format,
+	}, w)
+	// the status is sent with the first entry, a later error only cuts the file
+	if err != nil {
+		return err
+	}
+
+	// empty log
+	w.writeHeader()
+	return nil
+}
+
+// attachmentWriter - заголовки ответа отправляются при первой записи, до нее еще можно ответить ошибкой
+type attachmentWriter struct {
+	c           echo.Context
+	contentType string
+	filename    string
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	w.writeHeader()
+	return w.c.Response().Write(p)
+}
+
+func (w *attachmentWriter) writeHeader() {
+	if w.c.Response().Committed {
+		return
+	}
+
+	header := w.c.Response().Header()
+	header.Set(echo.HeaderContentType, w.contentType)
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", w.filename))
+	w.c.Response().WriteHeader(http.StatusOK)
+}
+
+func bindAdminUserInput(c echo.Context) (usecase.AdminUserInput, error) {
+	var input adminUserInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return usecase.AdminUserInput{}, err
+	}
+
+	return usecase.AdminUserInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+		UserID:          input.ID,
+	}, nil
+}
+
+func adminActionResponse(c echo.Context, err error) error {
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+type restoreInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *adminRoutes) restoreUser(c echo.Context) error {
+	var input restoreInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.userUseCase.RestoreUser(c.Request().Context(), usecase.UserRestoreUserInput{
+		ID: input.ID,
+	})
+
+	return adminActionResponse(c, err)
+}
+
+func (r *adminRoutes) restoreArticle(c echo.Context) error {
+	var input restoreInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.articleUseCase.RestoreArticle(c.Request().Context(), usecase.ArticleRestoreArticleInput{
+		ID: input.ID,
+	})
+
+	return adminActionResponse(c, err)
+}
+
+func (r *adminRoutes) restoreComment(c echo.Context) error {
+	var input restoreInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.commentUseCase.RestoreComment(c.Request().Context(), usecase.CommentRestoreCommentInput{
+		ID: input.ID,
+	})
+
+	return adminActionResponse(c, err)
+}
@@ -11,12 +11,15 @@ type articleRoutes struct {
 	articleUseCase usecase.Article
 }
 
-func newArticleRoutes(g *echo.Group, articleUseCase usecase.Article) {
+func newArticleRoutes(g *echo.Group, articleUseCase usecase.Article, conditional echo.MiddlewareFunc) {
 	r := &articleRoutes{
 		articleUseCase: articleUseCase,
 	}
 
 	g.POST("/articles", r.create)
+	g.GET("/articles/:id", r.getByID, conditional)
+	g.PUT("/articles/:id", r.update)
+	g.DELETE("/articles/:id", r.delete)
 }
 
 type createArticleInput struct {
@@ -30,7 +33,6 @@ func (r *articleRoutes) create(c echo.Context) error {
 
 	err := BindAndValidate(c, &input)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
@@ -40,9 +42,7 @@ func (r *articleRoutes) create(c echo.Context) error {
 		Description: input.Description,
 		Content:     input.Content,
 	})
-
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return err
 	}
 
@@ -50,3 +50,95 @@ func (r *articleRoutes) create(c echo.Context) error {
 		"id": articleID,
 	})
 }
+
+type getArticleByIDInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *articleRoutes) getByID(c echo.Context) error {
+	var input getArticleByIDInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	article, err := r.articleUseCase.GetArticleByID(c.Request().Context(), usecase.ArticleGetArticleByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"article": map[string]interface{}{
+			"id":              article.Id,
+			"author_id":       article.AuthorID,
+			"title":           article.Title,
+			"description":     article.Description,
+			"content":         article.Content,
+			"created_at":      article.CreatedAt,
+			"updated_at":      article.UpdatedAt,
+			"views_count":     article.ViewsCount,
+			"comments_count":  article.CommentsCount,
+			"favorites_count": article.FavoritesCount,
+			"votes_up":        article.VotesUpCount,
+			"votes_down":      article.VotesDownCount,
+		},
+	})
+}
+
+type updateArticleInput struct {
+	ID          uuid.UUID `param:"id" validate:"required"`
+	Title       *string   `json:"title" validate:"omitempty,min=1,max=256"`
+	Description *string   `json:"description" validate:"omitempty,min=1,max=256"`
+	Content     *string   `json:"content" validate:"omitempty,min=1"`
+}
+
+func (r *articleRoutes) update(c echo.Context) error {
+	var input updateArticleInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.articleUseCase.UpdateArticle(c.Request().Context(), usecase.ArticleUpdateArticleInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+		ID:              input.ID,
+		NewTitle:        input.Title,
+		NewDescription:  input.Description,
+		NewContent:      input.Content,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+type deleteArticleInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *articleRoutes) delete(c echo.Context) error {
+	var input deleteArticleInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.articleUseCase.DeleteArticle(c.Request().Context(), usecase.ArticleDeleteArticleInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
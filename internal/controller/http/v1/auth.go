@@ -34,7 +34,6 @@ func (r *authRoutes) signUp(c echo.Context) error {
 
 	err := BindAndValidate(c, &input)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
@@ -44,12 +43,7 @@ func (r *authRoutes) signUp(c echo.Context) error {
 		Password: input.Password,
 		Email:    input.Email,
 	})
-	if err == usecase.ErrUserAlreadyExists {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		return err
-	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return err
 	}
 
@@ -69,7 +63,6 @@ func (r *authRoutes) signIn(c echo.Context) error {
 
 	err := BindAndValidate(c, &input)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
@@ -77,12 +70,7 @@ func (r *authRoutes) signIn(c echo.Context) error {
 		Username: input.Username,
 		Password: input.Password,
 	})
-	if err == usecase.ErrUserNotFound {
-		newErrorResponse(c, http.StatusBadRequest, "invalid username or password")
-		return err
-	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return err
 	}
 
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"blog-backend/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+const defaultCommentsLimit = 50
+
+type commentRoutes struct {
+	commentUseCase usecase.Comment
+}
+
+func newCommentRoutes(g *echo.Group, commentUseCase usecase.Comment) {
+	r := &commentRoutes{
+		commentUseCase: commentUseCase,
+	}
+
+	g.POST("/articles/:id/comments", r.create)
+	g.GET("/articles/:id/comments", r.getByArticle)
+	g.PUT("/comments/:id", r.update)
+	g.DELETE("/comments/:id", r.delete)
+}
+
+type createCommentInput struct {
+	ArticleID uuid.UUID  `param:"id" validate:"required"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	Content   string     `json:"content" validate:"required,max=4096"`
+}
+
+func (r *commentRoutes) create(c echo.Context) error {
+	var input createCommentInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	var parentID uuid.NullUUID
+	if input.ParentID != nil {
+		parentID = uuid.NullUUID{UUID: *input.ParentID, Valid: true}
+	}
+
+	commentID, err := r.commentUseCase.CreateComment(c.Request().Context(), usecase.CommentCreateCommentInput{
+		AuthorID:  c.Get(userIDCtx).(uuid.UUID),
+		ArticleID: input.ArticleID,
+		ParentID:  parentID,
+		Content:   input.Content,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id": commentID,
+	})
+}
+
+type getCommentsByArticleInput struct {
+	ArticleID uuid.UUID `param:"id" validate:"required"`
+	Limit     int       `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset    int       `query:"offset" validate:"omitempty,min=0"`
+}
+
+func (r *commentRoutes) getByArticle(c echo.Context) error {
+	var input getCommentsByArticleInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultCommentsLimit
+	}
+
+	comments, err := r.commentUseCase.GetCommentsByArticleID(c.Request().Context(), usecase.CommentGetCommentsByArticleIDInput{
+		ArticleID: input.ArticleID,
+		Limit:     input.Limit,
+		Offset:    input.Offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(comments))
+	for _, comment := range comments {
+		result = append(result, map[string]interface{}{
+			"id":         comment.Id,
+			"author_id":  comment.AuthorID,
+			"parent_id":  comment.ParentID,
+			"content":    comment.Content,
+			"created_at": comment.CreatedAt,
+			"updated_at": comment.UpdatedAt,
+			"votes_up":   comment.VotesUpCount,
+			"votes_down": comment.VotesDownCount,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"comments": result,
+	})
+}
+
+type updateCommentInput struct {
+	ID      uuid.UUID `param:"id" validate:"required"`
+	Content string    `json:"content" validate:"required,max=4096"`
+}
+
+func (r *commentRoutes) update(c echo.Context) error {
+	var input updateCommentInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.commentUseCase.UpdateComment(c.Request().Context(), usecase.CommentUpdateCommentInput{
+		ID:      input.ID,
+		Content: input.Content,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+type deleteCommentInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *commentRoutes) delete(c echo.Context) error {
+	var input deleteCommentInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.commentUseCase.DeleteComment(c.Request().Context(), usecase.CommentDeleteCommentInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Conditional - ETag по телу успешного GET ответа и Cache-Control, на If-None-Match с тем же ETag отдается 304.
+// Ответы доступны только с авторизацией, поэтому они private: браузер может их хранить, общие кеши - нет.
+// С нулевым maxAge браузер обязан перепроверять ответ, но с ETag это стоит только заголовков
+func Conditional(maxAge time.Duration) echo.MiddlewareFunc {
+	cacheControl := "private, no-cache"
+	if maxAge > 0 {
+		cacheControl = fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds()))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method != http.MethodGet {
+				return next(c)
+			}
+
+			res := c.Response()
+			buffered := &bufferedWriter{ResponseWriter: res.Writer}
+			res.Writer = buffered
+
+			err := next(c)
+
+			res.Writer = buffered.ResponseWriter
+
+			// errors are written later by the error handler
+			if buffered.status == 0 {
+				return err
+			}
+
+			if buffered.status == http.StatusOK {
+				etag := etagOf(buffered.body.Bytes())
+				res.Header().Set(echo.HeaderCacheControl, cacheControl)
+				res.Header().Set("ETag", etag)
+
+				if etagMatch(c.Request().Header.Get("If-None-Match"), etag) {
+					res.Header().Del(echo.HeaderContentType)
+					res.Status = http.StatusNotModified
+					res.Size = 0
+					res.Writer.WriteHeader(http.StatusNotModified)
+					return err
+				}
+			}
+
+			res.Writer.WriteHeader(buffered.status)
+			_, writeErr := res.Writer.Write(buffered.body.Bytes())
+			if err == nil {
+				err = writeErr
+			}
+
+			return err
+		}
+	}
+}
+
+// bufferedWriter - ответ задерживается, пока по всему телу не посчитан ETag
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatch - слабое сравнение, как требует RFC 9110 для If-None-Match
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
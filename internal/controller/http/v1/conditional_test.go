@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConditional(t *testing.T) {
+	handler := echo.New()
+	handler.HTTPErrorHandler = ErrorHandler
+
+	body := map[string]string{"title": "first"}
+	handler.GET("/articles/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, body)
+	}, Conditional(time.Minute))
+	handler.GET("/missing", func(c echo.Context) error {
+		return echo.ErrNotFound
+	}, Conditional(0))
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("/articles/1", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Body.Len() == 0 {
+		t.Fatalf("first response: status %d, etag %q, body %q", first.Code, etag, first.Body)
+	}
+	if got := first.Header().Get(echo.HeaderCacheControl); got != "private, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		rec := get("/articles/1", ifNoneMatch)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: status %d, body %q, want 304 without body", ifNoneMatch, rec.Code, rec.Body)
+		}
+		if rec.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: ETag = %q, want %q", ifNoneMatch, rec.Header().Get("ETag"), etag)
+		}
+	}
+
+	body["title"] = "changed"
+	changed := get("/articles/1", etag)
+	if changed.Code != http.StatusOK || changed.Header().Get("ETag") == etag {
+		t.Errorf("changed response: status %d, etag %q", changed.Code, changed.Header().Get("ETag"))
+	}
+
+	missing := get("/missing", "*")
+	if missing.Code != http.StatusNotFound || missing.Header().Get("ETag") != "" {
+		t.Errorf("error response: status %d, etag %q", missing.Code, missing.Header().Get("ETag"))
+	}
+}
@@ -1,21 +1,104 @@
 package v1
 
 import (
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/validator"
 	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
 )
 
+const mimeApplicationProblemJSON = "application/problem+json"
+
 var (
-	ErrInvalidRequestBody = fmt.Errorf("invalid request body")
+	ErrInvalidRequest   = apperror.New("invalid_request", http.StatusBadRequest, "invalid request")
+	ErrValidationFailed = apperror.New("validation_failed", http.StatusBadRequest, "request validation failed")
 )
 
-func newErrorResponse(c echo.Context, errStatus int, message string) {
-	err := errors.New(message)
-	_, ok := err.(*echo.HTTPError)
-	if !ok {
-		report := echo.NewHTTPError(errStatus, err.Error())
-		_ = c.JSON(errStatus, report)
+// problem - тело ответа об ошибке по RFC 7807, code - постоянный код ошибки для клиентов
+type problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Code     string                 `json:"code"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Errors   []validator.FieldError `json:"errors,omitempty"`
+}
+
+// ErrorHandler - единственное место, где ошибки превращаются в ответы: ошибки приложения отдаются
+// с их кодом и статусом, ошибки валидации - по полям, все остальные - как internal_error без подробностей
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	// the original error is logged by the request logger together with the request id
+	p := newProblem(err)
+	p.Instance = c.Request().URL.Path
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(p.Status)
+	} else {
+		c.Response().Header().Set(echo.HeaderContentType, mimeApplicationProblemJSON)
+		err = c.JSON(p.Status, p)
+	}
+	if err != nil {
+		logger.FromContext(c.Request().Context()).Errorf("ErrorHandler - c.JSON: %v", err)
+	}
+}
+
+func newProblem(err error) problem {
+	var validationErr *validator.ValidationError
+	if errors.As(err, &validationErr) {
+		p := problemOf(ErrValidationFailed)
+		p.Errors = validationErr.Fields
+		return p
+	}
+
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return problemOf(appErr)
+	}
+
+	// routing and middleware errors of echo
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		p := problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(httpErr.Code),
+			Status: httpErr.Code,
+			Code:   statusCode(httpErr.Code),
+		}
+		if message, ok := httpErr.Message.(string); ok && httpErr.Code < http.StatusInternalServerError {
+			p.Detail = message
+		}
+		return p
+	}
+
+	return problemOf(apperror.Internal)
+}
+
+func problemOf(err *apperror.Error) problem {
+	return problem{
+		Type:    "about:blank",
+		Title:   http.StatusText(err.Status),
+		Status:  err.Status,
+		Detail:  err.Message,
+		Code:    err.Code,
+		Details: err.Details,
+	}
+}
+
+// statusCode - код ошибки echo по статусу: "Not Found" - not_found
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return fmt.Sprintf("http_%d", status)
 	}
-	c.Error(errors.New("internal server error"))
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
 }
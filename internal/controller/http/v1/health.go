@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"blog-backend/pkg/health"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type healthRoutes struct {
+	health *health.Health
+}
+
+func newHealthRoutes(handler *echo.Echo, h *health.Health) {
+	r := &healthRoutes{
+		health: h,
+	}
+
+	handler.GET("/livez", r.livez)
+	handler.GET("/readyz", r.readyz)
+	// deprecated, kept for existing probes
+	handler.GET("/health", r.readyz)
+}
+
+// процесс работает и не требует перезапуска
+func (r *healthRoutes) livez(c echo.Context) error {
+	return healthResponse(c, r.health.Live(c.Request().Context()))
+}
+
+// экземпляр готов принимать запросы
+func (r *healthRoutes) readyz(c echo.Context) error {
+	return healthResponse(c, r.health.Ready(c.Request().Context()))
+}
+
+func healthResponse(c echo.Context, report health.Report) error {
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.JSON(status, report)
+}
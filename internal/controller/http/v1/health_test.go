@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"blog-backend/pkg/health"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealth_Readyz(t *testing.T) {
+	h := health.New()
+	handler := echo.New()
+	newHealthRoutes(handler, h)
+
+	get := func(target string) (int, health.Report) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		var report health.Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if rec.Header().Get(echo.HeaderCacheControl) != "no-store" {
+			t.Errorf("%s: probe response can be cached", target)
+		}
+		return rec.Code, report
+	}
+
+	if code, _ := get("/readyz"); code != http.StatusOK {
+		t.Fatalf("readyz = %d, want %d", code, http.StatusOK)
+	}
+
+	h.Shutdown()
+
+	for _, target := range []string{"/readyz", "/health"} {
+		if code, report := get(target); code != http.StatusServiceUnavailable || report.Status != health.StatusFail {
+			t.Errorf("%s after shutdown = %d %s, want %d", target, code, report.Status, http.StatusServiceUnavailable)
+		}
+	}
+	if code, _ := get("/livez"); code != http.StatusOK {
+		t.Errorf("livez after shutdown = %d, want %d", code, http.StatusOK)
+	}
+}
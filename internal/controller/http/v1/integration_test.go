@@ -0,0 +1,352 @@
+//go:build integration
+
+package v1_test
+
+import (
+	v1 "blog-backend/internal/controller/http/v1"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/outbox"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/testutil/pgtest"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/hasher"
+	"blog-backend/pkg/health"
+	"blog-backend/pkg/postgres"
+	"blog-backend/pkg/pubsub"
+	"blog-backend/pkg/validator"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Main(m))
+}
+
+// client - роутер со всеми зависимостями поверх базы с фикстурами, как в app.Run, но без фоновых задач
+type client struct {
+	t       *testing.T
+	handler *echo.Echo
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	pg := pgtest.NewWithFixtures(t)
+	repos := repo.NewRepositories(pg)
+
+	ps := pubsub.New(pg.Pool)
+	t.Cleanup(ps.Close)
+
+	authorizer, err := policy.New(policy.DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := metrics.New()
+	useCases := usecase.NewUseCases(usecase.UseCasesDependencies{
+		Repos:    repos,
+		Tx:       postgres.NewTxManager(pg),
+		Hasher:   hasher.NewSHA1Hasher(pgtest.Salt),
+		PubSub:   ps,
+		Events:   outbox.NewRelay(repos),
+		Policy:   authorizer,
+		Metrics:  m,
+		SignKey:  "test-sign-key",
+		TokenTTL: time.Hour,
+
+		AccountDeletionGracePeriod: time.Hour,
+	})
+
+	handler := echo.New()
+	handler.Validator = validator.NewCustomValidator()
+	v1.NewRouter(handler, useCases, authorizer, m, health.New(), 0)
+
+	return &client{t: t, handler: handler}
+}
+
+// do - запрос от имени пользователя с токеном token, пустой token - анонимный запрос
+func (c *client) do(method, target, token string, body interface{}) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	var payload string
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		payload = string(data)
+	}
+
+	req := httptest.NewRequest(method, target, strings.NewReader(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: "access-token", Value: token})
+	}
+
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (c *client) signIn(username string) string {
+	c.t.Helper()
+
+	rec := c.do(http.MethodPost, "/auth/sign-in", "", map[string]string{
+		"username": username,
+		"password": pgtest.Password,
+	})
+	if rec.Code != http.StatusOK {
+		c.t.Fatalf("sign in as %s: status %d, body %s", username, rec.Code, rec.Body)
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	decode(c.t, rec, &resp)
+
+	return resp.AccessToken
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	err := json.Unmarshal(rec.Body.Bytes(), v)
+	if err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	c := newClient(t)
+
+	rec := c.do(http.MethodPost, "/auth/sign-up", "", map[string]string{
+		"name":     "Dinah Cat",
+		"username": "dinah",
+		"password": pgtest.Password,
+		"email":    "dinah@example.com",
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("sign up: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	token := c.signIn("dinah")
+
+	rec = c.do(http.MethodGet, "/api/v1/users/dinah", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("own profile: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		name   string
+		body   map[string]string
+		status int
+		code   string
+	}{
+		{
+			name:   "sign up with a taken username",
+			body:   map[string]string{"name": "Alice", "username": "alice", "password": pgtest.Password, "email": "a@example.com"},
+			status: http.StatusBadRequest,
+			code:   "user_already_exists",
+		},
+		{
+			name:   "sign up with a weak password",
+			body:   map[string]string{"name": "Weak one", "username": "weakling", "password": "password", "email": "w@example.com"},
+			status: http.StatusBadRequest,
+			code:   "validation_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := c.do(http.MethodPost, "/auth/sign-up", "", tt.body)
+			assertProblem(t, rec, tt.status, tt.code)
+		})
+	}
+
+	rec = c.do(http.MethodPost, "/auth/sign-in", "", map[string]string{"username": "alice", "password": "Wrong-pass1!"})
+	assertProblem(t, rec, http.StatusBadRequest, "invalid_credentials")
+
+	rec = c.do(http.MethodPost, "/auth/sign-in", "", map[string]string{"username": "carol", "password": pgtest.Password})
+	assertProblem(t, rec, http.StatusBadRequest, "invalid_credentials")
+}
+
+func TestArticles(t *testing.T) {
+	c := newClient(t)
+	alice := c.signIn("alice")
+	bobby := c.signIn("bobby")
+	admin := c.signIn("admin")
+
+	rec := c.do(http.MethodPost, "/api/v1/articles", alice, map[string]string{
+		"title":       "Advice from a Caterpillar",
+		"description": "the fifth chapter",
+		"content":     "Who are you?",
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create article: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	decode(t, rec, &created)
+
+	rec = c.do(http.MethodGet, "/api/v1/articles/"+created.ID, bobby, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get article: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	var got struct {
+		Article struct {
+			ID       string `json:"id"`
+			AuthorID string `json:"author_id"`
+			Title    string `json:"title"`
+		} `json:"article"`
+	}
+	decode(t, rec, &got)
+	if got.Article.ID != created.ID || got.Article.AuthorID != pgtest.AliceID.String() || got.Article.Title != "Advice from a Caterpillar" {
+		t.Errorf("article = %+v", got.Article)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		token  string
+		body   interface{}
+		status int
+		code   string
+	}{
+		{
+			name:   "anonymous",
+			method: http.MethodGet,
+			target: "/api/v1/articles/" + created.ID,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "unknown article",
+			method: http.MethodGet,
+			target: "/api/v1/articles/" + pgtest.CarolArticleID.String(),
+			token:  alice,
+			status: http.StatusNotFound,
+			code:   "article_not_found",
+		},
+		{
+			name:   "hidden article for a user",
+			method: http.MethodGet,
+			target: "/api/v1/articles/" + pgtest.BobbyHiddenArticleID.String(),
+			token:  alice,
+			status: http.StatusNotFound,
+			code:   "article_not_found",
+		},
+		{
+			name:   "hidden article for an admin",
+			method: http.MethodGet,
+			target: "/api/v1/articles/" + pgtest.BobbyHiddenArticleID.String(),
+			token:  admin,
+			status: http.StatusOK,
+		},
+		{
+			name:   "update of another's article",
+			method: http.MethodPut,
+			target: "/api/v1/articles/" + created.ID,
+			token:  bobby,
+			body:   map[string]string{"title": "Bobby was here"},
+			status: http.StatusForbidden,
+			code:   "have_no_permission",
+		},
+		{
+			name:   "update of own article",
+			method: http.MethodPut,
+			target: "/api/v1/articles/" + created.ID,
+			token:  alice,
+			body:   map[string]string{"title": "Advice from a Caterpillar, revised"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "delete of another's article",
+			method: http.MethodDelete,
+			target: "/api/v1/articles/" + created.ID,
+			token:  bobby,
+			status: http.StatusForbidden,
+			code:   "have_no_permission",
+		},
+		{
+			name:   "delete of own article",
+			method: http.MethodDelete,
+			target: "/api/v1/articles/" + created.ID,
+			token:  alice,
+			status: http.StatusOK,
+		},
+		{
+			name:   "deleted article",
+			method: http.MethodGet,
+			target: "/api/v1/articles/" + created.ID,
+			token:  alice,
+			status: http.StatusNotFound,
+			code:   "article_not_found",
+		},
+	}
+
+	// cases change the article, subtests run in order
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := c.do(tt.method, tt.target, tt.token, tt.body)
+			if tt.code != "" {
+				assertProblem(t, rec, tt.status, tt.code)
+				return
+			}
+			if rec.Code != tt.status {
+				t.Errorf("status %d, want %d, body %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+func TestAdminAccess(t *testing.T) {
+	c := newClient(t)
+
+	rec := c.do(http.MethodGet, "/api/v1/admin/users", c.signIn("alice"), nil)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("user: status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec = c.do(http.MethodGet, "/api/v1/admin/users", c.signIn("admin"), nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("admin: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	c := newClient(t)
+
+	for _, target := range []string{"/livez", "/readyz"} {
+		rec := c.do(http.MethodGet, target, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status %d, body %s", target, rec.Code, rec.Body)
+		}
+	}
+}
+
+func assertProblem(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status %d, want %d, body %s", rec.Code, status, rec.Body)
+	}
+
+	var p struct {
+		Code string `json:"code"`
+	}
+	decode(t, rec, &p)
+	if p.Code != code {
+		t.Errorf("code %q, want %q", p.Code, code)
+	}
+}
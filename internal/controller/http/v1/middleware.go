@@ -1,26 +1,184 @@
 package v1
 
 import (
-	"blog-backend/internal/entity"
+	"blog-backend/internal/audit"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/policy"
 	"blog-backend/internal/usecase"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/logger"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"strings"
+	"time"
 )
 
+var tracer = otel.Tracer("blog-backend/internal/controller/http/v1")
+
 const (
-	userIDCtx   = "userID"
-	userRoleCtx = "userRole"
+	userIDCtx = "userID"
+
+	unmatchedRoute = "unmatched"
+
+	headerImpersonatedBy = "X-Impersonated-By"
+
+	// longer user agents are cut to fit the audit log column, longer request ids are replaced
+	maxUserAgentLength = 512
+	maxRequestIDLength = 128
+
+	// the only route available until a forced password reset is done
+	passwordChangePath = "/api/v1/users/password"
 )
 
+var errPasswordResetRequired = apperror.New("password_reset_required", http.StatusForbidden, "password reset required")
+
 type AuthMiddleware struct {
 	authUseCase usecase.Auth
+	authorizer  usecase.Authorizer
+}
+
+func NewAuthMiddleware(authUseCase usecase.Auth, authorizer usecase.Authorizer) *AuthMiddleware {
+	return &AuthMiddleware{authUseCase: authUseCase, authorizer: authorizer}
+}
+
+// Tracing - серверный спан на запрос, продолжает трассу из заголовка traceparent.
+// Текст ошибки в спан не пишется, только ее код, полная ошибка есть в логе запроса
+func Tracing(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		route := c.Path()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		ctx, span := tracer.Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(req.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.SetRequest(req.WithContext(ctx))
+
+		err := next(c)
+		// status is known only after the error is rendered, usually the request logger has done it already
+		if err != nil && !c.Response().Committed {
+			c.Error(err)
+		}
+
+		status := c.Response().Status
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if err != nil {
+			span.SetAttributes(attribute.String("error.code", apperror.From(err).Code))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		return err
+	}
+}
+
+// RequestID - id запроса из X-Request-ID клиента или прокси, если он подходит, иначе новый.
+// id возвращается в ответе, а в контекст запроса добавляется логгер с id, методом и маршрутом
+func RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		requestID := req.Header.Get(echo.HeaderXRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+			req.Header.Set(echo.HeaderXRequestID, requestID)
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+		fields := log.Fields{
+			logger.FieldRequestID: requestID,
+			logger.FieldMethod:    req.Method,
+			logger.FieldRoute:     c.Path(),
+		}
+		if spanContext := trace.SpanContextFromContext(req.Context()); spanContext.IsValid() {
+			fields[logger.FieldTraceID] = spanContext.TraceID().String()
+		}
+
+		c.SetRequest(req.WithContext(logger.WithFields(req.Context(), fields)))
+
+		return next(c)
+	}
+}
+
+// RequestMetrics - время обработки запроса по маршруту и статусу. Должен стоять до логгера запросов,
+// который вызывает ErrorHandler, иначе статус ответа на ошибку еще не известен
+func RequestMetrics(m *metrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			// route is empty for unknown paths, the path itself would create a series per scanned url
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+			m.ObserveHTTPRequest(c.Request().Method, route, c.Response().Status, time.Since(start))
+
+			return err
+		}
+	}
 }
 
-func NewAuthMiddleware(authUseCase usecase.Auth) *AuthMiddleware {
-	return &AuthMiddleware{authUseCase: authUseCase}
+// LogRequest - одна запись на запрос, ошибка пишется полностью, клиенту она отдается через ErrorHandler
+func LogRequest(c echo.Context, v middleware.RequestLoggerValues) error {
+	entry := logger.FromContext(c.Request().Context()).WithFields(log.Fields{
+		"uri":        v.URI,
+		"status":     v.Status,
+		"latency_ms": v.Latency.Milliseconds(),
+	})
+
+	if v.Error != nil {
+		entry = entry.WithError(v.Error)
+	}
+
+	if v.Status >= http.StatusInternalServerError {
+		entry.Error("request failed")
+	} else {
+		entry.Info("request")
+	}
+
+	return nil
+}
+
+// AuditRequest - данные запроса для журнала аудита добавляются в контекст запроса
+func AuditRequest(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		c.SetRequest(req.WithContext(audit.WithRequest(req.Context(), audit.Request{
+			IP:        c.RealIP(),
+			UserAgent: truncate(req.UserAgent(), maxUserAgentLength),
+			RequestID: req.Header.Get(echo.HeaderXRequestID),
+		})))
+
+		return next(c)
+	}
 }
 
 // Authorize - проверка авторизации пользователя
-// если пользователь авторизован, то в контекст запроса добавляется его id и роль (user, moderator, admin)
+// если пользователь авторизован, то в контекст добавляется его id, а в контекст запроса - субъект политики
+// с ролью (user, moderator, admin) и, при входе администратора от имени пользователя, id администратора
 func (h *AuthMiddleware) Authorize(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		cookie, err := c.Cookie("access-token")
@@ -30,49 +188,73 @@ func (h *AuthMiddleware) Authorize(next echo.HandlerFunc) echo.HandlerFunc {
 
 		token := cookie.Value
 
-		userID, role, err := h.authUseCase.ParseToken(c.Request().Context(), usecase.AuthParseTokenInput{
+		session, err := h.authUseCase.ParseToken(c.Request().Context(), usecase.AuthParseTokenInput{
 			Token: token,
 		})
 		if err != nil {
 			return echo.ErrForbidden
 		}
 
-		c.Set(userIDCtx, userID)
-		c.Set(userRoleCtx, role)
+		c.Set(userIDCtx, session.UserID)
 
-		return next(c)
-	}
-}
+		fields := log.Fields{logger.FieldUserID: session.UserID}
+		if session.ImpersonatorID.Valid {
+			fields["impersonator_id"] = session.ImpersonatorID.UUID
+		}
 
-func AdminOnly(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		role := c.Get(userRoleCtx).(entity.RoleType)
-		if role != entity.RoleAdmin {
-			return echo.ErrForbidden
+		ctx := policy.WithSubject(c.Request().Context(), policy.Subject{
+			ID:             session.UserID,
+			Role:           session.Role,
+			ImpersonatorID: session.ImpersonatorID,
+		})
+		c.SetRequest(c.Request().WithContext(logger.WithFields(ctx, fields)))
+
+		// impersonated requests are marked for the client, the audit log takes the admin from the subject
+		if session.ImpersonatorID.Valid {
+			c.Response().Header().Set(headerImpersonatedBy, session.ImpersonatorID.UUID.String())
+		}
+
+		// after a forced reset the temporary password has to be changed first
+		if session.PasswordResetRequired && c.Path() != passwordChangePath {
+			return errPasswordResetRequired
 		}
 
 		return next(c)
 	}
 }
 
-func ModeratorOnly(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		role := c.Get(userRoleCtx).(entity.RoleType)
-		if role != entity.RoleAdmin && role != entity.RoleModerator {
-			return echo.ErrForbidden
+// Require - доступ к группе маршрутов по действию политики, проверка конкретных ресурсов остается в use cases
+func (h *AuthMiddleware) Require(action policy.Action) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !h.authorizer.Can(c.Request().Context(), action, policy.Any) {
+				return echo.ErrForbidden
+			}
+
+			return next(c)
 		}
+	}
+}
 
-		return next(c)
+// truncate - обрезка по байтам без разрыва многобайтовых символов
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
 	}
+	return strings.ToValidUTF8(s[:n], "")
 }
 
-func UserOnly(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		role := c.Get(userRoleCtx).(entity.RoleType)
-		if role != entity.RoleAdmin && role != entity.RoleModerator && role != entity.RoleUser {
-			return echo.ErrForbidden
-		}
+// isValidRequestID - id от клиента попадает в логи и журнал аудита, поэтому принимается только короткий токен
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
 
-		return next(c)
+	for _, r := range requestID {
+		valid := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r)
+		if !valid {
+			return false
+		}
 	}
+	return true
 }
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"blog-backend/internal/metrics"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestMetrics_Labels(t *testing.T) {
+	m := metrics.New()
+
+	// the same order as in NewRouter, the request logger writes the error response
+	handler := echo.New()
+	handler.HTTPErrorHandler = ErrorHandler
+	handler.Use(RequestMetrics(m))
+	handler.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		HandleError:   true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error { return nil },
+	}))
+
+	handler.GET("/articles/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	handler.POST("/articles/:id/favorite", func(c echo.Context) error {
+		return errors.New("boom")
+	})
+
+	for _, r := range []struct{ method, target string }{
+		{http.MethodGet, "/articles/1"},
+		{http.MethodGet, "/articles/2"},
+		{http.MethodPost, "/articles/1/favorite"},
+		{http.MethodGet, "/wp-login.php"},
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.method, r.target, nil))
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		// the route template, not the path
+		`blog_http_request_duration_seconds_count{method="GET",route="/articles/:id",status="200"} 2`,
+		// the status written by the error handler
+		`blog_http_request_duration_seconds_count{method="POST",route="/articles/:id/favorite",status="500"} 1`,
+		`blog_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics have no %s", want)
+		}
+	}
+	if strings.Contains(body, "wp-login") {
+		t.Error("path of an unknown route is used as a label")
+	}
+}
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+}
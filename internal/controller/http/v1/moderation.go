@@ -0,0 +1,265 @@
+package v1
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+const defaultModerationLimit = 20
+
+type moderationRoutes struct {
+	moderationUseCase usecase.Moderation
+}
+
+func newModerationRoutes(g *echo.Group, moderationUseCase usecase.Moderation) {
+	r := &moderationRoutes{
+		moderationUseCase: moderationUseCase,
+	}
+
+	g.POST("/reports", r.createReport)
+
+	g.GET("/moderation/cases", r.getCases)
+	g.GET("/moderation/cases/:id", r.getCase)
+	g.POST("/moderation/cases/:id/claim", r.claimCase)
+	g.POST("/moderation/cases/:id/resolve", r.resolveCase)
+	g.GET("/moderation/log", r.getLog)
+}
+
+type createReportInput struct {
+	TargetType entity.ReportTargetType `json:"target_type" validate:"required,oneof=article comment user"`
+	TargetID   uuid.UUID               `json:"target_id" validate:"required"`
+	Reason     entity.ReportReason     `json:"reason" validate:"required,oneof=spam harassment hate_speech inappropriate copyright other"`
+	Comment    string                  `json:"comment" validate:"max=1024"`
+}
+
+func (r *moderationRoutes) createReport(c echo.Context) error {
+	var input createReportInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	report, err := r.moderationUseCase.CreateReport(c.Request().Context(), usecase.ModerationCreateReportInput{
+		ReporterID: c.Get(userIDCtx).(uuid.UUID),
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		Reason:     input.Reason,
+		Comment:    input.Comment,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id": report.ID,
+	})
+}
+
+type getCasesInput struct {
+	Status     *entity.ModerationStatus `query:"status" validate:"omitempty,oneof=open claimed resolved"`
+	TargetType *entity.ReportTargetType `query:"target_type" validate:"omitempty,oneof=article comment user"`
+	Limit      int                      `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset     int                      `query:"offset" validate:"omitempty,min=0"`
+}
+
+func (r *moderationRoutes) getCases(c echo.Context) error {
+	var input getCasesInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultModerationLimit
+	}
+
+	moderationCases, err := r.moderationUseCase.GetModerationCases(c.Request().Context(), usecase.ModerationGetModerationCasesInput{
+		Status:     input.Status,
+		TargetType: input.TargetType,
+		Limit:      input.Limit,
+		Offset:     input.Offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(moderationCases))
+	for _, moderationCase := range moderationCases {
+		result = append(result, moderationCaseResponse(moderationCase))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"cases": result,
+	})
+}
+
+type getCaseInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *moderationRoutes) getCase(c echo.Context) error {
+	var input getCaseInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	moderationCase, reports, err := r.moderationUseCase.GetModerationCase(c.Request().Context(), usecase.ModerationGetModerationCaseInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	reportsResult := make([]map[string]interface{}, 0, len(reports))
+	for _, report := range reports {
+		reportsResult = append(reportsResult, map[string]interface{}{
+			"id":          report.ID,
+			"reporter_id": report.ReporterID,
+			"reason":      report.Reason,
+			"comment":     report.Comment,
+			"created_at":  report.CreatedAt,
+		})
+	}
+
+	result := moderationCaseResponse(moderationCase)
+	result["reports"] = reportsResult
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"case": result,
+	})
+}
+
+type claimCaseInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *moderationRoutes) claimCase(c echo.Context) error {
+	var input claimCaseInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.moderationUseCase.ClaimModerationCase(c.Request().Context(), usecase.ModerationClaimModerationCaseInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+		ID:              input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+type resolveCaseInput struct {
+	ID     uuid.UUID               `param:"id" validate:"required"`
+	Action entity.ModerationAction `json:"action" validate:"required,oneof=dismiss hide delete ban"`
+	Note   string                  `json:"note" validate:"max=1024"`
+}
+
+func (r *moderationRoutes) resolveCase(c echo.Context) error {
+	var input resolveCaseInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.moderationUseCase.ResolveModerationCase(c.Request().Context(), usecase.ModerationResolveModerationCaseInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+		ID:              input.ID,
+		Action:          input.Action,
+		Note:            input.Note,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+type getLogInput struct {
+	ModeratorID *uuid.UUID `query:"moderator_id"`
+	CaseID      *uuid.UUID `query:"case_id"`
+	Limit       int        `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset      int        `query:"offset" validate:"omitempty,min=0"`
+}
+
+func (r *moderationRoutes) getLog(c echo.Context) error {
+	var input getLogInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultModerationLimit
+	}
+
+	var moderatorID, caseID uuid.NullUUID
+	if input.ModeratorID != nil {
+		moderatorID = uuid.NullUUID{UUID: *input.ModeratorID, Valid: true}
+	}
+	if input.CaseID != nil {
+		caseID = uuid.NullUUID{UUID: *input.CaseID, Valid: true}
+	}
+
+	entries, err := r.moderationUseCase.GetModerationLog(c.Request().Context(), usecase.ModerationGetModerationLogInput{
+		ModeratorID: moderatorID,
+		CaseID:      caseID,
+		Limit:       input.Limit,
+		Offset:      input.Offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, map[string]interface{}{
+			"id":           entry.ID,
+			"case_id":      entry.CaseID,
+			"moderator_id": entry.ModeratorID,
+			"action":       entry.Action,
+			"target_type":  entry.TargetType,
+			"target_id":    entry.TargetID,
+			"note":         entry.Note,
+			"created_at":   entry.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"log": result,
+	})
+}
+
+func moderationCaseResponse(moderationCase entity.ModerationCase) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               moderationCase.ID,
+		"target_type":      moderationCase.TargetType,
+		"target_id":        moderationCase.TargetID,
+		"target_author_id": moderationCase.TargetAuthorID,
+		"status":           moderationCase.Status,
+		"reports_count":    moderationCase.ReportsCount,
+		"assignee_id":      moderationCase.AssigneeID,
+		"claimed_at":       moderationCase.ClaimedAt,
+		"resolution":       moderationCase.Resolution,
+		"resolved_by":      moderationCase.ResolvedBy,
+		"resolved_at":      moderationCase.ResolvedAt,
+		"created_at":       moderationCase.CreatedAt,
+		"updated_at":       moderationCase.UpdatedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"blog-backend/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+const defaultNotificationsLimit = 20
+
+type notificationRoutes struct {
+	notificationUseCase usecase.Notification
+}
+
+func newNotificationRoutes(g *echo.Group, notificationUseCase usecase.Notification) {
+	r := &notificationRoutes{
+		notificationUseCase: notificationUseCase,
+	}
+
+	g.GET("/notifications", r.getNotifications)
+}
+
+type getNotificationsInput struct {
+	Limit  int `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int `query:"offset" validate:"omitempty,min=0"`
+}
+
+func (r *notificationRoutes) getNotifications(c echo.Context) error {
+	var input getNotificationsInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultNotificationsLimit
+	}
+
+	notifications, err := r.notificationUseCase.GetNotifications(c.Request().Context(), usecase.NotificationGetNotificationsInput{
+		UserID: c.Get(userIDCtx).(uuid.UUID),
+		Limit:  input.Limit,
+		Offset: input.Offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(notifications))
+	for _, notification := range notifications {
+		result = append(result, map[string]interface{}{
+			"id":         notification.ID,
+			"type":       notification.Type,
+			"actor_id":   notification.ActorID,
+			"article_id": notification.ArticleID,
+			"comment_id": notification.CommentID,
+			"created_at": notification.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"notifications": result,
+	})
+}
@@ -1,19 +1,34 @@
 package v1
 
 import (
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/policy"
 	"blog-backend/internal/usecase"
+	"blog-backend/pkg/health"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"net/http"
+	"time"
 )
 
-func NewRouter(handler *echo.Echo, useCases *usecase.UseCases) {
-	handler.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: `{"time":"${time_rfc3339_nano}", "method":"${method}","uri":"${uri}", "status":${status},"error":"${error}"}` + "\n",
+func NewRouter(handler *echo.Echo, useCases *usecase.UseCases, authorizer usecase.Authorizer, m *metrics.Metrics, h *health.Health, cacheMaxAge time.Duration) {
+	handler.HTTPErrorHandler = ErrorHandler
+
+	handler.Use(Tracing)
+	handler.Use(RequestID)
+	handler.Use(RequestMetrics(m))
+	handler.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		Skipper:       isProbe,
+		LogURI:        true,
+		LogStatus:     true,
+		LogLatency:    true,
+		LogError:      true,
+		HandleError:   true,
+		LogValuesFunc: LogRequest,
 	}))
 	handler.Use(middleware.Recover())
+	handler.Use(AuditRequest)
 
-	handler.GET("/health", func(c echo.Context) error { return c.String(http.StatusOK, "OK") })
+	newHealthRoutes(handler, h)
 	handler.Static("/swagger-ui", "docs/swagger-ui")
 
 	auth := handler.Group("/auth")
@@ -21,10 +36,32 @@ func NewRouter(handler *echo.Echo, useCases *usecase.UseCases) {
 		newAuthRoutes(auth, useCases.Auth, useCases.User)
 	}
 
-	authMiddleware := NewAuthMiddleware(useCases.Auth)
+	authMiddleware := NewAuthMiddleware(useCases.Auth, authorizer)
 	v1 := handler.Group("/api/v1", authMiddleware.Authorize)
 	{
-		newUserRoutes(v1, useCases.User)
-		newArticleRoutes(v1, useCases.Article)
+		conditional := Conditional(cacheMaxAge)
+
+		newUserRoutes(v1, useCases.User, conditional)
+		newAccountRoutes(v1, useCases.Account)
+		newArticleRoutes(v1, useCases.Article, conditional)
+		newCommentRoutes(v1, useCases.Comment)
+		newNotificationRoutes(v1, useCases.Notification)
+		newStreamRoutes(v1, useCases.Stream)
+		newWebhookRoutes(v1, useCases.Webhook)
+		newModerationRoutes(v1, useCases.Moderation)
+	}
+
+	admin := v1.Group("/admin", authMiddleware.Require(policy.AdminAccess))
+	{
+		newAdminRoutes(admin, useCases.Admin, useCases.User, useCases.Article, useCases.Comment)
+	}
+}
+
+// isProbe - пробы приходят каждые несколько секунд и засоряли бы лог запросов
+func isProbe(c echo.Context) bool {
+	switch c.Path() {
+	case "/livez", "/readyz", "/health":
+		return true
 	}
+	return false
 }
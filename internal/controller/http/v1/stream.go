@@ -0,0 +1,251 @@
+package v1
+
+import (
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/pubsub"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+const (
+	streamWriteTimeout = 10 * time.Second
+	streamPingInterval = 30 * time.Second
+	wsPongTimeout      = streamPingInterval + streamWriteTimeout
+	wsMaxMessageSize   = 1024
+)
+
+type streamRoutes struct {
+	streamUseCase usecase.Stream
+	upgrader      websocket.Upgrader
+}
+
+func newStreamRoutes(g *echo.Group, streamUseCase usecase.Stream) {
+	r := &streamRoutes{
+		streamUseCase: streamUseCase,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+
+	g.GET("/stream/notifications", r.notificationsSSE)
+	g.GET("/stream/articles/:id/comments", r.articleCommentsSSE)
+	g.GET("/ws", r.websocket)
+}
+
+func (r *streamRoutes) notificationsSSE(c echo.Context) error {
+	sub, err := r.streamUseCase.Subscribe(c.Request().Context(), usecase.StreamSubscribeInput{
+		UserID:        c.Get(userIDCtx).(uuid.UUID),
+		Notifications: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return serveSSE(c, sub)
+}
+
+type articleCommentsSSEInput struct {
+	ArticleID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *streamRoutes) articleCommentsSSE(c echo.Context) error {
+	var input articleCommentsSSEInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	sub, err := r.streamUseCase.Subscribe(c.Request().Context(), usecase.StreamSubscribeInput{
+		UserID:     c.Get(userIDCtx).(uuid.UUID),
+		ArticleIDs: []uuid.UUID{input.ArticleID},
+	})
+	if err != nil {
+		return err
+	}
+
+	return serveSSE(c, sub)
+}
+
+// serveSSE - отправка сообщений подписки в виде text/event-stream до закрытия подписки или соединения
+func serveSSE(c echo.Context, sub *pubsub.Subscription) error {
+	defer sub.Close()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	// the server write timeout applies to the whole response, so it is extended before every write
+	rc := http.NewResponseController(w)
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+
+		case msg, ok := <-sub.Messages():
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if !ok {
+				if sub.Err() != nil {
+					_, _ = fmt.Fprintf(w, "event: close\ndata: %q\n\n", sub.Err().Error())
+					w.Flush()
+				}
+				return nil
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Type, msg.Data)
+
+		case <-ping.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		}
+
+		if err != nil {
+			return nil
+		}
+		w.Flush()
+	}
+}
+
+type wsRequest struct {
+	Action    string    `json:"action"`
+	ArticleID uuid.UUID `json:"article_id"`
+}
+
+type wsError struct {
+	Type  string `json:"type"`
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+var errUnknownWSAction = apperror.New("unknown_action", http.StatusBadRequest, "unknown action")
+
+// websocket - notifications of the user are streamed right after connection,
+// comments of the articles are streamed after {"action": "subscribe", "article_id": "..."}
+func (r *streamRoutes) websocket(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	sub, err := r.streamUseCase.Subscribe(ctx, usecase.StreamSubscribeInput{
+		UserID:        c.Get(userIDCtx).(uuid.UUID),
+		Notifications: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	conn, err := r.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// upgrader has already written the error response
+		return nil
+	}
+	defer func() { _ = conn.Close() }()
+
+	replies := make(chan wsError, 1)
+	go r.wsReadLoop(c, conn, sub, replies)
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+
+	for {
+		_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+		select {
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				closeCode, reason := websocket.CloseNormalClosure, ""
+				if sub.Err() == pubsub.ErrSlowConsumer {
+					closeCode, reason = websocket.ClosePolicyViolation, sub.Err().Error()
+				} else if sub.Err() == pubsub.ErrClosed {
+					closeCode, reason = websocket.CloseGoingAway, "server is shutting down"
+				}
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, reason))
+				return nil
+			}
+			err = conn.WriteJSON(msg)
+
+		case reply := <-replies:
+			err = conn.WriteJSON(reply)
+
+		case <-ping.C:
+			err = conn.WriteMessage(websocket.PingMessage, nil)
+		}
+
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// wsReadLoop - обработка запросов клиента, при разрыве соединения закрывает подписку
+func (r *streamRoutes) wsReadLoop(c echo.Context, conn *websocket.Conn, sub *pubsub.Subscription, replies chan<- wsError) {
+	defer sub.Close()
+
+	ctx := c.Request().Context()
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		err = json.Unmarshal(data, &req)
+		if err != nil {
+			r.wsReply(replies, ErrInvalidRequest)
+			continue
+		}
+
+		switch req.Action {
+		case "subscribe":
+			err = r.streamUseCase.SubscribeArticleComments(ctx, usecase.StreamSubscribeArticleCommentsInput{
+				Subscription: sub,
+				ArticleID:    req.ArticleID,
+			})
+			if err != nil {
+				appErr := apperror.From(err)
+				if appErr.Status >= http.StatusInternalServerError {
+					logger.FromContext(ctx).Errorf("streamRoutes.wsReadLoop - r.streamUseCase.SubscribeArticleComments: %v", err)
+				}
+				r.wsReply(replies, appErr)
+			}
+		case "unsubscribe":
+			r.streamUseCase.UnsubscribeArticleComments(ctx, usecase.StreamUnsubscribeArticleCommentsInput{
+				Subscription: sub,
+				ArticleID:    req.ArticleID,
+			})
+		default:
+			r.wsReply(replies, errUnknownWSAction)
+		}
+	}
+}
+
+// wsReply - error replies are dropped if the client doesn't read them fast enough
+func (r *streamRoutes) wsReply(replies chan<- wsError, err *apperror.Error) {
+	select {
+	case replies <- wsError{Type: "error", Code: err.Code, Error: err.Message}:
+	default:
+	}
+}
@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"blog-backend/internal/usecase"
+	"blog-backend/internal/usecase/mocks"
+	"blog-backend/pkg/pubsub"
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStreamServer - маршруты стримов с пользователем userID, done закрывается, когда обработчик завершился
+func newStreamServer(t *testing.T, userID uuid.UUID, sub *pubsub.Subscription) (*httptest.Server, <-chan struct{}) {
+	t.Helper()
+
+	stream := mocks.NewMockStream(gomock.NewController(t))
+	stream.EXPECT().Subscribe(gomock.Any(), usecase.StreamSubscribeInput{UserID: userID, Notifications: true}).
+		Return(sub, nil)
+
+	done := make(chan struct{})
+	handler := echo.New()
+	g := handler.Group("", func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			defer close(done)
+			c.Set(userIDCtx, userID)
+			return next(c)
+		}
+	})
+	newStreamRoutes(g, stream)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server, done
+}
+
+// readEvent - одно событие text/event-stream без комментариев
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var event, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && event != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func waitDone(t *testing.T, done <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler is still running")
+	}
+}
+
+func TestStream_NotificationsSSE(t *testing.T) {
+	userID := uuid.New()
+	p := pubsub.New(nil, pubsub.BufferSize(1))
+	defer p.Close()
+
+	sub, err := p.Subscribe("notifications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, done := newStreamServer(t, userID, sub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/stream/notifications", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(echo.HeaderContentType) != "text/event-stream" {
+		t.Fatalf("status %d, content type %q", resp.StatusCode, resp.Header.Get(echo.HeaderContentType))
+	}
+
+	err = p.Publish(context.Background(), pubsub.Message{
+		Topic: "notifications",
+		Type:  "notification",
+		Data:  json.RawMessage(`{"id":"1"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, data := readEvent(t, bufio.NewReader(resp.Body))
+	if event != "notification" || data != `{"id":"1"}` {
+		t.Errorf("event %q, data %q", event, data)
+	}
+
+	// the client goes away, the handler must return and release the subscription
+	cancel()
+	waitDone(t, done)
+
+	if _, ok := <-sub.Messages(); ok {
+		t.Error("subscription is open after the request is cancelled")
+	}
+}
+
+func TestStream_SSEClose(t *testing.T) {
+	userID := uuid.New()
+	p := pubsub.New(nil)
+
+	sub, err := p.Subscribe("notifications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, done := newStreamServer(t, userID, sub)
+
+	resp, err := http.Get(server.URL + "/stream/notifications")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// shutdown closes every subscription with a reason the client sees
+	p.Close()
+
+	event, data := readEvent(t, bufio.NewReader(resp.Body))
+	if event != "close" || data != `"pubsub is closed"` {
+		t.Errorf("event %q, data %q", event, data)
+	}
+	waitDone(t, done)
+}
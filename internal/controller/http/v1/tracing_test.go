@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"blog-backend/pkg/tracing"
+	"context"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tr, err := tracing.New("test", "0.0.0", tracing.Exporter(exporter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tr.Shutdown(context.Background()) }()
+
+	handler := echo.New()
+	handler.HTTPErrorHandler = ErrorHandler
+	handler.Use(Tracing)
+	handler.GET("/articles/:id", func(c echo.Context) error {
+		_, span := otel.Tracer("test").Start(c.Request().Context(), "Article.GetArticleByID")
+		span.End()
+		return c.NoContent(http.StatusOK)
+	})
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/42", nil)
+	req.Header.Set("traceparent", traceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	child, server := spans[0], spans[1]
+	if server.Name != "GET /articles/:id" {
+		t.Errorf("server span name = %q", server.Name)
+	}
+	if got := server.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("server span does not continue the incoming trace, trace id = %s", got)
+	}
+	if child.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("use case span is not a child of the server span")
+	}
+	if !hasAttribute(server.Attributes, attribute.Int("http.response.status_code", http.StatusOK)) {
+		t.Errorf("server span has no status code attribute: %v", server.Attributes)
+	}
+
+	exporter.Reset()
+
+	handler.GET("/fail", func(c echo.Context) error {
+		return context.DeadlineExceeded
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	spans = exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("failed request span status = %v, want error", spans[0].Status.Code)
+	}
+	if !hasAttribute(spans[0].Attributes, attribute.String("error.code", "internal_error")) {
+		t.Errorf("failed request span has no error code: %v", spans[0].Attributes)
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
@@ -12,13 +12,14 @@ type userRoutes struct {
 	userUseCase usecase.User
 }
 
-func newUserRoutes(g *echo.Group, userUseCase usecase.User) {
+func newUserRoutes(g *echo.Group, userUseCase usecase.User, conditional echo.MiddlewareFunc) {
 	r := &userRoutes{
 		userUseCase: userUseCase,
 	}
 
-	g.GET("/users/:username", r.getUser)
+	g.GET("/users/:username", r.getUser, conditional)
 	g.PUT("/users/:username", r.updateUser)
+	g.DELETE("/users/:username", r.deleteUser)
 	g.PUT("/users/password", r.updateUserPassword)
 }
 
@@ -35,25 +36,18 @@ func (r *userRoutes) updateUser(c echo.Context) error {
 
 	err := BindAndValidate(c, &input)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
-	requestedUserRole := c.Get(userRoleCtx).(entity.RoleType)
-	requestedUserID := c.Get(userIDCtx).(uuid.UUID)
-
 	err = r.userUseCase.UpdateUser(c.Request().Context(), usecase.UserUpdateUserInput{
-		RequestedUserID:   requestedUserID,
-		RequestedUserRole: requestedUserRole,
-		Username:          input.Username,
-		NewName:           input.Name,
-		NewEmail:          input.Email,
-		NewRole:           input.Role,
-		NewDescription:    input.Description,
+		Username:       input.Username,
+		NewName:        input.Name,
+		NewEmail:       input.Email,
+		NewRole:        input.Role,
+		NewDescription: input.Description,
 	})
 
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return err
 	}
 
@@ -72,7 +66,6 @@ func (r *userRoutes) updateUserPassword(c echo.Context) error {
 
 	err := BindAndValidate(c, &input)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
@@ -84,16 +77,7 @@ func (r *userRoutes) updateUserPassword(c echo.Context) error {
 		NewPassword: input.NewPassword,
 	})
 
-	if err == usecase.ErrUserNotFound {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		return err
-	}
-	if err == usecase.ErrCannotUpdatePasswordToIdentical {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
-		return err
-	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return err
 	}
 
@@ -111,19 +95,13 @@ func (r *userRoutes) getUser(c echo.Context) error {
 
 	err := BindAndValidate(c, &input)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
 	user, err := r.userUseCase.GetUserByUsername(c.Request().Context(), usecase.UserGetUserByUsernameInput{
 		Username: input.Username,
 	})
-	if err == usecase.ErrUserNotFound {
-		newErrorResponse(c, http.StatusNotFound, err.Error())
-		return err
-	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return err
 	}
 
@@ -137,3 +115,27 @@ func (r *userRoutes) getUser(c echo.Context) error {
 		},
 	})
 }
+
+type deleteUserInput struct {
+	Username string `param:"username" validate:"required,min=3,max=256"`
+}
+
+func (r *userRoutes) deleteUser(c echo.Context) error {
+	var input deleteUserInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.userUseCase.DeleteUser(c.Request().Context(), usecase.UserDeleteUserInput{
+		Username: input.Username,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -1,10 +1,22 @@
 package v1
 
-import "github.com/labstack/echo/v4"
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
 
+// BindAndValidate - ошибки разбора запроса возвращаются как ErrInvalidRequest, ошибки правил - по полям
 func BindAndValidate(c echo.Context, i any) error {
 	err := c.Bind(i)
 	if err != nil {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == http.StatusBadRequest {
+			if message, ok := httpErr.Message.(string); ok {
+				return ErrInvalidRequest.WithDetails(map[string]interface{}{"reason": message}).Wrap(err)
+			}
+			return ErrInvalidRequest.Wrap(err)
+		}
 		return err
 	}
 
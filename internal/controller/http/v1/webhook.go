@@ -0,0 +1,222 @@
+package v1
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+const defaultDeliveriesLimit = 20
+
+type webhookRoutes struct {
+	webhookUseCase usecase.Webhook
+}
+
+func newWebhookRoutes(g *echo.Group, webhookUseCase usecase.Webhook) {
+	r := &webhookRoutes{
+		webhookUseCase: webhookUseCase,
+	}
+
+	g.POST("/webhooks", r.create)
+	g.GET("/webhooks", r.getWebhooks)
+	g.PUT("/webhooks/:id", r.update)
+	g.DELETE("/webhooks/:id", r.delete)
+	g.GET("/webhooks/:id/deliveries", r.getDeliveries)
+	g.POST("/webhooks/:id/deliveries/:deliveryID/redeliver", r.redeliver)
+}
+
+type createWebhookInput struct {
+	URL        string             `json:"url" validate:"required,url,max=2048"`
+	EventTypes []entity.EventType `json:"event_types"`
+	Global     bool               `json:"global"`
+}
+
+func (r *webhookRoutes) create(c echo.Context) error {
+	var input createWebhookInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := r.webhookUseCase.CreateWebhook(c.Request().Context(), usecase.WebhookCreateWebhookInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+		URL:             input.URL,
+		EventTypes:      input.EventTypes,
+		Global:          input.Global,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := webhookResponse(webhook)
+	// secret is shown only once
+	result["secret"] = webhook.Secret
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"webhook": result,
+	})
+}
+
+func (r *webhookRoutes) getWebhooks(c echo.Context) error {
+	webhooks, err := r.webhookUseCase.GetWebhooks(c.Request().Context(), usecase.WebhookGetWebhooksInput{
+		RequestedUserID: c.Get(userIDCtx).(uuid.UUID),
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		result = append(result, webhookResponse(webhook))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"webhooks": result,
+	})
+}
+
+type updateWebhookInput struct {
+	ID         uuid.UUID           `param:"id" validate:"required"`
+	URL        *string             `json:"url" validate:"omitempty,url,max=2048"`
+	EventTypes *[]entity.EventType `json:"event_types"`
+	Active     *bool               `json:"active"`
+}
+
+func (r *webhookRoutes) update(c echo.Context) error {
+	var input updateWebhookInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.webhookUseCase.UpdateWebhook(c.Request().Context(), usecase.WebhookUpdateWebhookInput{
+		ID:            input.ID,
+		NewURL:        input.URL,
+		NewEventTypes: input.EventTypes,
+		NewActive:     input.Active,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+type deleteWebhookInput struct {
+	ID uuid.UUID `param:"id" validate:"required"`
+}
+
+func (r *webhookRoutes) delete(c echo.Context) error {
+	var input deleteWebhookInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.webhookUseCase.DeleteWebhook(c.Request().Context(), usecase.WebhookDeleteWebhookInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+type getDeliveriesInput struct {
+	ID     uuid.UUID `param:"id" validate:"required"`
+	Limit  int       `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int       `query:"offset" validate:"omitempty,min=0"`
+}
+
+func (r *webhookRoutes) getDeliveries(c echo.Context) error {
+	var input getDeliveriesInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultDeliveriesLimit
+	}
+
+	deliveries, err := r.webhookUseCase.GetDeliveries(c.Request().Context(), usecase.WebhookGetDeliveriesInput{
+		WebhookID: input.ID,
+		Limit:     input.Limit,
+		Offset:    input.Offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, map[string]interface{}{
+			"id":               delivery.ID,
+			"event_id":         delivery.EventID,
+			"event_type":       delivery.EventType,
+			"payload":          delivery.Payload,
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"last_attempt_at":  delivery.LastAttemptAt,
+			"delivered_at":     delivery.DeliveredAt,
+			"created_at":       delivery.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"deliveries": result,
+	})
+}
+
+type redeliverInput struct {
+	ID         uuid.UUID `param:"id" validate:"required"`
+	DeliveryID uuid.UUID `param:"deliveryID" validate:"required"`
+}
+
+func (r *webhookRoutes) redeliver(c echo.Context) error {
+	var input redeliverInput
+
+	err := BindAndValidate(c, &input)
+	if err != nil {
+		return err
+	}
+
+	err = r.webhookUseCase.Redeliver(c.Request().Context(), usecase.WebhookRedeliverInput{
+		WebhookID:  input.ID,
+		DeliveryID: input.DeliveryID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+func webhookResponse(webhook entity.Webhook) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          webhook.ID,
+		"owner_id":    webhook.OwnerID,
+		"url":         webhook.URL,
+		"event_types": webhook.EventTypes,
+		"global":      webhook.IsGlobal,
+		"active":      webhook.Active,
+		"created_at":  webhook.CreatedAt,
+		"updated_at":  webhook.UpdatedAt,
+	}
+}
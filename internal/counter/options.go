@@ -0,0 +1,27 @@
+package counter
+
+import (
+	"blog-backend/internal/metrics"
+	"time"
+)
+
+type Option func(*Reconciler)
+
+func Interval(interval time.Duration) Option {
+	return func(r *Reconciler) {
+		r.interval = interval
+	}
+}
+
+// Fix - без него расхождения только попадают в лог и метрики
+func Fix(fix bool) Option {
+	return func(r *Reconciler) {
+		r.fix = fix
+	}
+}
+
+func Metrics(m *metrics.Metrics) Option {
+	return func(r *Reconciler) {
+		r.metrics = m
+	}
+}
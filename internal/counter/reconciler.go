@@ -0,0 +1,100 @@
+package counter
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/repo"
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const defaultInterval = 24 * time.Hour
+
+// Reconciler - периодическая сверка счетчиков с исходными таблицами.
+// Счетчики меняются в транзакциях вместе с исходными строками, сверка чинит то, что разошлось в обход репозиториев
+type Reconciler struct {
+	counterRepo repo.Counter
+
+	interval time.Duration
+	fix      bool
+	metrics  *metrics.Metrics
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewReconciler(counterRepo repo.Counter, opts ...Option) *Reconciler {
+	r := &Reconciler{
+		counterRepo: counterRepo,
+		interval:    defaultInterval,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.run(ctx)
+
+	return r
+}
+
+func (r *Reconciler) Close() {
+	r.cancel()
+	<-r.done
+}
+
+func (r *Reconciler) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := r.Reconcile(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf("Reconciler.run - r.Reconcile: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile - одна сверка, каждое расхождение пишется в лог и считается в метриках
+func (r *Reconciler) Reconcile(ctx context.Context) ([]entity.CounterDrift, error) {
+	drift, err := r.counterRepo.ReconcileCounters(ctx, r.fix)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile counters: %w", err)
+	}
+
+	if len(drift) == 0 {
+		return nil, nil
+	}
+
+	action := "found"
+	if r.fix {
+		action = "fixed"
+	}
+
+	perColumn := make(map[[2]string]int)
+	for _, d := range drift {
+		log.Warnf("Reconciler.Reconcile - %s.%s of %s is %d, expected %d", d.Table, d.Column, d.ID, d.Stored, d.Actual)
+		perColumn[[2]string{d.Table, d.Column}]++
+	}
+	for column, n := range perColumn {
+		r.metrics.CounterDrift(column[0], column[1], n)
+	}
+
+	log.Warnf("Reconciler.Reconcile - %s %d drifted counters", action, len(drift))
+
+	return drift, nil
+}
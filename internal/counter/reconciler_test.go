@@ -0,0 +1,140 @@
+package counter_test
+
+import (
+	"blog-backend/internal/counter"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/metrics"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCounterRepo - repo.Counter, записывающий значения fix и возвращающий drift и err
+type fakeCounterRepo struct {
+	fixes []bool
+	drift []entity.CounterDrift
+	err   error
+}
+
+func (r *fakeCounterRepo) ReconcileCounters(ctx context.Context, fix bool) ([]entity.CounterDrift, error) {
+	r.fixes = append(r.fixes, fix)
+	return r.drift, r.err
+}
+
+// newReconciler - reconciler без фонового цикла: первая сверка run завершается до того, как тест настроит repo
+func newReconciler(r *fakeCounterRepo, opts ...counter.Option) *counter.Reconciler {
+	c := counter.NewReconciler(r, append([]counter.Option{counter.Interval(time.Hour)}, opts...)...)
+	c.Close()
+
+	r.fixes = nil
+	return c
+}
+
+func TestReconciler_Drift(t *testing.T) {
+	drift := []entity.CounterDrift{
+		{Table: "articles", Column: "favorites_count", ID: uuid.New(), Stored: 2, Actual: 1},
+		{Table: "users", Column: "followers_count", ID: uuid.New(), Stored: 0, Actual: 1},
+		{Table: "users", Column: "followers_count", ID: uuid.New(), Stored: 3, Actual: 1},
+	}
+
+	tests := []struct {
+		name    string
+		fix     bool
+		summary string
+	}{
+		// drift is only reported
+		{name: "dry run", fix: false, summary: "Reconciler.Reconcile - found 3 drifted counters"},
+		// drift is reported the same way and the repo fixes it
+		{name: "fix", fix: true, summary: "Reconciler.Reconcile - fixed 3 drifted counters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.New()
+			r := &fakeCounterRepo{}
+			c := newReconciler(r, counter.Fix(tt.fix), counter.Metrics(m))
+			r.drift = drift
+			hook := test.NewGlobal()
+			defer hook.Reset()
+
+			got, err := c.Reconcile(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, drift) {
+				t.Errorf("drift = %+v, want %+v", got, drift)
+			}
+			if want := []bool{tt.fix}; !reflect.DeepEqual(r.fixes, want) {
+				t.Errorf("fix = %v, want %v", r.fixes, want)
+			}
+
+			// every drifted counter and the summary are logged
+			if n := len(hook.AllEntries()); n != len(drift)+1 {
+				t.Errorf("logged %d entries, want %d", n, len(drift)+1)
+			}
+			if entry := hook.LastEntry(); entry == nil || entry.Message != tt.summary {
+				t.Errorf("last log entry = %v, want %q", entry, tt.summary)
+			}
+
+			body := scrape(t, m)
+			for _, want := range []string{
+				`blog_counters_drift_total{column="favorites_count",table="articles"} 1`,
+				`blog_counters_drift_total{column="followers_count",table="users"} 2`,
+			} {
+				if !strings.Contains(body, want) {
+					t.Errorf("metrics have no %s", want)
+				}
+			}
+		})
+	}
+}
+
+func TestReconciler_NoDrift(t *testing.T) {
+	m := metrics.New()
+	r := &fakeCounterRepo{}
+	c := newReconciler(r, counter.Metrics(m))
+
+	drift, err := c.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift != nil {
+		t.Errorf("drift = %+v, want none", drift)
+	}
+	if body := scrape(t, m); strings.Contains(body, "blog_counters_drift_total{") {
+		t.Error("drift is counted when there is none")
+	}
+}
+
+func TestReconciler_Error(t *testing.T) {
+	r := &fakeCounterRepo{}
+	c := newReconciler(r)
+	r.err = errors.New("repo failed")
+
+	_, err := c.Reconcile(context.Background())
+	if !errors.Is(err, r.err) {
+		t.Errorf("err = %v, want %v", err, r.err)
+	}
+}
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+}
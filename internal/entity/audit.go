@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// AuditEntry - record of a security-relevant or administrative action, the log is never updated
+type AuditEntry struct {
+	ID             uuid.UUID       `db:"id"`
+	ActorID        uuid.NullUUID   `db:"actor_id"`        // empty for failed sign-ins
+	ImpersonatorID uuid.NullUUID   `db:"impersonator_id"` // set when the actor is impersonated by an admin
+	Action         AuditAction     `db:"action"`
+	TargetType     AuditTargetType `db:"target_type"`
+	TargetID       uuid.NullUUID   `db:"target_id"`
+	Details        json.RawMessage `db:"details"`
+	IP             string          `db:"ip"`
+	UserAgent      string          `db:"user_agent"`
+	RequestID      string          `db:"request_id"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// AuditFilter - параметры поиска по журналу аудита, пустые поля не фильтруют
+type AuditFilter struct {
+	ActorID    uuid.NullUUID
+	TargetID   uuid.NullUUID
+	TargetType *AuditTargetType
+	Action     *AuditAction
+	RequestID  string
+	IP         string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditAction string
+
+const (
+	AuditSignIn         AuditAction = "sign_in"
+	AuditSignInFailed   AuditAction = "sign_in_failed"
+	AuditPasswordChange AuditAction = "password_change"
+	AuditUserUpdate     AuditAction = "user_update" // details has before and after values of the changed fields
+
+	AuditRoleChange     AuditAction = "role_change"
+	AuditPasswordReset  AuditAction = "password_reset"
+	AuditSessionsRevoke AuditAction = "sessions_revoke"
+	AuditImpersonate    AuditAction = "impersonate"
+	AuditBan            AuditAction = "ban"
+	AuditUnban          AuditAction = "unban"
+	AuditDelete         AuditAction = "delete"
+	AuditRestore        AuditAction = "restore"
+
+	AuditModerationClaim   AuditAction = "moderation_claim"
+	AuditModerationResolve AuditAction = "moderation_resolve"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetUser           AuditTargetType = "user"
+	AuditTargetArticle        AuditTargetType = "article"
+	AuditTargetComment        AuditTargetType = "comment"
+	AuditTargetModerationCase AuditTargetType = "moderation_case"
+)
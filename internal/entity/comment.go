@@ -15,4 +15,5 @@ type Comment struct {
 	UpdatedAt      time.Time     `db:"updated_at"`
 	VotesUpCount   int           `db:"votes_up_count"`
 	VotesDownCount int           `db:"votes_down_count"`
+	HiddenAt       *time.Time    `db:"hidden_at"`
 }
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"sort"
+)
+
+// CounterDrift - денормализованный счетчик строки ID, который разошелся с количеством строк в исходной таблице.
+// Stored - значение в строке, Actual - пересчитанное
+type CounterDrift struct {
+	Table  string
+	Column string
+	ID     uuid.UUID
+	Stored int
+	Actual int
+}
+
+// SortCounterDrift - порядок отчета не зависит от хранилища: по таблице, колонке и id
+func SortCounterDrift(drift []CounterDrift) {
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Table != drift[j].Table {
+			return drift[i].Table < drift[j].Table
+		}
+		if drift[i].Column != drift[j].Column {
+			return drift[i].Column < drift[j].Column
+		}
+		return drift[i].ID.String() < drift[j].ID.String()
+	})
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Event - domain event, stored in the outbox table in the same transaction as the change itself
+type Event struct {
+	ID          int64           `db:"id"           json:"id"`
+	Type        EventType       `db:"event_type"   json:"type"`
+	AggregateID uuid.UUID       `db:"aggregate_id" json:"aggregate_id"`
+	Payload     json.RawMessage `db:"payload"      json:"payload"`
+	CreatedAt   time.Time       `db:"created_at"   json:"created_at"`
+	Attempts    int             `db:"attempts"     json:"-"`
+}
+
+type EventType string
+
+const (
+	EventUserCreated        EventType = "user.created"
+	EventUserFollowed       EventType = "user.followed"
+	EventArticleCreated     EventType = "article.created"
+	EventArticleUpdated     EventType = "article.updated"
+	EventArticleFavorited   EventType = "article.favorited"
+	EventArticleUnfavorited EventType = "article.unfavorited"
+	EventArticleDeleted     EventType = "article.deleted"
+	EventArticleRestored    EventType = "article.restored"
+	EventArticleHidden      EventType = "article.hidden" // hidden by a moderator
+	EventCommentPosted      EventType = "comment.posted"
+	EventCommentDeleted     EventType = "comment.deleted"
+	EventCommentRestored    EventType = "comment.restored"
+	EventCommentHidden      EventType = "comment.hidden" // hidden by a moderator
+)
+
+type EventStatus string
+
+const (
+	EventStatusPending   EventStatus = "pending"
+	EventStatusPublished EventStatus = "published"
+	EventStatusDead      EventStatus = "dead" // delivery attempts are exhausted
+)
+
+func NewEvent(eventType EventType, aggregateID uuid.UUID, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+	}, nil
+}
+
+func (e Event) Decode(payload any) error {
+	return json.Unmarshal(e.Payload, payload)
+}
+
+type UserCreatedPayload struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+}
+
+type UserFollowedPayload struct {
+	FollowerID  uuid.UUID `json:"follower_id"`
+	FollowingID uuid.UUID `json:"following_id"`
+}
+
+type ArticleCreatedPayload struct {
+	ArticleID   uuid.UUID `json:"article_id"`
+	AuthorID    uuid.UUID `json:"author_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+}
+
+type ArticleUpdatedPayload struct {
+	ArticleID   uuid.UUID `json:"article_id"`
+	AuthorID    uuid.UUID `json:"author_id"`
+	EditorID    uuid.UUID `json:"editor_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+}
+
+type ArticleFavoritedPayload struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+// ArticleDeletedPayload - payload of article.deleted, article.restored and article.hidden
+type ArticleDeletedPayload struct {
+	ArticleID uuid.UUID `json:"article_id"`
+	AuthorID  uuid.UUID `json:"author_id"`
+}
+
+type CommentPostedPayload struct {
+	CommentID uuid.UUID     `json:"comment_id"`
+	ArticleID uuid.UUID     `json:"article_id"`
+	ParentID  uuid.NullUUID `json:"parent_id"`
+	AuthorID  uuid.UUID     `json:"author_id"`
+}
+
+// CommentDeletedPayload - payload of comment.deleted, comment.restored and comment.hidden
+type CommentDeletedPayload struct {
+	CommentID uuid.UUID `json:"comment_id"`
+	ArticleID uuid.UUID `json:"article_id"`
+	AuthorID  uuid.UUID `json:"author_id"`
+}
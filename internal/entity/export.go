@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// UserExport - архив с данными пользователя, собирается асинхронно и хранится до ExpiresAt
+type UserExport struct {
+	ID          uuid.UUID        `db:"id"`
+	UserID      uuid.UUID        `db:"user_id"`
+	Status      UserExportStatus `db:"status"`
+	Attempts    int              `db:"attempts"`
+	Size        *int64           `db:"size"`
+	LastError   *string          `db:"last_error"`
+	CreatedAt   time.Time        `db:"created_at"`
+	CompletedAt *time.Time       `db:"completed_at"`
+	ExpiresAt   *time.Time       `db:"expires_at"`
+}
+
+type UserExportStatus string
+
+const (
+	UserExportPending UserExportStatus = "pending"
+	UserExportReady   UserExportStatus = "ready"
+	UserExportFailed  UserExportStatus = "failed"
+)
+
+type Vote struct {
+	TargetType VoteTargetType
+	TargetID   uuid.UUID
+	Value      VoteValue
+}
+
+type VoteTargetType string
+
+const (
+	VoteTargetArticle VoteTargetType = "article"
+	VoteTargetComment VoteTargetType = "comment"
+)
+
+type VoteValue string
+
+const (
+	VoteUp   VoteValue = "up"
+	VoteDown VoteValue = "down"
+)
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// ModerationCase - reports of the same target aggregated until a moderator resolves them
+type ModerationCase struct {
+	ID             uuid.UUID         `db:"id"`
+	TargetType     ReportTargetType  `db:"target_type"`
+	TargetID       uuid.UUID         `db:"target_id"`
+	TargetAuthorID uuid.UUID         `db:"target_author_id"`
+	Status         ModerationStatus  `db:"status"`
+	ReportsCount   int               `db:"reports_count"`
+	AssigneeID     uuid.NullUUID     `db:"assignee_id"`
+	ClaimedAt      *time.Time        `db:"claimed_at"`
+	Resolution     *ModerationAction `db:"resolution"`
+	ResolvedBy     uuid.NullUUID     `db:"resolved_by"`
+	ResolvedAt     *time.Time        `db:"resolved_at"`
+	CreatedAt      time.Time         `db:"created_at"`
+	UpdatedAt      time.Time         `db:"updated_at"`
+}
+
+type Report struct {
+	ID         uuid.UUID    `db:"id"`
+	CaseID     uuid.UUID    `db:"case_id"`
+	ReporterID uuid.UUID    `db:"reporter_id"`
+	Reason     ReportReason `db:"reason"`
+	Comment    string       `db:"comment"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
+
+// ModerationLogEntry - record of a moderator action, the log is never updated
+type ModerationLogEntry struct {
+	ID          uuid.UUID        `db:"id"`
+	CaseID      uuid.NullUUID    `db:"case_id"`
+	ModeratorID uuid.UUID        `db:"moderator_id"`
+	Action      ModerationAction `db:"action"`
+	TargetType  ReportTargetType `db:"target_type"`
+	TargetID    uuid.UUID        `db:"target_id"`
+	Note        string           `db:"note"`
+	CreatedAt   time.Time        `db:"created_at"`
+}
+
+type ReportTargetType string
+
+const (
+	ReportTargetArticle ReportTargetType = "article"
+	ReportTargetComment ReportTargetType = "comment"
+	ReportTargetUser    ReportTargetType = "user"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonSpam          ReportReason = "spam"
+	ReportReasonHarassment    ReportReason = "harassment"
+	ReportReasonHateSpeech    ReportReason = "hate_speech"
+	ReportReasonInappropriate ReportReason = "inappropriate"
+	ReportReasonCopyright     ReportReason = "copyright"
+	ReportReasonOther         ReportReason = "other"
+)
+
+type ModerationStatus string
+
+const (
+	ModerationStatusOpen     ModerationStatus = "open"
+	ModerationStatusClaimed  ModerationStatus = "claimed"
+	ModerationStatusResolved ModerationStatus = "resolved"
+)
+
+type ModerationAction string
+
+const (
+	ModerationActionClaim   ModerationAction = "claim"
+	ModerationActionDismiss ModerationAction = "dismiss" // reports are groundless, content stays as is
+	ModerationActionHide    ModerationAction = "hide"    // content is kept but not shown to users
+	ModerationActionDelete  ModerationAction = "delete"
+	ModerationActionBan     ModerationAction = "ban" // author of the content can't sign in anymore
+)
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Notification struct {
+	ID        uuid.UUID        `db:"id"`
+	UserID    uuid.UUID        `db:"user_id"`
+	ActorID   uuid.UUID        `db:"actor_id"`
+	Type      NotificationType `db:"type"`
+	ArticleID uuid.NullUUID    `db:"article_id"`
+	CommentID uuid.NullUUID    `db:"comment_id"`
+	CreatedAt time.Time        `db:"created_at"`
+}
+
+type NotificationType string
+
+const (
+	NotificationArticleComment  NotificationType = "article_comment"  // someone commented user's article
+	NotificationCommentReply    NotificationType = "comment_reply"    // someone replied to user's comment
+	NotificationNewFollower     NotificationType = "new_follower"     // someone followed the user
+	NotificationArticleFavorite NotificationType = "article_favorite" // someone added user's article to favorites
+)
@@ -0,0 +1,11 @@
+package entity
+
+import "github.com/google/uuid"
+
+// Purged - результат одного вызова очистки: Count - сколько строк выбрано для удаления или анонимизации,
+// Usernames и ArticleIDs - пользователи и статьи, которые удалены или сменили автора, по ним сбрасывается кеш
+type Purged struct {
+	Count      int
+	Usernames  []string
+	ArticleIDs []uuid.UUID
+}
@@ -6,21 +6,38 @@ import (
 )
 
 type User struct {
-	ID                     uuid.UUID `db:"id"`
-	Name                   string    `db:"name"`
-	Username               string    `db:"username"`
-	Password               string    `db:"password"`
-	Email                  string    `db:"email"`
-	CreatedAt              time.Time `db:"created_at"`
-	UpdatedAt              time.Time `db:"updated_at"`
-	Role                   RoleType  `db:"role"`
-	Description            string    `db:"description"`
-	ArticlesCount          int       `db:"articles_count"`
-	CommentsCount          int       `db:"comments_count"`
-	FavoritesArticlesCount int       `db:"favorites_articles_count"`
-	FavoritesCommentsCount int       `db:"favorites_comments_count"`
-	FollowersCount         int       `db:"followers_count"`
-	FollowingCount         int       `db:"following_count"`
+	ID                     uuid.UUID  `db:"id"`
+	Name                   string     `db:"name"`
+	Username               string     `db:"username"`
+	Password               string     `db:"password"`
+	Email                  string     `db:"email"`
+	CreatedAt              time.Time  `db:"created_at"`
+	UpdatedAt              time.Time  `db:"updated_at"`
+	Role                   RoleType   `db:"role"`
+	Description            string     `db:"description"`
+	ArticlesCount          int        `db:"articles_count"`
+	CommentsCount          int        `db:"comments_count"`
+	FavoritesArticlesCount int        `db:"favorites_articles_count"`
+	FavoritesCommentsCount int        `db:"favorites_comments_count"`
+	FollowersCount         int        `db:"followers_count"`
+	FollowingCount         int        `db:"following_count"`
+	BannedAt               *time.Time `db:"banned_at"`
+	DeletionScheduledAt    *time.Time `db:"deletion_scheduled_at"`
+	SessionsRevokedAt      *time.Time `db:"sessions_revoked_at"`
+	PasswordResetRequired  bool       `db:"password_reset_required"`
+}
+
+// TombstoneUserID - автор контента анонимизированных аккаунтов
+var TombstoneUserID = uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+
+// UserFilter - параметры поиска пользователей администратором
+type UserFilter struct {
+	Query   string // part of username, name or email
+	Role    *RoleType
+	Banned  *bool
+	Deleted bool // deleted users are shown instead of active ones
+	Limit   int
+	Offset  int
 }
 
 type RoleType string
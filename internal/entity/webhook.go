@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Webhook struct {
+	ID         uuid.UUID   `db:"id"`
+	OwnerID    uuid.UUID   `db:"owner_id"`
+	URL        string      `db:"url"`
+	Secret     string      `db:"secret"`
+	EventTypes []EventType `db:"event_types"` // empty means all events
+	IsGlobal   bool        `db:"is_global"`   // receives events of all users, can be created by admin only
+	Active     bool        `db:"active"`
+	CreatedAt  time.Time   `db:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID             `db:"id"`
+	WebhookID      uuid.UUID             `db:"webhook_id"`
+	EventID        int64                 `db:"event_id"`
+	EventType      EventType             `db:"event_type"`
+	Payload        json.RawMessage       `db:"payload"`
+	Status         WebhookDeliveryStatus `db:"status"`
+	Attempts       int                   `db:"attempts"`
+	NextAttemptAt  time.Time             `db:"next_attempt_at"`
+	LastStatusCode *int                  `db:"last_status_code"`
+	LastError      *string               `db:"last_error"`
+	LastAttemptAt  *time.Time            `db:"last_attempt_at"`
+	DeliveredAt    *time.Time            `db:"delivered_at"`
+	CreatedAt      time.Time             `db:"created_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "dead" // attempts are exhausted, can be redelivered manually
+)
+
+// WebhookEventTypes - события, на которые можно подписать webhook
+var WebhookEventTypes = []EventType{
+	EventArticleCreated,
+	EventArticleUpdated,
+	EventArticleFavorited,
+	EventArticleUnfavorited,
+	EventArticleDeleted,
+	EventArticleRestored,
+	EventArticleHidden,
+	EventCommentPosted,
+	EventCommentDeleted,
+	EventCommentRestored,
+	EventCommentHidden,
+}
@@ -0,0 +1,215 @@
+package export
+
+import (
+	"archive/zip"
+	"blog-backend/internal/entity"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// Data - все данные пользователя, попадающие в архив
+type Data struct {
+	User             entity.User
+	Articles         []entity.Article
+	Comments         []entity.Comment
+	FavoriteArticles []entity.Article
+	FavoriteComments []entity.Comment
+	Followers        []entity.User
+	Followings       []entity.User
+	Votes            []entity.Vote
+	GeneratedAt      time.Time
+}
+
+type profileJSON struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Username    string          `json:"username"`
+	Email       string          `json:"email"`
+	Role        entity.RoleType `json:"role"`
+	Description string          `json:"description"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type articleJSON struct {
+	ID          uuid.UUID `json:"id"`
+	AuthorID    uuid.UUID `json:"author_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type commentJSON struct {
+	ID        uuid.UUID     `json:"id"`
+	AuthorID  uuid.UUID     `json:"author_id"`
+	ArticleID uuid.UUID     `json:"article_id"`
+	ParentID  uuid.NullUUID `json:"parent_id"`
+	Content   string        `json:"content"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type voteJSON struct {
+	TargetType entity.VoteTargetType `json:"target_type"`
+	TargetID   uuid.UUID             `json:"target_id"`
+	Value      entity.VoteValue      `json:"value"`
+}
+
+type followJSON struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Name     string    `json:"name"`
+}
+
+// Archive - ZIP с JSON файлами и Markdown копиями статей пользователя
+func Archive(data Data) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	files := []struct {
+		name  string
+		value interface{}
+	}{
+		{"profile.json", profileJSON{
+			ID:          data.User.ID,
+			Name:        data.User.Name,
+			Username:    data.User.Username,
+			Email:       data.User.Email,
+			Role:        data.User.Role,
+			Description: data.User.Description,
+			CreatedAt:   data.User.CreatedAt,
+			UpdatedAt:   data.User.UpdatedAt,
+		}},
+		{"articles.json", articlesJSON(data.Articles)},
+		{"comments.json", commentsJSON(data.Comments)},
+		{"favorites.json", map[string]interface{}{
+			"articles": articlesJSON(data.FavoriteArticles),
+			"comments": commentsJSON(data.FavoriteComments),
+		}},
+		{"follows.json", map[string]interface{}{
+			"followers":  followsJSON(data.Followers),
+			"followings": followsJSON(data.Followings),
+		}},
+		{"votes.json", votesJSON(data.Votes)},
+	}
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.value, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %v", file.name, err)
+		}
+
+		err = writeFile(w, file.name, data.GeneratedAt, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, article := range data.Articles {
+		err := writeFile(w, "articles/"+article.Id.String()+".md", data.GeneratedAt, articleMarkdown(article))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := w.Close()
+	if err != nil {
+		return nil, fmt.Errorf("close archive: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeFile(w *zip.Writer, name string, modified time.Time, content []byte) error {
+	f, err := w.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return fmt.Errorf("create %s: %v", name, err)
+	}
+
+	_, err = f.Write(content)
+	if err != nil {
+		return fmt.Errorf("write %s: %v", name, err)
+	}
+
+	return nil
+}
+
+func articleMarkdown(article entity.Article) []byte {
+	var sb strings.Builder
+	sb.WriteString("# " + article.Title + "\n\n")
+	if article.Description != "" {
+		sb.WriteString("> " + strings.ReplaceAll(article.Description, "\n", "\n> ") + "\n\n")
+	}
+	sb.WriteString("_Published " + article.CreatedAt.UTC().Format(time.RFC3339) + "_\n\n")
+	sb.WriteString(article.Content)
+	if !strings.HasSuffix(article.Content, "\n") {
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String())
+}
+
+func articlesJSON(articles []entity.Article) []articleJSON {
+	result := make([]articleJSON, 0, len(articles))
+	for _, article := range articles {
+		result = append(result, articleJSON{
+			ID:          article.Id,
+			AuthorID:    article.AuthorID,
+			Title:       article.Title,
+			Description: article.Description,
+			Content:     article.Content,
+			CreatedAt:   article.CreatedAt,
+			UpdatedAt:   article.UpdatedAt,
+		})
+	}
+	return result
+}
+
+func commentsJSON(comments []entity.Comment) []commentJSON {
+	result := make([]commentJSON, 0, len(comments))
+	for _, comment := range comments {
+		result = append(result, commentJSON{
+			ID:        comment.Id,
+			AuthorID:  comment.AuthorID,
+			ArticleID: comment.ArticleID,
+			ParentID:  comment.ParentID,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		})
+	}
+	return result
+}
+
+func followsJSON(users []entity.User) []followJSON {
+	result := make([]followJSON, 0, len(users))
+	for _, user := range users {
+		result = append(result, followJSON{
+			ID:       user.ID,
+			Username: user.Username,
+			Name:     user.Name,
+		})
+	}
+	return result
+}
+
+func votesJSON(votes []entity.Vote) []voteJSON {
+	result := make([]voteJSON, 0, len(votes))
+	for _, vote := range votes {
+		result = append(result, voteJSON{
+			TargetType: vote.TargetType,
+			TargetID:   vote.TargetID,
+			Value:      vote.Value,
+		})
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"archive/zip"
+	"blog-backend/internal/entity"
+	"bytes"
+	"encoding/json"
+	"github.com/google/uuid"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// readArchive - содержимое файлов архива по именам
+func readArchive(t *testing.T, archive []byte) map[string][]byte {
+	t.Helper()
+
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string][]byte, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestArchive(t *testing.T) {
+	generatedAt := time.Date(2022, 11, 14, 10, 0, 0, 0, time.UTC)
+	user := entity.User{ID: uuid.New(), Username: "alice", Email: "alice@example.com", Password: "hash", Role: entity.RoleUser}
+	article := entity.Article{
+		Id:          uuid.New(),
+		AuthorID:    user.ID,
+		Title:       "Title",
+		Description: "first line\nsecond line",
+		Content:     "content",
+		CreatedAt:   generatedAt,
+	}
+	comment := entity.Comment{Id: uuid.New(), AuthorID: user.ID, ArticleID: article.Id, Content: "comment"}
+	follower := entity.User{ID: uuid.New(), Username: "bob", Name: "Bob", Email: "bob@example.com"}
+
+	archive, err := Archive(Data{
+		User:        user,
+		Articles:    []entity.Article{article},
+		Comments:    []entity.Comment{comment},
+		Followers:   []entity.User{follower},
+		Votes:       []entity.Vote{{TargetType: entity.VoteTargetArticle, TargetID: article.Id, Value: entity.VoteUp}},
+		GeneratedAt: generatedAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := readArchive(t, archive)
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{
+		"articles.json",
+		"articles/" + article.Id.String() + ".md",
+		"comments.json",
+		"favorites.json",
+		"follows.json",
+		"profile.json",
+		"votes.json",
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	// the password hash never leaves the database
+	var profile map[string]interface{}
+	if err := json.Unmarshal(files["profile.json"], &profile); err != nil {
+		t.Fatal(err)
+	}
+	if profile["username"] != "alice" || profile["email"] != "alice@example.com" {
+		t.Errorf("profile = %v", profile)
+	}
+	if _, ok := profile["password"]; ok {
+		t.Error("archive contains the password hash")
+	}
+
+	// only public data of other users is exported
+	var follows struct {
+		Followers  []map[string]interface{} `json:"followers"`
+		Followings []map[string]interface{} `json:"followings"`
+	}
+	if err := json.Unmarshal(files["follows.json"], &follows); err != nil {
+		t.Fatal(err)
+	}
+	if len(follows.Followers) != 1 || follows.Followers[0]["username"] != "bob" || follows.Followers[0]["email"] != nil {
+		t.Errorf("followers = %v", follows.Followers)
+	}
+	if follows.Followings == nil {
+		t.Error("empty followings are exported as null, want []")
+	}
+
+	markdown := "# Title\n\n> first line\n> second line\n\n_Published 2022-11-14T10:00:00Z_\n\ncontent\n"
+	if got := string(files["articles/"+article.Id.String()+".md"]); got != markdown {
+		t.Errorf("markdown = %q, want %q", got, markdown)
+	}
+}
@@ -0,0 +1,171 @@
+package export
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultTTL          = 7 * 24 * time.Hour
+	defaultMaxAttempts  = 3
+
+	batchSize = 5
+	// archive of one user must be built within the lease
+	lease = 5 * time.Minute
+)
+
+// Exporter - асинхронная сборка архивов с данными пользователей и удаление истекших архивов
+type Exporter struct {
+	exportRepo  repo.Export
+	userRepo    repo.User
+	articleRepo repo.Article
+	commentRepo repo.Comment
+
+	pollInterval time.Duration
+	ttl          time.Duration
+	maxAttempts  int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewExporter(exportRepo repo.Export, userRepo repo.User, articleRepo repo.Article, commentRepo repo.Comment, opts ...Option) *Exporter {
+	e := &Exporter{
+		exportRepo:   exportRepo,
+		userRepo:     userRepo,
+		articleRepo:  articleRepo,
+		commentRepo:  commentRepo,
+		pollInterval: defaultPollInterval,
+		ttl:          defaultTTL,
+		maxAttempts:  defaultMaxAttempts,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+
+	go e.run(ctx)
+
+	return e
+}
+
+func (e *Exporter) Close() {
+	e.cancel()
+	<-e.done
+}
+
+func (e *Exporter) run(ctx context.Context) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := e.ProcessBatch(ctx)
+		if err != nil {
+			log.Errorf("Exporter.run - e.ProcessBatch: %v", err)
+		}
+
+		if err == nil && n == batchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		_, err = e.exportRepo.DeleteExpiredExports(ctx)
+		if err != nil {
+			log.Errorf("Exporter.run - e.exportRepo.DeleteExpiredExports: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch - сборка пачки архивов, возвращает количество захваченных архивов
+func (e *Exporter) ProcessBatch(ctx context.Context) (int, error) {
+	exports, err := e.exportRepo.LockPendingExports(ctx, batchSize, lease)
+	if err != nil {
+		return 0, fmt.Errorf("e.exportRepo.LockPendingExports: %v", err)
+	}
+
+	for _, export := range exports {
+		archive, err := e.build(ctx, export)
+		if err != nil {
+			log.Errorf("Exporter.ProcessBatch - e.build %s: %v", export.ID, err)
+
+			err = e.exportRepo.MarkExportFailed(ctx, export.ID, err.Error(), export.Attempts >= e.maxAttempts)
+			if err != nil {
+				return 0, fmt.Errorf("e.exportRepo.MarkExportFailed: %v", err)
+			}
+			continue
+		}
+
+		err = e.exportRepo.MarkExportReady(ctx, export.ID, archive, e.ttl)
+		if err != nil {
+			return 0, fmt.Errorf("e.exportRepo.MarkExportReady: %v", err)
+		}
+	}
+
+	return len(exports), nil
+}
+
+func (e *Exporter) build(ctx context.Context, export entity.UserExport) ([]byte, error) {
+	data := Data{GeneratedAt: time.Now()}
+	var err error
+
+	data.User, err = e.userRepo.GetUserByID(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Articles, err = e.articleRepo.GetArticlesByAuthorID(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Comments, err = e.commentRepo.GetCommentsByAuthorID(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.FavoriteArticles, err = e.articleRepo.GetFavoriteArticles(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.FavoriteComments, err = e.commentRepo.GetFavoriteComments(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Followers, err = e.userRepo.GetUserFollowers(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Followings, err = e.userRepo.GetUserFollowings(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Votes, err = e.exportRepo.GetUserVotes(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Archive(data)
+}
@@ -0,0 +1,152 @@
+package export_test
+
+import (
+	"archive/zip"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/export"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/memdb"
+	"blog-backend/internal/repo/repoerrs"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// newExport - пользователь со статьей и запрошенный им архив
+func newExport(t *testing.T, repos *repo.Repositories) (entity.UserExport, uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+
+	userID, err := repos.CreateUser(ctx, entity.User{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "password",
+		Role:     entity.RoleUser,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	articleID, err := repos.CreateArticle(ctx, entity.Article{AuthorID: userID, Title: "title", Content: "content"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := repos.CreateExport(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e, articleID
+}
+
+// newExporter - exporter без фонового цикла, архивы собираются только вызовами ProcessBatch
+func newExporter(repos *repo.Repositories, opts ...export.Option) *export.Exporter {
+	e := export.NewExporter(repos, repos, repos, repos, append([]export.Option{export.PollInterval(time.Hour)}, opts...)...)
+	e.Close()
+	return e
+}
+
+func processBatch(t *testing.T, e *export.Exporter) int {
+	t.Helper()
+
+	n, err := e.ProcessBatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestExporter_Ready(t *testing.T) {
+	ctx := context.Background()
+	repos := repo.NewMemoryRepositories(memdb.New())
+	e := newExporter(repos, export.TTL(time.Hour))
+	userExport, articleID := newExport(t, repos)
+
+	if n := processBatch(t, e); n != 1 {
+		t.Fatalf("locked %d exports, want 1", n)
+	}
+
+	got, err := repos.GetExportByID(ctx, userExport.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != entity.UserExportReady || got.ExpiresAt == nil {
+		t.Fatalf("status %s, expires at %v, want ready with expiration", got.Status, got.ExpiresAt)
+	}
+	if ttl := got.ExpiresAt.Sub(*got.CompletedAt); ttl != time.Hour {
+		t.Errorf("archive is kept for %s, want an hour", ttl)
+	}
+
+	archive, err := repos.GetExportArchive(ctx, userExport.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, f := range r.File {
+		found = found || f.Name == "articles/"+articleID.String()+".md"
+	}
+	if !found || int64(len(archive)) != *got.Size {
+		t.Errorf("archive of %d bytes has no article of the user", len(archive))
+	}
+
+	if n := processBatch(t, e); n != 0 {
+		t.Errorf("locked %d exports after the archive is ready, want 0", n)
+	}
+}
+
+func TestExporter_Failed(t *testing.T) {
+	ctx := context.Background()
+	repos := repo.NewMemoryRepositories(memdb.New())
+	e := newExporter(repos, export.MaxAttempts(1))
+	userExport, _ := newExport(t, repos)
+
+	// the user is deleted before the archive is built
+	err := repos.DeleteUserByID(ctx, userExport.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processBatch(t, e)
+
+	got, err := repos.GetExportByID(ctx, userExport.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != entity.UserExportFailed || got.LastError == nil {
+		t.Errorf("status %s, last error %v, want failed with the error", got.Status, got.LastError)
+	}
+}
+
+// the background loop deletes archives whose ttl is over
+func TestExporter_Expired(t *testing.T) {
+	ctx := context.Background()
+	repos := repo.NewMemoryRepositories(memdb.New())
+	userExport, _ := newExport(t, repos)
+
+	e := export.NewExporter(repos, repos, repos, repos, export.PollInterval(10*time.Millisecond), export.TTL(-time.Minute))
+	defer e.Close()
+
+	deadline := time.After(time.Second)
+	for {
+		_, err := repos.GetExportByID(ctx, userExport.ID)
+		if errors.Is(err, repoerrs.ErrExportNotFound) {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-deadline:
+			t.Fatal("expired archive is not deleted in a second")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
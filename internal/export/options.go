@@ -0,0 +1,23 @@
+package export
+
+import "time"
+
+type Option func(*Exporter)
+
+func PollInterval(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.pollInterval = interval
+	}
+}
+
+func TTL(ttl time.Duration) Option {
+	return func(e *Exporter) {
+		e.ttl = ttl
+	}
+}
+
+func MaxAttempts(attempts int) Option {
+	return func(e *Exporter) {
+		e.maxAttempts = attempts
+	}
+}
@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "blog"
+
+// Metrics - метрики приложения в собственном реестре, отдаются через Handler на отдельном порту.
+// Методы можно вызывать у nil, тогда они ничего не делают
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequestDuration *prometheus.HistogramVec
+	grpcRequestDuration *prometheus.HistogramVec
+	repoQueryDuration   *prometheus.HistogramVec
+	cacheRequests       *prometheus.CounterVec
+	counterDrift        *prometheus.CounterVec
+
+	signUps         prometheus.Counter
+	signIns         *prometheus.CounterVec
+	articlesCreated prometheus.Counter
+	favorites       prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		grpcRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of gRPC calls by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		repoQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repo",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of repository method calls.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repo", "method"}),
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Number of cache lookups by cache and result.",
+		}, []string{"cache", "result"}),
+		counterDrift: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "counters",
+			Name:      "drift_total",
+			Help:      "Number of denormalized counters found out of sync with their source tables.",
+		}, []string{"table", "column"}),
+		signUps: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sign_ups_total",
+			Help:      "Number of registered users.",
+		}),
+		signIns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sign_ins_total",
+			Help:      "Number of sign-in attempts by result.",
+		}, []string{"result"}),
+		articlesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "articles_created_total",
+			Help:      "Number of created articles.",
+		}),
+		favorites: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "favorites_total",
+			Help:      "Number of articles added to favorites.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequestDuration,
+		m.grpcRequestDuration,
+		m.repoQueryDuration,
+		m.cacheRequests,
+		m.counterDrift,
+		m.signUps,
+		m.signIns,
+		m.articlesCreated,
+		m.favorites,
+	)
+
+	return m
+}
+
+// Register - дополнительные коллекторы, например статистика пула соединений
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTPRequest - route это шаблон маршрута, а не путь запроса, иначе число рядов не ограничено
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveGRPCRequest - method - полное имя метода, например /blog.v1.ArticleService/GetArticle
+func (m *Metrics) ObserveGRPCRequest(method, code string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.grpcRequestDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// ObserveRepoQuery - удобно вызывать через defer в начале метода: defer m.ObserveRepoQuery(repo, method, time.Now())
+func (m *Metrics) ObserveRepoQuery(repo, method string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.repoQueryDuration.WithLabelValues(repo, method).Observe(time.Since(start).Seconds())
+}
+
+// CacheRequest - result: hit или miss
+func (m *Metrics) CacheRequest(cache, result string) {
+	if m == nil {
+		return
+	}
+	m.cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// CounterDrift - n счетчиков колонки table.column разошлись с исходной таблицей
+func (m *Metrics) CounterDrift(table, column string, n int) {
+	if m == nil {
+		return
+	}
+	m.counterDrift.WithLabelValues(table, column).Add(float64(n))
+}
+
+func (m *Metrics) SignUp() {
+	if m == nil {
+		return
+	}
+	m.signUps.Inc()
+}
+
+// SignIn - result: success или причина отказа
+func (m *Metrics) SignIn(result string) {
+	if m == nil {
+		return
+	}
+	m.signIns.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) ArticleCreated() {
+	if m == nil {
+		return
+	}
+	m.articlesCreated.Inc()
+}
+
+func (m *Metrics) ArticleFavorited() {
+	if m == nil {
+		return
+	}
+	m.favorites.Inc()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"blog-backend/pkg/postgres"
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestPoolCollector(t *testing.T) {
+	// the pool never connects, only its configuration and zero stats are collected
+	config, err := pgxpool.ParseConfig("postgres://user@127.0.0.1:1/blog?pool_max_conns=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.LazyConnect = true
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	collector := NewPoolCollector(&postgres.Node{Name: "replica", Pool: pool})
+
+	if n := testutil.CollectAndCount(collector); n != 11 {
+		t.Errorf("collected %d series, want 11", n)
+	}
+
+	// a node that hasn't passed a health check yet is out of rotation
+	expected := `
+# HELP blog_pgxpool_healthy Whether the node is in rotation, replicas are taken out when a health check fails.
+# TYPE blog_pgxpool_healthy gauge
+blog_pgxpool_healthy{node="replica"} 0
+# HELP blog_pgxpool_max_conns Maximum size of the pool.
+# TYPE blog_pgxpool_max_conns gauge
+blog_pgxpool_max_conns{node="replica"} 7
+# HELP blog_pgxpool_acquire_count_total Number of successful acquires from the pool.
+# TYPE blog_pgxpool_acquire_count_total counter
+blog_pgxpool_acquire_count_total{node="replica"} 0
+`
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"blog_pgxpool_healthy", "blog_pgxpool_max_conns", "blog_pgxpool_acquire_count_total")
+	if err != nil {
+		t.Error(err)
+	}
+
+	// names and help texts follow the prometheus conventions
+	problems, err := testutil.CollectAndLint(collector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range problems {
+		t.Errorf("%s: %s", problem.Metric, problem.Text)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build integration
+
+package outbox_test
+
+import (
+	"blog-backend/internal/repo"
+	"blog-backend/internal/testutil/pgtest"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Main(m))
+}
+
+// LockPendingEvents в postgres пропускает строки, захваченные другими релеями (SKIP LOCKED)
+func TestRelay_ConcurrentPostgres(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	repos := repo.NewRepositories(pg)
+
+	testConcurrentRelays(t, repos, createEvents(t, repos, pgtest.AliceID, 50))
+}
@@ -0,0 +1,48 @@
+package outbox
+
+import "time"
+
+type Option func(*Relay)
+
+func PollInterval(interval time.Duration) Option {
+	return func(r *Relay) {
+		r.pollInterval = interval
+	}
+}
+
+func BatchSize(size int) Option {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+func Lease(lease time.Duration) Option {
+	return func(r *Relay) {
+		r.lease = lease
+	}
+}
+
+func MaxAttempts(attempts int) Option {
+	return func(r *Relay) {
+		r.maxAttempts = attempts
+	}
+}
+
+func Backoff(min, max time.Duration) Option {
+	return func(r *Relay) {
+		r.minBackoff = min
+		r.maxBackoff = max
+	}
+}
+
+func DeliveryTimeout(timeout time.Duration) Option {
+	return func(r *Relay) {
+		r.deliveryTimeout = timeout
+	}
+}
+
+func Sinks(sinks ...Sink) Option {
+	return func(r *Relay) {
+		r.sinks = append(r.sinks, sinks...)
+	}
+}
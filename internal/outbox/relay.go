@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/pkg/backoff"
+	"context"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval    = time.Second
+	defaultBatchSize       = 100
+	defaultLease           = time.Minute
+	defaultMaxAttempts     = 10
+	defaultMinBackoff      = time.Second
+	defaultMaxBackoff      = 10 * time.Minute
+	defaultDeliveryTimeout = 10 * time.Second
+)
+
+type HandlerFunc func(ctx context.Context, event entity.Event) error
+
+// Relay - доставка событий из outbox подписчикам внутри процесса и во внешние sink'и.
+// Доставка at-least-once: событие помечается опубликованным только после успеха всех получателей,
+// при ошибке событие доставляется всем получателям повторно, поэтому они должны быть идемпотентны
+type Relay struct {
+	outboxRepo repo.Outbox
+
+	pollInterval    time.Duration
+	batchSize       int
+	lease           time.Duration
+	maxAttempts     int
+	minBackoff      time.Duration
+	maxBackoff      time.Duration
+	deliveryTimeout time.Duration
+	sinks           []Sink
+
+	mu       sync.RWMutex
+	handlers map[entity.EventType][]HandlerFunc
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRelay(outboxRepo repo.Outbox, opts ...Option) *Relay {
+	r := &Relay{
+		outboxRepo:      outboxRepo,
+		pollInterval:    defaultPollInterval,
+		batchSize:       defaultBatchSize,
+		lease:           defaultLease,
+		maxAttempts:     defaultMaxAttempts,
+		minBackoff:      defaultMinBackoff,
+		maxBackoff:      defaultMaxBackoff,
+		deliveryTimeout: defaultDeliveryTimeout,
+		handlers:        make(map[entity.EventType][]HandlerFunc),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Subscribe - регистрация обработчика событий типа eventType внутри процесса
+func (r *Relay) Subscribe(eventType entity.EventType, handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers[eventType] = append(r.handlers[eventType], handler)
+}
+
+// Start - запуск фоновой доставки, подписчики должны быть зарегистрированы до запуска
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.run(ctx)
+}
+
+// Close - остановка доставки, текущая пачка событий дорабатывается
+func (r *Relay) Close() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+
+	for _, sink := range r.sinks {
+		err := sink.Close()
+		if err != nil {
+			log.Errorf("Relay.Close - sink %s: %v", sink.Name(), err)
+		}
+	}
+}
+
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.ProcessBatch(ctx)
+		if err != nil {
+			log.Errorf("Relay.run - r.ProcessBatch: %v", err)
+		}
+
+		// full batch means there are more events waiting
+		if err == nil && n == r.batchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch - одна итерация релея: захват пачки событий и их доставка, возвращает количество захваченных событий
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	events, err := r.outboxRepo.LockPendingEvents(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return 0, fmt.Errorf("r.outboxRepo.LockPendingEvents: %v", err)
+	}
+
+	for _, event := range events {
+		// the batch is finished even if the relay is stopping, locked events would wait for the lease otherwise
+		err = r.deliver(context.Background(), event)
+		if err == nil {
+			err = r.outboxRepo.MarkEventPublished(context.Background(), event.ID)
+			if err != nil {
+				log.Errorf("Relay.ProcessBatch - r.outboxRepo.MarkEventPublished: %v", err)
+			}
+			continue
+		}
+
+		if event.Attempts >= r.maxAttempts {
+			log.Errorf("Relay.ProcessBatch - event %d %s is dead after %d attempts: %v", event.ID, event.Type, event.Attempts, err)
+			err = r.outboxRepo.MarkEventDead(context.Background(), event.ID, err.Error())
+			if err != nil {
+				log.Errorf("Relay.ProcessBatch - r.outboxRepo.MarkEventDead: %v", err)
+			}
+			continue
+		}
+
+		log.Warnf("Relay.ProcessBatch - event %d %s delivery failed, attempt %d: %v", event.ID, event.Type, event.Attempts, err)
+		err = r.outboxRepo.MarkEventFailed(context.Background(), event.ID, err.Error(), backoff.Exponential(event.Attempts, r.minBackoff, r.maxBackoff))
+		if err != nil {
+			log.Errorf("Relay.ProcessBatch - r.outboxRepo.MarkEventFailed: %v", err)
+		}
+	}
+
+	return len(events), nil
+}
+
+func (r *Relay) deliver(ctx context.Context, event entity.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, r.deliveryTimeout)
+	defer cancel()
+
+	r.mu.RLock()
+	handlers := r.handlers[event.Type]
+	r.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		err := handler(ctx, event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("handler: %w", err))
+		}
+	}
+
+	for _, sink := range r.sinks {
+		err := sink.Send(ctx, event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,200 @@
+package outbox_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/outbox"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/memdb"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"sync"
+	"testing"
+)
+
+// createEvents - n событий article_created автора authorID, возвращает их aggregate id
+func createEvents(t *testing.T, repos *repo.Repositories, authorID uuid.UUID, n int) []uuid.UUID {
+	t.Helper()
+
+	ids := make([]uuid.UUID, 0, n)
+	for i := 0; i < n; i++ {
+		id, err := repos.CreateArticle(context.Background(), entity.Article{AuthorID: authorID, Title: "title"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func newMemoryRepos(t *testing.T) (*repo.Repositories, uuid.UUID) {
+	t.Helper()
+
+	repos := repo.NewMemoryRepositories(memdb.New())
+	authorID, err := repos.CreateUser(context.Background(), entity.User{
+		Username: "author",
+		Email:    "author@example.com",
+		Password: "password",
+		Role:     entity.RoleUser,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// user.created of the author is published before the test
+	processBatch(t, outbox.NewRelay(repos))
+
+	return repos, authorID
+}
+
+func processBatch(t *testing.T, r *outbox.Relay) int {
+	t.Helper()
+
+	n, err := r.ProcessBatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// countByAggregate - сколько раз доставлено событие каждого агрегата
+func countByAggregate(events []entity.Event) map[uuid.UUID]int {
+	counts := make(map[uuid.UUID]int, len(events))
+	for _, event := range events {
+		counts[event.AggregateID]++
+	}
+	return counts
+}
+
+func TestRelay_Delivery(t *testing.T) {
+	repos, authorID := newMemoryRepos(t)
+	ids := createEvents(t, repos, authorID, 3)
+
+	sink := outbox.NewMemorySink()
+	relay := outbox.NewRelay(repos, outbox.Sinks(sink))
+
+	var handled []entity.Event
+	relay.Subscribe(entity.EventArticleCreated, func(ctx context.Context, event entity.Event) error {
+		handled = append(handled, event)
+		return nil
+	})
+	relay.Subscribe(entity.EventCommentPosted, func(ctx context.Context, event entity.Event) error {
+		t.Errorf("handler of another event type is called for %s", event.Type)
+		return nil
+	})
+
+	if n := processBatch(t, relay); n != len(ids) {
+		t.Fatalf("locked %d events, want %d", n, len(ids))
+	}
+
+	for i, id := range ids {
+		if handled[i].AggregateID != id || sink.Events()[i].AggregateID != id {
+			t.Errorf("event %d is delivered out of order", i)
+		}
+	}
+
+	// published events are not locked again
+	if n := processBatch(t, relay); n != 0 {
+		t.Errorf("locked %d events after publishing, want 0", n)
+	}
+}
+
+func TestRelay_Retry(t *testing.T) {
+	repos, authorID := newMemoryRepos(t)
+	ids := createEvents(t, repos, authorID, 1)
+
+	sink := outbox.NewMemorySink()
+	sink.Fail(2)
+	relay := outbox.NewRelay(repos, outbox.Sinks(sink), outbox.MaxAttempts(3), outbox.Backoff(0, 0))
+
+	var attempts []int
+	relay.Subscribe(entity.EventArticleCreated, func(ctx context.Context, event entity.Event) error {
+		attempts = append(attempts, event.Attempts)
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if n := processBatch(t, relay); n != 1 {
+			t.Fatalf("attempt %d: locked %d events, want 1", i+1, n)
+		}
+	}
+	if n := processBatch(t, relay); n != 0 {
+		t.Fatalf("locked %d events after the successful attempt, want 0", n)
+	}
+
+	// at-least-once: the handler sees the event again every time the sink fails
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("handler attempts = %v, want [1 2 3]", attempts)
+	}
+	if events := sink.Events(); len(events) != 1 || events[0].AggregateID != ids[0] {
+		t.Errorf("sink events = %v, want the event once", events)
+	}
+}
+
+func TestRelay_Dead(t *testing.T) {
+	repos, authorID := newMemoryRepos(t)
+	createEvents(t, repos, authorID, 2)
+
+	relay := outbox.NewRelay(repos, outbox.MaxAttempts(2), outbox.Backoff(0, 0))
+
+	handlerErr := errors.New("handler failed")
+	calls := 0
+	relay.Subscribe(entity.EventArticleCreated, func(ctx context.Context, event entity.Event) error {
+		calls++
+		return handlerErr
+	})
+
+	processBatch(t, relay)
+	processBatch(t, relay)
+
+	// dead events are kept for investigation and never delivered again
+	if n := processBatch(t, relay); n != 0 {
+		t.Errorf("locked %d events after max attempts, want 0", n)
+	}
+	if calls != 4 {
+		t.Errorf("handler calls = %d, want 2 events * 2 attempts", calls)
+	}
+}
+
+// testConcurrentRelays - релеи с общим outbox доставляют каждое событие ровно один раз, пока ни один не падает
+func testConcurrentRelays(t *testing.T, repos *repo.Repositories, ids []uuid.UUID) {
+	t.Helper()
+
+	sink := outbox.NewMemorySink()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		relay := outbox.NewRelay(repos, outbox.Sinks(sink), outbox.BatchSize(3))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n, err := relay.ProcessBatch(context.Background())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n == 0 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	counts := countByAggregate(sink.Events())
+	if len(counts) != len(ids) {
+		t.Errorf("delivered %d events, want %d", len(counts), len(ids))
+	}
+	for _, id := range ids {
+		if counts[id] != 1 {
+			t.Errorf("event of %s is delivered %d times, want once", id, counts[id])
+		}
+	}
+}
+
+func TestRelay_Concurrent(t *testing.T) {
+	repos, authorID := newMemoryRepos(t)
+	testConcurrentRelays(t, repos, createEvents(t, repos, authorID, 50))
+}
@@ -0,0 +1,229 @@
+package outbox
+
+import (
+	"blog-backend/internal/entity"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Sink - внешний получатель событий, Send должен возвращать ошибку пока событие не принято получателем
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event entity.Event) error
+	Close() error
+}
+
+// WebhookSink - отправка событий POST запросом, принятым считается ответ 2xx
+type WebhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookSink(url, secret string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &WebhookSink{
+		url:    url,
+		secret: secret,
+		client: client,
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Send(ctx context.Context, event entity.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", string(event.Type))
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s.client.Do: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// NATSSink - публикация событий в JetStream, subject формируется как <prefix>.<event type>.
+// ID события передается как Nats-Msg-Id, поэтому повторы в окне дедупликации стрима отбрасываются
+type NATSSink struct {
+	conn          *nats.Conn
+	js            nats.JetStreamContext
+	subjectPrefix string
+}
+
+func NewNATSSink(url, subjectPrefix string) (*NATSSink, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("NewNATSSink - nats.Connect: %w", err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("NewNATSSink - conn.JetStream: %w", err)
+	}
+
+	return &NATSSink{
+		conn:          conn,
+		js:            js,
+		subjectPrefix: subjectPrefix,
+	}, nil
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Send(ctx context.Context, event entity.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %v", err)
+	}
+
+	subject := s.subjectPrefix + "." + string(event.Type)
+	_, err = s.js.Publish(subject, data, nats.Context(ctx), nats.MsgId(strconv.FormatInt(event.ID, 10)))
+	if err != nil {
+		return fmt.Errorf("s.js.Publish: %v", err)
+	}
+
+	return nil
+}
+
+func (s *NATSSink) Close() error {
+	s.conn.Close()
+	return nil
+}
+
+// KafkaSink - запись событий в топик kafka с ключом aggregate_id,
+// так события одного агрегата попадают в одну партицию и сохраняют порядок
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Send(ctx context.Context, event entity.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %v", err)
+	}
+
+	err = s.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.AggregateID.String()),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event_id", Value: []byte(strconv.FormatInt(event.ID, 10))},
+			{Key: "event_type", Value: []byte(event.Type)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("s.writer.WriteMessages: %v", err)
+	}
+
+	return nil
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+// MemorySink - брокер-заглушка в памяти для локальной разработки и тестов релея,
+// Fail заставляет следующие n вызовов Send вернуть ошибку
+type MemorySink struct {
+	mu     sync.Mutex
+	events []entity.Event
+	fail   int
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Name() string {
+	return "memory"
+}
+
+func (s *MemorySink) Send(ctx context.Context, event entity.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail > 0 {
+		s.fail--
+		return fmt.Errorf("memory sink: injected failure")
+	}
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+func (s *MemorySink) Close() error {
+	return nil
+}
+
+func (s *MemorySink) Fail(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fail = n
+}
+
+func (s *MemorySink) Events() []entity.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]entity.Event(nil), s.events...)
+}
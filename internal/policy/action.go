@@ -0,0 +1,71 @@
+package policy
+
+import "blog-backend/internal/entity"
+
+// Action - действие над ресурсом, имена используются в правилах конфигурации
+type Action string
+
+const (
+	AdminAccess Action = "admin.access"
+	AuditRead   Action = "audit.read"
+
+	UserUpdate         Action = "user.update" // name and description
+	UserUpdateEmail    Action = "user.update_email"
+	UserSetRole        Action = "user.set_role"
+	UserDelete         Action = "user.delete"
+	UserRestore        Action = "user.restore"
+	UserSearch         Action = "user.search"
+	UserResetPassword  Action = "user.reset_password"
+	UserRevokeSessions Action = "user.revoke_sessions"
+	UserBan            Action = "user.ban"
+	UserUnban          Action = "user.unban"
+	UserImpersonate    Action = "user.impersonate"
+
+	AccountDelete Action = "account.delete"
+
+	ArticleCreate     Action = "article.create"
+	ArticleUpdate     Action = "article.update"
+	ArticleDelete     Action = "article.delete"
+	ArticleRestore    Action = "article.restore"
+	ArticleReadHidden Action = "article.read_hidden"
+	ArticleFavorite   Action = "article.favorite"
+
+	CommentCreate  Action = "comment.create"
+	CommentUpdate  Action = "comment.update"
+	CommentDelete  Action = "comment.delete"
+	CommentRestore Action = "comment.restore"
+
+	ModerationRead    Action = "moderation.read"
+	ModerationClaim   Action = "moderation.claim"
+	ModerationResolve Action = "moderation.resolve" // resource is the case, its owner is the assignee
+	ModerationBan     Action = "moderation.ban"
+
+	WebhookCreateGlobal Action = "webhook.create_global"
+	WebhookManage       Action = "webhook.manage"
+)
+
+// Roles - роли в порядке возрастания прав
+var Roles = []entity.RoleType{entity.RoleUser, entity.RoleModerator, entity.RoleAdmin}
+
+// GrantRole - назначение конкретной роли проверяется отдельно от права менять роль пользователя
+func GrantRole(role entity.RoleType) Action {
+	return Action("user.grant_role." + string(role))
+}
+
+// Actions - все известные действия, правила с другими действиями не загружаются
+func Actions() []Action {
+	actions := []Action{
+		AdminAccess, AuditRead,
+		UserUpdate, UserUpdateEmail, UserSetRole, UserDelete, UserRestore, UserSearch,
+		UserResetPassword, UserRevokeSessions, UserBan, UserUnban, UserImpersonate,
+		AccountDelete,
+		ArticleCreate, ArticleUpdate, ArticleDelete, ArticleRestore, ArticleReadHidden, ArticleFavorite,
+		CommentCreate, CommentUpdate, CommentDelete, CommentRestore,
+		ModerationRead, ModerationClaim, ModerationResolve, ModerationBan,
+		WebhookCreateGlobal, WebhookManage,
+	}
+	for _, role := range Roles {
+		actions = append(actions, GrantRole(role))
+	}
+	return actions
+}
@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// Ownership - отношение субъекта к владельцу ресурса
+type Ownership string
+
+const (
+	OwnershipAny    Ownership = "any"
+	OwnershipOwn    Ownership = "own"
+	OwnershipOthers Ownership = "others"
+)
+
+// Rule - разрешение ролям выполнять действия над ресурсами.
+// Все, что не разрешено правилами, запрещено
+type Rule struct {
+	Roles      []entity.RoleType `yaml:"roles"`
+	Actions    []Action          `yaml:"actions"`
+	Ownership  Ownership         `yaml:"ownership"`   // any when empty
+	OwnerRoles []entity.RoleType `yaml:"owner_roles"` // any owner role when empty
+}
+
+// Subject - пользователь, от имени которого выполняется запрос
+type Subject struct {
+	ID             uuid.UUID
+	Role           entity.RoleType
+	ImpersonatorID uuid.NullUUID // admin acting on behalf of the user, rules apply to the user
+}
+
+// Resource - владелец ресурса, над которым выполняется действие
+type Resource struct {
+	OwnerID   uuid.UUID
+	OwnerRole entity.RoleType // empty when the owner role is not known
+}
+
+// Any - ресурс без владельца, для действий над системой в целом
+var Any = Resource{}
+
+func User(user entity.User) Resource {
+	return Resource{OwnerID: user.ID, OwnerRole: user.Role}
+}
+
+func Article(article entity.Article) Resource {
+	return Resource{OwnerID: article.AuthorID}
+}
+
+func Comment(comment entity.Comment) Resource {
+	return Resource{OwnerID: comment.AuthorID}
+}
+
+func Webhook(webhook entity.Webhook) Resource {
+	return Resource{OwnerID: webhook.OwnerID}
+}
+
+// ModerationCase - владелец кейса - модератор, взявший его в работу, у открытого и закрытого кейса владельца нет
+func ModerationCase(moderationCase entity.ModerationCase) Resource {
+	if moderationCase.Status != entity.ModerationStatusClaimed {
+		return Any
+	}
+	return Resource{OwnerID: moderationCase.AssigneeID.UUID}
+}
+
+type Policy struct {
+	rules map[Action][]Rule
+}
+
+// New - правила проверяются при загрузке, опечатка в конфигурации не должна молча запрещать или разрешать действия
+func New(rules []Rule) (*Policy, error) {
+	knownActions := make(map[Action]bool)
+	for _, action := range Actions() {
+		knownActions[action] = true
+	}
+
+	p := &Policy{
+		rules: make(map[Action][]Rule),
+	}
+
+	for i, rule := range rules {
+		if len(rule.Roles) == 0 || len(rule.Actions) == 0 {
+			return nil, fmt.Errorf("policy rule %d: roles and actions are required", i)
+		}
+
+		for _, roles := range [][]entity.RoleType{rule.Roles, rule.OwnerRoles} {
+			for _, role := range roles {
+				if !isKnownRole(role) {
+					return nil, fmt.Errorf("policy rule %d: unknown role %q", i, role)
+				}
+			}
+		}
+
+		switch rule.Ownership {
+		case "":
+			rule.Ownership = OwnershipAny
+		case OwnershipAny, OwnershipOwn, OwnershipOthers:
+		default:
+			return nil, fmt.Errorf("policy rule %d: unknown ownership %q", i, rule.Ownership)
+		}
+
+		for _, action := range rule.Actions {
+			if !knownActions[action] {
+				return nil, fmt.Errorf("policy rule %d: unknown action %q", i, action)
+			}
+			p.rules[action] = append(p.rules[action], rule)
+		}
+	}
+
+	return p, nil
+}
+
+// Can - разрешено ли субъекту из контекста действие над ресурсом, без субъекта ничего не разрешено
+func (p *Policy) Can(ctx context.Context, action Action, resource Resource) bool {
+	subject, ok := SubjectFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, rule := range p.rules[action] {
+		if rule.allows(subject, resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Rule) allows(subject Subject, resource Resource) bool {
+	if !containsRole(r.Roles, subject.Role) {
+		return false
+	}
+
+	own := resource.OwnerID != uuid.Nil && resource.OwnerID == subject.ID
+	switch r.Ownership {
+	case OwnershipOwn:
+		if !own {
+			return false
+		}
+	case OwnershipOthers:
+		if own {
+			return false
+		}
+	}
+
+	// an unknown owner role never matches a restricted rule
+	if len(r.OwnerRoles) > 0 && !containsRole(r.OwnerRoles, resource.OwnerRole) {
+		return false
+	}
+
+	return true
+}
+
+func isKnownRole(role entity.RoleType) bool {
+	return containsRole(Roles, role)
+}
+
+func containsRole(roles []entity.RoleType, role entity.RoleType) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type subjectKey struct{}
+
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	subject, ok := ctx.Value(subjectKey{}).(Subject)
+	return subject, ok
+}
@@ -0,0 +1,325 @@
+package policy
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+// case keys: "<role>/own" - own resource,
+// "<role>/others:<owner role>" - resource of another user, empty owner role - unknown owner or no resource at all
+var defaultRulesTable = map[Action][]string{
+	AdminAccess:                     {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	AuditRead:                       {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	UserSearch:                      {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	UserRestore:                     {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	GrantRole(entity.RoleUser):      {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	GrantRole(entity.RoleModerator): {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	GrantRole(entity.RoleAdmin):     {},
+
+	UserUpdate: {
+		"user/own", "moderator/own", "admin/own",
+		"moderator/others:user",
+		"admin/others:user", "admin/others:moderator",
+	},
+	UserUpdateEmail:    {"user/own", "moderator/own", "admin/own", "admin/others:user", "admin/others:moderator"},
+	UserSetRole:        {"admin/others:user", "admin/others:moderator"},
+	UserDelete:         {"admin/others:user", "admin/others:moderator"},
+	UserResetPassword:  {"admin/others:user", "admin/others:moderator"},
+	UserRevokeSessions: {"admin/others:user", "admin/others:moderator"},
+	UserBan:            {"admin/others:user", "admin/others:moderator"},
+	UserUnban:          {"admin/others:user", "admin/others:moderator"},
+	UserImpersonate:    {"admin/others:user", "admin/others:moderator"},
+
+	AccountDelete: {"user/own", "moderator/own"},
+
+	ArticleCreate: {
+		"user/own", "user/others:user", "user/others:moderator", "user/others:admin", "user/others:",
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	ArticleFavorite: {
+		"user/own", "user/others:user", "user/others:moderator", "user/others:admin", "user/others:",
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	ArticleUpdate: {
+		"user/own", "moderator/own",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	ArticleDelete: {
+		"user/own",
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	ArticleRestore: {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	ArticleReadHidden: {
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+
+	CommentCreate: {
+		"user/own", "user/others:user", "user/others:moderator", "user/others:admin", "user/others:",
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	CommentUpdate: {
+		"user/own", "moderator/own",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	CommentDelete: {
+		"user/own",
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	CommentRestore: {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+
+	ModerationRead: {
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	ModerationClaim: {
+		"moderator/own", "moderator/others:user", "moderator/others:moderator", "moderator/others:admin", "moderator/others:",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	ModerationResolve: {
+		"moderator/own",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+	ModerationBan: {"moderator/others:user", "admin/others:user", "admin/others:moderator"},
+
+	WebhookCreateGlobal: {"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:"},
+	WebhookManage: {
+		"user/own", "moderator/own",
+		"admin/own", "admin/others:user", "admin/others:moderator", "admin/others:admin", "admin/others:",
+	},
+}
+
+type policyCase struct {
+	key      string
+	subject  Subject
+	resource Resource
+}
+
+// policyCases - все сочетания роли субъекта, владельца ресурса и его роли
+func policyCases() []policyCase {
+	var cases []policyCase
+	for _, role := range Roles {
+		subject := Subject{ID: uuid.New(), Role: role}
+
+		cases = append(cases, policyCase{
+			key:      fmt.Sprintf("%s/own", role),
+			subject:  subject,
+			resource: Resource{OwnerID: subject.ID, OwnerRole: role},
+		})
+
+		for _, ownerRole := range append([]entity.RoleType{""}, Roles...) {
+			cases = append(cases, policyCase{
+				key:      fmt.Sprintf("%s/others:%s", role, ownerRole),
+				subject:  subject,
+				resource: Resource{OwnerID: uuid.New(), OwnerRole: ownerRole},
+			})
+		}
+
+		// no resource behaves as a resource of an unknown user
+		cases = append(cases, policyCase{
+			key:      fmt.Sprintf("%s/others:", role),
+			subject:  subject,
+			resource: Any,
+		})
+	}
+	return cases
+}
+
+func TestDefaultRules(t *testing.T) {
+	p, err := New(DefaultRules())
+	if err != nil {
+		t.Fatalf("New(DefaultRules()) error = %v", err)
+	}
+
+	for _, action := range Actions() {
+		allowedKeys, ok := defaultRulesTable[action]
+		if !ok {
+			t.Errorf("action %s is missing in the table", action)
+			continue
+		}
+
+		allowed := make(map[string]bool)
+		for _, key := range allowedKeys {
+			allowed[key] = true
+		}
+
+		for _, c := range policyCases() {
+			t.Run(fmt.Sprintf("%s/%s", action, c.key), func(t *testing.T) {
+				ctx := WithSubject(context.Background(), c.subject)
+
+				got := p.Can(ctx, action, c.resource)
+				if got != allowed[c.key] {
+					t.Errorf("Can() = %v, want %v", got, allowed[c.key])
+				}
+			})
+		}
+	}
+}
+
+func TestCanWithoutSubject(t *testing.T) {
+	p, err := New(DefaultRules())
+	if err != nil {
+		t.Fatalf("New(DefaultRules()) error = %v", err)
+	}
+
+	for _, action := range Actions() {
+		if p.Can(context.Background(), action, Any) {
+			t.Errorf("Can(%s) without subject = true, want false", action)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr bool
+	}{
+		{
+			name:  "no rules",
+			rules: nil,
+		},
+		{
+			name:  "empty ownership",
+			rules: []Rule{{Roles: []entity.RoleType{entity.RoleUser}, Actions: []Action{ArticleCreate}}},
+		},
+		{
+			name: "all fields",
+			rules: []Rule{{
+				Roles:      []entity.RoleType{entity.RoleModerator},
+				Actions:    []Action{UserUpdate},
+				Ownership:  OwnershipOthers,
+				OwnerRoles: []entity.RoleType{entity.RoleUser},
+			}},
+		},
+		{
+			name:    "no roles",
+			rules:   []Rule{{Actions: []Action{ArticleCreate}}},
+			wantErr: true,
+		},
+		{
+			name:    "no actions",
+			rules:   []Rule{{Roles: []entity.RoleType{entity.RoleUser}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown role",
+			rules:   []Rule{{Roles: []entity.RoleType{"guest"}, Actions: []Action{ArticleCreate}}},
+			wantErr: true,
+		},
+		{
+			name: "unknown owner role",
+			rules: []Rule{{
+				Roles:      []entity.RoleType{entity.RoleUser},
+				Actions:    []Action{ArticleCreate},
+				OwnerRoles: []entity.RoleType{"guest"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown action",
+			rules:   []Rule{{Roles: []entity.RoleType{entity.RoleUser}, Actions: []Action{"article.publish"}}},
+			wantErr: true,
+		},
+		{
+			name: "unknown ownership",
+			rules: []Rule{{
+				Roles:     []entity.RoleType{entity.RoleUser},
+				Actions:   []Action{ArticleCreate},
+				Ownership: "mine",
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRulesFromConfig(t *testing.T) {
+	const config = `
+- roles: [user, moderator, admin]
+  actions: [comment.update]
+  ownership: own
+- roles: [moderator]
+  actions: [user.update]
+  ownership: others
+  owner_roles: [user]
+`
+
+	var rules []Rule
+	err := yaml.Unmarshal([]byte(config), &rules)
+	if err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	p, err := New(rules)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	moderator := Subject{ID: uuid.New(), Role: entity.RoleModerator}
+	ctx := WithSubject(context.Background(), moderator)
+
+	tests := []struct {
+		name     string
+		action   Action
+		resource Resource
+		want     bool
+	}{
+		{"own comment", CommentUpdate, Resource{OwnerID: moderator.ID}, true},
+		{"other comment", CommentUpdate, Resource{OwnerID: uuid.New()}, false},
+		{"other user", UserUpdate, Resource{OwnerID: uuid.New(), OwnerRole: entity.RoleUser}, true},
+		{"other moderator", UserUpdate, Resource{OwnerID: uuid.New(), OwnerRole: entity.RoleModerator}, false},
+		{"not configured", ArticleCreate, Any, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Can(ctx, tt.action, tt.resource); got != tt.want {
+				t.Errorf("Can() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModerationCase(t *testing.T) {
+	assignee := uuid.New()
+
+	tests := []struct {
+		name   string
+		status entity.ModerationStatus
+		want   uuid.UUID
+	}{
+		{"open", entity.ModerationStatusOpen, uuid.Nil},
+		{"claimed", entity.ModerationStatusClaimed, assignee},
+		{"resolved", entity.ModerationStatusResolved, uuid.Nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := ModerationCase(entity.ModerationCase{
+				Status:     tt.status,
+				AssigneeID: uuid.NullUUID{UUID: assignee, Valid: true},
+			})
+			if resource.OwnerID != tt.want {
+				t.Errorf("ModerationCase().OwnerID = %v, want %v", resource.OwnerID, tt.want)
+			}
+		})
+	}
+}
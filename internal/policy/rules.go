@@ -0,0 +1,74 @@
+package policy
+
+import "blog-backend/internal/entity"
+
+var (
+	everyone   = []entity.RoleType{entity.RoleUser, entity.RoleModerator, entity.RoleAdmin}
+	moderators = []entity.RoleType{entity.RoleModerator, entity.RoleAdmin}
+	admins     = []entity.RoleType{entity.RoleAdmin}
+
+	// admins are never managed by other users, moderators only by admins
+	manageableByModerators = []entity.RoleType{entity.RoleUser}
+	manageableByAdmins     = []entity.RoleType{entity.RoleUser, entity.RoleModerator}
+)
+
+// DefaultRules - правила, действующие, если в конфигурации правила не заданы
+func DefaultRules() []Rule {
+	return []Rule{
+		// own profile and content
+		{
+			Roles:     everyone,
+			Actions:   []Action{UserUpdate, UserUpdateEmail, ArticleUpdate, ArticleDelete, CommentUpdate, CommentDelete, WebhookManage},
+			Ownership: OwnershipOwn,
+		},
+		{
+			Roles:     everyone,
+			Actions:   []Action{ArticleCreate, ArticleFavorite, CommentCreate},
+			Ownership: OwnershipAny,
+		},
+		// admins can't delete their accounts, the same as through the admin API
+		{
+			Roles:     []entity.RoleType{entity.RoleUser, entity.RoleModerator},
+			Actions:   []Action{AccountDelete},
+			Ownership: OwnershipOwn,
+		},
+
+		// moderation
+		{
+			Roles:     moderators,
+			Actions:   []Action{ArticleDelete, ArticleReadHidden, CommentDelete, ModerationRead, ModerationClaim},
+			Ownership: OwnershipAny,
+		},
+		{
+			Roles:     []entity.RoleType{entity.RoleModerator},
+			Actions:   []Action{ModerationResolve},
+			Ownership: OwnershipOwn,
+		},
+		{
+			Roles:      []entity.RoleType{entity.RoleModerator},
+			Actions:    []Action{UserUpdate, ModerationBan},
+			Ownership:  OwnershipOthers,
+			OwnerRoles: manageableByModerators,
+		},
+
+		// administration
+		{
+			Roles: admins,
+			Actions: []Action{
+				AdminAccess, AuditRead, UserSearch, UserRestore, GrantRole(entity.RoleUser), GrantRole(entity.RoleModerator),
+				ArticleUpdate, ArticleRestore, CommentUpdate, CommentRestore,
+				ModerationResolve, WebhookCreateGlobal, WebhookManage,
+			},
+			Ownership: OwnershipAny,
+		},
+		{
+			Roles: admins,
+			Actions: []Action{
+				UserUpdate, UserUpdateEmail, UserSetRole, UserDelete, UserResetPassword, UserRevokeSessions,
+				UserBan, UserUnban, UserImpersonate, ModerationBan,
+			},
+			Ownership:  OwnershipOthers,
+			OwnerRoles: manageableByAdmins,
+		},
+	}
+}
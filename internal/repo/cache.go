@@ -0,0 +1,417 @@
+package repo
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/metrics"
+	"blog-backend/pkg/cache"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+	"time"
+)
+
+const (
+	articleCache = "article"
+	userCache    = "user"
+)
+
+// WithCache - статьи по id и пользователи по username читаются через кеш на ttl.
+// Записи через эти репозитории, администрирование, решения модерации и очистка удаленного сбрасывают затронутые ключи.
+// Счетчики, которые меняют другие репозитории (комментарии, подписки), могут отставать не больше чем на ttl.
+// Обертки не встраивают интерфейс, поэтому новый метод без обертки не скомпилируется
+func WithCache(repos *Repositories, c cache.Cache, ttl time.Duration, m *metrics.Metrics) *Repositories {
+	rt := &readThrough{cache: c, ttl: ttl, metrics: m}
+
+	cached := *repos
+	cached.User = &userCached{next: repos.User, articles: repos.Article, cache: rt}
+	cached.Article = &articleCached{next: repos.Article, cache: rt}
+	cached.Admin = &adminCached{next: repos.Admin, users: repos.User, cache: rt}
+	cached.Moderation = &moderationCached{next: repos.Moderation, users: repos.User, cache: rt}
+	cached.Retention = &retentionCached{next: repos.Retention, cache: rt}
+
+	return &cached
+}
+
+func articleKey(id uuid.UUID) string {
+	return "article:id:" + id.String()
+}
+
+func userKey(username string) string {
+	return "user:username:" + username
+}
+
+// readThrough - ошибки кеша не ломают чтение: значение берется из репозитория, ошибка пишется в лог
+type readThrough struct {
+	cache   cache.Cache
+	ttl     time.Duration
+	metrics *metrics.Metrics
+	group   singleflight.Group
+}
+
+// load - одновременные промахи по одному ключу загружают значение один раз и из primary,
+// чтобы в кеш на весь ttl не попало значение отстающей реплики.
+// Чтения в транзакции идут мимо кеша: транзакция видит свои незафиксированные изменения и может откатиться
+func load[T any](ctx context.Context, c *readThrough, name, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	if postgres.InTx(ctx) {
+		return fn(ctx)
+	}
+
+	data, err := c.cache.Get(ctx, key)
+	if err == nil {
+		var value T
+		err = json.Unmarshal(data, &value)
+		if err == nil {
+			c.metrics.CacheRequest(name, "hit")
+			return value, nil
+		}
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		logger.FromContext(ctx).Warnf("repo - cache - %s: %v", key, err)
+	}
+	c.metrics.CacheRequest(name, "miss")
+
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+		// the load is shared, a canceled first caller must not fail the others
+		ctx := postgres.WithPrimary(context.WithoutCancel(ctx))
+
+		value, err := fn(ctx)
+		if err != nil {
+			return value, err
+		}
+
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = c.cache.Set(ctx, key, data, c.ttl)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Warnf("repo - cache - %s: %v", key, err)
+		}
+
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return value.(T), nil
+}
+
+// invalidate - в транзакции TxManager ключи сбрасываются после фиксации: до нее другие запросы
+// еще читают старые значения и закешировали бы их заново
+func (c *readThrough) invalidate(ctx context.Context, keys ...string) {
+	postgres.AfterCommit(ctx, func(ctx context.Context) {
+		err := c.cache.Delete(ctx, keys...)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("repo - cache - invalidate %v: %v", keys, err)
+		}
+	})
+}
+
+// invalidateUser - пользователи кешируются по username, а изменяются по id, поэтому username ищется до изменения.
+// Ключ сбрасывается и при ошибке изменения: неизвестно, успело ли оно примениться
+func (c *readThrough) invalidateUser(ctx context.Context, users User, userID uuid.UUID, write func() error) error {
+	user, lookupErr := users.GetUserByID(ctx, userID)
+
+	err := write()
+
+	if lookupErr == nil {
+		c.invalidate(ctx, userKey(user.Username))
+	}
+
+	return err
+}
+
+type userCached struct {
+	next     User
+	articles Article
+	cache    *readThrough
+}
+
+func (r *userCached) CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error) {
+	return r.next.CreateUser(ctx, user)
+}
+
+func (r *userCached) UpdateUserPassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
+	return r.cache.invalidateUser(ctx, r.next, userID, func() error {
+		return r.next.UpdateUserPassword(ctx, userID, oldPassword, newPassword)
+	})
+}
+
+func (r *userCached) UpdateUserByID(ctx context.Context, userID uuid.UUID, name, email, description *string, role *entity.RoleType) error {
+	return r.cache.invalidateUser(ctx, r.next, userID, func() error {
+		return r.next.UpdateUserByID(ctx, userID, name, email, description, role)
+	})
+}
+
+func (r *userCached) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error) {
+	return r.next.GetUserByUsernameAndPassword(ctx, username, password)
+}
+
+func (r *userCached) GetUserByID(ctx context.Context, userID uuid.UUID) (entity.User, error) {
+	return r.next.GetUserByID(ctx, userID)
+}
+
+// GetUserByUsername - хеш пароля не попадает в кеш, поэтому не возвращается и при промахе
+func (r *userCached) GetUserByUsername(ctx context.Context, username string) (entity.User, error) {
+	return load(ctx, r.cache, userCache, userKey(username), func(ctx context.Context) (entity.User, error) {
+		user, err := r.next.GetUserByUsername(ctx, username)
+		user.Password = ""
+		return user, err
+	})
+}
+
+// GetUsersByIDs - пакетные чтения идут мимо кеша, им достаточно одного запроса
+func (r *userCached) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]entity.User, error) {
+	return r.next.GetUsersByIDs(ctx, userIDs)
+}
+
+func (r *userCached) SetUserFollower(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) (bool, error) {
+	return r.next.SetUserFollower(ctx, followerID, followingID)
+}
+
+func (r *userCached) GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	return r.next.GetUserFollowers(ctx, userID)
+}
+
+func (r *userCached) GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	return r.next.GetUserFollowings(ctx, userID)
+}
+
+// DeleteUserByID - статьи удаляются вместе с пользователем, их ключи тоже сбрасываются
+func (r *userCached) DeleteUserByID(ctx context.Context, userID uuid.UUID) error {
+	articles, lookupErr := r.articles.GetArticlesByAuthorID(ctx, userID)
+
+	err := r.cache.invalidateUser(ctx, r.next, userID, func() error {
+		return r.next.DeleteUserByID(ctx, userID)
+	})
+
+	if lookupErr == nil && len(articles) > 0 {
+		keys := make([]string, 0, len(articles))
+		for _, article := range articles {
+			keys = append(keys, articleKey(article.Id))
+		}
+		r.cache.invalidate(ctx, keys...)
+	}
+
+	return err
+}
+
+// RestoreUserByID - удаленный пользователь не кешируется, сбрасывать нечего
+func (r *userCached) RestoreUserByID(ctx context.Context, userID uuid.UUID) error {
+	return r.next.RestoreUserByID(ctx, userID)
+}
+
+func (r *userCached) ScheduleUserDeletion(ctx context.Context, userID uuid.UUID, gracePeriod time.Duration) (time.Time, error) {
+	var scheduledAt time.Time
+	err := r.cache.invalidateUser(ctx, r.next, userID, func() error {
+		var err error
+		scheduledAt, err = r.next.ScheduleUserDeletion(ctx, userID, gracePeriod)
+		return err
+	})
+
+	return scheduledAt, err
+}
+
+func (r *userCached) CancelUserDeletion(ctx context.Context, userID uuid.UUID) error {
+	return r.cache.invalidateUser(ctx, r.next, userID, func() error {
+		return r.next.CancelUserDeletion(ctx, userID)
+	})
+}
+
+type articleCached struct {
+	next  Article
+	cache *readThrough
+}
+
+func (r *articleCached) CreateArticle(ctx context.Context, article entity.Article) (uuid.UUID, error) {
+	return r.next.CreateArticle(ctx, article)
+}
+
+func (r *articleCached) GetArticleByID(ctx context.Context, id uuid.UUID) (entity.Article, error) {
+	return load(ctx, r.cache, articleCache, articleKey(id), func(ctx context.Context) (entity.Article, error) {
+		return r.next.GetArticleByID(ctx, id)
+	})
+}
+
+func (r *articleCached) GetArticlesByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Article, error) {
+	return r.next.GetArticlesByAuthorID(ctx, authorID)
+}
+
+func (r *articleCached) GetNewestArticles(ctx context.Context, limit, offset int) ([]entity.Article, error) {
+	return r.next.GetNewestArticles(ctx, limit, offset)
+}
+
+func (r *articleCached) SetArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) (bool, error) {
+	defer r.cache.invalidate(ctx, articleKey(articleID))
+	return r.next.SetArticleFavorite(ctx, userID, articleID)
+}
+
+func (r *articleCached) RemoveArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) error {
+	defer r.cache.invalidate(ctx, articleKey(articleID))
+	return r.next.RemoveArticleFavorite(ctx, userID, articleID)
+}
+
+func (r *articleCached) GetFavoriteArticles(ctx context.Context, userID uuid.UUID) ([]entity.Article, error) {
+	return r.next.GetFavoriteArticles(ctx, userID)
+}
+
+func (r *articleCached) GetFavoritedArticleIDs(ctx context.Context, userID uuid.UUID, articleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	return r.next.GetFavoritedArticleIDs(ctx, userID, articleIDs)
+}
+
+func (r *articleCached) GetFeedArticles(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Article, error) {
+	return r.next.GetFeedArticles(ctx, userID, limit, offset)
+}
+
+func (r *articleCached) SearchArticles(ctx context.Context, query string, limit, offset int) ([]entity.Article, error) {
+	return r.next.SearchArticles(ctx, query, limit, offset)
+}
+
+func (r *articleCached) GetArticlesTags(ctx context.Context, articleIDs []uuid.UUID) (map[uuid.UUID][]entity.Tag, error) {
+	return r.next.GetArticlesTags(ctx, articleIDs)
+}
+
+func (r *articleCached) UpdateArticleByID(ctx context.Context, articleID, editorID uuid.UUID, title, description, content *string) error {
+	defer r.cache.invalidate(ctx, articleKey(articleID))
+	return r.next.UpdateArticleByID(ctx, articleID, editorID, title, description, content)
+}
+
+func (r *articleCached) DeleteArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	defer r.cache.invalidate(ctx, articleKey(articleID))
+	return r.next.DeleteArticleByID(ctx, articleID)
+}
+
+// RestoreArticleByID - удаленная статья не кешируется, сбрасывать нечего
+func (r *articleCached) RestoreArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	return r.next.RestoreArticleByID(ctx, articleID)
+}
+
+type adminCached struct {
+	next  Admin
+	users User
+	cache *readThrough
+}
+
+func (r *adminCached) SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	return r.next.SearchUsers(ctx, filter)
+}
+
+func (r *adminCached) SetUserRole(ctx context.Context, userID uuid.UUID, role entity.RoleType, entry entity.AuditEntry) error {
+	return r.cache.invalidateUser(ctx, r.users, userID, func() error {
+		return r.next.SetUserRole(ctx, userID, role, entry)
+	})
+}
+
+func (r *adminCached) SetUserBanned(ctx context.Context, userID uuid.UUID, banned bool, entry entity.AuditEntry) error {
+	return r.cache.invalidateUser(ctx, r.users, userID, func() error {
+		return r.next.SetUserBanned(ctx, userID, banned, entry)
+	})
+}
+
+func (r *adminCached) ResetUserPassword(ctx context.Context, userID uuid.UUID, password string, entry entity.AuditEntry) error {
+	return r.cache.invalidateUser(ctx, r.users, userID, func() error {
+		return r.next.ResetUserPassword(ctx, userID, password, entry)
+	})
+}
+
+func (r *adminCached) RevokeUserSessions(ctx context.Context, userID uuid.UUID, entry entity.AuditEntry) error {
+	return r.cache.invalidateUser(ctx, r.users, userID, func() error {
+		return r.next.RevokeUserSessions(ctx, userID, entry)
+	})
+}
+
+type moderationCached struct {
+	next  Moderation
+	users User
+	cache *readThrough
+}
+
+func (r *moderationCached) CreateReport(ctx context.Context, moderationCase entity.ModerationCase, report entity.Report) (entity.Report, error) {
+	return r.next.CreateReport(ctx, moderationCase, report)
+}
+
+func (r *moderationCached) GetModerationCaseByID(ctx context.Context, id uuid.UUID) (entity.ModerationCase, error) {
+	return r.next.GetModerationCaseByID(ctx, id)
+}
+
+func (r *moderationCached) GetModerationCases(ctx context.Context, status *entity.ModerationStatus, targetType *entity.ReportTargetType, limit, offset int) ([]entity.ModerationCase, error) {
+	return r.next.GetModerationCases(ctx, status, targetType, limit, offset)
+}
+
+func (r *moderationCached) GetReportsByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.Report, error) {
+	return r.next.GetReportsByCaseID(ctx, caseID)
+}
+
+func (r *moderationCached) ClaimModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID) error {
+	return r.next.ClaimModerationCase(ctx, moderationCase, moderatorID)
+}
+
+// ResolveModerationCase - скрытие и удаление меняют статью, бан - автора цели
+func (r *moderationCached) ResolveModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) error {
+	resolve := func() error {
+		return r.next.ResolveModerationCase(ctx, moderationCase, moderatorID, action, note)
+	}
+
+	if action == entity.ModerationActionBan {
+		return r.cache.invalidateUser(ctx, r.users, moderationCase.TargetAuthorID, resolve)
+	}
+
+	if moderationCase.TargetType == entity.ReportTargetArticle {
+		defer r.cache.invalidate(ctx, articleKey(moderationCase.TargetID))
+	}
+
+	return resolve()
+}
+
+func (r *moderationCached) GetModerationLog(ctx context.Context, moderatorID, caseID uuid.NullUUID, limit, offset int) ([]entity.ModerationLogEntry, error) {
+	return r.next.GetModerationLog(ctx, moderatorID, caseID, limit, offset)
+}
+
+// retentionCached - анонимизация удаляет закешированного пользователя и меняет автора его статей.
+// Ключи сбрасываются и при ошибке: строки, очищенные до нее, уже зафиксированы.
+// Очистка комментариев меняет только счетчики статей, они могут отставать не больше чем на ttl
+type retentionCached struct {
+	next  Retention
+	cache *readThrough
+}
+
+func (r *retentionCached) PurgeDeletedComments(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	return r.next.PurgeDeletedComments(ctx, olderThan, limit)
+}
+
+func (r *retentionCached) PurgeDeletedArticles(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	purged, err := r.next.PurgeDeletedArticles(ctx, olderThan, limit)
+	r.invalidate(ctx, purged)
+	return purged, err
+}
+
+func (r *retentionCached) PurgeDeletedUsers(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	purged, err := r.next.PurgeDeletedUsers(ctx, olderThan, limit)
+	r.invalidate(ctx, purged)
+	return purged, err
+}
+
+func (r *retentionCached) AnonymizeScheduledUsers(ctx context.Context, limit int) (entity.Purged, error) {
+	purged, err := r.next.AnonymizeScheduledUsers(ctx, limit)
+	r.invalidate(ctx, purged)
+	return purged, err
+}
+
+func (r *retentionCached) invalidate(ctx context.Context, purged entity.Purged) {
+	keys := make([]string, 0, len(purged.Usernames)+len(purged.ArticleIDs))
+	for _, username := range purged.Usernames {
+		keys = append(keys, userKey(username))
+	}
+	for _, id := range purged.ArticleIDs {
+		keys = append(keys, articleKey(id))
+	}
+
+	if len(keys) > 0 {
+		r.cache.invalidate(ctx, keys...)
+	}
+}
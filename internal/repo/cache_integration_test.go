@@ -0,0 +1,59 @@
+//go:build integration
+
+package repo_test
+
+import (
+	"blog-backend/internal/repo"
+	"blog-backend/internal/testutil/pgtest"
+	"blog-backend/pkg/cache"
+	"blog-backend/pkg/postgres"
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Main(m))
+}
+
+// a read that refills the cache while the transaction is open must not keep the old value after commit
+func TestWithCache_InvalidatedAfterCommit(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	repos := repo.WithCache(repo.NewRepositories(pg), cache.NewLRU(100), time.Minute, nil)
+	ctx := context.Background()
+
+	alice, err := repos.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "Alice Pleasance Liddell"
+	err = postgres.NewTxManager(pg).Do(ctx, func(txCtx context.Context) error {
+		err := repos.UpdateUserByID(txCtx, alice.ID, &name, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		// another request still sees the committed name
+		user, err := repos.GetUserByUsername(ctx, "alice")
+		if err != nil {
+			return err
+		}
+		if user.Name != alice.Name {
+			t.Errorf("name before commit = %q, want %q", user.Name, alice.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := repos.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != name {
+		t.Errorf("name after commit = %q, want %q", user.Name, name)
+	}
+}
@@ -0,0 +1,214 @@
+package repo_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/mocks"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/cache"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"sync"
+	"testing"
+	"time"
+)
+
+type cachedRepos struct {
+	user       *mocks.MockUser
+	article    *mocks.MockArticle
+	admin      *mocks.MockAdmin
+	moderation *mocks.MockModeration
+	retention  *mocks.MockRetention
+	repos      *repo.Repositories
+}
+
+func newCachedRepos(t *testing.T) cachedRepos {
+	ctrl := gomock.NewController(t)
+
+	r := cachedRepos{
+		user:       mocks.NewMockUser(ctrl),
+		article:    mocks.NewMockArticle(ctrl),
+		admin:      mocks.NewMockAdmin(ctrl),
+		moderation: mocks.NewMockModeration(ctrl),
+		retention:  mocks.NewMockRetention(ctrl),
+	}
+	r.repos = repo.WithCache(&repo.Repositories{
+		User:       r.user,
+		Article:    r.article,
+		Admin:      r.admin,
+		Moderation: r.moderation,
+		Retention:  r.retention,
+	}, cache.NewLRU(100), time.Minute, nil)
+
+	return r
+}
+
+func TestWithCache_Article(t *testing.T) {
+	ctx := context.Background()
+	r := newCachedRepos(t)
+	article := entity.Article{Id: uuid.New(), Title: "title", FavoritesCount: 1}
+
+	r.article.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+
+	for i := 0; i < 3; i++ {
+		got, err := r.repos.GetArticleByID(ctx, article.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != article.Title || got.FavoritesCount != 1 {
+			t.Errorf("cached article = %+v", got)
+		}
+	}
+
+	// every write to the article drops it from the cache
+	writes := []func() error{
+		func() error {
+			_, err := r.repos.SetArticleFavorite(ctx, uuid.New(), article.Id)
+			return err
+		},
+		func() error { return r.repos.RemoveArticleFavorite(ctx, uuid.New(), article.Id) },
+		func() error { return r.repos.UpdateArticleByID(ctx, article.Id, uuid.New(), nil, nil, nil) },
+		func() error { return r.repos.DeleteArticleByID(ctx, article.Id) },
+	}
+	r.article.EXPECT().SetArticleFavorite(gomock.Any(), gomock.Any(), article.Id)
+	r.article.EXPECT().RemoveArticleFavorite(gomock.Any(), gomock.Any(), article.Id)
+	r.article.EXPECT().UpdateArticleByID(gomock.Any(), article.Id, gomock.Any(), nil, nil, nil)
+	r.article.EXPECT().DeleteArticleByID(gomock.Any(), article.Id)
+	r.article.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil).Times(len(writes))
+
+	for _, write := range writes {
+		if err := write(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.repos.GetArticleByID(ctx, article.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWithCache_ErrorsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	r := newCachedRepos(t)
+	id := uuid.New()
+
+	r.article.EXPECT().GetArticleByID(gomock.Any(), id).Return(entity.Article{}, repoerrs.ErrArticleNotFound).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := r.repos.GetArticleByID(ctx, id)
+		if !errors.Is(err, repoerrs.ErrArticleNotFound) {
+			t.Errorf("err = %v, want %v", err, repoerrs.ErrArticleNotFound)
+		}
+	}
+}
+
+func TestWithCache_Singleflight(t *testing.T) {
+	ctx := context.Background()
+	r := newCachedRepos(t)
+	article := entity.Article{Id: uuid.New()}
+
+	release := make(chan struct{})
+	r.article.EXPECT().GetArticleByID(gomock.Any(), article.Id).DoAndReturn(func(ctx context.Context, id uuid.UUID) (entity.Article, error) {
+		<-release
+		return article, nil
+	})
+
+	const readers = 10
+
+	var started, done sync.WaitGroup
+	started.Add(readers)
+	done.Add(readers)
+	for i := 0; i < readers; i++ {
+		go func() {
+			defer done.Done()
+			started.Done()
+			if _, err := r.repos.GetArticleByID(ctx, article.Id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// readers that come after the load has finished are served from the cache
+	started.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	done.Wait()
+}
+
+func TestWithCache_User(t *testing.T) {
+	ctx := context.Background()
+	r := newCachedRepos(t)
+	user := entity.User{ID: uuid.New(), Username: "dinah", Password: "hash", Role: entity.RoleUser}
+
+	r.user.EXPECT().GetUserByUsername(gomock.Any(), "dinah").Return(user, nil)
+
+	for i := 0; i < 2; i++ {
+		got, err := r.repos.GetUserByUsername(ctx, "dinah")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != user.ID || got.Password != "" {
+			t.Errorf("cached user = %+v, want no password", got)
+		}
+	}
+
+	// users are changed by id, the username is looked up first
+	role := entity.RoleModerator
+	r.user.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil).Times(3)
+	r.user.EXPECT().UpdateUserByID(gomock.Any(), user.ID, nil, nil, nil, &role)
+	r.admin.EXPECT().SetUserBanned(gomock.Any(), user.ID, true, gomock.Any())
+	r.moderation.EXPECT().ResolveModerationCase(gomock.Any(), gomock.Any(), gomock.Any(), entity.ModerationActionBan, "")
+
+	writes := []func() error{
+		func() error { return r.repos.UpdateUserByID(ctx, user.ID, nil, nil, nil, &role) },
+		func() error { return r.repos.SetUserBanned(ctx, user.ID, true, entity.AuditEntry{}) },
+		func() error {
+			return r.repos.ResolveModerationCase(ctx, entity.ModerationCase{TargetAuthorID: user.ID}, uuid.New(), entity.ModerationActionBan, "")
+		},
+	}
+	r.user.EXPECT().GetUserByUsername(gomock.Any(), "dinah").Return(user, nil).Times(len(writes))
+
+	for _, write := range writes {
+		if err := write(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.repos.GetUserByUsername(ctx, "dinah"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWithCache_Retention(t *testing.T) {
+	ctx := context.Background()
+	r := newCachedRepos(t)
+	user := entity.User{ID: uuid.New(), Username: "dinah"}
+	article := entity.Article{Id: uuid.New(), AuthorID: user.ID}
+
+	r.user.EXPECT().GetUserByUsername(gomock.Any(), "dinah").Return(user, nil).Times(2)
+	r.article.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil).Times(2)
+
+	read := func() {
+		if _, err := r.repos.GetUserByUsername(ctx, "dinah"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.repos.GetArticleByID(ctx, article.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read()
+	read()
+
+	// the batch failed after the account had been anonymized, its keys are dropped anyway
+	errPurge := errors.New("purge failed")
+	r.retention.EXPECT().AnonymizeScheduledUsers(gomock.Any(), 10).
+		Return(entity.Purged{Count: 1, Usernames: []string{"dinah"}, ArticleIDs: []uuid.UUID{article.Id}}, errPurge)
+
+	_, err := r.repos.AnonymizeScheduledUsers(ctx, 10)
+	if !errors.Is(err, errPurge) {
+		t.Errorf("err = %v, want %v", err, errPurge)
+	}
+
+	// loaded again after the invalidation
+	read()
+}
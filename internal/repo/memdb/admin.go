@@ -0,0 +1,103 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// AdminRepo - изменения пользователей администратором, каждое изменение записывается в журнал аудита под тем же мьютексом
+type AdminRepo struct {
+	*DB
+}
+
+func NewAdminRepo(db *DB) *AdminRepo {
+	return &AdminRepo{db}
+}
+
+func (r *AdminRepo) SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query := strings.ToLower(filter.Query)
+
+	var users []entity.User
+	for id, row := range r.users {
+		if id == entity.TombstoneUserID || (row.deletedAt != nil) != filter.Deleted {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(row.Username), query) &&
+			!strings.Contains(strings.ToLower(row.Name), query) &&
+			!strings.Contains(strings.ToLower(row.Email), query) {
+			continue
+		}
+		if filter.Role != nil && row.Role != *filter.Role {
+			continue
+		}
+		if filter.Banned != nil && (row.BannedAt != nil) != *filter.Banned {
+			continue
+		}
+		users = append(users, row.User)
+	}
+
+	sortByCreatedAt(users, func(user entity.User) time.Time { return user.CreatedAt }, true)
+
+	from, to := page(len(users), filter.Limit, filter.Offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return users[from:to], nil
+}
+
+func (r *AdminRepo) SetUserRole(ctx context.Context, userID uuid.UUID, role entity.RoleType, entry entity.AuditEntry) error {
+	return r.updateUser(userID, func(row *userRow, now time.Time) {
+		row.Role = role
+		row.UpdatedAt = now
+	}, entry)
+}
+
+// SetUserBanned - бан также отзывает все сессии пользователя
+func (r *AdminRepo) SetUserBanned(ctx context.Context, userID uuid.UUID, banned bool, entry entity.AuditEntry) error {
+	return r.updateUser(userID, func(row *userRow, now time.Time) {
+		row.BannedAt = nil
+		if banned {
+			row.BannedAt = &now
+			row.SessionsRevokedAt = &now
+		}
+	}, entry)
+}
+
+// ResetUserPassword - пароль заменяется временным, который пользователь обязан сменить, сессии отзываются
+func (r *AdminRepo) ResetUserPassword(ctx context.Context, userID uuid.UUID, password string, entry entity.AuditEntry) error {
+	return r.updateUser(userID, func(row *userRow, now time.Time) {
+		row.Password = password
+		row.PasswordResetRequired = true
+		row.SessionsRevokedAt = &now
+		row.UpdatedAt = now
+	}, entry)
+}
+
+func (r *AdminRepo) RevokeUserSessions(ctx context.Context, userID uuid.UUID, entry entity.AuditEntry) error {
+	return r.updateUser(userID, func(row *userRow, now time.Time) {
+		row.SessionsRevokedAt = &now
+	}, entry)
+}
+
+func (r *AdminRepo) updateUser(userID uuid.UUID, update func(row *userRow, now time.Time), entry entity.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.activeUserLocked(userID)
+	if !ok {
+		return repoerrs.ErrUserNotFound
+	}
+
+	update(row, r.now())
+	r.insertAuditEntryLocked(entry)
+
+	return nil
+}
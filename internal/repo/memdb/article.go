@@ -0,0 +1,359 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ArticleRepo struct {
+	*DB
+}
+
+func NewArticleRepo(db *DB) *ArticleRepo {
+	return &ArticleRepo{db}
+}
+
+func (a ArticleRepo) CreateArticle(ctx context.Context, article entity.Article) (uuid.UUID, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	author, ok := a.users[article.AuthorID]
+	if !ok {
+		return uuid.UUID{}, fmt.Errorf("ArticleRepo.CreateArticle - %w", errForeignKey("user", article.AuthorID))
+	}
+
+	now := a.now()
+	row := &articleRow{Article: entity.Article{
+		Id:          uuid.New(),
+		AuthorID:    article.AuthorID,
+		Title:       article.Title,
+		Description: article.Description,
+		Content:     article.Content,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}}
+
+	err := a.insertEventLocked(entity.EventArticleCreated, row.Id, entity.ArticleCreatedPayload{
+		ArticleID:   row.Id,
+		AuthorID:    row.AuthorID,
+		Title:       row.Title,
+		Description: row.Description,
+	})
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("ArticleRepo.CreateArticle - a.insertEventLocked: %w", err)
+	}
+
+	a.articles[row.Id] = row
+	author.ArticlesCount++
+
+	return row.Id, nil
+}
+
+func (a ArticleRepo) GetArticleByID(ctx context.Context, id uuid.UUID) (entity.Article, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	row, ok := a.activeArticleLocked(id)
+	if !ok {
+		return entity.Article{}, repoerrs.ErrArticleNotFound
+	}
+
+	return row.Article, nil
+}
+
+func (a ArticleRepo) GetArticlesByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Article, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.visibleArticlesLocked(func(row *articleRow) bool { return row.AuthorID == authorID }), nil
+}
+
+func (a ArticleRepo) GetNewestArticles(ctx context.Context, limit, offset int) ([]entity.Article, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	articles := a.visibleArticlesLocked(func(*articleRow) bool { return true })
+	sortByCreatedAt(articles, func(article entity.Article) time.Time { return article.CreatedAt }, true)
+
+	from, to := page(len(articles), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return articles[from:to], nil
+}
+
+// SetArticleFavorite - пара пользователя и статьи уникальна, как в postgres
+func (a ArticleRepo) SetArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	user, ok := a.users[userID]
+	if !ok {
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - %w", errForeignKey("user", userID))
+	}
+	article, ok := a.articles[articleID]
+	if !ok {
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - %w", errForeignKey("article", articleID))
+	}
+
+	for _, f := range a.articleFavorites {
+		if f.userID == userID && f.articleID == articleID {
+			return false, nil
+		}
+	}
+
+	err := a.insertEventLocked(entity.EventArticleFavorited, articleID, entity.ArticleFavoritedPayload{
+		ArticleID: articleID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - a.insertEventLocked: %w", err)
+	}
+
+	a.articleFavorites = append(a.articleFavorites, articleFavorite{userID: userID, articleID: articleID})
+	article.FavoritesCount++
+	user.FavoritesArticlesCount++
+
+	return true, nil
+}
+
+func (a ArticleRepo) RemoveArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	favorites := a.articleFavorites[:0]
+	for _, f := range a.articleFavorites {
+		if f.userID != userID || f.articleID != articleID {
+			favorites = append(favorites, f)
+		}
+	}
+
+	// nothing was removed, nothing has happened
+	if len(favorites) == len(a.articleFavorites) {
+		return nil
+	}
+	a.articleFavorites = favorites
+
+	if article, ok := a.articles[articleID]; ok {
+		article.FavoritesCount--
+	}
+	if user, ok := a.users[userID]; ok {
+		user.FavoritesArticlesCount--
+	}
+
+	err := a.insertEventLocked(entity.EventArticleUnfavorited, articleID, entity.ArticleFavoritedPayload{
+		ArticleID: articleID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return fmt.Errorf("ArticleRepo.RemoveArticleFavorite - a.insertEventLocked: %w", err)
+	}
+
+	return nil
+}
+
+func (a ArticleRepo) GetFavoriteArticles(ctx context.Context, userID uuid.UUID) ([]entity.Article, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var articles []entity.Article
+	for _, f := range a.articleFavorites {
+		if f.userID != userID {
+			continue
+		}
+		if row, ok := a.activeArticleLocked(f.articleID); ok && row.HiddenAt == nil {
+			articles = append(articles, row.Article)
+		}
+	}
+
+	return articles, nil
+}
+
+func (a ArticleRepo) GetFavoritedArticleIDs(ctx context.Context, userID uuid.UUID, articleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	wanted := make(map[uuid.UUID]bool, len(articleIDs))
+	for _, id := range articleIDs {
+		wanted[id] = true
+	}
+
+	var ids []uuid.UUID
+	for _, f := range a.articleFavorites {
+		if f.userID == userID && wanted[f.articleID] {
+			ids = append(ids, f.articleID)
+		}
+	}
+
+	return ids, nil
+}
+
+func (a ArticleRepo) GetFeedArticles(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Article, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	followings := make(map[uuid.UUID]bool)
+	for _, f := range a.followers {
+		if f.followerID == userID {
+			followings[f.followingID] = true
+		}
+	}
+
+	articles := a.visibleArticlesLocked(func(row *articleRow) bool { return followings[row.AuthorID] })
+	sortByCreatedAt(articles, func(article entity.Article) time.Time { return article.CreatedAt }, true)
+
+	from, to := page(len(articles), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return articles[from:to], nil
+}
+
+func (a ArticleRepo) SearchArticles(ctx context.Context, query string, limit, offset int) ([]entity.Article, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	articles := a.visibleArticlesLocked(func(row *articleRow) bool {
+		return strings.Contains(strings.ToLower(row.Title), query) || strings.Contains(strings.ToLower(row.Description), query)
+	})
+	sortByCreatedAt(articles, func(article entity.Article) time.Time { return article.CreatedAt }, true)
+
+	from, to := page(len(articles), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return articles[from:to], nil
+}
+
+func (a ArticleRepo) GetArticlesTags(ctx context.Context, articleIDs []uuid.UUID) (map[uuid.UUID][]entity.Tag, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	wanted := make(map[uuid.UUID]bool, len(articleIDs))
+	for _, id := range articleIDs {
+		wanted[id] = true
+	}
+
+	tags := make(map[uuid.UUID][]entity.Tag)
+	for _, t := range a.articleTags {
+		if tag, ok := a.tags[t.tagID]; ok && wanted[t.articleID] {
+			tags[t.articleID] = append(tags[t.articleID], tag)
+		}
+	}
+	for _, articleTags := range tags {
+		sort.Slice(articleTags, func(i, j int) bool { return articleTags[i].Description < articleTags[j].Description })
+	}
+
+	return tags, nil
+}
+
+func (a ArticleRepo) UpdateArticleByID(ctx context.Context, articleID, editorID uuid.UUID, title, description, content *string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	row, ok := a.activeArticleLocked(articleID)
+	if !ok {
+		return repoerrs.ErrArticleNotFound
+	}
+
+	updated := row.Article
+	if title != nil {
+		updated.Title = *title
+	}
+
+	if description != nil {
+		updated.Description = *description
+	}
+
+	if content != nil {
+		updated.Content = *content
+	}
+
+	updated.UpdatedAt = a.now()
+
+	err := a.insertEventLocked(entity.EventArticleUpdated, articleID, entity.ArticleUpdatedPayload{
+		ArticleID:   articleID,
+		AuthorID:    updated.AuthorID,
+		EditorID:    editorID,
+		Title:       updated.Title,
+		Description: updated.Description,
+	})
+	if err != nil {
+		return fmt.Errorf("ArticleRepo.UpdateArticleByID - a.insertEventLocked: %w", err)
+	}
+
+	row.Article = updated
+
+	return nil
+}
+
+// DeleteArticleByID - мягкое удаление, статья удаляется из базы задачей очистки после срока хранения
+func (a ArticleRepo) DeleteArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	row, ok := a.activeArticleLocked(articleID)
+	if !ok {
+		return repoerrs.ErrArticleNotFound
+	}
+
+	row.deletedAt = ptr(a.now())
+
+	err := a.articleEventLocked(entity.EventArticleDeleted, row)
+	if err != nil {
+		return fmt.Errorf("ArticleRepo.DeleteArticleByID - a.articleEventLocked: %w", err)
+	}
+
+	return nil
+}
+
+func (a ArticleRepo) RestoreArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	row, ok := a.articles[articleID]
+	if !ok || row.deletedAt == nil {
+		return repoerrs.ErrArticleNotFound
+	}
+
+	row.deletedAt = nil
+
+	err := a.articleEventLocked(entity.EventArticleRestored, row)
+	if err != nil {
+		return fmt.Errorf("ArticleRepo.RestoreArticleByID - a.articleEventLocked: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) activeArticleLocked(id uuid.UUID) (*articleRow, bool) {
+	row, ok := db.articles[id]
+	if !ok || row.deletedAt != nil {
+		return nil, false
+	}
+	return row, true
+}
+
+// visibleArticlesLocked - не скрытые и не удаленные статьи в порядке создания
+func (db *DB) visibleArticlesLocked(match func(row *articleRow) bool) []entity.Article {
+	var articles []entity.Article
+	for _, row := range db.articles {
+		if row.deletedAt == nil && row.HiddenAt == nil && match(row) {
+			articles = append(articles, row.Article)
+		}
+	}
+
+	sortByCreatedAt(articles, func(article entity.Article) time.Time { return article.CreatedAt }, false)
+
+	return articles
+}
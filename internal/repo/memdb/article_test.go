@@ -0,0 +1,57 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"github.com/google/uuid"
+	"testing"
+)
+
+func TestArticleRepo_GetArticlesTags(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	r := NewArticleRepo(db)
+
+	authorID, err := NewUserRepo(db).CreateUser(ctx, entity.User{Username: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstID, err := r.CreateArticle(ctx, entity.Article{AuthorID: authorID, Title: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondID, err := r.CreateArticle(ctx, entity.Article{AuthorID: authorID, Title: "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tags aren't managed through the repositories
+	wonderland := entity.Tag{Id: uuid.New(), Description: "wonderland"}
+	classics := entity.Tag{Id: uuid.New(), Description: "classics"}
+	db.tags[wonderland.Id] = wonderland
+	db.tags[classics.Id] = classics
+	db.articleTags = []articleTag{
+		{articleID: firstID, tagID: wonderland.Id},
+		{articleID: firstID, tagID: classics.Id},
+	}
+
+	tags, err := r.GetArticlesTags(ctx, []uuid.UUID{firstID, secondID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := tags[firstID]
+	if len(first) != 2 || first[0] != classics || first[1] != wonderland {
+		t.Errorf("tags of the first article = %+v", first)
+	}
+	if _, ok := tags[secondID]; ok {
+		t.Errorf("tags of the second article = %+v, want none", tags[secondID])
+	}
+
+	// tags leave together with the purged article
+	db.mu.Lock()
+	db.deleteArticleLocked(firstID)
+	db.mu.Unlock()
+	if len(db.articleTags) != 0 {
+		t.Errorf("tags of the purged article are kept: %+v", db.articleTags)
+	}
+}
@@ -0,0 +1,105 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type AuditRepo struct {
+	*DB
+}
+
+func NewAuditRepo(db *DB) *AuditRepo {
+	return &AuditRepo{db}
+}
+
+func (r *AuditRepo) CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insertAuditEntryLocked(entry)
+
+	return nil
+}
+
+func (r *AuditRepo) GetAuditLog(ctx context.Context, filter entity.AuditFilter, limit, offset int) ([]entity.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := r.auditLogLocked(filter)
+
+	from, to := page(len(entries), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return entries[from:to], nil
+}
+
+// ExportAuditLog - записи копируются под мьютексом, fn вызывается уже без него
+func (r *AuditRepo) ExportAuditLog(ctx context.Context, filter entity.AuditFilter, fn func(entry entity.AuditEntry) error) error {
+	r.mu.RLock()
+	entries := r.auditLogLocked(filter)
+	r.mu.RUnlock()
+
+	for _, entry := range entries {
+		err := fn(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *AuditRepo) auditLogLocked(filter entity.AuditFilter) []entity.AuditEntry {
+	var entries []entity.AuditEntry
+	for _, entry := range r.auditLog {
+		if filter.ActorID.Valid && entry.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.TargetID.Valid && entry.TargetID != filter.TargetID {
+			continue
+		}
+		if filter.TargetType != nil && entry.TargetType != *filter.TargetType {
+			continue
+		}
+		if filter.Action != nil && entry.Action != *filter.Action {
+			continue
+		}
+		if filter.RequestID != "" && entry.RequestID != filter.RequestID {
+			continue
+		}
+		if filter.IP != "" && entry.IP != filter.IP {
+			continue
+		}
+		if filter.From != nil && entry.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !entry.CreatedAt.Before(*filter.To) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sortByCreatedAt(entries, func(entry entity.AuditEntry) time.Time { return entry.CreatedAt }, true)
+
+	return entries
+}
+
+// insertAuditEntryLocked - журнал только дополняется, записи не меняются и не удаляются
+func (db *DB) insertAuditEntryLocked(entry entity.AuditEntry) {
+	details := json.RawMessage("{}")
+	if entry.Details != nil {
+		details = append(json.RawMessage(nil), entry.Details...)
+	}
+
+	entry.ID = uuid.New()
+	entry.Details = details
+	entry.CreatedAt = db.now()
+
+	db.auditLog = append(db.auditLog, entry)
+}
@@ -0,0 +1,171 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+type CommentRepo struct {
+	*DB
+}
+
+func NewCommentRepo(db *DB) *CommentRepo {
+	return &CommentRepo{db}
+}
+
+func (r *CommentRepo) CreateComment(ctx context.Context, comment entity.Comment) (entity.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	author, ok := r.users[comment.AuthorID]
+	if !ok {
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - %w", errForeignKey("user", comment.AuthorID))
+	}
+	article, ok := r.articles[comment.ArticleID]
+	if !ok {
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - %w", errForeignKey("article", comment.ArticleID))
+	}
+	if _, ok := r.comments[comment.ParentID.UUID]; comment.ParentID.Valid && !ok {
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - %w", errForeignKey("comment", comment.ParentID.UUID))
+	}
+
+	comment.Id = uuid.New()
+	comment.CreatedAt = r.now()
+	comment.UpdatedAt = comment.CreatedAt
+
+	err := r.insertEventLocked(entity.EventCommentPosted, comment.Id, entity.CommentPostedPayload{
+		CommentID: comment.Id,
+		ArticleID: comment.ArticleID,
+		ParentID:  comment.ParentID,
+		AuthorID:  comment.AuthorID,
+	})
+	if err != nil {
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - r.insertEventLocked: %w", err)
+	}
+
+	// counters and votes of a new comment start from zero whatever is passed
+	r.comments[comment.Id] = &commentRow{Comment: entity.Comment{
+		Id:        comment.Id,
+		AuthorID:  comment.AuthorID,
+		ArticleID: comment.ArticleID,
+		ParentID:  comment.ParentID,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}}
+	article.CommentsCount++
+	author.CommentsCount++
+
+	return comment, nil
+}
+
+func (r *CommentRepo) GetCommentByID(ctx context.Context, id uuid.UUID) (entity.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.comments[id]
+	if !ok || row.deletedAt != nil {
+		return entity.Comment{}, repoerrs.ErrCommentNotFound
+	}
+
+	return row.Comment, nil
+}
+
+func (r *CommentRepo) GetCommentsByArticleID(ctx context.Context, articleID uuid.UUID, limit, offset int) ([]entity.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := r.commentsLocked(func(row *commentRow) bool { return row.ArticleID == articleID && row.HiddenAt == nil })
+
+	from, to := page(len(comments), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return comments[from:to], nil
+}
+
+func (r *CommentRepo) UpdateCommentByID(ctx context.Context, commentID uuid.UUID, content string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.comments[commentID]
+	if !ok || row.deletedAt != nil {
+		return repoerrs.ErrCommentNotFound
+	}
+
+	row.Content = content
+	row.UpdatedAt = r.now()
+
+	return nil
+}
+
+// DeleteCommentByID - мягкое удаление, ответы на комментарий остаются видимыми
+func (r *CommentRepo) DeleteCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.comments[commentID]
+	if !ok || row.deletedAt != nil {
+		return repoerrs.ErrCommentNotFound
+	}
+
+	row.deletedAt = ptr(r.now())
+
+	err := r.commentEventLocked(entity.EventCommentDeleted, row)
+	if err != nil {
+		return fmt.Errorf("CommentRepo.DeleteCommentByID - r.commentEventLocked: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CommentRepo) RestoreCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.comments[commentID]
+	if !ok || row.deletedAt == nil {
+		return repoerrs.ErrCommentNotFound
+	}
+
+	row.deletedAt = nil
+
+	err := r.commentEventLocked(entity.EventCommentRestored, row)
+	if err != nil {
+		return fmt.Errorf("CommentRepo.RestoreCommentByID - r.commentEventLocked: %w", err)
+	}
+
+	return nil
+}
+
+// GetCommentsByAuthorID - все комментарии пользователя, включая скрытые модератором
+func (r *CommentRepo) GetCommentsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.commentsLocked(func(row *commentRow) bool { return row.AuthorID == authorID }), nil
+}
+
+// GetFavoriteComments - избранные комментарии пока не добавляются ни одним репозиторием
+func (r *CommentRepo) GetFavoriteComments(ctx context.Context, userID uuid.UUID) ([]entity.Comment, error) {
+	return nil, nil
+}
+
+// commentsLocked - неудаленные комментарии в порядке создания
+func (r *CommentRepo) commentsLocked(match func(row *commentRow) bool) []entity.Comment {
+	var comments []entity.Comment
+	for _, row := range r.comments {
+		if row.deletedAt == nil && match(row) {
+			comments = append(comments, row.Comment)
+		}
+	}
+
+	sortByCreatedAt(comments, func(comment entity.Comment) time.Time { return comment.CreatedAt }, false)
+
+	return comments
+}
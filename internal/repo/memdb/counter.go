@@ -0,0 +1,84 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"github.com/google/uuid"
+)
+
+type CounterRepo struct {
+	*DB
+}
+
+func NewCounterRepo(db *DB) *CounterRepo {
+	return &CounterRepo{db}
+}
+
+// ReconcileCounters - пересчет счетчиков, как в pgdb. Избранных комментариев и голосов в памяти нет,
+// поэтому их счетчики сверяются с нулем
+func (r *CounterRepo) ReconcileCounters(ctx context.Context, fix bool) ([]entity.CounterDrift, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	articlesByAuthor := make(map[uuid.UUID]int)
+	for _, article := range r.articles {
+		articlesByAuthor[article.AuthorID]++
+	}
+
+	commentsByAuthor := make(map[uuid.UUID]int)
+	commentsByArticle := make(map[uuid.UUID]int)
+	for _, comment := range r.comments {
+		commentsByAuthor[comment.AuthorID]++
+		commentsByArticle[comment.ArticleID]++
+	}
+
+	favoritesByUser := make(map[uuid.UUID]int)
+	favoritesByArticle := make(map[uuid.UUID]int)
+	for _, f := range r.articleFavorites {
+		favoritesByUser[f.userID]++
+		favoritesByArticle[f.articleID]++
+	}
+
+	followers := make(map[uuid.UUID]int)
+	followings := make(map[uuid.UUID]int)
+	for _, f := range r.followers {
+		followers[f.followingID]++
+		followings[f.followerID]++
+	}
+
+	var drift []entity.CounterDrift
+	check := func(table, column string, id uuid.UUID, stored *int, actual int) {
+		if *stored == actual {
+			return
+		}
+		drift = append(drift, entity.CounterDrift{Table: table, Column: column, ID: id, Stored: *stored, Actual: actual})
+		if fix {
+			*stored = actual
+		}
+	}
+
+	for id, user := range r.users {
+		check("users", "articles_count", id, &user.ArticlesCount, articlesByAuthor[id])
+		check("users", "comments_count", id, &user.CommentsCount, commentsByAuthor[id])
+		check("users", "favorites_articles_count", id, &user.FavoritesArticlesCount, favoritesByUser[id])
+		check("users", "favorites_comments_count", id, &user.FavoritesCommentsCount, 0)
+		check("users", "followers_count", id, &user.FollowersCount, followers[id])
+		check("users", "followings_count", id, &user.FollowingCount, followings[id])
+	}
+
+	for id, article := range r.articles {
+		check("articles", "comments_count", id, &article.CommentsCount, commentsByArticle[id])
+		check("articles", "favorites_count", id, &article.FavoritesCount, favoritesByArticle[id])
+		check("articles", "votes_up_count", id, &article.VotesUpCount, 0)
+		check("articles", "votes_down_count", id, &article.VotesDownCount, 0)
+	}
+
+	for id, comment := range r.comments {
+		check("comments", "votes_up_count", id, &comment.VotesUpCount, 0)
+		check("comments", "votes_down_count", id, &comment.VotesDownCount, 0)
+	}
+
+	entity.SortCounterDrift(drift)
+
+	return drift, nil
+}
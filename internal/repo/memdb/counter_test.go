@@ -0,0 +1,70 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"testing"
+)
+
+func TestCounterRepo_ReconcileCounters(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+
+	authorID, err := NewUserRepo(db).CreateUser(ctx, entity.User{Username: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	articleID, err := NewArticleRepo(db).CreateArticle(ctx, entity.Article{AuthorID: authorID, Title: "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// drift that bypassed the repositories
+	db.users[authorID].ArticlesCount = 5
+	db.articles[articleID].FavoritesCount = -1
+
+	r := NewCounterRepo(db)
+	want := []entity.CounterDrift{
+		{Table: "articles", Column: "favorites_count", ID: articleID, Stored: -1, Actual: 0},
+		{Table: "users", Column: "articles_count", ID: authorID, Stored: 5, Actual: 1},
+	}
+
+	drift, err := r.ReconcileCounters(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalDrift(drift, want) {
+		t.Errorf("drift = %+v, want %+v", drift, want)
+	}
+	if got := db.users[authorID].ArticlesCount; got != 5 {
+		t.Errorf("articles_count changed without fix: %d", got)
+	}
+
+	drift, err = r.ReconcileCounters(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalDrift(drift, want) {
+		t.Errorf("fixed drift = %+v, want %+v", drift, want)
+	}
+
+	drift, err = r.ReconcileCounters(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("drift after fix = %+v, want none", drift)
+	}
+}
+
+func equalDrift(got, want []entity.CounterDrift) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DB - все таблицы в памяти процесса, для локальной разработки и быстрых тестов.
+// Один мьютекс на все таблицы: каждый метод репозитория атомарен, как транзакция в pgdb.
+// Строки хранятся со служебными колонками, которых нет в сущностях, например deleted_at
+type DB struct {
+	mu sync.RWMutex
+
+	tables
+}
+
+// tables - таблицы отдельно от мьютекса, чтобы TxManager мог сделать их снимок
+type tables struct {
+	users            map[uuid.UUID]*userRow
+	articles         map[uuid.UUID]*articleRow
+	comments         map[uuid.UUID]*commentRow
+	followers        []follower
+	articleFavorites []articleFavorite
+	tags             map[uuid.UUID]entity.Tag
+	articleTags      []articleTag
+	notifications    []entity.Notification
+	outbox           []*eventRow
+	webhooks         map[uuid.UUID]*entity.Webhook
+	deliveries       map[uuid.UUID]*deliveryRow
+	moderationCases  map[uuid.UUID]*entity.ModerationCase
+	reports          []entity.Report
+	moderationLog    []entity.ModerationLogEntry
+	exports          map[uuid.UUID]*exportRow
+	auditLog         []entity.AuditEntry
+
+	lastEventID int64
+}
+
+type userRow struct {
+	entity.User
+	deletedAt *time.Time
+}
+
+type articleRow struct {
+	entity.Article
+	deletedAt *time.Time
+}
+
+type commentRow struct {
+	entity.Comment
+	deletedAt *time.Time
+}
+
+// follower, articleFavorite - как и в postgres, пары подписок и избранного уникальны
+type follower struct {
+	followerID  uuid.UUID
+	followingID uuid.UUID
+}
+
+type articleFavorite struct {
+	userID    uuid.UUID
+	articleID uuid.UUID
+}
+
+// articleTag - теги статей через API не меняются, в postgres их заполняют вне приложения
+type articleTag struct {
+	articleID uuid.UUID
+	tagID     uuid.UUID
+}
+
+type eventRow struct {
+	entity.Event
+	status        entity.EventStatus
+	nextAttemptAt time.Time
+	lockedUntil   *time.Time
+	publishedAt   *time.Time
+	lastError     *string
+}
+
+type deliveryRow struct {
+	entity.WebhookDelivery
+	lockedUntil *time.Time
+}
+
+type exportRow struct {
+	entity.UserExport
+	archive     []byte
+	lockedUntil *time.Time
+}
+
+func New() *DB {
+	db := &DB{tables: tables{
+		users:           make(map[uuid.UUID]*userRow),
+		articles:        make(map[uuid.UUID]*articleRow),
+		comments:        make(map[uuid.UUID]*commentRow),
+		tags:            make(map[uuid.UUID]entity.Tag),
+		webhooks:        make(map[uuid.UUID]*entity.Webhook),
+		deliveries:      make(map[uuid.UUID]*deliveryRow),
+		moderationCases: make(map[uuid.UUID]*entity.ModerationCase),
+		exports:         make(map[uuid.UUID]*exportRow),
+	}}
+
+	// the tombstone author is created by a migration in postgres
+	now := db.now()
+	db.users[entity.TombstoneUserID] = &userRow{User: entity.User{
+		ID:          entity.TombstoneUserID,
+		Name:        "Deleted user",
+		Username:    "deleted",
+		Email:       "deleted@invalid",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Role:        entity.RoleUser,
+		Description: "content of deleted accounts",
+		BannedAt:    &now,
+	}}
+
+	return db
+}
+
+func (db *DB) now() time.Time {
+	return time.Now().UTC()
+}
+
+// insertEventLocked - запись события в outbox вместе с изменением, как insertEvent в pgdb
+func (db *DB) insertEventLocked(eventType entity.EventType, aggregateID uuid.UUID, payload any) error {
+	event, err := entity.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return fmt.Errorf("entity.NewEvent: %w", err)
+	}
+
+	db.lastEventID++
+	event.ID = db.lastEventID
+	event.CreatedAt = db.now()
+
+	db.outbox = append(db.outbox, &eventRow{
+		Event:         event,
+		status:        entity.EventStatusPending,
+		nextAttemptAt: event.CreatedAt,
+	})
+
+	return nil
+}
+
+// articleEventLocked - событие об удалении, восстановлении или скрытии статьи, как updateArticles в pgdb
+func (db *DB) articleEventLocked(eventType entity.EventType, row *articleRow) error {
+	return db.insertEventLocked(eventType, row.Id, entity.ArticleDeletedPayload{
+		ArticleID: row.Id,
+		AuthorID:  row.AuthorID,
+	})
+}
+
+// commentEventLocked - то же, что articleEventLocked, для комментариев
+func (db *DB) commentEventLocked(eventType entity.EventType, row *commentRow) error {
+	return db.insertEventLocked(eventType, row.Id, entity.CommentDeletedPayload{
+		CommentID: row.Id,
+		ArticleID: row.ArticleID,
+		AuthorID:  row.AuthorID,
+	})
+}
+
+// errForeignKey - ссылка на несуществующую строку, в postgres это нарушение внешнего ключа
+func errForeignKey(table string, id uuid.UUID) error {
+	return fmt.Errorf("%s %s does not exist", table, id)
+}
+
+// page - границы страницы limit/offset в списке из n строк
+func page(n, limit, offset int) (int, int) {
+	if offset > n {
+		offset = n
+	}
+	end := offset + limit
+	if limit < 0 || end > n {
+		end = n
+	}
+	return offset, end
+}
+
+func sortByCreatedAt[T any](rows []T, createdAt func(T) time.Time, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return createdAt(rows[i]).After(createdAt(rows[j]))
+		}
+		return createdAt(rows[i]).Before(createdAt(rows[j]))
+	})
+}
+
+// lockable - строка очереди свободна, если ее никто не захватил или lease истек
+func lockable(lockedUntil *time.Time, now time.Time) bool {
+	return lockedUntil == nil || lockedUntil.Before(now)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
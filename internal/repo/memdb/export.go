@@ -0,0 +1,172 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+type ExportRepo struct {
+	*DB
+}
+
+func NewExportRepo(db *DB) *ExportRepo {
+	return &ExportRepo{db}
+}
+
+func (r *ExportRepo) CreateExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[userID]; !ok {
+		return entity.UserExport{}, fmt.Errorf("ExportRepo.CreateExport - %w", errForeignKey("user", userID))
+	}
+
+	row := &exportRow{UserExport: entity.UserExport{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    entity.UserExportPending,
+		CreatedAt: r.now(),
+	}}
+	r.exports[row.ID] = row
+
+	return row.UserExport, nil
+}
+
+func (r *ExportRepo) GetExportByID(ctx context.Context, id uuid.UUID) (entity.UserExport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.exports[id]
+	if !ok {
+		return entity.UserExport{}, repoerrs.ErrExportNotFound
+	}
+
+	return row.UserExport, nil
+}
+
+// GetPendingExport - несобранный архив пользователя, новый не создается, пока этот не готов
+func (r *ExportRepo) GetPendingExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pending *exportRow
+	for _, row := range r.exports {
+		if row.UserID != userID || row.Status != entity.UserExportPending {
+			continue
+		}
+		if pending == nil || row.CreatedAt.After(pending.CreatedAt) {
+			pending = row
+		}
+	}
+
+	if pending == nil {
+		return entity.UserExport{}, repoerrs.ErrExportNotFound
+	}
+
+	return pending.UserExport, nil
+}
+
+// GetExportArchive - архив отдается, только пока он не истек
+func (r *ExportRepo) GetExportArchive(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.exports[id]
+	if !ok || row.Status != entity.UserExportReady || !row.ExpiresAt.After(r.now()) {
+		return nil, repoerrs.ErrExportNotFound
+	}
+
+	return append([]byte(nil), row.archive...), nil
+}
+
+// LockPendingExports - захват пачки архивов на время lease, аналогично WebhookRepo.LockPendingDeliveries
+func (r *ExportRepo) LockPendingExports(ctx context.Context, limit int, lease time.Duration) ([]entity.UserExport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	var rows []*exportRow
+	for _, row := range r.exports {
+		if row.Status == entity.UserExportPending && lockable(row.lockedUntil, now) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].CreatedAt.Before(rows[j].CreatedAt) })
+
+	from, to := page(len(rows), limit, 0)
+
+	var exports []entity.UserExport
+	for _, row := range rows[from:to] {
+		row.lockedUntil = ptr(now.Add(lease))
+		row.Attempts++
+		exports = append(exports, row.UserExport)
+	}
+
+	return exports, nil
+}
+
+func (r *ExportRepo) MarkExportReady(ctx context.Context, id uuid.UUID, archive []byte, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.exports[id]; ok {
+		now := r.now()
+		row.Status = entity.UserExportReady
+		row.archive = append([]byte(nil), archive...)
+		row.Size = ptr(int64(len(archive)))
+		row.lockedUntil = nil
+		row.LastError = nil
+		row.CompletedAt = &now
+		row.ExpiresAt = ptr(now.Add(ttl))
+	}
+
+	return nil
+}
+
+// MarkExportFailed - архив остается pending для повтора после lease, пока не закончатся попытки
+func (r *ExportRepo) MarkExportFailed(ctx context.Context, id uuid.UUID, lastError string, final bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.exports[id]; ok {
+		row.LastError = &lastError
+		if final {
+			row.Status = entity.UserExportFailed
+			row.lockedUntil = nil
+			row.CompletedAt = ptr(r.now())
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpiredExports - удаление истекших и неудавшихся архивов
+func (r *ExportRepo) DeleteExpiredExports(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	var deleted int
+	for id, row := range r.exports {
+		expired := row.ExpiresAt != nil && row.ExpiresAt.Before(now)
+		failed := row.Status == entity.UserExportFailed && row.CompletedAt != nil && row.CompletedAt.Before(now.AddDate(0, 0, -1))
+		if expired || failed {
+			delete(r.exports, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// GetUserVotes - голоса пока не добавляются ни одним репозиторием
+func (r *ExportRepo) GetUserVotes(ctx context.Context, userID uuid.UUID) ([]entity.Vote, error) {
+	return nil, nil
+}
@@ -0,0 +1,15 @@
+package memdb_test
+
+import (
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/memdb"
+	"blog-backend/internal/testutil/repotest"
+	"testing"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) (*repo.Repositories, repotest.TxManager) {
+		db := memdb.New()
+		return repo.NewMemoryRepositories(db), memdb.NewTxManager(db)
+	})
+}
@@ -0,0 +1,275 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+type ModerationRepo struct {
+	*DB
+}
+
+func NewModerationRepo(db *DB) *ModerationRepo {
+	return &ModerationRepo{db}
+}
+
+// CreateReport - жалоба добавляется в открытый кейс цели или создает новый,
+// повторная жалоба того же пользователя в тот же кейс возвращает ErrReportAlreadyExists
+func (r *ModerationRepo) CreateReport(ctx context.Context, moderationCase entity.ModerationCase, report entity.Report) (entity.Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[report.ReporterID]; !ok {
+		return entity.Report{}, fmt.Errorf("ModerationRepo.CreateReport - %w", errForeignKey("user", report.ReporterID))
+	}
+
+	var openCase *entity.ModerationCase
+	for _, row := range r.moderationCases {
+		if row.TargetType == moderationCase.TargetType && row.TargetID == moderationCase.TargetID && row.Status != entity.ModerationStatusResolved {
+			openCase = row
+			break
+		}
+	}
+
+	now := r.now()
+	if openCase != nil {
+		for _, existing := range r.reports {
+			if existing.CaseID == openCase.ID && existing.ReporterID == report.ReporterID {
+				return entity.Report{}, repoerrs.ErrReportAlreadyExists
+			}
+		}
+
+		openCase.ReportsCount++
+		openCase.UpdatedAt = now
+	} else {
+		if _, ok := r.users[moderationCase.TargetAuthorID]; !ok {
+			return entity.Report{}, fmt.Errorf("ModerationRepo.CreateReport - %w", errForeignKey("user", moderationCase.TargetAuthorID))
+		}
+
+		openCase = &entity.ModerationCase{
+			ID:             uuid.New(),
+			TargetType:     moderationCase.TargetType,
+			TargetID:       moderationCase.TargetID,
+			TargetAuthorID: moderationCase.TargetAuthorID,
+			Status:         entity.ModerationStatusOpen,
+			ReportsCount:   1,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		r.moderationCases[openCase.ID] = openCase
+	}
+
+	report.ID = uuid.New()
+	report.CaseID = openCase.ID
+	report.CreatedAt = now
+	r.reports = append(r.reports, report)
+
+	return report, nil
+}
+
+func (r *ModerationRepo) GetModerationCaseByID(ctx context.Context, id uuid.UUID) (entity.ModerationCase, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.moderationCases[id]
+	if !ok {
+		return entity.ModerationCase{}, repoerrs.ErrModerationCaseNotFound
+	}
+
+	return *row, nil
+}
+
+// GetModerationCases - очередь модерации, первыми идут цели с наибольшим количеством жалоб
+func (r *ModerationRepo) GetModerationCases(ctx context.Context, status *entity.ModerationStatus, targetType *entity.ReportTargetType, limit, offset int) ([]entity.ModerationCase, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var moderationCases []entity.ModerationCase
+	for _, row := range r.moderationCases {
+		if status != nil && row.Status != *status {
+			continue
+		}
+		if targetType != nil && row.TargetType != *targetType {
+			continue
+		}
+		moderationCases = append(moderationCases, *row)
+	}
+
+	sort.SliceStable(moderationCases, func(i, j int) bool {
+		if moderationCases[i].ReportsCount != moderationCases[j].ReportsCount {
+			return moderationCases[i].ReportsCount > moderationCases[j].ReportsCount
+		}
+		return moderationCases[i].CreatedAt.Before(moderationCases[j].CreatedAt)
+	})
+
+	from, to := page(len(moderationCases), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return moderationCases[from:to], nil
+}
+
+func (r *ModerationRepo) GetReportsByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.Report, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// reports are appended in the order of creation
+	var reports []entity.Report
+	for _, report := range r.reports {
+		if report.CaseID == caseID {
+			reports = append(reports, report)
+		}
+	}
+
+	return reports, nil
+}
+
+// ClaimModerationCase - взять открытый кейс в работу, ErrModerationCaseNotFound если кейс уже не открыт
+func (r *ModerationRepo) ClaimModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.moderationCases[moderationCase.ID]
+	if !ok || row.Status != entity.ModerationStatusOpen {
+		return repoerrs.ErrModerationCaseNotFound
+	}
+	if _, ok := r.users[moderatorID]; !ok {
+		return fmt.Errorf("ModerationRepo.ClaimModerationCase - %w", errForeignKey("user", moderatorID))
+	}
+
+	now := r.now()
+	row.Status = entity.ModerationStatusClaimed
+	row.AssigneeID = uuid.NullUUID{UUID: moderatorID, Valid: true}
+	row.ClaimedAt = &now
+	row.UpdatedAt = now
+
+	r.insertLogEntryLocked(moderationCase, moderatorID, entity.ModerationActionClaim, "")
+
+	return nil
+}
+
+// ResolveModerationCase - закрытие кейса и применение действия к цели за один вызов,
+// ErrModerationCaseNotFound если кейс уже закрыт
+func (r *ModerationRepo) ResolveModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.moderationCases[moderationCase.ID]
+	if !ok || row.Status == entity.ModerationStatusResolved {
+		return repoerrs.ErrModerationCaseNotFound
+	}
+	if _, ok := r.users[moderatorID]; !ok {
+		return fmt.Errorf("ModerationRepo.ResolveModerationCase - %w", errForeignKey("user", moderatorID))
+	}
+
+	now := r.now()
+	err := r.applyActionLocked(moderationCase, action, now)
+	if err != nil {
+		return fmt.Errorf("ModerationRepo.ResolveModerationCase - r.applyActionLocked: %w", err)
+	}
+
+	row.Status = entity.ModerationStatusResolved
+	row.Resolution = &action
+	row.ResolvedBy = uuid.NullUUID{UUID: moderatorID, Valid: true}
+	row.ResolvedAt = &now
+	row.UpdatedAt = now
+
+	r.insertLogEntryLocked(moderationCase, moderatorID, action, note)
+
+	return nil
+}
+
+func (r *ModerationRepo) GetModerationLog(ctx context.Context, moderatorID, caseID uuid.NullUUID, limit, offset int) ([]entity.ModerationLogEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []entity.ModerationLogEntry
+	for _, entry := range r.moderationLog {
+		if moderatorID.Valid && entry.ModeratorID != moderatorID.UUID {
+			continue
+		}
+		if caseID.Valid && entry.CaseID != caseID {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sortByCreatedAt(entries, func(entry entity.ModerationLogEntry) time.Time { return entry.CreatedAt }, true)
+
+	from, to := page(len(entries), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return entries[from:to], nil
+}
+
+// applyActionLocked - цель могла быть уже удалена другим кейсом, тогда действие ничего не меняет
+func (r *ModerationRepo) applyActionLocked(moderationCase entity.ModerationCase, action entity.ModerationAction, now time.Time) error {
+	if action == entity.ModerationActionBan {
+		if user, ok := r.users[moderationCase.TargetAuthorID]; ok && user.BannedAt == nil {
+			user.BannedAt = &now
+		}
+		return nil
+	}
+	if action != entity.ModerationActionHide && action != entity.ModerationActionDelete {
+		return nil
+	}
+
+	switch moderationCase.TargetType {
+	case entity.ReportTargetArticle:
+		article, ok := r.articles[moderationCase.TargetID]
+		// the target is already purged
+		if !ok {
+			return nil
+		}
+
+		column, eventType := &article.HiddenAt, entity.EventArticleHidden
+		if action == entity.ModerationActionDelete {
+			// soft delete, the row and the counters are cleaned up by the retention purge
+			column, eventType = &article.deletedAt, entity.EventArticleDeleted
+		}
+		if *column != nil {
+			return nil
+		}
+		*column = &now
+		return r.articleEventLocked(eventType, article)
+
+	case entity.ReportTargetComment:
+		comment, ok := r.comments[moderationCase.TargetID]
+		if !ok {
+			return nil
+		}
+
+		column, eventType := &comment.HiddenAt, entity.EventCommentHidden
+		if action == entity.ModerationActionDelete {
+			column, eventType = &comment.deletedAt, entity.EventCommentDeleted
+		}
+		if *column != nil {
+			return nil
+		}
+		*column = &now
+		return r.commentEventLocked(eventType, comment)
+	}
+
+	return fmt.Errorf("%s can't be applied to %s", action, moderationCase.TargetType)
+}
+
+func (r *ModerationRepo) insertLogEntryLocked(moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) {
+	r.moderationLog = append(r.moderationLog, entity.ModerationLogEntry{
+		ID:          uuid.New(),
+		CaseID:      uuid.NullUUID{UUID: moderationCase.ID, Valid: true},
+		ModeratorID: moderatorID,
+		Action:      action,
+		TargetType:  moderationCase.TargetType,
+		TargetID:    moderationCase.TargetID,
+		Note:        note,
+		CreatedAt:   r.now(),
+	})
+}
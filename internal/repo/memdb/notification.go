@@ -0,0 +1,70 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+type NotificationRepo struct {
+	*DB
+}
+
+func NewNotificationRepo(db *DB) *NotificationRepo {
+	return &NotificationRepo{db}
+}
+
+func (r *NotificationRepo) CreateNotification(ctx context.Context, notification entity.Notification) (entity.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range []uuid.UUID{notification.UserID, notification.ActorID} {
+		if _, ok := r.users[id]; !ok {
+			return entity.Notification{}, fmt.Errorf("NotificationRepo.CreateNotification - %w", errForeignKey("user", id))
+		}
+	}
+	if _, ok := r.articles[notification.ArticleID.UUID]; notification.ArticleID.Valid && !ok {
+		return entity.Notification{}, fmt.Errorf("NotificationRepo.CreateNotification - %w", errForeignKey("article", notification.ArticleID.UUID))
+	}
+	if _, ok := r.comments[notification.CommentID.UUID]; notification.CommentID.Valid && !ok {
+		return entity.Notification{}, fmt.Errorf("NotificationRepo.CreateNotification - %w", errForeignKey("comment", notification.CommentID.UUID))
+	}
+
+	// unique by user, type, actor, article and comment, missing article and comment are equal as in notifications_dedup_idx
+	for _, n := range r.notifications {
+		if n.UserID == notification.UserID && n.Type == notification.Type && n.ActorID == notification.ActorID &&
+			n.ArticleID == notification.ArticleID && n.CommentID == notification.CommentID {
+			return entity.Notification{}, repoerrs.ErrNotificationAlreadyExists
+		}
+	}
+
+	notification.ID = uuid.New()
+	notification.CreatedAt = r.now()
+	r.notifications = append(r.notifications, notification)
+
+	return notification, nil
+}
+
+func (r *NotificationRepo) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var notifications []entity.Notification
+	for _, n := range r.notifications {
+		if n.UserID == userID {
+			notifications = append(notifications, n)
+		}
+	}
+
+	sortByCreatedAt(notifications, func(n entity.Notification) time.Time { return n.CreatedAt }, true)
+
+	from, to := page(len(notifications), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return notifications[from:to], nil
+}
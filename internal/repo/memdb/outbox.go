@@ -0,0 +1,91 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"time"
+)
+
+type OutboxRepo struct {
+	*DB
+}
+
+func NewOutboxRepo(db *DB) *OutboxRepo {
+	return &OutboxRepo{db}
+}
+
+// LockPendingEvents - захват пачки событий для доставки на время lease,
+// незавершенные по истечении lease захватываются снова
+func (r *OutboxRepo) LockPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	lockedUntil := now.Add(lease)
+
+	// outbox is kept in the order of ids
+	var events []entity.Event
+	for _, row := range r.outbox {
+		if len(events) == limit {
+			break
+		}
+		if row.status != entity.EventStatusPending || row.nextAttemptAt.After(now) || !lockable(row.lockedUntil, now) {
+			continue
+		}
+
+		row.lockedUntil = &lockedUntil
+		row.Attempts++
+		events = append(events, row.Event)
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepo) MarkEventPublished(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row := r.eventLocked(id); row != nil {
+		row.status = entity.EventStatusPublished
+		row.publishedAt = ptr(r.now())
+		row.lockedUntil = nil
+		row.lastError = nil
+	}
+
+	return nil
+}
+
+func (r *OutboxRepo) MarkEventFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row := r.eventLocked(id); row != nil {
+		row.nextAttemptAt = r.now().Add(retryIn)
+		row.lockedUntil = nil
+		row.lastError = &lastError
+	}
+
+	return nil
+}
+
+func (r *OutboxRepo) MarkEventDead(ctx context.Context, id int64, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row := r.eventLocked(id); row != nil {
+		row.status = entity.EventStatusDead
+		row.lockedUntil = nil
+		row.lastError = &lastError
+	}
+
+	return nil
+}
+
+func (r *OutboxRepo) eventLocked(id int64) *eventRow {
+	for _, row := range r.outbox {
+		if row.ID == id {
+			return row
+		}
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"context"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+type RetentionRepo struct {
+	*DB
+}
+
+func NewRetentionRepo(db *DB) *RetentionRepo {
+	return &RetentionRepo{db}
+}
+
+// PurgeDeletedComments - удаление комментариев, удаленных раньше olderThan назад, вместе с удаленными ответами.
+// Комментарий с неудаленными ответами остается, пока ответы не будут удалены
+func (r *RetentionRepo) PurgeDeletedComments(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.now().Add(-olderThan)
+
+	var ids []uuid.UUID
+	var deletedAt []time.Time
+	for id, row := range r.comments {
+		if row.deletedAt == nil || !row.deletedAt.Before(cutoff) || r.hasActiveRepliesLocked(id) {
+			continue
+		}
+		ids = append(ids, id)
+		deletedAt = append(deletedAt, *row.deletedAt)
+	}
+
+	ids = oldestFirst(ids, deletedAt, limit)
+	for _, id := range ids {
+		// the comment could be purged with the subtree of a previous one
+		if _, ok := r.comments[id]; !ok {
+			continue
+		}
+		r.decreaseSubtreeCountersLocked(r.commentSubtreeLocked([]uuid.UUID{id}))
+		r.deleteCommentLocked(id)
+	}
+
+	return entity.Purged{Count: len(ids)}, nil
+}
+
+// PurgeDeletedArticles - удаление статей вместе со всеми комментариями и избранным
+func (r *RetentionRepo) PurgeDeletedArticles(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.now().Add(-olderThan)
+
+	var ids []uuid.UUID
+	var deletedAt []time.Time
+	for id, row := range r.articles {
+		if row.deletedAt != nil && row.deletedAt.Before(cutoff) {
+			ids = append(ids, id)
+			deletedAt = append(deletedAt, *row.deletedAt)
+		}
+	}
+
+	ids = oldestFirst(ids, deletedAt, limit)
+	for _, id := range ids {
+		if author, ok := r.users[r.articles[id].AuthorID]; ok {
+			author.ArticlesCount--
+		}
+		r.decreaseArticlesCountersLocked(map[uuid.UUID]bool{id: true})
+		r.deleteArticleLocked(id)
+	}
+
+	return entity.Purged{Count: len(ids), ArticleIDs: ids}, nil
+}
+
+// PurgeDeletedUsers - удаление пользователей со всем их контентом, подписками и избранным
+func (r *RetentionRepo) PurgeDeletedUsers(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.now().Add(-olderThan)
+
+	var ids []uuid.UUID
+	var deletedAt []time.Time
+	for id, row := range r.users {
+		if row.deletedAt != nil && row.deletedAt.Before(cutoff) {
+			ids = append(ids, id)
+			deletedAt = append(deletedAt, *row.deletedAt)
+		}
+	}
+
+	ids = oldestFirst(ids, deletedAt, limit)
+	purged := entity.Purged{Count: len(ids)}
+	for _, id := range ids {
+		purged.Usernames = append(purged.Usernames, r.users[id].Username)
+
+		articles := make(map[uuid.UUID]bool)
+		for articleID, article := range r.articles {
+			if article.AuthorID == id {
+				articles[articleID] = true
+				purged.ArticleIDs = append(purged.ArticleIDs, articleID)
+			}
+		}
+		r.decreaseArticlesCountersLocked(articles)
+
+		// comments of the user outside of his own articles with all replies
+		var roots []uuid.UUID
+		for commentID, comment := range r.comments {
+			if comment.AuthorID == id && !articles[comment.ArticleID] {
+				roots = append(roots, commentID)
+			}
+		}
+		r.decreaseSubtreeCountersLocked(r.commentSubtreeLocked(roots))
+
+		r.decreaseUserRelationsLocked(id)
+		r.deleteUserLocked(id)
+	}
+
+	return purged, nil
+}
+
+// AnonymizeScheduledUsers - удаление аккаунтов, у которых закончился срок отмены удаления.
+// Статьи и комментарии переходят к TombstoneUserID, подписки и избранное удаляются
+func (r *RetentionRepo) AnonymizeScheduledUsers(ctx context.Context, limit int) (entity.Purged, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	var ids []uuid.UUID
+	var scheduledAt []time.Time
+	for id, row := range r.users {
+		if row.DeletionScheduledAt != nil && !row.DeletionScheduledAt.After(now) {
+			ids = append(ids, id)
+			scheduledAt = append(scheduledAt, *row.DeletionScheduledAt)
+		}
+	}
+
+	tombstone := r.users[entity.TombstoneUserID]
+
+	ids = oldestFirst(ids, scheduledAt, limit)
+	purged := entity.Purged{Count: len(ids)}
+	for _, id := range ids {
+		purged.Usernames = append(purged.Usernames, r.users[id].Username)
+
+		for articleID, article := range r.articles {
+			if article.AuthorID == id {
+				article.AuthorID = entity.TombstoneUserID
+				tombstone.ArticlesCount++
+				purged.ArticleIDs = append(purged.ArticleIDs, articleID)
+			}
+		}
+		for _, comment := range r.comments {
+			if comment.AuthorID == id {
+				comment.AuthorID = entity.TombstoneUserID
+				tombstone.CommentsCount++
+			}
+		}
+		for _, moderationCase := range r.moderationCases {
+			if moderationCase.TargetAuthorID == id {
+				moderationCase.TargetAuthorID = entity.TombstoneUserID
+			}
+		}
+
+		r.decreaseUserRelationsLocked(id)
+		r.deleteUserLocked(id)
+	}
+
+	return purged, nil
+}
+
+func (r *RetentionRepo) hasActiveRepliesLocked(commentID uuid.UUID) bool {
+	for _, reply := range r.comments {
+		if reply.ParentID.Valid && reply.ParentID.UUID == commentID && reply.deletedAt == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// commentSubtreeLocked - комментарии roots со всеми ответами
+func (r *RetentionRepo) commentSubtreeLocked(roots []uuid.UUID) []*commentRow {
+	seen := make(map[uuid.UUID]bool)
+	var subtree []*commentRow
+
+	queue := roots
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		subtree = append(subtree, r.comments[id])
+
+		for replyID, reply := range r.comments {
+			if reply.ParentID.Valid && reply.ParentID.UUID == id {
+				queue = append(queue, replyID)
+			}
+		}
+	}
+
+	return subtree
+}
+
+func (r *RetentionRepo) decreaseSubtreeCountersLocked(subtree []*commentRow) {
+	for _, comment := range subtree {
+		if author, ok := r.users[comment.AuthorID]; ok {
+			author.CommentsCount--
+		}
+		if article, ok := r.articles[comment.ArticleID]; ok {
+			article.CommentsCount--
+		}
+	}
+}
+
+// decreaseArticlesCountersLocked - счетчики комментаторов и добавивших в избранное статьи, которые будут удалены
+func (r *RetentionRepo) decreaseArticlesCountersLocked(articles map[uuid.UUID]bool) {
+	for _, comment := range r.comments {
+		if !articles[comment.ArticleID] {
+			continue
+		}
+		if author, ok := r.users[comment.AuthorID]; ok {
+			author.CommentsCount--
+		}
+	}
+
+	for _, favorite := range r.articleFavorites {
+		if !articles[favorite.articleID] {
+			continue
+		}
+		if user, ok := r.users[favorite.userID]; ok {
+			user.FavoritesArticlesCount--
+		}
+	}
+}
+
+// decreaseUserRelationsLocked - счетчики второй стороны подписок и избранного пользователя, которые удаляются вместе с ним
+func (r *RetentionRepo) decreaseUserRelationsLocked(userID uuid.UUID) {
+	for _, f := range r.followers {
+		if f.followerID == userID {
+			if following, ok := r.users[f.followingID]; ok {
+				following.FollowersCount--
+			}
+		}
+		if f.followingID == userID {
+			if followerRow, ok := r.users[f.followerID]; ok {
+				followerRow.FollowingCount--
+			}
+		}
+	}
+
+	for _, favorite := range r.articleFavorites {
+		if favorite.userID != userID {
+			continue
+		}
+		if article, ok := r.articles[favorite.articleID]; ok {
+			article.FavoritesCount--
+		}
+	}
+}
+
+// deleteUserLocked - удаление пользователя со всем, что ссылается на него, как каскады внешних ключей в postgres
+func (db *DB) deleteUserLocked(userID uuid.UUID) {
+	for id, article := range db.articles {
+		if article.AuthorID == userID {
+			db.deleteArticleLocked(id)
+		}
+	}
+	for id, comment := range db.comments {
+		if comment.AuthorID == userID {
+			db.deleteCommentLocked(id)
+		}
+	}
+
+	db.followers = filter(db.followers, func(f follower) bool { return f.followerID != userID && f.followingID != userID })
+	db.articleFavorites = filter(db.articleFavorites, func(f articleFavorite) bool { return f.userID != userID })
+	db.notifications = filter(db.notifications, func(n entity.Notification) bool { return n.UserID != userID && n.ActorID != userID })
+
+	for id, webhook := range db.webhooks {
+		if webhook.OwnerID == userID {
+			db.deleteWebhookLocked(id)
+		}
+	}
+
+	db.reports = filter(db.reports, func(report entity.Report) bool { return report.ReporterID != userID })
+	for id, moderationCase := range db.moderationCases {
+		if moderationCase.TargetAuthorID == userID {
+			db.deleteModerationCaseLocked(id)
+			continue
+		}
+		if moderationCase.AssigneeID.Valid && moderationCase.AssigneeID.UUID == userID {
+			moderationCase.AssigneeID = uuid.NullUUID{}
+		}
+		if moderationCase.ResolvedBy.Valid && moderationCase.ResolvedBy.UUID == userID {
+			moderationCase.ResolvedBy = uuid.NullUUID{}
+		}
+	}
+	for i := range db.moderationLog {
+		if db.moderationLog[i].ModeratorID == userID {
+			db.moderationLog[i].ModeratorID = uuid.Nil
+		}
+	}
+
+	for id, export := range db.exports {
+		if export.UserID == userID {
+			delete(db.exports, id)
+		}
+	}
+
+	delete(db.users, userID)
+}
+
+// deleteArticleLocked - комментарии, избранное, теги и уведомления удаляются вместе со статьей
+func (db *DB) deleteArticleLocked(articleID uuid.UUID) {
+	for id, comment := range db.comments {
+		if comment.ArticleID == articleID {
+			db.deleteCommentLocked(id)
+		}
+	}
+
+	db.articleFavorites = filter(db.articleFavorites, func(f articleFavorite) bool { return f.articleID != articleID })
+	db.articleTags = filter(db.articleTags, func(t articleTag) bool { return t.articleID != articleID })
+	db.notifications = filter(db.notifications, func(n entity.Notification) bool {
+		return !n.ArticleID.Valid || n.ArticleID.UUID != articleID
+	})
+
+	delete(db.articles, articleID)
+}
+
+// deleteCommentLocked - ответы и уведомления удаляются вместе с комментарием
+func (db *DB) deleteCommentLocked(commentID uuid.UUID) {
+	// the comment could be removed already with its parent or article
+	if _, ok := db.comments[commentID]; !ok {
+		return
+	}
+	delete(db.comments, commentID)
+
+	for id, reply := range db.comments {
+		if reply.ParentID.Valid && reply.ParentID.UUID == commentID {
+			db.deleteCommentLocked(id)
+		}
+	}
+
+	db.notifications = filter(db.notifications, func(n entity.Notification) bool {
+		return !n.CommentID.Valid || n.CommentID.UUID != commentID
+	})
+}
+
+// deleteModerationCaseLocked - жалобы удаляются вместе с кейсом, записи журнала остаются без кейса
+func (db *DB) deleteModerationCaseLocked(caseID uuid.UUID) {
+	db.reports = filter(db.reports, func(report entity.Report) bool { return report.CaseID != caseID })
+	for i := range db.moderationLog {
+		if db.moderationLog[i].CaseID.Valid && db.moderationLog[i].CaseID.UUID == caseID {
+			db.moderationLog[i].CaseID = uuid.NullUUID{}
+		}
+	}
+
+	delete(db.moderationCases, caseID)
+}
+
+// oldestFirst - не больше limit идентификаторов в порядке times
+func oldestFirst(ids []uuid.UUID, times []time.Time, limit int) []uuid.UUID {
+	order := make([]int, len(ids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return times[order[i]].Before(times[order[j]]) })
+
+	from, to := page(len(order), limit, 0)
+
+	sorted := make([]uuid.UUID, 0, to-from)
+	for _, i := range order[from:to] {
+		sorted = append(sorted, ids[i])
+	}
+
+	return sorted
+}
+
+func filter[T any](rows []T, keep func(T) bool) []T {
+	kept := rows[:0]
+	for _, row := range rows {
+		if keep(row) {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,89 @@
+package memdb
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// TxManager - перед fn снимается копия всех таблиц, при ошибке или панике fn таблицы возвращаются к ней.
+// Транзакции выполняются по одной, вложенный Do откатывает только свои изменения, как точка сохранения в pgdb.
+// Изоляции нет: записи вне транзакций, сделанные во время откатываемой транзакции, откатываются вместе с ней
+type TxManager struct {
+	db *DB
+	mu sync.Mutex
+}
+
+type txKey struct{}
+
+func NewTxManager(db *DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if ctx.Value(txKey{}) != m {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		ctx = context.WithValue(ctx, txKey{}, m)
+	}
+
+	m.db.mu.RLock()
+	snapshot := m.db.tables.clone()
+	m.db.mu.RUnlock()
+
+	defer func() {
+		p := recover()
+		if err != nil || p != nil {
+			m.db.mu.Lock()
+			m.db.tables = snapshot
+			m.db.mu.Unlock()
+		}
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// clone - строки копируются по значению: репозитории меняют поля строк, а не то, на что они указывают
+func (t *tables) clone() tables {
+	return tables{
+		users:            cloneRows(t.users),
+		articles:         cloneRows(t.articles),
+		comments:         cloneRows(t.comments),
+		followers:        slices.Clone(t.followers),
+		articleFavorites: slices.Clone(t.articleFavorites),
+		tags:             maps.Clone(t.tags),
+		articleTags:      slices.Clone(t.articleTags),
+		notifications:    slices.Clone(t.notifications),
+		outbox:           cloneRowSlice(t.outbox),
+		webhooks:         cloneRows(t.webhooks),
+		deliveries:       cloneRows(t.deliveries),
+		moderationCases:  cloneRows(t.moderationCases),
+		reports:          slices.Clone(t.reports),
+		moderationLog:    slices.Clone(t.moderationLog),
+		exports:          cloneRows(t.exports),
+		auditLog:         slices.Clone(t.auditLog),
+		lastEventID:      t.lastEventID,
+	}
+}
+
+func cloneRows[K comparable, V any](rows map[K]*V) map[K]*V {
+	clone := make(map[K]*V, len(rows))
+	for key, row := range rows {
+		copied := *row
+		clone[key] = &copied
+	}
+	return clone
+}
+
+func cloneRowSlice[V any](rows []*V) []*V {
+	clone := make([]*V, 0, len(rows))
+	for _, row := range rows {
+		copied := *row
+		clone = append(clone, &copied)
+	}
+	return clone
+}
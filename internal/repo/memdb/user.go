@@ -0,0 +1,337 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+type UserRepo struct {
+	*DB
+}
+
+func NewUserRepo(db *DB) *UserRepo {
+	return &UserRepo{db}
+}
+
+func (r *UserRepo) CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// usernames are unique among deleted users too
+	for _, row := range r.users {
+		if row.Username == user.Username {
+			return uuid.UUID{}, repoerrs.ErrUserAlreadyExists
+		}
+	}
+
+	now := r.now()
+	row := &userRow{User: entity.User{
+		ID:        uuid.New(),
+		Name:      user.Name,
+		Username:  user.Username,
+		Password:  user.Password,
+		Email:     user.Email,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Role:      entity.RoleUser,
+	}}
+
+	err := r.insertEventLocked(entity.EventUserCreated, row.ID, entity.UserCreatedPayload{
+		UserID:   row.ID,
+		Username: row.Username,
+	})
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("UserRepo.CreateUser - r.insertEventLocked: %w", err)
+	}
+
+	r.users[row.ID] = row
+
+	return row.ID, nil
+}
+
+func (r *UserRepo) UpdateUserPassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.activeUserLocked(userID)
+	if !ok || row.Password != oldPassword {
+		return repoerrs.ErrUserNotFound
+	}
+
+	row.Password = newPassword
+	row.PasswordResetRequired = false
+	row.UpdatedAt = r.now()
+
+	return nil
+}
+
+func (r *UserRepo) UpdateUserByID(ctx context.Context, userID uuid.UUID, name, email, description *string, role *entity.RoleType) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.activeUserLocked(userID)
+	if !ok {
+		return repoerrs.ErrUserNotFound
+	}
+
+	if name != nil {
+		row.Name = *name
+	}
+
+	if email != nil {
+		row.Email = *email
+	}
+
+	if description != nil {
+		row.Description = *description
+	}
+
+	if role != nil {
+		row.Role = *role
+	}
+
+	row.UpdatedAt = r.now()
+
+	return nil
+}
+
+func (r *UserRepo) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, row := range r.users {
+		if row.deletedAt == nil && row.Username == username && row.Password == password {
+			return row.User, nil
+		}
+	}
+
+	return entity.User{}, repoerrs.ErrUserNotFound
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.activeUserLocked(userID)
+	if !ok {
+		return entity.User{}, repoerrs.ErrUserNotFound
+	}
+
+	return row.User, nil
+}
+
+func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, row := range r.users {
+		if row.deletedAt == nil && row.Username == username {
+			return row.User, nil
+		}
+	}
+
+	return entity.User{}, repoerrs.ErrUserNotFound
+}
+
+func (r *UserRepo) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []entity.User
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if row, ok := r.activeUserLocked(id); ok {
+			users = append(users, row.User)
+		}
+	}
+
+	return users, nil
+}
+
+// SetUserFollower - пара подписчика и автора уникальна, как в postgres
+func (r *UserRepo) SetUserFollower(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	followerRow, ok := r.users[followerID]
+	if !ok {
+		return false, fmt.Errorf("UserRepo.SetUserFollower - %w", errForeignKey("user", followerID))
+	}
+	following, ok := r.users[followingID]
+	if !ok {
+		return false, fmt.Errorf("UserRepo.SetUserFollower - %w", errForeignKey("user", followingID))
+	}
+
+	for _, f := range r.followers {
+		if f.followerID == followerID && f.followingID == followingID {
+			return false, nil
+		}
+	}
+
+	err := r.insertEventLocked(entity.EventUserFollowed, followingID, entity.UserFollowedPayload{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("UserRepo.SetUserFollower - r.insertEventLocked: %w", err)
+	}
+
+	r.followers = append(r.followers, follower{followerID: followerID, followingID: followingID})
+	following.FollowersCount++
+	followerRow.FollowingCount++
+
+	return true, nil
+}
+
+func (r *UserRepo) GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []entity.User
+	for _, f := range r.followers {
+		if f.followingID != userID {
+			continue
+		}
+		if row, ok := r.activeUserLocked(f.followerID); ok {
+			users = append(users, row.User)
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserRepo) GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []entity.User
+	for _, f := range r.followers {
+		if f.followerID != userID {
+			continue
+		}
+		if row, ok := r.activeUserLocked(f.followingID); ok {
+			users = append(users, row.User)
+		}
+	}
+
+	return users, nil
+}
+
+// DeleteUserByID - мягкое удаление пользователя вместе с его статьями и комментариями.
+// Контент помечается тем же временем, что и пользователь, чтобы восстановить только его
+func (r *UserRepo) DeleteUserByID(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.activeUserLocked(userID)
+	if !ok {
+		return repoerrs.ErrUserNotFound
+	}
+
+	deletedAt := r.now()
+	row.deletedAt = &deletedAt
+
+	for _, article := range r.articles {
+		if article.AuthorID == userID && article.deletedAt == nil {
+			article.deletedAt = &deletedAt
+			err := r.articleEventLocked(entity.EventArticleDeleted, article)
+			if err != nil {
+				return fmt.Errorf("UserRepo.DeleteUserByID - r.articleEventLocked: %w", err)
+			}
+		}
+	}
+	for _, comment := range r.comments {
+		if comment.AuthorID == userID && comment.deletedAt == nil {
+			comment.deletedAt = &deletedAt
+			err := r.commentEventLocked(entity.EventCommentDeleted, comment)
+			if err != nil {
+				return fmt.Errorf("UserRepo.DeleteUserByID - r.commentEventLocked: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RestoreUserByID - восстанавливает пользователя и контент, удаленный вместе с ним
+func (r *UserRepo) RestoreUserByID(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.users[userID]
+	if !ok || row.deletedAt == nil {
+		return repoerrs.ErrUserNotFound
+	}
+
+	deletedAt := *row.deletedAt
+	row.deletedAt = nil
+
+	for _, article := range r.articles {
+		if article.AuthorID == userID && article.deletedAt != nil && article.deletedAt.Equal(deletedAt) {
+			article.deletedAt = nil
+			err := r.articleEventLocked(entity.EventArticleRestored, article)
+			if err != nil {
+				return fmt.Errorf("UserRepo.RestoreUserByID - r.articleEventLocked: %w", err)
+			}
+		}
+	}
+	for _, comment := range r.comments {
+		if comment.AuthorID == userID && comment.deletedAt != nil && comment.deletedAt.Equal(deletedAt) {
+			comment.deletedAt = nil
+			err := r.commentEventLocked(entity.EventCommentRestored, comment)
+			if err != nil {
+				return fmt.Errorf("UserRepo.RestoreUserByID - r.commentEventLocked: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ScheduleUserDeletion - аккаунт будет анонимизирован через gracePeriod, возвращает время удаления
+func (r *UserRepo) ScheduleUserDeletion(ctx context.Context, userID uuid.UUID, gracePeriod time.Duration) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.activeUserLocked(userID)
+	if !ok || row.DeletionScheduledAt != nil {
+		return time.Time{}, repoerrs.ErrUserNotFound
+	}
+
+	scheduledAt := r.now().Add(gracePeriod)
+	row.DeletionScheduledAt = &scheduledAt
+
+	return scheduledAt, nil
+}
+
+// CancelUserDeletion - отмена возможна, пока аккаунт не анонимизирован
+func (r *UserRepo) CancelUserDeletion(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.users[userID]
+	if !ok || row.DeletionScheduledAt == nil || !row.DeletionScheduledAt.After(r.now()) {
+		return repoerrs.ErrUserNotFound
+	}
+
+	row.DeletionScheduledAt = nil
+
+	return nil
+}
+
+func (db *DB) activeUserLocked(userID uuid.UUID) (*userRow, bool) {
+	row, ok := db.users[userID]
+	if !ok || row.deletedAt != nil {
+		return nil, false
+	}
+	return row, true
+}
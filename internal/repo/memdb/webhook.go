@@ -0,0 +1,350 @@
+package memdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+type WebhookRepo struct {
+	*DB
+}
+
+func NewWebhookRepo(db *DB) *WebhookRepo {
+	return &WebhookRepo{db}
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook entity.Webhook) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[webhook.OwnerID]; !ok {
+		return uuid.UUID{}, fmt.Errorf("WebhookRepo.CreateWebhook - %w", errForeignKey("user", webhook.OwnerID))
+	}
+
+	now := r.now()
+	row := &entity.Webhook{
+		ID:         uuid.New(),
+		OwnerID:    webhook.OwnerID,
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		EventTypes: copyEventTypes(webhook.EventTypes),
+		IsGlobal:   webhook.IsGlobal,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	r.webhooks[row.ID] = row
+
+	return row.ID, nil
+}
+
+func (r *WebhookRepo) GetWebhookByID(ctx context.Context, id uuid.UUID) (entity.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.webhooks[id]
+	if !ok {
+		return entity.Webhook{}, repoerrs.ErrWebhookNotFound
+	}
+
+	return copyWebhook(row), nil
+}
+
+func (r *WebhookRepo) GetWebhooksByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]entity.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.webhooksLocked(func(row *entity.Webhook) bool { return row.OwnerID == ownerID }), nil
+}
+
+// GetActiveWebhooksForEvent - активные webhook'и, подписанные на eventType: глобальные и принадлежащие ownerIDs
+func (r *WebhookRepo) GetActiveWebhooksForEvent(ctx context.Context, eventType entity.EventType, ownerIDs []uuid.UUID) ([]entity.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owners := make(map[uuid.UUID]bool, len(ownerIDs))
+	for _, id := range ownerIDs {
+		owners[id] = true
+	}
+
+	return r.webhooksLocked(func(row *entity.Webhook) bool {
+		// webhooks of deleted users stay until the purge but don't receive deliveries
+		if _, ok := r.activeUserLocked(row.OwnerID); !ok || !row.Active {
+			return false
+		}
+		return subscribed(row, eventType) && (row.IsGlobal || owners[row.OwnerID])
+	}), nil
+}
+
+func (r *WebhookRepo) UpdateWebhookByID(ctx context.Context, id uuid.UUID, url *string, eventTypes *[]entity.EventType, active *bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.webhooks[id]
+	if !ok {
+		return repoerrs.ErrWebhookNotFound
+	}
+
+	if url != nil {
+		row.URL = *url
+	}
+
+	if eventTypes != nil {
+		row.EventTypes = copyEventTypes(*eventTypes)
+	}
+
+	if active != nil {
+		row.Active = *active
+	}
+
+	row.UpdatedAt = r.now()
+
+	return nil
+}
+
+func (r *WebhookRepo) DeleteWebhookByID(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return repoerrs.ErrWebhookNotFound
+	}
+
+	r.deleteWebhookLocked(id)
+
+	return nil
+}
+
+// CreateDeliveries - повторная доставка того же события не создает новых записей
+func (r *WebhookRepo) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the batch is inserted as a whole or not at all
+	for _, delivery := range deliveries {
+		if _, ok := r.webhooks[delivery.WebhookID]; !ok {
+			return fmt.Errorf("WebhookRepo.CreateDeliveries - %w", errForeignKey("webhook", delivery.WebhookID))
+		}
+	}
+
+	type key struct {
+		webhookID uuid.UUID
+		eventID   int64
+	}
+	existing := make(map[key]bool, len(r.deliveries))
+	for _, row := range r.deliveries {
+		existing[key{row.WebhookID, row.EventID}] = true
+	}
+
+	now := r.now()
+	for _, delivery := range deliveries {
+		k := key{delivery.WebhookID, delivery.EventID}
+		if existing[k] {
+			continue
+		}
+		existing[k] = true
+
+		row := &deliveryRow{WebhookDelivery: entity.WebhookDelivery{
+			ID:            uuid.New(),
+			WebhookID:     delivery.WebhookID,
+			EventID:       delivery.EventID,
+			EventType:     delivery.EventType,
+			Payload:       append(json.RawMessage(nil), delivery.Payload...),
+			Status:        entity.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}}
+		r.deliveries[row.ID] = row
+	}
+
+	return nil
+}
+
+// LockPendingDeliveries - захват пачки доставок на время lease, аналогично OutboxRepo.LockPendingEvents
+func (r *WebhookRepo) LockPendingDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	var rows []*deliveryRow
+	for _, row := range r.deliveries {
+		if row.Status == entity.WebhookDeliveryPending && !row.NextAttemptAt.After(now) && lockable(row.lockedUntil, now) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].NextAttemptAt.Before(rows[j].NextAttemptAt) })
+
+	from, to := page(len(rows), limit, 0)
+	rows = rows[from:to]
+
+	var deliveries []entity.WebhookDelivery
+	for _, row := range rows {
+		row.lockedUntil = ptr(now.Add(lease))
+		row.Attempts++
+		row.LastAttemptAt = ptr(now)
+		deliveries = append(deliveries, row.WebhookDelivery)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepo) MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, statusCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.deliveries[id]; ok {
+		row.Status = entity.WebhookDeliveryDelivered
+		row.DeliveredAt = ptr(r.now())
+		row.lockedUntil = nil
+		row.LastStatusCode = &statusCode
+		row.LastError = nil
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, statusCode *int, lastError string, retryIn time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.deliveries[id]; ok {
+		row.NextAttemptAt = r.now().Add(retryIn)
+		row.lockedUntil = nil
+		row.LastStatusCode = copyPtr(statusCode)
+		row.LastError = &lastError
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) MarkDeliveryDead(ctx context.Context, id uuid.UUID, statusCode *int, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.deliveries[id]; ok {
+		row.Status = entity.WebhookDeliveryDead
+		row.lockedUntil = nil
+		row.LastStatusCode = copyPtr(statusCode)
+		row.LastError = &lastError
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) GetDeliveryByID(ctx context.Context, id uuid.UUID) (entity.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.deliveries[id]
+	if !ok {
+		return entity.WebhookDelivery{}, repoerrs.ErrWebhookDeliveryNotFound
+	}
+
+	return row.WebhookDelivery, nil
+}
+
+func (r *WebhookRepo) GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]entity.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []entity.WebhookDelivery
+	for _, row := range r.deliveries {
+		if row.WebhookID == webhookID {
+			deliveries = append(deliveries, row.WebhookDelivery)
+		}
+	}
+
+	sortByCreatedAt(deliveries, func(d entity.WebhookDelivery) time.Time { return d.CreatedAt }, true)
+
+	from, to := page(len(deliveries), limit, offset)
+	if from == to {
+		return nil, nil
+	}
+
+	return deliveries[from:to], nil
+}
+
+// ResetDelivery - постановка доставки в очередь заново, в том числе из dead
+func (r *WebhookRepo) ResetDelivery(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.deliveries[id]
+	if !ok {
+		return repoerrs.ErrWebhookDeliveryNotFound
+	}
+
+	row.Status = entity.WebhookDeliveryPending
+	row.Attempts = 0
+	row.NextAttemptAt = r.now()
+	row.lockedUntil = nil
+	row.DeliveredAt = nil
+
+	return nil
+}
+
+// webhooksLocked - webhook'и в порядке создания
+func (r *WebhookRepo) webhooksLocked(match func(row *entity.Webhook) bool) []entity.Webhook {
+	var webhooks []entity.Webhook
+	for _, row := range r.webhooks {
+		if match(row) {
+			webhooks = append(webhooks, copyWebhook(row))
+		}
+	}
+
+	sortByCreatedAt(webhooks, func(webhook entity.Webhook) time.Time { return webhook.CreatedAt }, false)
+
+	return webhooks
+}
+
+// deleteWebhookLocked - доставки удаляются вместе с webhook'ом
+func (db *DB) deleteWebhookLocked(id uuid.UUID) {
+	delete(db.webhooks, id)
+	for deliveryID, row := range db.deliveries {
+		if row.WebhookID == id {
+			delete(db.deliveries, deliveryID)
+		}
+	}
+}
+
+// subscribed - пустой список событий означает подписку на все
+func subscribed(webhook *entity.Webhook, eventType entity.EventType) bool {
+	if len(webhook.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range webhook.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// copyWebhook - список событий не должен меняться снаружи
+func copyWebhook(row *entity.Webhook) entity.Webhook {
+	webhook := *row
+	webhook.EventTypes = copyEventTypes(row.EventTypes)
+	return webhook
+}
+
+// copyEventTypes - пустой список читается из postgres как nil
+func copyEventTypes(eventTypes []entity.EventType) []entity.EventType {
+	if len(eventTypes) == 0 {
+		return nil
+	}
+	return append([]entity.EventType(nil), eventTypes...)
+}
+
+func copyPtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	return ptr(*v)
+}
@@ -0,0 +1,513 @@
+package repo
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/metrics"
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// WithMetrics - репозитории, которые замеряют время каждого вызова.
+// Обертки не встраивают интерфейс, поэтому новый метод без обертки не скомпилируется
+func WithMetrics(repos *Repositories, m *metrics.Metrics) *Repositories {
+	return &Repositories{
+		User:         &userMetrics{next: repos.User, metrics: m},
+		Article:      &articleMetrics{next: repos.Article, metrics: m},
+		Comment:      &commentMetrics{next: repos.Comment, metrics: m},
+		Notification: &notificationMetrics{next: repos.Notification, metrics: m},
+		Outbox:       &outboxMetrics{next: repos.Outbox, metrics: m},
+		Webhook:      &webhookMetrics{next: repos.Webhook, metrics: m},
+		Moderation:   &moderationMetrics{next: repos.Moderation, metrics: m},
+		Retention:    &retentionMetrics{next: repos.Retention, metrics: m},
+		Counter:      &counterMetrics{next: repos.Counter, metrics: m},
+		Export:       &exportMetrics{next: repos.Export, metrics: m},
+		Admin:        &adminMetrics{next: repos.Admin, metrics: m},
+		Audit:        &auditMetrics{next: repos.Audit, metrics: m},
+	}
+}
+
+type userMetrics struct {
+	next    User
+	metrics *metrics.Metrics
+}
+
+func (r *userMetrics) CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error) {
+	defer r.metrics.ObserveRepoQuery("user", "CreateUser", time.Now())
+	return r.next.CreateUser(ctx, user)
+}
+
+func (r *userMetrics) UpdateUserPassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
+	defer r.metrics.ObserveRepoQuery("user", "UpdateUserPassword", time.Now())
+	return r.next.UpdateUserPassword(ctx, userID, oldPassword, newPassword)
+}
+
+func (r *userMetrics) UpdateUserByID(ctx context.Context, userID uuid.UUID, name, email, description *string, role *entity.RoleType) error {
+	defer r.metrics.ObserveRepoQuery("user", "UpdateUserByID", time.Now())
+	return r.next.UpdateUserByID(ctx, userID, name, email, description, role)
+}
+
+func (r *userMetrics) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error) {
+	defer r.metrics.ObserveRepoQuery("user", "GetUserByUsernameAndPassword", time.Now())
+	return r.next.GetUserByUsernameAndPassword(ctx, username, password)
+}
+
+func (r *userMetrics) GetUserByID(ctx context.Context, userID uuid.UUID) (entity.User, error) {
+	defer r.metrics.ObserveRepoQuery("user", "GetUserByID", time.Now())
+	return r.next.GetUserByID(ctx, userID)
+}
+
+func (r *userMetrics) GetUserByUsername(ctx context.Context, username string) (entity.User, error) {
+	defer r.metrics.ObserveRepoQuery("user", "GetUserByUsername", time.Now())
+	return r.next.GetUserByUsername(ctx, username)
+}
+
+func (r *userMetrics) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]entity.User, error) {
+	defer r.metrics.ObserveRepoQuery("user", "GetUsersByIDs", time.Now())
+	return r.next.GetUsersByIDs(ctx, userIDs)
+}
+
+func (r *userMetrics) SetUserFollower(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) (bool, error) {
+	defer r.metrics.ObserveRepoQuery("user", "SetUserFollower", time.Now())
+	return r.next.SetUserFollower(ctx, followerID, followingID)
+}
+
+func (r *userMetrics) GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	defer r.metrics.ObserveRepoQuery("user", "GetUserFollowers", time.Now())
+	return r.next.GetUserFollowers(ctx, userID)
+}
+
+func (r *userMetrics) GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	defer r.metrics.ObserveRepoQuery("user", "GetUserFollowings", time.Now())
+	return r.next.GetUserFollowings(ctx, userID)
+}
+
+func (r *userMetrics) DeleteUserByID(ctx context.Context, userID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("user", "DeleteUserByID", time.Now())
+	return r.next.DeleteUserByID(ctx, userID)
+}
+
+func (r *userMetrics) RestoreUserByID(ctx context.Context, userID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("user", "RestoreUserByID", time.Now())
+	return r.next.RestoreUserByID(ctx, userID)
+}
+
+func (r *userMetrics) ScheduleUserDeletion(ctx context.Context, userID uuid.UUID, gracePeriod time.Duration) (time.Time, error) {
+	defer r.metrics.ObserveRepoQuery("user", "ScheduleUserDeletion", time.Now())
+	return r.next.ScheduleUserDeletion(ctx, userID, gracePeriod)
+}
+
+func (r *userMetrics) CancelUserDeletion(ctx context.Context, userID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("user", "CancelUserDeletion", time.Now())
+	return r.next.CancelUserDeletion(ctx, userID)
+}
+
+type articleMetrics struct {
+	next    Article
+	metrics *metrics.Metrics
+}
+
+func (r *articleMetrics) CreateArticle(ctx context.Context, article entity.Article) (uuid.UUID, error) {
+	defer r.metrics.ObserveRepoQuery("article", "CreateArticle", time.Now())
+	return r.next.CreateArticle(ctx, article)
+}
+
+func (r *articleMetrics) GetArticleByID(ctx context.Context, id uuid.UUID) (entity.Article, error) {
+	defer r.metrics.ObserveRepoQuery("article", "GetArticleByID", time.Now())
+	return r.next.GetArticleByID(ctx, id)
+}
+
+func (r *articleMetrics) GetArticlesByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Article, error) {
+	defer r.metrics.ObserveRepoQuery("article", "GetArticlesByAuthorID", time.Now())
+	return r.next.GetArticlesByAuthorID(ctx, authorID)
+}
+
+func (r *articleMetrics) GetNewestArticles(ctx context.Context, limit, offset int) ([]entity.Article, error) {
+	defer r.metrics.ObserveRepoQuery("article", "GetNewestArticles", time.Now())
+	return r.next.GetNewestArticles(ctx, limit, offset)
+}
+
+func (r *articleMetrics) SetArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) (bool, error) {
+	defer r.metrics.ObserveRepoQuery("article", "SetArticleFavorite", time.Now())
+	return r.next.SetArticleFavorite(ctx, userID, articleID)
+}
+
+func (r *articleMetrics) RemoveArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("article", "RemoveArticleFavorite", time.Now())
+	return r.next.RemoveArticleFavorite(ctx, userID, articleID)
+}
+
+func (r *articleMetrics) GetFavoriteArticles(ctx context.Context, userID uuid.UUID) ([]entity.Article, error) {
+	defer r.metrics.ObserveRepoQuery("article", "GetFavoriteArticles", time.Now())
+	return r.next.GetFavoriteArticles(ctx, userID)
+}
+
+func (r *articleMetrics) GetFavoritedArticleIDs(ctx context.Context, userID uuid.UUID, articleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	defer r.metrics.ObserveRepoQuery("article", "GetFavoritedArticleIDs", time.Now())
+	return r.next.GetFavoritedArticleIDs(ctx, userID, articleIDs)
+}
+
+func (r *articleMetrics) GetFeedArticles(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Article, error) {
+	defer r.metrics.ObserveRepoQuery("article", "GetFeedArticles", time.Now())
+	return r.next.GetFeedArticles(ctx, userID, limit, offset)
+}
+
+func (r *articleMetrics) SearchArticles(ctx context.Context, query string, limit, offset int) ([]entity.Article, error) {
+	defer r.metrics.ObserveRepoQuery("article", "SearchArticles", time.Now())
+	return r.next.SearchArticles(ctx, query, limit, offset)
+}
+
+func (r *articleMetrics) GetArticlesTags(ctx context.Context, articleIDs []uuid.UUID) (map[uuid.UUID][]entity.Tag, error) {
+	defer r.metrics.ObserveRepoQuery("article", "GetArticlesTags", time.Now())
+	return r.next.GetArticlesTags(ctx, articleIDs)
+}
+
+func (r *articleMetrics) UpdateArticleByID(ctx context.Context, articleID, editorID uuid.UUID, title, description, content *string) error {
+	defer r.metrics.ObserveRepoQuery("article", "UpdateArticleByID", time.Now())
+	return r.next.UpdateArticleByID(ctx, articleID, editorID, title, description, content)
+}
+
+func (r *articleMetrics) DeleteArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("article", "DeleteArticleByID", time.Now())
+	return r.next.DeleteArticleByID(ctx, articleID)
+}
+
+func (r *articleMetrics) RestoreArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("article", "RestoreArticleByID", time.Now())
+	return r.next.RestoreArticleByID(ctx, articleID)
+}
+
+type commentMetrics struct {
+	next    Comment
+	metrics *metrics.Metrics
+}
+
+func (r *commentMetrics) CreateComment(ctx context.Context, comment entity.Comment) (entity.Comment, error) {
+	defer r.metrics.ObserveRepoQuery("comment", "CreateComment", time.Now())
+	return r.next.CreateComment(ctx, comment)
+}
+
+func (r *commentMetrics) GetCommentByID(ctx context.Context, id uuid.UUID) (entity.Comment, error) {
+	defer r.metrics.ObserveRepoQuery("comment", "GetCommentByID", time.Now())
+	return r.next.GetCommentByID(ctx, id)
+}
+
+func (r *commentMetrics) GetCommentsByArticleID(ctx context.Context, articleID uuid.UUID, limit, offset int) ([]entity.Comment, error) {
+	defer r.metrics.ObserveRepoQuery("comment", "GetCommentsByArticleID", time.Now())
+	return r.next.GetCommentsByArticleID(ctx, articleID, limit, offset)
+}
+
+func (r *commentMetrics) UpdateCommentByID(ctx context.Context, commentID uuid.UUID, content string) error {
+	defer r.metrics.ObserveRepoQuery("comment", "UpdateCommentByID", time.Now())
+	return r.next.UpdateCommentByID(ctx, commentID, content)
+}
+
+func (r *commentMetrics) DeleteCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("comment", "DeleteCommentByID", time.Now())
+	return r.next.DeleteCommentByID(ctx, commentID)
+}
+
+func (r *commentMetrics) RestoreCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("comment", "RestoreCommentByID", time.Now())
+	return r.next.RestoreCommentByID(ctx, commentID)
+}
+
+func (r *commentMetrics) GetCommentsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Comment, error) {
+	defer r.metrics.ObserveRepoQuery("comment", "GetCommentsByAuthorID", time.Now())
+	return r.next.GetCommentsByAuthorID(ctx, authorID)
+}
+
+func (r *commentMetrics) GetFavoriteComments(ctx context.Context, userID uuid.UUID) ([]entity.Comment, error) {
+	defer r.metrics.ObserveRepoQuery("comment", "GetFavoriteComments", time.Now())
+	return r.next.GetFavoriteComments(ctx, userID)
+}
+
+type notificationMetrics struct {
+	next    Notification
+	metrics *metrics.Metrics
+}
+
+func (r *notificationMetrics) CreateNotification(ctx context.Context, notification entity.Notification) (entity.Notification, error) {
+	defer r.metrics.ObserveRepoQuery("notification", "CreateNotification", time.Now())
+	return r.next.CreateNotification(ctx, notification)
+}
+
+func (r *notificationMetrics) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, error) {
+	defer r.metrics.ObserveRepoQuery("notification", "GetNotificationsByUserID", time.Now())
+	return r.next.GetNotificationsByUserID(ctx, userID, limit, offset)
+}
+
+type outboxMetrics struct {
+	next    Outbox
+	metrics *metrics.Metrics
+}
+
+func (r *outboxMetrics) LockPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.Event, error) {
+	defer r.metrics.ObserveRepoQuery("outbox", "LockPendingEvents", time.Now())
+	return r.next.LockPendingEvents(ctx, limit, lease)
+}
+
+func (r *outboxMetrics) MarkEventPublished(ctx context.Context, id int64) error {
+	defer r.metrics.ObserveRepoQuery("outbox", "MarkEventPublished", time.Now())
+	return r.next.MarkEventPublished(ctx, id)
+}
+
+func (r *outboxMetrics) MarkEventFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration) error {
+	defer r.metrics.ObserveRepoQuery("outbox", "MarkEventFailed", time.Now())
+	return r.next.MarkEventFailed(ctx, id, lastError, retryIn)
+}
+
+func (r *outboxMetrics) MarkEventDead(ctx context.Context, id int64, lastError string) error {
+	defer r.metrics.ObserveRepoQuery("outbox", "MarkEventDead", time.Now())
+	return r.next.MarkEventDead(ctx, id, lastError)
+}
+
+type webhookMetrics struct {
+	next    Webhook
+	metrics *metrics.Metrics
+}
+
+func (r *webhookMetrics) CreateWebhook(ctx context.Context, webhook entity.Webhook) (uuid.UUID, error) {
+	defer r.metrics.ObserveRepoQuery("webhook", "CreateWebhook", time.Now())
+	return r.next.CreateWebhook(ctx, webhook)
+}
+
+func (r *webhookMetrics) GetWebhookByID(ctx context.Context, id uuid.UUID) (entity.Webhook, error) {
+	defer r.metrics.ObserveRepoQuery("webhook", "GetWebhookByID", time.Now())
+	return r.next.GetWebhookByID(ctx, id)
+}
+
+func (r *webhookMetrics) GetWebhooksByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]entity.Webhook, error) {
+	defer r.metrics.ObserveRepoQuery("webhook", "GetWebhooksByOwnerID", time.Now())
+	return r.next.GetWebhooksByOwnerID(ctx, ownerID)
+}
+
+func (r *webhookMetrics) GetActiveWebhooksForEvent(ctx context.Context, eventType entity.EventType, ownerIDs []uuid.UUID) ([]entity.Webhook, error) {
+	defer r.metrics.ObserveRepoQuery("webhook", "GetActiveWebhooksForEvent", time.Now())
+	return r.next.GetActiveWebhooksForEvent(ctx, eventType, ownerIDs)
+}
+
+func (r *webhookMetrics) UpdateWebhookByID(ctx context.Context, id uuid.UUID, url *string, eventTypes *[]entity.EventType, active *bool) error {
+	defer r.metrics.ObserveRepoQuery("webhook", "UpdateWebhookByID", time.Now())
+	return r.next.UpdateWebhookByID(ctx, id, url, eventTypes, active)
+}
+
+func (r *webhookMetrics) DeleteWebhookByID(ctx context.Context, id uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("webhook", "DeleteWebhookByID", time.Now())
+	return r.next.DeleteWebhookByID(ctx, id)
+}
+
+func (r *webhookMetrics) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	defer r.metrics.ObserveRepoQuery("webhook", "CreateDeliveries", time.Now())
+	return r.next.CreateDeliveries(ctx, deliveries)
+}
+
+func (r *webhookMetrics) LockPendingDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	defer r.metrics.ObserveRepoQuery("webhook", "LockPendingDeliveries", time.Now())
+	return r.next.LockPendingDeliveries(ctx, limit, lease)
+}
+
+func (r *webhookMetrics) MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, statusCode int) error {
+	defer r.metrics.ObserveRepoQuery("webhook", "MarkDeliveryDelivered", time.Now())
+	return r.next.MarkDeliveryDelivered(ctx, id, statusCode)
+}
+
+func (r *webhookMetrics) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, statusCode *int, lastError string, retryIn time.Duration) error {
+	defer r.metrics.ObserveRepoQuery("webhook", "MarkDeliveryFailed", time.Now())
+	return r.next.MarkDeliveryFailed(ctx, id, statusCode, lastError, retryIn)
+}
+
+func (r *webhookMetrics) MarkDeliveryDead(ctx context.Context, id uuid.UUID, statusCode *int, lastError string) error {
+	defer r.metrics.ObserveRepoQuery("webhook", "MarkDeliveryDead", time.Now())
+	return r.next.MarkDeliveryDead(ctx, id, statusCode, lastError)
+}
+
+func (r *webhookMetrics) GetDeliveryByID(ctx context.Context, id uuid.UUID) (entity.WebhookDelivery, error) {
+	defer r.metrics.ObserveRepoQuery("webhook", "GetDeliveryByID", time.Now())
+	return r.next.GetDeliveryByID(ctx, id)
+}
+
+func (r *webhookMetrics) GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]entity.WebhookDelivery, error) {
+	defer r.metrics.ObserveRepoQuery("webhook", "GetDeliveriesByWebhookID", time.Now())
+	return r.next.GetDeliveriesByWebhookID(ctx, webhookID, limit, offset)
+}
+
+func (r *webhookMetrics) ResetDelivery(ctx context.Context, id uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("webhook", "ResetDelivery", time.Now())
+	return r.next.ResetDelivery(ctx, id)
+}
+
+type moderationMetrics struct {
+	next    Moderation
+	metrics *metrics.Metrics
+}
+
+func (r *moderationMetrics) CreateReport(ctx context.Context, moderationCase entity.ModerationCase, report entity.Report) (entity.Report, error) {
+	defer r.metrics.ObserveRepoQuery("moderation", "CreateReport", time.Now())
+	return r.next.CreateReport(ctx, moderationCase, report)
+}
+
+func (r *moderationMetrics) GetModerationCaseByID(ctx context.Context, id uuid.UUID) (entity.ModerationCase, error) {
+	defer r.metrics.ObserveRepoQuery("moderation", "GetModerationCaseByID", time.Now())
+	return r.next.GetModerationCaseByID(ctx, id)
+}
+
+func (r *moderationMetrics) GetModerationCases(ctx context.Context, status *entity.ModerationStatus, targetType *entity.ReportTargetType, limit, offset int) ([]entity.ModerationCase, error) {
+	defer r.metrics.ObserveRepoQuery("moderation", "GetModerationCases", time.Now())
+	return r.next.GetModerationCases(ctx, status, targetType, limit, offset)
+}
+
+func (r *moderationMetrics) GetReportsByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.Report, error) {
+	defer r.metrics.ObserveRepoQuery("moderation", "GetReportsByCaseID", time.Now())
+	return r.next.GetReportsByCaseID(ctx, caseID)
+}
+
+func (r *moderationMetrics) ClaimModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID) error {
+	defer r.metrics.ObserveRepoQuery("moderation", "ClaimModerationCase", time.Now())
+	return r.next.ClaimModerationCase(ctx, moderationCase, moderatorID)
+}
+
+func (r *moderationMetrics) ResolveModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) error {
+	defer r.metrics.ObserveRepoQuery("moderation", "ResolveModerationCase", time.Now())
+	return r.next.ResolveModerationCase(ctx, moderationCase, moderatorID, action, note)
+}
+
+func (r *moderationMetrics) GetModerationLog(ctx context.Context, moderatorID, caseID uuid.NullUUID, limit, offset int) ([]entity.ModerationLogEntry, error) {
+	defer r.metrics.ObserveRepoQuery("moderation", "GetModerationLog", time.Now())
+	return r.next.GetModerationLog(ctx, moderatorID, caseID, limit, offset)
+}
+
+type retentionMetrics struct {
+	next    Retention
+	metrics *metrics.Metrics
+}
+
+func (r *retentionMetrics) PurgeDeletedComments(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	defer r.metrics.ObserveRepoQuery("retention", "PurgeDeletedComments", time.Now())
+	return r.next.PurgeDeletedComments(ctx, olderThan, limit)
+}
+
+func (r *retentionMetrics) PurgeDeletedArticles(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	defer r.metrics.ObserveRepoQuery("retention", "PurgeDeletedArticles", time.Now())
+	return r.next.PurgeDeletedArticles(ctx, olderThan, limit)
+}
+
+func (r *retentionMetrics) PurgeDeletedUsers(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	defer r.metrics.ObserveRepoQuery("retention", "PurgeDeletedUsers", time.Now())
+	return r.next.PurgeDeletedUsers(ctx, olderThan, limit)
+}
+
+func (r *retentionMetrics) AnonymizeScheduledUsers(ctx context.Context, limit int) (entity.Purged, error) {
+	defer r.metrics.ObserveRepoQuery("retention", "AnonymizeScheduledUsers", time.Now())
+	return r.next.AnonymizeScheduledUsers(ctx, limit)
+}
+
+type counterMetrics struct {
+	next    Counter
+	metrics *metrics.Metrics
+}
+
+func (r *counterMetrics) ReconcileCounters(ctx context.Context, fix bool) ([]entity.CounterDrift, error) {
+	defer r.metrics.ObserveRepoQuery("counter", "ReconcileCounters", time.Now())
+	return r.next.ReconcileCounters(ctx, fix)
+}
+
+type exportMetrics struct {
+	next    Export
+	metrics *metrics.Metrics
+}
+
+func (r *exportMetrics) CreateExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	defer r.metrics.ObserveRepoQuery("export", "CreateExport", time.Now())
+	return r.next.CreateExport(ctx, userID)
+}
+
+func (r *exportMetrics) GetExportByID(ctx context.Context, id uuid.UUID) (entity.UserExport, error) {
+	defer r.metrics.ObserveRepoQuery("export", "GetExportByID", time.Now())
+	return r.next.GetExportByID(ctx, id)
+}
+
+func (r *exportMetrics) GetPendingExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	defer r.metrics.ObserveRepoQuery("export", "GetPendingExport", time.Now())
+	return r.next.GetPendingExport(ctx, userID)
+}
+
+func (r *exportMetrics) GetExportArchive(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	defer r.metrics.ObserveRepoQuery("export", "GetExportArchive", time.Now())
+	return r.next.GetExportArchive(ctx, id)
+}
+
+func (r *exportMetrics) LockPendingExports(ctx context.Context, limit int, lease time.Duration) ([]entity.UserExport, error) {
+	defer r.metrics.ObserveRepoQuery("export", "LockPendingExports", time.Now())
+	return r.next.LockPendingExports(ctx, limit, lease)
+}
+
+func (r *exportMetrics) MarkExportReady(ctx context.Context, id uuid.UUID, archive []byte, ttl time.Duration) error {
+	defer r.metrics.ObserveRepoQuery("export", "MarkExportReady", time.Now())
+	return r.next.MarkExportReady(ctx, id, archive, ttl)
+}
+
+func (r *exportMetrics) MarkExportFailed(ctx context.Context, id uuid.UUID, lastError string, final bool) error {
+	defer r.metrics.ObserveRepoQuery("export", "MarkExportFailed", time.Now())
+	return r.next.MarkExportFailed(ctx, id, lastError, final)
+}
+
+func (r *exportMetrics) DeleteExpiredExports(ctx context.Context) (int, error) {
+	defer r.metrics.ObserveRepoQuery("export", "DeleteExpiredExports", time.Now())
+	return r.next.DeleteExpiredExports(ctx)
+}
+
+func (r *exportMetrics) GetUserVotes(ctx context.Context, userID uuid.UUID) ([]entity.Vote, error) {
+	defer r.metrics.ObserveRepoQuery("export", "GetUserVotes", time.Now())
+	return r.next.GetUserVotes(ctx, userID)
+}
+
+type adminMetrics struct {
+	next    Admin
+	metrics *metrics.Metrics
+}
+
+func (r *adminMetrics) SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	defer r.metrics.ObserveRepoQuery("admin", "SearchUsers", time.Now())
+	return r.next.SearchUsers(ctx, filter)
+}
+
+func (r *adminMetrics) SetUserRole(ctx context.Context, userID uuid.UUID, role entity.RoleType, entry entity.AuditEntry) error {
+	defer r.metrics.ObserveRepoQuery("admin", "SetUserRole", time.Now())
+	return r.next.SetUserRole(ctx, userID, role, entry)
+}
+
+func (r *adminMetrics) SetUserBanned(ctx context.Context, userID uuid.UUID, banned bool, entry entity.AuditEntry) error {
+	defer r.metrics.ObserveRepoQuery("admin", "SetUserBanned", time.Now())
+	return r.next.SetUserBanned(ctx, userID, banned, entry)
+}
+
+func (r *adminMetrics) ResetUserPassword(ctx context.Context, userID uuid.UUID, password string, entry entity.AuditEntry) error {
+	defer r.metrics.ObserveRepoQuery("admin", "ResetUserPassword", time.Now())
+	return r.next.ResetUserPassword(ctx, userID, password, entry)
+}
+
+func (r *adminMetrics) RevokeUserSessions(ctx context.Context, userID uuid.UUID, entry entity.AuditEntry) error {
+	defer r.metrics.ObserveRepoQuery("admin", "RevokeUserSessions", time.Now())
+	return r.next.RevokeUserSessions(ctx, userID, entry)
+}
+
+type auditMetrics struct {
+	next    Audit
+	metrics *metrics.Metrics
+}
+
+func (r *auditMetrics) CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error {
+	defer r.metrics.ObserveRepoQuery("audit", "CreateAuditEntry", time.Now())
+	return r.next.CreateAuditEntry(ctx, entry)
+}
+
+func (r *auditMetrics) GetAuditLog(ctx context.Context, filter entity.AuditFilter, limit, offset int) ([]entity.AuditEntry, error) {
+	defer r.metrics.ObserveRepoQuery("audit", "GetAuditLog", time.Now())
+	return r.next.GetAuditLog(ctx, filter, limit, offset)
+}
+
+func (r *auditMetrics) ExportAuditLog(ctx context.Context, filter entity.AuditFilter, fn func(entry entity.AuditEntry) error) error {
+	defer r.metrics.ObserveRepoQuery("audit", "ExportAuditLog", time.Now())
+	return r.next.ExportAuditLog(ctx, filter, fn)
+}
@@ -0,0 +1,86 @@
+package repo_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/metrics"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/mocks"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMetrics_Latency(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	article := mocks.NewMockArticle(ctrl)
+	comment := mocks.NewMockComment(ctrl)
+
+	m := metrics.New()
+	repos := repo.WithMetrics(&repo.Repositories{Article: article, Comment: comment}, m)
+
+	const delay = 20 * time.Millisecond
+	article.EXPECT().GetArticleByID(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, uuid.UUID) (entity.Article, error) {
+		time.Sleep(delay)
+		return entity.Article{}, nil
+	}).Times(2)
+	comment.EXPECT().GetCommentByID(gomock.Any(), gomock.Any()).Return(entity.Comment{}, repoerrs.ErrCommentNotFound)
+
+	for i := 0; i < 2; i++ {
+		if _, err := repos.GetArticleByID(ctx, uuid.New()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// failed calls are measured too and the error is passed through unchanged
+	_, err := repos.GetCommentByID(ctx, uuid.New())
+	if !errors.Is(err, repoerrs.ErrCommentNotFound) {
+		t.Errorf("err = %v, want %v", err, repoerrs.ErrCommentNotFound)
+	}
+
+	series := repoQuerySeries(t, m)
+	if got := series[`blog_repo_query_duration_seconds_count{method="GetArticleByID",repo="article"}`]; got != 2 {
+		t.Errorf("article.GetArticleByID count = %v, want 2", got)
+	}
+	if got := series[`blog_repo_query_duration_seconds_sum{method="GetArticleByID",repo="article"}`]; got < 2*delay.Seconds() {
+		t.Errorf("article.GetArticleByID sum = %v, want at least %v", got, 2*delay.Seconds())
+	}
+	// buckets below the delay stay empty
+	if got, ok := series[`blog_repo_query_duration_seconds_bucket{method="GetArticleByID",repo="article",le="0.01"}`]; !ok || got != 0 {
+		t.Errorf("%v calls in bucket le=0.01, faster than the call itself", got)
+	}
+	if got := series[`blog_repo_query_duration_seconds_count{method="GetCommentByID",repo="comment"}`]; got != 1 {
+		t.Errorf("comment.GetCommentByID count = %v, want 1", got)
+	}
+}
+
+// repoQuerySeries - значения рядов blog_repo_query_duration_seconds из ответа /metrics по имени ряда с метками
+func repoQuerySeries(t *testing.T, m *metrics.Metrics) map[string]float64 {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	series := make(map[string]float64)
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if !strings.HasPrefix(line, "blog_repo_query_duration_seconds") {
+			continue
+		}
+
+		i := strings.LastIndex(line, " ")
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		series[line[:i]] = value
+	}
+
+	return series
+}
@@ -0,0 +1,1539 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+//
+// Generated by this command:
+//
+//	mockgen -source=repo.go -destination=mocks/repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	entity "blog-backend/internal/entity"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUser is a mock of User interface.
+type MockUser struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMockRecorder
+}
+
+// MockUserMockRecorder is the mock recorder for MockUser.
+type MockUserMockRecorder struct {
+	mock *MockUser
+}
+
+// NewMockUser creates a new mock instance.
+func NewMockUser(ctrl *gomock.Controller) *MockUser {
+	mock := &MockUser{ctrl: ctrl}
+	mock.recorder = &MockUserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser) EXPECT() *MockUserMockRecorder {
+	return m.recorder
+}
+
+// CancelUserDeletion mocks base method.
+func (m *MockUser) CancelUserDeletion(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelUserDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelUserDeletion indicates an expected call of CancelUserDeletion.
+func (mr *MockUserMockRecorder) CancelUserDeletion(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelUserDeletion", reflect.TypeOf((*MockUser)(nil).CancelUserDeletion), ctx, userID)
+}
+
+// CreateUser mocks base method.
+func (m *MockUser) CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserMockRecorder) CreateUser(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUser)(nil).CreateUser), ctx, user)
+}
+
+// DeleteUserByID mocks base method.
+func (m *MockUser) DeleteUserByID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserByID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserByID indicates an expected call of DeleteUserByID.
+func (mr *MockUserMockRecorder) DeleteUserByID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserByID", reflect.TypeOf((*MockUser)(nil).DeleteUserByID), ctx, userID)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUser) GetUserByID(ctx context.Context, userID uuid.UUID) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserMockRecorder) GetUserByID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUser)(nil).GetUserByID), ctx, userID)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockUser) GetUserByUsername(ctx context.Context, username string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockUserMockRecorder) GetUserByUsername(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUser)(nil).GetUserByUsername), ctx, username)
+}
+
+// GetUserByUsernameAndPassword mocks base method.
+func (m *MockUser) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsernameAndPassword", ctx, username, password)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsernameAndPassword indicates an expected call of GetUserByUsernameAndPassword.
+func (mr *MockUserMockRecorder) GetUserByUsernameAndPassword(ctx, username, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsernameAndPassword", reflect.TypeOf((*MockUser)(nil).GetUserByUsernameAndPassword), ctx, username, password)
+}
+
+// GetUserFollowers mocks base method.
+func (m *MockUser) GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFollowers", ctx, userID)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFollowers indicates an expected call of GetUserFollowers.
+func (mr *MockUserMockRecorder) GetUserFollowers(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFollowers", reflect.TypeOf((*MockUser)(nil).GetUserFollowers), ctx, userID)
+}
+
+// GetUserFollowings mocks base method.
+func (m *MockUser) GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFollowings", ctx, userID)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFollowings indicates an expected call of GetUserFollowings.
+func (mr *MockUserMockRecorder) GetUserFollowings(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFollowings", reflect.TypeOf((*MockUser)(nil).GetUserFollowings), ctx, userID)
+}
+
+// GetUsersByIDs mocks base method.
+func (m *MockUser) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByIDs", ctx, userIDs)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByIDs indicates an expected call of GetUsersByIDs.
+func (mr *MockUserMockRecorder) GetUsersByIDs(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByIDs", reflect.TypeOf((*MockUser)(nil).GetUsersByIDs), ctx, userIDs)
+}
+
+// RestoreUserByID mocks base method.
+func (m *MockUser) RestoreUserByID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUserByID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUserByID indicates an expected call of RestoreUserByID.
+func (mr *MockUserMockRecorder) RestoreUserByID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserByID", reflect.TypeOf((*MockUser)(nil).RestoreUserByID), ctx, userID)
+}
+
+// ScheduleUserDeletion mocks base method.
+func (m *MockUser) ScheduleUserDeletion(ctx context.Context, userID uuid.UUID, gracePeriod time.Duration) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleUserDeletion", ctx, userID, gracePeriod)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleUserDeletion indicates an expected call of ScheduleUserDeletion.
+func (mr *MockUserMockRecorder) ScheduleUserDeletion(ctx, userID, gracePeriod any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleUserDeletion", reflect.TypeOf((*MockUser)(nil).ScheduleUserDeletion), ctx, userID, gracePeriod)
+}
+
+// SetUserFollower mocks base method.
+func (m *MockUser) SetUserFollower(ctx context.Context, followerID, followingID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserFollower", ctx, followerID, followingID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserFollower indicates an expected call of SetUserFollower.
+func (mr *MockUserMockRecorder) SetUserFollower(ctx, followerID, followingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserFollower", reflect.TypeOf((*MockUser)(nil).SetUserFollower), ctx, followerID, followingID)
+}
+
+// UpdateUserByID mocks base method.
+func (m *MockUser) UpdateUserByID(ctx context.Context, userID uuid.UUID, name, email, description *string, role *entity.RoleType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserByID", ctx, userID, name, email, description, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserByID indicates an expected call of UpdateUserByID.
+func (mr *MockUserMockRecorder) UpdateUserByID(ctx, userID, name, email, description, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserByID", reflect.TypeOf((*MockUser)(nil).UpdateUserByID), ctx, userID, name, email, description, role)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockUser) UpdateUserPassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", ctx, userID, oldPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockUserMockRecorder) UpdateUserPassword(ctx, userID, oldPassword, newPassword any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockUser)(nil).UpdateUserPassword), ctx, userID, oldPassword, newPassword)
+}
+
+// MockArticle is a mock of Article interface.
+type MockArticle struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleMockRecorder
+}
+
+// MockArticleMockRecorder is the mock recorder for MockArticle.
+type MockArticleMockRecorder struct {
+	mock *MockArticle
+}
+
+// NewMockArticle creates a new mock instance.
+func NewMockArticle(ctrl *gomock.Controller) *MockArticle {
+	mock := &MockArticle{ctrl: ctrl}
+	mock.recorder = &MockArticleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticle) EXPECT() *MockArticleMockRecorder {
+	return m.recorder
+}
+
+// CreateArticle mocks base method.
+func (m *MockArticle) CreateArticle(ctx context.Context, article entity.Article) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateArticle", ctx, article)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateArticle indicates an expected call of CreateArticle.
+func (mr *MockArticleMockRecorder) CreateArticle(ctx, article any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateArticle", reflect.TypeOf((*MockArticle)(nil).CreateArticle), ctx, article)
+}
+
+// DeleteArticleByID mocks base method.
+func (m *MockArticle) DeleteArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticleByID", ctx, articleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArticleByID indicates an expected call of DeleteArticleByID.
+func (mr *MockArticleMockRecorder) DeleteArticleByID(ctx, articleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticleByID", reflect.TypeOf((*MockArticle)(nil).DeleteArticleByID), ctx, articleID)
+}
+
+// GetArticleByID mocks base method.
+func (m *MockArticle) GetArticleByID(ctx context.Context, id uuid.UUID) (entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticleByID", ctx, id)
+	ret0, _ := ret[0].(entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticleByID indicates an expected call of GetArticleByID.
+func (mr *MockArticleMockRecorder) GetArticleByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleByID", reflect.TypeOf((*MockArticle)(nil).GetArticleByID), ctx, id)
+}
+
+// GetArticlesByAuthorID mocks base method.
+func (m *MockArticle) GetArticlesByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticlesByAuthorID", ctx, authorID)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticlesByAuthorID indicates an expected call of GetArticlesByAuthorID.
+func (mr *MockArticleMockRecorder) GetArticlesByAuthorID(ctx, authorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticlesByAuthorID", reflect.TypeOf((*MockArticle)(nil).GetArticlesByAuthorID), ctx, authorID)
+}
+
+// GetArticlesTags mocks base method.
+func (m *MockArticle) GetArticlesTags(ctx context.Context, articleIDs []uuid.UUID) (map[uuid.UUID][]entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticlesTags", ctx, articleIDs)
+	ret0, _ := ret[0].(map[uuid.UUID][]entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticlesTags indicates an expected call of GetArticlesTags.
+func (mr *MockArticleMockRecorder) GetArticlesTags(ctx, articleIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticlesTags", reflect.TypeOf((*MockArticle)(nil).GetArticlesTags), ctx, articleIDs)
+}
+
+// GetFavoriteArticles mocks base method.
+func (m *MockArticle) GetFavoriteArticles(ctx context.Context, userID uuid.UUID) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFavoriteArticles", ctx, userID)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFavoriteArticles indicates an expected call of GetFavoriteArticles.
+func (mr *MockArticleMockRecorder) GetFavoriteArticles(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFavoriteArticles", reflect.TypeOf((*MockArticle)(nil).GetFavoriteArticles), ctx, userID)
+}
+
+// GetFavoritedArticleIDs mocks base method.
+func (m *MockArticle) GetFavoritedArticleIDs(ctx context.Context, userID uuid.UUID, articleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFavoritedArticleIDs", ctx, userID, articleIDs)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFavoritedArticleIDs indicates an expected call of GetFavoritedArticleIDs.
+func (mr *MockArticleMockRecorder) GetFavoritedArticleIDs(ctx, userID, articleIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFavoritedArticleIDs", reflect.TypeOf((*MockArticle)(nil).GetFavoritedArticleIDs), ctx, userID, articleIDs)
+}
+
+// GetFeedArticles mocks base method.
+func (m *MockArticle) GetFeedArticles(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeedArticles", ctx, userID, limit, offset)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeedArticles indicates an expected call of GetFeedArticles.
+func (mr *MockArticleMockRecorder) GetFeedArticles(ctx, userID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeedArticles", reflect.TypeOf((*MockArticle)(nil).GetFeedArticles), ctx, userID, limit, offset)
+}
+
+// GetNewestArticles mocks base method.
+func (m *MockArticle) GetNewestArticles(ctx context.Context, limit, offset int) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNewestArticles", ctx, limit, offset)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNewestArticles indicates an expected call of GetNewestArticles.
+func (mr *MockArticleMockRecorder) GetNewestArticles(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewestArticles", reflect.TypeOf((*MockArticle)(nil).GetNewestArticles), ctx, limit, offset)
+}
+
+// RemoveArticleFavorite mocks base method.
+func (m *MockArticle) RemoveArticleFavorite(ctx context.Context, userID, articleID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveArticleFavorite", ctx, userID, articleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveArticleFavorite indicates an expected call of RemoveArticleFavorite.
+func (mr *MockArticleMockRecorder) RemoveArticleFavorite(ctx, userID, articleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveArticleFavorite", reflect.TypeOf((*MockArticle)(nil).RemoveArticleFavorite), ctx, userID, articleID)
+}
+
+// RestoreArticleByID mocks base method.
+func (m *MockArticle) RestoreArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreArticleByID", ctx, articleID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreArticleByID indicates an expected call of RestoreArticleByID.
+func (mr *MockArticleMockRecorder) RestoreArticleByID(ctx, articleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreArticleByID", reflect.TypeOf((*MockArticle)(nil).RestoreArticleByID), ctx, articleID)
+}
+
+// SearchArticles mocks base method.
+func (m *MockArticle) SearchArticles(ctx context.Context, query string, limit, offset int) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchArticles", ctx, query, limit, offset)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchArticles indicates an expected call of SearchArticles.
+func (mr *MockArticleMockRecorder) SearchArticles(ctx, query, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchArticles", reflect.TypeOf((*MockArticle)(nil).SearchArticles), ctx, query, limit, offset)
+}
+
+// SetArticleFavorite mocks base method.
+func (m *MockArticle) SetArticleFavorite(ctx context.Context, userID, articleID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArticleFavorite", ctx, userID, articleID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetArticleFavorite indicates an expected call of SetArticleFavorite.
+func (mr *MockArticleMockRecorder) SetArticleFavorite(ctx, userID, articleID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticleFavorite", reflect.TypeOf((*MockArticle)(nil).SetArticleFavorite), ctx, userID, articleID)
+}
+
+// UpdateArticleByID mocks base method.
+func (m *MockArticle) UpdateArticleByID(ctx context.Context, articleID, editorID uuid.UUID, title, description, content *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateArticleByID", ctx, articleID, editorID, title, description, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateArticleByID indicates an expected call of UpdateArticleByID.
+func (mr *MockArticleMockRecorder) UpdateArticleByID(ctx, articleID, editorID, title, description, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateArticleByID", reflect.TypeOf((*MockArticle)(nil).UpdateArticleByID), ctx, articleID, editorID, title, description, content)
+}
+
+// MockComment is a mock of Comment interface.
+type MockComment struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentMockRecorder
+}
+
+// MockCommentMockRecorder is the mock recorder for MockComment.
+type MockCommentMockRecorder struct {
+	mock *MockComment
+}
+
+// NewMockComment creates a new mock instance.
+func NewMockComment(ctrl *gomock.Controller) *MockComment {
+	mock := &MockComment{ctrl: ctrl}
+	mock.recorder = &MockCommentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComment) EXPECT() *MockCommentMockRecorder {
+	return m.recorder
+}
+
+// CreateComment mocks base method.
+func (m *MockComment) CreateComment(ctx context.Context, comment entity.Comment) (entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", ctx, comment)
+	ret0, _ := ret[0].(entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateComment indicates an expected call of CreateComment.
+func (mr *MockCommentMockRecorder) CreateComment(ctx, comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockComment)(nil).CreateComment), ctx, comment)
+}
+
+// DeleteCommentByID mocks base method.
+func (m *MockComment) DeleteCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCommentByID", ctx, commentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCommentByID indicates an expected call of DeleteCommentByID.
+func (mr *MockCommentMockRecorder) DeleteCommentByID(ctx, commentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCommentByID", reflect.TypeOf((*MockComment)(nil).DeleteCommentByID), ctx, commentID)
+}
+
+// GetCommentByID mocks base method.
+func (m *MockComment) GetCommentByID(ctx context.Context, id uuid.UUID) (entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentByID", ctx, id)
+	ret0, _ := ret[0].(entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentByID indicates an expected call of GetCommentByID.
+func (mr *MockCommentMockRecorder) GetCommentByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentByID", reflect.TypeOf((*MockComment)(nil).GetCommentByID), ctx, id)
+}
+
+// GetCommentsByArticleID mocks base method.
+func (m *MockComment) GetCommentsByArticleID(ctx context.Context, articleID uuid.UUID, limit, offset int) ([]entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsByArticleID", ctx, articleID, limit, offset)
+	ret0, _ := ret[0].([]entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentsByArticleID indicates an expected call of GetCommentsByArticleID.
+func (mr *MockCommentMockRecorder) GetCommentsByArticleID(ctx, articleID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByArticleID", reflect.TypeOf((*MockComment)(nil).GetCommentsByArticleID), ctx, articleID, limit, offset)
+}
+
+// GetCommentsByAuthorID mocks base method.
+func (m *MockComment) GetCommentsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsByAuthorID", ctx, authorID)
+	ret0, _ := ret[0].([]entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentsByAuthorID indicates an expected call of GetCommentsByAuthorID.
+func (mr *MockCommentMockRecorder) GetCommentsByAuthorID(ctx, authorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByAuthorID", reflect.TypeOf((*MockComment)(nil).GetCommentsByAuthorID), ctx, authorID)
+}
+
+// GetFavoriteComments mocks base method.
+func (m *MockComment) GetFavoriteComments(ctx context.Context, userID uuid.UUID) ([]entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFavoriteComments", ctx, userID)
+	ret0, _ := ret[0].([]entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFavoriteComments indicates an expected call of GetFavoriteComments.
+func (mr *MockCommentMockRecorder) GetFavoriteComments(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFavoriteComments", reflect.TypeOf((*MockComment)(nil).GetFavoriteComments), ctx, userID)
+}
+
+// RestoreCommentByID mocks base method.
+func (m *MockComment) RestoreCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCommentByID", ctx, commentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreCommentByID indicates an expected call of RestoreCommentByID.
+func (mr *MockCommentMockRecorder) RestoreCommentByID(ctx, commentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCommentByID", reflect.TypeOf((*MockComment)(nil).RestoreCommentByID), ctx, commentID)
+}
+
+// UpdateCommentByID mocks base method.
+func (m *MockComment) UpdateCommentByID(ctx context.Context, commentID uuid.UUID, content string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCommentByID", ctx, commentID, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCommentByID indicates an expected call of UpdateCommentByID.
+func (mr *MockCommentMockRecorder) UpdateCommentByID(ctx, commentID, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCommentByID", reflect.TypeOf((*MockComment)(nil).UpdateCommentByID), ctx, commentID, content)
+}
+
+// MockNotification is a mock of Notification interface.
+type MockNotification struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationMockRecorder
+}
+
+// MockNotificationMockRecorder is the mock recorder for MockNotification.
+type MockNotificationMockRecorder struct {
+	mock *MockNotification
+}
+
+// NewMockNotification creates a new mock instance.
+func NewMockNotification(ctrl *gomock.Controller) *MockNotification {
+	mock := &MockNotification{ctrl: ctrl}
+	mock.recorder = &MockNotificationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotification) EXPECT() *MockNotificationMockRecorder {
+	return m.recorder
+}
+
+// CreateNotification mocks base method.
+func (m *MockNotification) CreateNotification(ctx context.Context, notification entity.Notification) (entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, notification)
+	ret0, _ := ret[0].(entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockNotificationMockRecorder) CreateNotification(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotification)(nil).CreateNotification), ctx, notification)
+}
+
+// GetNotificationsByUserID mocks base method.
+func (m *MockNotification) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationsByUserID", ctx, userID, limit, offset)
+	ret0, _ := ret[0].([]entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationsByUserID indicates an expected call of GetNotificationsByUserID.
+func (mr *MockNotificationMockRecorder) GetNotificationsByUserID(ctx, userID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationsByUserID", reflect.TypeOf((*MockNotification)(nil).GetNotificationsByUserID), ctx, userID, limit, offset)
+}
+
+// MockOutbox is a mock of Outbox interface.
+type MockOutbox struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxMockRecorder
+}
+
+// MockOutboxMockRecorder is the mock recorder for MockOutbox.
+type MockOutboxMockRecorder struct {
+	mock *MockOutbox
+}
+
+// NewMockOutbox creates a new mock instance.
+func NewMockOutbox(ctrl *gomock.Controller) *MockOutbox {
+	mock := &MockOutbox{ctrl: ctrl}
+	mock.recorder = &MockOutboxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutbox) EXPECT() *MockOutboxMockRecorder {
+	return m.recorder
+}
+
+// LockPendingEvents mocks base method.
+func (m *MockOutbox) LockPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockPendingEvents", ctx, limit, lease)
+	ret0, _ := ret[0].([]entity.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockPendingEvents indicates an expected call of LockPendingEvents.
+func (mr *MockOutboxMockRecorder) LockPendingEvents(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockPendingEvents", reflect.TypeOf((*MockOutbox)(nil).LockPendingEvents), ctx, limit, lease)
+}
+
+// MarkEventDead mocks base method.
+func (m *MockOutbox) MarkEventDead(ctx context.Context, id int64, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventDead", ctx, id, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventDead indicates an expected call of MarkEventDead.
+func (mr *MockOutboxMockRecorder) MarkEventDead(ctx, id, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventDead", reflect.TypeOf((*MockOutbox)(nil).MarkEventDead), ctx, id, lastError)
+}
+
+// MarkEventFailed mocks base method.
+func (m *MockOutbox) MarkEventFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventFailed", ctx, id, lastError, retryIn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventFailed indicates an expected call of MarkEventFailed.
+func (mr *MockOutboxMockRecorder) MarkEventFailed(ctx, id, lastError, retryIn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventFailed", reflect.TypeOf((*MockOutbox)(nil).MarkEventFailed), ctx, id, lastError, retryIn)
+}
+
+// MarkEventPublished mocks base method.
+func (m *MockOutbox) MarkEventPublished(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventPublished", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventPublished indicates an expected call of MarkEventPublished.
+func (mr *MockOutboxMockRecorder) MarkEventPublished(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventPublished", reflect.TypeOf((*MockOutbox)(nil).MarkEventPublished), ctx, id)
+}
+
+// MockWebhook is a mock of Webhook interface.
+type MockWebhook struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookMockRecorder
+}
+
+// MockWebhookMockRecorder is the mock recorder for MockWebhook.
+type MockWebhookMockRecorder struct {
+	mock *MockWebhook
+}
+
+// NewMockWebhook creates a new mock instance.
+func NewMockWebhook(ctrl *gomock.Controller) *MockWebhook {
+	mock := &MockWebhook{ctrl: ctrl}
+	mock.recorder = &MockWebhookMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhook) EXPECT() *MockWebhookMockRecorder {
+	return m.recorder
+}
+
+// CreateDeliveries mocks base method.
+func (m *MockWebhook) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveries", ctx, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveries indicates an expected call of CreateDeliveries.
+func (mr *MockWebhookMockRecorder) CreateDeliveries(ctx, deliveries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveries", reflect.TypeOf((*MockWebhook)(nil).CreateDeliveries), ctx, deliveries)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhook) CreateWebhook(ctx context.Context, webhook entity.Webhook) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookMockRecorder) CreateWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhook)(nil).CreateWebhook), ctx, webhook)
+}
+
+// DeleteWebhookByID mocks base method.
+func (m *MockWebhook) DeleteWebhookByID(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookByID", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookByID indicates an expected call of DeleteWebhookByID.
+func (mr *MockWebhookMockRecorder) DeleteWebhookByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookByID", reflect.TypeOf((*MockWebhook)(nil).DeleteWebhookByID), ctx, id)
+}
+
+// GetActiveWebhooksForEvent mocks base method.
+func (m *MockWebhook) GetActiveWebhooksForEvent(ctx context.Context, eventType entity.EventType, ownerIDs []uuid.UUID) ([]entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveWebhooksForEvent", ctx, eventType, ownerIDs)
+	ret0, _ := ret[0].([]entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveWebhooksForEvent indicates an expected call of GetActiveWebhooksForEvent.
+func (mr *MockWebhookMockRecorder) GetActiveWebhooksForEvent(ctx, eventType, ownerIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveWebhooksForEvent", reflect.TypeOf((*MockWebhook)(nil).GetActiveWebhooksForEvent), ctx, eventType, ownerIDs)
+}
+
+// GetDeliveriesByWebhookID mocks base method.
+func (m *MockWebhook) GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveriesByWebhookID", ctx, webhookID, limit, offset)
+	ret0, _ := ret[0].([]entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveriesByWebhookID indicates an expected call of GetDeliveriesByWebhookID.
+func (mr *MockWebhookMockRecorder) GetDeliveriesByWebhookID(ctx, webhookID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveriesByWebhookID", reflect.TypeOf((*MockWebhook)(nil).GetDeliveriesByWebhookID), ctx, webhookID, limit, offset)
+}
+
+// GetDeliveryByID mocks base method.
+func (m *MockWebhook) GetDeliveryByID(ctx context.Context, id uuid.UUID) (entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryByID", ctx, id)
+	ret0, _ := ret[0].(entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryByID indicates an expected call of GetDeliveryByID.
+func (mr *MockWebhookMockRecorder) GetDeliveryByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryByID", reflect.TypeOf((*MockWebhook)(nil).GetDeliveryByID), ctx, id)
+}
+
+// GetWebhookByID mocks base method.
+func (m *MockWebhook) GetWebhookByID(ctx context.Context, id uuid.UUID) (entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookByID", ctx, id)
+	ret0, _ := ret[0].(entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookByID indicates an expected call of GetWebhookByID.
+func (mr *MockWebhookMockRecorder) GetWebhookByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookByID", reflect.TypeOf((*MockWebhook)(nil).GetWebhookByID), ctx, id)
+}
+
+// GetWebhooksByOwnerID mocks base method.
+func (m *MockWebhook) GetWebhooksByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooksByOwnerID", ctx, ownerID)
+	ret0, _ := ret[0].([]entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooksByOwnerID indicates an expected call of GetWebhooksByOwnerID.
+func (mr *MockWebhookMockRecorder) GetWebhooksByOwnerID(ctx, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooksByOwnerID", reflect.TypeOf((*MockWebhook)(nil).GetWebhooksByOwnerID), ctx, ownerID)
+}
+
+// LockPendingDeliveries mocks base method.
+func (m *MockWebhook) LockPendingDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockPendingDeliveries", ctx, limit, lease)
+	ret0, _ := ret[0].([]entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockPendingDeliveries indicates an expected call of LockPendingDeliveries.
+func (mr *MockWebhookMockRecorder) LockPendingDeliveries(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockPendingDeliveries", reflect.TypeOf((*MockWebhook)(nil).LockPendingDeliveries), ctx, limit, lease)
+}
+
+// MarkDeliveryDead mocks base method.
+func (m *MockWebhook) MarkDeliveryDead(ctx context.Context, id uuid.UUID, statusCode *int, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeliveryDead", ctx, id, statusCode, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeliveryDead indicates an expected call of MarkDeliveryDead.
+func (mr *MockWebhookMockRecorder) MarkDeliveryDead(ctx, id, statusCode, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeliveryDead", reflect.TypeOf((*MockWebhook)(nil).MarkDeliveryDead), ctx, id, statusCode, lastError)
+}
+
+// MarkDeliveryDelivered mocks base method.
+func (m *MockWebhook) MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, statusCode int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeliveryDelivered", ctx, id, statusCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeliveryDelivered indicates an expected call of MarkDeliveryDelivered.
+func (mr *MockWebhookMockRecorder) MarkDeliveryDelivered(ctx, id, statusCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeliveryDelivered", reflect.TypeOf((*MockWebhook)(nil).MarkDeliveryDelivered), ctx, id, statusCode)
+}
+
+// MarkDeliveryFailed mocks base method.
+func (m *MockWebhook) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, statusCode *int, lastError string, retryIn time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeliveryFailed", ctx, id, statusCode, lastError, retryIn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeliveryFailed indicates an expected call of MarkDeliveryFailed.
+func (mr *MockWebhookMockRecorder) MarkDeliveryFailed(ctx, id, statusCode, lastError, retryIn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeliveryFailed", reflect.TypeOf((*MockWebhook)(nil).MarkDeliveryFailed), ctx, id, statusCode, lastError, retryIn)
+}
+
+// ResetDelivery mocks base method.
+func (m *MockWebhook) ResetDelivery(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetDelivery", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetDelivery indicates an expected call of ResetDelivery.
+func (mr *MockWebhookMockRecorder) ResetDelivery(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetDelivery", reflect.TypeOf((*MockWebhook)(nil).ResetDelivery), ctx, id)
+}
+
+// UpdateWebhookByID mocks base method.
+func (m *MockWebhook) UpdateWebhookByID(ctx context.Context, id uuid.UUID, url *string, eventTypes *[]entity.EventType, active *bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookByID", ctx, id, url, eventTypes, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookByID indicates an expected call of UpdateWebhookByID.
+func (mr *MockWebhookMockRecorder) UpdateWebhookByID(ctx, id, url, eventTypes, active any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookByID", reflect.TypeOf((*MockWebhook)(nil).UpdateWebhookByID), ctx, id, url, eventTypes, active)
+}
+
+// MockModeration is a mock of Moderation interface.
+type MockModeration struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationMockRecorder
+}
+
+// MockModerationMockRecorder is the mock recorder for MockModeration.
+type MockModerationMockRecorder struct {
+	mock *MockModeration
+}
+
+// NewMockModeration creates a new mock instance.
+func NewMockModeration(ctrl *gomock.Controller) *MockModeration {
+	mock := &MockModeration{ctrl: ctrl}
+	mock.recorder = &MockModerationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModeration) EXPECT() *MockModerationMockRecorder {
+	return m.recorder
+}
+
+// ClaimModerationCase mocks base method.
+func (m *MockModeration) ClaimModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimModerationCase", ctx, moderationCase, moderatorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimModerationCase indicates an expected call of ClaimModerationCase.
+func (mr *MockModerationMockRecorder) ClaimModerationCase(ctx, moderationCase, moderatorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimModerationCase", reflect.TypeOf((*MockModeration)(nil).ClaimModerationCase), ctx, moderationCase, moderatorID)
+}
+
+// CreateReport mocks base method.
+func (m *MockModeration) CreateReport(ctx context.Context, moderationCase entity.ModerationCase, report entity.Report) (entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReport", ctx, moderationCase, report)
+	ret0, _ := ret[0].(entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReport indicates an expected call of CreateReport.
+func (mr *MockModerationMockRecorder) CreateReport(ctx, moderationCase, report any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReport", reflect.TypeOf((*MockModeration)(nil).CreateReport), ctx, moderationCase, report)
+}
+
+// GetModerationCaseByID mocks base method.
+func (m *MockModeration) GetModerationCaseByID(ctx context.Context, id uuid.UUID) (entity.ModerationCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationCaseByID", ctx, id)
+	ret0, _ := ret[0].(entity.ModerationCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModerationCaseByID indicates an expected call of GetModerationCaseByID.
+func (mr *MockModerationMockRecorder) GetModerationCaseByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationCaseByID", reflect.TypeOf((*MockModeration)(nil).GetModerationCaseByID), ctx, id)
+}
+
+// GetModerationCases mocks base method.
+func (m *MockModeration) GetModerationCases(ctx context.Context, status *entity.ModerationStatus, targetType *entity.ReportTargetType, limit, offset int) ([]entity.ModerationCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationCases", ctx, status, targetType, limit, offset)
+	ret0, _ := ret[0].([]entity.ModerationCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModerationCases indicates an expected call of GetModerationCases.
+func (mr *MockModerationMockRecorder) GetModerationCases(ctx, status, targetType, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationCases", reflect.TypeOf((*MockModeration)(nil).GetModerationCases), ctx, status, targetType, limit, offset)
+}
+
+// GetModerationLog mocks base method.
+func (m *MockModeration) GetModerationLog(ctx context.Context, moderatorID, caseID uuid.NullUUID, limit, offset int) ([]entity.ModerationLogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationLog", ctx, moderatorID, caseID, limit, offset)
+	ret0, _ := ret[0].([]entity.ModerationLogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModerationLog indicates an expected call of GetModerationLog.
+func (mr *MockModerationMockRecorder) GetModerationLog(ctx, moderatorID, caseID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationLog", reflect.TypeOf((*MockModeration)(nil).GetModerationLog), ctx, moderatorID, caseID, limit, offset)
+}
+
+// GetReportsByCaseID mocks base method.
+func (m *MockModeration) GetReportsByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportsByCaseID", ctx, caseID)
+	ret0, _ := ret[0].([]entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsByCaseID indicates an expected call of GetReportsByCaseID.
+func (mr *MockModerationMockRecorder) GetReportsByCaseID(ctx, caseID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsByCaseID", reflect.TypeOf((*MockModeration)(nil).GetReportsByCaseID), ctx, caseID)
+}
+
+// ResolveModerationCase mocks base method.
+func (m *MockModeration) ResolveModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveModerationCase", ctx, moderationCase, moderatorID, action, note)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveModerationCase indicates an expected call of ResolveModerationCase.
+func (mr *MockModerationMockRecorder) ResolveModerationCase(ctx, moderationCase, moderatorID, action, note any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveModerationCase", reflect.TypeOf((*MockModeration)(nil).ResolveModerationCase), ctx, moderationCase, moderatorID, action, note)
+}
+
+// MockRetention is a mock of Retention interface.
+type MockRetention struct {
+	ctrl     *gomock.Controller
+	recorder *MockRetentionMockRecorder
+}
+
+// MockRetentionMockRecorder is the mock recorder for MockRetention.
+type MockRetentionMockRecorder struct {
+	mock *MockRetention
+}
+
+// NewMockRetention creates a new mock instance.
+func NewMockRetention(ctrl *gomock.Controller) *MockRetention {
+	mock := &MockRetention{ctrl: ctrl}
+	mock.recorder = &MockRetentionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRetention) EXPECT() *MockRetentionMockRecorder {
+	return m.recorder
+}
+
+// AnonymizeScheduledUsers mocks base method.
+func (m *MockRetention) AnonymizeScheduledUsers(ctx context.Context, limit int) (entity.Purged, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeScheduledUsers", ctx, limit)
+	ret0, _ := ret[0].(entity.Purged)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeScheduledUsers indicates an expected call of AnonymizeScheduledUsers.
+func (mr *MockRetentionMockRecorder) AnonymizeScheduledUsers(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeScheduledUsers", reflect.TypeOf((*MockRetention)(nil).AnonymizeScheduledUsers), ctx, limit)
+}
+
+// PurgeDeletedArticles mocks base method.
+func (m *MockRetention) PurgeDeletedArticles(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedArticles", ctx, olderThan, limit)
+	ret0, _ := ret[0].(entity.Purged)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedArticles indicates an expected call of PurgeDeletedArticles.
+func (mr *MockRetentionMockRecorder) PurgeDeletedArticles(ctx, olderThan, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedArticles", reflect.TypeOf((*MockRetention)(nil).PurgeDeletedArticles), ctx, olderThan, limit)
+}
+
+// PurgeDeletedComments mocks base method.
+func (m *MockRetention) PurgeDeletedComments(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedComments", ctx, olderThan, limit)
+	ret0, _ := ret[0].(entity.Purged)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedComments indicates an expected call of PurgeDeletedComments.
+func (mr *MockRetentionMockRecorder) PurgeDeletedComments(ctx, olderThan, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedComments", reflect.TypeOf((*MockRetention)(nil).PurgeDeletedComments), ctx, olderThan, limit)
+}
+
+// PurgeDeletedUsers mocks base method.
+func (m *MockRetention) PurgeDeletedUsers(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", ctx, olderThan, limit)
+	ret0, _ := ret[0].(entity.Purged)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers.
+func (mr *MockRetentionMockRecorder) PurgeDeletedUsers(ctx, olderThan, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockRetention)(nil).PurgeDeletedUsers), ctx, olderThan, limit)
+}
+
+// MockCounter is a mock of Counter interface.
+type MockCounter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCounterMockRecorder
+}
+
+// MockCounterMockRecorder is the mock recorder for MockCounter.
+type MockCounterMockRecorder struct {
+	mock *MockCounter
+}
+
+// NewMockCounter creates a new mock instance.
+func NewMockCounter(ctrl *gomock.Controller) *MockCounter {
+	mock := &MockCounter{ctrl: ctrl}
+	mock.recorder = &MockCounterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCounter) EXPECT() *MockCounterMockRecorder {
+	return m.recorder
+}
+
+// ReconcileCounters mocks base method.
+func (m *MockCounter) ReconcileCounters(ctx context.Context, fix bool) ([]entity.CounterDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileCounters", ctx, fix)
+	ret0, _ := ret[0].([]entity.CounterDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileCounters indicates an expected call of ReconcileCounters.
+func (mr *MockCounterMockRecorder) ReconcileCounters(ctx, fix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileCounters", reflect.TypeOf((*MockCounter)(nil).ReconcileCounters), ctx, fix)
+}
+
+// MockExport is a mock of Export interface.
+type MockExport struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportMockRecorder
+}
+
+// MockExportMockRecorder is the mock recorder for MockExport.
+type MockExportMockRecorder struct {
+	mock *MockExport
+}
+
+// NewMockExport creates a new mock instance.
+func NewMockExport(ctrl *gomock.Controller) *MockExport {
+	mock := &MockExport{ctrl: ctrl}
+	mock.recorder = &MockExportMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExport) EXPECT() *MockExportMockRecorder {
+	return m.recorder
+}
+
+// CreateExport mocks base method.
+func (m *MockExport) CreateExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExport", ctx, userID)
+	ret0, _ := ret[0].(entity.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExport indicates an expected call of CreateExport.
+func (mr *MockExportMockRecorder) CreateExport(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExport", reflect.TypeOf((*MockExport)(nil).CreateExport), ctx, userID)
+}
+
+// DeleteExpiredExports mocks base method.
+func (m *MockExport) DeleteExpiredExports(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredExports", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredExports indicates an expected call of DeleteExpiredExports.
+func (mr *MockExportMockRecorder) DeleteExpiredExports(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredExports", reflect.TypeOf((*MockExport)(nil).DeleteExpiredExports), ctx)
+}
+
+// GetExportArchive mocks base method.
+func (m *MockExport) GetExportArchive(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportArchive", ctx, id)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExportArchive indicates an expected call of GetExportArchive.
+func (mr *MockExportMockRecorder) GetExportArchive(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportArchive", reflect.TypeOf((*MockExport)(nil).GetExportArchive), ctx, id)
+}
+
+// GetExportByID mocks base method.
+func (m *MockExport) GetExportByID(ctx context.Context, id uuid.UUID) (entity.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportByID", ctx, id)
+	ret0, _ := ret[0].(entity.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExportByID indicates an expected call of GetExportByID.
+func (mr *MockExportMockRecorder) GetExportByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportByID", reflect.TypeOf((*MockExport)(nil).GetExportByID), ctx, id)
+}
+
+// GetPendingExport mocks base method.
+func (m *MockExport) GetPendingExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingExport", ctx, userID)
+	ret0, _ := ret[0].(entity.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingExport indicates an expected call of GetPendingExport.
+func (mr *MockExportMockRecorder) GetPendingExport(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingExport", reflect.TypeOf((*MockExport)(nil).GetPendingExport), ctx, userID)
+}
+
+// GetUserVotes mocks base method.
+func (m *MockExport) GetUserVotes(ctx context.Context, userID uuid.UUID) ([]entity.Vote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserVotes", ctx, userID)
+	ret0, _ := ret[0].([]entity.Vote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserVotes indicates an expected call of GetUserVotes.
+func (mr *MockExportMockRecorder) GetUserVotes(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserVotes", reflect.TypeOf((*MockExport)(nil).GetUserVotes), ctx, userID)
+}
+
+// LockPendingExports mocks base method.
+func (m *MockExport) LockPendingExports(ctx context.Context, limit int, lease time.Duration) ([]entity.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockPendingExports", ctx, limit, lease)
+	ret0, _ := ret[0].([]entity.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockPendingExports indicates an expected call of LockPendingExports.
+func (mr *MockExportMockRecorder) LockPendingExports(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockPendingExports", reflect.TypeOf((*MockExport)(nil).LockPendingExports), ctx, limit, lease)
+}
+
+// MarkExportFailed mocks base method.
+func (m *MockExport) MarkExportFailed(ctx context.Context, id uuid.UUID, lastError string, final bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkExportFailed", ctx, id, lastError, final)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkExportFailed indicates an expected call of MarkExportFailed.
+func (mr *MockExportMockRecorder) MarkExportFailed(ctx, id, lastError, final any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExportFailed", reflect.TypeOf((*MockExport)(nil).MarkExportFailed), ctx, id, lastError, final)
+}
+
+// MarkExportReady mocks base method.
+func (m *MockExport) MarkExportReady(ctx context.Context, id uuid.UUID, archive []byte, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkExportReady", ctx, id, archive, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkExportReady indicates an expected call of MarkExportReady.
+func (mr *MockExportMockRecorder) MarkExportReady(ctx, id, archive, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExportReady", reflect.TypeOf((*MockExport)(nil).MarkExportReady), ctx, id, archive, ttl)
+}
+
+// MockAdmin is a mock of Admin interface.
+type MockAdmin struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminMockRecorder
+}
+
+// MockAdminMockRecorder is the mock recorder for MockAdmin.
+type MockAdminMockRecorder struct {
+	mock *MockAdmin
+}
+
+// NewMockAdmin creates a new mock instance.
+func NewMockAdmin(ctrl *gomock.Controller) *MockAdmin {
+	mock := &MockAdmin{ctrl: ctrl}
+	mock.recorder = &MockAdminMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin) EXPECT() *MockAdminMockRecorder {
+	return m.recorder
+}
+
+// ResetUserPassword mocks base method.
+func (m *MockAdmin) ResetUserPassword(ctx context.Context, userID uuid.UUID, password string, entry entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetUserPassword", ctx, userID, password, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetUserPassword indicates an expected call of ResetUserPassword.
+func (mr *MockAdminMockRecorder) ResetUserPassword(ctx, userID, password, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetUserPassword", reflect.TypeOf((*MockAdmin)(nil).ResetUserPassword), ctx, userID, password, entry)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MockAdmin) RevokeUserSessions(ctx context.Context, userID uuid.UUID, entry entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, userID, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockAdminMockRecorder) RevokeUserSessions(ctx, userID, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockAdmin)(nil).RevokeUserSessions), ctx, userID, entry)
+}
+
+// SearchUsers mocks base method.
+func (m *MockAdmin) SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, filter)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAdminMockRecorder) SearchUsers(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAdmin)(nil).SearchUsers), ctx, filter)
+}
+
+// SetUserBanned mocks base method.
+func (m *MockAdmin) SetUserBanned(ctx context.Context, userID uuid.UUID, banned bool, entry entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserBanned", ctx, userID, banned, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserBanned indicates an expected call of SetUserBanned.
+func (mr *MockAdminMockRecorder) SetUserBanned(ctx, userID, banned, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserBanned", reflect.TypeOf((*MockAdmin)(nil).SetUserBanned), ctx, userID, banned, entry)
+}
+
+// SetUserRole mocks base method.
+func (m *MockAdmin) SetUserRole(ctx context.Context, userID uuid.UUID, role entity.RoleType, entry entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, userID, role, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockAdminMockRecorder) SetUserRole(ctx, userID, role, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockAdmin)(nil).SetUserRole), ctx, userID, role, entry)
+}
+
+// MockAudit is a mock of Audit interface.
+type MockAudit struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditMockRecorder
+}
+
+// MockAuditMockRecorder is the mock recorder for MockAudit.
+type MockAuditMockRecorder struct {
+	mock *MockAudit
+}
+
+// NewMockAudit creates a new mock instance.
+func NewMockAudit(ctrl *gomock.Controller) *MockAudit {
+	mock := &MockAudit{ctrl: ctrl}
+	mock.recorder = &MockAuditMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudit) EXPECT() *MockAuditMockRecorder {
+	return m.recorder
+}
+
+// CreateAuditEntry mocks base method.
+func (m *MockAudit) CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEntry indicates an expected call of CreateAuditEntry.
+func (mr *MockAuditMockRecorder) CreateAuditEntry(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEntry", reflect.TypeOf((*MockAudit)(nil).CreateAuditEntry), ctx, entry)
+}
+
+// ExportAuditLog mocks base method.
+func (m *MockAudit) ExportAuditLog(ctx context.Context, filter entity.AuditFilter, fn func(entity.AuditEntry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportAuditLog", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportAuditLog indicates an expected call of ExportAuditLog.
+func (mr *MockAuditMockRecorder) ExportAuditLog(ctx, filter, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportAuditLog", reflect.TypeOf((*MockAudit)(nil).ExportAuditLog), ctx, filter, fn)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockAudit) GetAuditLog(ctx context.Context, filter entity.AuditFilter, limit, offset int) ([]entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", ctx, filter, limit, offset)
+	ret0, _ := ret[0].([]entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockAuditMockRecorder) GetAuditLog(ctx, filter, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockAudit)(nil).GetAuditLog), ctx, filter, limit, offset)
+}
@@ -0,0 +1,175 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// AdminRepo - изменения пользователей администратором, каждое изменение записывается в audit_log в той же транзакции
+type AdminRepo struct {
+	*postgres.Postgres
+}
+
+func NewAdminRepo(pg *postgres.Postgres) *AdminRepo {
+	return &AdminRepo{pg}
+}
+
+func (r *AdminRepo) SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error) {
+	sqlBuilder := r.Builder.
+		Select(userColumns...).
+		From("users").
+		Where("id <> ?", entity.TombstoneUserID)
+
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		sqlBuilder = sqlBuilder.Where(squirrel.Or{
+			squirrel.ILike{"username": pattern},
+			squirrel.ILike{"name": pattern},
+			squirrel.ILike{"email": pattern},
+		})
+	}
+	if filter.Role != nil {
+		sqlBuilder = sqlBuilder.Where("role = ?", *filter.Role)
+	}
+	if filter.Banned != nil {
+		if *filter.Banned {
+			sqlBuilder = sqlBuilder.Where("banned_at IS NOT NULL")
+		} else {
+			sqlBuilder = sqlBuilder.Where("banned_at IS NULL")
+		}
+	}
+	if filter.Deleted {
+		sqlBuilder = sqlBuilder.Where("deleted_at IS NOT NULL")
+	} else {
+		sqlBuilder = sqlBuilder.Where("deleted_at IS NULL")
+	}
+
+	sql, args, _ := sqlBuilder.
+		OrderBy("created_at DESC").
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("AdminRepo.SearchUsers - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("AdminRepo.SearchUsers - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var users []entity.User
+	for rows.Next() {
+		var user entity.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Username,
+			&user.Password,
+			&user.Email,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Role,
+			&user.Description,
+			&user.ArticlesCount,
+			&user.CommentsCount,
+			&user.FavoritesArticlesCount,
+			&user.FavoritesCommentsCount,
+			&user.FollowersCount,
+			&user.FollowingCount,
+			&user.BannedAt,
+			&user.DeletionScheduledAt,
+			&user.SessionsRevokedAt,
+			&user.PasswordResetRequired,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("AdminRepo.SearchUsers - rows.Scan: %v", err)
+			return nil, fmt.Errorf("AdminRepo.SearchUsers - rows.Scan: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (r *AdminRepo) SetUserRole(ctx context.Context, userID uuid.UUID, role entity.RoleType, entry entity.AuditEntry) error {
+	return r.updateUser(ctx, "AdminRepo.SetUserRole", userID, map[string]interface{}{
+		"role":       role,
+		"updated_at": squirrel.Expr("NOW()"),
+	}, entry)
+}
+
+// SetUserBanned - бан также отзывает все сессии пользователя
+func (r *AdminRepo) SetUserBanned(ctx context.Context, userID uuid.UUID, banned bool, entry entity.AuditEntry) error {
+	values := map[string]interface{}{
+		"banned_at": nil,
+	}
+	if banned {
+		values["banned_at"] = squirrel.Expr("NOW()")
+		values["sessions_revoked_at"] = squirrel.Expr("NOW()")
+	}
+
+	return r.updateUser(ctx, "AdminRepo.SetUserBanned", userID, values, entry)
+}
+
+// ResetUserPassword - пароль заменяется временным, который пользователь обязан сменить, сессии отзываются
+func (r *AdminRepo) ResetUserPassword(ctx context.Context, userID uuid.UUID, password string, entry entity.AuditEntry) error {
+	return r.updateUser(ctx, "AdminRepo.ResetUserPassword", userID, map[string]interface{}{
+		"password":                password,
+		"password_reset_required": true,
+		"sessions_revoked_at":     squirrel.Expr("NOW()"),
+		"updated_at":              squirrel.Expr("NOW()"),
+	}, entry)
+}
+
+func (r *AdminRepo) RevokeUserSessions(ctx context.Context, userID uuid.UUID, entry entity.AuditEntry) error {
+	return r.updateUser(ctx, "AdminRepo.RevokeUserSessions", userID, map[string]interface{}{
+		"sessions_revoked_at": squirrel.Expr("NOW()"),
+	}, entry)
+}
+
+func (r *AdminRepo) updateUser(ctx context.Context, method string, userID uuid.UUID, values map[string]interface{}, entry entity.AuditEntry) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - r.Begin: %v", method, err)
+		return fmt.Errorf("%s - r.Begin: %w", method, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Update("users").
+		SetMap(values).
+		Where("id = ?", userID).
+		Where("deleted_at IS NULL").
+		ToSql()
+
+	res, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - tx.Exec: %v", method, err)
+		return fmt.Errorf("%s - tx.Exec: %w", method, err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrUserNotFound
+	}
+
+	err = insertAuditEntry(ctx, tx, r.Builder, entry)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - insertAuditEntry: %v", method, err)
+		return fmt.Errorf("%s - insertAuditEntry: %w", method, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - tx.Commit: %v", method, err)
+		return fmt.Errorf("%s - tx.Commit: %w", method, err)
+	}
+
+	return nil
+}
@@ -2,11 +2,22 @@ package pgdb
 
 import (
 	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
 	"blog-backend/pkg/postgres"
 	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 )
 
+var articleColumns = []string{
+	"id", "author_id", "title", "description", "content", "created_at", "updated_at", "views_count",
+	"comments_count", "favorites_count", "votes_up_count", "votes_down_count", "hidden_at",
+}
+
 type ArticleRepo struct {
 	*postgres.Postgres
 }
@@ -16,6 +27,13 @@ func NewArticleRepo(pg *postgres.Postgres) *ArticleRepo {
 }
 
 func (a ArticleRepo) CreateArticle(ctx context.Context, article entity.Article) (uuid.UUID, error) {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.CreateArticle - a.Begin: %v", err)
+		return uuid.UUID{}, fmt.Errorf("ArticleRepo.CreateArticle - a.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	sql, args, _ := a.Builder.
 		Insert("articles").
 		Columns("author_id", "title", "description", "content").
@@ -24,9 +42,33 @@ func (a ArticleRepo) CreateArticle(ctx context.Context, article entity.Article)
 		ToSql()
 
 	var id uuid.UUID
-	err := a.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.CreateArticle - tx.QueryRow: %v", err)
+		return uuid.UUID{}, fmt.Errorf("ArticleRepo.CreateArticle - tx.QueryRow: %w", err)
+	}
+
+	err = changeCounter(ctx, tx, a.Builder, "users", "articles_count", article.AuthorID, 1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.CreateArticle - changeCounter: %v", err)
+		return uuid.UUID{}, fmt.Errorf("ArticleRepo.CreateArticle - changeCounter: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, a.Builder, entity.EventArticleCreated, id, entity.ArticleCreatedPayload{
+		ArticleID:   id,
+		AuthorID:    article.AuthorID,
+		Title:       article.Title,
+		Description: article.Description,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.CreateArticle - insertEvent: %v", err)
+		return uuid.UUID{}, fmt.Errorf("ArticleRepo.CreateArticle - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
-		return uuid.UUID{}, err
+		logger.FromContext(ctx).Errorf("ArticleRepo.CreateArticle - tx.Commit: %v", err)
+		return uuid.UUID{}, fmt.Errorf("ArticleRepo.CreateArticle - tx.Commit: %w", err)
 	}
 
 	return id, nil
@@ -34,13 +76,14 @@ func (a ArticleRepo) CreateArticle(ctx context.Context, article entity.Article)
 
 func (a ArticleRepo) GetArticleByID(ctx context.Context, id uuid.UUID) (entity.Article, error) {
 	sql, args, _ := a.Builder.
-		Select("*").
+		Select(articleColumns...).
 		From("articles").
 		Where("id = ?", id).
+		Where("deleted_at IS NULL").
 		ToSql()
 
 	var article entity.Article
-	err := a.Pool.QueryRow(ctx, sql, args...).Scan(
+	err := a.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
 		&article.Id,
 		&article.AuthorID,
 		&article.Title,
@@ -53,9 +96,14 @@ func (a ArticleRepo) GetArticleByID(ctx context.Context, id uuid.UUID) (entity.A
 		&article.FavoritesCount,
 		&article.VotesUpCount,
 		&article.VotesDownCount,
+		&article.HiddenAt,
 	)
 	if err != nil {
-		return entity.Article{}, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Article{}, repoerrs.ErrArticleNotFound
+		}
+		logger.FromContext(ctx).Errorf("ArticleRepo.GetArticleByID - a.Reader.QueryRow: %v", err)
+		return entity.Article{}, fmt.Errorf("ArticleRepo.GetArticleByID - a.Reader.QueryRow: %w", err)
 	}
 
 	return article, nil
@@ -63,14 +111,17 @@ func (a ArticleRepo) GetArticleByID(ctx context.Context, id uuid.UUID) (entity.A
 
 func (a ArticleRepo) GetArticlesByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Article, error) {
 	sql, args, _ := a.Builder.
-		Select("*").
+		Select(articleColumns...).
 		From("articles").
 		Where("author_id = ?", authorID).
+		Where("hidden_at IS NULL").
+		Where("deleted_at IS NULL").
 		ToSql()
 
-	rows, err := a.Pool.Query(ctx, sql, args...)
+	rows, err := a.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		return nil, err
+		logger.FromContext(ctx).Errorf("ArticleRepo.GetArticlesByAuthorID - a.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ArticleRepo.GetArticlesByAuthorID - a.Reader.Query: %w", err)
 	}
 	defer rows.Close()
 
@@ -90,9 +141,11 @@ func (a ArticleRepo) GetArticlesByAuthorID(ctx context.Context, authorID uuid.UU
 			&article.FavoritesCount,
 			&article.VotesUpCount,
 			&article.VotesDownCount,
+			&article.HiddenAt,
 		)
 		if err != nil {
-			return nil, err
+			logger.FromContext(ctx).Errorf("ArticleRepo.GetArticlesByAuthorID - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ArticleRepo.GetArticlesByAuthorID - rows.Scan: %w", err)
 		}
 
 		articles = append(articles, article)
@@ -103,16 +156,19 @@ func (a ArticleRepo) GetArticlesByAuthorID(ctx context.Context, authorID uuid.UU
 
 func (a ArticleRepo) GetNewestArticles(ctx context.Context, limit, offset int) ([]entity.Article, error) {
 	sql, args, _ := a.Builder.
-		Select("*").
+		Select(articleColumns...).
 		From("articles").
+		Where("hidden_at IS NULL").
+		Where("deleted_at IS NULL").
 		OrderBy("created_at DESC").
 		Limit(uint64(limit)).
 		Offset(uint64(offset)).
 		ToSql()
 
-	rows, err := a.Pool.Query(ctx, sql, args...)
+	rows, err := a.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		return nil, err
+		logger.FromContext(ctx).Errorf("ArticleRepo.GetNewestArticles - a.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ArticleRepo.GetNewestArticles - a.Reader.Query: %w", err)
 	}
 	defer rows.Close()
 
@@ -132,9 +188,11 @@ func (a ArticleRepo) GetNewestArticles(ctx context.Context, limit, offset int) (
 			&article.FavoritesCount,
 			&article.VotesUpCount,
 			&article.VotesDownCount,
+			&article.HiddenAt,
 		)
 		if err != nil {
-			return nil, err
+			logger.FromContext(ctx).Errorf("ArticleRepo.GetNewestArticles - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ArticleRepo.GetNewestArticles - rows.Scan: %w", err)
 		}
 
 		articles = append(articles, article)
@@ -143,31 +201,111 @@ func (a ArticleRepo) GetNewestArticles(ctx context.Context, limit, offset int) (
 	return articles, nil
 }
 
-func (a ArticleRepo) SetArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) error {
+// SetArticleFavorite - повторное добавление не вставляется благодаря уникальной паре, счетчики и событие не меняются
+func (a ArticleRepo) SetArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) (bool, error) {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.SetArticleFavorite - a.Begin: %v", err)
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - a.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	sql, args, _ := a.Builder.
 		Insert("users_articles_favorites").
 		Columns("user_id", "article_id").
 		Values(userID, articleID).
+		Suffix("ON CONFLICT (user_id, article_id) DO NOTHING").
 		ToSql()
 
-	_, err := a.Pool.Exec(ctx, sql, args...)
+	tag, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
-		return err
+		logger.FromContext(ctx).Errorf("ArticleRepo.SetArticleFavorite - tx.Exec: %v", err)
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - tx.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
 	}
 
-	return nil
+	err = changeCounter(ctx, tx, a.Builder, "articles", "favorites_count", articleID, 1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.SetArticleFavorite - changeCounter: %v", err)
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - changeCounter: %w", err)
+	}
+
+	err = changeCounter(ctx, tx, a.Builder, "users", "favorites_articles_count", userID, 1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.SetArticleFavorite - changeCounter: %v", err)
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - changeCounter: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, a.Builder, entity.EventArticleFavorited, articleID, entity.ArticleFavoritedPayload{
+		ArticleID: articleID,
+		UserID:    userID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.SetArticleFavorite - insertEvent: %v", err)
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.SetArticleFavorite - tx.Commit: %v", err)
+		return false, fmt.Errorf("ArticleRepo.SetArticleFavorite - tx.Commit: %w", err)
+	}
+
+	return true, nil
 }
 
 func (a ArticleRepo) RemoveArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) error {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RemoveArticleFavorite - a.Begin: %v", err)
+		return fmt.Errorf("ArticleRepo.RemoveArticleFavorite - a.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	sql, args, _ := a.Builder.
 		Delete("users_articles_favorites").
 		Where("user_id = ?", userID).
 		Where("article_id = ?", articleID).
 		ToSql()
 
-	_, err := a.Pool.Exec(ctx, sql, args...)
+	res, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
-		return err
+		logger.FromContext(ctx).Errorf("ArticleRepo.RemoveArticleFavorite - tx.Exec: %v", err)
+		return fmt.Errorf("ArticleRepo.RemoveArticleFavorite - tx.Exec: %w", err)
+	}
+
+	// nothing was removed, nothing has happened
+	if res.RowsAffected() == 0 {
+		return nil
+	}
+
+	err = changeCounter(ctx, tx, a.Builder, "articles", "favorites_count", articleID, -1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RemoveArticleFavorite - changeCounter: %v", err)
+		return fmt.Errorf("ArticleRepo.RemoveArticleFavorite - changeCounter: %w", err)
+	}
+
+	err = changeCounter(ctx, tx, a.Builder, "users", "favorites_articles_count", userID, -1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RemoveArticleFavorite - changeCounter: %v", err)
+		return fmt.Errorf("ArticleRepo.RemoveArticleFavorite - changeCounter: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, a.Builder, entity.EventArticleUnfavorited, articleID, entity.ArticleFavoritedPayload{
+		ArticleID: articleID,
+		UserID:    userID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RemoveArticleFavorite - insertEvent: %v", err)
+		return fmt.Errorf("ArticleRepo.RemoveArticleFavorite - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RemoveArticleFavorite - tx.Commit: %v", err)
+		return fmt.Errorf("ArticleRepo.RemoveArticleFavorite - tx.Commit: %w", err)
 	}
 
 	return nil
@@ -175,15 +313,156 @@ func (a ArticleRepo) RemoveArticleFavorite(ctx context.Context, userID uuid.UUID
 
 func (a ArticleRepo) GetFavoriteArticles(ctx context.Context, userID uuid.UUID) ([]entity.Article, error) {
 	sql, args, _ := a.Builder.
-		Select("a.*").
+		Select(prefixColumns("a", articleColumns)...).
 		From("users_articles_favorites uf").
 		Join("articles a ON a.id = uf.article_id").
 		Where("uf.user_id = ?", userID).
+		Where("a.hidden_at IS NULL").
+		Where("a.deleted_at IS NULL").
+		ToSql()
+
+	rows, err := a.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.GetFavoriteArticles - a.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ArticleRepo.GetFavoriteArticles - a.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var articles []entity.Article
+	for rows.Next() {
+		var article entity.Article
+		err := rows.Scan(
+			&article.Id,
+			&article.AuthorID,
+			&article.Title,
+			&article.Description,
+			&article.Content,
+			&article.CreatedAt,
+			&article.UpdatedAt,
+			&article.ViewsCount,
+			&article.CommentsCount,
+			&article.FavoritesCount,
+			&article.VotesUpCount,
+			&article.VotesDownCount,
+			&article.HiddenAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ArticleRepo.GetFavoriteArticles - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ArticleRepo.GetFavoriteArticles - rows.Scan: %w", err)
+		}
+
+		articles = append(articles, article)
+	}
+
+	return articles, nil
+}
+
+// GetFavoritedArticleIDs - какие из статей в избранном пользователя, одним запросом на всю страницу статей
+func (a ArticleRepo) GetFavoritedArticleIDs(ctx context.Context, userID uuid.UUID, articleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(articleIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, _ := a.Builder.
+		Select("article_id").
+		From("users_articles_favorites").
+		Where("user_id = ?", userID).
+		Where(squirrel.Eq{"article_id": articleIDs}).
+		ToSql()
+
+	rows, err := a.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.GetFavoritedArticleIDs - a.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ArticleRepo.GetFavoritedArticleIDs - a.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err := rows.Scan(&id)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ArticleRepo.GetFavoritedArticleIDs - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ArticleRepo.GetFavoritedArticleIDs - rows.Scan: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetFeedArticles - статьи авторов, на которых подписан пользователь, сначала новые
+func (a ArticleRepo) GetFeedArticles(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Article, error) {
+	sql, args, _ := a.Builder.
+		Select(articleColumns...).
+		From("articles").
+		Where("author_id IN (SELECT following_id FROM users_followers WHERE follower_id = ?)", userID).
+		Where("hidden_at IS NULL").
+		Where("deleted_at IS NULL").
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := a.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.GetFeedArticles - a.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ArticleRepo.GetFeedArticles - a.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var articles []entity.Article
+	for rows.Next() {
+		var article entity.Article
+		err := rows.Scan(
+			&article.Id,
+			&article.AuthorID,
+			&article.Title,
+			&article.Description,
+			&article.Content,
+			&article.CreatedAt,
+			&article.UpdatedAt,
+			&article.ViewsCount,
+			&article.CommentsCount,
+			&article.FavoritesCount,
+			&article.VotesUpCount,
+			&article.VotesDownCount,
+			&article.HiddenAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ArticleRepo.GetFeedArticles - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ArticleRepo.GetFeedArticles - rows.Scan: %w", err)
+		}
+
+		articles = append(articles, article)
+	}
+
+	return articles, nil
+}
+
+// SearchArticles - поиск по вхождению в заголовок или описание без учета регистра, сначала новые
+func (a ArticleRepo) SearchArticles(ctx context.Context, query string, limit, offset int) ([]entity.Article, error) {
+	pattern := "%" + escapeLike(query) + "%"
+
+	sql, args, _ := a.Builder.
+		Select(articleColumns...).
+		From("articles").
+		Where(squirrel.Or{
+			squirrel.ILike{"title": pattern},
+			squirrel.ILike{"description": pattern},
+		}).
+		Where("hidden_at IS NULL").
+		Where("deleted_at IS NULL").
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
 		ToSql()
 
-	rows, err := a.Pool.Query(ctx, sql, args...)
+	rows, err := a.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		return nil, err
+		logger.FromContext(ctx).Errorf("ArticleRepo.SearchArticles - a.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ArticleRepo.SearchArticles - a.Reader.Query: %w", err)
 	}
 	defer rows.Close()
 
@@ -203,9 +482,11 @@ func (a ArticleRepo) GetFavoriteArticles(ctx context.Context, userID uuid.UUID)
 			&article.FavoritesCount,
 			&article.VotesUpCount,
 			&article.VotesDownCount,
+			&article.HiddenAt,
 		)
 		if err != nil {
-			return nil, err
+			logger.FromContext(ctx).Errorf("ArticleRepo.SearchArticles - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ArticleRepo.SearchArticles - rows.Scan: %w", err)
 		}
 
 		articles = append(articles, article)
@@ -213,3 +494,167 @@ func (a ArticleRepo) GetFavoriteArticles(ctx context.Context, userID uuid.UUID)
 
 	return articles, nil
 }
+
+// GetArticlesTags - теги статей по id статьи, статьи без тегов в результат не попадают
+func (a ArticleRepo) GetArticlesTags(ctx context.Context, articleIDs []uuid.UUID) (map[uuid.UUID][]entity.Tag, error) {
+	if len(articleIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, _ := a.Builder.
+		Select("at.article_id", "t.id", "t.description").
+		From("articles_tags at").
+		Join("tags t ON t.id = at.tag_id").
+		Where(squirrel.Eq{"at.article_id": articleIDs}).
+		OrderBy("t.description").
+		ToSql()
+
+	rows, err := a.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.GetArticlesTags - a.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ArticleRepo.GetArticlesTags - a.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[uuid.UUID][]entity.Tag)
+	for rows.Next() {
+		var (
+			articleID uuid.UUID
+			tag       entity.Tag
+		)
+		err := rows.Scan(&articleID, &tag.Id, &tag.Description)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ArticleRepo.GetArticlesTags - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ArticleRepo.GetArticlesTags - rows.Scan: %w", err)
+		}
+
+		tags[articleID] = append(tags[articleID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (a ArticleRepo) UpdateArticleByID(ctx context.Context, articleID, editorID uuid.UUID, title, description, content *string) error {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.UpdateArticleByID - a.Begin: %v", err)
+		return fmt.Errorf("ArticleRepo.UpdateArticleByID - a.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sqlBuilder := a.Builder.
+		Update("articles").
+		Set("updated_at", squirrel.Expr("NOW()"))
+
+	if title != nil {
+		sqlBuilder = sqlBuilder.Set("title", *title)
+	}
+
+	if description != nil {
+		sqlBuilder = sqlBuilder.Set("description", *description)
+	}
+
+	if content != nil {
+		sqlBuilder = sqlBuilder.Set("content", *content)
+	}
+
+	sql, args, _ := sqlBuilder.
+		Where("id = ?", articleID).
+		Where("deleted_at IS NULL").
+		Suffix("RETURNING author_id, title, description").
+		ToSql()
+
+	payload := entity.ArticleUpdatedPayload{
+		ArticleID: articleID,
+		EditorID:  editorID,
+	}
+	err = tx.QueryRow(ctx, sql, args...).Scan(&payload.AuthorID, &payload.Title, &payload.Description)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrArticleNotFound
+		}
+		logger.FromContext(ctx).Errorf("ArticleRepo.UpdateArticleByID - tx.QueryRow: %v", err)
+		return fmt.Errorf("ArticleRepo.UpdateArticleByID - tx.QueryRow: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, a.Builder, entity.EventArticleUpdated, articleID, payload)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.UpdateArticleByID - insertEvent: %v", err)
+		return fmt.Errorf("ArticleRepo.UpdateArticleByID - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.UpdateArticleByID - tx.Commit: %v", err)
+		return fmt.Errorf("ArticleRepo.UpdateArticleByID - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteArticleByID - мягкое удаление, статья удаляется из базы задачей очистки после срока хранения
+func (a ArticleRepo) DeleteArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.DeleteArticleByID - a.Begin: %v", err)
+		return fmt.Errorf("ArticleRepo.DeleteArticleByID - a.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	update := a.Builder.
+		Update("articles").
+		Set("deleted_at", squirrel.Expr("NOW()")).
+		Where("id = ?", articleID).
+		Where("deleted_at IS NULL")
+
+	n, err := updateArticles(ctx, tx, a.Builder, update, entity.EventArticleDeleted)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.DeleteArticleByID - updateArticles: %v", err)
+		return fmt.Errorf("ArticleRepo.DeleteArticleByID - updateArticles: %w", err)
+	}
+
+	if n == 0 {
+		return repoerrs.ErrArticleNotFound
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.DeleteArticleByID - tx.Commit: %v", err)
+		return fmt.Errorf("ArticleRepo.DeleteArticleByID - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (a ArticleRepo) RestoreArticleByID(ctx context.Context, articleID uuid.UUID) error {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RestoreArticleByID - a.Begin: %v", err)
+		return fmt.Errorf("ArticleRepo.RestoreArticleByID - a.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	update := a.Builder.
+		Update("articles").
+		Set("deleted_at", nil).
+		Where("id = ?", articleID).
+		Where("deleted_at IS NOT NULL")
+
+	n, err := updateArticles(ctx, tx, a.Builder, update, entity.EventArticleRestored)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RestoreArticleByID - updateArticles: %v", err)
+		return fmt.Errorf("ArticleRepo.RestoreArticleByID - updateArticles: %w", err)
+	}
+
+	if n == 0 {
+		return repoerrs.ErrArticleNotFound
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ArticleRepo.RestoreArticleByID - tx.Commit: %v", err)
+		return fmt.Errorf("ArticleRepo.RestoreArticleByID - tx.Commit: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+//go:build integration
+
+package pgdb_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/pgdb"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/testutil/pgtest"
+	"context"
+	"github.com/google/uuid"
+	"sort"
+	"testing"
+)
+
+func TestArticleRepo_CreateArticle(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	r := pgdb.NewArticleRepo(pg)
+	ctx := context.Background()
+
+	id, err := r.CreateArticle(ctx, entity.Article{
+		AuthorID:    pgtest.BobbyID,
+		Title:       "Little Bobby Tables",
+		Description: "we call him",
+		Content:     "Did you really name your son that?",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article, err := r.GetArticleByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.AuthorID != pgtest.BobbyID || article.Title != "Little Bobby Tables" {
+		t.Errorf("created article = %+v", article)
+	}
+	if got := countEvents(t, pg, entity.EventArticleCreated, id); got != 1 {
+		t.Errorf("got %d article.created events, want 1", got)
+	}
+
+	_, err = r.CreateArticle(ctx, entity.Article{AuthorID: uuid.New(), Title: "t", Description: "d", Content: "c"})
+	if err == nil {
+		t.Error("article of an unknown author is created")
+	}
+}
+
+func TestArticleRepo_GetArticleByID(t *testing.T) {
+	r := pgdb.NewArticleRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		id   uuid.UUID
+		want error
+	}{
+		{name: "ok", id: pgtest.AliceFirstArticleID},
+		// hidden articles are shown by id, the use case decides who can see them
+		{name: "hidden", id: pgtest.BobbyHiddenArticleID},
+		{name: "deleted", id: pgtest.CarolArticleID, want: repoerrs.ErrArticleNotFound},
+		{name: "unknown", id: uuid.New(), want: repoerrs.ErrArticleNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article, err := r.GetArticleByID(ctx, tt.id)
+			if err != tt.want {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if err == nil && article.Id != tt.id {
+				t.Errorf("article id = %s, want %s", article.Id, tt.id)
+			}
+		})
+	}
+}
+
+func TestArticleRepo_Lists(t *testing.T) {
+	r := pgdb.NewArticleRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		list    func() ([]entity.Article, error)
+		want    []uuid.UUID
+		ordered bool
+	}{
+		{
+			name: "by author",
+			list: func() ([]entity.Article, error) { return r.GetArticlesByAuthorID(ctx, pgtest.AliceID) },
+			want: []uuid.UUID{pgtest.AliceFirstArticleID, pgtest.AliceSecondArticleID},
+		},
+		{
+			name: "by author, hidden are skipped",
+			list: func() ([]entity.Article, error) { return r.GetArticlesByAuthorID(ctx, pgtest.BobbyID) },
+		},
+		{
+			name: "by author, deleted are skipped",
+			list: func() ([]entity.Article, error) { return r.GetArticlesByAuthorID(ctx, pgtest.CarolID) },
+		},
+		{
+			name:    "newest",
+			list:    func() ([]entity.Article, error) { return r.GetNewestArticles(ctx, 10, 0) },
+			want:    []uuid.UUID{pgtest.AliceSecondArticleID, pgtest.AliceFirstArticleID},
+			ordered: true,
+		},
+		{
+			name:    "newest, second page",
+			list:    func() ([]entity.Article, error) { return r.GetNewestArticles(ctx, 1, 1) },
+			want:    []uuid.UUID{pgtest.AliceFirstArticleID},
+			ordered: true,
+		},
+		{
+			name: "favorites",
+			list: func() ([]entity.Article, error) { return r.GetFavoriteArticles(ctx, pgtest.BobbyID) },
+			want: []uuid.UUID{pgtest.AliceFirstArticleID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, err := tt.list()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]uuid.UUID, 0, len(articles))
+			for _, article := range articles {
+				got = append(got, article.Id)
+			}
+			if !tt.ordered {
+				sortIDs(got)
+				sortIDs(tt.want)
+			}
+			if !sameOrder(got, tt.want) {
+				t.Errorf("articles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArticleRepo_Favorites(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	r := pgdb.NewArticleRepo(pg)
+	ctx := context.Background()
+
+	_, err := r.SetArticleFavorite(ctx, pgtest.AliceID, pgtest.AliceSecondArticleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pair is unique, a repeated favorite is not inserted
+	inserted, err := r.SetArticleFavorite(ctx, pgtest.AliceID, pgtest.AliceSecondArticleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted {
+		t.Error("existing favorite is inserted again")
+	}
+
+	favorites, err := r.GetFavoriteArticles(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites) != 1 || favorites[0].Id != pgtest.AliceSecondArticleID {
+		t.Errorf("favorites after set = %+v", favorites)
+	}
+
+	err = r.RemoveArticleFavorite(ctx, pgtest.AliceID, pgtest.AliceSecondArticleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// removing what is not there is not an error and is not an event
+	err = r.RemoveArticleFavorite(ctx, pgtest.AliceID, pgtest.AliceSecondArticleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	favorites, err = r.GetFavoriteArticles(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites) != 0 {
+		t.Errorf("favorites after remove = %+v", favorites)
+	}
+
+	if got := countEvents(t, pg, entity.EventArticleFavorited, pgtest.AliceSecondArticleID); got != 1 {
+		t.Errorf("got %d article.favorited events, want 1", got)
+	}
+	if got := countEvents(t, pg, entity.EventArticleUnfavorited, pgtest.AliceSecondArticleID); got != 1 {
+		t.Errorf("got %d article.unfavorited events, want 1", got)
+	}
+}
+
+func TestArticleRepo_GetArticlesTags(t *testing.T) {
+	r := pgdb.NewArticleRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	tags, err := r.GetArticlesTags(ctx, []uuid.UUID{pgtest.AliceFirstArticleID, pgtest.AliceSecondArticleID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tags are sorted by description, articles without tags are absent
+	first := tags[pgtest.AliceFirstArticleID]
+	if len(first) != 2 || first[0].Description != "classics" || first[1].Description != "wonderland" {
+		t.Errorf("tags of the first chapter = %+v", first)
+	}
+	if _, ok := tags[pgtest.AliceSecondArticleID]; ok || len(tags) != 1 {
+		t.Errorf("tags of other articles are returned: %+v", tags)
+	}
+}
+
+func TestArticleRepo_UpdateArticleByID(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	r := pgdb.NewArticleRepo(pg)
+	ctx := context.Background()
+
+	title := "A Caucus-Race"
+	err := r.UpdateArticleByID(ctx, pgtest.AliceSecondArticleID, pgtest.AdminID, &title, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article, err := r.GetArticleByID(ctx, pgtest.AliceSecondArticleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.Title != title || article.Description != "the second chapter" {
+		t.Errorf("updated article = %+v", article)
+	}
+	if !article.UpdatedAt.After(article.CreatedAt) {
+		t.Errorf("updated_at is not moved: %s", article.UpdatedAt)
+	}
+	if got := countEvents(t, pg, entity.EventArticleUpdated, pgtest.AliceSecondArticleID); got != 1 {
+		t.Errorf("got %d article.updated events, want 1", got)
+	}
+
+	err = r.UpdateArticleByID(ctx, pgtest.CarolArticleID, pgtest.AdminID, &title, nil, nil)
+	if err != repoerrs.ErrArticleNotFound {
+		t.Errorf("deleted article: err = %v, want %v", err, repoerrs.ErrArticleNotFound)
+	}
+}
+
+func TestArticleRepo_DeleteAndRestore(t *testing.T) {
+	r := pgdb.NewArticleRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{name: "delete", run: func() error { return r.DeleteArticleByID(ctx, pgtest.AliceFirstArticleID) }},
+		{
+			name: "delete again",
+			run:  func() error { return r.DeleteArticleByID(ctx, pgtest.AliceFirstArticleID) },
+			want: repoerrs.ErrArticleNotFound,
+		},
+		{name: "restore", run: func() error { return r.RestoreArticleByID(ctx, pgtest.AliceFirstArticleID) }},
+		{
+			name: "restore active",
+			run:  func() error { return r.RestoreArticleByID(ctx, pgtest.AliceSecondArticleID) },
+			want: repoerrs.ErrArticleNotFound,
+		},
+		{
+			name: "delete unknown",
+			run:  func() error { return r.DeleteArticleByID(ctx, uuid.New()) },
+			want: repoerrs.ErrArticleNotFound,
+		},
+	}
+
+	// steps depend on each other, so they run in order
+	for _, tt := range tests {
+		if err := tt.run(); err != tt.want {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := r.GetArticleByID(ctx, pgtest.AliceFirstArticleID); err != nil {
+		t.Errorf("restored article: %v", err)
+	}
+}
+
+func sameOrder(got, want []uuid.UUID) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+}
@@ -0,0 +1,174 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+var auditColumns = []string{
+	"id", "actor_id", "impersonator_id", "action", "target_type", "target_id", "details",
+	"ip", "user_agent", "request_id", "created_at",
+}
+
+type AuditRepo struct {
+	*postgres.Postgres
+}
+
+func NewAuditRepo(pg *postgres.Postgres) *AuditRepo {
+	return &AuditRepo{pg}
+}
+
+// execer - pgx.Tx or pool, audit entries are written in the transaction of the audited change when there is one
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+func (r *AuditRepo) CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error {
+	err := insertAuditEntry(ctx, r.DB(ctx), r.Builder, entry)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("AuditRepo.CreateAuditEntry - insertAuditEntry: %v", err)
+		return fmt.Errorf("AuditRepo.CreateAuditEntry - insertAuditEntry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditRepo) GetAuditLog(ctx context.Context, filter entity.AuditFilter, limit, offset int) ([]entity.AuditEntry, error) {
+	sql, args, _ := r.auditLogQuery(filter).
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("AuditRepo.GetAuditLog - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("AuditRepo.GetAuditLog - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []entity.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("AuditRepo.GetAuditLog - rows.Scan: %v", err)
+			return nil, fmt.Errorf("AuditRepo.GetAuditLog - rows.Scan: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ExportAuditLog - все записи по фильтру передаются в fn по одной, без загрузки журнала в память
+func (r *AuditRepo) ExportAuditLog(ctx context.Context, filter entity.AuditFilter, fn func(entry entity.AuditEntry) error) error {
+	sql, args, _ := r.auditLogQuery(filter).ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("AuditRepo.ExportAuditLog - r.Reader.Query: %v", err)
+		return fmt.Errorf("AuditRepo.ExportAuditLog - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("AuditRepo.ExportAuditLog - rows.Scan: %v", err)
+			return fmt.Errorf("AuditRepo.ExportAuditLog - rows.Scan: %w", err)
+		}
+
+		err = fn(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		logger.FromContext(ctx).Errorf("AuditRepo.ExportAuditLog - rows.Err: %v", err)
+		return fmt.Errorf("AuditRepo.ExportAuditLog - rows.Err: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditRepo) auditLogQuery(filter entity.AuditFilter) squirrel.SelectBuilder {
+	sqlBuilder := r.Builder.
+		Select(auditColumns...).
+		From("audit_log")
+
+	if filter.ActorID.Valid {
+		sqlBuilder = sqlBuilder.Where("actor_id = ?", filter.ActorID.UUID)
+	}
+	if filter.TargetID.Valid {
+		sqlBuilder = sqlBuilder.Where("target_id = ?", filter.TargetID.UUID)
+	}
+	if filter.TargetType != nil {
+		sqlBuilder = sqlBuilder.Where("target_type = ?", *filter.TargetType)
+	}
+	if filter.Action != nil {
+		sqlBuilder = sqlBuilder.Where("action = ?", *filter.Action)
+	}
+	if filter.RequestID != "" {
+		sqlBuilder = sqlBuilder.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.IP != "" {
+		sqlBuilder = sqlBuilder.Where("ip = ?", filter.IP)
+	}
+	if filter.From != nil {
+		sqlBuilder = sqlBuilder.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		sqlBuilder = sqlBuilder.Where("created_at < ?", *filter.To)
+	}
+
+	return sqlBuilder.OrderBy("created_at DESC")
+}
+
+func insertAuditEntry(ctx context.Context, db execer, builder squirrel.StatementBuilderType, entry entity.AuditEntry) error {
+	details := entry.Details
+	if details == nil {
+		details = []byte("{}")
+	}
+
+	sql, args, _ := builder.
+		Insert("audit_log").
+		Columns("actor_id", "impersonator_id", "action", "target_type", "target_id", "details", "ip", "user_agent", "request_id").
+		Values(
+			entry.ActorID, entry.ImpersonatorID, entry.Action, entry.TargetType, entry.TargetID, details,
+			entry.IP, entry.UserAgent, entry.RequestID,
+		).
+		ToSql()
+
+	_, err := db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("db.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func scanAuditEntry(row pgx.Row) (entity.AuditEntry, error) {
+	var entry entity.AuditEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.ActorID,
+		&entry.ImpersonatorID,
+		&entry.Action,
+		&entry.TargetType,
+		&entry.TargetID,
+		&entry.Details,
+		&entry.IP,
+		&entry.UserAgent,
+		&entry.RequestID,
+		&entry.CreatedAt,
+	)
+	return entry, err
+}
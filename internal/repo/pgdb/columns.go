@@ -0,0 +1,19 @@
+package pgdb
+
+import "strings"
+
+// prefixColumns - имена колонок с алиасом таблицы для запросов с join
+func prefixColumns(alias string, columns []string) []string {
+	prefixed := make([]string, 0, len(columns))
+	for _, column := range columns {
+		prefixed = append(prefixed, alias+"."+column)
+	}
+	return prefixed
+}
+
+// escapeLike - экранирование спецсимволов LIKE в пользовательском вводе
+func escapeLike(s string) string {
+	return likeReplacer.Replace(s)
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,306 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+var commentColumns = []string{
+	"id", "author_id", "article_id", "parent_id", "content", "created_at", "updated_at",
+	"votes_up_count", "votes_down_count", "hidden_at",
+}
+
+type CommentRepo struct {
+	*postgres.Postgres
+}
+
+func NewCommentRepo(pg *postgres.Postgres) *CommentRepo {
+	return &CommentRepo{pg}
+}
+
+func (r *CommentRepo) CreateComment(ctx context.Context, comment entity.Comment) (entity.Comment, error) {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.CreateComment - r.Begin: %v", err)
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Insert("comments").
+		Columns("author_id", "article_id", "parent_id", "content").
+		Values(comment.AuthorID, comment.ArticleID, comment.ParentID, comment.Content).
+		Suffix("RETURNING id, created_at, updated_at").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&comment.Id, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.CreateComment - tx.QueryRow: %v", err)
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - tx.QueryRow: %w", err)
+	}
+
+	err = changeCounter(ctx, tx, r.Builder, "articles", "comments_count", comment.ArticleID, 1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.CreateComment - changeCounter: %v", err)
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - changeCounter: %w", err)
+	}
+
+	err = changeCounter(ctx, tx, r.Builder, "users", "comments_count", comment.AuthorID, 1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.CreateComment - changeCounter: %v", err)
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - changeCounter: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, r.Builder, entity.EventCommentPosted, comment.Id, entity.CommentPostedPayload{
+		CommentID: comment.Id,
+		ArticleID: comment.ArticleID,
+		ParentID:  comment.ParentID,
+		AuthorID:  comment.AuthorID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.CreateComment - insertEvent: %v", err)
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.CreateComment - tx.Commit: %v", err)
+		return entity.Comment{}, fmt.Errorf("CommentRepo.CreateComment - tx.Commit: %w", err)
+	}
+
+	return comment, nil
+}
+
+func (r *CommentRepo) GetCommentByID(ctx context.Context, id uuid.UUID) (entity.Comment, error) {
+	sql, args, _ := r.Builder.
+		Select(commentColumns...).
+		From("comments").
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		ToSql()
+
+	var comment entity.Comment
+	err := r.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
+		&comment.Id,
+		&comment.AuthorID,
+		&comment.ArticleID,
+		&comment.ParentID,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+		&comment.VotesUpCount,
+		&comment.VotesDownCount,
+		&comment.HiddenAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Comment{}, repoerrs.ErrCommentNotFound
+		}
+		logger.FromContext(ctx).Errorf("CommentRepo.GetCommentByID - r.Reader.QueryRow: %v", err)
+		return entity.Comment{}, fmt.Errorf("CommentRepo.GetCommentByID - r.Reader.QueryRow: %w", err)
+	}
+
+	return comment, nil
+}
+
+func (r *CommentRepo) GetCommentsByArticleID(ctx context.Context, articleID uuid.UUID, limit, offset int) ([]entity.Comment, error) {
+	sql, args, _ := r.Builder.
+		Select(commentColumns...).
+		From("comments").
+		Where("article_id = ?", articleID).
+		Where("hidden_at IS NULL").
+		Where("deleted_at IS NULL").
+		OrderBy("created_at").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.GetCommentsByArticleID - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("CommentRepo.GetCommentsByArticleID - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []entity.Comment
+	for rows.Next() {
+		var comment entity.Comment
+		err := rows.Scan(
+			&comment.Id,
+			&comment.AuthorID,
+			&comment.ArticleID,
+			&comment.ParentID,
+			&comment.Content,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+			&comment.VotesUpCount,
+			&comment.VotesDownCount,
+			&comment.HiddenAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("CommentRepo.GetCommentsByArticleID - rows.Scan: %v", err)
+			return nil, fmt.Errorf("CommentRepo.GetCommentsByArticleID - rows.Scan: %w", err)
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+func (r *CommentRepo) UpdateCommentByID(ctx context.Context, commentID uuid.UUID, content string) error {
+	sql, args, _ := r.Builder.
+		Update("comments").
+		Set("content", content).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where("id = ?", commentID).
+		Where("deleted_at IS NULL").
+		ToSql()
+
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.UpdateCommentByID - r.DB.Exec: %v", err)
+		return fmt.Errorf("CommentRepo.UpdateCommentByID - r.DB.Exec: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// DeleteCommentByID - мягкое удаление, ответы на комментарий остаются видимыми
+func (r *CommentRepo) DeleteCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.DeleteCommentByID - r.Begin: %v", err)
+		return fmt.Errorf("CommentRepo.DeleteCommentByID - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	update := r.Builder.
+		Update("comments").
+		Set("deleted_at", squirrel.Expr("NOW()")).
+		Where("id = ?", commentID).
+		Where("deleted_at IS NULL")
+
+	n, err := updateComments(ctx, tx, r.Builder, update, entity.EventCommentDeleted)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.DeleteCommentByID - updateComments: %v", err)
+		return fmt.Errorf("CommentRepo.DeleteCommentByID - updateComments: %w", err)
+	}
+
+	if n == 0 {
+		return repoerrs.ErrCommentNotFound
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.DeleteCommentByID - tx.Commit: %v", err)
+		return fmt.Errorf("CommentRepo.DeleteCommentByID - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CommentRepo) RestoreCommentByID(ctx context.Context, commentID uuid.UUID) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.RestoreCommentByID - r.Begin: %v", err)
+		return fmt.Errorf("CommentRepo.RestoreCommentByID - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	update := r.Builder.
+		Update("comments").
+		Set("deleted_at", nil).
+		Where("id = ?", commentID).
+		Where("deleted_at IS NOT NULL")
+
+	n, err := updateComments(ctx, tx, r.Builder, update, entity.EventCommentRestored)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.RestoreCommentByID - updateComments: %v", err)
+		return fmt.Errorf("CommentRepo.RestoreCommentByID - updateComments: %w", err)
+	}
+
+	if n == 0 {
+		return repoerrs.ErrCommentNotFound
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentRepo.RestoreCommentByID - tx.Commit: %v", err)
+		return fmt.Errorf("CommentRepo.RestoreCommentByID - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// GetCommentsByAuthorID - все комментарии пользователя, включая скрытые модератором
+func (r *CommentRepo) GetCommentsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Comment, error) {
+	sql, args, _ := r.Builder.
+		Select(commentColumns...).
+		From("comments").
+		Where("author_id = ?", authorID).
+		Where("deleted_at IS NULL").
+		OrderBy("created_at").
+		ToSql()
+
+	return r.queryComments(ctx, "CommentRepo.GetCommentsByAuthorID", sql, args)
+}
+
+func (r *CommentRepo) GetFavoriteComments(ctx context.Context, userID uuid.UUID) ([]entity.Comment, error) {
+	sql, args, _ := r.Builder.
+		Select(prefixColumns("c", commentColumns)...).
+		From("comments c").
+		Join("users_comments_favorites f ON f.comment_id = c.id").
+		Where("f.user_id = ?", userID).
+		Where("c.deleted_at IS NULL").
+		OrderBy("c.created_at").
+		ToSql()
+
+	return r.queryComments(ctx, "CommentRepo.GetFavoriteComments", sql, args)
+}
+
+func (r *CommentRepo) queryComments(ctx context.Context, method, sql string, args []interface{}) ([]entity.Comment, error) {
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - r.Reader.Query: %v", method, err)
+		return nil, fmt.Errorf("%s - r.Reader.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	var comments []entity.Comment
+	for rows.Next() {
+		var comment entity.Comment
+		err := rows.Scan(
+			&comment.Id,
+			&comment.AuthorID,
+			&comment.ArticleID,
+			&comment.ParentID,
+			&comment.Content,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+			&comment.VotesUpCount,
+			&comment.VotesDownCount,
+			&comment.HiddenAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("%s - rows.Scan: %v", method, err)
+			return nil, fmt.Errorf("%s - rows.Scan: %w", method, err)
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
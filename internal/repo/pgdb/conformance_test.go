@@ -0,0 +1,18 @@
+//go:build integration
+
+package pgdb_test
+
+import (
+	"blog-backend/internal/repo"
+	"blog-backend/internal/testutil/pgtest"
+	"blog-backend/internal/testutil/repotest"
+	"blog-backend/pkg/postgres"
+	"testing"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) (*repo.Repositories, repotest.TxManager) {
+		pg := pgtest.New(t)
+		return repo.NewRepositories(pg), postgres.NewTxManager(pg)
+	})
+}
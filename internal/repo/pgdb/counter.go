@@ -0,0 +1,120 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type CounterRepo struct {
+	*postgres.Postgres
+}
+
+func NewCounterRepo(pg *postgres.Postgres) *CounterRepo {
+	return &CounterRepo{pg}
+}
+
+// counter - колонка table, равная количеству строк source со значением key = id.
+// Мягко удаленные строки считаются, счетчики уменьшаются только при окончательном удалении
+type counter struct {
+	table  string
+	column string
+	source string
+	key    string
+}
+
+// views_count isn't here, views of purged users stay counted
+var counters = []counter{
+	{"users", "articles_count", "articles", "author_id"},
+	{"users", "comments_count", "comments", "author_id"},
+	{"users", "favorites_articles_count", "users_articles_favorites", "user_id"},
+	{"users", "favorites_comments_count", "users_comments_favorites", "user_id"},
+	{"users", "followers_count", "users_followers", "following_id"},
+	{"users", "followings_count", "users_followers", "follower_id"},
+	{"articles", "comments_count", "comments", "article_id"},
+	{"articles", "favorites_count", "users_articles_favorites", "article_id"},
+	{"articles", "votes_up_count", "votes_articles_up", "article_id"},
+	{"articles", "votes_down_count", "votes_articles_down", "article_id"},
+	{"comments", "votes_up_count", "votes_comments_up", "comment_id"},
+	{"comments", "votes_down_count", "votes_comments_down", "comment_id"},
+}
+
+// %[1]s - table, %[2]s - column, %[3]s - source, %[4]s - key
+const (
+	counterDriftSQL = `select t.id, t.%[2]s as stored, coalesce(s.count, 0) as actual
+from %[1]s t
+left join (select %[4]s as id, count(*) as count from %[3]s group by %[4]s) s on s.id = t.id
+where t.%[2]s <> coalesce(s.count, 0)`
+
+	// the row is skipped if the counter was changed after the snapshot, the next run will get it
+	fixCounterDriftSQL = `with drift as (` + counterDriftSQL + `)
+update %[1]s t set %[2]s = d.actual
+from drift d
+where t.id = d.id and t.%[2]s = d.stored
+returning t.id, d.stored, d.actual`
+)
+
+// ReconcileCounters - пересчет счетчиков по исходным таблицам, возвращает расхождения.
+// С fix расхождения исправляются, каждый счетчик отдельным запросом, чтобы не держать блокировки на все таблицы
+func (r *CounterRepo) ReconcileCounters(ctx context.Context, fix bool) ([]entity.CounterDrift, error) {
+	// the dry run only reads, each query sees a consistent snapshot of a replica
+	db, query := r.Reader(ctx), counterDriftSQL
+	if fix {
+		db, query = r.DB(ctx), fixCounterDriftSQL
+	}
+
+	var drift []entity.CounterDrift
+	for _, c := range counters {
+		rows, err := db.Query(ctx, fmt.Sprintf(query, c.table, c.column, c.source, c.key))
+		if err != nil {
+			logger.FromContext(ctx).Errorf("CounterRepo.ReconcileCounters - db.Query: %v", err)
+			return nil, fmt.Errorf("CounterRepo.ReconcileCounters - db.Query: %w", err)
+		}
+
+		for rows.Next() {
+			d := entity.CounterDrift{Table: c.table, Column: c.column}
+			err = rows.Scan(&d.ID, &d.Stored, &d.Actual)
+			if err != nil {
+				rows.Close()
+				logger.FromContext(ctx).Errorf("CounterRepo.ReconcileCounters - rows.Scan: %v", err)
+				return nil, fmt.Errorf("CounterRepo.ReconcileCounters - rows.Scan: %w", err)
+			}
+			drift = append(drift, d)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			logger.FromContext(ctx).Errorf("CounterRepo.ReconcileCounters - rows.Err: %v", err)
+			return nil, fmt.Errorf("CounterRepo.ReconcileCounters - rows.Err: %w", err)
+		}
+	}
+
+	entity.SortCounterDrift(drift)
+
+	return drift, nil
+}
+
+// changeCounter - атомарное изменение счетчика в транзакции, которая меняет исходную таблицу
+func changeCounter(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, table, column string, id uuid.UUID, delta int64) error {
+	if delta == 0 {
+		return nil
+	}
+
+	sql, args, _ := builder.
+		Update(table).
+		Set(column, squirrel.Expr(column+" + ?", delta)).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	return nil
+}
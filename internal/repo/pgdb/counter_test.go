@@ -0,0 +1,77 @@
+//go:build integration
+
+package pgdb_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/pgdb"
+	"blog-backend/internal/testutil/pgtest"
+	"context"
+	"testing"
+)
+
+func TestCounterRepo_ReconcileCounters(t *testing.T) {
+	pg := pgtest.New(t)
+	ctx := context.Background()
+
+	authorID, err := pgdb.NewUserRepo(pg).CreateUser(ctx, entity.User{Name: "alice", Username: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	articleID, err := pgdb.NewArticleRepo(pg).CreateArticle(ctx, entity.Article{AuthorID: authorID, Title: "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// drift that bypassed the repositories
+	_, err = pg.Pool.Exec(ctx, "UPDATE users SET articles_count = 5 WHERE id = $1", authorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pg.Pool.Exec(ctx, "UPDATE articles SET favorites_count = -1 WHERE id = $1", articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := pgdb.NewCounterRepo(pg)
+	want := []entity.CounterDrift{
+		{Table: "articles", Column: "favorites_count", ID: articleID, Stored: -1, Actual: 0},
+		{Table: "users", Column: "articles_count", ID: authorID, Stored: 5, Actual: 1},
+	}
+
+	drift, err := r.ReconcileCounters(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalDrift(drift, want) {
+		t.Errorf("drift = %+v, want %+v", drift, want)
+	}
+
+	drift, err = r.ReconcileCounters(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalDrift(drift, want) {
+		t.Errorf("fixed drift = %+v, want %+v", drift, want)
+	}
+
+	drift, err = r.ReconcileCounters(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("drift after fix = %+v, want none", drift)
+	}
+}
+
+func equalDrift(got, want []entity.CounterDrift) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
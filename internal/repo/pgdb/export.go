@@ -0,0 +1,269 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"strings"
+	"time"
+)
+
+var userExportColumns = []string{
+	"id", "user_id", "status", "attempts", "size", "last_error", "created_at", "completed_at", "expires_at",
+}
+
+type ExportRepo struct {
+	*postgres.Postgres
+}
+
+func NewExportRepo(pg *postgres.Postgres) *ExportRepo {
+	return &ExportRepo{pg}
+}
+
+func (r *ExportRepo) CreateExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	sql, args, _ := r.Builder.
+		Insert("user_exports").
+		Columns("user_id").
+		Values(userID).
+		Suffix("RETURNING " + strings.Join(userExportColumns, ", ")).
+		ToSql()
+
+	export, err := scanUserExport(r.DB(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ExportRepo.CreateExport - r.DB.QueryRow: %v", err)
+		return entity.UserExport{}, fmt.Errorf("ExportRepo.CreateExport - r.DB.QueryRow: %w", err)
+	}
+
+	return export, nil
+}
+
+func (r *ExportRepo) GetExportByID(ctx context.Context, id uuid.UUID) (entity.UserExport, error) {
+	sql, args, _ := r.Builder.
+		Select(userExportColumns...).
+		From("user_exports").
+		Where("id = ?", id).
+		ToSql()
+
+	export, err := scanUserExport(r.Reader(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.UserExport{}, repoerrs.ErrExportNotFound
+		}
+		logger.FromContext(ctx).Errorf("ExportRepo.GetExportByID - r.Reader.QueryRow: %v", err)
+		return entity.UserExport{}, fmt.Errorf("ExportRepo.GetExportByID - r.Reader.QueryRow: %w", err)
+	}
+
+	return export, nil
+}
+
+// GetPendingExport - несобранный архив пользователя, новый не создается, пока этот не готов
+func (r *ExportRepo) GetPendingExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	sql, args, _ := r.Builder.
+		Select(userExportColumns...).
+		From("user_exports").
+		Where("user_id = ?", userID).
+		Where("status = ?", entity.UserExportPending).
+		OrderBy("created_at DESC").
+		Limit(1).
+		ToSql()
+
+	export, err := scanUserExport(r.Reader(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.UserExport{}, repoerrs.ErrExportNotFound
+		}
+		logger.FromContext(ctx).Errorf("ExportRepo.GetPendingExport - r.Reader.QueryRow: %v", err)
+		return entity.UserExport{}, fmt.Errorf("ExportRepo.GetPendingExport - r.Reader.QueryRow: %w", err)
+	}
+
+	return export, nil
+}
+
+// GetExportArchive - архив отдается, только пока он не истек
+func (r *ExportRepo) GetExportArchive(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	sql, args, _ := r.Builder.
+		Select("archive").
+		From("user_exports").
+		Where("id = ?", id).
+		Where("status = ?", entity.UserExportReady).
+		Where("expires_at > NOW()").
+		ToSql()
+
+	var archive []byte
+	err := r.Reader(ctx).QueryRow(ctx, sql, args...).Scan(&archive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrs.ErrExportNotFound
+		}
+		logger.FromContext(ctx).Errorf("ExportRepo.GetExportArchive - r.Reader.QueryRow: %v", err)
+		return nil, fmt.Errorf("ExportRepo.GetExportArchive - r.Reader.QueryRow: %w", err)
+	}
+
+	return archive, nil
+}
+
+// LockPendingExports - захват пачки архивов на время lease, аналогично WebhookRepo.LockPendingDeliveries
+func (r *ExportRepo) LockPendingExports(ctx context.Context, limit int, lease time.Duration) ([]entity.UserExport, error) {
+	subQuery := r.Builder.
+		Select("id").
+		From("user_exports").
+		Where("status = ?", entity.UserExportPending).
+		Where("(locked_until IS NULL OR locked_until < NOW())").
+		OrderBy("created_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, _ := r.Builder.
+		Update("user_exports").
+		Set("locked_until", squirrel.Expr("NOW() + make_interval(secs => ?)", lease.Seconds())).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Where(squirrel.Expr("id IN (?)", subQuery)).
+		Suffix("RETURNING " + strings.Join(userExportColumns, ", ")).
+		ToSql()
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ExportRepo.LockPendingExports - r.DB.Query: %v", err)
+		return nil, fmt.Errorf("ExportRepo.LockPendingExports - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []entity.UserExport
+	for rows.Next() {
+		export, err := scanUserExport(rows)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ExportRepo.LockPendingExports - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ExportRepo.LockPendingExports - rows.Scan: %w", err)
+		}
+
+		exports = append(exports, export)
+	}
+
+	return exports, nil
+}
+
+func (r *ExportRepo) MarkExportReady(ctx context.Context, id uuid.UUID, archive []byte, ttl time.Duration) error {
+	sql, args, _ := r.Builder.
+		Update("user_exports").
+		Set("status", entity.UserExportReady).
+		Set("archive", archive).
+		Set("size", len(archive)).
+		Set("locked_until", nil).
+		Set("last_error", nil).
+		Set("completed_at", squirrel.Expr("NOW()")).
+		Set("expires_at", squirrel.Expr("NOW() + make_interval(secs => ?)", ttl.Seconds())).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ExportRepo.MarkExportReady - r.DB.Exec: %v", err)
+		return fmt.Errorf("ExportRepo.MarkExportReady - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// MarkExportFailed - архив остается pending для повтора после lease, пока не закончатся попытки
+func (r *ExportRepo) MarkExportFailed(ctx context.Context, id uuid.UUID, lastError string, final bool) error {
+	sqlBuilder := r.Builder.
+		Update("user_exports").
+		Set("last_error", lastError)
+
+	if final {
+		sqlBuilder = sqlBuilder.
+			Set("status", entity.UserExportFailed).
+			Set("locked_until", nil).
+			Set("completed_at", squirrel.Expr("NOW()"))
+	}
+
+	sql, args, _ := sqlBuilder.
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ExportRepo.MarkExportFailed - r.DB.Exec: %v", err)
+		return fmt.Errorf("ExportRepo.MarkExportFailed - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredExports - удаление истекших и неудавшихся архивов
+func (r *ExportRepo) DeleteExpiredExports(ctx context.Context) (int, error) {
+	sql, args, _ := r.Builder.
+		Delete("user_exports").
+		Where(squirrel.Or{
+			squirrel.Expr("expires_at < NOW()"),
+			squirrel.And{
+				squirrel.Eq{"status": entity.UserExportFailed},
+				squirrel.Expr("completed_at < NOW() - make_interval(days => 1)"),
+			},
+		}).
+		ToSql()
+
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ExportRepo.DeleteExpiredExports - r.DB.Exec: %v", err)
+		return 0, fmt.Errorf("ExportRepo.DeleteExpiredExports - r.DB.Exec: %w", err)
+	}
+
+	return int(res.RowsAffected()), nil
+}
+
+// GetUserVotes - голоса пользователя за статьи и комментарии
+func (r *ExportRepo) GetUserVotes(ctx context.Context, userID uuid.UUID) ([]entity.Vote, error) {
+	sql := `
+select 'article', article_id, 'up' from votes_articles_up where user_id = $1
+union all
+select 'article', article_id, 'down' from votes_articles_down where user_id = $1
+union all
+select 'comment', comment_id, 'up' from votes_comments_up where user_id = $1
+union all
+select 'comment', comment_id, 'down' from votes_comments_down where user_id = $1`
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, userID)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ExportRepo.GetUserVotes - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ExportRepo.GetUserVotes - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var votes []entity.Vote
+	for rows.Next() {
+		var vote entity.Vote
+		err := rows.Scan(&vote.TargetType, &vote.TargetID, &vote.Value)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ExportRepo.GetUserVotes - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ExportRepo.GetUserVotes - rows.Scan: %w", err)
+		}
+
+		votes = append(votes, vote)
+	}
+
+	return votes, nil
+}
+
+func scanUserExport(row pgx.Row) (entity.UserExport, error) {
+	var export entity.UserExport
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.Attempts,
+		&export.Size,
+		&export.LastError,
+		&export.CreatedAt,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+	)
+	return export, err
+}
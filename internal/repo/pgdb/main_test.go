@@ -0,0 +1,32 @@
+//go:build integration
+
+package pgdb_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/testutil/pgtest"
+	"blog-backend/pkg/postgres"
+	"context"
+	"github.com/google/uuid"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Main(m))
+}
+
+// countEvents - число событий eventType по агрегату в outbox
+func countEvents(t *testing.T, pg *postgres.Postgres, eventType entity.EventType, aggregateID uuid.UUID) int {
+	t.Helper()
+
+	var count int
+	err := pg.Pool.QueryRow(context.Background(),
+		"SELECT count(*) FROM outbox WHERE event_type = $1 AND aggregate_id = $2", eventType, aggregateID,
+	).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
@@ -0,0 +1,426 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type ModerationRepo struct {
+	*postgres.Postgres
+}
+
+func NewModerationRepo(pg *postgres.Postgres) *ModerationRepo {
+	return &ModerationRepo{pg}
+}
+
+var (
+	moderationCaseColumns = []string{
+		"id", "target_type", "target_id", "target_author_id", "status", "reports_count", "assignee_id",
+		"claimed_at", "resolution", "resolved_by", "resolved_at", "created_at", "updated_at",
+	}
+	reportColumns = []string{
+		"id", "case_id", "reporter_id", "reason", "comment", "created_at",
+	}
+	moderationLogColumns = []string{
+		"id", "case_id", "moderator_id", "action", "target_type", "target_id", "note", "created_at",
+	}
+)
+
+// CreateReport - жалоба добавляется в открытый кейс цели или создает новый,
+// повторная жалоба того же пользователя в тот же кейс возвращает ErrReportAlreadyExists
+func (r *ModerationRepo) CreateReport(ctx context.Context, moderationCase entity.ModerationCase, report entity.Report) (entity.Report, error) {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.CreateReport - r.Begin: %v", err)
+		return entity.Report{}, fmt.Errorf("ModerationRepo.CreateReport - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Insert("moderation_cases").
+		Columns("target_type", "target_id", "target_author_id", "reports_count").
+		Values(moderationCase.TargetType, moderationCase.TargetID, moderationCase.TargetAuthorID, 1).
+		Suffix(`ON CONFLICT (target_type, target_id) WHERE status <> 'resolved'
+			DO UPDATE SET reports_count = moderation_cases.reports_count + 1, updated_at = NOW()
+			RETURNING id`).
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&report.CaseID)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.CreateReport - tx.QueryRow: %v", err)
+		return entity.Report{}, fmt.Errorf("ModerationRepo.CreateReport - tx.QueryRow: %w", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Insert("reports").
+		Columns("case_id", "reporter_id", "reason", "comment").
+		Values(report.CaseID, report.ReporterID, report.Reason, report.Comment).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return entity.Report{}, repoerrs.ErrReportAlreadyExists
+			}
+		}
+		logger.FromContext(ctx).Errorf("ModerationRepo.CreateReport - tx.QueryRow: %v", err)
+		return entity.Report{}, fmt.Errorf("ModerationRepo.CreateReport - tx.QueryRow: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.CreateReport - tx.Commit: %v", err)
+		return entity.Report{}, fmt.Errorf("ModerationRepo.CreateReport - tx.Commit: %w", err)
+	}
+
+	return report, nil
+}
+
+func (r *ModerationRepo) GetModerationCaseByID(ctx context.Context, id uuid.UUID) (entity.ModerationCase, error) {
+	sql, args, _ := r.Builder.
+		Select(moderationCaseColumns...).
+		From("moderation_cases").
+		Where("id = ?", id).
+		ToSql()
+
+	moderationCase, err := scanModerationCase(r.Reader(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ModerationCase{}, repoerrs.ErrModerationCaseNotFound
+		}
+		logger.FromContext(ctx).Errorf("ModerationRepo.GetModerationCaseByID - r.Reader.QueryRow: %v", err)
+		return entity.ModerationCase{}, fmt.Errorf("ModerationRepo.GetModerationCaseByID - r.Reader.QueryRow: %w", err)
+	}
+
+	return moderationCase, nil
+}
+
+// GetModerationCases - очередь модерации, первыми идут цели с наибольшим количеством жалоб
+func (r *ModerationRepo) GetModerationCases(ctx context.Context, status *entity.ModerationStatus, targetType *entity.ReportTargetType, limit, offset int) ([]entity.ModerationCase, error) {
+	sqlBuilder := r.Builder.
+		Select(moderationCaseColumns...).
+		From("moderation_cases")
+
+	if status != nil {
+		sqlBuilder = sqlBuilder.Where("status = ?", *status)
+	}
+	if targetType != nil {
+		sqlBuilder = sqlBuilder.Where("target_type = ?", *targetType)
+	}
+
+	sql, args, _ := sqlBuilder.
+		OrderBy("reports_count DESC", "created_at").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.GetModerationCases - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ModerationRepo.GetModerationCases - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var moderationCases []entity.ModerationCase
+	for rows.Next() {
+		moderationCase, err := scanModerationCase(rows)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ModerationRepo.GetModerationCases - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ModerationRepo.GetModerationCases - rows.Scan: %w", err)
+		}
+
+		moderationCases = append(moderationCases, moderationCase)
+	}
+
+	return moderationCases, nil
+}
+
+func (r *ModerationRepo) GetReportsByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.Report, error) {
+	sql, args, _ := r.Builder.
+		Select(reportColumns...).
+		From("reports").
+		Where("case_id = ?", caseID).
+		OrderBy("created_at").
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.GetReportsByCaseID - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ModerationRepo.GetReportsByCaseID - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []entity.Report
+	for rows.Next() {
+		var report entity.Report
+		err := rows.Scan(
+			&report.ID,
+			&report.CaseID,
+			&report.ReporterID,
+			&report.Reason,
+			&report.Comment,
+			&report.CreatedAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ModerationRepo.GetReportsByCaseID - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ModerationRepo.GetReportsByCaseID - rows.Scan: %w", err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// ClaimModerationCase - взять открытый кейс в работу, ErrModerationCaseNotFound если кейс уже не открыт
+func (r *ModerationRepo) ClaimModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ClaimModerationCase - r.Begin: %v", err)
+		return fmt.Errorf("ModerationRepo.ClaimModerationCase - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Update("moderation_cases").
+		Set("status", entity.ModerationStatusClaimed).
+		Set("assignee_id", moderatorID).
+		Set("claimed_at", squirrel.Expr("NOW()")).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where("id = ?", moderationCase.ID).
+		Where("status = ?", entity.ModerationStatusOpen).
+		ToSql()
+
+	res, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ClaimModerationCase - tx.Exec: %v", err)
+		return fmt.Errorf("ModerationRepo.ClaimModerationCase - tx.Exec: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrModerationCaseNotFound
+	}
+
+	err = r.insertLogEntry(ctx, tx, moderationCase, moderatorID, entity.ModerationActionClaim, "")
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ClaimModerationCase - r.insertLogEntry: %v", err)
+		return fmt.Errorf("ModerationRepo.ClaimModerationCase - r.insertLogEntry: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ClaimModerationCase - tx.Commit: %v", err)
+		return fmt.Errorf("ModerationRepo.ClaimModerationCase - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveModerationCase - закрытие кейса и применение действия к цели в одной транзакции,
+// ErrModerationCaseNotFound если кейс уже закрыт
+func (r *ModerationRepo) ResolveModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ResolveModerationCase - r.Begin: %v", err)
+		return fmt.Errorf("ModerationRepo.ResolveModerationCase - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Update("moderation_cases").
+		Set("status", entity.ModerationStatusResolved).
+		Set("resolution", action).
+		Set("resolved_by", moderatorID).
+		Set("resolved_at", squirrel.Expr("NOW()")).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where("id = ?", moderationCase.ID).
+		Where("status <> ?", entity.ModerationStatusResolved).
+		ToSql()
+
+	res, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ResolveModerationCase - tx.Exec: %v", err)
+		return fmt.Errorf("ModerationRepo.ResolveModerationCase - tx.Exec: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrModerationCaseNotFound
+	}
+
+	err = r.applyAction(ctx, tx, moderationCase, action)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ResolveModerationCase - r.applyAction: %v", err)
+		return fmt.Errorf("ModerationRepo.ResolveModerationCase - r.applyAction: %w", err)
+	}
+
+	err = r.insertLogEntry(ctx, tx, moderationCase, moderatorID, action, note)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ResolveModerationCase - r.insertLogEntry: %v", err)
+		return fmt.Errorf("ModerationRepo.ResolveModerationCase - r.insertLogEntry: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.ResolveModerationCase - tx.Commit: %v", err)
+		return fmt.Errorf("ModerationRepo.ResolveModerationCase - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ModerationRepo) GetModerationLog(ctx context.Context, moderatorID, caseID uuid.NullUUID, limit, offset int) ([]entity.ModerationLogEntry, error) {
+	sqlBuilder := r.Builder.
+		Select(moderationLogColumns...).
+		From("moderation_log")
+
+	if moderatorID.Valid {
+		sqlBuilder = sqlBuilder.Where("moderator_id = ?", moderatorID.UUID)
+	}
+	if caseID.Valid {
+		sqlBuilder = sqlBuilder.Where("case_id = ?", caseID.UUID)
+	}
+
+	sql, args, _ := sqlBuilder.
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("ModerationRepo.GetModerationLog - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("ModerationRepo.GetModerationLog - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []entity.ModerationLogEntry
+	for rows.Next() {
+		var entry entity.ModerationLogEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.CaseID,
+			&entry.ModeratorID,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.Note,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("ModerationRepo.GetModerationLog - rows.Scan: %v", err)
+			return nil, fmt.Errorf("ModerationRepo.GetModerationLog - rows.Scan: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// applyAction - цель могла быть уже удалена другим кейсом, тогда действие ничего не меняет и события нет
+func (r *ModerationRepo) applyAction(ctx context.Context, tx pgx.Tx, moderationCase entity.ModerationCase, action entity.ModerationAction) error {
+	table := ""
+	switch moderationCase.TargetType {
+	case entity.ReportTargetArticle:
+		table = "articles"
+	case entity.ReportTargetComment:
+		table = "comments"
+	}
+
+	switch action {
+	case entity.ModerationActionHide:
+		update := r.Builder.
+			Update(table).
+			Set("hidden_at", squirrel.Expr("NOW()")).
+			Where("id = ?", moderationCase.TargetID).
+			Where("hidden_at IS NULL")
+
+		if moderationCase.TargetType == entity.ReportTargetComment {
+			_, err := updateComments(ctx, tx, r.Builder, update, entity.EventCommentHidden)
+			return err
+		}
+		_, err := updateArticles(ctx, tx, r.Builder, update, entity.EventArticleHidden)
+		return err
+
+	case entity.ModerationActionDelete:
+		// soft delete, the row and the counters are cleaned up by the retention purge
+		update := r.Builder.
+			Update(table).
+			Set("deleted_at", squirrel.Expr("NOW()")).
+			Where("id = ?", moderationCase.TargetID).
+			Where("deleted_at IS NULL")
+
+		if moderationCase.TargetType == entity.ReportTargetComment {
+			_, err := updateComments(ctx, tx, r.Builder, update, entity.EventCommentDeleted)
+			return err
+		}
+		_, err := updateArticles(ctx, tx, r.Builder, update, entity.EventArticleDeleted)
+		return err
+
+	case entity.ModerationActionBan:
+		sql, args, _ := r.Builder.
+			Update("users").
+			Set("banned_at", squirrel.Expr("NOW()")).
+			Where("id = ?", moderationCase.TargetAuthorID).
+			Where("banned_at IS NULL").
+			ToSql()
+
+		_, err := tx.Exec(ctx, sql, args...)
+		return err
+	}
+
+	return nil
+}
+
+func (r *ModerationRepo) insertLogEntry(ctx context.Context, tx pgx.Tx, moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) error {
+	sql, args, _ := r.Builder.
+		Insert("moderation_log").
+		Columns("case_id", "moderator_id", "action", "target_type", "target_id", "note").
+		Values(moderationCase.ID, moderatorID, action, moderationCase.TargetType, moderationCase.TargetID, note).
+		ToSql()
+
+	_, err := tx.Exec(ctx, sql, args...)
+	return err
+}
+
+func scanModerationCase(row pgx.Row) (entity.ModerationCase, error) {
+	var moderationCase entity.ModerationCase
+	var resolution *string
+	err := row.Scan(
+		&moderationCase.ID,
+		&moderationCase.TargetType,
+		&moderationCase.TargetID,
+		&moderationCase.TargetAuthorID,
+		&moderationCase.Status,
+		&moderationCase.ReportsCount,
+		&moderationCase.AssigneeID,
+		&moderationCase.ClaimedAt,
+		&resolution,
+		&moderationCase.ResolvedBy,
+		&moderationCase.ResolvedAt,
+		&moderationCase.CreatedAt,
+		&moderationCase.UpdatedAt,
+	)
+	if err != nil {
+		return entity.ModerationCase{}, err
+	}
+
+	if resolution != nil {
+		action := entity.ModerationAction(*resolution)
+		moderationCase.Resolution = &action
+	}
+
+	return moderationCase, nil
+}
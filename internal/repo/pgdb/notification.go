@@ -0,0 +1,81 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type NotificationRepo struct {
+	*postgres.Postgres
+}
+
+func NewNotificationRepo(pg *postgres.Postgres) *NotificationRepo {
+	return &NotificationRepo{pg}
+}
+
+func (r *NotificationRepo) CreateNotification(ctx context.Context, notification entity.Notification) (entity.Notification, error) {
+	sql, args, _ := r.Builder.
+		Insert("notifications").
+		Columns("user_id", "actor_id", "type", "article_id", "comment_id").
+		Values(notification.UserID, notification.ActorID, notification.Type, notification.ArticleID, notification.CommentID).
+		Suffix("ON CONFLICT DO NOTHING RETURNING id, created_at").
+		ToSql()
+
+	err := r.DB(ctx).QueryRow(ctx, sql, args...).Scan(&notification.ID, &notification.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Notification{}, repoerrs.ErrNotificationAlreadyExists
+		}
+		logger.FromContext(ctx).Errorf("NotificationRepo.CreateNotification - r.DB.QueryRow: %v", err)
+		return entity.Notification{}, fmt.Errorf("NotificationRepo.CreateNotification - r.DB.QueryRow: %w", err)
+	}
+
+	return notification, nil
+}
+
+func (r *NotificationRepo) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, error) {
+	sql, args, _ := r.Builder.
+		Select("id", "user_id", "actor_id", "type", "article_id", "comment_id", "created_at").
+		From("notifications").
+		Where("user_id = ?", userID).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("NotificationRepo.GetNotificationsByUserID - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("NotificationRepo.GetNotificationsByUserID - r.Reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []entity.Notification
+	for rows.Next() {
+		var notification entity.Notification
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.ActorID,
+			&notification.Type,
+			&notification.ArticleID,
+			&notification.CommentID,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("NotificationRepo.GetNotificationsByUserID - rows.Scan: %v", err)
+			return nil, fmt.Errorf("NotificationRepo.GetNotificationsByUserID - rows.Scan: %w", err)
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
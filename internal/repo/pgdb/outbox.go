@@ -0,0 +1,223 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"sort"
+	"time"
+)
+
+type OutboxRepo struct {
+	*postgres.Postgres
+}
+
+func NewOutboxRepo(pg *postgres.Postgres) *OutboxRepo {
+	return &OutboxRepo{pg}
+}
+
+// LockPendingEvents - захват пачки событий для доставки на время lease,
+// события других экземпляров релея пропускаются, незавершенные по истечении lease захватываются снова
+func (r *OutboxRepo) LockPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.Event, error) {
+	subQuery := r.Builder.
+		Select("id").
+		From("outbox").
+		Where("status = ?", entity.EventStatusPending).
+		Where("next_attempt_at <= NOW()").
+		Where("(locked_until IS NULL OR locked_until < NOW())").
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, _ := r.Builder.
+		Update("outbox").
+		Set("locked_until", squirrel.Expr("NOW() + make_interval(secs => ?)", lease.Seconds())).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Where(squirrel.Expr("id IN (?)", subQuery)).
+		Suffix("RETURNING id, event_type, aggregate_id, payload, created_at, attempts").
+		ToSql()
+
+	rows, err := r.DB(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("OutboxRepo.LockPendingEvents - r.DB.Query: %v", err)
+		return nil, fmt.Errorf("OutboxRepo.LockPendingEvents - r.DB.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var events []entity.Event
+	for rows.Next() {
+		var event entity.Event
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.AggregateID,
+			&event.Payload,
+			&event.CreatedAt,
+			&event.Attempts,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("OutboxRepo.LockPendingEvents - rows.Scan: %v", err)
+			return nil, fmt.Errorf("OutboxRepo.LockPendingEvents - rows.Scan: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	// RETURNING doesn't keep the order of the sub query
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	return events, nil
+}
+
+func (r *OutboxRepo) MarkEventPublished(ctx context.Context, id int64) error {
+	sql, args, _ := r.Builder.
+		Update("outbox").
+		Set("status", entity.EventStatusPublished).
+		Set("published_at", squirrel.Expr("NOW()")).
+		Set("locked_until", nil).
+		Set("last_error", nil).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("OutboxRepo.MarkEventPublished - r.DB.Exec: %v", err)
+		return fmt.Errorf("OutboxRepo.MarkEventPublished - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepo) MarkEventFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration) error {
+	sql, args, _ := r.Builder.
+		Update("outbox").
+		Set("next_attempt_at", squirrel.Expr("NOW() + make_interval(secs => ?)", retryIn.Seconds())).
+		Set("locked_until", nil).
+		Set("last_error", lastError).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("OutboxRepo.MarkEventFailed - r.DB.Exec: %v", err)
+		return fmt.Errorf("OutboxRepo.MarkEventFailed - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepo) MarkEventDead(ctx context.Context, id int64, lastError string) error {
+	sql, args, _ := r.Builder.
+		Update("outbox").
+		Set("status", entity.EventStatusDead).
+		Set("locked_until", nil).
+		Set("last_error", lastError).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("OutboxRepo.MarkEventDead - r.DB.Exec: %v", err)
+		return fmt.Errorf("OutboxRepo.MarkEventDead - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// insertEvent - запись события в outbox внутри транзакции изменения
+func insertEvent(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, eventType entity.EventType, aggregateID uuid.UUID, payload any) error {
+	event, err := entity.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return fmt.Errorf("entity.NewEvent: %w", err)
+	}
+
+	sql, args, _ := builder.
+		Insert("outbox").
+		Columns("event_type", "aggregate_id", "payload").
+		Values(event.Type, event.AggregateID, event.Payload).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// updateArticles - обновление статей с событием на каждую измененную статью в той же транзакции,
+// возвращает число измененных статей
+func updateArticles(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, update squirrel.UpdateBuilder, eventType entity.EventType) (int, error) {
+	sql, args, _ := update.Suffix("RETURNING id, author_id").ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("tx.Query: %w", err)
+	}
+
+	var payloads []entity.ArticleDeletedPayload
+	for rows.Next() {
+		var payload entity.ArticleDeletedPayload
+		err = rows.Scan(&payload.ArticleID, &payload.AuthorID)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("rows.Scan: %w", err)
+		}
+
+		payloads = append(payloads, payload)
+	}
+	// the connection is busy until the rows are closed
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	for _, payload := range payloads {
+		err = insertEvent(ctx, tx, builder, eventType, payload.ArticleID, payload)
+		if err != nil {
+			return 0, fmt.Errorf("insertEvent: %w", err)
+		}
+	}
+
+	return len(payloads), nil
+}
+
+// updateComments - то же, что updateArticles, для комментариев
+func updateComments(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, update squirrel.UpdateBuilder, eventType entity.EventType) (int, error) {
+	sql, args, _ := update.Suffix("RETURNING id, article_id, author_id").ToSql()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("tx.Query: %w", err)
+	}
+
+	var payloads []entity.CommentDeletedPayload
+	for rows.Next() {
+		var payload entity.CommentDeletedPayload
+		err = rows.Scan(&payload.CommentID, &payload.ArticleID, &payload.AuthorID)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("rows.Scan: %w", err)
+		}
+
+		payloads = append(payloads, payload)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows.Err: %w", err)
+	}
+
+	for _, payload := range payloads {
+		err = insertEvent(ctx, tx, builder, eventType, payload.CommentID, payload)
+		if err != nil {
+			return 0, fmt.Errorf("insertEvent: %w", err)
+		}
+	}
+
+	return len(payloads), nil
+}
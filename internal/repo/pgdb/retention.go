@@ -0,0 +1,338 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+type RetentionRepo struct {
+	*postgres.Postgres
+}
+
+func NewRetentionRepo(pg *postgres.Postgres) *RetentionRepo {
+	return &RetentionRepo{pg}
+}
+
+// counters are kept while rows are soft deleted and are decreased right before the purge.
+// Each statement updates a table once, a single query can't update the same row twice.
+// Aggregated sub queries take care of duplicated favorites and votes.
+const (
+	// comment with all replies, $1 - comment id
+	commentSubtreeCTE = `with recursive subtree as (
+    select id, author_id, article_id from comments where id = $1
+    union
+    select c.id, c.author_id, c.article_id from comments c join subtree s on c.parent_id = s.id
+)
+`
+	// comments of the user outside of his own articles with all replies, $1 - user id
+	userCommentsSubtreeCTE = `with recursive subtree as (
+    select id, author_id, article_id from comments
+    where author_id = $1 and article_id not in (select id from articles where author_id = $1)
+    union
+    select c.id, c.author_id, c.article_id from comments c join subtree s on c.parent_id = s.id
+)
+`
+	decreaseSubtreeCountersSQL = `
+update users u set comments_count = u.comments_count - s.count
+from (select author_id, count(*) as count from subtree group by author_id) s
+where u.id = s.author_id`
+
+	decreaseSubtreeArticleCountersSQL = `
+update articles a set comments_count = a.comments_count - s.count
+from (select article_id, count(*) as count from subtree group by article_id) s
+where a.id = s.article_id`
+
+	decreaseSubtreeFavoritesSQL = `
+update users u set favorites_comments_count = u.favorites_comments_count - f.count
+from (select user_id, count(*) as count from users_comments_favorites
+      where comment_id in (select id from subtree) group by user_id) f
+where u.id = f.user_id`
+
+	// articles scope is a sub query returning article ids
+	decreaseArticlesCommentersSQL = `
+update users u set comments_count = u.comments_count - c.count
+from (select author_id, count(*) as count from comments where article_id in (%[1]s) group by author_id) c
+where u.id = c.author_id`
+
+	decreaseArticlesFavoritesSQL = `
+update users u set favorites_articles_count = u.favorites_articles_count - f.count
+from (select user_id, count(*) as count from users_articles_favorites
+      where article_id in (%[1]s) group by user_id) f
+where u.id = f.user_id`
+
+	decreaseArticlesCommentsFavoritesSQL = `
+update users u set favorites_comments_count = u.favorites_comments_count - f.count
+from (select f.user_id, count(*) as count from users_comments_favorites f
+      join comments c on c.id = f.comment_id
+      where c.article_id in (%[1]s) group by f.user_id) f
+where u.id = f.user_id`
+)
+
+var (
+	purgeCommentSQL = []string{
+		commentSubtreeCTE + decreaseSubtreeCountersSQL,
+		commentSubtreeCTE + decreaseSubtreeArticleCountersSQL,
+		commentSubtreeCTE + decreaseSubtreeFavoritesSQL,
+	}
+
+	purgeArticleSQL = []string{
+		`update users u set articles_count = u.articles_count - 1 from articles a where a.id = $1 and u.id = a.author_id`,
+		fmt.Sprintf(decreaseArticlesCommentersSQL, "$1"),
+		fmt.Sprintf(decreaseArticlesFavoritesSQL, "$1"),
+		fmt.Sprintf(decreaseArticlesCommentsFavoritesSQL, "$1"),
+	}
+
+	// follows, favorites and votes of the user are removed by cascade
+	userRelationsSQL = []string{
+		`update users u set followers_count = u.followers_count - f.count
+from (select following_id, count(*) as count from users_followers where follower_id = $1 group by following_id) f
+where u.id = f.following_id`,
+		`update users u set followings_count = u.followings_count - f.count
+from (select follower_id, count(*) as count from users_followers where following_id = $1 group by follower_id) f
+where u.id = f.follower_id`,
+		`update articles a set favorites_count = a.favorites_count - f.count
+from (select article_id, count(*) as count from users_articles_favorites where user_id = $1 group by article_id) f
+where a.id = f.article_id`,
+		`update articles a set votes_up_count = a.votes_up_count - v.count
+from (select article_id, count(*) as count from votes_articles_up where user_id = $1 group by article_id) v
+where a.id = v.article_id`,
+		`update articles a set votes_down_count = a.votes_down_count - v.count
+from (select article_id, count(*) as count from votes_articles_down where user_id = $1 group by article_id) v
+where a.id = v.article_id`,
+		`update comments c set votes_up_count = c.votes_up_count - v.count
+from (select comment_id, count(*) as count from votes_comments_up where user_id = $1 group by comment_id) v
+where c.id = v.comment_id`,
+		`update comments c set votes_down_count = c.votes_down_count - v.count
+from (select comment_id, count(*) as count from votes_comments_down where user_id = $1 group by comment_id) v
+where c.id = v.comment_id`,
+	}
+
+	purgeUserSQL = append([]string{
+		// articles of the user are removed by cascade
+		fmt.Sprintf(decreaseArticlesCommentersSQL, "select id from articles where author_id = $1"),
+		fmt.Sprintf(decreaseArticlesFavoritesSQL, "select id from articles where author_id = $1"),
+		fmt.Sprintf(decreaseArticlesCommentsFavoritesSQL, "select id from articles where author_id = $1"),
+		// comments of the user in other articles and replies to them are removed by cascade too
+		userCommentsSubtreeCTE + decreaseSubtreeCountersSQL,
+		userCommentsSubtreeCTE + decreaseSubtreeArticleCountersSQL,
+		userCommentsSubtreeCTE + decreaseSubtreeFavoritesSQL,
+	}, userRelationsSQL...)
+
+	// content of the user is kept under the tombstone author
+	anonymizeUserSQL = append([]string{
+		fmt.Sprintf(`update users t set
+    articles_count = t.articles_count + (select count(*) from articles where author_id = $1),
+    comments_count = t.comments_count + (select count(*) from comments where author_id = $1)
+where t.id = '%s'`, entity.TombstoneUserID),
+		fmt.Sprintf(`update articles set author_id = '%s' where author_id = $1`, entity.TombstoneUserID),
+		fmt.Sprintf(`update comments set author_id = '%s' where author_id = $1`, entity.TombstoneUserID),
+		fmt.Sprintf(`update moderation_cases set target_author_id = '%s' where target_author_id = $1`, entity.TombstoneUserID),
+	}, userRelationsSQL...)
+)
+
+// PurgeDeletedComments - удаление комментариев, удаленных раньше olderThan назад, вместе с удаленными ответами.
+// Комментарий с неудаленными ответами остается, пока ответы не будут удалены
+func (r *RetentionRepo) PurgeDeletedComments(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	sql, args, _ := r.Builder.
+		Select("id").
+		From("comments c").
+		Where("deleted_at < NOW() - make_interval(secs => ?)", olderThan.Seconds()).
+		Where("NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL)").
+		OrderBy("deleted_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	return r.purge(ctx, "RetentionRepo.PurgeDeletedComments", sql, args, "comments", "deleted_at IS NOT NULL", purgeCommentSQL)
+}
+
+// PurgeDeletedArticles - удаление статей вместе со всеми комментариями, избранным и голосами
+func (r *RetentionRepo) PurgeDeletedArticles(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	sql, args, _ := r.Builder.
+		Select("id").
+		From("articles").
+		Where("deleted_at < NOW() - make_interval(secs => ?)", olderThan.Seconds()).
+		OrderBy("deleted_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	return r.purge(ctx, "RetentionRepo.PurgeDeletedArticles", sql, args, "articles", "deleted_at IS NOT NULL", purgeArticleSQL)
+}
+
+// PurgeDeletedUsers - удаление пользователей со всем их контентом, подписками, избранным и голосами
+func (r *RetentionRepo) PurgeDeletedUsers(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	sql, args, _ := r.Builder.
+		Select("id").
+		From("users").
+		Where("deleted_at < NOW() - make_interval(secs => ?)", olderThan.Seconds()).
+		OrderBy("deleted_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	return r.purge(ctx, "RetentionRepo.PurgeDeletedUsers", sql, args, "users", "deleted_at IS NOT NULL", purgeUserSQL)
+}
+
+// AnonymizeScheduledUsers - удаление аккаунтов, у которых закончился срок отмены удаления.
+// Статьи и комментарии переходят к TombstoneUserID, подписки, избранное и голоса удаляются
+func (r *RetentionRepo) AnonymizeScheduledUsers(ctx context.Context, limit int) (entity.Purged, error) {
+	sql, args, _ := r.Builder.
+		Select("id").
+		From("users").
+		Where("deletion_scheduled_at <= NOW()").
+		OrderBy("deletion_scheduled_at").
+		Limit(uint64(limit)).
+		ToSql()
+
+	return r.purge(ctx, "RetentionRepo.AnonymizeScheduledUsers", sql, args, "users", "deletion_scheduled_at <= NOW()", anonymizeUserSQL)
+}
+
+// purge - каждая строка удаляется в отдельной транзакции, чтобы не держать блокировки на всю пачку.
+// При ошибке возвращаются строки, удаленные до нее
+func (r *RetentionRepo) purge(ctx context.Context, method, sql string, args []interface{}, table, lockCondition string, counterSQL []string) (entity.Purged, error) {
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - r.Reader.Query: %v", method, err)
+		return entity.Purged{}, fmt.Errorf("%s - r.Reader.Query: %w", method, err)
+	}
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			logger.FromContext(ctx).Errorf("%s - rows.Scan: %v", method, err)
+			return entity.Purged{}, fmt.Errorf("%s - rows.Scan: %w", method, err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	var purged entity.Purged
+	for i, id := range ids {
+		err = r.purgeRow(ctx, id, table, lockCondition, counterSQL, &purged)
+		if err != nil {
+			purged.Count = i
+			logger.FromContext(ctx).Errorf("%s - r.purgeRow: %v", method, err)
+			return purged, fmt.Errorf("%s - r.purgeRow: %w", method, err)
+		}
+	}
+	purged.Count = len(ids)
+
+	return purged, nil
+}
+
+// purgeRow - ключи кеша добавляются в purged только после фиксации
+func (r *RetentionRepo) purgeRow(ctx context.Context, id uuid.UUID, table, lockCondition string, counterSQL []string, purged *entity.Purged) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// the row could be restored after it was selected
+	sql, args, _ := r.Builder.
+		Select("id").
+		From(table).
+		Where("id = ?", id).
+		Where(lockCondition).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var lockedID uuid.UUID
+	err = tx.QueryRow(ctx, sql, args...).Scan(&lockedID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	keys, err := r.purgedKeys(ctx, tx, id, table)
+	if err != nil {
+		return err
+	}
+
+	for _, sql := range counterSQL {
+		_, err = tx.Exec(ctx, sql, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	sql, args, _ = r.Builder.
+		Delete(table).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	purged.Usernames = append(purged.Usernames, keys.Usernames...)
+	purged.ArticleIDs = append(purged.ArticleIDs, keys.ArticleIDs...)
+
+	return nil
+}
+
+// purgedKeys - статьи пользователя удаляются каскадом или переходят к TombstoneUserID, поэтому читаются до изменения
+func (r *RetentionRepo) purgedKeys(ctx context.Context, tx pgx.Tx, id uuid.UUID, table string) (entity.Purged, error) {
+	var keys entity.Purged
+
+	switch table {
+	case "articles":
+		keys.ArticleIDs = []uuid.UUID{id}
+	case "users":
+		sql, args, _ := r.Builder.
+			Select("username").
+			From("users").
+			Where("id = ?", id).
+			ToSql()
+
+		var username string
+		err := tx.QueryRow(ctx, sql, args...).Scan(&username)
+		if err != nil {
+			return keys, err
+		}
+		keys.Usernames = []string{username}
+
+		sql, args, _ = r.Builder.
+			Select("id").
+			From("articles").
+			Where("author_id = ?", id).
+			ToSql()
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return keys, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var articleID uuid.UUID
+			err = rows.Scan(&articleID)
+			if err != nil {
+				return keys, err
+			}
+			keys.ArticleIDs = append(keys.ArticleIDs, articleID)
+		}
+		if err = rows.Err(); err != nil {
+			return keys, err
+		}
+	}
+
+	return keys, nil
+}
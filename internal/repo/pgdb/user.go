@@ -3,16 +3,25 @@ package pgdb
 import (
 	"blog-backend/internal/entity"
 	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
 	"blog-backend/pkg/postgres"
 	"context"
 	"errors"
 	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
-	log "github.com/sirupsen/logrus"
+	"time"
 )
 
+var userColumns = []string{
+	"id", "name", "username", "password", "email", "created_at", "updated_at", "role", "description",
+	"articles_count", "comments_count", "favorites_articles_count", "favorites_comments_count",
+	"followers_count", "followings_count", "banned_at", "deletion_scheduled_at",
+	"sessions_revoked_at", "password_reset_required",
+}
+
 type UserRepo struct {
 	*postgres.Postgres
 }
@@ -22,6 +31,13 @@ func NewUserRepo(pg *postgres.Postgres) *UserRepo {
 }
 
 func (r *UserRepo) CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error) {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.CreateUser - r.Begin: %v", err)
+		return uuid.UUID{}, fmt.Errorf("UserRepo.CreateUser - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	sql, args, _ := r.Builder.
 		Insert("users").
 		Columns("name", "username", "password", "email").
@@ -30,7 +46,7 @@ func (r *UserRepo) CreateUser(ctx context.Context, user entity.User) (uuid.UUID,
 		ToSql()
 
 	var id uuid.UUID
-	err := r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if ok := errors.As(err, &pgErr); ok {
@@ -38,7 +54,22 @@ func (r *UserRepo) CreateUser(ctx context.Context, user entity.User) (uuid.UUID,
 				return uuid.UUID{}, repoerrs.ErrUserAlreadyExists
 			}
 		}
-		return uuid.UUID{}, fmt.Errorf("UserRepo.CreateUser - r.Pool.QueryRow: %v", err)
+		return uuid.UUID{}, fmt.Errorf("UserRepo.CreateUser - tx.QueryRow: %w", err)
+	}
+
+	err = insertEvent(ctx, tx, r.Builder, entity.EventUserCreated, id, entity.UserCreatedPayload{
+		UserID:   id,
+		Username: user.Username,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.CreateUser - insertEvent: %v", err)
+		return uuid.UUID{}, fmt.Errorf("UserRepo.CreateUser - insertEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.CreateUser - tx.Commit: %v", err)
+		return uuid.UUID{}, fmt.Errorf("UserRepo.CreateUser - tx.Commit: %w", err)
 	}
 
 	return id, nil
@@ -48,14 +79,16 @@ func (r *UserRepo) UpdateUserPassword(ctx context.Context, userID uuid.UUID, old
 	sql, args, _ := r.Builder.
 		Update("users").
 		Set("password", newPassword).
+		Set("password_reset_required", false).
 		Set("updated_at", "NOW()").
 		Where("id = ? AND password = ?", userID, oldPassword).
+		Where("deleted_at IS NULL").
 		ToSql()
 
-	res, err := r.Pool.Exec(ctx, sql, args...)
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
 	if err != nil {
-		log.Errorf("UserRepo.UpdateUserPassword - r.Pool.Exec: %v", err)
-		return fmt.Errorf("UserRepo.UpdateUserPassword - r.Pool.Exec: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.UpdateUserPassword - r.DB.Exec: %v", err)
+		return fmt.Errorf("UserRepo.UpdateUserPassword - r.DB.Exec: %w", err)
 	}
 
 	if res.RowsAffected() == 0 {
@@ -88,12 +121,13 @@ func (r *UserRepo) UpdateUserByID(ctx context.Context, userID uuid.UUID, name, e
 
 	sql, args, _ := sqlBuilder.
 		Where("id = ?", userID).
+		Where("deleted_at IS NULL").
 		ToSql()
 
-	res, err := r.Pool.Exec(ctx, sql, args...)
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
 	if err != nil {
-		log.Errorf("UserRepo.UpdateUserByID - r.Pool.Exec: %v", err)
-		return fmt.Errorf("UserRepo.UpdateUserByID - r.Pool.Exec: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.UpdateUserByID - r.DB.Exec: %v", err)
+		return fmt.Errorf("UserRepo.UpdateUserByID - r.DB.Exec: %w", err)
 	}
 
 	if res.RowsAffected() == 0 {
@@ -105,13 +139,14 @@ func (r *UserRepo) UpdateUserByID(ctx context.Context, userID uuid.UUID, name, e
 
 func (r *UserRepo) GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("*").
+		Select(userColumns...).
 		From("users").
 		Where("username = ? AND password = ?", username, password).
+		Where("deleted_at IS NULL").
 		ToSql()
 
 	var user entity.User
-	err := r.Pool.QueryRow(ctx, sql, args...).Scan(
+	err := r.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Username,
@@ -127,13 +162,17 @@ func (r *UserRepo) GetUserByUsernameAndPassword(ctx context.Context, username, p
 		&user.FavoritesCommentsCount,
 		&user.FollowersCount,
 		&user.FollowingCount,
+		&user.BannedAt,
+		&user.DeletionScheduledAt,
+		&user.SessionsRevokedAt,
+		&user.PasswordResetRequired,
 	)
 	if err != nil {
-		log.Errorf("UserRepo.GetUserByUsernameAndPassword - r.Pool.QueryRow: %v", err)
-		if err == pgx.ErrNoRows {
+		logger.FromContext(ctx).Errorf("UserRepo.GetUserByUsernameAndPassword - r.Reader.QueryRow: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
 			return entity.User{}, repoerrs.ErrUserNotFound
 		}
-		return entity.User{}, fmt.Errorf("UserRepo.GetUserByUsernameAndPassword - r.Pool.QueryRow: %v", err)
+		return entity.User{}, fmt.Errorf("UserRepo.GetUserByUsernameAndPassword - r.Reader.QueryRow: %w", err)
 	}
 
 	return user, nil
@@ -141,13 +180,14 @@ func (r *UserRepo) GetUserByUsernameAndPassword(ctx context.Context, username, p
 
 func (r *UserRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("*").
+		Select(userColumns...).
 		From("users").
-		Where("userID = ?", userID).
+		Where("id = ?", userID).
+		Where("deleted_at IS NULL").
 		ToSql()
 
 	var user entity.User
-	err := r.Pool.QueryRow(ctx, sql, args...).Scan(
+	err := r.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Username,
@@ -163,13 +203,17 @@ func (r *UserRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (entity.Us
 		&user.FavoritesCommentsCount,
 		&user.FollowersCount,
 		&user.FollowingCount,
+		&user.BannedAt,
+		&user.DeletionScheduledAt,
+		&user.SessionsRevokedAt,
+		&user.PasswordResetRequired,
 	)
 	if err != nil {
-		log.Errorf("UserRepo.GetUserByID - r.Pool.QueryRow: %v", err)
-		if err == pgx.ErrNoRows {
+		logger.FromContext(ctx).Errorf("UserRepo.GetUserByID - r.Reader.QueryRow: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
 			return entity.User{}, repoerrs.ErrUserNotFound
 		}
-		return entity.User{}, fmt.Errorf("UserRepo.GetUserByID - r.Pool.QueryRow: %v", err)
+		return entity.User{}, fmt.Errorf("UserRepo.GetUserByID - r.Reader.QueryRow: %w", err)
 	}
 
 	return user, nil
@@ -177,13 +221,14 @@ func (r *UserRepo) GetUserByID(ctx context.Context, userID uuid.UUID) (entity.Us
 
 func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("*").
+		Select(userColumns...).
 		From("users").
 		Where("username = ?", username).
+		Where("deleted_at IS NULL").
 		ToSql()
 
 	var user entity.User
-	err := r.Pool.QueryRow(ctx, sql, args...).Scan(
+	err := r.Reader(ctx).QueryRow(ctx, sql, args...).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Username,
@@ -199,83 +244,147 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (enti
 		&user.FavoritesCommentsCount,
 		&user.FollowersCount,
 		&user.FollowingCount,
+		&user.BannedAt,
+		&user.DeletionScheduledAt,
+		&user.SessionsRevokedAt,
+		&user.PasswordResetRequired,
 	)
 	if err != nil {
-		log.Errorf("UserRepo.GetUserByUsername - r.Pool.QueryRow: %v", err)
-		if err == pgx.ErrNoRows {
+		logger.FromContext(ctx).Errorf("UserRepo.GetUserByUsername - r.Reader.QueryRow: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
 			return entity.User{}, repoerrs.ErrUserNotFound
 		}
-		return entity.User{}, fmt.Errorf("UserRepo.GetUserByUsername - r.Pool.QueryRow: %v", err)
+		return entity.User{}, fmt.Errorf("UserRepo.GetUserByUsername - r.Reader.QueryRow: %w", err)
 	}
 
 	return user, nil
 }
 
-func (r *UserRepo) SetUserFollower(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) error {
-	tx, err := r.Pool.BeginTx(ctx, pgx.TxOptions{})
+// GetUsersByIDs - пользователи одним запросом, удаленные и не найденные пропускаются, порядок не определен
+func (r *UserRepo) GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]entity.User, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, _ := r.Builder.
+		Select(userColumns...).
+		From("users").
+		Where(squirrel.Eq{"id": userIDs}).
+		Where("deleted_at IS NULL").
+		ToSql()
+
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		log.Errorf("UserRepo.SetUserFollower - r.Pool.BeginTx: %v", err)
-		return fmt.Errorf("UserRepo.SetUserFollower - r.Pool.BeginTx: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.GetUsersByIDs - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("UserRepo.GetUsersByIDs - r.Reader.Query: %w", err)
 	}
+	defer rows.Close()
+
+	var users []entity.User
+	for rows.Next() {
+		var user entity.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Username,
+			&user.Password,
+			&user.Email,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Role,
+			&user.Description,
+			&user.ArticlesCount,
+			&user.CommentsCount,
+			&user.FavoritesArticlesCount,
+			&user.FavoritesCommentsCount,
+			&user.FollowersCount,
+			&user.FollowingCount,
+			&user.BannedAt,
+			&user.DeletionScheduledAt,
+			&user.SessionsRevokedAt,
+			&user.PasswordResetRequired,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("UserRepo.GetUsersByIDs - rows.Scan: %v", err)
+			return nil, fmt.Errorf("UserRepo.GetUsersByIDs - rows.Scan: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// SetUserFollower - повторная подписка не вставляется благодаря уникальной паре, счетчики и событие не меняются
+func (r *UserRepo) SetUserFollower(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) (bool, error) {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.SetUserFollower - r.Begin: %v", err)
+		return false, fmt.Errorf("UserRepo.SetUserFollower - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
 	sql, args, _ := r.Builder.
 		Insert("users_followers").
 		Columns("follower_id", "following_id").
 		Values(followerID, followingID).
+		Suffix("ON CONFLICT (follower_id, following_id) DO NOTHING").
 		ToSql()
 
-	_, err = tx.Exec(ctx, sql, args...)
+	tag, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
-		log.Errorf("UserRepo.SetUserFollower - tx.Exec: %v", err)
-		return fmt.Errorf("UserRepo.SetUserFollower - tx.Exec: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.SetUserFollower - tx.Exec: %v", err)
+		return false, fmt.Errorf("UserRepo.SetUserFollower - tx.Exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
 	}
 
-	sql, args, _ = r.Builder.
-		Update("users").
-		Set("followers_count", "followers_count + 1").
-		Where("id = ?", followingID).
-		ToSql()
-
-	_, err = tx.Exec(ctx, sql, args...)
+	err = changeCounter(ctx, tx, r.Builder, "users", "followers_count", followingID, 1)
 	if err != nil {
-		log.Errorf("UserRepo.SetUserFollower - tx.Exec: %v", err)
-		return fmt.Errorf("UserRepo.SetUserFollower - tx.Exec: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.SetUserFollower - changeCounter: %v", err)
+		return false, fmt.Errorf("UserRepo.SetUserFollower - changeCounter: %w", err)
 	}
 
-	sql, args, _ = r.Builder.
-		Update("users").
-		Set("following_count", "following_count + 1").
-		Where("id = ?", followerID).
-		ToSql()
+	err = changeCounter(ctx, tx, r.Builder, "users", "followings_count", followerID, 1)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.SetUserFollower - changeCounter: %v", err)
+		return false, fmt.Errorf("UserRepo.SetUserFollower - changeCounter: %w", err)
+	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	err = insertEvent(ctx, tx, r.Builder, entity.EventUserFollowed, followingID, entity.UserFollowedPayload{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+	})
 	if err != nil {
-		log.Errorf("UserRepo.SetUserFollower - tx.Exec: %v", err)
-		return fmt.Errorf("UserRepo.SetUserFollower - tx.Exec: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.SetUserFollower - insertEvent: %v", err)
+		return false, fmt.Errorf("UserRepo.SetUserFollower - insertEvent: %w", err)
 	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
-		log.Errorf("UserRepo.SetUserFollower - tx.Commit: %v", err)
-		return fmt.Errorf("UserRepo.SetUserFollower - tx.Commit: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.SetUserFollower - tx.Commit: %v", err)
+		return false, fmt.Errorf("UserRepo.SetUserFollower - tx.Commit: %w", err)
 	}
 
-	return nil
+	return true, nil
 }
 
 func (r *UserRepo) GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("u.*").
+		Select(prefixColumns("u", userColumns)...).
 		From("users_followers uf").
 		Join("users u ON u.id = uf.follower_id").
 		Where("uf.following_id = ?", userID).
+		Where("u.deleted_at IS NULL").
 		ToSql()
 
-	rows, err := r.Pool.Query(ctx, sql, args...)
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		log.Errorf("UserRepo.GetUserFollowers - r.Pool.Query: %v", err)
-		return nil, fmt.Errorf("UserRepo.GetUserFollowers - r.Pool.Query: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.GetUserFollowers - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("UserRepo.GetUserFollowers - r.Reader.Query: %w", err)
 	}
+	defer rows.Close()
 
 	var users []entity.User
 	for rows.Next() {
@@ -296,10 +405,14 @@ func (r *UserRepo) GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]en
 			&user.FavoritesCommentsCount,
 			&user.FollowersCount,
 			&user.FollowingCount,
+			&user.BannedAt,
+			&user.DeletionScheduledAt,
+			&user.SessionsRevokedAt,
+			&user.PasswordResetRequired,
 		)
 		if err != nil {
-			log.Errorf("UserRepo.GetUserFollowers - rows.Scan: %v", err)
-			return nil, fmt.Errorf("UserRepo.GetUserFollowers - rows.Scan: %v", err)
+			logger.FromContext(ctx).Errorf("UserRepo.GetUserFollowers - rows.Scan: %v", err)
+			return nil, fmt.Errorf("UserRepo.GetUserFollowers - rows.Scan: %w", err)
 		}
 
 		users = append(users, user)
@@ -310,17 +423,19 @@ func (r *UserRepo) GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]en
 
 func (r *UserRepo) GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]entity.User, error) {
 	sql, args, _ := r.Builder.
-		Select("u.*").
+		Select(prefixColumns("u", userColumns)...).
 		From("users_followers uf").
 		Join("users u ON u.id = uf.following_id").
 		Where("uf.follower_id = ?", userID).
+		Where("u.deleted_at IS NULL").
 		ToSql()
 
-	rows, err := r.Pool.Query(ctx, sql, args...)
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		log.Errorf("UserRepo.GetUserFollowings - r.Pool.Query: %v", err)
-		return nil, fmt.Errorf("UserRepo.GetUserFollowings - r.Pool.Query: %v", err)
+		logger.FromContext(ctx).Errorf("UserRepo.GetUserFollowings - r.Reader.Query: %v", err)
+		return nil, fmt.Errorf("UserRepo.GetUserFollowings - r.Reader.Query: %w", err)
 	}
+	defer rows.Close()
 
 	var users []entity.User
 	for rows.Next() {
@@ -341,10 +456,14 @@ func (r *UserRepo) GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]e
 			&user.FavoritesCommentsCount,
 			&user.FollowersCount,
 			&user.FollowingCount,
+			&user.BannedAt,
+			&user.DeletionScheduledAt,
+			&user.SessionsRevokedAt,
+			&user.PasswordResetRequired,
 		)
 		if err != nil {
-			log.Errorf("UserRepo.GetUserFollowings - rows.Scan: %v", err)
-			return nil, fmt.Errorf("UserRepo.GetUserFollowings - rows.Scan: %v", err)
+			logger.FromContext(ctx).Errorf("UserRepo.GetUserFollowings - rows.Scan: %v", err)
+			return nil, fmt.Errorf("UserRepo.GetUserFollowings - rows.Scan: %w", err)
 		}
 
 		users = append(users, user)
@@ -352,3 +471,175 @@ func (r *UserRepo) GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]e
 
 	return users, nil
 }
+
+// DeleteUserByID - мягкое удаление пользователя вместе с его статьями и комментариями.
+// Контент помечается тем же временем, что и пользователь, чтобы восстановить только его
+func (r *UserRepo) DeleteUserByID(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.DeleteUserByID - r.Begin: %v", err)
+		return fmt.Errorf("UserRepo.DeleteUserByID - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Update("users").
+		Set("deleted_at", squirrel.Expr("NOW()")).
+		Where("id = ?", userID).
+		Where("deleted_at IS NULL").
+		Suffix("RETURNING deleted_at").
+		ToSql()
+
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, sql, args...).Scan(&deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrUserNotFound
+		}
+		logger.FromContext(ctx).Errorf("UserRepo.DeleteUserByID - tx.QueryRow: %v", err)
+		return fmt.Errorf("UserRepo.DeleteUserByID - tx.QueryRow: %w", err)
+	}
+
+	// every removed article and comment gets its own event, webhook consumers don't know about users
+	_, err = updateArticles(ctx, tx, r.Builder, r.Builder.
+		Update("articles").
+		Set("deleted_at", deletedAt).
+		Where("author_id = ?", userID).
+		Where("deleted_at IS NULL"), entity.EventArticleDeleted)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.DeleteUserByID - updateArticles: %v", err)
+		return fmt.Errorf("UserRepo.DeleteUserByID - updateArticles: %w", err)
+	}
+
+	_, err = updateComments(ctx, tx, r.Builder, r.Builder.
+		Update("comments").
+		Set("deleted_at", deletedAt).
+		Where("author_id = ?", userID).
+		Where("deleted_at IS NULL"), entity.EventCommentDeleted)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.DeleteUserByID - updateComments: %v", err)
+		return fmt.Errorf("UserRepo.DeleteUserByID - updateComments: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.DeleteUserByID - tx.Commit: %v", err)
+		return fmt.Errorf("UserRepo.DeleteUserByID - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreUserByID - восстанавливает пользователя и контент, удаленный вместе с ним
+func (r *UserRepo) RestoreUserByID(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.RestoreUserByID - r.Begin: %v", err)
+		return fmt.Errorf("UserRepo.RestoreUserByID - r.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql, args, _ := r.Builder.
+		Select("deleted_at").
+		From("users").
+		Where("id = ?", userID).
+		Where("deleted_at IS NOT NULL").
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, sql, args...).Scan(&deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrUserNotFound
+		}
+		logger.FromContext(ctx).Errorf("UserRepo.RestoreUserByID - tx.QueryRow: %v", err)
+		return fmt.Errorf("UserRepo.RestoreUserByID - tx.QueryRow: %w", err)
+	}
+
+	sql, args, _ = r.Builder.
+		Update("users").
+		Set("deleted_at", nil).
+		Where("id = ?", userID).
+		ToSql()
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.RestoreUserByID - tx.Exec: %v", err)
+		return fmt.Errorf("UserRepo.RestoreUserByID - tx.Exec: %w", err)
+	}
+
+	_, err = updateArticles(ctx, tx, r.Builder, r.Builder.
+		Update("articles").
+		Set("deleted_at", nil).
+		Where("author_id = ?", userID).
+		Where("deleted_at = ?", deletedAt), entity.EventArticleRestored)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.RestoreUserByID - updateArticles: %v", err)
+		return fmt.Errorf("UserRepo.RestoreUserByID - updateArticles: %w", err)
+	}
+
+	_, err = updateComments(ctx, tx, r.Builder, r.Builder.
+		Update("comments").
+		Set("deleted_at", nil).
+		Where("author_id = ?", userID).
+		Where("deleted_at = ?", deletedAt), entity.EventCommentRestored)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.RestoreUserByID - updateComments: %v", err)
+		return fmt.Errorf("UserRepo.RestoreUserByID - updateComments: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.RestoreUserByID - tx.Commit: %v", err)
+		return fmt.Errorf("UserRepo.RestoreUserByID - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// ScheduleUserDeletion - аккаунт будет анонимизирован через gracePeriod, возвращает время удаления
+func (r *UserRepo) ScheduleUserDeletion(ctx context.Context, userID uuid.UUID, gracePeriod time.Duration) (time.Time, error) {
+	sql, args, _ := r.Builder.
+		Update("users").
+		Set("deletion_scheduled_at", squirrel.Expr("NOW() + make_interval(secs => ?)", gracePeriod.Seconds())).
+		Where("id = ?", userID).
+		Where("deleted_at IS NULL").
+		Where("deletion_scheduled_at IS NULL").
+		Suffix("RETURNING deletion_scheduled_at").
+		ToSql()
+
+	var scheduledAt time.Time
+	err := r.DB(ctx).QueryRow(ctx, sql, args...).Scan(&scheduledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, repoerrs.ErrUserNotFound
+		}
+		logger.FromContext(ctx).Errorf("UserRepo.ScheduleUserDeletion - r.DB.QueryRow: %v", err)
+		return time.Time{}, fmt.Errorf("UserRepo.ScheduleUserDeletion - r.DB.QueryRow: %w", err)
+	}
+
+	return scheduledAt, nil
+}
+
+// CancelUserDeletion - отмена возможна, пока аккаунт не анонимизирован
+func (r *UserRepo) CancelUserDeletion(ctx context.Context, userID uuid.UUID) error {
+	sql, args, _ := r.Builder.
+		Update("users").
+		Set("deletion_scheduled_at", nil).
+		Where("id = ?", userID).
+		Where("deletion_scheduled_at > NOW()").
+		ToSql()
+
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("UserRepo.CancelUserDeletion - r.DB.Exec: %v", err)
+		return fmt.Errorf("UserRepo.CancelUserDeletion - r.DB.Exec: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrUserNotFound
+	}
+
+	return nil
+}
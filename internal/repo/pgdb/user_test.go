@@ -0,0 +1,335 @@
+//go:build integration
+
+package pgdb_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/pgdb"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/testutil/pgtest"
+	"blog-backend/pkg/hasher"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+var passwordHash = hasher.NewSHA1Hasher(pgtest.Salt).Hash(pgtest.Password)
+
+func TestUserRepo_CreateUser(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	r := pgdb.NewUserRepo(pg)
+	ctx := context.Background()
+
+	id, err := r.CreateUser(ctx, entity.User{
+		Name:     "Dinah Cat",
+		Username: "dinah",
+		Password: passwordHash,
+		Email:    "dinah@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := r.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "dinah" || user.Role != entity.RoleUser {
+		t.Errorf("created user = %+v", user)
+	}
+	if got := countEvents(t, pg, entity.EventUserCreated, id); got != 1 {
+		t.Errorf("got %d user.created events, want 1", got)
+	}
+
+	_, err = r.CreateUser(ctx, entity.User{Name: "Alice", Username: "alice", Password: passwordHash, Email: "a@example.com"})
+	if err != repoerrs.ErrUserAlreadyExists {
+		t.Errorf("duplicate username: err = %v, want %v", err, repoerrs.ErrUserAlreadyExists)
+	}
+}
+
+func TestUserRepo_UpdateUserPassword(t *testing.T) {
+	r := pgdb.NewUserRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	newHash := hasher.NewSHA1Hasher(pgtest.Salt).Hash("New-pass1!")
+
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		oldPassword string
+		want        error
+	}{
+		{name: "wrong old password", userID: pgtest.AliceID, oldPassword: newHash, want: repoerrs.ErrUserNotFound},
+		{name: "deleted user", userID: pgtest.CarolID, oldPassword: passwordHash, want: repoerrs.ErrUserNotFound},
+		{name: "ok", userID: pgtest.AliceID, oldPassword: passwordHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.UpdateUserPassword(ctx, tt.userID, tt.oldPassword, newHash)
+			if err != tt.want {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	_, err := r.GetUserByUsernameAndPassword(ctx, "alice", newHash)
+	if err != nil {
+		t.Errorf("sign in with the new password: %v", err)
+	}
+}
+
+func TestUserRepo_UpdateUserByID(t *testing.T) {
+	r := pgdb.NewUserRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	name, description, role := "Alice Pleasance", "", entity.RoleModerator
+	err := r.UpdateUserByID(ctx, pgtest.AliceID, &name, nil, &description, &role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := r.GetUserByID(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != name || user.Description != description || user.Role != role {
+		t.Errorf("updated user = %+v", user)
+	}
+	if user.Email != "alice@example.com" {
+		t.Errorf("email is changed to %q, but it was not passed", user.Email)
+	}
+
+	err = r.UpdateUserByID(ctx, pgtest.CarolID, &name, nil, nil, nil)
+	if err != repoerrs.ErrUserNotFound {
+		t.Errorf("deleted user: err = %v, want %v", err, repoerrs.ErrUserNotFound)
+	}
+}
+
+func TestUserRepo_GetUser(t *testing.T) {
+	r := pgdb.NewUserRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		get    func() (entity.User, error)
+		wantID uuid.UUID
+		want   error
+	}{
+		{
+			name:   "by id",
+			get:    func() (entity.User, error) { return r.GetUserByID(ctx, pgtest.AliceID) },
+			wantID: pgtest.AliceID,
+		},
+		{
+			name: "by id, deleted",
+			get:  func() (entity.User, error) { return r.GetUserByID(ctx, pgtest.CarolID) },
+			want: repoerrs.ErrUserNotFound,
+		},
+		{
+			name: "by id, unknown",
+			get:  func() (entity.User, error) { return r.GetUserByID(ctx, uuid.New()) },
+			want: repoerrs.ErrUserNotFound,
+		},
+		{
+			name:   "by username",
+			get:    func() (entity.User, error) { return r.GetUserByUsername(ctx, "bobby") },
+			wantID: pgtest.BobbyID,
+		},
+		{
+			name: "by username, deleted",
+			get:  func() (entity.User, error) { return r.GetUserByUsername(ctx, "carol") },
+			want: repoerrs.ErrUserNotFound,
+		},
+		{
+			name:   "by username and password",
+			get:    func() (entity.User, error) { return r.GetUserByUsernameAndPassword(ctx, "alice", passwordHash) },
+			wantID: pgtest.AliceID,
+		},
+		{
+			name: "by username and wrong password",
+			get:  func() (entity.User, error) { return r.GetUserByUsernameAndPassword(ctx, "alice", "wrong") },
+			want: repoerrs.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := tt.get()
+			if err != tt.want {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if user.ID != tt.wantID {
+				t.Errorf("user id = %s, want %s", user.ID, tt.wantID)
+			}
+		})
+	}
+
+	user, err := r.GetUserByID(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ArticlesCount != 2 || user.FollowersCount != 1 || user.Description != "writes about rabbit holes" {
+		t.Errorf("user columns are scanned wrong: %+v", user)
+	}
+}
+
+func TestUserRepo_Followers(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	r := pgdb.NewUserRepo(pg)
+	ctx := context.Background()
+
+	_, err := r.SetUserFollower(ctx, pgtest.AdminID, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bobby already follows alice in the fixtures
+	inserted, err := r.SetUserFollower(ctx, pgtest.BobbyID, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted {
+		t.Error("existing follow is inserted again")
+	}
+
+	followers, err := r.GetUserFollowers(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameUsers(followers, pgtest.BobbyID, pgtest.AdminID) {
+		t.Errorf("followers of alice = %v", userIDs(followers))
+	}
+
+	followings, err := r.GetUserFollowings(ctx, pgtest.AdminID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameUsers(followings, pgtest.AliceID) {
+		t.Errorf("followings of admin = %v", userIDs(followings))
+	}
+
+	alice, _ := r.GetUserByID(ctx, pgtest.AliceID)
+	admin, _ := r.GetUserByID(ctx, pgtest.AdminID)
+	if alice.FollowersCount != 2 || admin.FollowingCount != 1 {
+		t.Errorf("counters: alice followers = %d, admin followings = %d", alice.FollowersCount, admin.FollowingCount)
+	}
+	if got := countEvents(t, pg, entity.EventUserFollowed, pgtest.AliceID); got != 1 {
+		t.Errorf("got %d user.followed events, want 1", got)
+	}
+
+	// deleted users are not listed
+	err = r.DeleteUserByID(ctx, pgtest.BobbyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	followers, err = r.GetUserFollowers(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameUsers(followers, pgtest.AdminID) {
+		t.Errorf("followers of alice after bobby is deleted = %v", userIDs(followers))
+	}
+}
+
+func TestUserRepo_DeleteAndRestore(t *testing.T) {
+	pg := pgtest.NewWithFixtures(t)
+	r := pgdb.NewUserRepo(pg)
+	articles := pgdb.NewArticleRepo(pg)
+	ctx := context.Background()
+
+	err := r.DeleteUserByID(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeleteUserByID(ctx, pgtest.AliceID); err != repoerrs.ErrUserNotFound {
+		t.Errorf("second delete: err = %v, want %v", err, repoerrs.ErrUserNotFound)
+	}
+	if _, err := articles.GetArticleByID(ctx, pgtest.AliceFirstArticleID); err != repoerrs.ErrArticleNotFound {
+		t.Errorf("article of a deleted user: err = %v, want %v", err, repoerrs.ErrArticleNotFound)
+	}
+
+	err = r.RestoreUserByID(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := articles.GetArticleByID(ctx, pgtest.AliceFirstArticleID); err != nil {
+		t.Errorf("article is not restored with the user: %v", err)
+	}
+	if err := r.RestoreUserByID(ctx, pgtest.AliceID); err != repoerrs.ErrUserNotFound {
+		t.Errorf("restore of an active user: err = %v, want %v", err, repoerrs.ErrUserNotFound)
+	}
+
+	// content deleted with the fixture user comes back too
+	err = r.RestoreUserByID(ctx, pgtest.CarolID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := articles.GetArticleByID(ctx, pgtest.CarolArticleID); err != nil {
+		t.Errorf("article is not restored with the user: %v", err)
+	}
+}
+
+func TestUserRepo_ScheduleAndCancelDeletion(t *testing.T) {
+	r := pgdb.NewUserRepo(pgtest.NewWithFixtures(t))
+	ctx := context.Background()
+
+	scheduledAt, err := r.ScheduleUserDeletion(ctx, pgtest.AliceID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(scheduledAt); until < 59*time.Minute || until > 61*time.Minute {
+		t.Errorf("deletion is scheduled in %s, want an hour", until)
+	}
+
+	_, err = r.ScheduleUserDeletion(ctx, pgtest.AliceID, time.Hour)
+	if err != repoerrs.ErrUserNotFound {
+		t.Errorf("second schedule: err = %v, want %v", err, repoerrs.ErrUserNotFound)
+	}
+
+	err = r.CancelUserDeletion(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := r.GetUserByID(ctx, pgtest.AliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DeletionScheduledAt != nil {
+		t.Errorf("deletion is still scheduled at %s", user.DeletionScheduledAt)
+	}
+
+	err = r.CancelUserDeletion(ctx, pgtest.BobbyID)
+	if !errors.Is(err, repoerrs.ErrUserNotFound) {
+		t.Errorf("cancel without schedule: err = %v, want %v", err, repoerrs.ErrUserNotFound)
+	}
+}
+
+func sameUsers(users []entity.User, ids ...uuid.UUID) bool {
+	if len(users) != len(ids) {
+		return false
+	}
+
+	want := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	for _, user := range users {
+		if !want[user.ID] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func userIDs(users []entity.User) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
@@ -0,0 +1,417 @@
+package pgdb
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"strings"
+	"time"
+)
+
+type WebhookRepo struct {
+	*postgres.Postgres
+}
+
+func NewWebhookRepo(pg *postgres.Postgres) *WebhookRepo {
+	return &WebhookRepo{pg}
+}
+
+var (
+	webhookColumns = []string{
+		"id", "owner_id", "url", "secret", "event_types", "is_global", "active", "created_at", "updated_at",
+	}
+	webhookDeliveryColumns = []string{
+		"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at",
+		"last_status_code", "last_error", "last_attempt_at", "delivered_at", "created_at",
+	}
+)
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook entity.Webhook) (uuid.UUID, error) {
+	sql, args, _ := r.Builder.
+		Insert("webhooks").
+		Columns("owner_id", "url", "secret", "event_types", "is_global").
+		Values(webhook.OwnerID, webhook.URL, webhook.Secret, eventTypesToStrings(webhook.EventTypes), webhook.IsGlobal).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id uuid.UUID
+	err := r.DB(ctx).QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.CreateWebhook - r.DB.QueryRow: %v", err)
+		return uuid.UUID{}, fmt.Errorf("WebhookRepo.CreateWebhook - r.DB.QueryRow: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *WebhookRepo) GetWebhookByID(ctx context.Context, id uuid.UUID) (entity.Webhook, error) {
+	sql, args, _ := r.Builder.
+		Select(webhookColumns...).
+		From("webhooks").
+		Where("id = ?", id).
+		ToSql()
+
+	webhook, err := scanWebhook(r.Reader(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Webhook{}, repoerrs.ErrWebhookNotFound
+		}
+		logger.FromContext(ctx).Errorf("WebhookRepo.GetWebhookByID - r.Reader.QueryRow: %v", err)
+		return entity.Webhook{}, fmt.Errorf("WebhookRepo.GetWebhookByID - r.Reader.QueryRow: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepo) GetWebhooksByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]entity.Webhook, error) {
+	sql, args, _ := r.Builder.
+		Select(webhookColumns...).
+		From("webhooks").
+		Where("owner_id = ?", ownerID).
+		OrderBy("created_at").
+		ToSql()
+
+	return r.queryWebhooks(ctx, "WebhookRepo.GetWebhooksByOwnerID", sql, args)
+}
+
+// GetActiveWebhooksForEvent - активные webhook'и, подписанные на eventType: глобальные и принадлежащие ownerIDs
+func (r *WebhookRepo) GetActiveWebhooksForEvent(ctx context.Context, eventType entity.EventType, ownerIDs []uuid.UUID) ([]entity.Webhook, error) {
+	sql, args, _ := r.Builder.
+		Select(webhookColumns...).
+		From("webhooks").
+		Where("active").
+		Where("(event_types = '{}' OR ? = ANY(event_types))", string(eventType)).
+		Where(squirrel.Or{
+			squirrel.Expr("is_global"),
+			squirrel.Eq{"owner_id": ownerIDs},
+		}).
+		// webhooks of deleted users stay until the purge but don't receive deliveries
+		Where("owner_id IN (SELECT id FROM users WHERE deleted_at IS NULL)").
+		ToSql()
+
+	return r.queryWebhooks(ctx, "WebhookRepo.GetActiveWebhooksForEvent", sql, args)
+}
+
+func (r *WebhookRepo) UpdateWebhookByID(ctx context.Context, id uuid.UUID, url *string, eventTypes *[]entity.EventType, active *bool) error {
+	sqlBuilder := r.Builder.
+		Update("webhooks").
+		Set("updated_at", squirrel.Expr("NOW()"))
+
+	if url != nil {
+		sqlBuilder = sqlBuilder.Set("url", *url)
+	}
+
+	if eventTypes != nil {
+		sqlBuilder = sqlBuilder.Set("event_types", eventTypesToStrings(*eventTypes))
+	}
+
+	if active != nil {
+		sqlBuilder = sqlBuilder.Set("active", *active)
+	}
+
+	sql, args, _ := sqlBuilder.
+		Where("id = ?", id).
+		ToSql()
+
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.UpdateWebhookByID - r.DB.Exec: %v", err)
+		return fmt.Errorf("WebhookRepo.UpdateWebhookByID - r.DB.Exec: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) DeleteWebhookByID(ctx context.Context, id uuid.UUID) error {
+	sql, args, _ := r.Builder.
+		Delete("webhooks").
+		Where("id = ?", id).
+		ToSql()
+
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.DeleteWebhookByID - r.DB.Exec: %v", err)
+		return fmt.Errorf("WebhookRepo.DeleteWebhookByID - r.DB.Exec: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// CreateDeliveries - повторная доставка того же события не создает новых записей
+func (r *WebhookRepo) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	sqlBuilder := r.Builder.
+		Insert("webhook_deliveries").
+		Columns("webhook_id", "event_id", "event_type", "payload")
+
+	for _, delivery := range deliveries {
+		sqlBuilder = sqlBuilder.Values(delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload)
+	}
+
+	sql, args, _ := sqlBuilder.
+		Suffix("ON CONFLICT (webhook_id, event_id) DO NOTHING").
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.CreateDeliveries - r.DB.Exec: %v", err)
+		return fmt.Errorf("WebhookRepo.CreateDeliveries - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// LockPendingDeliveries - захват пачки доставок на время lease, аналогично OutboxRepo.LockPendingEvents
+func (r *WebhookRepo) LockPendingDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	subQuery := r.Builder.
+		Select("id").
+		From("webhook_deliveries").
+		Where("status = ?", entity.WebhookDeliveryPending).
+		Where("next_attempt_at <= NOW()").
+		Where("(locked_until IS NULL OR locked_until < NOW())").
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, _ := r.Builder.
+		Update("webhook_deliveries").
+		Set("locked_until", squirrel.Expr("NOW() + make_interval(secs => ?)", lease.Seconds())).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("last_attempt_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Expr("id IN (?)", subQuery)).
+		Suffix("RETURNING " + strings.Join(webhookDeliveryColumns, ", ")).
+		ToSql()
+
+	return r.queryDeliveries(ctx, r.DB(ctx), "WebhookRepo.LockPendingDeliveries", sql, args)
+}
+
+func (r *WebhookRepo) MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, statusCode int) error {
+	sql, args, _ := r.Builder.
+		Update("webhook_deliveries").
+		Set("status", entity.WebhookDeliveryDelivered).
+		Set("delivered_at", squirrel.Expr("NOW()")).
+		Set("locked_until", nil).
+		Set("last_status_code", statusCode).
+		Set("last_error", nil).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.MarkDeliveryDelivered - r.DB.Exec: %v", err)
+		return fmt.Errorf("WebhookRepo.MarkDeliveryDelivered - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, statusCode *int, lastError string, retryIn time.Duration) error {
+	sql, args, _ := r.Builder.
+		Update("webhook_deliveries").
+		Set("next_attempt_at", squirrel.Expr("NOW() + make_interval(secs => ?)", retryIn.Seconds())).
+		Set("locked_until", nil).
+		Set("last_status_code", statusCode).
+		Set("last_error", lastError).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.MarkDeliveryFailed - r.DB.Exec: %v", err)
+		return fmt.Errorf("WebhookRepo.MarkDeliveryFailed - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) MarkDeliveryDead(ctx context.Context, id uuid.UUID, statusCode *int, lastError string) error {
+	sql, args, _ := r.Builder.
+		Update("webhook_deliveries").
+		Set("status", entity.WebhookDeliveryDead).
+		Set("locked_until", nil).
+		Set("last_status_code", statusCode).
+		Set("last_error", lastError).
+		Where("id = ?", id).
+		ToSql()
+
+	_, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.MarkDeliveryDead - r.DB.Exec: %v", err)
+		return fmt.Errorf("WebhookRepo.MarkDeliveryDead - r.DB.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) GetDeliveryByID(ctx context.Context, id uuid.UUID) (entity.WebhookDelivery, error) {
+	sql, args, _ := r.Builder.
+		Select(webhookDeliveryColumns...).
+		From("webhook_deliveries").
+		Where("id = ?", id).
+		ToSql()
+
+	delivery, err := scanWebhookDelivery(r.Reader(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.WebhookDelivery{}, repoerrs.ErrWebhookDeliveryNotFound
+		}
+		logger.FromContext(ctx).Errorf("WebhookRepo.GetDeliveryByID - r.Reader.QueryRow: %v", err)
+		return entity.WebhookDelivery{}, fmt.Errorf("WebhookRepo.GetDeliveryByID - r.Reader.QueryRow: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (r *WebhookRepo) GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]entity.WebhookDelivery, error) {
+	sql, args, _ := r.Builder.
+		Select(webhookDeliveryColumns...).
+		From("webhook_deliveries").
+		Where("webhook_id = ?", webhookID).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	return r.queryDeliveries(ctx, r.Reader(ctx), "WebhookRepo.GetDeliveriesByWebhookID", sql, args)
+}
+
+// ResetDelivery - постановка доставки в очередь заново, в том числе из dead
+func (r *WebhookRepo) ResetDelivery(ctx context.Context, id uuid.UUID) error {
+	sql, args, _ := r.Builder.
+		Update("webhook_deliveries").
+		Set("status", entity.WebhookDeliveryPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", squirrel.Expr("NOW()")).
+		Set("locked_until", nil).
+		Set("delivered_at", nil).
+		Where("id = ?", id).
+		ToSql()
+
+	res, err := r.DB(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("WebhookRepo.ResetDelivery - r.DB.Exec: %v", err)
+		return fmt.Errorf("WebhookRepo.ResetDelivery - r.DB.Exec: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return repoerrs.ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) queryWebhooks(ctx context.Context, method, sql string, args []interface{}) ([]entity.Webhook, error) {
+	rows, err := r.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - r.Reader.Query: %v", method, err)
+		return nil, fmt.Errorf("%s - r.Reader.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	var webhooks []entity.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("%s - rows.Scan: %v", method, err)
+			return nil, fmt.Errorf("%s - rows.Scan: %w", method, err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// queryDeliveries - db это r.DB для захвата доставок и r.Reader для чтения
+func (r *WebhookRepo) queryDeliveries(ctx context.Context, db postgres.Querier, method, sql string, args []interface{}) ([]entity.WebhookDelivery, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("%s - db.Query: %v", method, err)
+		return nil, fmt.Errorf("%s - db.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			logger.FromContext(ctx).Errorf("%s - rows.Scan: %v", method, err)
+			return nil, fmt.Errorf("%s - rows.Scan: %w", method, err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func scanWebhook(row pgx.Row) (entity.Webhook, error) {
+	var webhook entity.Webhook
+	var eventTypes []string
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.OwnerID,
+		&webhook.URL,
+		&webhook.Secret,
+		&eventTypes,
+		&webhook.IsGlobal,
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	for _, eventType := range eventTypes {
+		webhook.EventTypes = append(webhook.EventTypes, entity.EventType(eventType))
+	}
+
+	return webhook, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.LastAttemptAt,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+	return delivery, err
+}
+
+func eventTypesToStrings(eventTypes []entity.EventType) []string {
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		result = append(result, string(eventType))
+	}
+	return result
+}
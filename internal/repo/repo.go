@@ -2,12 +2,16 @@ package repo
 
 import (
 	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/memdb"
 	"blog-backend/internal/repo/pgdb"
 	"blog-backend/pkg/postgres"
 	"context"
 	"github.com/google/uuid"
+	"time"
 )
 
+//go:generate mockgen -source=repo.go -destination=mocks/repo.go -package=mocks
+
 type User interface {
 	CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error)
 	UpdateUserPassword(ctx context.Context, userID uuid.UUID, oldPassword, newPassword string) error
@@ -15,9 +19,14 @@ type User interface {
 	GetUserByUsernameAndPassword(ctx context.Context, username, password string) (entity.User, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (entity.User, error)
 	GetUserByUsername(ctx context.Context, username string) (entity.User, error)
-	SetUserFollower(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) error
+	GetUsersByIDs(ctx context.Context, userIDs []uuid.UUID) ([]entity.User, error)
+	SetUserFollower(ctx context.Context, followerID uuid.UUID, followingID uuid.UUID) (bool, error)
 	GetUserFollowers(ctx context.Context, userID uuid.UUID) ([]entity.User, error)
 	GetUserFollowings(ctx context.Context, userID uuid.UUID) ([]entity.User, error)
+	DeleteUserByID(ctx context.Context, userID uuid.UUID) error
+	RestoreUserByID(ctx context.Context, userID uuid.UUID) error
+	ScheduleUserDeletion(ctx context.Context, userID uuid.UUID, gracePeriod time.Duration) (time.Time, error)
+	CancelUserDeletion(ctx context.Context, userID uuid.UUID) error
 }
 
 type Article interface {
@@ -25,19 +34,152 @@ type Article interface {
 	GetArticleByID(ctx context.Context, id uuid.UUID) (entity.Article, error)
 	GetArticlesByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Article, error)
 	GetNewestArticles(ctx context.Context, limit, offset int) ([]entity.Article, error)
-	SetArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) error
+	SetArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) (bool, error)
 	RemoveArticleFavorite(ctx context.Context, userID uuid.UUID, articleID uuid.UUID) error
 	GetFavoriteArticles(ctx context.Context, userID uuid.UUID) ([]entity.Article, error)
+	GetFavoritedArticleIDs(ctx context.Context, userID uuid.UUID, articleIDs []uuid.UUID) ([]uuid.UUID, error)
+	GetFeedArticles(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Article, error)
+	SearchArticles(ctx context.Context, query string, limit, offset int) ([]entity.Article, error)
+	GetArticlesTags(ctx context.Context, articleIDs []uuid.UUID) (map[uuid.UUID][]entity.Tag, error)
+	UpdateArticleByID(ctx context.Context, articleID, editorID uuid.UUID, title, description, content *string) error
+	DeleteArticleByID(ctx context.Context, articleID uuid.UUID) error
+	RestoreArticleByID(ctx context.Context, articleID uuid.UUID) error
+}
+
+type Comment interface {
+	CreateComment(ctx context.Context, comment entity.Comment) (entity.Comment, error)
+	GetCommentByID(ctx context.Context, id uuid.UUID) (entity.Comment, error)
+	GetCommentsByArticleID(ctx context.Context, articleID uuid.UUID, limit, offset int) ([]entity.Comment, error)
+	UpdateCommentByID(ctx context.Context, commentID uuid.UUID, content string) error
+	DeleteCommentByID(ctx context.Context, commentID uuid.UUID) error
+	RestoreCommentByID(ctx context.Context, commentID uuid.UUID) error
+	GetCommentsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]entity.Comment, error)
+	GetFavoriteComments(ctx context.Context, userID uuid.UUID) ([]entity.Comment, error)
+}
+
+type Notification interface {
+	CreateNotification(ctx context.Context, notification entity.Notification) (entity.Notification, error)
+	GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Notification, error)
+}
+
+type Outbox interface {
+	LockPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.Event, error)
+	MarkEventPublished(ctx context.Context, id int64) error
+	MarkEventFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration) error
+	MarkEventDead(ctx context.Context, id int64, lastError string) error
+}
+
+type Webhook interface {
+	CreateWebhook(ctx context.Context, webhook entity.Webhook) (uuid.UUID, error)
+	GetWebhookByID(ctx context.Context, id uuid.UUID) (entity.Webhook, error)
+	GetWebhooksByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]entity.Webhook, error)
+	GetActiveWebhooksForEvent(ctx context.Context, eventType entity.EventType, ownerIDs []uuid.UUID) ([]entity.Webhook, error)
+	UpdateWebhookByID(ctx context.Context, id uuid.UUID, url *string, eventTypes *[]entity.EventType, active *bool) error
+	DeleteWebhookByID(ctx context.Context, id uuid.UUID) error
+	CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+	LockPendingDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	MarkDeliveryDelivered(ctx context.Context, id uuid.UUID, statusCode int) error
+	MarkDeliveryFailed(ctx context.Context, id uuid.UUID, statusCode *int, lastError string, retryIn time.Duration) error
+	MarkDeliveryDead(ctx context.Context, id uuid.UUID, statusCode *int, lastError string) error
+	GetDeliveryByID(ctx context.Context, id uuid.UUID) (entity.WebhookDelivery, error)
+	GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]entity.WebhookDelivery, error)
+	ResetDelivery(ctx context.Context, id uuid.UUID) error
+}
+
+type Moderation interface {
+	CreateReport(ctx context.Context, moderationCase entity.ModerationCase, report entity.Report) (entity.Report, error)
+	GetModerationCaseByID(ctx context.Context, id uuid.UUID) (entity.ModerationCase, error)
+	GetModerationCases(ctx context.Context, status *entity.ModerationStatus, targetType *entity.ReportTargetType, limit, offset int) ([]entity.ModerationCase, error)
+	GetReportsByCaseID(ctx context.Context, caseID uuid.UUID) ([]entity.Report, error)
+	ClaimModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID) error
+	ResolveModerationCase(ctx context.Context, moderationCase entity.ModerationCase, moderatorID uuid.UUID, action entity.ModerationAction, note string) error
+	GetModerationLog(ctx context.Context, moderatorID, caseID uuid.NullUUID, limit, offset int) ([]entity.ModerationLogEntry, error)
+}
+
+type Retention interface {
+	PurgeDeletedComments(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error)
+	PurgeDeletedArticles(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error)
+	PurgeDeletedUsers(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error)
+	AnonymizeScheduledUsers(ctx context.Context, limit int) (entity.Purged, error)
+}
+
+// Counter - сверка денормализованных счетчиков с исходными таблицами
+type Counter interface {
+	ReconcileCounters(ctx context.Context, fix bool) ([]entity.CounterDrift, error)
+}
+
+type Export interface {
+	CreateExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error)
+	GetExportByID(ctx context.Context, id uuid.UUID) (entity.UserExport, error)
+	GetPendingExport(ctx context.Context, userID uuid.UUID) (entity.UserExport, error)
+	GetExportArchive(ctx context.Context, id uuid.UUID) ([]byte, error)
+	LockPendingExports(ctx context.Context, limit int, lease time.Duration) ([]entity.UserExport, error)
+	MarkExportReady(ctx context.Context, id uuid.UUID, archive []byte, ttl time.Duration) error
+	MarkExportFailed(ctx context.Context, id uuid.UUID, lastError string, final bool) error
+	DeleteExpiredExports(ctx context.Context) (int, error)
+	GetUserVotes(ctx context.Context, userID uuid.UUID) ([]entity.Vote, error)
+}
+
+type Admin interface {
+	SearchUsers(ctx context.Context, filter entity.UserFilter) ([]entity.User, error)
+	SetUserRole(ctx context.Context, userID uuid.UUID, role entity.RoleType, entry entity.AuditEntry) error
+	SetUserBanned(ctx context.Context, userID uuid.UUID, banned bool, entry entity.AuditEntry) error
+	ResetUserPassword(ctx context.Context, userID uuid.UUID, password string, entry entity.AuditEntry) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID, entry entity.AuditEntry) error
+}
+
+type Audit interface {
+	CreateAuditEntry(ctx context.Context, entry entity.AuditEntry) error
+	GetAuditLog(ctx context.Context, filter entity.AuditFilter, limit, offset int) ([]entity.AuditEntry, error)
+	ExportAuditLog(ctx context.Context, filter entity.AuditFilter, fn func(entry entity.AuditEntry) error) error
 }
 
 type Repositories struct {
 	User
 	Article
+	Comment
+	Notification
+	Outbox
+	Webhook
+	Moderation
+	Retention
+	Counter
+	Export
+	Admin
+	Audit
 }
 
 func NewRepositories(pg *postgres.Postgres) *Repositories {
 	return &Repositories{
-		User:    pgdb.NewUserRepo(pg),
-		Article: pgdb.NewArticleRepo(pg),
+		User:         pgdb.NewUserRepo(pg),
+		Article:      pgdb.NewArticleRepo(pg),
+		Comment:      pgdb.NewCommentRepo(pg),
+		Notification: pgdb.NewNotificationRepo(pg),
+		Outbox:       pgdb.NewOutboxRepo(pg),
+		Webhook:      pgdb.NewWebhookRepo(pg),
+		Moderation:   pgdb.NewModerationRepo(pg),
+		Retention:    pgdb.NewRetentionRepo(pg),
+		Counter:      pgdb.NewCounterRepo(pg),
+		Export:       pgdb.NewExportRepo(pg),
+		Admin:        pgdb.NewAdminRepo(pg),
+		Audit:        pgdb.NewAuditRepo(pg),
+	}
+}
+
+// NewMemoryRepositories - репозитории в памяти процесса, данные теряются при остановке
+func NewMemoryRepositories(db *memdb.DB) *Repositories {
+	return &Repositories{
+		User:         memdb.NewUserRepo(db),
+		Article:      memdb.NewArticleRepo(db),
+		Comment:      memdb.NewCommentRepo(db),
+		Notification: memdb.NewNotificationRepo(db),
+		Outbox:       memdb.NewOutboxRepo(db),
+		Webhook:      memdb.NewWebhookRepo(db),
+		Moderation:   memdb.NewModerationRepo(db),
+		Retention:    memdb.NewRetentionRepo(db),
+		Counter:      memdb.NewCounterRepo(db),
+		Export:       memdb.NewExportRepo(db),
+		Admin:        memdb.NewAdminRepo(db),
+		Audit:        memdb.NewAuditRepo(db),
 	}
 }
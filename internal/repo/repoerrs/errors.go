@@ -5,4 +5,16 @@ import "errors"
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrArticleNotFound   = errors.New("article not found")
+	ErrCommentNotFound   = errors.New("comment not found")
+
+	ErrNotificationAlreadyExists = errors.New("notification already exists")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+	ErrReportAlreadyExists    = errors.New("report already exists")
+	ErrModerationCaseNotFound = errors.New("moderation case not found")
+
+	ErrExportNotFound = errors.New("export not found")
 )
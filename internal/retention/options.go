@@ -0,0 +1,23 @@
+package retention
+
+import "time"
+
+type Option func(*Purger)
+
+func Period(period time.Duration) Option {
+	return func(p *Purger) {
+		p.period = period
+	}
+}
+
+func Interval(interval time.Duration) Option {
+	return func(p *Purger) {
+		p.interval = interval
+	}
+}
+
+func BatchSize(size int) Option {
+	return func(p *Purger) {
+		p.batchSize = size
+	}
+}
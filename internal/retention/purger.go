@@ -0,0 +1,127 @@
+package retention
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"context"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	defaultPeriod    = 30 * 24 * time.Hour
+	defaultInterval  = time.Hour
+	defaultBatchSize = 100
+)
+
+// Purger - окончательное удаление пользователей, статей и комментариев, мягко удаленных раньше period назад.
+// Счетчики связанных записей уменьшаются в той же транзакции, что и удаление
+type Purger struct {
+	retentionRepo repo.Retention
+
+	period    time.Duration
+	interval  time.Duration
+	batchSize int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPurger(retentionRepo repo.Retention, opts ...Option) *Purger {
+	p := &Purger{
+		retentionRepo: retentionRepo,
+		period:        defaultPeriod,
+		interval:      defaultInterval,
+		batchSize:     defaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go p.run(ctx)
+
+	return p
+}
+
+func (p *Purger) Close() {
+	p.cancel()
+	<-p.done
+}
+
+func (p *Purger) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		err := p.Purge(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf("Purger.run - p.Purge: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge - анонимизация аккаунтов и удаление пачками, пока есть что удалять. Комментарии удаляются до статей,
+// статьи до пользователей, чтобы каскадное удаление не обходило пересчет счетчиков
+func (p *Purger) Purge(ctx context.Context) error {
+	// accounts whose deletion grace period is over, their content goes to the tombstone author
+	total := 0
+	for {
+		purged, err := p.retentionRepo.AnonymizeScheduledUsers(ctx, p.batchSize)
+		total += purged.Count
+		if err != nil {
+			return fmt.Errorf("anonymize users: %v", err)
+		}
+
+		if purged.Count < p.batchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Infof("Purger.Purge - anonymized %d users", total)
+	}
+
+	steps := []struct {
+		name  string
+		purge func(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error)
+	}{
+		{"comments", p.retentionRepo.PurgeDeletedComments},
+		{"articles", p.retentionRepo.PurgeDeletedArticles},
+		{"users", p.retentionRepo.PurgeDeletedUsers},
+	}
+
+	for _, step := range steps {
+		total = 0
+		for {
+			purged, err := step.purge(ctx, p.period, p.batchSize)
+			total += purged.Count
+			if err != nil {
+				return fmt.Errorf("purge %s: %v", step.name, err)
+			}
+
+			if purged.Count < p.batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		if total > 0 {
+			log.Infof("Purger.Purge - purged %d %s", total, step.name)
+		}
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,186 @@
+package retention_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/memdb"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/retention"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingRepo - repo.Retention, записывающий вызовы. Каждый шаг возвращает количества из counts по очереди, затем 0
+type recordingRepo struct {
+	mu     sync.Mutex
+	calls  []string
+	counts map[string][]int
+	fail   string
+}
+
+func (r *recordingRepo) step(name string) (entity.Purged, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, name)
+	if name == r.fail {
+		return entity.Purged{}, errors.New("repo failed")
+	}
+
+	if len(r.counts[name]) == 0 {
+		return entity.Purged{}, nil
+	}
+	n := r.counts[name][0]
+	r.counts[name] = r.counts[name][1:]
+	return entity.Purged{Count: n}, nil
+}
+
+func (r *recordingRepo) PurgeDeletedComments(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	return r.step("comments")
+}
+
+func (r *recordingRepo) PurgeDeletedArticles(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	return r.step("articles")
+}
+
+func (r *recordingRepo) PurgeDeletedUsers(ctx context.Context, olderThan time.Duration, limit int) (entity.Purged, error) {
+	return r.step("users")
+}
+
+func (r *recordingRepo) AnonymizeScheduledUsers(ctx context.Context, limit int) (entity.Purged, error) {
+	return r.step("anonymize")
+}
+
+// newPurger - purger без фонового цикла: первый проход run завершается до того, как тест настроит repo
+func newPurger(r *recordingRepo, opts ...retention.Option) *retention.Purger {
+	p := retention.NewPurger(r, append([]retention.Option{retention.Interval(time.Hour)}, opts...)...)
+	p.Close()
+
+	r.calls = nil
+	return p
+}
+
+func TestPurger_Order(t *testing.T) {
+	r := &recordingRepo{}
+	p := newPurger(r, retention.BatchSize(2))
+
+	// full batches are repeated until a partial one
+	r.counts = map[string][]int{
+		"anonymize": {2, 1},
+		"comments":  {2, 2, 0},
+		"users":     {1},
+	}
+
+	err := p.Purge(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"anonymize", "anonymize", "comments", "comments", "comments", "articles", "users"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %v, want %v", r.calls, want)
+	}
+}
+
+func TestPurger_Error(t *testing.T) {
+	r := &recordingRepo{}
+	p := newPurger(r)
+	r.fail = "articles"
+
+	err := p.Purge(context.Background())
+	if err == nil {
+		t.Fatal("error of the articles step is lost")
+	}
+
+	// users are not purged before their articles
+	want := []string{"anonymize", "comments", "articles"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %v, want %v", r.calls, want)
+	}
+}
+
+func createUser(t *testing.T, repos *repo.Repositories, username string) uuid.UUID {
+	t.Helper()
+
+	id, err := repos.CreateUser(context.Background(), entity.User{
+		Username: username,
+		Email:    fmt.Sprintf("%s@example.com", username),
+		Password: "password",
+		Role:     entity.RoleUser,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// content of the anonymized account stays with the tombstone author, deleted content is purged with its counters
+func TestPurger_Memory(t *testing.T) {
+	ctx := context.Background()
+	repos := repo.NewMemoryRepositories(memdb.New())
+
+	p := retention.NewPurger(repos, retention.Interval(time.Hour), retention.Period(-time.Minute))
+	p.Close()
+
+	alice := createUser(t, repos, "alice")
+	bob := createUser(t, repos, "bob")
+
+	articleID, err := repos.CreateArticle(ctx, entity.Article{AuthorID: alice, Title: "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment, err := repos.CreateComment(ctx, entity.Comment{AuthorID: bob, ArticleID: articleID, Content: "comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repos.ScheduleUserDeletion(ctx, alice, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repos.DeleteUserByID(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.Purge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article, err := repos.GetArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.AuthorID != entity.TombstoneUserID {
+		t.Errorf("article author = %s, want the tombstone author", article.AuthorID)
+	}
+
+	_, err = repos.GetUserByID(ctx, alice)
+	if !errors.Is(err, repoerrs.ErrUserNotFound) {
+		t.Errorf("anonymized user: err = %v, want %v", err, repoerrs.ErrUserNotFound)
+	}
+	// purged users can't be restored anymore
+	err = repos.RestoreUserByID(ctx, bob)
+	if !errors.Is(err, repoerrs.ErrUserNotFound) {
+		t.Errorf("restore purged user: err = %v, want %v", err, repoerrs.ErrUserNotFound)
+	}
+	_, err = repos.GetCommentByID(ctx, comment.Id)
+	if !errors.Is(err, repoerrs.ErrCommentNotFound) {
+		t.Errorf("comment of purged user: err = %v, want %v", err, repoerrs.ErrCommentNotFound)
+	}
+
+	drift, err := repos.ReconcileCounters(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("counters drifted after purge: %v", drift)
+	}
+}
@@ -0,0 +1,77 @@
+package pgtest
+
+import (
+	"blog-backend/pkg/postgres"
+	"context"
+	"embed"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+// Salt и Password - соль хешера и пароль всех пользователей из фикстур
+const (
+	Salt     = "test-salt"
+	Password = "Test-pass1!"
+)
+
+// идентификаторы записей из fixtures
+var (
+	AliceID = uuid.MustParse("a1000000-0000-4000-8000-000000000001")
+	BobbyID = uuid.MustParse("b0000000-0000-4000-8000-000000000002")
+	CarolID = uuid.MustParse("c0000000-0000-4000-8000-000000000003")
+	AdminID = uuid.MustParse("ad000000-0000-4000-8000-000000000004")
+
+	AliceFirstArticleID  = uuid.MustParse("a1a00000-0000-4000-8000-000000000001")
+	AliceSecondArticleID = uuid.MustParse("a1a00000-0000-4000-8000-000000000002")
+	BobbyHiddenArticleID = uuid.MustParse("b0a00000-0000-4000-8000-000000000001")
+	CarolArticleID       = uuid.MustParse("c0a00000-0000-4000-8000-000000000001")
+)
+
+// DefaultFixtures - все фикстуры в порядке, в котором их допускают внешние ключи
+var DefaultFixtures = []string{"users", "articles", "users_followers", "users_articles_favorites", "tags", "articles_tags"}
+
+//go:embed fixtures/*.yml
+var fixtures embed.FS
+
+// LoadFixtures - вставка строк из fixtures/<table>.yml, таблицы заполняются в переданном порядке.
+// Столбцы, которых нет в файле, получают значения по умолчанию
+func LoadFixtures(t *testing.T, pg *postgres.Postgres, tables ...string) {
+	t.Helper()
+
+	ctx := context.Background()
+	for _, table := range tables {
+		data, err := fixtures.ReadFile("fixtures/" + table + ".yml")
+		if err != nil {
+			t.Fatalf("pgtest: fixture %s: %v", table, err)
+		}
+
+		var rows []map[string]interface{}
+		err = yaml.Unmarshal(data, &rows)
+		if err != nil {
+			t.Fatalf("pgtest: fixture %s: %v", table, err)
+		}
+
+		for i, row := range rows {
+			sql, args, err := pg.Builder.Insert(table).SetMap(row).ToSql()
+			if err != nil {
+				t.Fatalf("pgtest: fixture %s row %d: %v", table, i, err)
+			}
+
+			_, err = pg.Pool.Exec(ctx, sql, args...)
+			if err != nil {
+				t.Fatalf("pgtest: fixture %s row %d: %v", table, i, err)
+			}
+		}
+	}
+}
+
+// NewWithFixtures - New с загруженными DefaultFixtures
+func NewWithFixtures(t *testing.T) *postgres.Postgres {
+	t.Helper()
+
+	pg := New(t)
+	LoadFixtures(t, pg, DefaultFixtures...)
+
+	return pg
+}
@@ -0,0 +1,216 @@
+// Package pgtest - Postgres для интеграционных тестов. Сервер запускается один раз на пакет тестов,
+// схема из migrations применяется к шаблонной базе, каждый тест получает свою копию шаблона.
+//
+// По умолчанию запускается embedded-postgres. Бинарники берутся из кэша (~/.embedded-postgres-go
+// или PGTEST_CACHE_PATH) и скачиваются, только если кэша нет, поэтому после первого запуска
+// тесты работают без сети. Вместо встроенного сервера можно указать готовый в PGTEST_URL.
+package pgtest
+
+import (
+	"blog-backend/migrations"
+	"blog-backend/pkg/postgres"
+	"context"
+	"errors"
+	"fmt"
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/golang-migrate/migrate/v4"
+	// migrate tools
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	envURL       = "PGTEST_URL"
+	envCachePath = "PGTEST_CACHE_PATH"
+
+	startTimeout = time.Minute
+	poolSize     = 4
+)
+
+// server - состояние, общее для тестов пакета, заполняется в Main
+var server struct {
+	url      string
+	template string
+}
+
+// Main - запуск Postgres и подготовка шаблонной базы, вызывается из TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(pgtest.Main(m)) }
+func Main(m *testing.M) int {
+	stop, err := start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pgtest: %v\n", err)
+		return 1
+	}
+	defer stop()
+
+	return m.Run()
+}
+
+// New - подключение к новой базе с примененными миграциями, база удаляется после теста
+func New(t *testing.T) *postgres.Postgres {
+	t.Helper()
+
+	if server.url == "" {
+		t.Fatal("pgtest: server is not started, call pgtest.Main from TestMain")
+	}
+
+	name := randomName("test")
+	err := exec(fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s", name, server.template))
+	if err != nil {
+		t.Fatalf("pgtest: create database: %v", err)
+	}
+
+	pg, err := postgres.New(databaseURL(server.url, name), postgres.MaxPoolSize(poolSize), postgres.ConnAttempts(1))
+	if err != nil {
+		t.Fatalf("pgtest: postgres.New: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pg.Close()
+		if err := exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", name)); err != nil {
+			t.Errorf("pgtest: drop database: %v", err)
+		}
+	})
+
+	return pg
+}
+
+// start - сервер из PGTEST_URL или встроенный, возвращает функцию остановки
+func start() (func(), error) {
+	stopServer := func() error { return nil }
+
+	server.url = os.Getenv(envURL)
+	if server.url == "" {
+		port, err := freePort()
+		if err != nil {
+			return nil, fmt.Errorf("free port: %w", err)
+		}
+
+		runtimePath, err := os.MkdirTemp("", "pgtest")
+		if err != nil {
+			return nil, fmt.Errorf("runtime path: %w", err)
+		}
+
+		config := embeddedpostgres.DefaultConfig().
+			Version(embeddedpostgres.V15).
+			Port(port).
+			RuntimePath(runtimePath).
+			StartTimeout(startTimeout).
+			Logger(io.Discard)
+		if cachePath := os.Getenv(envCachePath); cachePath != "" {
+			config = config.CachePath(cachePath)
+		}
+
+		db := embeddedpostgres.NewDatabase(config)
+		if err := db.Start(); err != nil {
+			_ = os.RemoveAll(runtimePath)
+			return nil, fmt.Errorf("embedded postgres: %w", err)
+		}
+
+		server.url = config.GetConnectionURL() + "?sslmode=disable"
+		stopServer = func() error {
+			defer func() { _ = os.RemoveAll(runtimePath) }()
+			return db.Stop()
+		}
+	}
+
+	// the name is unique, so packages may share a server from PGTEST_URL
+	server.template = randomName("template")
+	err := createTemplate()
+	if err != nil {
+		_ = stopServer()
+		return nil, err
+	}
+
+	return func() {
+		if err := exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", server.template)); err != nil {
+			fmt.Fprintf(os.Stderr, "pgtest: drop template: %v\n", err)
+		}
+		if err := stopServer(); err != nil {
+			fmt.Fprintf(os.Stderr, "pgtest: stop server: %v\n", err)
+		}
+	}, nil
+}
+
+func createTemplate() error {
+	err := exec(fmt.Sprintf("CREATE DATABASE %s", server.template))
+	if err != nil {
+		return fmt.Errorf("create template: %w", err)
+	}
+
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return fmt.Errorf("iofs.New: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, databaseURL(server.url, server.template))
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	err = m.Up()
+	// template is copied only when nobody is connected to it
+	sourceErr, dbErr := m.Close()
+	if err != nil {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+
+	return errors.Join(sourceErr, dbErr)
+}
+
+// exec - служебные запросы к базе сервера, CREATE DATABASE нельзя выполнить в транзакции пула
+func exec(sql string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, server.url)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close(ctx) }()
+
+	_, err = conn.Exec(ctx, sql)
+	return err
+}
+
+// databaseURL - адрес сервера с другой базой
+func databaseURL(serverURL, database string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return serverURL
+	}
+
+	u.Path = "/" + database
+
+	query := u.Query()
+	if query.Get("sslmode") == "" {
+		query.Set("sslmode", "disable")
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
+
+func randomName(prefix string) string {
+	return prefix + "_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = l.Close() }()
+
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
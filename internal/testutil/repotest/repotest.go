@@ -0,0 +1,1958 @@
+// Package repotest - общий набор тестов поведения репозиториев. Любая реализация repo.Repositories
+// должна его проходить, поэтому тесты создают данные только через интерфейсы репозиториев
+// и не полагаются на фикстуры конкретного хранилища:
+//
+//	func TestRepositories(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) (*repo.Repositories, repotest.TxManager) {
+//			db := memdb.New()
+//			return repo.NewMemoryRepositories(db), memdb.NewTxManager(db)
+//		})
+//	}
+package repotest
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// NewFunc - пустое хранилище для одного теста и менеджер транзакций над ним
+type NewFunc func(t *testing.T) (*repo.Repositories, TxManager)
+
+// TxManager - как usecase.TxManager: вызовы репозиториев с контекстом fn выполняются в одной транзакции
+type TxManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Run - запуск всех тестов набора, каждый тест получает свое хранилище
+func Run(t *testing.T, newRepos NewFunc) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r *repo.Repositories)
+	}{
+		{"User/Create", testUserCreate},
+		{"User/Update", testUserUpdate},
+		{"User/Follow", testUserFollow},
+		{"User/GetByIDs", testUserGetByIDs},
+		{"User/DeleteRestore", testUserDeleteRestore},
+		{"User/ScheduleDeletion", testUserScheduleDeletion},
+		{"Article/CreateGet", testArticleCreateGet},
+		{"Article/UpdateDelete", testArticleUpdateDelete},
+		{"Article/Favorites", testArticleFavorites},
+		{"Article/Feed", testArticleFeed},
+		{"Article/Search", testArticleSearch},
+		{"Comment", testComment},
+		{"Notification", testNotification},
+		{"Outbox", testOutbox},
+		{"Webhook", testWebhook},
+		{"Webhook/Deliveries", testWebhookDeliveries},
+		{"Moderation", testModeration},
+		{"Retention/Comments", testRetentionComments},
+		{"Retention/Articles", testRetentionArticles},
+		{"Retention/Users", testRetentionUsers},
+		{"Retention/Anonymize", testRetentionAnonymize},
+		{"Counters", testCounters},
+		{"Export", testExport},
+		{"Admin", testAdmin},
+		{"Audit", testAudit},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newRepos(t)
+			tt.fn(t, r)
+		})
+	}
+
+	t.Run("Tx", func(t *testing.T) {
+		testTx(t, newRepos)
+	})
+}
+
+func testUserCreate(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	id := createUser(t, r, "dinah")
+
+	user, err := r.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "dinah" || user.Email != "dinah@example.com" || user.Role != entity.RoleUser {
+		t.Errorf("created user = %+v", user)
+	}
+
+	byName, err := r.GetUserByUsername(ctx, "dinah")
+	if err != nil || byName.ID != id {
+		t.Errorf("GetUserByUsername = %v, %v, want %s", byName.ID, err, id)
+	}
+
+	signedIn, err := r.GetUserByUsernameAndPassword(ctx, "dinah", "hash-dinah")
+	if err != nil || signedIn.ID != id {
+		t.Errorf("GetUserByUsernameAndPassword = %v, %v, want %s", signedIn.ID, err, id)
+	}
+
+	_, err = r.GetUserByUsernameAndPassword(ctx, "dinah", "wrong")
+	expectErr(t, "wrong password", err, repoerrs.ErrUserNotFound)
+
+	_, err = r.GetUserByID(ctx, uuid.New())
+	expectErr(t, "unknown id", err, repoerrs.ErrUserNotFound)
+
+	_, err = r.CreateUser(ctx, entity.User{Name: "Other", Username: "dinah", Password: "hash", Email: "other@example.com"})
+	expectErr(t, "duplicate username", err, repoerrs.ErrUserAlreadyExists)
+
+	if got := pendingEvents(t, r, id)[entity.EventUserCreated]; got != 1 {
+		t.Errorf("got %d user.created events, want 1", got)
+	}
+}
+
+func testUserUpdate(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	id := createUser(t, r, "dinah")
+
+	name, description, role := "Dinah Cat", "purrs", entity.RoleModerator
+	err := r.UpdateUserByID(ctx, id, &name, nil, &description, &role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := getUser(t, r, id)
+	if user.Name != name || user.Email != "dinah@example.com" || user.Description != description || user.Role != role {
+		t.Errorf("updated user = %+v", user)
+	}
+
+	err = r.UpdateUserPassword(ctx, id, "wrong", "new-hash")
+	expectErr(t, "wrong old password", err, repoerrs.ErrUserNotFound)
+
+	err = r.UpdateUserPassword(ctx, id, "hash-dinah", "new-hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetUserByUsernameAndPassword(ctx, "dinah", "new-hash"); err != nil {
+		t.Errorf("sign in with the new password: %v", err)
+	}
+
+	err = r.UpdateUserByID(ctx, uuid.New(), &name, nil, nil, nil)
+	expectErr(t, "unknown user", err, repoerrs.ErrUserNotFound)
+}
+
+func testUserFollow(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+
+	inserted, err := r.SetUserFollower(ctx, alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inserted {
+		t.Error("first follow is not inserted")
+	}
+
+	// repeated and concurrent follows of the same pair are not stored or counted again
+	var wg sync.WaitGroup
+	var repeated atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inserted, err := r.SetUserFollower(ctx, alice, bob)
+			if err != nil {
+				t.Error(err)
+			}
+			if inserted {
+				repeated.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := repeated.Load(); n != 0 {
+		t.Errorf("%d repeated follows are inserted, want 0", n)
+	}
+
+	if got := getUser(t, r, bob).FollowersCount; got != 1 {
+		t.Errorf("followers of bob = %d, want 1", got)
+	}
+	if got := getUser(t, r, alice).FollowingCount; got != 1 {
+		t.Errorf("followings of alice = %d, want 1", got)
+	}
+
+	followers, err := r.GetUserFollowers(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(followers) != 1 || followers[0].ID != alice {
+		t.Errorf("followers of bob = %v, want alice", userIDs(followers))
+	}
+
+	followings, err := r.GetUserFollowings(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(followings) != 1 || followings[0].ID != bob {
+		t.Errorf("followings of alice = %v, want bob", userIDs(followings))
+	}
+
+	if got := pendingEvents(t, r, bob)[entity.EventUserFollowed]; got != 1 {
+		t.Errorf("got %d user.followed events, want 1", got)
+	}
+
+	// deleted users are not listed
+	err = r.DeleteUserByID(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	followers, err = r.GetUserFollowers(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(followers) != 0 {
+		t.Errorf("followers of bob after alice is deleted = %v, want none", userIDs(followers))
+	}
+}
+
+func testUserGetByIDs(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	carol := createUser(t, r, "carol")
+
+	err := r.DeleteUserByID(ctx, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// deleted and unknown users are skipped, repeated ids give one user
+	users, err := r.GetUsersByIDs(ctx, []uuid.UUID{bob, carol, uuid.New(), alice, bob})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameIDs(userIDs(users), []uuid.UUID{alice, bob}) {
+		t.Errorf("users = %v, want alice and bob", userIDs(users))
+	}
+
+	users, err = r.GetUsersByIDs(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Errorf("users without ids = %v, want none", userIDs(users))
+	}
+}
+
+func testUserDeleteRestore(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	articleID := createArticle(t, r, alice)
+	commentID := createComment(t, r, alice, articleID, uuid.NullUUID{})
+
+	err := r.DeleteUserByID(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.GetUserByID(ctx, alice)
+	expectErr(t, "get deleted user", err, repoerrs.ErrUserNotFound)
+	_, err = r.GetArticleByID(ctx, articleID)
+	expectErr(t, "get article of deleted user", err, repoerrs.ErrArticleNotFound)
+	_, err = r.GetCommentByID(ctx, commentID)
+	expectErr(t, "get comment of deleted user", err, repoerrs.ErrCommentNotFound)
+
+	err = r.DeleteUserByID(ctx, alice)
+	expectErr(t, "delete twice", err, repoerrs.ErrUserNotFound)
+
+	// username stays taken while the user can be restored
+	_, err = r.CreateUser(ctx, entity.User{Name: "Alice", Username: "alice", Password: "hash", Email: "a@example.com"})
+	expectErr(t, "username of deleted user", err, repoerrs.ErrUserAlreadyExists)
+
+	err = r.RestoreUserByID(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getUser(t, r, alice)
+	if _, err := r.GetArticleByID(ctx, articleID); err != nil {
+		t.Errorf("article is not restored with the user: %v", err)
+	}
+	if _, err := r.GetCommentByID(ctx, commentID); err != nil {
+		t.Errorf("comment is not restored with the user: %v", err)
+	}
+
+	// the content deleted and restored with the user is announced article by article
+	events := pendingEvents(t, r, articleID, commentID)
+	for _, eventType := range []entity.EventType{
+		entity.EventArticleDeleted, entity.EventArticleRestored, entity.EventCommentDeleted, entity.EventCommentRestored,
+	} {
+		if events[eventType] != 1 {
+			t.Errorf("got %d %s events, want 1", events[eventType], eventType)
+		}
+	}
+
+	err = r.RestoreUserByID(ctx, alice)
+	expectErr(t, "restore active user", err, repoerrs.ErrUserNotFound)
+}
+
+func testUserScheduleDeletion(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+
+	scheduledAt, err := r.ScheduleUserDeletion(ctx, alice, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until := time.Until(scheduledAt); until < 59*time.Minute || until > 61*time.Minute {
+		t.Errorf("deletion is scheduled in %s, want an hour", until)
+	}
+
+	_, err = r.ScheduleUserDeletion(ctx, alice, time.Hour)
+	expectErr(t, "schedule twice", err, repoerrs.ErrUserNotFound)
+
+	err = r.CancelUserDeletion(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user := getUser(t, r, alice); user.DeletionScheduledAt != nil {
+		t.Errorf("deletion is still scheduled at %s", user.DeletionScheduledAt)
+	}
+
+	err = r.CancelUserDeletion(ctx, alice)
+	expectErr(t, "cancel twice", err, repoerrs.ErrUserNotFound)
+}
+
+func testArticleCreateGet(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	first := createArticle(t, r, alice)
+	second := createArticle(t, r, alice)
+
+	article, err := r.GetArticleByID(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.AuthorID != alice || article.Title != "Title" || article.Content != "Content" {
+		t.Errorf("created article = %+v", article)
+	}
+
+	_, err = r.GetArticleByID(ctx, uuid.New())
+	expectErr(t, "unknown article", err, repoerrs.ErrArticleNotFound)
+
+	byAuthor, err := r.GetArticlesByAuthorID(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byAuthor) != 2 {
+		t.Errorf("got %d articles of alice, want 2", len(byAuthor))
+	}
+
+	newest, err := r.GetNewestArticles(ctx, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newest) != 1 || newest[0].Id != second {
+		t.Errorf("newest article = %v, want %s", articleIDs(newest), second)
+	}
+
+	newest, err = r.GetNewestArticles(ctx, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(newest) != 1 || newest[0].Id != first {
+		t.Errorf("second page = %v, want %s", articleIDs(newest), first)
+	}
+
+	if got := pendingEvents(t, r, first)[entity.EventArticleCreated]; got != 1 {
+		t.Errorf("got %d article.created events, want 1", got)
+	}
+}
+
+func testArticleUpdateDelete(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	articleID := createArticle(t, r, alice)
+
+	title := "New title"
+	err := r.UpdateArticleByID(ctx, articleID, alice, &title, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article, err := r.GetArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.Title != title || article.Description != "Description" {
+		t.Errorf("updated article = %+v", article)
+	}
+	if got := pendingEvents(t, r, articleID)[entity.EventArticleUpdated]; got != 1 {
+		t.Errorf("got %d article.updated events, want 1", got)
+	}
+
+	err = r.DeleteArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.GetArticleByID(ctx, articleID)
+	expectErr(t, "get deleted article", err, repoerrs.ErrArticleNotFound)
+
+	err = r.UpdateArticleByID(ctx, articleID, alice, &title, nil, nil)
+	expectErr(t, "update deleted article", err, repoerrs.ErrArticleNotFound)
+
+	err = r.DeleteArticleByID(ctx, articleID)
+	expectErr(t, "delete twice", err, repoerrs.ErrArticleNotFound)
+
+	err = r.RestoreArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetArticleByID(ctx, articleID); err != nil {
+		t.Errorf("restored article: %v", err)
+	}
+
+	err = r.RestoreArticleByID(ctx, articleID)
+	expectErr(t, "restore active article", err, repoerrs.ErrArticleNotFound)
+
+	// failed calls don't produce events
+	events := pendingEvents(t, r, articleID)
+	if events[entity.EventArticleDeleted] != 1 || events[entity.EventArticleRestored] != 1 {
+		t.Errorf("events = %v, want one article.deleted and one article.restored", events)
+	}
+}
+
+func testArticleFavorites(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	articleID := createArticle(t, r, alice)
+
+	inserted, err := r.SetArticleFavorite(ctx, bob, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inserted {
+		t.Error("first favorite is not inserted")
+	}
+
+	// repeated and concurrent favorites of the same pair are not stored or counted again
+	var wg sync.WaitGroup
+	var repeated atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inserted, err := r.SetArticleFavorite(ctx, bob, articleID)
+			if err != nil {
+				t.Error(err)
+			}
+			if inserted {
+				repeated.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := repeated.Load(); n != 0 {
+		t.Errorf("%d repeated favorites are inserted, want 0", n)
+	}
+
+	if got := getArticle(t, r, articleID).FavoritesCount; got != 1 {
+		t.Errorf("favorites of the article = %d, want 1", got)
+	}
+	if got := getUser(t, r, bob).FavoritesArticlesCount; got != 1 {
+		t.Errorf("favorite articles of bob = %d, want 1", got)
+	}
+
+	favorites, err := r.GetFavoriteArticles(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites) != 1 || favorites[0].Id != articleID {
+		t.Errorf("favorites of bob = %v, want %s", articleIDs(favorites), articleID)
+	}
+
+	err = r.RemoveArticleFavorite(ctx, bob, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// removing a missing favorite is not an error and is not an event
+	err = r.RemoveArticleFavorite(ctx, bob, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	favorites, err = r.GetFavoriteArticles(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites) != 0 {
+		t.Errorf("favorites of bob after removal = %v, want none", articleIDs(favorites))
+	}
+
+	events := pendingEvents(t, r, articleID)
+	if got := events[entity.EventArticleFavorited]; got != 1 {
+		t.Errorf("got %d article.favorited events, want 1", got)
+	}
+	if got := events[entity.EventArticleUnfavorited]; got != 1 {
+		t.Errorf("got %d article.unfavorited events, want 1", got)
+	}
+
+	// a removed favorite can be added again, other articles and users are not mixed in
+	otherID := createArticle(t, r, alice)
+	inserted, err = r.SetArticleFavorite(ctx, bob, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inserted {
+		t.Error("favorite added after removal is not inserted")
+	}
+	_, err = r.SetArticleFavorite(ctx, alice, otherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	favorited, err := r.GetFavoritedArticleIDs(ctx, bob, []uuid.UUID{articleID, otherID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorited) != 1 || favorited[0] != articleID {
+		t.Errorf("favorited by bob = %v, want %s", favorited, articleID)
+	}
+}
+
+func testArticleFeed(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	carol := createUser(t, r, "carol")
+
+	first := createArticle(t, r, bob)
+	second := createArticle(t, r, bob)
+	createArticle(t, r, carol)
+	createArticle(t, r, alice)
+
+	_, err := r.SetUserFollower(ctx, alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := r.GetFeedArticles(ctx, alice, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := articleIDs(feed), []uuid.UUID{second, first}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("feed of alice = %v, want %v", got, want)
+	}
+
+	feed, err = r.GetFeedArticles(ctx, alice, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed) != 1 || feed[0].Id != first {
+		t.Errorf("second page of the feed = %v, want %s", articleIDs(feed), first)
+	}
+
+	// deleted articles leave the feed
+	err = r.DeleteArticleByID(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed, err = r.GetFeedArticles(ctx, alice, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed) != 1 || feed[0].Id != first {
+		t.Errorf("feed after deletion = %v, want %s", articleIDs(feed), first)
+	}
+
+	feed, err = r.GetFeedArticles(ctx, carol, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed) != 0 {
+		t.Errorf("feed without followings = %v, want none", articleIDs(feed))
+	}
+}
+
+func testArticleSearch(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+
+	var ids []uuid.UUID
+	for _, article := range []entity.Article{
+		{AuthorID: alice, Title: "Go generics", Description: "type parameters", Content: "content"},
+		{AuthorID: alice, Title: "Postgres", Description: "indexes in GO services", Content: "content"},
+		{AuthorID: alice, Title: "Rust", Description: "borrow checker", Content: "go"},
+		{AuthorID: alice, Title: "100% coverage", Description: "tests", Content: "content"},
+	} {
+		id, err := r.CreateArticle(ctx, article)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	// title and description match regardless of case, content doesn't
+	found, err := r.SearchArticles(ctx, "go", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := articleIDs(found), []uuid.UUID{ids[1], ids[0]}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("found = %v, want %v", got, want)
+	}
+
+	// LIKE wildcards in the query are literal
+	found, err = r.SearchArticles(ctx, "0%", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Id != ids[3] {
+		t.Errorf("found by 0%% = %v, want %s", articleIDs(found), ids[3])
+	}
+	found, err = r.SearchArticles(ctx, "_o", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("found by _o = %v, want none", articleIDs(found))
+	}
+}
+
+func testComment(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	articleID := createArticle(t, r, alice)
+
+	commentID := createComment(t, r, bob, articleID, uuid.NullUUID{})
+	replyID := createComment(t, r, alice, articleID, uuid.NullUUID{UUID: commentID, Valid: true})
+
+	if got := getArticle(t, r, articleID).CommentsCount; got != 2 {
+		t.Errorf("comments of the article = %d, want 2", got)
+	}
+	if got := getUser(t, r, bob).CommentsCount; got != 1 {
+		t.Errorf("comments of bob = %d, want 1", got)
+	}
+	if got := pendingEvents(t, r, replyID)[entity.EventCommentPosted]; got != 1 {
+		t.Errorf("got %d comment.posted events, want 1", got)
+	}
+
+	comments, err := r.GetCommentsByArticleID(ctx, articleID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || comments[0].Id != commentID || comments[1].Id != replyID {
+		t.Errorf("comments of the article = %v, want %s, %s", commentIDs(comments), commentID, replyID)
+	}
+
+	err = r.UpdateCommentByID(ctx, commentID, "Edited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment, err := r.GetCommentByID(ctx, commentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.Content != "Edited" || comment.AuthorID != bob {
+		t.Errorf("updated comment = %+v", comment)
+	}
+
+	byAuthor, err := r.GetCommentsByAuthorID(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byAuthor) != 1 || byAuthor[0].Id != commentID {
+		t.Errorf("comments of bob = %v, want %s", commentIDs(byAuthor), commentID)
+	}
+
+	err = r.DeleteCommentByID(ctx, commentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.GetCommentByID(ctx, commentID)
+	expectErr(t, "get deleted comment", err, repoerrs.ErrCommentNotFound)
+	// replies stay visible
+	if _, err := r.GetCommentByID(ctx, replyID); err != nil {
+		t.Errorf("reply to the deleted comment: %v", err)
+	}
+
+	err = r.UpdateCommentByID(ctx, commentID, "Edited")
+	expectErr(t, "update deleted comment", err, repoerrs.ErrCommentNotFound)
+
+	err = r.RestoreCommentByID(ctx, commentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RestoreCommentByID(ctx, commentID)
+	expectErr(t, "restore active comment", err, repoerrs.ErrCommentNotFound)
+
+	events := pendingEvents(t, r, commentID)
+	if events[entity.EventCommentDeleted] != 1 || events[entity.EventCommentRestored] != 1 {
+		t.Errorf("events = %v, want one comment.deleted and one comment.restored", events)
+	}
+}
+
+func testNotification(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	articleID := createArticle(t, r, alice)
+	commentID := createComment(t, r, bob, articleID, uuid.NullUUID{})
+
+	notification := entity.Notification{
+		UserID:    alice,
+		ActorID:   bob,
+		Type:      entity.NotificationArticleComment,
+		ArticleID: uuid.NullUUID{UUID: articleID, Valid: true},
+		CommentID: uuid.NullUUID{UUID: commentID, Valid: true},
+	}
+
+	created, err := r.CreateNotification(ctx, notification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == uuid.Nil || created.CreatedAt.IsZero() {
+		t.Errorf("created notification = %+v", created)
+	}
+
+	_, err = r.CreateNotification(ctx, notification)
+	expectErr(t, "duplicate notification", err, repoerrs.ErrNotificationAlreadyExists)
+
+	// notifications without a comment are deduplicated too
+	follow := entity.Notification{UserID: alice, ActorID: bob, Type: entity.NotificationNewFollower}
+	_, err = r.CreateNotification(ctx, follow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.CreateNotification(ctx, follow)
+	expectErr(t, "duplicate follow notification", err, repoerrs.ErrNotificationAlreadyExists)
+
+	favorite := entity.Notification{
+		UserID:    alice,
+		ActorID:   bob,
+		Type:      entity.NotificationArticleFavorite,
+		ArticleID: uuid.NullUUID{UUID: articleID, Valid: true},
+	}
+	_, err = r.CreateNotification(ctx, favorite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.CreateNotification(ctx, favorite)
+	expectErr(t, "duplicate favorite notification", err, repoerrs.ErrNotificationAlreadyExists)
+
+	notifications, err := r.GetNotificationsByUserID(ctx, alice, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 3 {
+		t.Fatalf("notifications of alice = %+v, want 3", notifications)
+	}
+	for _, n := range notifications {
+		if n.ID == created.ID && n.CommentID != notification.CommentID {
+			t.Errorf("comment notification of alice = %+v", n)
+		}
+	}
+
+	notifications, err = r.GetNotificationsByUserID(ctx, bob, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 0 {
+		t.Errorf("notifications of bob = %+v, want none", notifications)
+	}
+}
+
+func testOutbox(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	createUser(t, r, "bobby")
+	createUser(t, r, "carol")
+
+	events, err := r.LockPendingEvents(ctx, 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].AggregateID != alice || events[0].Type != entity.EventUserCreated || events[0].Attempts != 1 {
+		t.Fatalf("locked events = %+v", events)
+	}
+
+	// locked events are skipped until the lease expires
+	rest, err := r.LockPendingEvents(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 1 || rest[0].ID == events[0].ID || rest[0].ID == events[1].ID {
+		t.Fatalf("events locked second = %+v", rest)
+	}
+
+	err = r.MarkEventPublished(ctx, events[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.MarkEventFailed(ctx, events[1].ID, "timeout", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.MarkEventDead(ctx, rest[0].ID, "rejected")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the failed event is retried
+	retried, err := r.LockPendingEvents(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retried) != 1 || retried[0].ID != events[1].ID || retried[0].Attempts != 2 {
+		t.Errorf("retried events = %+v", retried)
+	}
+
+	// unknown events are ignored
+	if err := r.MarkEventPublished(ctx, -1); err != nil {
+		t.Errorf("MarkEventPublished of unknown event: %v", err)
+	}
+}
+
+func testWebhook(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+
+	all := createWebhook(t, r, entity.Webhook{OwnerID: alice, URL: "https://alice.example.com"})
+	created := createWebhook(t, r, entity.Webhook{
+		OwnerID:    bob,
+		URL:        "https://bob.example.com",
+		EventTypes: []entity.EventType{entity.EventArticleCreated},
+	})
+	global := createWebhook(t, r, entity.Webhook{OwnerID: bob, URL: "https://global.example.com", IsGlobal: true})
+
+	webhook, err := r.GetWebhookByID(ctx, created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !webhook.Active || webhook.OwnerID != bob || len(webhook.EventTypes) != 1 || webhook.EventTypes[0] != entity.EventArticleCreated {
+		t.Errorf("created webhook = %+v", webhook)
+	}
+
+	owned, err := r.GetWebhooksByOwnerID(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owned) != 2 || owned[0].ID != created || owned[1].ID != global {
+		t.Errorf("webhooks of bob = %v, want %s, %s", webhookIDs(owned), created, global)
+	}
+
+	tests := []struct {
+		eventType entity.EventType
+		owners    []uuid.UUID
+		want      []uuid.UUID
+	}{
+		{entity.EventArticleCreated, []uuid.UUID{alice}, []uuid.UUID{all, global}},
+		{entity.EventArticleCreated, []uuid.UUID{bob}, []uuid.UUID{created, global}},
+		{entity.EventCommentPosted, []uuid.UUID{alice, bob}, []uuid.UUID{all, global}},
+		{entity.EventCommentPosted, nil, []uuid.UUID{global}},
+	}
+	for _, tt := range tests {
+		webhooks, err := r.GetActiveWebhooksForEvent(ctx, tt.eventType, tt.owners)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the order of webhooks for an event is not defined
+		if got := webhookIDs(webhooks); !sameIDs(got, tt.want) {
+			t.Errorf("webhooks for %s of %v = %v, want %v", tt.eventType, tt.owners, got, tt.want)
+		}
+	}
+
+	inactive := false
+	err = r.UpdateWebhookByID(ctx, global, nil, nil, &inactive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhooks, err := r.GetActiveWebhooksForEvent(ctx, entity.EventCommentPosted, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("webhooks after the global one is deactivated = %v, want none", webhookIDs(webhooks))
+	}
+
+	err = r.DeleteWebhookByID(ctx, created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.GetWebhookByID(ctx, created)
+	expectErr(t, "get deleted webhook", err, repoerrs.ErrWebhookNotFound)
+
+	err = r.DeleteWebhookByID(ctx, created)
+	expectErr(t, "delete twice", err, repoerrs.ErrWebhookNotFound)
+
+	err = r.UpdateWebhookByID(ctx, created, nil, nil, &inactive)
+	expectErr(t, "update deleted webhook", err, repoerrs.ErrWebhookNotFound)
+}
+
+func testWebhookDeliveries(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	webhookID := createWebhook(t, r, entity.Webhook{OwnerID: alice, URL: "https://alice.example.com"})
+
+	deliveries := []entity.WebhookDelivery{
+		{WebhookID: webhookID, EventID: 1, EventType: entity.EventArticleCreated, Payload: json.RawMessage(`{"n":1}`)},
+		{WebhookID: webhookID, EventID: 2, EventType: entity.EventArticleCreated, Payload: json.RawMessage(`{"n":2}`)},
+	}
+	err := r.CreateDeliveries(ctx, deliveries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same events are delivered once
+	err = r.CreateDeliveries(ctx, deliveries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked, err := r.LockPendingDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locked) != 2 || locked[0].Attempts != 1 || locked[0].LastAttemptAt == nil {
+		t.Fatalf("locked deliveries = %+v", locked)
+	}
+
+	again, err := r.LockPendingDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Errorf("deliveries locked twice = %+v", again)
+	}
+
+	delivered, failed := locked[0].ID, locked[1].ID
+
+	err = r.MarkDeliveryDelivered(ctx, delivered, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery, err := r.GetDeliveryByID(ctx, delivered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != entity.WebhookDeliveryDelivered || delivery.DeliveredAt == nil || *delivery.LastStatusCode != 200 {
+		t.Errorf("delivered delivery = %+v", delivery)
+	}
+
+	statusCode := 500
+	err = r.MarkDeliveryFailed(ctx, failed, &statusCode, "server error", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retried, err := r.LockPendingDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retried) != 1 || retried[0].ID != failed || retried[0].Attempts != 2 || *retried[0].LastError != "server error" {
+		t.Fatalf("retried deliveries = %+v", retried)
+	}
+
+	err = r.MarkDeliveryDead(ctx, failed, nil, "gave up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery, _ := r.GetDeliveryByID(ctx, failed); delivery.Status != entity.WebhookDeliveryDead {
+		t.Errorf("status of dead delivery = %s", delivery.Status)
+	}
+
+	err = r.ResetDelivery(ctx, failed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery, err = r.GetDeliveryByID(ctx, failed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != entity.WebhookDeliveryPending || delivery.Attempts != 0 {
+		t.Errorf("reset delivery = %+v", delivery)
+	}
+
+	err = r.ResetDelivery(ctx, uuid.New())
+	expectErr(t, "reset unknown delivery", err, repoerrs.ErrWebhookDeliveryNotFound)
+
+	listed, err := r.GetDeliveriesByWebhookID(ctx, webhookID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 2 {
+		t.Errorf("got %d deliveries of the webhook, want 2", len(listed))
+	}
+
+	// deliveries are removed with the webhook
+	err = r.DeleteWebhookByID(ctx, webhookID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.GetDeliveryByID(ctx, delivered)
+	expectErr(t, "delivery of deleted webhook", err, repoerrs.ErrWebhookDeliveryNotFound)
+}
+
+func testModeration(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	carol := createUser(t, r, "carol")
+	moderator := createUser(t, r, "moderator")
+	articleID := createArticle(t, r, alice)
+
+	target := entity.ModerationCase{TargetType: entity.ReportTargetArticle, TargetID: articleID, TargetAuthorID: alice}
+
+	first, err := r.CreateReport(ctx, target, entity.Report{ReporterID: bob, Reason: entity.ReportReasonSpam})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.CreateReport(ctx, target, entity.Report{ReporterID: carol, Reason: entity.ReportReasonOther, Comment: "ads"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.CaseID != second.CaseID {
+		t.Errorf("reports of the same target are in cases %s and %s", first.CaseID, second.CaseID)
+	}
+
+	_, err = r.CreateReport(ctx, target, entity.Report{ReporterID: bob, Reason: entity.ReportReasonSpam})
+	expectErr(t, "second report of bob", err, repoerrs.ErrReportAlreadyExists)
+
+	moderationCase, err := r.GetModerationCaseByID(ctx, first.CaseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moderationCase.Status != entity.ModerationStatusOpen || moderationCase.ReportsCount != 2 {
+		t.Errorf("case = %+v", moderationCase)
+	}
+
+	reports, err := r.GetReportsByCaseID(ctx, moderationCase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports[0].ID != first.ID || reports[1].Comment != "ads" {
+		t.Errorf("reports = %+v", reports)
+	}
+
+	open := entity.ModerationStatusOpen
+	cases, err := r.GetModerationCases(ctx, &open, nil, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 1 || cases[0].ID != moderationCase.ID {
+		t.Errorf("open cases = %+v", cases)
+	}
+
+	err = r.ClaimModerationCase(ctx, moderationCase, moderator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ClaimModerationCase(ctx, moderationCase, moderator)
+	expectErr(t, "claim twice", err, repoerrs.ErrModerationCaseNotFound)
+
+	err = r.ResolveModerationCase(ctx, moderationCase, moderator, entity.ModerationActionHide, "spam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ResolveModerationCase(ctx, moderationCase, moderator, entity.ModerationActionHide, "spam")
+	expectErr(t, "resolve twice", err, repoerrs.ErrModerationCaseNotFound)
+
+	moderationCase, err = r.GetModerationCaseByID(ctx, moderationCase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moderationCase.Status != entity.ModerationStatusResolved || moderationCase.Resolution == nil ||
+		*moderationCase.Resolution != entity.ModerationActionHide || moderationCase.ResolvedBy.UUID != moderator {
+		t.Errorf("resolved case = %+v", moderationCase)
+	}
+
+	// hidden articles are not listed
+	articles, err := r.GetArticlesByAuthorID(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(articles) != 0 {
+		t.Errorf("articles of alice after hide = %v, want none", articleIDs(articles))
+	}
+	if got := pendingEvents(t, r, articleID)[entity.EventArticleHidden]; got != 1 {
+		t.Errorf("got %d article.hidden events, want 1", got)
+	}
+
+	log, err := r.GetModerationLog(ctx, uuid.NullUUID{UUID: moderator, Valid: true}, uuid.NullUUID{}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 2 || log[0].Action != entity.ModerationActionHide || log[0].Note != "spam" || log[1].Action != entity.ModerationActionClaim {
+		t.Errorf("moderation log = %+v", log)
+	}
+
+	// a new report of the same target opens a new case
+	third, err := r.CreateReport(ctx, target, entity.Report{ReporterID: bob, Reason: entity.ReportReasonSpam})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.CaseID == moderationCase.ID {
+		t.Errorf("report is added to the resolved case")
+	}
+
+	// ban applies to the author of the target
+	banCase, err := r.GetModerationCaseByID(ctx, third.CaseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ResolveModerationCase(ctx, banCase, moderator, entity.ModerationActionBan, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user := getUser(t, r, alice); user.BannedAt == nil {
+		t.Errorf("author is not banned")
+	}
+
+	// delete of a reported comment
+	commentID := createComment(t, r, alice, articleID, uuid.NullUUID{})
+	report, err := r.CreateReport(ctx, entity.ModerationCase{TargetType: entity.ReportTargetComment, TargetID: commentID, TargetAuthorID: alice},
+		entity.Report{ReporterID: bob, Reason: entity.ReportReasonSpam})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commentCase, err := r.GetModerationCaseByID(ctx, report.CaseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ResolveModerationCase(ctx, commentCase, moderator, entity.ModerationActionDelete, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.GetCommentByID(ctx, commentID)
+	expectErr(t, "get comment deleted by moderator", err, repoerrs.ErrCommentNotFound)
+	if got := pendingEvents(t, r, commentID)[entity.EventCommentDeleted]; got != 1 {
+		t.Errorf("got %d comment.deleted events, want 1", got)
+	}
+}
+
+func testRetentionComments(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	articleID := createArticle(t, r, alice)
+	commentID := createComment(t, r, bob, articleID, uuid.NullUUID{})
+	replyID := createComment(t, r, alice, articleID, uuid.NullUUID{UUID: commentID, Valid: true})
+
+	deleteComment(t, r, commentID)
+
+	// the comment waits until its replies are deleted
+	purged, err := r.PurgeDeletedComments(ctx, -time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged.Count != 0 {
+		t.Errorf("purged %d comments with active replies, want 0", purged.Count)
+	}
+
+	// the retention period is not over yet
+	deleteComment(t, r, replyID)
+	purged, err = r.PurgeDeletedComments(ctx, time.Hour, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged.Count != 0 {
+		t.Errorf("purged %d recently deleted comments, want 0", purged.Count)
+	}
+
+	_, err = r.PurgeDeletedComments(ctx, -time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.RestoreCommentByID(ctx, commentID); !errors.Is(err, repoerrs.ErrCommentNotFound) {
+		t.Errorf("restore purged comment: err = %v, want %v", err, repoerrs.ErrCommentNotFound)
+	}
+	if got := getArticle(t, r, articleID).CommentsCount; got != 0 {
+		t.Errorf("comments of the article = %d, want 0", got)
+	}
+	if got := getUser(t, r, bob).CommentsCount; got != 0 {
+		t.Errorf("comments of bob = %d, want 0", got)
+	}
+	if got := getUser(t, r, alice).CommentsCount; got != 0 {
+		t.Errorf("comments of alice = %d, want 0", got)
+	}
+}
+
+func testRetentionArticles(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	articleID := createArticle(t, r, alice)
+	createComment(t, r, bob, articleID, uuid.NullUUID{})
+
+	_, err := r.SetArticleFavorite(ctx, bob, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	favoritesBefore := getUser(t, r, bob).FavoritesArticlesCount
+	articlesBefore := getUser(t, r, alice).ArticlesCount
+
+	err = r.DeleteArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := r.PurgeDeletedArticles(ctx, -time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged.Count != 1 {
+		t.Fatalf("purged %d articles, want 1", purged.Count)
+	}
+	if fmt.Sprint(purged.ArticleIDs) != fmt.Sprint([]uuid.UUID{articleID}) || len(purged.Usernames) != 0 {
+		t.Errorf("purged keys = %+v, want the article", purged)
+	}
+
+	err = r.RestoreArticleByID(ctx, articleID)
+	expectErr(t, "restore purged article", err, repoerrs.ErrArticleNotFound)
+
+	if got := getUser(t, r, bob).CommentsCount; got != 0 {
+		t.Errorf("comments of bob = %d, want 0", got)
+	}
+	if got := getUser(t, r, bob).FavoritesArticlesCount; got != favoritesBefore-1 {
+		t.Errorf("favorites of bob = %d, want %d", got, favoritesBefore-1)
+	}
+	if got := getUser(t, r, alice).ArticlesCount; got != articlesBefore-1 {
+		t.Errorf("articles of alice = %d, want %d", got, articlesBefore-1)
+	}
+
+	favorites, err := r.GetFavoriteArticles(ctx, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites) != 0 {
+		t.Errorf("favorites of bob = %v, want none", articleIDs(favorites))
+	}
+}
+
+func testRetentionUsers(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	aliceArticle := createArticle(t, r, alice)
+	bobArticle := createArticle(t, r, bob)
+	createComment(t, r, bob, aliceArticle, uuid.NullUUID{})
+	createComment(t, r, alice, bobArticle, uuid.NullUUID{})
+
+	for _, follow := range [][2]uuid.UUID{{alice, bob}, {bob, alice}} {
+		_, err := r.SetUserFollower(ctx, follow[0], follow[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	webhookID := createWebhook(t, r, entity.Webhook{OwnerID: alice, URL: "https://alice.example.com"})
+
+	err := r.DeleteUserByID(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := r.PurgeDeletedUsers(ctx, time.Hour, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged.Count != 0 {
+		t.Errorf("purged %d recently deleted users, want 0", purged.Count)
+	}
+
+	purged, err = r.PurgeDeletedUsers(ctx, -time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged.Count != 1 {
+		t.Fatalf("purged %d users, want 1", purged.Count)
+	}
+	// articles of the user are removed by cascade
+	if fmt.Sprint(purged.Usernames) != "[alice]" || fmt.Sprint(purged.ArticleIDs) != fmt.Sprint([]uuid.UUID{aliceArticle}) {
+		t.Errorf("purged keys = %+v, want alice and her article", purged)
+	}
+
+	err = r.RestoreUserByID(ctx, alice)
+	expectErr(t, "restore purged user", err, repoerrs.ErrUserNotFound)
+	_, err = r.GetWebhookByID(ctx, webhookID)
+	expectErr(t, "webhook of purged user", err, repoerrs.ErrWebhookNotFound)
+
+	user := getUser(t, r, bob)
+	if user.CommentsCount != 0 || user.FollowersCount != 0 || user.FollowingCount != 0 {
+		t.Errorf("counters of bob = comments %d, followers %d, followings %d, want zeros",
+			user.CommentsCount, user.FollowersCount, user.FollowingCount)
+	}
+	if got := getArticle(t, r, bobArticle).CommentsCount; got != 0 {
+		t.Errorf("comments of the article of bob = %d, want 0", got)
+	}
+
+	// the username is free again
+	createUser(t, r, "alice")
+}
+
+func testRetentionAnonymize(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	articleID := createArticle(t, r, alice)
+	commentID := createComment(t, r, alice, articleID, uuid.NullUUID{})
+
+	_, err := r.SetUserFollower(ctx, bob, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.ScheduleUserDeletion(ctx, bob, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.ScheduleUserDeletion(ctx, alice, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anonymized, err := r.AnonymizeScheduledUsers(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anonymized.Count != 1 {
+		t.Fatalf("anonymized %d users, want 1", anonymized.Count)
+	}
+	// the article changes its author
+	if fmt.Sprint(anonymized.Usernames) != "[alice]" || fmt.Sprint(anonymized.ArticleIDs) != fmt.Sprint([]uuid.UUID{articleID}) {
+		t.Errorf("anonymized keys = %+v, want alice and her article", anonymized)
+	}
+
+	_, err = r.GetUserByID(ctx, alice)
+	expectErr(t, "anonymized user", err, repoerrs.ErrUserNotFound)
+
+	article, err := r.GetArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment, err := r.GetCommentByID(ctx, commentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.AuthorID != entity.TombstoneUserID || comment.AuthorID != entity.TombstoneUserID {
+		t.Errorf("content authors = %s, %s, want %s", article.AuthorID, comment.AuthorID, entity.TombstoneUserID)
+	}
+
+	if got := getUser(t, r, bob).FollowingCount; got != 0 {
+		t.Errorf("followings of bob = %d, want 0", got)
+	}
+	if user := getUser(t, r, bob); user.DeletionScheduledAt == nil {
+		t.Errorf("deletion of bob is not scheduled anymore")
+	}
+}
+
+// testCounters - счетчики меняются вместе с исходными строками, поэтому сверка не находит расхождений
+func testCounters(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	articleID := createArticle(t, r, alice)
+	createArticle(t, r, alice)
+	createComment(t, r, bob, articleID, uuid.NullUUID{})
+
+	_, err := r.SetUserFollower(ctx, bob, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a repeated favorite is counted once
+	for i := 0; i < 2; i++ {
+		_, err = r.SetArticleFavorite(ctx, bob, articleID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := getArticle(t, r, articleID).FavoritesCount; got != 1 {
+		t.Errorf("favorites_count after two favorites = %d, want 1", got)
+	}
+	if got := getUser(t, r, bob).FavoritesArticlesCount; got != 1 {
+		t.Errorf("favorites_articles_count after two favorites = %d, want 1", got)
+	}
+
+	err = r.RemoveArticleFavorite(ctx, bob, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getArticle(t, r, articleID).FavoritesCount; got != 0 {
+		t.Errorf("favorites_count after removal = %d, want 0", got)
+	}
+	if got := getUser(t, r, bob).FavoritesArticlesCount; got != 0 {
+		t.Errorf("favorites_articles_count after removal = %d, want 0", got)
+	}
+
+	// soft deleted articles stay counted until they are purged
+	err = r.DeleteArticleByID(ctx, articleID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getUser(t, r, alice).ArticlesCount; got != 2 {
+		t.Errorf("articles_count = %d, want 2", got)
+	}
+
+	drift, err := r.ReconcileCounters(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("drift = %+v, want none", drift)
+	}
+}
+
+func testExport(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+
+	_, err := r.GetPendingExport(ctx, alice)
+	expectErr(t, "no pending export", err, repoerrs.ErrExportNotFound)
+
+	export, err := r.CreateExport(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Status != entity.UserExportPending || export.UserID != alice {
+		t.Errorf("created export = %+v", export)
+	}
+
+	pending, err := r.GetPendingExport(ctx, alice)
+	if err != nil || pending.ID != export.ID {
+		t.Errorf("GetPendingExport = %v, %v, want %s", pending.ID, err, export.ID)
+	}
+
+	_, err = r.GetExportArchive(ctx, export.ID)
+	expectErr(t, "archive of pending export", err, repoerrs.ErrExportNotFound)
+
+	locked, err := r.LockPendingExports(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locked) != 1 || locked[0].ID != export.ID || locked[0].Attempts != 1 {
+		t.Fatalf("locked exports = %+v", locked)
+	}
+	again, err := r.LockPendingExports(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Errorf("exports locked twice = %+v", again)
+	}
+
+	err = r.MarkExportReady(ctx, export.ID, []byte("archive"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := r.GetExportArchive(ctx, export.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(archive) != "archive" {
+		t.Errorf("archive = %q", archive)
+	}
+
+	export, err = r.GetExportByID(ctx, export.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Status != entity.UserExportReady || export.Size == nil || *export.Size != 7 || export.ExpiresAt == nil {
+		t.Errorf("ready export = %+v", export)
+	}
+
+	expired, err := r.CreateExport(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.MarkExportReady(ctx, expired.ID, []byte("old"), -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.GetExportArchive(ctx, expired.ID)
+	expectErr(t, "expired archive", err, repoerrs.ErrExportNotFound)
+
+	failed, err := r.CreateExport(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.MarkExportFailed(ctx, failed.ID, "disk full", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed, err = r.GetExportByID(ctx, failed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed.Status != entity.UserExportFailed || failed.LastError == nil || *failed.LastError != "disk full" {
+		t.Errorf("failed export = %+v", failed)
+	}
+
+	// failed exports are kept for a day
+	deleted, err := r.DeleteExpiredExports(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted %d exports, want 1", deleted)
+	}
+	_, err = r.GetExportByID(ctx, expired.ID)
+	expectErr(t, "deleted export", err, repoerrs.ErrExportNotFound)
+
+	votes, err := r.GetUserVotes(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(votes) != 0 {
+		t.Errorf("votes of alice = %+v, want none", votes)
+	}
+}
+
+func testAdmin(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	bob := createUser(t, r, "bobby")
+	carol := createUser(t, r, "carol")
+
+	err := r.DeleteUserByID(ctx, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := func(action entity.AuditAction, target uuid.UUID) entity.AuditEntry {
+		return entity.AuditEntry{
+			ActorID:    uuid.NullUUID{UUID: alice, Valid: true},
+			Action:     action,
+			TargetType: entity.AuditTargetUser,
+			TargetID:   uuid.NullUUID{UUID: target, Valid: true},
+		}
+	}
+
+	err = r.SetUserRole(ctx, bob, entity.RoleModerator, entry(entity.AuditRoleChange, bob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.SetUserBanned(ctx, bob, true, entry(entity.AuditBan, bob))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := getUser(t, r, bob)
+	if user.Role != entity.RoleModerator || user.BannedAt == nil || user.SessionsRevokedAt == nil {
+		t.Errorf("bob after role change and ban = %+v", user)
+	}
+
+	err = r.SetUserBanned(ctx, bob, false, entry(entity.AuditUnban, bob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.ResetUserPassword(ctx, bob, "temporary", entry(entity.AuditPasswordReset, bob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.RevokeUserSessions(ctx, alice, entry(entity.AuditSessionsRevoke, alice))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user = getUser(t, r, bob)
+	if user.BannedAt != nil || !user.PasswordResetRequired || user.Password != "temporary" {
+		t.Errorf("bob after unban and password reset = %+v", user)
+	}
+
+	// deleted users can't be changed, nothing is logged
+	err = r.SetUserRole(ctx, carol, entity.RoleAdmin, entry(entity.AuditRoleChange, carol))
+	expectErr(t, "role of deleted user", err, repoerrs.ErrUserNotFound)
+
+	log, err := r.GetAuditLog(ctx, entity.AuditFilter{ActorID: uuid.NullUUID{UUID: alice, Valid: true}}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 5 {
+		t.Errorf("got %d audit entries, want 5", len(log))
+	}
+
+	moderator := entity.RoleModerator
+	tests := []struct {
+		name   string
+		filter entity.UserFilter
+		want   []uuid.UUID
+	}{
+		{"all active", entity.UserFilter{Limit: 10}, []uuid.UUID{bob, alice}},
+		{"query", entity.UserFilter{Query: "BOB", Limit: 10}, []uuid.UUID{bob}},
+		{"role", entity.UserFilter{Role: &moderator, Limit: 10}, []uuid.UUID{bob}},
+		{"deleted", entity.UserFilter{Deleted: true, Limit: 10}, []uuid.UUID{carol}},
+		{"page", entity.UserFilter{Limit: 1, Offset: 1}, []uuid.UUID{alice}},
+	}
+	for _, tt := range tests {
+		users, err := r.SearchUsers(ctx, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := userIDs(users); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: users = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testAudit(t *testing.T, r *repo.Repositories) {
+	ctx := context.Background()
+
+	alice := createUser(t, r, "alice")
+	actor := uuid.NullUUID{UUID: alice, Valid: true}
+
+	entries := []entity.AuditEntry{
+		{ActorID: actor, Action: entity.AuditSignIn, TargetType: entity.AuditTargetUser, TargetID: actor, IP: "10.0.0.1", RequestID: "r1"},
+		{Action: entity.AuditSignInFailed, TargetType: entity.AuditTargetUser, IP: "10.0.0.2", Details: json.RawMessage(`{"username":"alice"}`)},
+		{ActorID: actor, Action: entity.AuditPasswordChange, TargetType: entity.AuditTargetUser, TargetID: actor, IP: "10.0.0.1"},
+	}
+	for _, entry := range entries {
+		err := r.CreateAuditEntry(ctx, entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log, err := r.GetAuditLog(ctx, entity.AuditFilter{}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 3 || log[0].Action != entity.AuditPasswordChange || log[2].Action != entity.AuditSignIn {
+		t.Fatalf("audit log = %+v", log)
+	}
+
+	var details map[string]string
+	if err := json.Unmarshal(log[1].Details, &details); err != nil || details["username"] != "alice" {
+		t.Errorf("details = %s, %v", log[1].Details, err)
+	}
+	if err := json.Unmarshal(log[0].Details, &details); err != nil {
+		t.Errorf("empty details = %s, %v", log[0].Details, err)
+	}
+
+	signIn := entity.AuditSignIn
+	tests := []struct {
+		name   string
+		filter entity.AuditFilter
+		want   int
+	}{
+		{"actor", entity.AuditFilter{ActorID: actor}, 2},
+		{"action", entity.AuditFilter{Action: &signIn}, 1},
+		{"ip", entity.AuditFilter{IP: "10.0.0.1"}, 2},
+		{"request", entity.AuditFilter{RequestID: "r1"}, 1},
+		{"from", entity.AuditFilter{From: &log[0].CreatedAt}, 1},
+		{"to", entity.AuditFilter{To: &log[0].CreatedAt}, 2},
+	}
+	for _, tt := range tests {
+		filtered, err := r.GetAuditLog(ctx, tt.filter, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filtered) != tt.want {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(filtered), tt.want)
+		}
+	}
+
+	var exported []entity.AuditEntry
+	err = r.ExportAuditLog(ctx, entity.AuditFilter{ActorID: actor}, func(entry entity.AuditEntry) error {
+		exported = append(exported, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 2 || exported[0].ID != log[0].ID {
+		t.Errorf("exported entries = %+v", exported)
+	}
+
+	stop := errors.New("stop")
+	err = r.ExportAuditLog(ctx, entity.AuditFilter{}, func(entity.AuditEntry) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("export stopped by fn: err = %v, want %v", err, stop)
+	}
+}
+
+// createUser - пароль пользователя hash-<username>, почта <username>@example.com
+// testTx - изменения fn, вернувшей ошибку, откатываются вместе со счетчиками и событиями outbox
+func testTx(t *testing.T, newRepos NewFunc) {
+	ctx := context.Background()
+	r, tx := newRepos(t)
+	errRollback := errors.New("rollback")
+	article := func(authorID uuid.UUID) entity.Article {
+		return entity.Article{AuthorID: authorID, Title: "Title", Description: "Description", Content: "Content"}
+	}
+
+	alice := createUser(t, r, "alice")
+
+	var kept uuid.UUID
+	err := tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		kept, err = r.CreateArticle(ctx, article(alice))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bob, dropped uuid.UUID
+	err = tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		bob, err = r.CreateUser(ctx, entity.User{Name: "Bob", Username: "bobby", Password: "hash", Email: "b@example.com"})
+		if err != nil {
+			return err
+		}
+		dropped, err = r.CreateArticle(ctx, article(alice))
+		if err != nil {
+			return err
+		}
+		_, err = r.SetUserFollower(ctx, bob, alice)
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Do = %v, want %v", err, errRollback)
+	}
+
+	_, err = r.GetUserByID(ctx, bob)
+	expectErr(t, "get user of the rolled back tx", err, repoerrs.ErrUserNotFound)
+	_, err = r.GetArticleByID(ctx, dropped)
+	expectErr(t, "get article of the rolled back tx", err, repoerrs.ErrArticleNotFound)
+	getArticle(t, r, kept)
+	if user := getUser(t, r, alice); user.ArticlesCount != 1 || user.FollowersCount != 0 {
+		t.Errorf("counters of alice = %d articles, %d followers, want 1 and 0", user.ArticlesCount, user.FollowersCount)
+	}
+
+	// a nested Do rolls back only its own changes, like a savepoint
+	var carol, nested uuid.UUID
+	err = tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		carol, err = r.CreateUser(ctx, entity.User{Name: "Carol", Username: "carol", Password: "hash", Email: "c@example.com"})
+		if err != nil {
+			return err
+		}
+
+		err = tx.Do(ctx, func(ctx context.Context) error {
+			nested, err = r.CreateArticle(ctx, article(carol))
+			if err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			return fmt.Errorf("nested Do = %v, want %v", err, errRollback)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.GetArticleByID(ctx, nested)
+	expectErr(t, "get article of the rolled back nested tx", err, repoerrs.ErrArticleNotFound)
+	if user := getUser(t, r, carol); user.ArticlesCount != 0 {
+		t.Errorf("articles count of carol = %d, want 0", user.ArticlesCount)
+	}
+
+	events := pendingEvents(t, r, alice, bob, carol, kept, dropped, nested)
+	want := map[entity.EventType]int{entity.EventUserCreated: 2, entity.EventArticleCreated: 1}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func createUser(t *testing.T, r *repo.Repositories, username string) uuid.UUID {
+	t.Helper()
+
+	id, err := r.CreateUser(context.Background(), entity.User{
+		Name:     username,
+		Username: username,
+		Password: "hash-" + username,
+		Email:    username + "@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+func createArticle(t *testing.T, r *repo.Repositories, authorID uuid.UUID) uuid.UUID {
+	t.Helper()
+
+	id, err := r.CreateArticle(context.Background(), entity.Article{
+		AuthorID:    authorID,
+		Title:       "Title",
+		Description: "Description",
+		Content:     "Content",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+func createComment(t *testing.T, r *repo.Repositories, authorID, articleID uuid.UUID, parentID uuid.NullUUID) uuid.UUID {
+	t.Helper()
+
+	comment, err := r.CreateComment(context.Background(), entity.Comment{
+		AuthorID:  authorID,
+		ArticleID: articleID,
+		ParentID:  parentID,
+		Content:   "Comment",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return comment.Id
+}
+
+func createWebhook(t *testing.T, r *repo.Repositories, webhook entity.Webhook) uuid.UUID {
+	t.Helper()
+
+	webhook.Secret = "secret"
+	id, err := r.CreateWebhook(context.Background(), webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+func deleteComment(t *testing.T, r *repo.Repositories, id uuid.UUID) {
+	t.Helper()
+
+	err := r.DeleteCommentByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getUser(t *testing.T, r *repo.Repositories, id uuid.UUID) entity.User {
+	t.Helper()
+
+	user, err := r.GetUserByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+func getArticle(t *testing.T, r *repo.Repositories, id uuid.UUID) entity.Article {
+	t.Helper()
+
+	article, err := r.GetArticleByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return article
+}
+
+// pendingEvents - число еще не захваченных событий outbox агрегатов по типам.
+// События захватываются на час, поэтому повторный вызов их уже не вернет
+func pendingEvents(t *testing.T, r *repo.Repositories, aggregateIDs ...uuid.UUID) map[entity.EventType]int {
+	t.Helper()
+
+	events, err := r.LockPendingEvents(context.Background(), 1000, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[entity.EventType]int)
+	for _, event := range events {
+		for _, id := range aggregateIDs {
+			if event.AggregateID == id {
+				counts[event.Type]++
+			}
+		}
+	}
+
+	return counts
+}
+
+func expectErr(t *testing.T, name string, err, want error) {
+	t.Helper()
+
+	if !errors.Is(err, want) {
+		t.Errorf("%s: err = %v, want %v", name, err, want)
+	}
+}
+
+func sameIDs(got, want []uuid.UUID) bool {
+	sorted := func(ids []uuid.UUID) string {
+		s := make([]string, 0, len(ids))
+		for _, id := range ids {
+			s = append(s, id.String())
+		}
+		sort.Strings(s)
+		return strings.Join(s, ",")
+	}
+	return sorted(got) == sorted(want)
+}
+
+func userIDs(users []entity.User) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func articleIDs(articles []entity.Article) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, article := range articles {
+		ids = append(ids, article.Id)
+	}
+	return ids
+}
+
+func commentIDs(comments []entity.Comment) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, comment := range comments {
+		ids = append(ids, comment.Id)
+	}
+	return ids
+}
+
+func webhookIDs(webhooks []entity.Webhook) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, webhook := range webhooks {
+		ids = append(ids, webhook.ID)
+	}
+	return ids
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/hasher"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type AccountUseCase struct {
+	userRepo            repo.User
+	exportRepo          repo.Export
+	passwordHasher      hasher.PasswordHasher
+	authorizer          Authorizer
+	deletionGracePeriod time.Duration
+}
+
+var (
+	ErrExportNotFound           = apperror.New("export_not_found", http.StatusNotFound, "export not found")
+	ErrExportNotReady           = apperror.New("export_not_ready", http.StatusConflict, "export is not ready")
+	ErrCannotCreateExport       = apperror.New("cannot_create_export", http.StatusInternalServerError, "cannot create export")
+	ErrWrongPassword            = apperror.New("wrong_password", http.StatusForbidden, "wrong password")
+	ErrDeletionAlreadyScheduled = apperror.New("deletion_already_scheduled", http.StatusConflict, "account deletion is already scheduled")
+	ErrDeletionNotScheduled     = apperror.New("deletion_not_scheduled", http.StatusConflict, "account deletion is not scheduled")
+)
+
+func NewAccountUseCase(
+	userRepo repo.User,
+	exportRepo repo.Export,
+	passwordHasher hasher.PasswordHasher,
+	authorizer Authorizer,
+	deletionGracePeriod time.Duration,
+) *AccountUseCase {
+	return &AccountUseCase{
+		userRepo:            userRepo,
+		exportRepo:          exportRepo,
+		passwordHasher:      passwordHasher,
+		authorizer:          authorizer,
+		deletionGracePeriod: deletionGracePeriod,
+	}
+}
+
+// RequestExport - архив собирается асинхронно, пока он не собран, возвращается тот же архив
+func (u *AccountUseCase) RequestExport(ctx context.Context, input AccountRequestExportInput) (entity.UserExport, error) {
+	export, err := u.exportRepo.GetPendingExport(ctx, input.UserID)
+	if err == nil {
+		return export, nil
+	}
+	if !errors.Is(err, repoerrs.ErrExportNotFound) {
+		return entity.UserExport{}, err
+	}
+
+	export, err = u.exportRepo.CreateExport(ctx, input.UserID)
+	if err != nil {
+		return entity.UserExport{}, ErrCannotCreateExport
+	}
+	return export, nil
+}
+
+func (u *AccountUseCase) GetExport(ctx context.Context, input AccountGetExportInput) (entity.UserExport, error) {
+	export, err := u.exportRepo.GetExportByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrExportNotFound) || (err == nil && export.UserID != input.UserID) {
+		return entity.UserExport{}, ErrExportNotFound
+	}
+	if err != nil {
+		return entity.UserExport{}, err
+	}
+	return export, nil
+}
+
+func (u *AccountUseCase) GetExportArchive(ctx context.Context, input AccountGetExportInput) ([]byte, error) {
+	export, err := u.GetExport(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Status != entity.UserExportReady {
+		return nil, ErrExportNotReady
+	}
+
+	archive, err := u.exportRepo.GetExportArchive(ctx, export.ID)
+	if errors.Is(err, repoerrs.ErrExportNotFound) {
+		return nil, ErrExportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// ScheduleDeletion - удаление подтверждается паролем, до окончания срока его можно отменить.
+// Администраторов удалить нельзя, как и через DeleteUser
+func (u *AccountUseCase) ScheduleDeletion(ctx context.Context, input AccountScheduleDeletionInput) (time.Time, error) {
+	user, err := u.userRepo.GetUserByID(ctx, input.UserID)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return time.Time{}, ErrUserNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !u.authorizer.Can(ctx, policy.AccountDelete, policy.User(user)) {
+		return time.Time{}, ErrHaveNoPermission
+	}
+
+	if user.Password != u.passwordHasher.Hash(input.Password) {
+		return time.Time{}, ErrWrongPassword
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return time.Time{}, ErrDeletionAlreadyScheduled
+	}
+
+	scheduledAt, err := u.userRepo.ScheduleUserDeletion(ctx, user.ID, u.deletionGracePeriod)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return time.Time{}, ErrDeletionAlreadyScheduled
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return scheduledAt, nil
+}
+
+func (u *AccountUseCase) CancelDeletion(ctx context.Context, input AccountCancelDeletionInput) error {
+	err := u.userRepo.CancelUserDeletion(ctx, input.UserID)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrDeletionNotScheduled
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
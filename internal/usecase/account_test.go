@@ -0,0 +1,257 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+const deletionGracePeriod = 14 * 24 * time.Hour
+
+func newAccountUseCase(d deps) *usecase.AccountUseCase {
+	return usecase.NewAccountUseCase(d.userRepo, d.exportRepo, d.hasher, d.authorizer, deletionGracePeriod)
+}
+
+func TestAccountUseCase_RequestExport(t *testing.T) {
+	userID := uuid.New()
+	pending := entity.UserExport{ID: uuid.New(), UserID: userID, Status: entity.UserExportPending}
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		want    entity.UserExport
+		err     error
+	}{
+		{
+			name: "new export",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetPendingExport(gomock.Any(), userID).Return(entity.UserExport{}, repoerrs.ErrExportNotFound)
+				d.exportRepo.EXPECT().CreateExport(gomock.Any(), userID).Return(pending, nil)
+			},
+			want: pending,
+		},
+		{
+			name: "pending export is reused",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetPendingExport(gomock.Any(), userID).Return(pending, nil)
+			},
+			want: pending,
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetPendingExport(gomock.Any(), userID).Return(entity.UserExport{}, errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "cannot create",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetPendingExport(gomock.Any(), userID).Return(entity.UserExport{}, repoerrs.ErrExportNotFound)
+				d.exportRepo.EXPECT().CreateExport(gomock.Any(), userID).Return(entity.UserExport{}, errInternal)
+			},
+			err: usecase.ErrCannotCreateExport,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			got, err := newAccountUseCase(d).RequestExport(context.Background(), usecase.AccountRequestExportInput{UserID: userID})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("export = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountUseCase_GetExportArchive(t *testing.T) {
+	userID := uuid.New()
+	ready := entity.UserExport{ID: uuid.New(), UserID: userID, Status: entity.UserExportReady}
+	pending := ready
+	pending.Status = entity.UserExportPending
+	foreign := ready
+	foreign.UserID = uuid.New()
+	input := usecase.AccountGetExportInput{UserID: userID, ID: ready.ID}
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		want    string
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetExportByID(gomock.Any(), ready.ID).Return(ready, nil)
+				d.exportRepo.EXPECT().GetExportArchive(gomock.Any(), ready.ID).Return([]byte("zip"), nil)
+			},
+			want: "zip",
+		},
+		{
+			name: "not found",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetExportByID(gomock.Any(), ready.ID).Return(entity.UserExport{}, repoerrs.ErrExportNotFound)
+			},
+			err: usecase.ErrExportNotFound,
+		},
+		{
+			name: "export of another user",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetExportByID(gomock.Any(), ready.ID).Return(foreign, nil)
+			},
+			err: usecase.ErrExportNotFound,
+		},
+		{
+			name: "not ready",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetExportByID(gomock.Any(), ready.ID).Return(pending, nil)
+			},
+			err: usecase.ErrExportNotReady,
+		},
+		{
+			name: "expired meanwhile",
+			prepare: func(d deps) {
+				d.exportRepo.EXPECT().GetExportByID(gomock.Any(), ready.ID).Return(ready, nil)
+				d.exportRepo.EXPECT().GetExportArchive(gomock.Any(), ready.ID).Return(nil, repoerrs.ErrExportNotFound)
+			},
+			err: usecase.ErrExportNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			got, err := newAccountUseCase(d).GetExportArchive(context.Background(), input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("archive = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountUseCase_ScheduleDeletion(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Password: "hash", Role: entity.RoleUser}
+	scheduled := user
+	scheduled.DeletionScheduledAt = ptr(time.Now())
+	scheduledAt := time.Now().Add(deletionGracePeriod)
+	input := usecase.AccountScheduleDeletionInput{UserID: user.ID, Password: "password"}
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		want    time.Time
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+				d.allow(policy.AccountDelete, policy.User(user), true)
+				d.hasher.EXPECT().Hash("password").Return("hash")
+				d.userRepo.EXPECT().ScheduleUserDeletion(gomock.Any(), user.ID, deletionGracePeriod).Return(scheduledAt, nil)
+			},
+			want: scheduledAt,
+		},
+		{
+			name: "user not found",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(entity.User{}, repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name: "admin",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+				d.allow(policy.AccountDelete, policy.User(user), false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "wrong password",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+				d.allow(policy.AccountDelete, policy.User(user), true)
+				d.hasher.EXPECT().Hash("password").Return("other hash")
+			},
+			err: usecase.ErrWrongPassword,
+		},
+		{
+			name: "already scheduled",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(scheduled, nil)
+				d.allow(policy.AccountDelete, policy.User(scheduled), true)
+				d.hasher.EXPECT().Hash("password").Return("hash")
+			},
+			err: usecase.ErrDeletionAlreadyScheduled,
+		},
+		{
+			name: "scheduled concurrently",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+				d.allow(policy.AccountDelete, policy.User(user), true)
+				d.hasher.EXPECT().Hash("password").Return("hash")
+				d.userRepo.EXPECT().ScheduleUserDeletion(gomock.Any(), user.ID, deletionGracePeriod).Return(time.Time{}, repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrDeletionAlreadyScheduled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			got, err := newAccountUseCase(d).ScheduleDeletion(context.Background(), input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("scheduled at %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountUseCase_CancelDeletion(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		repoErr error
+		err     error
+	}{
+		{name: "ok"},
+		{name: "not scheduled", repoErr: repoerrs.ErrUserNotFound, err: usecase.ErrDeletionNotScheduled},
+		{name: "repo error", repoErr: errInternal, err: errInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.userRepo.EXPECT().CancelUserDeletion(gomock.Any(), userID).Return(tt.repoErr)
+
+			err := newAccountUseCase(d).CancelDeletion(context.Background(), usecase.AccountCancelDeletionInput{UserID: userID})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
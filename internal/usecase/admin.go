@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"blog-backend/internal/audit"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/hasher"
+	"context"
+	"crypto/rand"
+	"errors"
+	"github.com/google/uuid"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+const (
+	impersonationTokenTTL = time.Hour
+
+	temporaryPasswordLength = 16
+	maxBulkActionUsers      = 100
+)
+
+type AdminBulkAction string
+
+const (
+	AdminBulkBan            AdminBulkAction = "ban"
+	AdminBulkUnban          AdminBulkAction = "unban"
+	AdminBulkRevokeSessions AdminBulkAction = "revoke_sessions"
+	AdminBulkDelete         AdminBulkAction = "delete"
+	AdminBulkRestore        AdminBulkAction = "restore"
+)
+
+// AdminBulkResult - результат действия над одним пользователем, ошибка одного не прерывает остальные
+type AdminBulkResult struct {
+	UserID uuid.UUID
+	Err    error
+}
+
+type AdminUseCase struct {
+	adminRepo      repo.Admin
+	auditRepo      repo.Audit
+	userRepo       repo.User
+	txManager      TxManager
+	passwordHasher hasher.PasswordHasher
+	auth           *AuthUseCase
+	authorizer     Authorizer
+}
+
+var (
+	ErrTooManyUsers           = apperror.New("too_many_users", http.StatusBadRequest, "too many users")
+	ErrUnknownBulkAction      = apperror.New("unknown_bulk_action", http.StatusBadRequest, "unknown bulk action")
+	ErrCannotGeneratePassword = apperror.New("cannot_generate_password", http.StatusInternalServerError, "cannot generate password")
+)
+
+func NewAdminUseCase(
+	adminRepo repo.Admin,
+	auditRepo repo.Audit,
+	userRepo repo.User,
+	txManager TxManager,
+	passwordHasher hasher.PasswordHasher,
+	auth *AuthUseCase,
+	authorizer Authorizer,
+) *AdminUseCase {
+	return &AdminUseCase{
+		adminRepo:      adminRepo,
+		auditRepo:      auditRepo,
+		userRepo:       userRepo,
+		txManager:      txManager,
+		passwordHasher: passwordHasher,
+		auth:           auth,
+		authorizer:     authorizer,
+	}
+}
+
+func (u *AdminUseCase) SearchUsers(ctx context.Context, input AdminSearchUsersInput) ([]entity.User, error) {
+	if !u.authorizer.Can(ctx, policy.UserSearch, policy.Any) {
+		return nil, ErrHaveNoPermission
+	}
+
+	return u.adminRepo.SearchUsers(ctx, input.Filter)
+}
+
+// SetUserRole - назначение роли, правила checkPermissions сохраняются: нельзя менять администраторов и назначать администраторов
+func (u *AdminUseCase) SetUserRole(ctx context.Context, input AdminSetUserRoleInput) error {
+	user, err := u.getTarget(ctx, policy.UserSetRole, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !u.authorizer.Can(ctx, policy.GrantRole(input.Role), policy.Any) {
+		return ErrHaveNoPermission
+	}
+
+	if user.Role == input.Role {
+		return ErrNothingToUpdate
+	}
+
+	entry := audit.NewEntry(ctx, entity.AuditRoleChange, entity.AuditTargetUser, user.ID, audit.Change(user.Role, input.Role))
+
+	err = u.adminRepo.SetUserRole(ctx, user.ID, input.Role, entry)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// ResetUserPassword - возвращает временный пароль, он показывается только один раз
+func (u *AdminUseCase) ResetUserPassword(ctx context.Context, input AdminUserInput) (string, error) {
+	user, err := u.getTarget(ctx, policy.UserResetPassword, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := generateTemporaryPassword()
+	if err != nil {
+		return "", ErrCannotGeneratePassword
+	}
+
+	entry := audit.NewEntry(ctx, entity.AuditPasswordReset, entity.AuditTargetUser, user.ID, nil)
+
+	err = u.adminRepo.ResetUserPassword(ctx, user.ID, u.passwordHasher.Hash(password), entry)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+func (u *AdminUseCase) RevokeUserSessions(ctx context.Context, input AdminUserInput) error {
+	user, err := u.getTarget(ctx, policy.UserRevokeSessions, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	return u.revokeUserSessions(ctx, user)
+}
+
+func (u *AdminUseCase) BanUser(ctx context.Context, input AdminUserInput) error {
+	user, err := u.getTarget(ctx, policy.UserBan, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	return u.setUserBanned(ctx, user, true)
+}
+
+func (u *AdminUseCase) UnbanUser(ctx context.Context, input AdminUserInput) error {
+	user, err := u.getTarget(ctx, policy.UserUnban, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	return u.setUserBanned(ctx, user, false)
+}
+
+// Impersonate - короткоживущий токен от имени пользователя, в токене и в журнале указан администратор
+func (u *AdminUseCase) Impersonate(ctx context.Context, input AdminUserInput) (string, error) {
+	user, err := u.getTarget(ctx, policy.UserImpersonate, input.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	if user.BannedAt != nil {
+		return "", ErrUserBanned
+	}
+
+	entry := audit.NewEntry(ctx, entity.AuditImpersonate, entity.AuditTargetUser, user.ID, map[string]interface{}{
+		"expires_at": time.Now().Add(impersonationTokenTTL),
+	})
+
+	err = u.auditRepo.CreateAuditEntry(ctx, entry)
+	if err != nil {
+		return "", err
+	}
+
+	return u.auth.issueToken(ctx, user, &input.RequestedUserID, impersonationTokenTTL)
+}
+
+// BulkAction - действие применяется к каждому пользователю отдельно, результат возвращается для каждого id
+func (u *AdminUseCase) BulkAction(ctx context.Context, input AdminBulkActionInput) ([]AdminBulkResult, error) {
+	if len(input.UserIDs) > maxBulkActionUsers {
+		return nil, ErrTooManyUsers
+	}
+
+	var (
+		action policy.Action
+		apply  func(ctx context.Context, user entity.User) error
+	)
+	switch input.Action {
+	case AdminBulkBan:
+		action = policy.UserBan
+		apply = func(ctx context.Context, user entity.User) error {
+			return u.setUserBanned(ctx, user, true)
+		}
+	case AdminBulkUnban:
+		action = policy.UserUnban
+		apply = func(ctx context.Context, user entity.User) error {
+			return u.setUserBanned(ctx, user, false)
+		}
+	case AdminBulkRevokeSessions:
+		action = policy.UserRevokeSessions
+		apply = func(ctx context.Context, user entity.User) error {
+			return u.revokeUserSessions(ctx, user)
+		}
+	case AdminBulkDelete:
+		action = policy.UserDelete
+		apply = func(ctx context.Context, user entity.User) error {
+			return u.deleteUser(ctx, user)
+		}
+	case AdminBulkRestore:
+		// deleted users can't be loaded, restore works by id
+		if !u.authorizer.Can(ctx, policy.UserRestore, policy.Any) {
+			return nil, ErrHaveNoPermission
+		}
+	default:
+		return nil, ErrUnknownBulkAction
+	}
+
+	results := make([]AdminBulkResult, 0, len(input.UserIDs))
+	for _, userID := range input.UserIDs {
+		var err error
+		if input.Action == AdminBulkRestore {
+			err = u.restoreUser(ctx, userID)
+		} else {
+			var user entity.User
+			user, err = u.getTarget(ctx, action, userID)
+			if err == nil {
+				err = apply(ctx, user)
+			}
+		}
+
+		results = append(results, AdminBulkResult{
+			UserID: userID,
+			Err:    err,
+		})
+	}
+
+	return results, nil
+}
+
+func (u *AdminUseCase) GetAuditLog(ctx context.Context, input AdminGetAuditLogInput) ([]entity.AuditEntry, error) {
+	if !u.authorizer.Can(ctx, policy.AuditRead, policy.Any) {
+		return nil, ErrHaveNoPermission
+	}
+
+	return u.auditRepo.GetAuditLog(ctx, input.Filter, input.Limit, input.Offset)
+}
+
+// ExportAuditLog - выгрузка журнала по фильтру в w, записи пишутся по мере чтения из базы
+func (u *AdminUseCase) ExportAuditLog(ctx context.Context, input AdminExportAuditLogInput, w io.Writer) error {
+	if !u.authorizer.Can(ctx, policy.AuditRead, policy.Any) {
+		return ErrHaveNoPermission
+	}
+
+	encoder, err := audit.NewEncoder(w, input.Format)
+	if err != nil {
+		return err
+	}
+
+	err = u.auditRepo.ExportAuditLog(ctx, input.Filter, encoder.Encode)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Flush()
+}
+
+// getTarget - действие над удаленным автором запрещено, над остальными проверяется политикой:
+// администратор не может действовать над собой и другими администраторами
+func (u *AdminUseCase) getTarget(ctx context.Context, action policy.Action, userID uuid.UUID) (entity.User, error) {
+	if userID == entity.TombstoneUserID {
+		return entity.User{}, ErrHaveNoPermission
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return entity.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	if !u.authorizer.Can(ctx, action, policy.User(user)) {
+		return entity.User{}, ErrHaveNoPermission
+	}
+
+	return user, nil
+}
+
+func (u *AdminUseCase) setUserBanned(ctx context.Context, user entity.User, banned bool) error {
+	if (user.BannedAt != nil) == banned {
+		return ErrNothingToUpdate
+	}
+
+	action := entity.AuditUnban
+	if banned {
+		action = entity.AuditBan
+	}
+
+	err := u.adminRepo.SetUserBanned(ctx, user.ID, banned, audit.NewEntry(ctx, action, entity.AuditTargetUser, user.ID, nil))
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+func (u *AdminUseCase) revokeUserSessions(ctx context.Context, user entity.User) error {
+	entry := audit.NewEntry(ctx, entity.AuditSessionsRevoke, entity.AuditTargetUser, user.ID, nil)
+
+	err := u.adminRepo.RevokeUserSessions(ctx, user.ID, entry)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// deleteUser - удаление и запись аудита в одной транзакции
+func (u *AdminUseCase) deleteUser(ctx context.Context, user entity.User) error {
+	return u.txManager.Do(ctx, func(ctx context.Context) error {
+		err := u.userRepo.DeleteUserByID(ctx, user.ID)
+		if errors.Is(err, repoerrs.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return u.auditRepo.CreateAuditEntry(ctx, audit.NewEntry(ctx, entity.AuditDelete, entity.AuditTargetUser, user.ID, nil))
+	})
+}
+
+func (u *AdminUseCase) restoreUser(ctx context.Context, userID uuid.UUID) error {
+	return u.txManager.Do(ctx, func(ctx context.Context) error {
+		err := u.userRepo.RestoreUserByID(ctx, userID)
+		if errors.Is(err, repoerrs.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return u.auditRepo.CreateAuditEntry(ctx, audit.NewEntry(ctx, entity.AuditRestore, entity.AuditTargetUser, userID, nil))
+	})
+}
+
+// generateTemporaryPassword - пароль проходит правило валидации password: строчная, заглавная буквы, цифра и символ
+func generateTemporaryPassword() (string, error) {
+	const (
+		lower   = "abcdefghijklmnopqrstuvwxyz"
+		upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		digits  = "0123456789"
+		symbols = "!@_#$%^&*"
+	)
+
+	classes := []string{lower, upper, digits, symbols}
+	all := lower + upper + digits + symbols
+
+	password := make([]byte, temporaryPasswordLength)
+	for i := range password {
+		// first characters cover every class, the rest are from the whole alphabet
+		alphabet := all
+		if i < len(classes) {
+			alphabet = classes[i]
+		}
+
+		c, err := randomChar(alphabet)
+		if err != nil {
+			return "", err
+		}
+		password[i] = c
+	}
+
+	// shuffle so the required characters are not always in front
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomChar(alphabet string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+	if err != nil {
+		return 0, err
+	}
+	return alphabet[n.Int64()], nil
+}
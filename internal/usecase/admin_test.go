@@ -0,0 +1,279 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"unicode"
+)
+
+func newAdminUseCase(d deps) *usecase.AdminUseCase {
+	return usecase.NewAdminUseCase(d.adminRepo, d.auditRepo, d.userRepo, d.txManager, d.hasher, nil, d.authorizer)
+}
+
+// target - администратор получает пользователя и может выполнить над ним action
+func (d deps) target(action policy.Action, user entity.User, allowed bool) {
+	d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+	d.allow(action, policy.User(user), allowed)
+}
+
+func TestAdminUseCase_SetUserRole(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Role: entity.RoleUser}
+	input := usecase.AdminSetUserRoleInput{RequestedUserID: uuid.New(), UserID: user.ID, Role: entity.RoleModerator}
+
+	tests := []struct {
+		name    string
+		input   usecase.AdminSetUserRoleInput
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name:  "ok",
+			input: input,
+			prepare: func(d deps) {
+				d.target(policy.UserSetRole, user, true)
+				d.allow(policy.GrantRole(entity.RoleModerator), policy.Any, true)
+				d.adminRepo.EXPECT().SetUserRole(gomock.Any(), user.ID, entity.RoleModerator, auditEntry(entity.AuditRoleChange, user.ID)).Return(nil)
+			},
+		},
+		{
+			name:    "tombstone author",
+			input:   usecase.AdminSetUserRoleInput{UserID: entity.TombstoneUserID, Role: entity.RoleModerator},
+			prepare: func(d deps) {},
+			err:     usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "user not found",
+			input: input,
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(entity.User{}, repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name:  "admin target",
+			input: input,
+			prepare: func(d deps) {
+				d.target(policy.UserSetRole, user, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "grant admin",
+			input: input,
+			prepare: func(d deps) {
+				d.target(policy.UserSetRole, user, true)
+				d.allow(policy.GrantRole(entity.RoleModerator), policy.Any, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "same role",
+			input: usecase.AdminSetUserRoleInput{UserID: user.ID, Role: entity.RoleUser},
+			prepare: func(d deps) {
+				d.target(policy.UserSetRole, user, true)
+				d.allow(policy.GrantRole(entity.RoleUser), policy.Any, true)
+			},
+			err: usecase.ErrNothingToUpdate,
+		},
+		{
+			name:  "deleted meanwhile",
+			input: input,
+			prepare: func(d deps) {
+				d.target(policy.UserSetRole, user, true)
+				d.allow(policy.GrantRole(entity.RoleModerator), policy.Any, true)
+				d.adminRepo.EXPECT().SetUserRole(gomock.Any(), user.ID, entity.RoleModerator, gomock.Any()).Return(repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			err := newAdminUseCase(d).SetUserRole(context.Background(), tt.input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAdminUseCase_ResetUserPassword(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Role: entity.RoleUser}
+
+	d := newDeps(t)
+	d.target(policy.UserResetPassword, user, true)
+
+	var password string
+	d.hasher.EXPECT().Hash(gomock.Any()).DoAndReturn(func(p string) string {
+		password = p
+		return "hash"
+	})
+	d.adminRepo.EXPECT().ResetUserPassword(gomock.Any(), user.ID, "hash", auditEntry(entity.AuditPasswordReset, user.ID)).Return(nil)
+
+	got, err := newAdminUseCase(d).ResetUserPassword(context.Background(), usecase.AdminUserInput{UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != password {
+		t.Errorf("returned password %q is not the hashed one %q", got, password)
+	}
+
+	// the temporary password passes the password validation rule
+	var lower, upper, digit, symbol bool
+	for _, c := range got {
+		lower = lower || unicode.IsLower(c)
+		upper = upper || unicode.IsUpper(c)
+		digit = digit || unicode.IsDigit(c)
+		symbol = symbol || unicode.IsPunct(c) || unicode.IsSymbol(c)
+	}
+	if len(got) != 16 || !lower || !upper || !digit || !symbol {
+		t.Errorf("password %q does not cover every character class", got)
+	}
+}
+
+func TestAdminUseCase_BanUser(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Role: entity.RoleUser}
+	banned := user
+	banned.BannedAt = ptr(time.Now())
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.target(policy.UserBan, user, true)
+				d.adminRepo.EXPECT().SetUserBanned(gomock.Any(), user.ID, true, auditEntry(entity.AuditBan, user.ID)).Return(nil)
+			},
+		},
+		{
+			name: "already banned",
+			prepare: func(d deps) {
+				d.target(policy.UserBan, banned, true)
+			},
+			err: usecase.ErrNothingToUpdate,
+		},
+		{
+			name: "no permission",
+			prepare: func(d deps) {
+				d.target(policy.UserBan, user, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			err := newAdminUseCase(d).BanUser(context.Background(), usecase.AdminUserInput{UserID: user.ID})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAdminUseCase_BulkAction(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Role: entity.RoleUser}
+	admin := entity.User{ID: uuid.New(), Role: entity.RoleAdmin}
+	missing := uuid.New()
+
+	tests := []struct {
+		name    string
+		input   usecase.AdminBulkActionInput
+		prepare func(d deps)
+		want    []usecase.AdminBulkResult
+		err     error
+	}{
+		{
+			name:  "delete, one result per user",
+			input: usecase.AdminBulkActionInput{Action: usecase.AdminBulkDelete, UserIDs: []uuid.UUID{user.ID, admin.ID, missing}},
+			prepare: func(d deps) {
+				d.target(policy.UserDelete, user, true)
+				d.inTx()
+				d.userRepo.EXPECT().DeleteUserByID(gomock.Any(), user.ID).Return(nil)
+				d.expectAudit(entity.AuditDelete, user.ID)
+
+				d.target(policy.UserDelete, admin, false)
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), missing).Return(entity.User{}, repoerrs.ErrUserNotFound)
+			},
+			want: []usecase.AdminBulkResult{
+				{UserID: user.ID},
+				{UserID: admin.ID, Err: usecase.ErrHaveNoPermission},
+				{UserID: missing, Err: usecase.ErrUserNotFound},
+			},
+		},
+		{
+			name:  "restore",
+			input: usecase.AdminBulkActionInput{Action: usecase.AdminBulkRestore, UserIDs: []uuid.UUID{user.ID, missing}},
+			prepare: func(d deps) {
+				d.allow(policy.UserRestore, policy.Any, true)
+
+				d.inTx()
+				d.userRepo.EXPECT().RestoreUserByID(gomock.Any(), user.ID).Return(nil)
+				d.expectAudit(entity.AuditRestore, user.ID)
+
+				d.inTx()
+				d.userRepo.EXPECT().RestoreUserByID(gomock.Any(), missing).Return(repoerrs.ErrUserNotFound)
+			},
+			want: []usecase.AdminBulkResult{
+				{UserID: user.ID},
+				{UserID: missing, Err: usecase.ErrUserNotFound},
+			},
+		},
+		{
+			name:  "restore without permission",
+			input: usecase.AdminBulkActionInput{Action: usecase.AdminBulkRestore, UserIDs: []uuid.UUID{user.ID}},
+			prepare: func(d deps) {
+				d.allow(policy.UserRestore, policy.Any, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:    "unknown action",
+			input:   usecase.AdminBulkActionInput{Action: "purge", UserIDs: []uuid.UUID{user.ID}},
+			prepare: func(d deps) {},
+			err:     usecase.ErrUnknownBulkAction,
+		},
+		{
+			name:    "too many users",
+			input:   usecase.AdminBulkActionInput{Action: usecase.AdminBulkBan, UserIDs: make([]uuid.UUID, 101)},
+			prepare: func(d deps) {},
+			err:     usecase.ErrTooManyUsers,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			got, err := newAdminUseCase(d).BulkAction(context.Background(), tt.input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("result %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -2,27 +2,44 @@ package usecase
 
 import (
 	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
 	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
 	"context"
-	"fmt"
+	"errors"
 	"github.com/google/uuid"
+	"net/http"
+	"strings"
 )
 
 type ArticleUseCase struct {
 	articleRepo repo.Article
+	txManager   TxManager
+	authorizer  Authorizer
+	metrics     BusinessMetrics
 }
 
 var (
-	ErrCannotCreateArticle = fmt.Errorf("cannot create article")
+	ErrCannotCreateArticle = apperror.New("cannot_create_article", http.StatusInternalServerError, "cannot create article")
+	ErrEmptySearchQuery    = apperror.New("empty_search_query", http.StatusBadRequest, "empty search query")
 )
 
-func NewArticleUseCase(articleRepo repo.Article) *ArticleUseCase {
+func NewArticleUseCase(articleRepo repo.Article, txManager TxManager, authorizer Authorizer, metrics BusinessMetrics) *ArticleUseCase {
 	return &ArticleUseCase{
 		articleRepo: articleRepo,
+		txManager:   txManager,
+		authorizer:  authorizer,
+		metrics:     metrics,
 	}
 }
 
+// CreateArticle - статья, счетчик статей автора и событие создаются в одной транзакции
 func (a *ArticleUseCase) CreateArticle(ctx context.Context, input ArticleCreateArticleInput) (uuid.UUID, error) {
+	if !a.authorizer.Can(ctx, policy.ArticleCreate, policy.Any) {
+		return uuid.UUID{}, ErrHaveNoPermission
+	}
+
 	article := entity.Article{
 		AuthorID:    input.AuthorID,
 		Title:       input.Title,
@@ -30,21 +47,62 @@ func (a *ArticleUseCase) CreateArticle(ctx context.Context, input ArticleCreateA
 		Content:     input.Content,
 	}
 
-	articleID, err := a.articleRepo.CreateArticle(ctx, article)
+	var articleID uuid.UUID
+	err := a.txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		articleID, err = a.articleRepo.CreateArticle(ctx, article)
+		return err
+	})
 	if err != nil {
 		return uuid.UUID{}, ErrCannotCreateArticle
 	}
+	a.metrics.ArticleCreated()
 	return articleID, nil
 }
 
 func (a *ArticleUseCase) GetArticleByID(ctx context.Context, input ArticleGetArticleByIDInput) (entity.Article, error) {
 	article, err := a.articleRepo.GetArticleByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return entity.Article{}, ErrArticleNotFound
+	}
 	if err != nil {
 		return entity.Article{}, err
 	}
+
+	// article hidden by moderator is visible to moderators only
+	if article.HiddenAt != nil && !a.authorizer.Can(ctx, policy.ArticleReadHidden, policy.Article(article)) {
+		return entity.Article{}, ErrArticleNotFound
+	}
 	return article, nil
 }
 
+func (a *ArticleUseCase) UpdateArticle(ctx context.Context, input ArticleUpdateArticleInput) error {
+	if input.NewTitle == nil && input.NewDescription == nil && input.NewContent == nil {
+		return ErrNothingToUpdate
+	}
+
+	article, err := a.articleRepo.GetArticleByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if !a.authorizer.Can(ctx, policy.ArticleUpdate, policy.Article(article)) {
+		return ErrHaveNoPermission
+	}
+
+	err = a.articleRepo.UpdateArticleByID(ctx, article.Id, input.RequestedUserID, input.NewTitle, input.NewDescription, input.NewContent)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (a *ArticleUseCase) GetArticlesByAuthorID(ctx context.Context, input ArticleGetArticlesByAuthorIDInput) ([]entity.Article, error) {
 	articles, err := a.articleRepo.GetArticlesByAuthorID(ctx, input.AuthorID)
 	if err != nil {
@@ -61,11 +119,33 @@ func (a *ArticleUseCase) GetNewestArticles(ctx context.Context, input ArticleGet
 	return articles, nil
 }
 
+// SetArticleFavorite - проверка статьи, избранное и счетчик избранного в одной транзакции:
+// статью не скроют и не удалят между проверкой и записью. Повторное добавление ничего не меняет и не считается в метрике
 func (a *ArticleUseCase) SetArticleFavorite(ctx context.Context, input ArticleSetArticleFavoriteInput) error {
-	err := a.articleRepo.SetArticleFavorite(ctx, input.UserID, input.ArticleID)
+	var inserted bool
+	err := a.txManager.Do(ctx, func(ctx context.Context) error {
+		// deleted and hidden articles can't be favorited
+		article, err := a.articleRepo.GetArticleByID(ctx, input.ArticleID)
+		if errors.Is(err, repoerrs.ErrArticleNotFound) || (err == nil && article.HiddenAt != nil) {
+			return ErrArticleNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !a.authorizer.Can(ctx, policy.ArticleFavorite, policy.Article(article)) {
+			return ErrHaveNoPermission
+		}
+
+		inserted, err = a.articleRepo.SetArticleFavorite(ctx, input.UserID, input.ArticleID)
+		return err
+	})
 	if err != nil {
 		return err
 	}
+	if inserted {
+		a.metrics.ArticleFavorited()
+	}
 	return nil
 }
 
@@ -84,3 +164,81 @@ func (a *ArticleUseCase) GetFavoriteArticles(ctx context.Context, input ArticleG
 	}
 	return articles, nil
 }
+
+// GetFavoritedArticleIDs - какие из статей пользователь добавил в избранное, для страницы статей сразу
+func (a *ArticleUseCase) GetFavoritedArticleIDs(ctx context.Context, input ArticleGetFavoritedArticleIDsInput) ([]uuid.UUID, error) {
+	ids, err := a.articleRepo.GetFavoritedArticleIDs(ctx, input.UserID, input.ArticleIDs)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetFeed - новые статьи авторов, на которых подписан пользователь
+func (a *ArticleUseCase) GetFeed(ctx context.Context, input ArticleGetFeedInput) ([]entity.Article, error) {
+	articles, err := a.articleRepo.GetFeedArticles(ctx, input.UserID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+func (a *ArticleUseCase) SearchArticles(ctx context.Context, input ArticleSearchArticlesInput) ([]entity.Article, error) {
+	if strings.TrimSpace(input.Query) == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	articles, err := a.articleRepo.SearchArticles(ctx, input.Query, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// GetArticlesTags - теги нескольких статей одним запросом, статьи без тегов в результат не попадают
+func (a *ArticleUseCase) GetArticlesTags(ctx context.Context, input ArticleGetArticlesTagsInput) (map[uuid.UUID][]entity.Tag, error) {
+	tags, err := a.articleRepo.GetArticlesTags(ctx, input.ArticleIDs)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// DeleteArticle - удалить статью может автор, модератор или администратор
+func (a *ArticleUseCase) DeleteArticle(ctx context.Context, input ArticleDeleteArticleInput) error {
+	article, err := a.articleRepo.GetArticleByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if !a.authorizer.Can(ctx, policy.ArticleDelete, policy.Article(article)) {
+		return ErrHaveNoPermission
+	}
+
+	err = a.articleRepo.DeleteArticleByID(ctx, article.Id)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *ArticleUseCase) RestoreArticle(ctx context.Context, input ArticleRestoreArticleInput) error {
+	if !a.authorizer.Can(ctx, policy.ArticleRestore, policy.Any) {
+		return ErrHaveNoPermission
+	}
+
+	err := a.articleRepo.RestoreArticleByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,538 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newArticleUseCase(d deps) *usecase.ArticleUseCase {
+	return usecase.NewArticleUseCase(d.articleRepo, d.txManager, d.authorizer, d.metrics)
+}
+
+func TestArticleUseCase_CreateArticle(t *testing.T) {
+	input := usecase.ArticleCreateArticleInput{AuthorID: uuid.New(), Title: "title", Description: "description", Content: "content"}
+	articleID := uuid.New()
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		want    uuid.UUID
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.allow(policy.ArticleCreate, policy.Any, true)
+				d.inTx()
+				d.articleRepo.EXPECT().CreateArticle(gomock.Any(), entity.Article{
+					AuthorID:    input.AuthorID,
+					Title:       "title",
+					Description: "description",
+					Content:     "content",
+				}).Return(articleID, nil)
+				d.metrics.EXPECT().ArticleCreated()
+			},
+			want: articleID,
+		},
+		{
+			name: "no permission",
+			prepare: func(d deps) {
+				d.allow(policy.ArticleCreate, policy.Any, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.allow(policy.ArticleCreate, policy.Any, true)
+				d.inTx()
+				d.articleRepo.EXPECT().CreateArticle(gomock.Any(), gomock.Any()).Return(uuid.UUID{}, errInternal)
+			},
+			err: usecase.ErrCannotCreateArticle,
+		},
+		{
+			name: "commit failed",
+			prepare: func(d deps) {
+				d.allow(policy.ArticleCreate, policy.Any, true)
+				d.txManager.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					_ = fn(ctx)
+					return errInternal
+				})
+				d.articleRepo.EXPECT().CreateArticle(gomock.Any(), gomock.Any()).Return(articleID, nil)
+			},
+			err: usecase.ErrCannotCreateArticle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			got, err := newArticleUseCase(d).CreateArticle(context.Background(), input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("id = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArticleUseCase_GetArticleByID(t *testing.T) {
+	article := entity.Article{Id: uuid.New(), AuthorID: uuid.New(), Title: "title"}
+	hidden := article
+	hidden.HiddenAt = ptr(time.Now())
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		want    entity.Article
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+			},
+			want: article,
+		},
+		{
+			name: "not found",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, repoerrs.ErrArticleNotFound)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "hidden, visible to moderators",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(hidden, nil)
+				d.allow(policy.ArticleReadHidden, policy.Article(hidden), true)
+			},
+			want: hidden,
+		},
+		{
+			name: "hidden, not found for others",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(hidden, nil)
+				d.allow(policy.ArticleReadHidden, policy.Article(hidden), false)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			got, err := newArticleUseCase(d).GetArticleByID(context.Background(), usecase.ArticleGetArticleByIDInput{ID: article.Id})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("article = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArticleUseCase_UpdateArticle(t *testing.T) {
+	article := entity.Article{Id: uuid.New(), AuthorID: uuid.New()}
+	requestedUserID := uuid.New()
+	title := ptr("new title")
+
+	tests := []struct {
+		name    string
+		input   usecase.ArticleUpdateArticleInput
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name:  "nothing passed",
+			input: usecase.ArticleUpdateArticleInput{ID: article.Id},
+			err:   usecase.ErrNothingToUpdate,
+		},
+		{
+			name:  "not found",
+			input: usecase.ArticleUpdateArticleInput{ID: article.Id, NewTitle: title},
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, repoerrs.ErrArticleNotFound)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+		{
+			name:  "repo error on get",
+			input: usecase.ArticleUpdateArticleInput{ID: article.Id, NewTitle: title},
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name:  "no permission",
+			input: usecase.ArticleUpdateArticleInput{ID: article.Id, NewTitle: title},
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleUpdate, policy.Article(article), false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "deleted concurrently",
+			input: usecase.ArticleUpdateArticleInput{ID: article.Id, RequestedUserID: requestedUserID, NewTitle: title},
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleUpdate, policy.Article(article), true)
+				d.articleRepo.EXPECT().UpdateArticleByID(gomock.Any(), article.Id, requestedUserID, title, nil, nil).Return(repoerrs.ErrArticleNotFound)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+		{
+			name:  "repo error on update",
+			input: usecase.ArticleUpdateArticleInput{ID: article.Id, RequestedUserID: requestedUserID, NewTitle: title},
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleUpdate, policy.Article(article), true)
+				d.articleRepo.EXPECT().UpdateArticleByID(gomock.Any(), article.Id, requestedUserID, title, nil, nil).Return(errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name:  "ok",
+			input: usecase.ArticleUpdateArticleInput{ID: article.Id, RequestedUserID: requestedUserID, NewTitle: title},
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleUpdate, policy.Article(article), true)
+				d.articleRepo.EXPECT().UpdateArticleByID(gomock.Any(), article.Id, requestedUserID, title, nil, nil).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			if tt.prepare != nil {
+				tt.prepare(d)
+			}
+
+			err := newArticleUseCase(d).UpdateArticle(context.Background(), tt.input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestArticleUseCase_Lists(t *testing.T) {
+	authorID, userID := uuid.New(), uuid.New()
+	articles := []entity.Article{{Id: uuid.New(), AuthorID: authorID}, {Id: uuid.New(), AuthorID: authorID}}
+
+	tests := []struct {
+		name   string
+		expect func(d deps) *gomock.Call
+		list   func(u *usecase.ArticleUseCase) ([]entity.Article, error)
+	}{
+		{
+			name: "by author",
+			expect: func(d deps) *gomock.Call {
+				return d.articleRepo.EXPECT().GetArticlesByAuthorID(gomock.Any(), authorID)
+			},
+			list: func(u *usecase.ArticleUseCase) ([]entity.Article, error) {
+				return u.GetArticlesByAuthorID(context.Background(), usecase.ArticleGetArticlesByAuthorIDInput{AuthorID: authorID})
+			},
+		},
+		{
+			name: "newest",
+			expect: func(d deps) *gomock.Call {
+				return d.articleRepo.EXPECT().GetNewestArticles(gomock.Any(), 10, 20)
+			},
+			list: func(u *usecase.ArticleUseCase) ([]entity.Article, error) {
+				return u.GetNewestArticles(context.Background(), usecase.ArticleGetNewestArticlesInput{Limit: 10, Offset: 20})
+			},
+		},
+		{
+			name: "favorites",
+			expect: func(d deps) *gomock.Call {
+				return d.articleRepo.EXPECT().GetFavoriteArticles(gomock.Any(), userID)
+			},
+			list: func(u *usecase.ArticleUseCase) ([]entity.Article, error) {
+				return u.GetFavoriteArticles(context.Background(), usecase.ArticleGetFavoriteArticlesInput{UserID: userID})
+			},
+		},
+		{
+			name: "feed",
+			expect: func(d deps) *gomock.Call {
+				return d.articleRepo.EXPECT().GetFeedArticles(gomock.Any(), userID, 10, 0)
+			},
+			list: func(u *usecase.ArticleUseCase) ([]entity.Article, error) {
+				return u.GetFeed(context.Background(), usecase.ArticleGetFeedInput{UserID: userID, Limit: 10})
+			},
+		},
+		{
+			name: "search",
+			expect: func(d deps) *gomock.Call {
+				return d.articleRepo.EXPECT().SearchArticles(gomock.Any(), "rabbit", 10, 0)
+			},
+			list: func(u *usecase.ArticleUseCase) ([]entity.Article, error) {
+				return u.SearchArticles(context.Background(), usecase.ArticleSearchArticlesInput{Query: "rabbit", Limit: 10})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.expect(d).Return(articles, nil)
+
+			got, err := tt.list(newArticleUseCase(d))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, articles) {
+				t.Errorf("articles = %+v, want %+v", got, articles)
+			}
+		})
+
+		t.Run(tt.name+", repo error", func(t *testing.T) {
+			d := newDeps(t)
+			tt.expect(d).Return(nil, errInternal)
+
+			got, err := tt.list(newArticleUseCase(d))
+			if err != errInternal || got != nil {
+				t.Errorf("articles = %+v, err = %v, want %v", got, err, errInternal)
+			}
+		})
+	}
+}
+
+func TestArticleUseCase_SearchArticles_EmptyQuery(t *testing.T) {
+	d := newDeps(t)
+
+	_, err := newArticleUseCase(d).SearchArticles(context.Background(), usecase.ArticleSearchArticlesInput{Query: "  ", Limit: 10})
+	if err != usecase.ErrEmptySearchQuery {
+		t.Errorf("err = %v, want %v", err, usecase.ErrEmptySearchQuery)
+	}
+}
+
+func TestArticleUseCase_SetArticleFavorite(t *testing.T) {
+	article := entity.Article{Id: uuid.New(), AuthorID: uuid.New()}
+	hidden := article
+	hidden.HiddenAt = ptr(time.Now())
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleFavorite, policy.Article(article), true)
+				d.articleRepo.EXPECT().SetArticleFavorite(gomock.Any(), userID, article.Id).Return(true, nil)
+				d.metrics.EXPECT().ArticleFavorited()
+			},
+		},
+		{
+			// the second favorite changes nothing and isn't counted
+			name: "already favorited",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleFavorite, policy.Article(article), true)
+				d.articleRepo.EXPECT().SetArticleFavorite(gomock.Any(), userID, article.Id).Return(false, nil)
+			},
+		},
+		{
+			name: "not found",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, repoerrs.ErrArticleNotFound)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+		{
+			name: "hidden",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(hidden, nil)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+		{
+			name: "repo error on get",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "no permission",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleFavorite, policy.Article(article), false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "repo error on set",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleFavorite, policy.Article(article), true)
+				d.articleRepo.EXPECT().SetArticleFavorite(gomock.Any(), userID, article.Id).Return(false, errInternal)
+			},
+			err: errInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.inTx()
+			tt.prepare(d)
+
+			err := newArticleUseCase(d).SetArticleFavorite(context.Background(), usecase.ArticleSetArticleFavoriteInput{
+				UserID:    userID,
+				ArticleID: article.Id,
+			})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestArticleUseCase_RemoveArticleFavorite(t *testing.T) {
+	userID, articleID := uuid.New(), uuid.New()
+
+	for _, repoErr := range []error{nil, errInternal} {
+		d := newDeps(t)
+		d.articleRepo.EXPECT().RemoveArticleFavorite(gomock.Any(), userID, articleID).Return(repoErr)
+
+		err := newArticleUseCase(d).RemoveArticleFavorite(context.Background(), usecase.ArticleRemoveArticleFavoriteInput{
+			UserID:    userID,
+			ArticleID: articleID,
+		})
+		if err != repoErr {
+			t.Errorf("err = %v, want %v", err, repoErr)
+		}
+	}
+}
+
+func TestArticleUseCase_DeleteArticle(t *testing.T) {
+	article := entity.Article{Id: uuid.New(), AuthorID: uuid.New()}
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleDelete, policy.Article(article), true)
+				d.articleRepo.EXPECT().DeleteArticleByID(gomock.Any(), article.Id).Return(nil)
+			},
+		},
+		{
+			name: "not found",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, repoerrs.ErrArticleNotFound)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+		{
+			name: "repo error on get",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "no permission",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleDelete, policy.Article(article), false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "deleted concurrently",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleDelete, policy.Article(article), true)
+				d.articleRepo.EXPECT().DeleteArticleByID(gomock.Any(), article.Id).Return(repoerrs.ErrArticleNotFound)
+			},
+			err: usecase.ErrArticleNotFound,
+		},
+		{
+			name: "repo error on delete",
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.allow(policy.ArticleDelete, policy.Article(article), true)
+				d.articleRepo.EXPECT().DeleteArticleByID(gomock.Any(), article.Id).Return(errInternal)
+			},
+			err: errInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			err := newArticleUseCase(d).DeleteArticle(context.Background(), usecase.ArticleDeleteArticleInput{ID: article.Id})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestArticleUseCase_RestoreArticle(t *testing.T) {
+	articleID := uuid.New()
+
+	tests := []struct {
+		name    string
+		allowed bool
+		repoErr error
+		err     error
+	}{
+		{name: "ok", allowed: true},
+		{name: "no permission", err: usecase.ErrHaveNoPermission},
+		{name: "not found", allowed: true, repoErr: repoerrs.ErrArticleNotFound, err: usecase.ErrArticleNotFound},
+		{name: "repo error", allowed: true, repoErr: errInternal, err: errInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.allow(policy.ArticleRestore, policy.Any, tt.allowed)
+			if tt.allowed {
+				d.articleRepo.EXPECT().RestoreArticleByID(gomock.Any(), articleID).Return(tt.repoErr)
+			}
+
+			err := newArticleUseCase(d).RestoreArticle(context.Background(), usecase.ArticleRestoreArticleInput{ID: articleID})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
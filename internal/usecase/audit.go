@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"context"
+)
+
+// recordAudit - запись о действии, которое уже выполнено: ошибка журнала его не отменяет,
+// она логируется в репозитории
+func recordAudit(ctx context.Context, auditRepo repo.Audit, entry entity.AuditEntry) {
+	_ = auditRepo.CreateAuditEntry(ctx, entry)
+}
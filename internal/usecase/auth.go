@@ -1,85 +1,138 @@
 package usecase
 
 import (
+	"blog-backend/internal/audit"
 	"blog-backend/internal/entity"
 	"blog-backend/internal/repo"
 	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
 	"blog-backend/pkg/hasher"
+	"blog-backend/pkg/logger"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
-	log "github.com/sirupsen/logrus"
+	"net/http"
 	"time"
 )
 
 type TokenClaims struct {
 	jwt.StandardClaims
-	UserID uuid.UUID       `json:"user_id"`
-	Role   entity.RoleType `json:"role"`
+	UserID         uuid.UUID       `json:"user_id"`
+	Role           entity.RoleType `json:"role"`
+	ImpersonatorID *uuid.UUID      `json:"impersonator_id,omitempty"`
+}
+
+// Session - пользователь запроса, роль берется из базы, а не из токена, чтобы смена роли действовала сразу
+type Session struct {
+	UserID                uuid.UUID
+	Role                  entity.RoleType
+	ImpersonatorID        uuid.NullUUID // admin acting on behalf of the user
+	PasswordResetRequired bool
 }
 
 type AuthUseCase struct {
 	userRepo       repo.User
+	auditRepo      repo.Audit
 	passwordHasher hasher.PasswordHasher
+	metrics        BusinessMetrics
 	signKey        string
 	tokenTTL       time.Duration
 }
 
 var (
-	ErrCannotGetUser    = fmt.Errorf("cannot get user")
-	ErrCannotSignToken  = fmt.Errorf("cannot sign token")
-	ErrCannotParseToken = fmt.Errorf("cannot parse token")
-	ErrTokenClaimsType  = fmt.Errorf("token claims are not of type TokenClaims")
-	ErrUserNotFound     = fmt.Errorf("user not found")
+	ErrCannotGetUser      = apperror.New("cannot_get_user", http.StatusInternalServerError, "cannot get user")
+	ErrCannotSignToken    = apperror.New("cannot_sign_token", http.StatusInternalServerError, "cannot sign token")
+	ErrCannotParseToken   = apperror.New("invalid_token", http.StatusForbidden, "cannot parse token")
+	ErrTokenClaimsType    = apperror.New("invalid_token_claims", http.StatusForbidden, "token claims are not of type TokenClaims")
+	ErrUserNotFound       = apperror.New("user_not_found", http.StatusNotFound, "user not found")
+	ErrInvalidCredentials = apperror.New("invalid_credentials", http.StatusBadRequest, "invalid username or password")
+	ErrUserBanned         = apperror.New("user_banned", http.StatusForbidden, "user is banned")
+	ErrSessionRevoked     = apperror.New("session_revoked", http.StatusForbidden, "session is revoked")
 )
 
-func NewAuthUseCase(userRepo repo.User, passwordHasher hasher.PasswordHasher, signKey string, tokenTTL time.Duration) *AuthUseCase {
+func NewAuthUseCase(
+	userRepo repo.User,
+	auditRepo repo.Audit,
+	passwordHasher hasher.PasswordHasher,
+	metrics BusinessMetrics,
+	signKey string,
+	tokenTTL time.Duration,
+) *AuthUseCase {
 	return &AuthUseCase{
 		userRepo:       userRepo,
+		auditRepo:      auditRepo,
 		passwordHasher: passwordHasher,
+		metrics:        metrics,
 		signKey:        signKey,
 		tokenTTL:       tokenTTL,
 	}
 }
 
+// GenerateToken - успешные и неудачные входы записываются в журнал аудита
 func (u *AuthUseCase) GenerateToken(ctx context.Context, input AuthGenerateTokenInput) (string, error) {
 	// get user from DB
 	user, err := u.userRepo.GetUserByUsernameAndPassword(ctx, input.Username, u.passwordHasher.Hash(input.Password))
-	if err == repoerrs.ErrUserNotFound {
-		return "", ErrUserNotFound
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		u.recordSignInFailed(ctx, input.Username, "invalid_credentials")
+		return "", ErrInvalidCredentials
 	}
 	if err != nil {
 		return "", ErrCannotGetUser
 	}
 
-	// generate token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &TokenClaims{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(u.tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		UserID: user.ID,
-		Role:   user.Role,
-	})
+	if user.BannedAt != nil {
+		u.recordSignInFailed(ctx, input.Username, "banned")
+		return "", ErrUserBanned
+	}
 
-	// sign token
-	tokenString, err := token.SignedString([]byte(u.signKey))
+	token, err := u.issueToken(ctx, user, nil, u.tokenTTL)
 	if err != nil {
-		log.Errorf("AuthUseCase.GenerateToken: cannot sign token: %v", err)
-		return "", ErrCannotSignToken
+		return "", err
 	}
 
-	return tokenString, nil
+	entry := audit.NewEntry(ctx, entity.AuditSignIn, entity.AuditTargetUser, user.ID, nil)
+	entry.ActorID = uuid.NullUUID{UUID: user.ID, Valid: true}
+	recordAudit(ctx, u.auditRepo, entry)
+	u.metrics.SignIn("success")
+
+	return token, nil
 }
 
-func (u *AuthUseCase) ParseToken(ctx context.Context, input AuthParseTokenInput) (uuid.UUID, entity.RoleType, error) {
+// ParseToken - токены, выпущенные до отзыва сессий, и токены забаненных и удаленных пользователей недействительны
+func (u *AuthUseCase) ParseToken(ctx context.Context, input AuthParseTokenInput) (Session, error) {
 	claims, err := u.parseToken(input.Token)
 	if err != nil {
-		return uuid.UUID{}, "", err
+		return Session{}, err
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, claims.UserID)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return Session{}, ErrUserNotFound
+	}
+	if err != nil {
+		return Session{}, ErrCannotGetUser
+	}
+
+	if user.BannedAt != nil {
+		return Session{}, ErrUserBanned
+	}
+
+	if user.SessionsRevokedAt != nil && claims.IssuedAt <= user.SessionsRevokedAt.Unix() {
+		return Session{}, ErrSessionRevoked
+	}
+
+	session := Session{
+		UserID:                user.ID,
+		Role:                  user.Role,
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
+	if claims.ImpersonatorID != nil {
+		session.ImpersonatorID = uuid.NullUUID{UUID: *claims.ImpersonatorID, Valid: true}
 	}
 
-	return claims.UserID, claims.Role, nil
+	return session, nil
 }
 
 func (u *AuthUseCase) GetTokenTTL() (time.Duration, error) {
@@ -106,3 +159,42 @@ func (u *AuthUseCase) parseToken(accessToken string) (*TokenClaims, error) {
 
 	return claims, nil
 }
+
+func (u *AuthUseCase) issueToken(ctx context.Context, user entity.User, impersonatorID *uuid.UUID, ttl time.Duration) (string, error) {
+	// generate token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &TokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		UserID:         user.ID,
+		Role:           user.Role,
+		ImpersonatorID: impersonatorID,
+	})
+
+	// sign token
+	tokenString, err := token.SignedString([]byte(u.signKey))
+	if err != nil {
+		logger.FromContext(ctx).Errorf("AuthUseCase.issueToken: cannot sign token: %v", err)
+		return "", ErrCannotSignToken
+	}
+
+	return tokenString, nil
+}
+
+// recordSignInFailed - актора нет, целью указывается существующий пользователь с этим username,
+// чтобы попытки подбора пароля были видны по пользователю
+func (u *AuthUseCase) recordSignInFailed(ctx context.Context, username, reason string) {
+	u.metrics.SignIn(reason)
+
+	var targetID uuid.UUID
+	user, err := u.userRepo.GetUserByUsername(ctx, username)
+	if err == nil {
+		targetID = user.ID
+	}
+
+	recordAudit(ctx, u.auditRepo, audit.NewEntry(ctx, entity.AuditSignInFailed, entity.AuditTargetUser, targetID, map[string]interface{}{
+		"username": username,
+		"reason":   reason,
+	}))
+}
@@ -0,0 +1,271 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"context"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+const (
+	signKey  = "sign-key"
+	tokenTTL = time.Hour
+)
+
+func newAuthUseCase(d deps) *usecase.AuthUseCase {
+	return usecase.NewAuthUseCase(d.userRepo, d.auditRepo, d.hasher, d.metrics, signKey, tokenTTL)
+}
+
+// signToken - токен с произвольными claims, как его выпустил бы AuthUseCase
+func signToken(t *testing.T, key string, claims usecase.TokenClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims).SignedString([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// unsignedToken - токен с alg none, такие токены не принимаются
+func unsignedToken(t *testing.T, claims usecase.TokenClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, &claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthUseCase_GenerateToken(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Username: "alice", Role: entity.RoleModerator}
+	banned := user
+	banned.BannedAt = ptr(time.Now())
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsernameAndPassword(gomock.Any(), "alice", "hash").Return(user, nil)
+				d.expectAudit(entity.AuditSignIn, user.ID)
+				d.metrics.EXPECT().SignIn("success")
+			},
+		},
+		{
+			name: "invalid credentials",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsernameAndPassword(gomock.Any(), "alice", "hash").Return(entity.User{}, repoerrs.ErrUserNotFound)
+				d.metrics.EXPECT().SignIn("invalid_credentials")
+				// the failed attempt is attributed to the existing user
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.expectAudit(entity.AuditSignInFailed, user.ID)
+			},
+			err: usecase.ErrInvalidCredentials,
+		},
+		{
+			name: "invalid credentials of an unknown user",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsernameAndPassword(gomock.Any(), "alice", "hash").Return(entity.User{}, repoerrs.ErrUserNotFound)
+				d.metrics.EXPECT().SignIn("invalid_credentials")
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(entity.User{}, repoerrs.ErrUserNotFound)
+				d.expectAudit(entity.AuditSignInFailed, uuid.Nil)
+			},
+			err: usecase.ErrInvalidCredentials,
+		},
+		{
+			name: "banned",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsernameAndPassword(gomock.Any(), "alice", "hash").Return(banned, nil)
+				d.metrics.EXPECT().SignIn("banned")
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(banned, nil)
+				d.expectAudit(entity.AuditSignInFailed, user.ID)
+			},
+			err: usecase.ErrUserBanned,
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsernameAndPassword(gomock.Any(), "alice", "hash").Return(entity.User{}, errInternal)
+			},
+			err: usecase.ErrCannotGetUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.hasher.EXPECT().Hash("Pass-word1!").Return("hash")
+			tt.prepare(d)
+
+			token, err := newAuthUseCase(d).GenerateToken(context.Background(), usecase.AuthGenerateTokenInput{
+				Username: "alice",
+				Password: "Pass-word1!",
+			})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			var claims usecase.TokenClaims
+			_, err = jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) { return []byte(signKey), nil })
+			if err != nil {
+				t.Fatalf("issued token is invalid: %v", err)
+			}
+			if claims.UserID != user.ID || claims.Role != user.Role || claims.ImpersonatorID != nil {
+				t.Errorf("claims = %+v", claims)
+			}
+			if ttl := time.Until(time.Unix(claims.ExpiresAt, 0)); ttl < tokenTTL-time.Minute || ttl > tokenTTL {
+				t.Errorf("token expires in %s, want %s", ttl, tokenTTL)
+			}
+		})
+	}
+}
+
+func TestAuthUseCase_ParseToken(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Role: entity.RoleUser, PasswordResetRequired: true}
+	impersonatorID := uuid.New()
+
+	now := time.Now()
+	claims := usecase.TokenClaims{
+		StandardClaims: jwt.StandardClaims{IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()},
+		UserID:         user.ID,
+		Role:           entity.RoleAdmin, // the role is taken from the database, not from the token
+	}
+	impersonated := claims
+	impersonated.ImpersonatorID = &impersonatorID
+	expired := claims
+	expired.ExpiresAt = now.Add(-time.Minute).Unix()
+
+	banned := user
+	banned.BannedAt = ptr(now)
+	revoked := user
+	revoked.SessionsRevokedAt = ptr(now.Add(time.Second))
+	revokedBefore := user
+	revokedBefore.SessionsRevokedAt = ptr(now.Add(-time.Hour))
+
+	tests := []struct {
+		name    string
+		token   string
+		prepare func(d deps)
+		want    usecase.Session
+		err     error
+	}{
+		{
+			name:  "ok",
+			token: signToken(t, signKey, claims),
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+			},
+			want: usecase.Session{UserID: user.ID, Role: entity.RoleUser, PasswordResetRequired: true},
+		},
+		{
+			name:  "impersonated",
+			token: signToken(t, signKey, impersonated),
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(user, nil)
+			},
+			want: usecase.Session{
+				UserID:                user.ID,
+				Role:                  entity.RoleUser,
+				ImpersonatorID:        uuid.NullUUID{UUID: impersonatorID, Valid: true},
+				PasswordResetRequired: true,
+			},
+		},
+		{
+			name:  "sessions revoked before the token",
+			token: signToken(t, signKey, claims),
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(revokedBefore, nil)
+			},
+			want: usecase.Session{UserID: user.ID, Role: entity.RoleUser, PasswordResetRequired: true},
+		},
+		{
+			name:  "garbage",
+			token: "not a token",
+			err:   usecase.ErrCannotParseToken,
+		},
+		{
+			name:  "foreign key",
+			token: signToken(t, "another-key", claims),
+			err:   usecase.ErrCannotParseToken,
+		},
+		{
+			name:  "unsigned",
+			token: unsignedToken(t, claims),
+			err:   usecase.ErrCannotParseToken,
+		},
+		{
+			name:  "expired",
+			token: signToken(t, signKey, expired),
+			err:   usecase.ErrCannotParseToken,
+		},
+		{
+			name:  "user not found",
+			token: signToken(t, signKey, claims),
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(entity.User{}, repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name:  "repo error",
+			token: signToken(t, signKey, claims),
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(entity.User{}, errInternal)
+			},
+			err: usecase.ErrCannotGetUser,
+		},
+		{
+			name:  "banned",
+			token: signToken(t, signKey, claims),
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(banned, nil)
+			},
+			err: usecase.ErrUserBanned,
+		},
+		{
+			name:  "sessions revoked after the token",
+			token: signToken(t, signKey, claims),
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), user.ID).Return(revoked, nil)
+			},
+			err: usecase.ErrSessionRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			if tt.prepare != nil {
+				tt.prepare(d)
+			}
+
+			session, err := newAuthUseCase(d).ParseToken(context.Background(), usecase.AuthParseTokenInput{Token: tt.token})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if session != tt.want {
+				t.Errorf("session = %+v, want %+v", session, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthUseCase_GetTokenTTL(t *testing.T) {
+	ttl, err := newAuthUseCase(newDeps(t)).GetTokenTTL()
+	if err != nil || ttl != tokenTTL {
+		t.Errorf("ttl = %s, err = %v, want %s", ttl, err, tokenTTL)
+	}
+}
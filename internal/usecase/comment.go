@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/logger"
+	"blog-backend/pkg/pubsub"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type CommentUseCase struct {
+	commentRepo repo.Comment
+	articleRepo repo.Article
+	publisher   Publisher
+	authorizer  Authorizer
+}
+
+var (
+	ErrArticleNotFound       = apperror.New("article_not_found", http.StatusNotFound, "article not found")
+	ErrCommentNotFound       = apperror.New("comment_not_found", http.StatusNotFound, "comment not found")
+	ErrCannotCreateComment   = apperror.New("cannot_create_comment", http.StatusInternalServerError, "cannot create comment")
+	ErrParentCommentNotFound = apperror.New("parent_comment_not_found", http.StatusBadRequest, "parent comment not found")
+	ErrParentNotInArticle    = apperror.New("parent_not_in_article", http.StatusBadRequest, "parent comment belongs to another article")
+)
+
+func NewCommentUseCase(commentRepo repo.Comment, articleRepo repo.Article, publisher Publisher, authorizer Authorizer) *CommentUseCase {
+	return &CommentUseCase{
+		commentRepo: commentRepo,
+		articleRepo: articleRepo,
+		publisher:   publisher,
+		authorizer:  authorizer,
+	}
+}
+
+func (u *CommentUseCase) CreateComment(ctx context.Context, input CommentCreateCommentInput) (uuid.UUID, error) {
+	if !u.authorizer.Can(ctx, policy.CommentCreate, policy.Any) {
+		return uuid.UUID{}, ErrHaveNoPermission
+	}
+
+	article, err := u.articleRepo.GetArticleByID(ctx, input.ArticleID)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) || (err == nil && article.HiddenAt != nil) {
+		return uuid.UUID{}, ErrArticleNotFound
+	}
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+
+	if input.ParentID.Valid {
+		parent, err := u.commentRepo.GetCommentByID(ctx, input.ParentID.UUID)
+		if errors.Is(err, repoerrs.ErrCommentNotFound) {
+			return uuid.UUID{}, ErrParentCommentNotFound
+		}
+		if err != nil {
+			return uuid.UUID{}, err
+		}
+
+		if parent.ArticleID != article.Id {
+			return uuid.UUID{}, ErrParentNotInArticle
+		}
+	}
+
+	comment, err := u.commentRepo.CreateComment(ctx, entity.Comment{
+		AuthorID:  input.AuthorID,
+		ArticleID: input.ArticleID,
+		ParentID:  input.ParentID,
+		Content:   input.Content,
+	})
+	if err != nil {
+		return uuid.UUID{}, ErrCannotCreateComment
+	}
+
+	// notifications are created by the comment.posted outbox subscriber
+	u.publishComment(ctx, comment)
+
+	return comment.Id, nil
+}
+
+func (u *CommentUseCase) GetCommentsByArticleID(ctx context.Context, input CommentGetCommentsByArticleIDInput) ([]entity.Comment, error) {
+	comments, err := u.commentRepo.GetCommentsByArticleID(ctx, input.ArticleID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (u *CommentUseCase) publishComment(ctx context.Context, comment entity.Comment) {
+	event := CommentEvent{
+		ID:        comment.Id,
+		ArticleID: comment.ArticleID,
+		ParentID:  nullUUIDPtr(comment.ParentID),
+		AuthorID:  comment.AuthorID,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+	}
+	topic := ArticleCommentsTopic(comment.ArticleID)
+
+	err := publishEvent(ctx, u.publisher, topic, EventTypeComment, event)
+	if errors.Is(err, pubsub.ErrPayloadTooLarge) {
+		// long comment doesn't fit into NOTIFY payload, client has to fetch it by itself
+		event.Content = ""
+		event.Truncated = true
+		err = publishEvent(ctx, u.publisher, topic, EventTypeComment, event)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Errorf("CommentUseCase.publishComment - publishEvent: %v", err)
+	}
+}
+
+// UpdateComment - изменить комментарий может автор или администратор
+func (u *CommentUseCase) UpdateComment(ctx context.Context, input CommentUpdateCommentInput) error {
+	comment, err := u.commentRepo.GetCommentByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrCommentNotFound) {
+		return ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if !u.authorizer.Can(ctx, policy.CommentUpdate, policy.Comment(comment)) {
+		return ErrHaveNoPermission
+	}
+
+	if comment.Content == input.Content {
+		return ErrNothingToUpdate
+	}
+
+	err = u.commentRepo.UpdateCommentByID(ctx, comment.Id, input.Content)
+	if errors.Is(err, repoerrs.ErrCommentNotFound) {
+		return ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteComment - удалить комментарий может автор, модератор или администратор
+func (u *CommentUseCase) DeleteComment(ctx context.Context, input CommentDeleteCommentInput) error {
+	comment, err := u.commentRepo.GetCommentByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrCommentNotFound) {
+		return ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if !u.authorizer.Can(ctx, policy.CommentDelete, policy.Comment(comment)) {
+		return ErrHaveNoPermission
+	}
+
+	err = u.commentRepo.DeleteCommentByID(ctx, comment.Id)
+	if errors.Is(err, repoerrs.ErrCommentNotFound) {
+		return ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *CommentUseCase) RestoreComment(ctx context.Context, input CommentRestoreCommentInput) error {
+	if !u.authorizer.Can(ctx, policy.CommentRestore, policy.Any) {
+		return ErrHaveNoPermission
+	}
+
+	err := u.commentRepo.RestoreCommentByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrCommentNotFound) {
+		return ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
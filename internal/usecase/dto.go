@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"blog-backend/internal/audit"
 	"blog-backend/internal/entity"
+	"blog-backend/pkg/pubsub"
 	"github.com/google/uuid"
 )
 
@@ -25,10 +27,17 @@ type UserGetUserByUsernameInput struct {
 	Username string
 }
 
+type UserGetUsersByIDsInput struct {
+	IDs []uuid.UUID
+}
+
+type UserFollowUserInput struct {
+	FollowerID uuid.UUID
+	Username   string
+}
+
 type UserUpdateUserInput struct {
-	RequestedUserID   uuid.UUID
-	RequestedUserRole entity.RoleType
-	Username          string
+	Username string
 
 	NewName        *string
 	NewEmail       *string
@@ -42,6 +51,32 @@ type UserUpdateUserPasswordInput struct {
 	NewPassword string
 }
 
+type UserDeleteUserInput struct {
+	Username string
+}
+
+type UserRestoreUserInput struct {
+	ID uuid.UUID
+}
+
+type AccountRequestExportInput struct {
+	UserID uuid.UUID
+}
+
+type AccountGetExportInput struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+type AccountScheduleDeletionInput struct {
+	UserID   uuid.UUID
+	Password string
+}
+
+type AccountCancelDeletionInput struct {
+	UserID uuid.UUID
+}
+
 type ArticleCreateArticleInput struct {
 	AuthorID    uuid.UUID
 	Title       string
@@ -53,6 +88,15 @@ type ArticleGetArticleByIDInput struct {
 	ID uuid.UUID
 }
 
+type ArticleUpdateArticleInput struct {
+	RequestedUserID uuid.UUID
+	ID              uuid.UUID
+
+	NewTitle       *string
+	NewDescription *string
+	NewContent     *string
+}
+
 type ArticleGetArticlesByAuthorIDInput struct {
 	AuthorID uuid.UUID
 }
@@ -75,3 +119,191 @@ type ArticleRemoveArticleFavoriteInput struct {
 type ArticleGetFavoriteArticlesInput struct {
 	UserID uuid.UUID
 }
+
+type ArticleGetFavoritedArticleIDsInput struct {
+	UserID     uuid.UUID
+	ArticleIDs []uuid.UUID
+}
+
+type ArticleGetFeedInput struct {
+	UserID uuid.UUID
+	Limit  int
+	Offset int
+}
+
+type ArticleSearchArticlesInput struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+type ArticleGetArticlesTagsInput struct {
+	ArticleIDs []uuid.UUID
+}
+
+type ArticleDeleteArticleInput struct {
+	ID uuid.UUID
+}
+
+type ArticleRestoreArticleInput struct {
+	ID uuid.UUID
+}
+
+type CommentCreateCommentInput struct {
+	AuthorID  uuid.UUID
+	ArticleID uuid.UUID
+	ParentID  uuid.NullUUID
+	Content   string
+}
+
+type CommentGetCommentsByArticleIDInput struct {
+	ArticleID uuid.UUID
+	Limit     int
+	Offset    int
+}
+
+type CommentUpdateCommentInput struct {
+	ID      uuid.UUID
+	Content string
+}
+
+type CommentDeleteCommentInput struct {
+	ID uuid.UUID
+}
+
+type CommentRestoreCommentInput struct {
+	ID uuid.UUID
+}
+
+type NotificationCreateNotificationInput struct {
+	UserID    uuid.UUID
+	ActorID   uuid.UUID
+	Type      entity.NotificationType
+	ArticleID uuid.NullUUID
+	CommentID uuid.NullUUID
+}
+
+type NotificationGetNotificationsInput struct {
+	UserID uuid.UUID
+	Limit  int
+	Offset int
+}
+
+type StreamSubscribeInput struct {
+	UserID        uuid.UUID
+	Notifications bool
+	ArticleIDs    []uuid.UUID
+}
+
+type StreamSubscribeArticleCommentsInput struct {
+	Subscription *pubsub.Subscription
+	ArticleID    uuid.UUID
+}
+
+type StreamUnsubscribeArticleCommentsInput struct {
+	Subscription *pubsub.Subscription
+	ArticleID    uuid.UUID
+}
+
+type WebhookCreateWebhookInput struct {
+	RequestedUserID uuid.UUID
+	URL             string
+	EventTypes      []entity.EventType
+	Global          bool
+}
+
+type WebhookGetWebhooksInput struct {
+	RequestedUserID uuid.UUID
+}
+
+type WebhookUpdateWebhookInput struct {
+	ID            uuid.UUID
+	NewURL        *string
+	NewEventTypes *[]entity.EventType
+	NewActive     *bool
+}
+
+type WebhookDeleteWebhookInput struct {
+	ID uuid.UUID
+}
+
+type WebhookGetDeliveriesInput struct {
+	WebhookID uuid.UUID
+	Limit     int
+	Offset    int
+}
+
+type WebhookRedeliverInput struct {
+	WebhookID  uuid.UUID
+	DeliveryID uuid.UUID
+}
+
+type ModerationCreateReportInput struct {
+	ReporterID uuid.UUID
+	TargetType entity.ReportTargetType
+	TargetID   uuid.UUID
+	Reason     entity.ReportReason
+	Comment    string
+}
+
+type ModerationGetModerationCasesInput struct {
+	Status     *entity.ModerationStatus
+	TargetType *entity.ReportTargetType
+	Limit      int
+	Offset     int
+}
+
+type ModerationGetModerationCaseInput struct {
+	ID uuid.UUID
+}
+
+type ModerationClaimModerationCaseInput struct {
+	RequestedUserID uuid.UUID
+	ID              uuid.UUID
+}
+
+type ModerationResolveModerationCaseInput struct {
+	RequestedUserID uuid.UUID
+	ID              uuid.UUID
+	Action          entity.ModerationAction
+	Note            string
+}
+
+type ModerationGetModerationLogInput struct {
+	ModeratorID uuid.NullUUID
+	CaseID      uuid.NullUUID
+	Limit       int
+	Offset      int
+}
+
+type AdminSearchUsersInput struct {
+	Filter entity.UserFilter
+}
+
+type AdminUserInput struct {
+	RequestedUserID uuid.UUID
+	UserID          uuid.UUID
+}
+
+type AdminSetUserRoleInput struct {
+	RequestedUserID uuid.UUID
+	UserID          uuid.UUID
+	Role            entity.RoleType
+}
+
+type AdminBulkActionInput struct {
+	RequestedUserID uuid.UUID
+	Action          AdminBulkAction
+	UserIDs         []uuid.UUID
+}
+
+type AdminGetAuditLogInput struct {
+	Filter entity.AuditFilter
+	Limit  int
+	Offset int
+}
+
+type AdminExportAuditLogInput struct {
+	Filter entity.AuditFilter
+	Format audit.Format
+}
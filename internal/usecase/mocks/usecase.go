@@ -0,0 +1,1337 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=usecase.go -destination=mocks/usecase.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	entity "blog-backend/internal/entity"
+	outbox "blog-backend/internal/outbox"
+	policy "blog-backend/internal/policy"
+	usecase "blog-backend/internal/usecase"
+	pubsub "blog-backend/pkg/pubsub"
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuth is a mock of Auth interface.
+type MockAuth struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthMockRecorder
+}
+
+// MockAuthMockRecorder is the mock recorder for MockAuth.
+type MockAuthMockRecorder struct {
+	mock *MockAuth
+}
+
+// NewMockAuth creates a new mock instance.
+func NewMockAuth(ctrl *gomock.Controller) *MockAuth {
+	mock := &MockAuth{ctrl: ctrl}
+	mock.recorder = &MockAuthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
+	return m.recorder
+}
+
+// GenerateToken mocks base method.
+func (m *MockAuth) GenerateToken(ctx context.Context, input usecase.AuthGenerateTokenInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateToken", ctx, input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateToken indicates an expected call of GenerateToken.
+func (mr *MockAuthMockRecorder) GenerateToken(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuth)(nil).GenerateToken), ctx, input)
+}
+
+// GetTokenTTL mocks base method.
+func (m *MockAuth) GetTokenTTL() (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenTTL")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenTTL indicates an expected call of GetTokenTTL.
+func (mr *MockAuthMockRecorder) GetTokenTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenTTL", reflect.TypeOf((*MockAuth)(nil).GetTokenTTL))
+}
+
+// ParseToken mocks base method.
+func (m *MockAuth) ParseToken(ctx context.Context, input usecase.AuthParseTokenInput) (usecase.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseToken", ctx, input)
+	ret0, _ := ret[0].(usecase.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseToken indicates an expected call of ParseToken.
+func (mr *MockAuthMockRecorder) ParseToken(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuth)(nil).ParseToken), ctx, input)
+}
+
+// MockAdmin is a mock of Admin interface.
+type MockAdmin struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminMockRecorder
+}
+
+// MockAdminMockRecorder is the mock recorder for MockAdmin.
+type MockAdminMockRecorder struct {
+	mock *MockAdmin
+}
+
+// NewMockAdmin creates a new mock instance.
+func NewMockAdmin(ctrl *gomock.Controller) *MockAdmin {
+	mock := &MockAdmin{ctrl: ctrl}
+	mock.recorder = &MockAdminMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin) EXPECT() *MockAdminMockRecorder {
+	return m.recorder
+}
+
+// BanUser mocks base method.
+func (m *MockAdmin) BanUser(ctx context.Context, input usecase.AdminUserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanUser", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanUser indicates an expected call of BanUser.
+func (mr *MockAdminMockRecorder) BanUser(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanUser", reflect.TypeOf((*MockAdmin)(nil).BanUser), ctx, input)
+}
+
+// BulkAction mocks base method.
+func (m *MockAdmin) BulkAction(ctx context.Context, input usecase.AdminBulkActionInput) ([]usecase.AdminBulkResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkAction", ctx, input)
+	ret0, _ := ret[0].([]usecase.AdminBulkResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkAction indicates an expected call of BulkAction.
+func (mr *MockAdminMockRecorder) BulkAction(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkAction", reflect.TypeOf((*MockAdmin)(nil).BulkAction), ctx, input)
+}
+
+// ExportAuditLog mocks base method.
+func (m *MockAdmin) ExportAuditLog(ctx context.Context, input usecase.AdminExportAuditLogInput, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportAuditLog", ctx, input, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportAuditLog indicates an expected call of ExportAuditLog.
+func (mr *MockAdminMockRecorder) ExportAuditLog(ctx, input, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportAuditLog", reflect.TypeOf((*MockAdmin)(nil).ExportAuditLog), ctx, input, w)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockAdmin) GetAuditLog(ctx context.Context, input usecase.AdminGetAuditLogInput) ([]entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", ctx, input)
+	ret0, _ := ret[0].([]entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockAdminMockRecorder) GetAuditLog(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockAdmin)(nil).GetAuditLog), ctx, input)
+}
+
+// Impersonate mocks base method.
+func (m *MockAdmin) Impersonate(ctx context.Context, input usecase.AdminUserInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", ctx, input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonate indicates an expected call of Impersonate.
+func (mr *MockAdminMockRecorder) Impersonate(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*MockAdmin)(nil).Impersonate), ctx, input)
+}
+
+// ResetUserPassword mocks base method.
+func (m *MockAdmin) ResetUserPassword(ctx context.Context, input usecase.AdminUserInput) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetUserPassword", ctx, input)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetUserPassword indicates an expected call of ResetUserPassword.
+func (mr *MockAdminMockRecorder) ResetUserPassword(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetUserPassword", reflect.TypeOf((*MockAdmin)(nil).ResetUserPassword), ctx, input)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MockAdmin) RevokeUserSessions(ctx context.Context, input usecase.AdminUserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockAdminMockRecorder) RevokeUserSessions(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockAdmin)(nil).RevokeUserSessions), ctx, input)
+}
+
+// SearchUsers mocks base method.
+func (m *MockAdmin) SearchUsers(ctx context.Context, input usecase.AdminSearchUsersInput) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, input)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAdminMockRecorder) SearchUsers(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAdmin)(nil).SearchUsers), ctx, input)
+}
+
+// SetUserRole mocks base method.
+func (m *MockAdmin) SetUserRole(ctx context.Context, input usecase.AdminSetUserRoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockAdminMockRecorder) SetUserRole(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockAdmin)(nil).SetUserRole), ctx, input)
+}
+
+// UnbanUser mocks base method.
+func (m *MockAdmin) UnbanUser(ctx context.Context, input usecase.AdminUserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockAdminMockRecorder) UnbanUser(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockAdmin)(nil).UnbanUser), ctx, input)
+}
+
+// MockUser is a mock of User interface.
+type MockUser struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMockRecorder
+}
+
+// MockUserMockRecorder is the mock recorder for MockUser.
+type MockUserMockRecorder struct {
+	mock *MockUser
+}
+
+// NewMockUser creates a new mock instance.
+func NewMockUser(ctrl *gomock.Controller) *MockUser {
+	mock := &MockUser{ctrl: ctrl}
+	mock.recorder = &MockUserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser) EXPECT() *MockUserMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockUser) CreateUser(ctx context.Context, input usecase.UserCreateUserInput) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, input)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserMockRecorder) CreateUser(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUser)(nil).CreateUser), ctx, input)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUser) DeleteUser(ctx context.Context, input usecase.UserDeleteUserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserMockRecorder) DeleteUser(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUser)(nil).DeleteUser), ctx, input)
+}
+
+// FollowUser mocks base method.
+func (m *MockUser) FollowUser(ctx context.Context, input usecase.UserFollowUserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowUser", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FollowUser indicates an expected call of FollowUser.
+func (mr *MockUserMockRecorder) FollowUser(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowUser", reflect.TypeOf((*MockUser)(nil).FollowUser), ctx, input)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockUser) GetUserByUsername(ctx context.Context, input usecase.UserGetUserByUsernameInput) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, input)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockUserMockRecorder) GetUserByUsername(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUser)(nil).GetUserByUsername), ctx, input)
+}
+
+// GetUsersByIDs mocks base method.
+func (m *MockUser) GetUsersByIDs(ctx context.Context, input usecase.UserGetUsersByIDsInput) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByIDs", ctx, input)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByIDs indicates an expected call of GetUsersByIDs.
+func (mr *MockUserMockRecorder) GetUsersByIDs(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByIDs", reflect.TypeOf((*MockUser)(nil).GetUsersByIDs), ctx, input)
+}
+
+// RestoreUser mocks base method.
+func (m *MockUser) RestoreUser(ctx context.Context, input usecase.UserRestoreUserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockUserMockRecorder) RestoreUser(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockUser)(nil).RestoreUser), ctx, input)
+}
+
+// UpdateUser mocks base method.
+func (m *MockUser) UpdateUser(ctx context.Context, input usecase.UserUpdateUserInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockUserMockRecorder) UpdateUser(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUser)(nil).UpdateUser), ctx, input)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockUser) UpdateUserPassword(ctx context.Context, input usecase.UserUpdateUserPasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockUserMockRecorder) UpdateUserPassword(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockUser)(nil).UpdateUserPassword), ctx, input)
+}
+
+// MockAccount is a mock of Account interface.
+type MockAccount struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountMockRecorder
+}
+
+// MockAccountMockRecorder is the mock recorder for MockAccount.
+type MockAccountMockRecorder struct {
+	mock *MockAccount
+}
+
+// NewMockAccount creates a new mock instance.
+func NewMockAccount(ctrl *gomock.Controller) *MockAccount {
+	mock := &MockAccount{ctrl: ctrl}
+	mock.recorder = &MockAccountMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccount) EXPECT() *MockAccountMockRecorder {
+	return m.recorder
+}
+
+// CancelDeletion mocks base method.
+func (m *MockAccount) CancelDeletion(ctx context.Context, input usecase.AccountCancelDeletionInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockAccountMockRecorder) CancelDeletion(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockAccount)(nil).CancelDeletion), ctx, input)
+}
+
+// GetExport mocks base method.
+func (m *MockAccount) GetExport(ctx context.Context, input usecase.AccountGetExportInput) (entity.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", ctx, input)
+	ret0, _ := ret[0].(entity.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockAccountMockRecorder) GetExport(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockAccount)(nil).GetExport), ctx, input)
+}
+
+// GetExportArchive mocks base method.
+func (m *MockAccount) GetExportArchive(ctx context.Context, input usecase.AccountGetExportInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportArchive", ctx, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExportArchive indicates an expected call of GetExportArchive.
+func (mr *MockAccountMockRecorder) GetExportArchive(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportArchive", reflect.TypeOf((*MockAccount)(nil).GetExportArchive), ctx, input)
+}
+
+// RequestExport mocks base method.
+func (m *MockAccount) RequestExport(ctx context.Context, input usecase.AccountRequestExportInput) (entity.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestExport", ctx, input)
+	ret0, _ := ret[0].(entity.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestExport indicates an expected call of RequestExport.
+func (mr *MockAccountMockRecorder) RequestExport(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestExport", reflect.TypeOf((*MockAccount)(nil).RequestExport), ctx, input)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockAccount) ScheduleDeletion(ctx context.Context, input usecase.AccountScheduleDeletionInput) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, input)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockAccountMockRecorder) ScheduleDeletion(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockAccount)(nil).ScheduleDeletion), ctx, input)
+}
+
+// MockArticle is a mock of Article interface.
+type MockArticle struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleMockRecorder
+}
+
+// MockArticleMockRecorder is the mock recorder for MockArticle.
+type MockArticleMockRecorder struct {
+	mock *MockArticle
+}
+
+// NewMockArticle creates a new mock instance.
+func NewMockArticle(ctrl *gomock.Controller) *MockArticle {
+	mock := &MockArticle{ctrl: ctrl}
+	mock.recorder = &MockArticleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticle) EXPECT() *MockArticleMockRecorder {
+	return m.recorder
+}
+
+// CreateArticle mocks base method.
+func (m *MockArticle) CreateArticle(ctx context.Context, input usecase.ArticleCreateArticleInput) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateArticle", ctx, input)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateArticle indicates an expected call of CreateArticle.
+func (mr *MockArticleMockRecorder) CreateArticle(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateArticle", reflect.TypeOf((*MockArticle)(nil).CreateArticle), ctx, input)
+}
+
+// DeleteArticle mocks base method.
+func (m *MockArticle) DeleteArticle(ctx context.Context, input usecase.ArticleDeleteArticleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticle", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArticle indicates an expected call of DeleteArticle.
+func (mr *MockArticleMockRecorder) DeleteArticle(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticle", reflect.TypeOf((*MockArticle)(nil).DeleteArticle), ctx, input)
+}
+
+// GetArticleByID mocks base method.
+func (m *MockArticle) GetArticleByID(ctx context.Context, input usecase.ArticleGetArticleByIDInput) (entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticleByID", ctx, input)
+	ret0, _ := ret[0].(entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticleByID indicates an expected call of GetArticleByID.
+func (mr *MockArticleMockRecorder) GetArticleByID(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleByID", reflect.TypeOf((*MockArticle)(nil).GetArticleByID), ctx, input)
+}
+
+// GetArticlesByAuthorID mocks base method.
+func (m *MockArticle) GetArticlesByAuthorID(ctx context.Context, input usecase.ArticleGetArticlesByAuthorIDInput) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticlesByAuthorID", ctx, input)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticlesByAuthorID indicates an expected call of GetArticlesByAuthorID.
+func (mr *MockArticleMockRecorder) GetArticlesByAuthorID(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticlesByAuthorID", reflect.TypeOf((*MockArticle)(nil).GetArticlesByAuthorID), ctx, input)
+}
+
+// GetArticlesTags mocks base method.
+func (m *MockArticle) GetArticlesTags(ctx context.Context, input usecase.ArticleGetArticlesTagsInput) (map[uuid.UUID][]entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticlesTags", ctx, input)
+	ret0, _ := ret[0].(map[uuid.UUID][]entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArticlesTags indicates an expected call of GetArticlesTags.
+func (mr *MockArticleMockRecorder) GetArticlesTags(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticlesTags", reflect.TypeOf((*MockArticle)(nil).GetArticlesTags), ctx, input)
+}
+
+// GetFavoriteArticles mocks base method.
+func (m *MockArticle) GetFavoriteArticles(ctx context.Context, input usecase.ArticleGetFavoriteArticlesInput) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFavoriteArticles", ctx, input)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFavoriteArticles indicates an expected call of GetFavoriteArticles.
+func (mr *MockArticleMockRecorder) GetFavoriteArticles(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFavoriteArticles", reflect.TypeOf((*MockArticle)(nil).GetFavoriteArticles), ctx, input)
+}
+
+// GetFavoritedArticleIDs mocks base method.
+func (m *MockArticle) GetFavoritedArticleIDs(ctx context.Context, input usecase.ArticleGetFavoritedArticleIDsInput) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFavoritedArticleIDs", ctx, input)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFavoritedArticleIDs indicates an expected call of GetFavoritedArticleIDs.
+func (mr *MockArticleMockRecorder) GetFavoritedArticleIDs(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFavoritedArticleIDs", reflect.TypeOf((*MockArticle)(nil).GetFavoritedArticleIDs), ctx, input)
+}
+
+// GetFeed mocks base method.
+func (m *MockArticle) GetFeed(ctx context.Context, input usecase.ArticleGetFeedInput) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeed", ctx, input)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockArticleMockRecorder) GetFeed(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockArticle)(nil).GetFeed), ctx, input)
+}
+
+// GetNewestArticles mocks base method.
+func (m *MockArticle) GetNewestArticles(ctx context.Context, input usecase.ArticleGetNewestArticlesInput) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNewestArticles", ctx, input)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNewestArticles indicates an expected call of GetNewestArticles.
+func (mr *MockArticleMockRecorder) GetNewestArticles(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewestArticles", reflect.TypeOf((*MockArticle)(nil).GetNewestArticles), ctx, input)
+}
+
+// RemoveArticleFavorite mocks base method.
+func (m *MockArticle) RemoveArticleFavorite(ctx context.Context, input usecase.ArticleRemoveArticleFavoriteInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveArticleFavorite", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveArticleFavorite indicates an expected call of RemoveArticleFavorite.
+func (mr *MockArticleMockRecorder) RemoveArticleFavorite(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveArticleFavorite", reflect.TypeOf((*MockArticle)(nil).RemoveArticleFavorite), ctx, input)
+}
+
+// RestoreArticle mocks base method.
+func (m *MockArticle) RestoreArticle(ctx context.Context, input usecase.ArticleRestoreArticleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreArticle", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreArticle indicates an expected call of RestoreArticle.
+func (mr *MockArticleMockRecorder) RestoreArticle(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreArticle", reflect.TypeOf((*MockArticle)(nil).RestoreArticle), ctx, input)
+}
+
+// SearchArticles mocks base method.
+func (m *MockArticle) SearchArticles(ctx context.Context, input usecase.ArticleSearchArticlesInput) ([]entity.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchArticles", ctx, input)
+	ret0, _ := ret[0].([]entity.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchArticles indicates an expected call of SearchArticles.
+func (mr *MockArticleMockRecorder) SearchArticles(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchArticles", reflect.TypeOf((*MockArticle)(nil).SearchArticles), ctx, input)
+}
+
+// SetArticleFavorite mocks base method.
+func (m *MockArticle) SetArticleFavorite(ctx context.Context, input usecase.ArticleSetArticleFavoriteInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArticleFavorite", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArticleFavorite indicates an expected call of SetArticleFavorite.
+func (mr *MockArticleMockRecorder) SetArticleFavorite(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticleFavorite", reflect.TypeOf((*MockArticle)(nil).SetArticleFavorite), ctx, input)
+}
+
+// UpdateArticle mocks base method.
+func (m *MockArticle) UpdateArticle(ctx context.Context, input usecase.ArticleUpdateArticleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateArticle", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateArticle indicates an expected call of UpdateArticle.
+func (mr *MockArticleMockRecorder) UpdateArticle(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateArticle", reflect.TypeOf((*MockArticle)(nil).UpdateArticle), ctx, input)
+}
+
+// MockComment is a mock of Comment interface.
+type MockComment struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentMockRecorder
+}
+
+// MockCommentMockRecorder is the mock recorder for MockComment.
+type MockCommentMockRecorder struct {
+	mock *MockComment
+}
+
+// NewMockComment creates a new mock instance.
+func NewMockComment(ctrl *gomock.Controller) *MockComment {
+	mock := &MockComment{ctrl: ctrl}
+	mock.recorder = &MockCommentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComment) EXPECT() *MockCommentMockRecorder {
+	return m.recorder
+}
+
+// CreateComment mocks base method.
+func (m *MockComment) CreateComment(ctx context.Context, input usecase.CommentCreateCommentInput) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", ctx, input)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateComment indicates an expected call of CreateComment.
+func (mr *MockCommentMockRecorder) CreateComment(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockComment)(nil).CreateComment), ctx, input)
+}
+
+// DeleteComment mocks base method.
+func (m *MockComment) DeleteComment(ctx context.Context, input usecase.CommentDeleteCommentInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentMockRecorder) DeleteComment(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockComment)(nil).DeleteComment), ctx, input)
+}
+
+// GetCommentsByArticleID mocks base method.
+func (m *MockComment) GetCommentsByArticleID(ctx context.Context, input usecase.CommentGetCommentsByArticleIDInput) ([]entity.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsByArticleID", ctx, input)
+	ret0, _ := ret[0].([]entity.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentsByArticleID indicates an expected call of GetCommentsByArticleID.
+func (mr *MockCommentMockRecorder) GetCommentsByArticleID(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByArticleID", reflect.TypeOf((*MockComment)(nil).GetCommentsByArticleID), ctx, input)
+}
+
+// RestoreComment mocks base method.
+func (m *MockComment) RestoreComment(ctx context.Context, input usecase.CommentRestoreCommentInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreComment", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreComment indicates an expected call of RestoreComment.
+func (mr *MockCommentMockRecorder) RestoreComment(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreComment", reflect.TypeOf((*MockComment)(nil).RestoreComment), ctx, input)
+}
+
+// UpdateComment mocks base method.
+func (m *MockComment) UpdateComment(ctx context.Context, input usecase.CommentUpdateCommentInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentMockRecorder) UpdateComment(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockComment)(nil).UpdateComment), ctx, input)
+}
+
+// MockNotification is a mock of Notification interface.
+type MockNotification struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationMockRecorder
+}
+
+// MockNotificationMockRecorder is the mock recorder for MockNotification.
+type MockNotificationMockRecorder struct {
+	mock *MockNotification
+}
+
+// NewMockNotification creates a new mock instance.
+func NewMockNotification(ctrl *gomock.Controller) *MockNotification {
+	mock := &MockNotification{ctrl: ctrl}
+	mock.recorder = &MockNotificationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotification) EXPECT() *MockNotificationMockRecorder {
+	return m.recorder
+}
+
+// CreateNotification mocks base method.
+func (m *MockNotification) CreateNotification(ctx context.Context, input usecase.NotificationCreateNotificationInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockNotificationMockRecorder) CreateNotification(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotification)(nil).CreateNotification), ctx, input)
+}
+
+// GetNotifications mocks base method.
+func (m *MockNotification) GetNotifications(ctx context.Context, input usecase.NotificationGetNotificationsInput) ([]entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, input)
+	ret0, _ := ret[0].([]entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockNotificationMockRecorder) GetNotifications(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotification)(nil).GetNotifications), ctx, input)
+}
+
+// MockStream is a mock of Stream interface.
+type MockStream struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamMockRecorder
+}
+
+// MockStreamMockRecorder is the mock recorder for MockStream.
+type MockStreamMockRecorder struct {
+	mock *MockStream
+}
+
+// NewMockStream creates a new mock instance.
+func NewMockStream(ctrl *gomock.Controller) *MockStream {
+	mock := &MockStream{ctrl: ctrl}
+	mock.recorder = &MockStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStream) EXPECT() *MockStreamMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockStream) Subscribe(ctx context.Context, input usecase.StreamSubscribeInput) (*pubsub.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, input)
+	ret0, _ := ret[0].(*pubsub.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockStreamMockRecorder) Subscribe(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockStream)(nil).Subscribe), ctx, input)
+}
+
+// SubscribeArticleComments mocks base method.
+func (m *MockStream) SubscribeArticleComments(ctx context.Context, input usecase.StreamSubscribeArticleCommentsInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeArticleComments", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribeArticleComments indicates an expected call of SubscribeArticleComments.
+func (mr *MockStreamMockRecorder) SubscribeArticleComments(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeArticleComments", reflect.TypeOf((*MockStream)(nil).SubscribeArticleComments), ctx, input)
+}
+
+// UnsubscribeArticleComments mocks base method.
+func (m *MockStream) UnsubscribeArticleComments(ctx context.Context, input usecase.StreamUnsubscribeArticleCommentsInput) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UnsubscribeArticleComments", ctx, input)
+}
+
+// UnsubscribeArticleComments indicates an expected call of UnsubscribeArticleComments.
+func (mr *MockStreamMockRecorder) UnsubscribeArticleComments(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeArticleComments", reflect.TypeOf((*MockStream)(nil).UnsubscribeArticleComments), ctx, input)
+}
+
+// MockWebhook is a mock of Webhook interface.
+type MockWebhook struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookMockRecorder
+}
+
+// MockWebhookMockRecorder is the mock recorder for MockWebhook.
+type MockWebhookMockRecorder struct {
+	mock *MockWebhook
+}
+
+// NewMockWebhook creates a new mock instance.
+func NewMockWebhook(ctrl *gomock.Controller) *MockWebhook {
+	mock := &MockWebhook{ctrl: ctrl}
+	mock.recorder = &MockWebhookMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhook) EXPECT() *MockWebhookMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhook) CreateWebhook(ctx context.Context, input usecase.WebhookCreateWebhookInput) (entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, input)
+	ret0, _ := ret[0].(entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookMockRecorder) CreateWebhook(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhook)(nil).CreateWebhook), ctx, input)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhook) DeleteWebhook(ctx context.Context, input usecase.WebhookDeleteWebhookInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookMockRecorder) DeleteWebhook(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhook)(nil).DeleteWebhook), ctx, input)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhook) GetDeliveries(ctx context.Context, input usecase.WebhookGetDeliveriesInput) ([]entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, input)
+	ret0, _ := ret[0].([]entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookMockRecorder) GetDeliveries(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhook)(nil).GetDeliveries), ctx, input)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhook) GetWebhooks(ctx context.Context, input usecase.WebhookGetWebhooksInput) ([]entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx, input)
+	ret0, _ := ret[0].([]entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookMockRecorder) GetWebhooks(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhook)(nil).GetWebhooks), ctx, input)
+}
+
+// Redeliver mocks base method.
+func (m *MockWebhook) Redeliver(ctx context.Context, input usecase.WebhookRedeliverInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockWebhookMockRecorder) Redeliver(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockWebhook)(nil).Redeliver), ctx, input)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockWebhook) UpdateWebhook(ctx context.Context, input usecase.WebhookUpdateWebhookInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockWebhookMockRecorder) UpdateWebhook(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockWebhook)(nil).UpdateWebhook), ctx, input)
+}
+
+// MockModeration is a mock of Moderation interface.
+type MockModeration struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationMockRecorder
+}
+
+// MockModerationMockRecorder is the mock recorder for MockModeration.
+type MockModerationMockRecorder struct {
+	mock *MockModeration
+}
+
+// NewMockModeration creates a new mock instance.
+func NewMockModeration(ctrl *gomock.Controller) *MockModeration {
+	mock := &MockModeration{ctrl: ctrl}
+	mock.recorder = &MockModerationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModeration) EXPECT() *MockModerationMockRecorder {
+	return m.recorder
+}
+
+// ClaimModerationCase mocks base method.
+func (m *MockModeration) ClaimModerationCase(ctx context.Context, input usecase.ModerationClaimModerationCaseInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimModerationCase", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimModerationCase indicates an expected call of ClaimModerationCase.
+func (mr *MockModerationMockRecorder) ClaimModerationCase(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimModerationCase", reflect.TypeOf((*MockModeration)(nil).ClaimModerationCase), ctx, input)
+}
+
+// CreateReport mocks base method.
+func (m *MockModeration) CreateReport(ctx context.Context, input usecase.ModerationCreateReportInput) (entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReport", ctx, input)
+	ret0, _ := ret[0].(entity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReport indicates an expected call of CreateReport.
+func (mr *MockModerationMockRecorder) CreateReport(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReport", reflect.TypeOf((*MockModeration)(nil).CreateReport), ctx, input)
+}
+
+// GetModerationCase mocks base method.
+func (m *MockModeration) GetModerationCase(ctx context.Context, input usecase.ModerationGetModerationCaseInput) (entity.ModerationCase, []entity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationCase", ctx, input)
+	ret0, _ := ret[0].(entity.ModerationCase)
+	ret1, _ := ret[1].([]entity.Report)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetModerationCase indicates an expected call of GetModerationCase.
+func (mr *MockModerationMockRecorder) GetModerationCase(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationCase", reflect.TypeOf((*MockModeration)(nil).GetModerationCase), ctx, input)
+}
+
+// GetModerationCases mocks base method.
+func (m *MockModeration) GetModerationCases(ctx context.Context, input usecase.ModerationGetModerationCasesInput) ([]entity.ModerationCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationCases", ctx, input)
+	ret0, _ := ret[0].([]entity.ModerationCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModerationCases indicates an expected call of GetModerationCases.
+func (mr *MockModerationMockRecorder) GetModerationCases(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationCases", reflect.TypeOf((*MockModeration)(nil).GetModerationCases), ctx, input)
+}
+
+// GetModerationLog mocks base method.
+func (m *MockModeration) GetModerationLog(ctx context.Context, input usecase.ModerationGetModerationLogInput) ([]entity.ModerationLogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationLog", ctx, input)
+	ret0, _ := ret[0].([]entity.ModerationLogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModerationLog indicates an expected call of GetModerationLog.
+func (mr *MockModerationMockRecorder) GetModerationLog(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationLog", reflect.TypeOf((*MockModeration)(nil).GetModerationLog), ctx, input)
+}
+
+// ResolveModerationCase mocks base method.
+func (m *MockModeration) ResolveModerationCase(ctx context.Context, input usecase.ModerationResolveModerationCaseInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveModerationCase", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveModerationCase indicates an expected call of ResolveModerationCase.
+func (mr *MockModerationMockRecorder) ResolveModerationCase(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveModerationCase", reflect.TypeOf((*MockModeration)(nil).ResolveModerationCase), ctx, input)
+}
+
+// MockEventSubscriber is a mock of EventSubscriber interface.
+type MockEventSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventSubscriberMockRecorder
+}
+
+// MockEventSubscriberMockRecorder is the mock recorder for MockEventSubscriber.
+type MockEventSubscriberMockRecorder struct {
+	mock *MockEventSubscriber
+}
+
+// NewMockEventSubscriber creates a new mock instance.
+func NewMockEventSubscriber(ctrl *gomock.Controller) *MockEventSubscriber {
+	mock := &MockEventSubscriber{ctrl: ctrl}
+	mock.recorder = &MockEventSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventSubscriber) EXPECT() *MockEventSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockEventSubscriber) Subscribe(eventType entity.EventType, handler outbox.HandlerFunc) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Subscribe", eventType, handler)
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventSubscriberMockRecorder) Subscribe(eventType, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventSubscriber)(nil).Subscribe), eventType, handler)
+}
+
+// MockBusinessMetrics is a mock of BusinessMetrics interface.
+type MockBusinessMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockBusinessMetricsMockRecorder
+}
+
+// MockBusinessMetricsMockRecorder is the mock recorder for MockBusinessMetrics.
+type MockBusinessMetricsMockRecorder struct {
+	mock *MockBusinessMetrics
+}
+
+// NewMockBusinessMetrics creates a new mock instance.
+func NewMockBusinessMetrics(ctrl *gomock.Controller) *MockBusinessMetrics {
+	mock := &MockBusinessMetrics{ctrl: ctrl}
+	mock.recorder = &MockBusinessMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBusinessMetrics) EXPECT() *MockBusinessMetricsMockRecorder {
+	return m.recorder
+}
+
+// ArticleCreated mocks base method.
+func (m *MockBusinessMetrics) ArticleCreated() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ArticleCreated")
+}
+
+// ArticleCreated indicates an expected call of ArticleCreated.
+func (mr *MockBusinessMetricsMockRecorder) ArticleCreated() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArticleCreated", reflect.TypeOf((*MockBusinessMetrics)(nil).ArticleCreated))
+}
+
+// ArticleFavorited mocks base method.
+func (m *MockBusinessMetrics) ArticleFavorited() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ArticleFavorited")
+}
+
+// ArticleFavorited indicates an expected call of ArticleFavorited.
+func (mr *MockBusinessMetricsMockRecorder) ArticleFavorited() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArticleFavorited", reflect.TypeOf((*MockBusinessMetrics)(nil).ArticleFavorited))
+}
+
+// SignIn mocks base method.
+func (m *MockBusinessMetrics) SignIn(result string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignIn", result)
+}
+
+// SignIn indicates an expected call of SignIn.
+func (mr *MockBusinessMetricsMockRecorder) SignIn(result any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockBusinessMetrics)(nil).SignIn), result)
+}
+
+// SignUp mocks base method.
+func (m *MockBusinessMetrics) SignUp() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignUp")
+}
+
+// SignUp indicates an expected call of SignUp.
+func (mr *MockBusinessMetricsMockRecorder) SignUp() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockBusinessMetrics)(nil).SignUp))
+}
+
+// MockTxManager is a mock of TxManager interface.
+type MockTxManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxManagerMockRecorder
+}
+
+// MockTxManagerMockRecorder is the mock recorder for MockTxManager.
+type MockTxManagerMockRecorder struct {
+	mock *MockTxManager
+}
+
+// NewMockTxManager creates a new mock instance.
+func NewMockTxManager(ctrl *gomock.Controller) *MockTxManager {
+	mock := &MockTxManager{ctrl: ctrl}
+	mock.recorder = &MockTxManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxManager) EXPECT() *MockTxManagerMockRecorder {
+	return m.recorder
+}
+
+// Do mocks base method.
+func (m *MockTxManager) Do(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockTxManagerMockRecorder) Do(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockTxManager)(nil).Do), ctx, fn)
+}
+
+// MockAuthorizer is a mock of Authorizer interface.
+type MockAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizerMockRecorder
+}
+
+// MockAuthorizerMockRecorder is the mock recorder for MockAuthorizer.
+type MockAuthorizerMockRecorder struct {
+	mock *MockAuthorizer
+}
+
+// NewMockAuthorizer creates a new mock instance.
+func NewMockAuthorizer(ctrl *gomock.Controller) *MockAuthorizer {
+	mock := &MockAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizer) EXPECT() *MockAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Can mocks base method.
+func (m *MockAuthorizer) Can(ctx context.Context, action policy.Action, resource policy.Resource) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Can", ctx, action, resource)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Can indicates an expected call of Can.
+func (mr *MockAuthorizerMockRecorder) Can(ctx, action, resource any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Can", reflect.TypeOf((*MockAuthorizer)(nil).Can), ctx, action, resource)
+}
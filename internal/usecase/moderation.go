@@ -0,0 +1,280 @@
+package usecase
+
+import (
+	"blog-backend/internal/audit"
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type ModerationUseCase struct {
+	moderationRepo repo.Moderation
+	userRepo       repo.User
+	articleRepo    repo.Article
+	commentRepo    repo.Comment
+	auditRepo      repo.Audit
+	authorizer     Authorizer
+}
+
+var (
+	ErrReportTargetNotFound        = apperror.New("report_target_not_found", http.StatusNotFound, "report target not found")
+	ErrCannotReportYourself        = apperror.New("cannot_report_yourself", http.StatusBadRequest, "cannot report yourself")
+	ErrReportAlreadyExists         = apperror.New("report_already_exists", http.StatusBadRequest, "report already exists")
+	ErrModerationCaseNotFound      = apperror.New("moderation_case_not_found", http.StatusNotFound, "moderation case not found")
+	ErrModerationCaseClaimed       = apperror.New("moderation_case_claimed", http.StatusConflict, "moderation case is already claimed")
+	ErrModerationCaseResolved      = apperror.New("moderation_case_resolved", http.StatusConflict, "moderation case is already resolved")
+	ErrModerationCaseNotClaimed    = apperror.New("moderation_case_not_claimed", http.StatusForbidden, "moderation case is not claimed by you")
+	ErrInvalidModerationAction     = apperror.New("invalid_moderation_action", http.StatusBadRequest, "invalid moderation action for the target")
+	ErrCannotCreateReport          = apperror.New("cannot_create_report", http.StatusInternalServerError, "cannot create report")
+	ErrCannotResolveModerationCase = apperror.New("cannot_resolve_moderation_case", http.StatusInternalServerError, "cannot resolve moderation case")
+)
+
+func NewModerationUseCase(
+	moderationRepo repo.Moderation,
+	userRepo repo.User,
+	articleRepo repo.Article,
+	commentRepo repo.Comment,
+	auditRepo repo.Audit,
+	authorizer Authorizer,
+) *ModerationUseCase {
+	return &ModerationUseCase{
+		moderationRepo: moderationRepo,
+		userRepo:       userRepo,
+		articleRepo:    articleRepo,
+		commentRepo:    commentRepo,
+		auditRepo:      auditRepo,
+		authorizer:     authorizer,
+	}
+}
+
+// CreateReport - жалобы на одну цель собираются в один кейс, пока он не закрыт
+func (u *ModerationUseCase) CreateReport(ctx context.Context, input ModerationCreateReportInput) (entity.Report, error) {
+	authorID, err := u.getTargetAuthor(ctx, input.TargetType, input.TargetID)
+	if err != nil {
+		return entity.Report{}, err
+	}
+
+	if authorID == input.ReporterID {
+		return entity.Report{}, ErrCannotReportYourself
+	}
+
+	report, err := u.moderationRepo.CreateReport(ctx, entity.ModerationCase{
+		TargetType:     input.TargetType,
+		TargetID:       input.TargetID,
+		TargetAuthorID: authorID,
+	}, entity.Report{
+		ReporterID: input.ReporterID,
+		Reason:     input.Reason,
+		Comment:    input.Comment,
+	})
+	if errors.Is(err, repoerrs.ErrReportAlreadyExists) {
+		return entity.Report{}, ErrReportAlreadyExists
+	}
+	if err != nil {
+		return entity.Report{}, ErrCannotCreateReport
+	}
+	return report, nil
+}
+
+func (u *ModerationUseCase) GetModerationCases(ctx context.Context, input ModerationGetModerationCasesInput) ([]entity.ModerationCase, error) {
+	if !u.authorizer.Can(ctx, policy.ModerationRead, policy.Any) {
+		return nil, ErrHaveNoPermission
+	}
+
+	moderationCases, err := u.moderationRepo.GetModerationCases(ctx, input.Status, input.TargetType, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return moderationCases, nil
+}
+
+func (u *ModerationUseCase) GetModerationCase(ctx context.Context, input ModerationGetModerationCaseInput) (entity.ModerationCase, []entity.Report, error) {
+	if !u.authorizer.Can(ctx, policy.ModerationRead, policy.Any) {
+		return entity.ModerationCase{}, nil, ErrHaveNoPermission
+	}
+
+	moderationCase, err := u.getModerationCase(ctx, input.ID)
+	if err != nil {
+		return entity.ModerationCase{}, nil, err
+	}
+
+	reports, err := u.moderationRepo.GetReportsByCaseID(ctx, moderationCase.ID)
+	if err != nil {
+		return entity.ModerationCase{}, nil, err
+	}
+
+	return moderationCase, reports, nil
+}
+
+func (u *ModerationUseCase) ClaimModerationCase(ctx context.Context, input ModerationClaimModerationCaseInput) error {
+	if !u.authorizer.Can(ctx, policy.ModerationClaim, policy.Any) {
+		return ErrHaveNoPermission
+	}
+
+	moderationCase, err := u.getModerationCase(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+
+	switch moderationCase.Status {
+	case entity.ModerationStatusClaimed:
+		return ErrModerationCaseClaimed
+	case entity.ModerationStatusResolved:
+		return ErrModerationCaseResolved
+	}
+
+	err = u.moderationRepo.ClaimModerationCase(ctx, moderationCase, input.RequestedUserID)
+	// claimed by another moderator in the meantime
+	if errors.Is(err, repoerrs.ErrModerationCaseNotFound) {
+		return ErrModerationCaseClaimed
+	}
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditRepo, moderationAuditEntry(ctx, entity.AuditModerationClaim, moderationCase, nil))
+	return nil
+}
+
+// ResolveModerationCase - закрыть кейс может модератор, взявший его в работу, или администратор
+func (u *ModerationUseCase) ResolveModerationCase(ctx context.Context, input ModerationResolveModerationCaseInput) error {
+	if !u.authorizer.Can(ctx, policy.ModerationRead, policy.Any) {
+		return ErrHaveNoPermission
+	}
+
+	moderationCase, err := u.getModerationCase(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+
+	if moderationCase.Status == entity.ModerationStatusResolved {
+		return ErrModerationCaseResolved
+	}
+
+	if !u.authorizer.Can(ctx, policy.ModerationResolve, policy.ModerationCase(moderationCase)) {
+		return ErrModerationCaseNotClaimed
+	}
+
+	err = u.checkAction(ctx, moderationCase, input.Action)
+	if err != nil {
+		return err
+	}
+
+	err = u.moderationRepo.ResolveModerationCase(ctx, moderationCase, input.RequestedUserID, input.Action, input.Note)
+	if errors.Is(err, repoerrs.ErrModerationCaseNotFound) {
+		return ErrModerationCaseResolved
+	}
+	if err != nil {
+		return ErrCannotResolveModerationCase
+	}
+
+	recordAudit(ctx, u.auditRepo, moderationAuditEntry(ctx, entity.AuditModerationResolve, moderationCase, map[string]interface{}{
+		"action": input.Action,
+		"note":   input.Note,
+	}))
+	return nil
+}
+
+func (u *ModerationUseCase) GetModerationLog(ctx context.Context, input ModerationGetModerationLogInput) ([]entity.ModerationLogEntry, error) {
+	if !u.authorizer.Can(ctx, policy.ModerationRead, policy.Any) {
+		return nil, ErrHaveNoPermission
+	}
+
+	entries, err := u.moderationRepo.GetModerationLog(ctx, input.ModeratorID, input.CaseID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (u *ModerationUseCase) checkAction(ctx context.Context, moderationCase entity.ModerationCase, action entity.ModerationAction) error {
+	switch action {
+	case entity.ModerationActionDismiss:
+		return nil
+
+	case entity.ModerationActionHide, entity.ModerationActionDelete:
+		// users are banned, not hidden or deleted
+		if moderationCase.TargetType == entity.ReportTargetUser {
+			return ErrInvalidModerationAction
+		}
+		return nil
+
+	case entity.ModerationActionBan:
+		author, err := u.userRepo.GetUserByID(ctx, moderationCase.TargetAuthorID)
+		if errors.Is(err, repoerrs.ErrUserNotFound) {
+			return ErrReportTargetNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// only admin can ban moderators, nobody can ban admins
+		if !u.authorizer.Can(ctx, policy.ModerationBan, policy.User(author)) {
+			return ErrHaveNoPermission
+		}
+		return nil
+	}
+
+	return ErrInvalidModerationAction
+}
+
+func (u *ModerationUseCase) getTargetAuthor(ctx context.Context, targetType entity.ReportTargetType, targetID uuid.UUID) (uuid.UUID, error) {
+	switch targetType {
+	case entity.ReportTargetArticle:
+		article, err := u.articleRepo.GetArticleByID(ctx, targetID)
+		if errors.Is(err, repoerrs.ErrArticleNotFound) || (err == nil && article.HiddenAt != nil) {
+			return uuid.UUID{}, ErrReportTargetNotFound
+		}
+		return article.AuthorID, err
+
+	case entity.ReportTargetComment:
+		comment, err := u.commentRepo.GetCommentByID(ctx, targetID)
+		if errors.Is(err, repoerrs.ErrCommentNotFound) || (err == nil && comment.HiddenAt != nil) {
+			return uuid.UUID{}, ErrReportTargetNotFound
+		}
+		return comment.AuthorID, err
+
+	case entity.ReportTargetUser:
+		user, err := u.userRepo.GetUserByID(ctx, targetID)
+		if errors.Is(err, repoerrs.ErrUserNotFound) {
+			return uuid.UUID{}, ErrReportTargetNotFound
+		}
+		return user.ID, err
+	}
+
+	return uuid.UUID{}, ErrReportTargetNotFound
+}
+
+func (u *ModerationUseCase) getModerationCase(ctx context.Context, id uuid.UUID) (entity.ModerationCase, error) {
+	moderationCase, err := u.moderationRepo.GetModerationCaseByID(ctx, id)
+	if errors.Is(err, repoerrs.ErrModerationCaseNotFound) {
+		return entity.ModerationCase{}, ErrModerationCaseNotFound
+	}
+	if err != nil {
+		return entity.ModerationCase{}, err
+	}
+	return moderationCase, nil
+}
+
+// moderationAuditEntry - целью записи является кейс, цель кейса и ее автор сохраняются в details
+func moderationAuditEntry(
+	ctx context.Context,
+	action entity.AuditAction,
+	moderationCase entity.ModerationCase,
+	details map[string]interface{},
+) entity.AuditEntry {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["target_type"] = moderationCase.TargetType
+	details["target_id"] = moderationCase.TargetID
+	details["target_author_id"] = moderationCase.TargetAuthorID
+
+	return audit.NewEntry(ctx, action, entity.AuditTargetModerationCase, moderationCase.ID, details)
+}
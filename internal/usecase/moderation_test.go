@@ -0,0 +1,327 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func newModerationUseCase(d deps) *usecase.ModerationUseCase {
+	return usecase.NewModerationUseCase(d.moderationRepo, d.userRepo, d.articleRepo, d.commentRepo, d.auditRepo, d.authorizer)
+}
+
+func TestModerationUseCase_CreateReport(t *testing.T) {
+	reporterID := uuid.New()
+	article := entity.Article{Id: uuid.New(), AuthorID: uuid.New()}
+	hidden := article
+	hidden.HiddenAt = ptr(time.Now())
+	input := usecase.ModerationCreateReportInput{
+		ReporterID: reporterID,
+		TargetType: entity.ReportTargetArticle,
+		TargetID:   article.Id,
+		Reason:     entity.ReportReason("spam"),
+	}
+	report := entity.Report{ID: uuid.New(), ReporterID: reporterID}
+
+	tests := []struct {
+		name    string
+		input   usecase.ModerationCreateReportInput
+		prepare func(d deps)
+		want    entity.Report
+		err     error
+	}{
+		{
+			name:  "ok",
+			input: input,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.moderationRepo.EXPECT().CreateReport(gomock.Any(), entity.ModerationCase{
+					TargetType:     entity.ReportTargetArticle,
+					TargetID:       article.Id,
+					TargetAuthorID: article.AuthorID,
+				}, entity.Report{ReporterID: reporterID, Reason: "spam"}).Return(report, nil)
+			},
+			want: report,
+		},
+		{
+			name:  "target not found",
+			input: input,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(entity.Article{}, repoerrs.ErrArticleNotFound)
+			},
+			err: usecase.ErrReportTargetNotFound,
+		},
+		{
+			name:  "hidden target",
+			input: input,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(hidden, nil)
+			},
+			err: usecase.ErrReportTargetNotFound,
+		},
+		{
+			name: "report yourself",
+			input: usecase.ModerationCreateReportInput{
+				ReporterID: reporterID,
+				TargetType: entity.ReportTargetUser,
+				TargetID:   reporterID,
+			},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), reporterID).Return(entity.User{ID: reporterID}, nil)
+			},
+			err: usecase.ErrCannotReportYourself,
+		},
+		{
+			name:  "already reported",
+			input: input,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.moderationRepo.EXPECT().CreateReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(entity.Report{}, repoerrs.ErrReportAlreadyExists)
+			},
+			err: usecase.ErrReportAlreadyExists,
+		},
+		{
+			name:  "repo error",
+			input: input,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), article.Id).Return(article, nil)
+				d.moderationRepo.EXPECT().CreateReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(entity.Report{}, errInternal)
+			},
+			err: usecase.ErrCannotCreateReport,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			got, err := newModerationUseCase(d).CreateReport(context.Background(), tt.input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("report = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModerationUseCase_ClaimModerationCase(t *testing.T) {
+	moderatorID := uuid.New()
+	open := entity.ModerationCase{ID: uuid.New(), Status: entity.ModerationStatusOpen}
+	claimed := open
+	claimed.Status = entity.ModerationStatusClaimed
+	resolved := open
+	resolved.Status = entity.ModerationStatusResolved
+	input := usecase.ModerationClaimModerationCaseInput{RequestedUserID: moderatorID, ID: open.ID}
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.allow(policy.ModerationClaim, policy.Any, true)
+				d.moderationRepo.EXPECT().GetModerationCaseByID(gomock.Any(), open.ID).Return(open, nil)
+				d.moderationRepo.EXPECT().ClaimModerationCase(gomock.Any(), open, moderatorID).Return(nil)
+				d.expectAudit(entity.AuditModerationClaim, open.ID)
+			},
+		},
+		{
+			name: "no permission",
+			prepare: func(d deps) {
+				d.allow(policy.ModerationClaim, policy.Any, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "not found",
+			prepare: func(d deps) {
+				d.allow(policy.ModerationClaim, policy.Any, true)
+				d.moderationRepo.EXPECT().GetModerationCaseByID(gomock.Any(), open.ID).Return(entity.ModerationCase{}, repoerrs.ErrModerationCaseNotFound)
+			},
+			err: usecase.ErrModerationCaseNotFound,
+		},
+		{
+			name: "already claimed",
+			prepare: func(d deps) {
+				d.allow(policy.ModerationClaim, policy.Any, true)
+				d.moderationRepo.EXPECT().GetModerationCaseByID(gomock.Any(), open.ID).Return(claimed, nil)
+			},
+			err: usecase.ErrModerationCaseClaimed,
+		},
+		{
+			name: "already resolved",
+			prepare: func(d deps) {
+				d.allow(policy.ModerationClaim, policy.Any, true)
+				d.moderationRepo.EXPECT().GetModerationCaseByID(gomock.Any(), open.ID).Return(resolved, nil)
+			},
+			err: usecase.ErrModerationCaseResolved,
+		},
+		{
+			name: "claimed by another moderator concurrently",
+			prepare: func(d deps) {
+				d.allow(policy.ModerationClaim, policy.Any, true)
+				d.moderationRepo.EXPECT().GetModerationCaseByID(gomock.Any(), open.ID).Return(open, nil)
+				d.moderationRepo.EXPECT().ClaimModerationCase(gomock.Any(), open, moderatorID).Return(repoerrs.ErrModerationCaseNotFound)
+			},
+			err: usecase.ErrModerationCaseClaimed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			err := newModerationUseCase(d).ClaimModerationCase(context.Background(), input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestModerationUseCase_ResolveModerationCase(t *testing.T) {
+	moderatorID := uuid.New()
+	author := entity.User{ID: uuid.New(), Role: entity.RoleUser}
+	claimed := entity.ModerationCase{
+		ID:             uuid.New(),
+		TargetType:     entity.ReportTargetUser,
+		TargetID:       author.ID,
+		TargetAuthorID: author.ID,
+		Status:         entity.ModerationStatusClaimed,
+		AssigneeID:     uuid.NullUUID{UUID: moderatorID, Valid: true},
+	}
+	resolved := claimed
+	resolved.Status = entity.ModerationStatusResolved
+
+	resolve := func(action entity.ModerationAction) usecase.ModerationResolveModerationCaseInput {
+		return usecase.ModerationResolveModerationCaseInput{RequestedUserID: moderatorID, ID: claimed.ID, Action: action, Note: "note"}
+	}
+	// prepareCase - модератор видит очередь и получает кейс, allowed - может ли он его закрыть
+	prepareCase := func(d deps, moderationCase entity.ModerationCase, allowed bool) {
+		d.allow(policy.ModerationRead, policy.Any, true)
+		d.moderationRepo.EXPECT().GetModerationCaseByID(gomock.Any(), claimed.ID).Return(moderationCase, nil)
+		if moderationCase.Status != entity.ModerationStatusResolved {
+			d.allow(policy.ModerationResolve, policy.ModerationCase(moderationCase), allowed)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		input   usecase.ModerationResolveModerationCaseInput
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name:  "dismiss",
+			input: resolve(entity.ModerationActionDismiss),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, true)
+				d.moderationRepo.EXPECT().ResolveModerationCase(gomock.Any(), claimed, moderatorID, entity.ModerationActionDismiss, "note").Return(nil)
+				d.expectAudit(entity.AuditModerationResolve, claimed.ID)
+			},
+		},
+		{
+			name:  "ban",
+			input: resolve(entity.ModerationActionBan),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, true)
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), author.ID).Return(author, nil)
+				d.allow(policy.ModerationBan, policy.User(author), true)
+				d.moderationRepo.EXPECT().ResolveModerationCase(gomock.Any(), claimed, moderatorID, entity.ModerationActionBan, "note").Return(nil)
+				d.expectAudit(entity.AuditModerationResolve, claimed.ID)
+			},
+		},
+		{
+			name:  "ban not allowed",
+			input: resolve(entity.ModerationActionBan),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, true)
+				d.userRepo.EXPECT().GetUserByID(gomock.Any(), author.ID).Return(author, nil)
+				d.allow(policy.ModerationBan, policy.User(author), false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "hide a user",
+			input: resolve(entity.ModerationActionHide),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, true)
+			},
+			err: usecase.ErrInvalidModerationAction,
+		},
+		{
+			name:  "unknown action",
+			input: resolve(entity.ModerationActionClaim),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, true)
+			},
+			err: usecase.ErrInvalidModerationAction,
+		},
+		{
+			name:  "no permission",
+			input: resolve(entity.ModerationActionDismiss),
+			prepare: func(d deps) {
+				d.allow(policy.ModerationRead, policy.Any, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "claimed by another moderator",
+			input: resolve(entity.ModerationActionDismiss),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, false)
+			},
+			err: usecase.ErrModerationCaseNotClaimed,
+		},
+		{
+			name:  "already resolved",
+			input: resolve(entity.ModerationActionDismiss),
+			prepare: func(d deps) {
+				prepareCase(d, resolved, true)
+			},
+			err: usecase.ErrModerationCaseResolved,
+		},
+		{
+			name:  "resolved concurrently",
+			input: resolve(entity.ModerationActionDismiss),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, true)
+				d.moderationRepo.EXPECT().ResolveModerationCase(gomock.Any(), claimed, moderatorID, gomock.Any(), gomock.Any()).Return(repoerrs.ErrModerationCaseNotFound)
+			},
+			err: usecase.ErrModerationCaseResolved,
+		},
+		{
+			name:  "repo error",
+			input: resolve(entity.ModerationActionDismiss),
+			prepare: func(d deps) {
+				prepareCase(d, claimed, true)
+				d.moderationRepo.EXPECT().ResolveModerationCase(gomock.Any(), claimed, moderatorID, gomock.Any(), gomock.Any()).Return(errInternal)
+			},
+			err: usecase.ErrCannotResolveModerationCase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			err := newModerationUseCase(d).ResolveModerationCase(context.Background(), tt.input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
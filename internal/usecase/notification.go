@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type NotificationUseCase struct {
+	notificationRepo repo.Notification
+	articleRepo      repo.Article
+	commentRepo      repo.Comment
+	publisher        Publisher
+}
+
+var (
+	ErrCannotCreateNotification = apperror.New("cannot_create_notification", http.StatusInternalServerError, "cannot create notification")
+)
+
+func NewNotificationUseCase(notificationRepo repo.Notification, articleRepo repo.Article, commentRepo repo.Comment, publisher Publisher) *NotificationUseCase {
+	return &NotificationUseCase{
+		notificationRepo: notificationRepo,
+		articleRepo:      articleRepo,
+		commentRepo:      commentRepo,
+		publisher:        publisher,
+	}
+}
+
+func (u *NotificationUseCase) CreateNotification(ctx context.Context, input NotificationCreateNotificationInput) error {
+	// nobody needs to be notified about own actions
+	if input.UserID == input.ActorID {
+		return nil
+	}
+
+	notification, err := u.notificationRepo.CreateNotification(ctx, entity.Notification{
+		UserID:    input.UserID,
+		ActorID:   input.ActorID,
+		Type:      input.Type,
+		ArticleID: input.ArticleID,
+		CommentID: input.CommentID,
+	})
+	// the same event may be delivered more than once
+	if errors.Is(err, repoerrs.ErrNotificationAlreadyExists) {
+		return nil
+	}
+	if err != nil {
+		return ErrCannotCreateNotification
+	}
+
+	err = publishEvent(ctx, u.publisher, NotificationsTopic(notification.UserID), EventTypeNotification, NotificationEvent{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		ActorID:   notification.ActorID,
+		ArticleID: nullUUIDPtr(notification.ArticleID),
+		CommentID: nullUUIDPtr(notification.CommentID),
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Errorf("NotificationUseCase.CreateNotification - publishEvent: %v", err)
+	}
+
+	return nil
+}
+
+func (u *NotificationUseCase) GetNotifications(ctx context.Context, input NotificationGetNotificationsInput) ([]entity.Notification, error) {
+	notifications, err := u.notificationRepo.GetNotificationsByUserID(ctx, input.UserID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// HandleCommentPosted - уведомление автора статьи и автора родительского комментария о новом комментарии
+func (u *NotificationUseCase) HandleCommentPosted(ctx context.Context, event entity.Event) error {
+	var payload entity.CommentPostedPayload
+	err := event.Decode(&payload)
+	if err != nil {
+		return fmt.Errorf("event.Decode: %v", err)
+	}
+
+	article, err := u.articleRepo.GetArticleByID(ctx, payload.ArticleID)
+	if err != nil {
+		return err
+	}
+
+	err = u.CreateNotification(ctx, NotificationCreateNotificationInput{
+		UserID:    article.AuthorID,
+		ActorID:   payload.AuthorID,
+		Type:      entity.NotificationArticleComment,
+		ArticleID: uuid.NullUUID{UUID: article.Id, Valid: true},
+		CommentID: uuid.NullUUID{UUID: payload.CommentID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if !payload.ParentID.Valid {
+		return nil
+	}
+
+	parent, err := u.commentRepo.GetCommentByID(ctx, payload.ParentID.UUID)
+	if err != nil {
+		return err
+	}
+
+	// article author has already been notified
+	if parent.AuthorID == article.AuthorID {
+		return nil
+	}
+
+	return u.CreateNotification(ctx, NotificationCreateNotificationInput{
+		UserID:    parent.AuthorID,
+		ActorID:   payload.AuthorID,
+		Type:      entity.NotificationCommentReply,
+		ArticleID: uuid.NullUUID{UUID: article.Id, Valid: true},
+		CommentID: uuid.NullUUID{UUID: payload.CommentID, Valid: true},
+	})
+}
+
+// HandleUserFollowed - уведомление пользователя о новом подписчике
+func (u *NotificationUseCase) HandleUserFollowed(ctx context.Context, event entity.Event) error {
+	var payload entity.UserFollowedPayload
+	err := event.Decode(&payload)
+	if err != nil {
+		return fmt.Errorf("event.Decode: %v", err)
+	}
+
+	return u.CreateNotification(ctx, NotificationCreateNotificationInput{
+		UserID:  payload.FollowingID,
+		ActorID: payload.FollowerID,
+		Type:    entity.NotificationNewFollower,
+	})
+}
+
+// HandleArticleFavorited - уведомление автора статьи о добавлении ее в избранное
+func (u *NotificationUseCase) HandleArticleFavorited(ctx context.Context, event entity.Event) error {
+	var payload entity.ArticleFavoritedPayload
+	err := event.Decode(&payload)
+	if err != nil {
+		return fmt.Errorf("event.Decode: %v", err)
+	}
+
+	article, err := u.articleRepo.GetArticleByID(ctx, payload.ArticleID)
+	// the article was deleted before the event was delivered, there is nothing to notify about
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return u.CreateNotification(ctx, NotificationCreateNotificationInput{
+		UserID:    article.AuthorID,
+		ActorID:   payload.UserID,
+		Type:      entity.NotificationArticleFavorite,
+		ArticleID: uuid.NullUUID{UUID: article.Id, Valid: true},
+	})
+}
@@ -0,0 +1,133 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"blog-backend/pkg/pubsub"
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func newEvent(t *testing.T, eventType entity.EventType, aggregateID uuid.UUID, payload any) entity.Event {
+	t.Helper()
+
+	event, err := entity.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestNotificationUseCase_HandleUserFollowed(t *testing.T) {
+	followerID, followingID := uuid.New(), uuid.New()
+	event := newEvent(t, entity.EventUserFollowed, followingID, entity.UserFollowedPayload{FollowerID: followerID, FollowingID: followingID})
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.notificationRepo.EXPECT().CreateNotification(gomock.Any(), entity.Notification{
+					UserID:  followingID,
+					ActorID: followerID,
+					Type:    entity.NotificationNewFollower,
+				}).Return(entity.Notification{ID: uuid.New(), UserID: followingID}, nil)
+			},
+		},
+		{
+			name: "delivered again",
+			prepare: func(d deps) {
+				d.notificationRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).
+					Return(entity.Notification{}, repoerrs.ErrNotificationAlreadyExists)
+			},
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.notificationRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(entity.Notification{}, errInternal)
+			},
+			err: usecase.ErrCannotCreateNotification,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			u := usecase.NewNotificationUseCase(d.notificationRepo, d.articleRepo, d.commentRepo, pubsub.New(nil))
+			err := u.HandleUserFollowed(context.Background(), event)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNotificationUseCase_HandleArticleFavorited(t *testing.T) {
+	authorID, userID, articleID := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name:   "ok",
+			userID: userID,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), articleID).Return(entity.Article{Id: articleID, AuthorID: authorID}, nil)
+				d.notificationRepo.EXPECT().CreateNotification(gomock.Any(), entity.Notification{
+					UserID:    authorID,
+					ActorID:   userID,
+					Type:      entity.NotificationArticleFavorite,
+					ArticleID: uuid.NullUUID{UUID: articleID, Valid: true},
+				}).Return(entity.Notification{ID: uuid.New(), UserID: authorID}, nil)
+			},
+		},
+		{
+			name:   "own article",
+			userID: authorID,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), articleID).Return(entity.Article{Id: articleID, AuthorID: authorID}, nil)
+			},
+		},
+		{
+			name:   "article deleted",
+			userID: userID,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), articleID).Return(entity.Article{}, repoerrs.ErrArticleNotFound)
+			},
+		},
+		{
+			name:   "repo error",
+			userID: userID,
+			prepare: func(d deps) {
+				d.articleRepo.EXPECT().GetArticleByID(gomock.Any(), articleID).Return(entity.Article{}, errInternal)
+			},
+			err: errInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			event := newEvent(t, entity.EventArticleFavorited, articleID, entity.ArticleFavoritedPayload{ArticleID: articleID, UserID: tt.userID})
+
+			u := usecase.NewNotificationUseCase(d.notificationRepo, d.articleRepo, d.commentRepo, pubsub.New(nil))
+			err := u.HandleArticleFavorited(context.Background(), event)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/pubsub"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+const (
+	EventTypeNotification = "notification"
+	EventTypeComment      = "comment"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, msg pubsub.Message) error
+}
+
+type NotificationEvent struct {
+	ID        uuid.UUID               `json:"id"`
+	Type      entity.NotificationType `json:"type"`
+	ActorID   uuid.UUID               `json:"actor_id"`
+	ArticleID *uuid.UUID              `json:"article_id,omitempty"`
+	CommentID *uuid.UUID              `json:"comment_id,omitempty"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+type CommentEvent struct {
+	ID        uuid.UUID  `json:"id"`
+	ArticleID uuid.UUID  `json:"article_id"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	AuthorID  uuid.UUID  `json:"author_id"`
+	Content   string     `json:"content,omitempty"`
+	Truncated bool       `json:"truncated,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NotificationsTopic(userID uuid.UUID) string {
+	return "notifications:" + userID.String()
+}
+
+func ArticleCommentsTopic(articleID uuid.UUID) string {
+	return "articles:" + articleID.String() + ":comments"
+}
+
+type StreamUseCase struct {
+	articleRepo repo.Article
+	pubSub      *pubsub.PubSub
+}
+
+var (
+	ErrCannotSubscribe = apperror.New("cannot_subscribe", http.StatusInternalServerError, "cannot subscribe")
+)
+
+func NewStreamUseCase(articleRepo repo.Article, pubSub *pubsub.PubSub) *StreamUseCase {
+	return &StreamUseCase{
+		articleRepo: articleRepo,
+		pubSub:      pubSub,
+	}
+}
+
+func (u *StreamUseCase) Subscribe(ctx context.Context, input StreamSubscribeInput) (*pubsub.Subscription, error) {
+	var topics []string
+	if input.Notifications {
+		topics = append(topics, NotificationsTopic(input.UserID))
+	}
+
+	for _, articleID := range input.ArticleIDs {
+		err := u.checkArticle(ctx, articleID)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, ArticleCommentsTopic(articleID))
+	}
+
+	sub, err := u.pubSub.Subscribe(topics...)
+	if err != nil {
+		return nil, ErrCannotSubscribe
+	}
+	return sub, nil
+}
+
+func (u *StreamUseCase) SubscribeArticleComments(ctx context.Context, input StreamSubscribeArticleCommentsInput) error {
+	err := u.checkArticle(ctx, input.ArticleID)
+	if err != nil {
+		return err
+	}
+
+	err = input.Subscription.Subscribe(ArticleCommentsTopic(input.ArticleID))
+	if err != nil {
+		return ErrCannotSubscribe
+	}
+	return nil
+}
+
+func (u *StreamUseCase) UnsubscribeArticleComments(ctx context.Context, input StreamUnsubscribeArticleCommentsInput) {
+	input.Subscription.Unsubscribe(ArticleCommentsTopic(input.ArticleID))
+}
+
+func (u *StreamUseCase) checkArticle(ctx context.Context, articleID uuid.UUID) error {
+	_, err := u.articleRepo.GetArticleByID(ctx, articleID)
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// publishEvent - best effort delivery of realtime event, the change itself is already stored
+func publishEvent(ctx context.Context, publisher Publisher, topic, eventType string, event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %v", err)
+	}
+
+	return publisher.Publish(ctx, pubsub.Message{
+		Topic: topic,
+		Type:  eventType,
+		Data:  data,
+	})
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
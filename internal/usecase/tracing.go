@@ -0,0 +1,518 @@
+package usecase
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/pubsub"
+	"context"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"time"
+)
+
+var tracer = otel.Tracer("blog-backend/internal/usecase")
+
+// WithTracing - use case с дочерним спаном на каждый вызов. Вызовы одного use case из другого
+// идут мимо оберток и отдельных спанов не получают
+func WithTracing(useCases *UseCases) *UseCases {
+	return &UseCases{
+		Auth:         &authTracing{next: useCases.Auth},
+		Admin:        &adminTracing{next: useCases.Admin},
+		User:         &userTracing{next: useCases.User},
+		Account:      &accountTracing{next: useCases.Account},
+		Article:      &articleTracing{next: useCases.Article},
+		Comment:      &commentTracing{next: useCases.Comment},
+		Notification: &notificationTracing{next: useCases.Notification},
+		Stream:       &streamTracing{next: useCases.Stream},
+		Webhook:      &webhookTracing{next: useCases.Webhook},
+		Moderation:   &moderationTracing{next: useCases.Moderation},
+	}
+}
+
+// endSpan - ошибки клиента (4xx) отмечаются только кодом, ошибкой спан помечается при сбое сервиса
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		appErr := apperror.From(err)
+		span.SetAttributes(attribute.String("error.code", appErr.Code))
+		if appErr.Status >= http.StatusInternalServerError {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, appErr.Message)
+		}
+	}
+	span.End()
+}
+
+type authTracing struct {
+	next Auth
+}
+
+func (u *authTracing) GenerateToken(ctx context.Context, input AuthGenerateTokenInput) (string, error) {
+	ctx, span := tracer.Start(ctx, "Auth.GenerateToken")
+	res, err := u.next.GenerateToken(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *authTracing) ParseToken(ctx context.Context, input AuthParseTokenInput) (Session, error) {
+	ctx, span := tracer.Start(ctx, "Auth.ParseToken")
+	res, err := u.next.ParseToken(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *authTracing) GetTokenTTL() (time.Duration, error) {
+	return u.next.GetTokenTTL()
+}
+
+type adminTracing struct {
+	next Admin
+}
+
+func (u *adminTracing) SearchUsers(ctx context.Context, input AdminSearchUsersInput) ([]entity.User, error) {
+	ctx, span := tracer.Start(ctx, "Admin.SearchUsers")
+	res, err := u.next.SearchUsers(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *adminTracing) SetUserRole(ctx context.Context, input AdminSetUserRoleInput) error {
+	ctx, span := tracer.Start(ctx, "Admin.SetUserRole")
+	err := u.next.SetUserRole(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *adminTracing) ResetUserPassword(ctx context.Context, input AdminUserInput) (string, error) {
+	ctx, span := tracer.Start(ctx, "Admin.ResetUserPassword")
+	res, err := u.next.ResetUserPassword(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *adminTracing) RevokeUserSessions(ctx context.Context, input AdminUserInput) error {
+	ctx, span := tracer.Start(ctx, "Admin.RevokeUserSessions")
+	err := u.next.RevokeUserSessions(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *adminTracing) BanUser(ctx context.Context, input AdminUserInput) error {
+	ctx, span := tracer.Start(ctx, "Admin.BanUser")
+	err := u.next.BanUser(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *adminTracing) UnbanUser(ctx context.Context, input AdminUserInput) error {
+	ctx, span := tracer.Start(ctx, "Admin.UnbanUser")
+	err := u.next.UnbanUser(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *adminTracing) Impersonate(ctx context.Context, input AdminUserInput) (string, error) {
+	ctx, span := tracer.Start(ctx, "Admin.Impersonate")
+	res, err := u.next.Impersonate(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *adminTracing) BulkAction(ctx context.Context, input AdminBulkActionInput) ([]AdminBulkResult, error) {
+	ctx, span := tracer.Start(ctx, "Admin.BulkAction")
+	res, err := u.next.BulkAction(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *adminTracing) GetAuditLog(ctx context.Context, input AdminGetAuditLogInput) ([]entity.AuditEntry, error) {
+	ctx, span := tracer.Start(ctx, "Admin.GetAuditLog")
+	res, err := u.next.GetAuditLog(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *adminTracing) ExportAuditLog(ctx context.Context, input AdminExportAuditLogInput, w io.Writer) error {
+	ctx, span := tracer.Start(ctx, "Admin.ExportAuditLog")
+	err := u.next.ExportAuditLog(ctx, input, w)
+	endSpan(span, err)
+	return err
+}
+
+type userTracing struct {
+	next User
+}
+
+func (u *userTracing) CreateUser(ctx context.Context, input UserCreateUserInput) (uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "User.CreateUser")
+	res, err := u.next.CreateUser(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *userTracing) GetUserByUsername(ctx context.Context, input UserGetUserByUsernameInput) (entity.User, error) {
+	ctx, span := tracer.Start(ctx, "User.GetUserByUsername")
+	res, err := u.next.GetUserByUsername(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *userTracing) GetUsersByIDs(ctx context.Context, input UserGetUsersByIDsInput) ([]entity.User, error) {
+	ctx, span := tracer.Start(ctx, "User.GetUsersByIDs")
+	res, err := u.next.GetUsersByIDs(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *userTracing) FollowUser(ctx context.Context, input UserFollowUserInput) error {
+	ctx, span := tracer.Start(ctx, "User.FollowUser")
+	err := u.next.FollowUser(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *userTracing) UpdateUser(ctx context.Context, input UserUpdateUserInput) error {
+	ctx, span := tracer.Start(ctx, "User.UpdateUser")
+	err := u.next.UpdateUser(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *userTracing) UpdateUserPassword(ctx context.Context, input UserUpdateUserPasswordInput) error {
+	ctx, span := tracer.Start(ctx, "User.UpdateUserPassword")
+	err := u.next.UpdateUserPassword(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *userTracing) DeleteUser(ctx context.Context, input UserDeleteUserInput) error {
+	ctx, span := tracer.Start(ctx, "User.DeleteUser")
+	err := u.next.DeleteUser(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *userTracing) RestoreUser(ctx context.Context, input UserRestoreUserInput) error {
+	ctx, span := tracer.Start(ctx, "User.RestoreUser")
+	err := u.next.RestoreUser(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+type accountTracing struct {
+	next Account
+}
+
+func (u *accountTracing) RequestExport(ctx context.Context, input AccountRequestExportInput) (entity.UserExport, error) {
+	ctx, span := tracer.Start(ctx, "Account.RequestExport")
+	res, err := u.next.RequestExport(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *accountTracing) GetExport(ctx context.Context, input AccountGetExportInput) (entity.UserExport, error) {
+	ctx, span := tracer.Start(ctx, "Account.GetExport")
+	res, err := u.next.GetExport(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *accountTracing) GetExportArchive(ctx context.Context, input AccountGetExportInput) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "Account.GetExportArchive")
+	res, err := u.next.GetExportArchive(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *accountTracing) ScheduleDeletion(ctx context.Context, input AccountScheduleDeletionInput) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Account.ScheduleDeletion")
+	res, err := u.next.ScheduleDeletion(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *accountTracing) CancelDeletion(ctx context.Context, input AccountCancelDeletionInput) error {
+	ctx, span := tracer.Start(ctx, "Account.CancelDeletion")
+	err := u.next.CancelDeletion(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+type articleTracing struct {
+	next Article
+}
+
+func (u *articleTracing) CreateArticle(ctx context.Context, input ArticleCreateArticleInput) (uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "Article.CreateArticle")
+	res, err := u.next.CreateArticle(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) GetArticleByID(ctx context.Context, input ArticleGetArticleByIDInput) (entity.Article, error) {
+	ctx, span := tracer.Start(ctx, "Article.GetArticleByID")
+	res, err := u.next.GetArticleByID(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) UpdateArticle(ctx context.Context, input ArticleUpdateArticleInput) error {
+	ctx, span := tracer.Start(ctx, "Article.UpdateArticle")
+	err := u.next.UpdateArticle(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *articleTracing) GetArticlesByAuthorID(ctx context.Context, input ArticleGetArticlesByAuthorIDInput) ([]entity.Article, error) {
+	ctx, span := tracer.Start(ctx, "Article.GetArticlesByAuthorID")
+	res, err := u.next.GetArticlesByAuthorID(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) GetNewestArticles(ctx context.Context, input ArticleGetNewestArticlesInput) ([]entity.Article, error) {
+	ctx, span := tracer.Start(ctx, "Article.GetNewestArticles")
+	res, err := u.next.GetNewestArticles(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) SetArticleFavorite(ctx context.Context, input ArticleSetArticleFavoriteInput) error {
+	ctx, span := tracer.Start(ctx, "Article.SetArticleFavorite")
+	err := u.next.SetArticleFavorite(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *articleTracing) RemoveArticleFavorite(ctx context.Context, input ArticleRemoveArticleFavoriteInput) error {
+	ctx, span := tracer.Start(ctx, "Article.RemoveArticleFavorite")
+	err := u.next.RemoveArticleFavorite(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *articleTracing) GetFavoriteArticles(ctx context.Context, input ArticleGetFavoriteArticlesInput) ([]entity.Article, error) {
+	ctx, span := tracer.Start(ctx, "Article.GetFavoriteArticles")
+	res, err := u.next.GetFavoriteArticles(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) GetFavoritedArticleIDs(ctx context.Context, input ArticleGetFavoritedArticleIDsInput) ([]uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "Article.GetFavoritedArticleIDs")
+	res, err := u.next.GetFavoritedArticleIDs(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) GetFeed(ctx context.Context, input ArticleGetFeedInput) ([]entity.Article, error) {
+	ctx, span := tracer.Start(ctx, "Article.GetFeed")
+	res, err := u.next.GetFeed(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) SearchArticles(ctx context.Context, input ArticleSearchArticlesInput) ([]entity.Article, error) {
+	ctx, span := tracer.Start(ctx, "Article.SearchArticles")
+	res, err := u.next.SearchArticles(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) GetArticlesTags(ctx context.Context, input ArticleGetArticlesTagsInput) (map[uuid.UUID][]entity.Tag, error) {
+	ctx, span := tracer.Start(ctx, "Article.GetArticlesTags")
+	res, err := u.next.GetArticlesTags(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *articleTracing) DeleteArticle(ctx context.Context, input ArticleDeleteArticleInput) error {
+	ctx, span := tracer.Start(ctx, "Article.DeleteArticle")
+	err := u.next.DeleteArticle(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *articleTracing) RestoreArticle(ctx context.Context, input ArticleRestoreArticleInput) error {
+	ctx, span := tracer.Start(ctx, "Article.RestoreArticle")
+	err := u.next.RestoreArticle(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+type commentTracing struct {
+	next Comment
+}
+
+func (u *commentTracing) CreateComment(ctx context.Context, input CommentCreateCommentInput) (uuid.UUID, error) {
+	ctx, span := tracer.Start(ctx, "Comment.CreateComment")
+	res, err := u.next.CreateComment(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *commentTracing) GetCommentsByArticleID(ctx context.Context, input CommentGetCommentsByArticleIDInput) ([]entity.Comment, error) {
+	ctx, span := tracer.Start(ctx, "Comment.GetCommentsByArticleID")
+	res, err := u.next.GetCommentsByArticleID(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *commentTracing) UpdateComment(ctx context.Context, input CommentUpdateCommentInput) error {
+	ctx, span := tracer.Start(ctx, "Comment.UpdateComment")
+	err := u.next.UpdateComment(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *commentTracing) DeleteComment(ctx context.Context, input CommentDeleteCommentInput) error {
+	ctx, span := tracer.Start(ctx, "Comment.DeleteComment")
+	err := u.next.DeleteComment(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *commentTracing) RestoreComment(ctx context.Context, input CommentRestoreCommentInput) error {
+	ctx, span := tracer.Start(ctx, "Comment.RestoreComment")
+	err := u.next.RestoreComment(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+type notificationTracing struct {
+	next Notification
+}
+
+func (u *notificationTracing) CreateNotification(ctx context.Context, input NotificationCreateNotificationInput) error {
+	ctx, span := tracer.Start(ctx, "Notification.CreateNotification")
+	err := u.next.CreateNotification(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *notificationTracing) GetNotifications(ctx context.Context, input NotificationGetNotificationsInput) ([]entity.Notification, error) {
+	ctx, span := tracer.Start(ctx, "Notification.GetNotifications")
+	res, err := u.next.GetNotifications(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+type streamTracing struct {
+	next Stream
+}
+
+func (u *streamTracing) Subscribe(ctx context.Context, input StreamSubscribeInput) (*pubsub.Subscription, error) {
+	ctx, span := tracer.Start(ctx, "Stream.Subscribe")
+	res, err := u.next.Subscribe(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *streamTracing) SubscribeArticleComments(ctx context.Context, input StreamSubscribeArticleCommentsInput) error {
+	ctx, span := tracer.Start(ctx, "Stream.SubscribeArticleComments")
+	err := u.next.SubscribeArticleComments(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *streamTracing) UnsubscribeArticleComments(ctx context.Context, input StreamUnsubscribeArticleCommentsInput) {
+	ctx, span := tracer.Start(ctx, "Stream.UnsubscribeArticleComments")
+	u.next.UnsubscribeArticleComments(ctx, input)
+	span.End()
+}
+
+type webhookTracing struct {
+	next Webhook
+}
+
+func (u *webhookTracing) CreateWebhook(ctx context.Context, input WebhookCreateWebhookInput) (entity.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "Webhook.CreateWebhook")
+	res, err := u.next.CreateWebhook(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *webhookTracing) GetWebhooks(ctx context.Context, input WebhookGetWebhooksInput) ([]entity.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "Webhook.GetWebhooks")
+	res, err := u.next.GetWebhooks(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *webhookTracing) UpdateWebhook(ctx context.Context, input WebhookUpdateWebhookInput) error {
+	ctx, span := tracer.Start(ctx, "Webhook.UpdateWebhook")
+	err := u.next.UpdateWebhook(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *webhookTracing) DeleteWebhook(ctx context.Context, input WebhookDeleteWebhookInput) error {
+	ctx, span := tracer.Start(ctx, "Webhook.DeleteWebhook")
+	err := u.next.DeleteWebhook(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *webhookTracing) GetDeliveries(ctx context.Context, input WebhookGetDeliveriesInput) ([]entity.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "Webhook.GetDeliveries")
+	res, err := u.next.GetDeliveries(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *webhookTracing) Redeliver(ctx context.Context, input WebhookRedeliverInput) error {
+	ctx, span := tracer.Start(ctx, "Webhook.Redeliver")
+	err := u.next.Redeliver(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+type moderationTracing struct {
+	next Moderation
+}
+
+func (u *moderationTracing) CreateReport(ctx context.Context, input ModerationCreateReportInput) (entity.Report, error) {
+	ctx, span := tracer.Start(ctx, "Moderation.CreateReport")
+	res, err := u.next.CreateReport(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *moderationTracing) GetModerationCases(ctx context.Context, input ModerationGetModerationCasesInput) ([]entity.ModerationCase, error) {
+	ctx, span := tracer.Start(ctx, "Moderation.GetModerationCases")
+	res, err := u.next.GetModerationCases(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u *moderationTracing) GetModerationCase(ctx context.Context, input ModerationGetModerationCaseInput) (entity.ModerationCase, []entity.Report, error) {
+	ctx, span := tracer.Start(ctx, "Moderation.GetModerationCase")
+	moderationCase, reports, err := u.next.GetModerationCase(ctx, input)
+	endSpan(span, err)
+	return moderationCase, reports, err
+}
+
+func (u *moderationTracing) ClaimModerationCase(ctx context.Context, input ModerationClaimModerationCaseInput) error {
+	ctx, span := tracer.Start(ctx, "Moderation.ClaimModerationCase")
+	err := u.next.ClaimModerationCase(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *moderationTracing) ResolveModerationCase(ctx context.Context, input ModerationResolveModerationCaseInput) error {
+	ctx, span := tracer.Start(ctx, "Moderation.ResolveModerationCase")
+	err := u.next.ResolveModerationCase(ctx, input)
+	endSpan(span, err)
+	return err
+}
+
+func (u *moderationTracing) GetModerationLog(ctx context.Context, input ModerationGetModerationLogInput) ([]entity.ModerationLogEntry, error) {
+	ctx, span := tracer.Start(ctx, "Moderation.GetModerationLog")
+	res, err := u.next.GetModerationLog(ctx, input)
+	endSpan(span, err)
+	return res, err
+}
@@ -2,10 +2,14 @@ package usecase
 
 import (
 	"blog-backend/internal/entity"
+	"blog-backend/internal/outbox"
+	"blog-backend/internal/policy"
 	"blog-backend/internal/repo"
 	"blog-backend/pkg/hasher"
+	"blog-backend/pkg/pubsub"
 	"context"
 	"github.com/google/uuid"
+	"io"
 	"time"
 )
 
@@ -13,45 +17,170 @@ import (
 
 type Auth interface {
 	GenerateToken(ctx context.Context, input AuthGenerateTokenInput) (string, error)
-	ParseToken(ctx context.Context, input AuthParseTokenInput) (uuid.UUID, entity.RoleType, error)
+	ParseToken(ctx context.Context, input AuthParseTokenInput) (Session, error)
 	GetTokenTTL() (time.Duration, error)
 }
 
+type Admin interface {
+	SearchUsers(ctx context.Context, input AdminSearchUsersInput) ([]entity.User, error)
+	SetUserRole(ctx context.Context, input AdminSetUserRoleInput) error
+	ResetUserPassword(ctx context.Context, input AdminUserInput) (string, error)
+	RevokeUserSessions(ctx context.Context, input AdminUserInput) error
+	BanUser(ctx context.Context, input AdminUserInput) error
+	UnbanUser(ctx context.Context, input AdminUserInput) error
+	Impersonate(ctx context.Context, input AdminUserInput) (string, error)
+	BulkAction(ctx context.Context, input AdminBulkActionInput) ([]AdminBulkResult, error)
+	GetAuditLog(ctx context.Context, input AdminGetAuditLogInput) ([]entity.AuditEntry, error)
+	ExportAuditLog(ctx context.Context, input AdminExportAuditLogInput, w io.Writer) error
+}
+
 type User interface {
 	CreateUser(ctx context.Context, input UserCreateUserInput) (uuid.UUID, error)
 	GetUserByUsername(ctx context.Context, input UserGetUserByUsernameInput) (entity.User, error)
+	GetUsersByIDs(ctx context.Context, input UserGetUsersByIDsInput) ([]entity.User, error)
+	FollowUser(ctx context.Context, input UserFollowUserInput) error
 	UpdateUser(ctx context.Context, input UserUpdateUserInput) error
 	UpdateUserPassword(ctx context.Context, input UserUpdateUserPasswordInput) error
+	DeleteUser(ctx context.Context, input UserDeleteUserInput) error
+	RestoreUser(ctx context.Context, input UserRestoreUserInput) error
+}
+
+type Account interface {
+	RequestExport(ctx context.Context, input AccountRequestExportInput) (entity.UserExport, error)
+	GetExport(ctx context.Context, input AccountGetExportInput) (entity.UserExport, error)
+	GetExportArchive(ctx context.Context, input AccountGetExportInput) ([]byte, error)
+	ScheduleDeletion(ctx context.Context, input AccountScheduleDeletionInput) (time.Time, error)
+	CancelDeletion(ctx context.Context, input AccountCancelDeletionInput) error
 }
 
 type Article interface {
 	CreateArticle(ctx context.Context, input ArticleCreateArticleInput) (uuid.UUID, error)
 	GetArticleByID(ctx context.Context, input ArticleGetArticleByIDInput) (entity.Article, error)
+	UpdateArticle(ctx context.Context, input ArticleUpdateArticleInput) error
 	GetArticlesByAuthorID(ctx context.Context, input ArticleGetArticlesByAuthorIDInput) ([]entity.Article, error)
 	GetNewestArticles(ctx context.Context, input ArticleGetNewestArticlesInput) ([]entity.Article, error)
 	SetArticleFavorite(ctx context.Context, input ArticleSetArticleFavoriteInput) error
 	RemoveArticleFavorite(ctx context.Context, input ArticleRemoveArticleFavoriteInput) error
 	GetFavoriteArticles(ctx context.Context, input ArticleGetFavoriteArticlesInput) ([]entity.Article, error)
+	GetFavoritedArticleIDs(ctx context.Context, input ArticleGetFavoritedArticleIDsInput) ([]uuid.UUID, error)
+	GetFeed(ctx context.Context, input ArticleGetFeedInput) ([]entity.Article, error)
+	SearchArticles(ctx context.Context, input ArticleSearchArticlesInput) ([]entity.Article, error)
+	GetArticlesTags(ctx context.Context, input ArticleGetArticlesTagsInput) (map[uuid.UUID][]entity.Tag, error)
+	DeleteArticle(ctx context.Context, input ArticleDeleteArticleInput) error
+	RestoreArticle(ctx context.Context, input ArticleRestoreArticleInput) error
+}
+
+type Comment interface {
+	CreateComment(ctx context.Context, input CommentCreateCommentInput) (uuid.UUID, error)
+	GetCommentsByArticleID(ctx context.Context, input CommentGetCommentsByArticleIDInput) ([]entity.Comment, error)
+	UpdateComment(ctx context.Context, input CommentUpdateCommentInput) error
+	DeleteComment(ctx context.Context, input CommentDeleteCommentInput) error
+	RestoreComment(ctx context.Context, input CommentRestoreCommentInput) error
+}
+
+type Notification interface {
+	CreateNotification(ctx context.Context, input NotificationCreateNotificationInput) error
+	GetNotifications(ctx context.Context, input NotificationGetNotificationsInput) ([]entity.Notification, error)
+}
+
+type Stream interface {
+	Subscribe(ctx context.Context, input StreamSubscribeInput) (*pubsub.Subscription, error)
+	SubscribeArticleComments(ctx context.Context, input StreamSubscribeArticleCommentsInput) error
+	UnsubscribeArticleComments(ctx context.Context, input StreamUnsubscribeArticleCommentsInput)
+}
+
+type Webhook interface {
+	CreateWebhook(ctx context.Context, input WebhookCreateWebhookInput) (entity.Webhook, error)
+	GetWebhooks(ctx context.Context, input WebhookGetWebhooksInput) ([]entity.Webhook, error)
+	UpdateWebhook(ctx context.Context, input WebhookUpdateWebhookInput) error
+	DeleteWebhook(ctx context.Context, input WebhookDeleteWebhookInput) error
+	GetDeliveries(ctx context.Context, input WebhookGetDeliveriesInput) ([]entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, input WebhookRedeliverInput) error
+}
+
+type Moderation interface {
+	CreateReport(ctx context.Context, input ModerationCreateReportInput) (entity.Report, error)
+	GetModerationCases(ctx context.Context, input ModerationGetModerationCasesInput) ([]entity.ModerationCase, error)
+	GetModerationCase(ctx context.Context, input ModerationGetModerationCaseInput) (entity.ModerationCase, []entity.Report, error)
+	ClaimModerationCase(ctx context.Context, input ModerationClaimModerationCaseInput) error
+	ResolveModerationCase(ctx context.Context, input ModerationResolveModerationCaseInput) error
+	GetModerationLog(ctx context.Context, input ModerationGetModerationLogInput) ([]entity.ModerationLogEntry, error)
+}
+
+type EventSubscriber interface {
+	Subscribe(eventType entity.EventType, handler outbox.HandlerFunc)
+}
+
+// BusinessMetrics - счетчики бизнес-событий, см. metrics.Metrics
+type BusinessMetrics interface {
+	SignUp()
+	SignIn(result string)
+	ArticleCreated()
+	ArticleFavorited()
+}
+
+// TxManager - выполнение fn в одной транзакции, репозитории берут ее из контекста, см. postgres.TxManager
+type TxManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Authorizer - проверка прав субъекта из контекста, см. policy.Policy
+type Authorizer interface {
+	Can(ctx context.Context, action policy.Action, resource policy.Resource) bool
 }
 
 type UseCases struct {
-	Auth    Auth
-	User    User
-	Article Article
+	Auth         Auth
+	Admin        Admin
+	User         User
+	Account      Account
+	Article      Article
+	Comment      Comment
+	Notification Notification
+	Stream       Stream
+	Webhook      Webhook
+	Moderation   Moderation
 }
 
 type UseCasesDependencies struct {
-	Repos  *repo.Repositories
-	Hasher hasher.PasswordHasher
+	Repos   *repo.Repositories
+	Tx      TxManager
+	Hasher  hasher.PasswordHasher
+	PubSub  *pubsub.PubSub
+	Events  EventSubscriber
+	Policy  Authorizer
+	Metrics BusinessMetrics
 
 	SignKey  string
 	TokenTTL time.Duration
+
+	AccountDeletionGracePeriod time.Duration
 }
 
 func NewUseCases(deps UseCasesDependencies) *UseCases {
+	notification := NewNotificationUseCase(deps.Repos, deps.Repos, deps.Repos, deps.PubSub)
+	webhook := NewWebhookUseCase(deps.Repos, deps.Repos, deps.Policy)
+
+	// side effects of domain events
+	deps.Events.Subscribe(entity.EventCommentPosted, notification.HandleCommentPosted)
+	deps.Events.Subscribe(entity.EventUserFollowed, notification.HandleUserFollowed)
+	deps.Events.Subscribe(entity.EventArticleFavorited, notification.HandleArticleFavorited)
+	for _, eventType := range entity.WebhookEventTypes {
+		deps.Events.Subscribe(eventType, webhook.HandleEvent)
+	}
+
+	auth := NewAuthUseCase(deps.Repos, deps.Repos, deps.Hasher, deps.Metrics, deps.SignKey, deps.TokenTTL)
+
 	return &UseCases{
-		Auth:    NewAuthUseCase(deps.Repos, deps.Hasher, deps.SignKey, deps.TokenTTL),
-		User:    NewUserUseCase(deps.Repos, deps.Hasher),
-		Article: NewArticleUseCase(deps.Repos),
+		Auth:         auth,
+		Admin:        NewAdminUseCase(deps.Repos, deps.Repos, deps.Repos, deps.Tx, deps.Hasher, auth, deps.Policy),
+		User:         NewUserUseCase(deps.Repos, deps.Repos, deps.Tx, deps.Hasher, deps.Policy, deps.Metrics),
+		Account:      NewAccountUseCase(deps.Repos, deps.Repos, deps.Hasher, deps.Policy, deps.AccountDeletionGracePeriod),
+		Article:      NewArticleUseCase(deps.Repos, deps.Tx, deps.Policy, deps.Metrics),
+		Comment:      NewCommentUseCase(deps.Repos, deps.Repos, deps.PubSub, deps.Policy),
+		Notification: notification,
+		Stream:       NewStreamUseCase(deps.Repos, deps.PubSub),
+		Webhook:      webhook,
+		Moderation:   NewModerationUseCase(deps.Repos, deps.Repos, deps.Repos, deps.Repos, deps.Repos, deps.Policy),
 	}
 }
@@ -1,32 +1,45 @@
 package usecase
 
 import (
+	"blog-backend/internal/audit"
 	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
 	"blog-backend/internal/repo"
 	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
 	"blog-backend/pkg/hasher"
 	"context"
-	"fmt"
+	"errors"
 	"github.com/google/uuid"
+	"net/http"
 )
 
 type UserUseCase struct {
 	userRepo       repo.User
+	auditRepo      repo.Audit
+	txManager      TxManager
 	passwordHasher hasher.PasswordHasher
+	authorizer     Authorizer
+	metrics        BusinessMetrics
 }
 
 var (
-	ErrUserAlreadyExists               = fmt.Errorf("user already exists")
-	ErrCannotCreateUser                = fmt.Errorf("cannot create user")
-	ErrHaveNoPermission                = fmt.Errorf("have no permission")
-	ErrCannotUpdatePasswordToIdentical = fmt.Errorf("cannot update password to identical")
-	ErrNothingToUpdate                 = fmt.Errorf("nothing to update")
+	ErrUserAlreadyExists               = apperror.New("user_already_exists", http.StatusBadRequest, "user already exists")
+	ErrCannotCreateUser                = apperror.New("cannot_create_user", http.StatusInternalServerError, "cannot create user")
+	ErrHaveNoPermission                = apperror.New("have_no_permission", http.StatusForbidden, "have no permission")
+	ErrCannotUpdatePasswordToIdentical = apperror.New("password_identical", http.StatusBadRequest, "cannot update password to identical")
+	ErrNothingToUpdate                 = apperror.New("nothing_to_update", http.StatusBadRequest, "nothing to update")
+	ErrCannotFollowYourself            = apperror.New("cannot_follow_yourself", http.StatusBadRequest, "cannot follow yourself")
 )
 
-func NewUserUseCase(userRepo repo.User, passwordHasher hasher.PasswordHasher) *UserUseCase {
+func NewUserUseCase(userRepo repo.User, auditRepo repo.Audit, txManager TxManager, passwordHasher hasher.PasswordHasher, authorizer Authorizer, metrics BusinessMetrics) *UserUseCase {
 	return &UserUseCase{
 		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		txManager:      txManager,
 		passwordHasher: passwordHasher,
+		authorizer:     authorizer,
+		metrics:        metrics,
 	}
 }
 
@@ -40,18 +53,19 @@ func (u *UserUseCase) CreateUser(ctx context.Context, input UserCreateUserInput)
 	}
 
 	userID, err := u.userRepo.CreateUser(ctx, user)
-	if err == repoerrs.ErrUserAlreadyExists {
+	if errors.Is(err, repoerrs.ErrUserAlreadyExists) {
 		return uuid.UUID{}, ErrUserAlreadyExists
 	}
 	if err != nil {
 		return uuid.UUID{}, ErrCannotCreateUser
 	}
+	u.metrics.SignUp()
 	return userID, nil
 }
 
 func (u *UserUseCase) GetUserByUsername(ctx context.Context, input UserGetUserByUsernameInput) (entity.User, error) {
 	user, err := u.userRepo.GetUserByUsername(ctx, input.Username)
-	if err == repoerrs.ErrUserNotFound {
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
 		return entity.User{}, ErrUserNotFound
 	}
 	if err != nil {
@@ -60,12 +74,45 @@ func (u *UserUseCase) GetUserByUsername(ctx context.Context, input UserGetUserBy
 	return user, nil
 }
 
+// GetUsersByIDs - пользователи одним запросом, удаленные и не найденные пропускаются
+func (u *UserUseCase) GetUsersByIDs(ctx context.Context, input UserGetUsersByIDsInput) ([]entity.User, error) {
+	users, err := u.userRepo.GetUsersByIDs(ctx, input.IDs)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FollowUser - повторная подписка ничего не меняет: пара уникальна в хранилище, и счетчики не растут дважды.
+// Пользователь ищется и подписка пишется в одной транзакции
+func (u *UserUseCase) FollowUser(ctx context.Context, input UserFollowUserInput) error {
+	return u.txManager.Do(ctx, func(ctx context.Context) error {
+		user, err := u.userRepo.GetUserByUsername(ctx, input.Username)
+		if errors.Is(err, repoerrs.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.ID == input.FollowerID {
+			return ErrCannotFollowYourself
+		}
+
+		_, err = u.userRepo.SetUserFollower(ctx, input.FollowerID, user.ID)
+		return err
+	})
+}
+
 func (u *UserUseCase) UpdateUser(ctx context.Context, input UserUpdateUserInput) error {
 	if input.NewName == nil && input.NewEmail == nil && input.NewRole == nil && input.NewDescription == nil {
 		return ErrNothingToUpdate
 	}
 
 	user, err := u.userRepo.GetUserByUsername(ctx, input.Username)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
 	if err != nil {
 		return err
 	}
@@ -78,7 +125,7 @@ func (u *UserUseCase) UpdateUser(ctx context.Context, input UserUpdateUserInput)
 		return ErrNothingToUpdate
 	}
 
-	err = u.checkPermissions(user, input.RequestedUserID, input.RequestedUserRole, input.NewRole, input.NewEmail)
+	err = checkPermissions(ctx, u.authorizer, user, input.NewRole, input.NewEmail)
 	if err != nil {
 		return err
 	}
@@ -91,13 +138,15 @@ func (u *UserUseCase) UpdateUser(ctx context.Context, input UserUpdateUserInput)
 		input.NewDescription,
 		input.NewRole,
 	)
-	if err == repoerrs.ErrUserNotFound {
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
 		return ErrUserNotFound
 	}
 	if err != nil {
 		return err
 	}
 
+	recordAudit(ctx, u.auditRepo, audit.NewEntry(ctx, entity.AuditUserUpdate, entity.AuditTargetUser, user.ID, userChanges(user, input)))
+
 	return nil
 }
 
@@ -112,64 +161,104 @@ func (u *UserUseCase) UpdateUserPassword(ctx context.Context, input UserUpdateUs
 		u.passwordHasher.Hash(input.OldPassword),
 		u.passwordHasher.Hash(input.NewPassword),
 	)
-	if err == repoerrs.ErrUserNotFound {
+	// the password is checked in the same query
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrWrongPassword
+	}
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditRepo, audit.NewEntry(ctx, entity.AuditPasswordChange, entity.AuditTargetUser, input.UserID, nil))
+
+	return nil
+}
+
+// DeleteUser - удалить пользователя вместе с его контентом может только администратор, администраторов удалить нельзя
+func (u *UserUseCase) DeleteUser(ctx context.Context, input UserDeleteUserInput) error {
+	user, err := u.userRepo.GetUserByUsername(ctx, input.Username)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
 		return ErrUserNotFound
 	}
 	if err != nil {
 		return err
 	}
 
+	// content of anonymized accounts belongs to the tombstone author
+	if user.ID == entity.TombstoneUserID || !u.authorizer.Can(ctx, policy.UserDelete, policy.User(user)) {
+		return ErrHaveNoPermission
+	}
+
+	err = u.userRepo.DeleteUserByID(ctx, user.ID)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditRepo, audit.NewEntry(ctx, entity.AuditDelete, entity.AuditTargetUser, user.ID, nil))
+	return nil
+}
+
+func (u *UserUseCase) RestoreUser(ctx context.Context, input UserRestoreUserInput) error {
+	if !u.authorizer.Can(ctx, policy.UserRestore, policy.Any) {
+		return ErrHaveNoPermission
+	}
+
+	err := u.userRepo.RestoreUserByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditRepo, audit.NewEntry(ctx, entity.AuditRestore, entity.AuditTargetUser, input.ID, nil))
 	return nil
 }
 
-func (u *UserUseCase) checkPermissions(
+// checkPermissions - правила изменения пользователя, общие для обновления профиля и админского API:
+// изменение имени и описания, email и роли проверяются отдельно, как и назначаемая роль
+func checkPermissions(
+	ctx context.Context,
+	authorizer Authorizer,
 	user entity.User,
-	requestedUserID uuid.UUID,
-	requestedUserRole entity.RoleType,
 	newRole *entity.RoleType,
 	newEmail *string,
 ) error {
-	// anyone can update himself except role
-	if user.ID == requestedUserID {
-		if newRole != nil {
-			return ErrHaveNoPermission
-		}
-		return nil
-	}
+	resource := policy.User(user)
 
-	// user can't update other users
-	if requestedUserRole == entity.RoleUser {
+	if !authorizer.Can(ctx, policy.UserUpdate, resource) {
 		return ErrHaveNoPermission
 	}
 
-	// moderator can't update other emails
-	if requestedUserRole == entity.RoleModerator && newEmail != nil {
+	if newEmail != nil && !authorizer.Can(ctx, policy.UserUpdateEmail, resource) {
 		return ErrHaveNoPermission
 	}
 
-	if requestedUserRole == entity.RoleModerator {
-		// moderator can't update any roles
-		if newRole != nil {
-			return ErrHaveNoPermission
-		}
-
-		// moderator can't update other moderators and admins
-		if user.Role == entity.RoleModerator || user.Role == entity.RoleAdmin {
-			return ErrHaveNoPermission
-		}
+	if newRole != nil &&
+		(!authorizer.Can(ctx, policy.UserSetRole, resource) || !authorizer.Can(ctx, policy.GrantRole(*newRole), policy.Any)) {
+		return ErrHaveNoPermission
 	}
 
-	if requestedUserRole == entity.RoleAdmin {
-		// admin can't update other admins
-		if user.Role == entity.RoleAdmin {
-			return ErrHaveNoPermission
-		}
+	return nil
+}
 
-		// admin can't update role to admin
-		if newRole != nil && *newRole == entity.RoleAdmin {
-			return ErrHaveNoPermission
-		}
+// userChanges - details записи об изменении пользователя, только поля, значение которых изменилось
+func userChanges(user entity.User, input UserUpdateUserInput) map[string]interface{} {
+	changes := make(map[string]interface{})
+	if input.NewName != nil && *input.NewName != user.Name {
+		changes["name"] = audit.Change(user.Name, *input.NewName)
 	}
-
-	return nil
+	if input.NewEmail != nil && *input.NewEmail != user.Email {
+		changes["email"] = audit.Change(user.Email, *input.NewEmail)
+	}
+	if input.NewDescription != nil && *input.NewDescription != user.Description {
+		changes["description"] = audit.Change(user.Description, *input.NewDescription)
+	}
+	if input.NewRole != nil && *input.NewRole != user.Role {
+		changes["role"] = audit.Change(user.Role, *input.NewRole)
+	}
+	return changes
 }
@@ -0,0 +1,593 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	repomocks "blog-backend/internal/repo/mocks"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/internal/usecase"
+	"blog-backend/internal/usecase/mocks"
+	hashermocks "blog-backend/pkg/hasher/mocks"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+var errInternal = errors.New("internal error")
+
+// deps - моки зависимостей use case'ов, неожиданный вызов любого из них проваливает тест
+type deps struct {
+	userRepo         *repomocks.MockUser
+	articleRepo      *repomocks.MockArticle
+	commentRepo      *repomocks.MockComment
+	notificationRepo *repomocks.MockNotification
+	moderationRepo   *repomocks.MockModeration
+	exportRepo       *repomocks.MockExport
+	adminRepo        *repomocks.MockAdmin
+	auditRepo        *repomocks.MockAudit
+	hasher           *hashermocks.MockPasswordHasher
+	txManager        *mocks.MockTxManager
+	authorizer       *mocks.MockAuthorizer
+	metrics          *mocks.MockBusinessMetrics
+}
+
+func newDeps(t *testing.T) deps {
+	ctrl := gomock.NewController(t)
+
+	return deps{
+		userRepo:         repomocks.NewMockUser(ctrl),
+		articleRepo:      repomocks.NewMockArticle(ctrl),
+		commentRepo:      repomocks.NewMockComment(ctrl),
+		notificationRepo: repomocks.NewMockNotification(ctrl),
+		moderationRepo:   repomocks.NewMockModeration(ctrl),
+		exportRepo:       repomocks.NewMockExport(ctrl),
+		adminRepo:        repomocks.NewMockAdmin(ctrl),
+		auditRepo:        repomocks.NewMockAudit(ctrl),
+		hasher:           hashermocks.NewMockPasswordHasher(ctrl),
+		txManager:        mocks.NewMockTxManager(ctrl),
+		authorizer:       mocks.NewMockAuthorizer(ctrl),
+		metrics:          mocks.NewMockBusinessMetrics(ctrl),
+	}
+}
+
+// allow - ответ авторизатора на действие над ресурсом
+func (d deps) allow(action policy.Action, resource policy.Resource, allowed bool) {
+	d.authorizer.EXPECT().Can(gomock.Any(), action, resource).Return(allowed)
+}
+
+// inTx - функция транзакции выполняется с тем же контекстом, ошибка возвращается как есть
+func (d deps) inTx() {
+	d.txManager.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	})
+}
+
+func (d deps) expectAudit(action entity.AuditAction, targetID uuid.UUID) {
+	d.auditRepo.EXPECT().CreateAuditEntry(gomock.Any(), auditEntry(action, targetID)).Return(nil)
+}
+
+// auditEntry - сравнение записи аудита только по действию и цели, остальное берется из контекста
+func auditEntry(action entity.AuditAction, targetID uuid.UUID) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		entry, ok := x.(entity.AuditEntry)
+		return ok && entry.Action == action && entry.TargetID.UUID == targetID
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUserUseCase_CreateUser(t *testing.T) {
+	input := usecase.UserCreateUserInput{Name: "Alice", Username: "alice", Password: "Pass-word1!", Email: "alice@example.com"}
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		want    uuid.UUID
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().CreateUser(gomock.Any(), entity.User{
+					Name:     "Alice",
+					Username: "alice",
+					Password: "hash",
+					Email:    "alice@example.com",
+					Role:     entity.RoleUser,
+				}).Return(userID, nil)
+				d.metrics.EXPECT().SignUp()
+			},
+			want: userID,
+		},
+		{
+			name: "already exists",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(uuid.UUID{}, repoerrs.ErrUserAlreadyExists)
+			},
+			err: usecase.ErrUserAlreadyExists,
+		},
+		{
+			name: "already exists, wrapped by the repo",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).
+					Return(uuid.UUID{}, fmt.Errorf("UserRepo.CreateUser - r.Pool.QueryRow: %w", repoerrs.ErrUserAlreadyExists))
+			},
+			err: usecase.ErrUserAlreadyExists,
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(uuid.UUID{}, errInternal)
+			},
+			err: usecase.ErrCannotCreateUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.hasher.EXPECT().Hash("Pass-word1!").Return("hash")
+			tt.prepare(d)
+
+			u := usecase.NewUserUseCase(d.userRepo, d.auditRepo, d.txManager, d.hasher, d.authorizer, d.metrics)
+			got, err := u.CreateUser(context.Background(), input)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("id = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_GetUserByUsername(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Username: "alice"}
+
+	tests := []struct {
+		name    string
+		repoErr error
+		want    entity.User
+		err     error
+	}{
+		{name: "ok", want: user},
+		{name: "not found", repoErr: repoerrs.ErrUserNotFound, err: usecase.ErrUserNotFound},
+		{name: "repo error", repoErr: errInternal, err: errInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(tt.want, tt.repoErr)
+
+			u := usecase.NewUserUseCase(d.userRepo, d.auditRepo, d.txManager, d.hasher, d.authorizer, d.metrics)
+			got, err := u.GetUserByUsername(context.Background(), usecase.UserGetUserByUsernameInput{Username: "alice"})
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("user = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_FollowUser(t *testing.T) {
+	followerID := uuid.New()
+	user := entity.User{ID: uuid.New(), Username: "alice"}
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.userRepo.EXPECT().SetUserFollower(gomock.Any(), followerID, user.ID).Return(true, nil)
+			},
+		},
+		{
+			name: "already following",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.userRepo.EXPECT().SetUserFollower(gomock.Any(), followerID, user.ID).Return(false, nil)
+			},
+		},
+		{
+			name: "not found",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(entity.User{}, repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name: "yourself",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(entity.User{ID: followerID, Username: "alice"}, nil)
+			},
+			err: usecase.ErrCannotFollowYourself,
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.userRepo.EXPECT().SetUserFollower(gomock.Any(), followerID, user.ID).Return(false, errInternal)
+			},
+			err: errInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.inTx()
+			tt.prepare(d)
+
+			u := usecase.NewUserUseCase(d.userRepo, d.auditRepo, d.txManager, d.hasher, d.authorizer, d.metrics)
+			err := u.FollowUser(context.Background(), usecase.UserFollowUserInput{FollowerID: followerID, Username: "alice"})
+			if err != tt.err {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestUserUseCase_UpdateUser - в том числе каждая ветка checkPermissions
+func TestUserUseCase_UpdateUser(t *testing.T) {
+	user := entity.User{
+		ID:          uuid.New(),
+		Name:        "Alice",
+		Username:    "alice",
+		Email:       "alice@example.com",
+		Role:        entity.RoleUser,
+		Description: "about",
+	}
+	resource := policy.User(user)
+
+	tests := []struct {
+		name    string
+		input   usecase.UserUpdateUserInput
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name:  "nothing passed",
+			input: usecase.UserUpdateUserInput{Username: "alice"},
+			err:   usecase.ErrNothingToUpdate,
+		},
+		{
+			name:  "user not found",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewName: ptr("Alicia")},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(entity.User{}, repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name:  "repo error on get",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewName: ptr("Alicia")},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(entity.User{}, errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "same values",
+			input: usecase.UserUpdateUserInput{
+				Username:       "alice",
+				NewName:        ptr("Alice"),
+				NewEmail:       ptr("alice@example.com"),
+				NewRole:        ptr(entity.RoleUser),
+				NewDescription: ptr("about"),
+			},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+			},
+			err: usecase.ErrNothingToUpdate,
+		},
+		{
+			name:  "checkPermissions: update is denied",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewName: ptr("Alicia")},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "checkPermissions: email change is denied",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewEmail: ptr("new@example.com")},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, true)
+				d.allow(policy.UserUpdateEmail, resource, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "checkPermissions: role change is denied",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewRole: ptr(entity.RoleModerator)},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, true)
+				d.allow(policy.UserSetRole, resource, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "checkPermissions: granting the role is denied",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewRole: ptr(entity.RoleAdmin)},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, true)
+				d.allow(policy.UserSetRole, resource, true)
+				d.allow(policy.GrantRole(entity.RoleAdmin), policy.Any, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name:  "checkPermissions: name only, email and role are not checked",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewName: ptr("Alicia"), NewDescription: ptr("")},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, true)
+				d.userRepo.EXPECT().UpdateUserByID(gomock.Any(), user.ID, ptr("Alicia"), nil, ptr(""), nil).Return(nil)
+				d.expectAudit(entity.AuditUserUpdate, user.ID)
+			},
+		},
+		{
+			name: "checkPermissions: everything is allowed",
+			input: usecase.UserUpdateUserInput{
+				Username: "alice",
+				NewEmail: ptr("new@example.com"),
+				NewRole:  ptr(entity.RoleModerator),
+			},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, true)
+				d.allow(policy.UserUpdateEmail, resource, true)
+				d.allow(policy.UserSetRole, resource, true)
+				d.allow(policy.GrantRole(entity.RoleModerator), policy.Any, true)
+				d.userRepo.EXPECT().UpdateUserByID(gomock.Any(), user.ID, nil, ptr("new@example.com"), nil, ptr(entity.RoleModerator)).Return(nil)
+				d.expectAudit(entity.AuditUserUpdate, user.ID)
+			},
+		},
+		{
+			name:  "deleted while updating",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewName: ptr("Alicia")},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, true)
+				d.userRepo.EXPECT().UpdateUserByID(gomock.Any(), user.ID, ptr("Alicia"), nil, nil, nil).Return(repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name:  "repo error on update",
+			input: usecase.UserUpdateUserInput{Username: "alice", NewName: ptr("Alicia")},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserUpdate, resource, true)
+				d.userRepo.EXPECT().UpdateUserByID(gomock.Any(), user.ID, ptr("Alicia"), nil, nil, nil).Return(errInternal)
+			},
+			err: errInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			if tt.prepare != nil {
+				tt.prepare(d)
+			}
+
+			u := usecase.NewUserUseCase(d.userRepo, d.auditRepo, d.txManager, d.hasher, d.authorizer, d.metrics)
+			err := u.UpdateUser(context.Background(), tt.input)
+			if err != tt.err {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_UpdateUserPassword(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		input   usecase.UserUpdateUserPasswordInput
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name:  "identical",
+			input: usecase.UserUpdateUserPasswordInput{UserID: userID, OldPassword: "Old-pass1!", NewPassword: "Old-pass1!"},
+			err:   usecase.ErrCannotUpdatePasswordToIdentical,
+		},
+		{
+			name:  "wrong old password",
+			input: usecase.UserUpdateUserPasswordInput{UserID: userID, OldPassword: "Old-pass1!", NewPassword: "New-pass1!"},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().UpdateUserPassword(gomock.Any(), userID, "old-hash", "new-hash").Return(repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrWrongPassword,
+		},
+		{
+			name:  "repo error",
+			input: usecase.UserUpdateUserPasswordInput{UserID: userID, OldPassword: "Old-pass1!", NewPassword: "New-pass1!"},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().UpdateUserPassword(gomock.Any(), userID, "old-hash", "new-hash").Return(errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name:  "ok",
+			input: usecase.UserUpdateUserPasswordInput{UserID: userID, OldPassword: "Old-pass1!", NewPassword: "New-pass1!"},
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().UpdateUserPassword(gomock.Any(), userID, "old-hash", "new-hash").Return(nil)
+				d.expectAudit(entity.AuditPasswordChange, userID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			d.hasher.EXPECT().Hash("Old-pass1!").Return("old-hash").AnyTimes()
+			d.hasher.EXPECT().Hash("New-pass1!").Return("new-hash").AnyTimes()
+			if tt.prepare != nil {
+				tt.prepare(d)
+			}
+
+			u := usecase.NewUserUseCase(d.userRepo, d.auditRepo, d.txManager, d.hasher, d.authorizer, d.metrics)
+			err := u.UpdateUserPassword(context.Background(), tt.input)
+			if err != tt.err {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_DeleteUser(t *testing.T) {
+	user := entity.User{ID: uuid.New(), Username: "alice", Role: entity.RoleUser}
+	tombstone := entity.User{ID: entity.TombstoneUserID, Username: "deleted"}
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "not found",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(entity.User{}, repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name: "repo error on get",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(entity.User{}, errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "tombstone user",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(tombstone, nil)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "denied",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserDelete, policy.User(user), false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "deleted concurrently",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserDelete, policy.User(user), true)
+				d.userRepo.EXPECT().DeleteUserByID(gomock.Any(), user.ID).Return(repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name: "repo error on delete",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserDelete, policy.User(user), true)
+				d.userRepo.EXPECT().DeleteUserByID(gomock.Any(), user.ID).Return(errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+				d.allow(policy.UserDelete, policy.User(user), true)
+				d.userRepo.EXPECT().DeleteUserByID(gomock.Any(), user.ID).Return(nil)
+				d.expectAudit(entity.AuditDelete, user.ID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			u := usecase.NewUserUseCase(d.userRepo, d.auditRepo, d.txManager, d.hasher, d.authorizer, d.metrics)
+			err := u.DeleteUser(context.Background(), usecase.UserDeleteUserInput{Username: "alice"})
+			if err != tt.err {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUserUseCase_RestoreUser(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		prepare func(d deps)
+		err     error
+	}{
+		{
+			name: "denied",
+			prepare: func(d deps) {
+				d.allow(policy.UserRestore, policy.Any, false)
+			},
+			err: usecase.ErrHaveNoPermission,
+		},
+		{
+			name: "not deleted",
+			prepare: func(d deps) {
+				d.allow(policy.UserRestore, policy.Any, true)
+				d.userRepo.EXPECT().RestoreUserByID(gomock.Any(), userID).Return(repoerrs.ErrUserNotFound)
+			},
+			err: usecase.ErrUserNotFound,
+		},
+		{
+			name: "repo error",
+			prepare: func(d deps) {
+				d.allow(policy.UserRestore, policy.Any, true)
+				d.userRepo.EXPECT().RestoreUserByID(gomock.Any(), userID).Return(errInternal)
+			},
+			err: errInternal,
+		},
+		{
+			name: "ok",
+			prepare: func(d deps) {
+				d.allow(policy.UserRestore, policy.Any, true)
+				d.userRepo.EXPECT().RestoreUserByID(gomock.Any(), userID).Return(nil)
+				d.expectAudit(entity.AuditRestore, userID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			tt.prepare(d)
+
+			u := usecase.NewUserUseCase(d.userRepo, d.auditRepo, d.txManager, d.hasher, d.authorizer, d.metrics)
+			err := u.RestoreUser(context.Background(), usecase.UserRestoreUserInput{ID: userID})
+			if err != tt.err {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
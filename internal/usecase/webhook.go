@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/policy"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/apperror"
+	"blog-backend/pkg/netguard"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+const webhookSecretSize = 32
+
+type WebhookUseCase struct {
+	webhookRepo repo.Webhook
+	articleRepo repo.Article
+	authorizer  Authorizer
+}
+
+var (
+	ErrWebhookNotFound         = apperror.New("webhook_not_found", http.StatusNotFound, "webhook not found")
+	ErrWebhookDeliveryNotFound = apperror.New("webhook_delivery_not_found", http.StatusNotFound, "webhook delivery not found")
+	ErrUnknownEventType        = apperror.New("unknown_event_type", http.StatusBadRequest, "unknown event type")
+	ErrCannotCreateWebhook     = apperror.New("cannot_create_webhook", http.StatusInternalServerError, "cannot create webhook")
+	ErrWebhookURLNotAllowed    = apperror.New("webhook_url_not_allowed", http.StatusBadRequest, "webhook url must be a public http or https address")
+)
+
+func NewWebhookUseCase(webhookRepo repo.Webhook, articleRepo repo.Article, authorizer Authorizer) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo: webhookRepo,
+		articleRepo: articleRepo,
+		authorizer:  authorizer,
+	}
+}
+
+// CreateWebhook - секрет для проверки подписи возвращается только при создании
+func (u *WebhookUseCase) CreateWebhook(ctx context.Context, input WebhookCreateWebhookInput) (entity.Webhook, error) {
+	// only admin can receive events of all users
+	if input.Global && !u.authorizer.Can(ctx, policy.WebhookCreateGlobal, policy.Any) {
+		return entity.Webhook{}, ErrHaveNoPermission
+	}
+
+	err := checkWebhookEventTypes(input.EventTypes)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	err = checkWebhookURL(ctx, input.URL)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	secret := make([]byte, webhookSecretSize)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return entity.Webhook{}, ErrCannotCreateWebhook
+	}
+
+	webhook := entity.Webhook{
+		OwnerID:    input.RequestedUserID,
+		URL:        input.URL,
+		Secret:     hex.EncodeToString(secret),
+		EventTypes: input.EventTypes,
+		IsGlobal:   input.Global,
+		Active:     true,
+	}
+
+	id, err := u.webhookRepo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return entity.Webhook{}, ErrCannotCreateWebhook
+	}
+
+	webhook, err = u.webhookRepo.GetWebhookByID(ctx, id)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (u *WebhookUseCase) GetWebhooks(ctx context.Context, input WebhookGetWebhooksInput) ([]entity.Webhook, error) {
+	webhooks, err := u.webhookRepo.GetWebhooksByOwnerID(ctx, input.RequestedUserID)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (u *WebhookUseCase) UpdateWebhook(ctx context.Context, input WebhookUpdateWebhookInput) error {
+	if input.NewURL == nil && input.NewEventTypes == nil && input.NewActive == nil {
+		return ErrNothingToUpdate
+	}
+
+	if input.NewEventTypes != nil {
+		err := checkWebhookEventTypes(*input.NewEventTypes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if input.NewURL != nil {
+		err := checkWebhookURL(ctx, *input.NewURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := u.getOwnWebhook(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.webhookRepo.UpdateWebhookByID(ctx, input.ID, input.NewURL, input.NewEventTypes, input.NewActive)
+	if errors.Is(err, repoerrs.ErrWebhookNotFound) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *WebhookUseCase) DeleteWebhook(ctx context.Context, input WebhookDeleteWebhookInput) error {
+	_, err := u.getOwnWebhook(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.webhookRepo.DeleteWebhookByID(ctx, input.ID)
+	if errors.Is(err, repoerrs.ErrWebhookNotFound) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *WebhookUseCase) GetDeliveries(ctx context.Context, input WebhookGetDeliveriesInput) ([]entity.WebhookDelivery, error) {
+	_, err := u.getOwnWebhook(ctx, input.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := u.webhookRepo.GetDeliveriesByWebhookID(ctx, input.WebhookID, input.Limit, input.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (u *WebhookUseCase) Redeliver(ctx context.Context, input WebhookRedeliverInput) error {
+	_, err := u.getOwnWebhook(ctx, input.WebhookID)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := u.webhookRepo.GetDeliveryByID(ctx, input.DeliveryID)
+	if errors.Is(err, repoerrs.ErrWebhookDeliveryNotFound) || (err == nil && delivery.WebhookID != input.WebhookID) {
+		return ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = u.webhookRepo.ResetDelivery(ctx, delivery.ID)
+	if errors.Is(err, repoerrs.ErrWebhookDeliveryNotFound) {
+		return ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type webhookPayload struct {
+	EventID   int64            `json:"event_id"`
+	Event     entity.EventType `json:"event"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      json.RawMessage  `json:"data"`
+}
+
+// HandleEvent - постановка события в очередь доставки всем подписанным webhook'ам:
+// глобальным и webhook'ам пользователей, чьего контента касается событие
+func (u *WebhookUseCase) HandleEvent(ctx context.Context, event entity.Event) error {
+	ownerIDs, err := u.eventOwners(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := u.webhookRepo.GetActiveWebhooksForEvent(ctx, event.Type, ownerIDs)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		EventID:   event.ID,
+		Event:     event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %v", err)
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+		})
+	}
+
+	return u.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+func (u *WebhookUseCase) eventOwners(ctx context.Context, event entity.Event) ([]uuid.UUID, error) {
+	switch event.Type {
+	case entity.EventArticleCreated:
+		var payload entity.ArticleCreatedPayload
+		err := event.Decode(&payload)
+		return []uuid.UUID{payload.AuthorID}, err
+
+	case entity.EventArticleUpdated:
+		var payload entity.ArticleUpdatedPayload
+		err := event.Decode(&payload)
+		return []uuid.UUID{payload.AuthorID}, err
+
+	case entity.EventArticleDeleted, entity.EventArticleRestored, entity.EventArticleHidden:
+		var payload entity.ArticleDeletedPayload
+		err := event.Decode(&payload)
+		return []uuid.UUID{payload.AuthorID}, err
+
+	case entity.EventArticleFavorited, entity.EventArticleUnfavorited:
+		var payload entity.ArticleFavoritedPayload
+		err := event.Decode(&payload)
+		if err != nil {
+			return nil, err
+		}
+		return u.articleAuthor(ctx, payload.ArticleID)
+
+	case entity.EventCommentPosted:
+		var payload entity.CommentPostedPayload
+		err := event.Decode(&payload)
+		if err != nil {
+			return nil, err
+		}
+		owners, err := u.articleAuthor(ctx, payload.ArticleID)
+		return append(owners, payload.AuthorID), err
+
+	case entity.EventCommentDeleted, entity.EventCommentRestored, entity.EventCommentHidden:
+		var payload entity.CommentDeletedPayload
+		err := event.Decode(&payload)
+		if err != nil {
+			return nil, err
+		}
+		owners, err := u.articleAuthor(ctx, payload.ArticleID)
+		return append(owners, payload.AuthorID), err
+	}
+
+	return nil, nil
+}
+
+func (u *WebhookUseCase) articleAuthor(ctx context.Context, articleID uuid.UUID) ([]uuid.UUID, error) {
+	article, err := u.articleRepo.GetArticleByID(ctx, articleID)
+	// deleted article still notifies global webhooks
+	if errors.Is(err, repoerrs.ErrArticleNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []uuid.UUID{article.AuthorID}, nil
+}
+
+// getOwnWebhook - webhook доступен владельцу и администратору
+func (u *WebhookUseCase) getOwnWebhook(ctx context.Context, id uuid.UUID) (entity.Webhook, error) {
+	webhook, err := u.webhookRepo.GetWebhookByID(ctx, id)
+	if errors.Is(err, repoerrs.ErrWebhookNotFound) {
+		return entity.Webhook{}, ErrWebhookNotFound
+	}
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	// other users must not know that the webhook exists
+	if !u.authorizer.Can(ctx, policy.WebhookManage, policy.Webhook(webhook)) {
+		return entity.Webhook{}, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func checkWebhookEventTypes(eventTypes []entity.EventType) error {
+	for _, eventType := range eventTypes {
+		known := false
+		for _, webhookEventType := range entity.WebhookEventTypes {
+			if eventType == webhookEventType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return ErrUnknownEventType
+		}
+	}
+	return nil
+}
+
+// checkWebhookURL - запросы уходят с сервера, поэтому адрес внутренней сети позволил бы любому
+// пользователю обращаться к внутренним сервисам и видеть ответы в журнале доставок
+func checkWebhookURL(ctx context.Context, rawURL string) error {
+	err := netguard.CheckURL(ctx, rawURL)
+	if err == nil {
+		return nil
+	}
+
+	reason := "host cannot be resolved"
+	for _, known := range []error{netguard.ErrScheme, netguard.ErrHost, netguard.ErrAddress} {
+		if errors.Is(err, known) {
+			reason = known.Error()
+		}
+	}
+
+	return ErrWebhookURLNotAllowed.WithDetails(map[string]interface{}{"reason": reason}).Wrap(err)
+}
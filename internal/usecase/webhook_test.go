@@ -0,0 +1,65 @@
+package usecase_test
+
+import (
+	"blog-backend/internal/entity"
+	repomocks "blog-backend/internal/repo/mocks"
+	"blog-backend/internal/usecase"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestWebhookUseCase_CreateWebhook_URL(t *testing.T) {
+	ownerID := uuid.New()
+	webhookID := uuid.New()
+
+	tests := []struct {
+		name string
+		url  string
+		err  error
+	}{
+		{name: "public address", url: "https://8.8.8.8/hook"},
+		{name: "not http", url: "gopher://8.8.8.8/hook", err: usecase.ErrWebhookURLNotAllowed},
+		{name: "loopback", url: "http://127.0.0.1:8080/hook", err: usecase.ErrWebhookURLNotAllowed},
+		{name: "private network", url: "http://10.0.0.5/hook", err: usecase.ErrWebhookURLNotAllowed},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", err: usecase.ErrWebhookURLNotAllowed},
+		{name: "ipv4 mapped loopback", url: "http://[::ffff:127.0.0.1]/hook", err: usecase.ErrWebhookURLNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDeps(t)
+			webhookRepo := repomocks.NewMockWebhook(gomock.NewController(t))
+			if tt.err == nil {
+				webhookRepo.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(webhookID, nil)
+				webhookRepo.EXPECT().GetWebhookByID(gomock.Any(), webhookID).Return(entity.Webhook{ID: webhookID, URL: tt.url}, nil)
+			}
+
+			u := usecase.NewWebhookUseCase(webhookRepo, d.articleRepo, d.authorizer)
+			_, err := u.CreateWebhook(context.Background(), usecase.WebhookCreateWebhookInput{
+				RequestedUserID: ownerID,
+				URL:             tt.url,
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWebhookUseCase_UpdateWebhook_URL(t *testing.T) {
+	d := newDeps(t)
+	webhookRepo := repomocks.NewMockWebhook(gomock.NewController(t))
+
+	// the url is checked before the webhook is loaded
+	u := usecase.NewWebhookUseCase(webhookRepo, d.articleRepo, d.authorizer)
+	err := u.UpdateWebhook(context.Background(), usecase.WebhookUpdateWebhookInput{
+		ID:     uuid.New(),
+		NewURL: ptr("http://192.168.0.1/hook"),
+	})
+	if !errors.Is(err, usecase.ErrWebhookURLNotAllowed) {
+		t.Fatalf("err = %v, want %v", err, usecase.ErrWebhookURLNotAllowed)
+	}
+}
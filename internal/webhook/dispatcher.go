@@ -0,0 +1,245 @@
+package webhook
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/repoerrs"
+	"blog-backend/pkg/backoff"
+	"blog-backend/pkg/netguard"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 50
+	defaultWorkers      = 4
+	defaultMaxAttempts  = 8
+	defaultMinBackoff   = 10 * time.Second
+	defaultMaxBackoff   = 6 * time.Hour
+	defaultTimeout      = 10 * time.Second
+
+	// lease must outlive the slowest request of the batch
+	leaseFactor = 4
+
+	HeaderWebhookID = "X-Webhook-ID"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Dispatcher - доставка webhook_deliveries получателям с повторами по экспоненциальной задержке,
+// после maxAttempts неудачных попыток доставка переходит в dead и может быть повторена вручную.
+// Адреса задают пользователи, поэтому по умолчанию подключения разрешены только к публичным адресам
+type Dispatcher struct {
+	webhookRepo repo.Webhook
+	client      *http.Client
+
+	pollInterval time.Duration
+	batchSize    int
+	workers      int
+	maxAttempts  int
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewDispatcher(webhookRepo repo.Webhook, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		webhookRepo:  webhookRepo,
+		client:       &http.Client{Timeout: defaultTimeout, Transport: netguard.Transport(defaultTimeout)},
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+		workers:      defaultWorkers,
+		maxAttempts:  defaultMaxAttempts,
+		minBackoff:   defaultMinBackoff,
+		maxBackoff:   defaultMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+
+	go d.run(ctx)
+
+	return d
+}
+
+func (d *Dispatcher) Close() {
+	d.cancel()
+	<-d.done
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := d.ProcessBatch(ctx)
+		if err != nil {
+			log.Errorf("Dispatcher.run - d.ProcessBatch: %v", err)
+		}
+
+		if err == nil && n == d.batchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch - захват пачки доставок и их параллельная отправка, возвращает количество захваченных доставок
+func (d *Dispatcher) ProcessBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.webhookRepo.LockPendingDeliveries(ctx, d.batchSize, leaseFactor*d.client.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("d.webhookRepo.LockPendingDeliveries: %v", err)
+	}
+
+	webhooks := make(map[string]*entity.Webhook)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, d.workers)
+
+	for _, delivery := range deliveries {
+		delivery := delivery
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+
+			mu.Lock()
+			webhook, ok := webhooks[delivery.WebhookID.String()]
+			mu.Unlock()
+
+			if !ok {
+				w, err := d.webhookRepo.GetWebhookByID(context.Background(), delivery.WebhookID)
+				if err != nil && !errors.Is(err, repoerrs.ErrWebhookNotFound) {
+					log.Errorf("Dispatcher.ProcessBatch - d.webhookRepo.GetWebhookByID: %v", err)
+					return
+				}
+				if err == nil {
+					webhook = &w
+				}
+
+				mu.Lock()
+				webhooks[delivery.WebhookID.String()] = webhook
+				mu.Unlock()
+			}
+
+			d.dispatch(webhook, delivery)
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) dispatch(webhook *entity.Webhook, delivery entity.WebhookDelivery) {
+	// requests are finished even if the dispatcher is stopping, locked deliveries would wait for the lease otherwise
+	ctx := context.Background()
+
+	if webhook == nil || !webhook.Active {
+		err := d.webhookRepo.MarkDeliveryDead(ctx, delivery.ID, nil, "webhook is inactive")
+		if err != nil {
+			log.Errorf("Dispatcher.dispatch - d.webhookRepo.MarkDeliveryDead: %v", err)
+		}
+		return
+	}
+
+	statusCode, err := d.send(ctx, webhook, delivery)
+	if err == nil {
+		err = d.webhookRepo.MarkDeliveryDelivered(ctx, delivery.ID, statusCode)
+		if err != nil {
+			log.Errorf("Dispatcher.dispatch - d.webhookRepo.MarkDeliveryDelivered: %v", err)
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	if delivery.Attempts >= d.maxAttempts {
+		err = d.webhookRepo.MarkDeliveryDead(ctx, delivery.ID, code, err.Error())
+		if err != nil {
+			log.Errorf("Dispatcher.dispatch - d.webhookRepo.MarkDeliveryDead: %v", err)
+		}
+		return
+	}
+
+	retryIn := backoff.Exponential(delivery.Attempts, d.minBackoff, d.maxBackoff)
+	err = d.webhookRepo.MarkDeliveryFailed(ctx, delivery.ID, code, err.Error(), retryIn)
+	if err != nil {
+		log.Errorf("Dispatcher.dispatch - d.webhookRepo.MarkDeliveryFailed: %v", err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook *entity.Webhook, delivery entity.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("http.NewRequestWithContext: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blog-backend-webhooks")
+	req.Header.Set(HeaderWebhookID, webhook.ID.String())
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("d.client.Do: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign - подпись запроса: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)),
+// timestamp в подписи позволяет получателю отбрасывать повторно отправленные старые запросы
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
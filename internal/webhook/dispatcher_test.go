@@ -0,0 +1,250 @@
+package webhook_test
+
+import (
+	"blog-backend/internal/entity"
+	"blog-backend/internal/repo"
+	"blog-backend/internal/repo/memdb"
+	"blog-backend/internal/webhook"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const payload = `{"event":"article.created"}`
+
+// newDispatcher - диспетчер без фонового цикла, пачки обрабатываются только вызовами ProcessBatch
+func newDispatcher(repos *repo.Repositories, opts ...webhook.Option) *webhook.Dispatcher {
+	d := webhook.NewDispatcher(repos, append([]webhook.Option{webhook.PollInterval(time.Hour)}, opts...)...)
+	d.Close()
+	return d
+}
+
+// newDelivery - webhook на url и одна ожидающая доставка ему. Создается после диспетчера,
+// иначе ее может захватить первая пачка фонового цикла
+func newDelivery(t *testing.T, repos *repo.Repositories, url string) entity.Webhook {
+	t.Helper()
+	ctx := context.Background()
+
+	ownerID, err := repos.CreateUser(ctx, entity.User{
+		Username: "owner",
+		Email:    "owner@example.com",
+		Password: "password",
+		Role:     entity.RoleUser,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhookID, err := repos.CreateWebhook(ctx, entity.Webhook{OwnerID: ownerID, URL: url, Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = repos.CreateDeliveries(ctx, []entity.WebhookDelivery{{
+		WebhookID: webhookID,
+		EventID:   1,
+		EventType: entity.EventArticleCreated,
+		Payload:   json.RawMessage(payload),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := repos.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func processBatch(t *testing.T, d *webhook.Dispatcher) int {
+	t.Helper()
+
+	n, err := d.ProcessBatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func getDelivery(t *testing.T, repos *repo.Repositories, w entity.Webhook) entity.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := repos.GetDeliveriesByWebhookID(context.Background(), w.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+// statusServer - сервер, отвечающий статусами из statuses по очереди, затем последним из них
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(&calls, 1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		w.WriteHeader(statuses[i])
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"event":"article.created"}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=e29feef62dcbe099e8add1a1ecb0449811bfbb265eaa091a3f25c8777c607217"
+
+	if got := webhook.Sign("secret", "1700000000", []byte(payload)); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestDispatcher_Delivery(t *testing.T) {
+	var req *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repos := repo.NewMemoryRepositories(memdb.New())
+	d := newDispatcher(repos, webhook.Transport(http.DefaultTransport))
+	w := newDelivery(t, repos, server.URL)
+
+	if n := processBatch(t, d); n != 1 {
+		t.Fatalf("locked %d deliveries, want 1", n)
+	}
+
+	delivery := getDelivery(t, repos, w)
+	if delivery.Status != entity.WebhookDeliveryDelivered || *delivery.LastStatusCode != http.StatusNoContent {
+		t.Errorf("status %s, code %d, want delivered with 204", delivery.Status, *delivery.LastStatusCode)
+	}
+
+	if string(body) != payload {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+	if req.Header.Get(webhook.HeaderWebhookID) != w.ID.String() ||
+		req.Header.Get(webhook.HeaderDelivery) != delivery.ID.String() ||
+		req.Header.Get(webhook.HeaderEvent) != string(entity.EventArticleCreated) {
+		t.Errorf("unexpected headers: %v", req.Header)
+	}
+
+	// the receiver verifies the signature with the timestamp from the header
+	timestamp := req.Header.Get(webhook.HeaderTimestamp)
+	if sig := req.Header.Get(webhook.HeaderSignature); sig != webhook.Sign(w.Secret, timestamp, body) {
+		t.Errorf("signature %s does not match timestamp %s", sig, timestamp)
+	}
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	server, calls := statusServer(t, http.StatusInternalServerError, http.StatusOK)
+
+	repos := repo.NewMemoryRepositories(memdb.New())
+	d := newDispatcher(repos, webhook.Transport(http.DefaultTransport), webhook.MaxAttempts(3), webhook.Backoff(0, 0))
+	w := newDelivery(t, repos, server.URL)
+
+	processBatch(t, d)
+
+	delivery := getDelivery(t, repos, w)
+	if delivery.Status != entity.WebhookDeliveryPending || *delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("status %s, code %v after a failed attempt, want pending with 500", delivery.Status, delivery.LastStatusCode)
+	}
+
+	processBatch(t, d)
+
+	delivery = getDelivery(t, repos, w)
+	if delivery.Status != entity.WebhookDeliveryDelivered || delivery.Attempts != 2 || atomic.LoadInt32(calls) != 2 {
+		t.Errorf("status %s after %d attempts, want delivered after 2", delivery.Status, delivery.Attempts)
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	server, _ := statusServer(t, http.StatusServiceUnavailable)
+
+	repos := repo.NewMemoryRepositories(memdb.New())
+	d := newDispatcher(repos, webhook.Transport(http.DefaultTransport), webhook.Backoff(time.Minute, time.Hour))
+	w := newDelivery(t, repos, server.URL)
+
+	start := time.Now()
+	processBatch(t, d)
+
+	// the first retry waits min backoff plus up to 20% jitter
+	delivery := getDelivery(t, repos, w)
+	if delay := delivery.NextAttemptAt.Sub(start); delay < time.Minute || delay > time.Minute*6/5+time.Second {
+		t.Errorf("next attempt in %s, want about a minute", delay)
+	}
+	if n := processBatch(t, d); n != 0 {
+		t.Errorf("locked %d deliveries before the backoff, want 0", n)
+	}
+}
+
+func TestDispatcher_Dead(t *testing.T) {
+	server, calls := statusServer(t, http.StatusInternalServerError)
+
+	repos := repo.NewMemoryRepositories(memdb.New())
+	d := newDispatcher(repos, webhook.Transport(http.DefaultTransport), webhook.MaxAttempts(2), webhook.Backoff(0, 0))
+	w := newDelivery(t, repos, server.URL)
+
+	processBatch(t, d)
+	processBatch(t, d)
+
+	delivery := getDelivery(t, repos, w)
+	if delivery.Status != entity.WebhookDeliveryDead || *delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Errorf("status %s, code %v after max attempts, want dead with 500", delivery.Status, delivery.LastStatusCode)
+	}
+	if n := processBatch(t, d); n != 0 || atomic.LoadInt32(calls) != 2 {
+		t.Errorf("dead delivery is sent again")
+	}
+}
+
+func TestDispatcher_InactiveWebhook(t *testing.T) {
+	server, calls := statusServer(t, http.StatusOK)
+
+	repos := repo.NewMemoryRepositories(memdb.New())
+	d := newDispatcher(repos, webhook.Transport(http.DefaultTransport))
+	w := newDelivery(t, repos, server.URL)
+	active := false
+	if err := repos.UpdateWebhookByID(context.Background(), w.ID, nil, nil, &active); err != nil {
+		t.Fatal(err)
+	}
+
+	processBatch(t, d)
+
+	if delivery := getDelivery(t, repos, w); delivery.Status != entity.WebhookDeliveryDead || atomic.LoadInt32(calls) != 0 {
+		t.Errorf("status %s, want dead without a request", delivery.Status)
+	}
+}
+
+// the default transport refuses to connect to the local server even if the url was stored
+func TestDispatcher_PrivateAddress(t *testing.T) {
+	server, calls := statusServer(t, http.StatusOK)
+
+	repos := repo.NewMemoryRepositories(memdb.New())
+	d := newDispatcher(repos, webhook.Backoff(0, 0))
+	w := newDelivery(t, repos, server.URL)
+
+	processBatch(t, d)
+
+	delivery := getDelivery(t, repos, w)
+	if delivery.Status != entity.WebhookDeliveryPending || atomic.LoadInt32(calls) != 0 {
+		t.Fatalf("status %s, want a failed attempt without a request", delivery.Status)
+	}
+	if delivery.LastError == nil || !strings.Contains(*delivery.LastError, "address is not public") {
+		t.Errorf("last error = %v, want the netguard error", delivery.LastError)
+	}
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+)
+
+type Option func(*Dispatcher)
+
+func PollInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.pollInterval = interval
+	}
+}
+
+func BatchSize(size int) Option {
+	return func(d *Dispatcher) {
+		d.batchSize = size
+	}
+}
+
+func Workers(workers int) Option {
+	return func(d *Dispatcher) {
+		d.workers = workers
+	}
+}
+
+func MaxAttempts(attempts int) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = attempts
+	}
+}
+
+func Backoff(min, max time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.minBackoff = min
+		d.maxBackoff = max
+	}
+}
+
+func Timeout(timeout time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.client.Timeout = timeout
+	}
+}
+
+// Transport - замена транспорта с проверкой адресов netguard, например, для тестов с локальным сервером
+func Transport(transport http.RoundTripper) Option {
+	return func(d *Dispatcher) {
+		d.client.Transport = transport
+	}
+}
@@ -0,0 +1,5 @@
+-- migration down file for blog_backend database
+
+drop index comments_article_id_created_at_idx;
+
+drop table notifications;
@@ -0,0 +1,21 @@
+-- migration up file for blog_backend database
+
+-- create notifications table
+create table notifications
+(
+    id         uuid primary key default uuid_generate_v4(),
+    user_id    uuid                           not null,
+    actor_id   uuid                           not null,
+    type       varchar(64)                    not null,
+    article_id uuid             default null,
+    comment_id uuid             default null,
+    created_at timestamp        default now() not null,
+    foreign key (user_id) references users (id),
+    foreign key (actor_id) references users (id),
+    foreign key (article_id) references articles (id),
+    foreign key (comment_id) references comments (id)
+);
+
+create index notifications_user_id_created_at_idx on notifications (user_id, created_at desc);
+
+create index comments_article_id_created_at_idx on comments (article_id, created_at);
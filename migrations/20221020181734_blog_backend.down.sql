@@ -0,0 +1,5 @@
+-- migration down file for blog_backend database
+
+drop index notifications_user_id_type_comment_id_idx;
+
+drop table outbox;
@@ -0,0 +1,22 @@
+-- migration up file for blog_backend database
+
+-- create outbox table, events are written in the same transaction as the change
+create table outbox
+(
+    id              bigserial primary key,
+    event_type      varchar(64)                      not null,
+    aggregate_id    uuid                             not null,
+    payload         jsonb                            not null,
+    created_at      timestamp   default now()        not null,
+    status          varchar(16) default 'pending'    not null,
+    attempts        int         default 0            not null,
+    next_attempt_at timestamp   default now()        not null,
+    locked_until    timestamp   default null,
+    published_at    timestamp   default null,
+    last_error      text        default null
+);
+
+create index outbox_pending_idx on outbox (next_attempt_at) where status = 'pending';
+
+-- notifications are created by outbox subscribers, which may receive an event more than once
+create unique index notifications_user_id_type_comment_id_idx on notifications (user_id, type, comment_id);
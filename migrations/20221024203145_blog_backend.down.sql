@@ -0,0 +1,5 @@
+-- migration down file for blog_backend database
+
+drop table webhook_deliveries;
+
+drop table webhooks;
@@ -0,0 +1,43 @@
+-- migration up file for blog_backend database
+
+-- create webhooks table, empty event_types means all events
+create table webhooks
+(
+    id          uuid primary key default uuid_generate_v4(),
+    owner_id    uuid                              not null,
+    url         varchar(2048)                     not null,
+    secret      varchar(256)                      not null,
+    event_types varchar(64)[]    default '{}'     not null,
+    is_global   boolean          default false    not null,
+    active      boolean          default true     not null,
+    created_at  timestamp        default now()    not null,
+    updated_at  timestamp        default now()    not null,
+    foreign key (owner_id) references users (id)
+);
+
+create index webhooks_owner_id_idx on webhooks (owner_id);
+
+-- create webhook_deliveries table, one row per event per webhook
+create table webhook_deliveries
+(
+    id               uuid primary key default uuid_generate_v4(),
+    webhook_id       uuid                             not null,
+    event_id         bigint                           not null,
+    event_type       varchar(64)                      not null,
+    payload          jsonb                            not null,
+    status           varchar(16)      default 'pending' not null,
+    attempts         int              default 0       not null,
+    next_attempt_at  timestamp        default now()   not null,
+    locked_until     timestamp        default null,
+    last_status_code int              default null,
+    last_error       text             default null,
+    last_attempt_at  timestamp        default null,
+    delivered_at     timestamp        default null,
+    created_at       timestamp        default now()   not null,
+    unique (webhook_id, event_id),
+    foreign key (webhook_id) references webhooks (id) on delete cascade
+);
+
+create index webhook_deliveries_pending_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
+
+create index webhook_deliveries_webhook_id_created_at_idx on webhook_deliveries (webhook_id, created_at desc);
@@ -0,0 +1,36 @@
+-- migration down file for blog_backend database
+
+drop table moderation_log;
+
+drop table reports;
+
+drop table moderation_cases;
+
+alter table notifications drop constraint notifications_comment_id_fkey,
+    add foreign key (comment_id) references comments (id);
+alter table notifications drop constraint notifications_article_id_fkey,
+    add foreign key (article_id) references articles (id);
+alter table articles_views drop constraint articles_views_article_id_fkey,
+    add foreign key (article_id) references articles (id);
+alter table votes_comments_down drop constraint votes_comments_down_comment_id_fkey,
+    add foreign key (comment_id) references comments (id);
+alter table votes_comments_up drop constraint votes_comments_up_comment_id_fkey,
+    add foreign key (comment_id) references comments (id);
+alter table votes_articles_down drop constraint votes_articles_down_article_id_fkey,
+    add foreign key (article_id) references articles (id);
+alter table votes_articles_up drop constraint votes_articles_up_article_id_fkey,
+    add foreign key (article_id) references articles (id);
+alter table articles_tags drop constraint articles_tags_article_id_fkey,
+    add foreign key (article_id) references articles (id);
+alter table users_comments_favorites drop constraint users_comments_favorites_comment_id_fkey,
+    add foreign key (comment_id) references comments (id);
+alter table users_articles_favorites drop constraint users_articles_favorites_article_id_fkey,
+    add foreign key (article_id) references articles (id);
+alter table comments drop constraint comments_parent_id_fkey,
+    add foreign key (parent_id) references comments (id);
+alter table comments drop constraint comments_article_id_fkey,
+    add foreign key (article_id) references articles (id);
+
+alter table users drop column banned_at;
+alter table comments drop column hidden_at;
+alter table articles drop column hidden_at;
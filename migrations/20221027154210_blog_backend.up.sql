@@ -0,0 +1,90 @@
+-- migration up file for blog_backend database
+
+-- hidden content stays in the database but is not shown to users
+alter table articles add column hidden_at timestamp default null;
+alter table comments add column hidden_at timestamp default null;
+
+-- banned users can't sign in
+alter table users add column banned_at timestamp default null;
+
+-- deleting content by moderator removes everything that references it
+alter table comments drop constraint comments_article_id_fkey,
+    add foreign key (article_id) references articles (id) on delete cascade;
+alter table comments drop constraint comments_parent_id_fkey,
+    add foreign key (parent_id) references comments (id) on delete cascade;
+alter table users_articles_favorites drop constraint users_articles_favorites_article_id_fkey,
+    add foreign key (article_id) references articles (id) on delete cascade;
+alter table users_comments_favorites drop constraint users_comments_favorites_comment_id_fkey,
+    add foreign key (comment_id) references comments (id) on delete cascade;
+alter table articles_tags drop constraint articles_tags_article_id_fkey,
+    add foreign key (article_id) references articles (id) on delete cascade;
+alter table votes_articles_up drop constraint votes_articles_up_article_id_fkey,
+    add foreign key (article_id) references articles (id) on delete cascade;
+alter table votes_articles_down drop constraint votes_articles_down_article_id_fkey,
+    add foreign key (article_id) references articles (id) on delete cascade;
+alter table votes_comments_up drop constraint votes_comments_up_comment_id_fkey,
+    add foreign key (comment_id) references comments (id) on delete cascade;
+alter table votes_comments_down drop constraint votes_comments_down_comment_id_fkey,
+    add foreign key (comment_id) references comments (id) on delete cascade;
+alter table articles_views drop constraint articles_views_article_id_fkey,
+    add foreign key (article_id) references articles (id) on delete cascade;
+alter table notifications drop constraint notifications_article_id_fkey,
+    add foreign key (article_id) references articles (id) on delete cascade;
+alter table notifications drop constraint notifications_comment_id_fkey,
+    add foreign key (comment_id) references comments (id) on delete cascade;
+
+-- create moderation_cases table, reports of the same target are aggregated in one case until it is resolved
+create table moderation_cases
+(
+    id               uuid primary key default uuid_generate_v4(),
+    target_type      varchar(16)                     not null,
+    target_id        uuid                            not null,
+    target_author_id uuid                            not null,
+    status           varchar(16)      default 'open' not null,
+    reports_count    int              default 0      not null,
+    assignee_id      uuid             default null,
+    claimed_at       timestamp        default null,
+    resolution       varchar(16)      default null,
+    resolved_by      uuid             default null,
+    resolved_at      timestamp        default null,
+    created_at       timestamp        default now()  not null,
+    updated_at       timestamp        default now()  not null,
+    foreign key (target_author_id) references users (id),
+    foreign key (assignee_id) references users (id),
+    foreign key (resolved_by) references users (id)
+);
+
+create unique index moderation_cases_target_idx on moderation_cases (target_type, target_id) where status <> 'resolved';
+
+create index moderation_cases_queue_idx on moderation_cases (status, reports_count desc, created_at);
+
+-- create reports table
+create table reports
+(
+    id          uuid primary key default uuid_generate_v4(),
+    case_id     uuid                           not null,
+    reporter_id uuid                           not null,
+    reason      varchar(32)                    not null,
+    comment     varchar(1024)    default ''    not null,
+    created_at  timestamp        default now() not null,
+    unique (case_id, reporter_id),
+    foreign key (case_id) references moderation_cases (id) on delete cascade,
+    foreign key (reporter_id) references users (id)
+);
+
+-- create moderation_log table
+create table moderation_log
+(
+    id           uuid primary key default uuid_generate_v4(),
+    case_id      uuid             default null,
+    moderator_id uuid                           not null,
+    action       varchar(16)                    not null,
+    target_type  varchar(16)                    not null,
+    target_id    uuid                           not null,
+    note         varchar(1024)    default ''    not null,
+    created_at   timestamp        default now() not null,
+    foreign key (case_id) references moderation_cases (id),
+    foreign key (moderator_id) references users (id)
+);
+
+create index moderation_log_created_at_idx on moderation_log (created_at desc);
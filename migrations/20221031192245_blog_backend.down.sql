@@ -0,0 +1,52 @@
+-- migration down file for blog_backend database
+
+alter table moderation_log drop constraint moderation_log_case_id_fkey,
+    add foreign key (case_id) references moderation_cases (id);
+alter table moderation_log drop constraint moderation_log_moderator_id_fkey,
+    add foreign key (moderator_id) references users (id);
+alter table moderation_log alter column moderator_id set not null;
+alter table moderation_cases drop constraint moderation_cases_resolved_by_fkey,
+    add foreign key (resolved_by) references users (id);
+alter table moderation_cases drop constraint moderation_cases_assignee_id_fkey,
+    add foreign key (assignee_id) references users (id);
+
+alter table moderation_cases drop constraint moderation_cases_target_author_id_fkey,
+    add foreign key (target_author_id) references users (id);
+alter table reports drop constraint reports_reporter_id_fkey,
+    add foreign key (reporter_id) references users (id);
+alter table webhooks drop constraint webhooks_owner_id_fkey,
+    add foreign key (owner_id) references users (id);
+alter table notifications drop constraint notifications_actor_id_fkey,
+    add foreign key (actor_id) references users (id);
+alter table notifications drop constraint notifications_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table articles_views drop constraint articles_views_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table votes_comments_down drop constraint votes_comments_down_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table votes_comments_up drop constraint votes_comments_up_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table votes_articles_down drop constraint votes_articles_down_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table votes_articles_up drop constraint votes_articles_up_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table users_comments_favorites drop constraint users_comments_favorites_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table users_articles_favorites drop constraint users_articles_favorites_user_id_fkey,
+    add foreign key (user_id) references users (id);
+alter table users_followers drop constraint users_followers_following_id_fkey,
+    add foreign key (following_id) references users (id);
+alter table users_followers drop constraint users_followers_follower_id_fkey,
+    add foreign key (follower_id) references users (id);
+alter table comments drop constraint comments_author_id_fkey,
+    add foreign key (author_id) references users (id);
+alter table articles drop constraint articles_author_id_fkey,
+    add foreign key (author_id) references users (id);
+
+drop index comments_deleted_at_idx;
+drop index articles_deleted_at_idx;
+drop index users_deleted_at_idx;
+
+alter table comments drop column deleted_at;
+alter table articles drop column deleted_at;
+alter table users drop column deleted_at;
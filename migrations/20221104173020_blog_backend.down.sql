@@ -0,0 +1,10 @@
+-- migration down file for blog_backend database
+
+drop table user_exports;
+
+-- anonymized content is removed by cascade together with the tombstone author
+delete from users where id = 'ffffffff-ffff-ffff-ffff-ffffffffffff';
+
+drop index users_deletion_scheduled_at_idx;
+
+alter table users drop column deletion_scheduled_at;
@@ -0,0 +1,32 @@
+-- migration up file for blog_backend database
+
+-- account is anonymized when the grace period is over, until then the user can cancel the deletion
+alter table users add column deletion_scheduled_at timestamp default null;
+
+create index users_deletion_scheduled_at_idx on users (deletion_scheduled_at) where deletion_scheduled_at is not null;
+
+-- content of anonymized accounts is moved to the tombstone author, nobody can sign in as him
+insert into users (id, name, username, password, email, role, description, banned_at)
+values ('ffffffff-ffff-ffff-ffff-ffffffffffff', 'Deleted user', 'deleted', '', 'deleted@invalid', 'user',
+        'content of deleted accounts', now());
+
+-- create user_exports table, archive is kept until expires_at
+create table user_exports
+(
+    id           uuid primary key default uuid_generate_v4(),
+    user_id      uuid                               not null,
+    status       varchar(16)      default 'pending' not null,
+    attempts     int              default 0         not null,
+    locked_until timestamp        default null,
+    archive      bytea            default null,
+    size         bigint           default null,
+    last_error   text             default null,
+    created_at   timestamp        default now()     not null,
+    completed_at timestamp        default null,
+    expires_at   timestamp        default null,
+    foreign key (user_id) references users (id) on delete cascade
+);
+
+create index user_exports_pending_idx on user_exports (created_at) where status = 'pending';
+
+create index user_exports_user_id_created_at_idx on user_exports (user_id, created_at desc);
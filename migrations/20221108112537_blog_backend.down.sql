@@ -0,0 +1,6 @@
+-- migration down file for blog_backend database
+
+drop table audit_log;
+
+alter table users drop column password_reset_required;
+alter table users drop column sessions_revoked_at;
@@ -0,0 +1,25 @@
+-- migration up file for blog_backend database
+
+-- tokens issued before sessions_revoked_at are rejected
+alter table users add column sessions_revoked_at timestamp default null;
+-- user signed in with a temporary password can only change it
+alter table users add column password_reset_required boolean default false not null;
+
+-- create audit_log table, impersonator_id is set for actions made on behalf of another user
+create table audit_log
+(
+    id              uuid primary key default uuid_generate_v4(),
+    actor_id        uuid                          default null,
+    impersonator_id uuid                          default null,
+    action          varchar(64)                   not null,
+    target_type     varchar(32)                   not null,
+    target_id       uuid                          default null,
+    details         jsonb            default '{}' not null,
+    created_at      timestamp        default now() not null,
+    foreign key (actor_id) references users (id) on delete set null,
+    foreign key (impersonator_id) references users (id) on delete set null
+);
+
+create index audit_log_created_at_idx on audit_log (created_at desc);
+create index audit_log_actor_id_idx on audit_log (actor_id, created_at desc);
+create index audit_log_target_id_idx on audit_log (target_id, created_at desc);
@@ -0,0 +1,17 @@
+-- migration down file for blog_backend database
+
+drop trigger audit_log_no_truncate on audit_log;
+drop trigger audit_log_no_update_delete on audit_log;
+drop function audit_log_append_only();
+
+drop index audit_log_request_id_idx;
+drop index audit_log_action_idx;
+
+update audit_log set actor_id = null where actor_id not in (select id from users);
+update audit_log set impersonator_id = null where impersonator_id not in (select id from users);
+alter table audit_log add foreign key (actor_id) references users (id) on delete set null;
+alter table audit_log add foreign key (impersonator_id) references users (id) on delete set null;
+
+alter table audit_log drop column request_id;
+alter table audit_log drop column user_agent;
+alter table audit_log drop column ip;
@@ -0,0 +1,33 @@
+-- migration up file for blog_backend database
+
+-- request the action was made in
+alter table audit_log add column ip varchar(64) default '' not null;
+alter table audit_log add column user_agent varchar(512) default '' not null;
+alter table audit_log add column request_id varchar(128) default '' not null;
+
+-- actors are kept after users are purged, set null on delete would be an update of the log
+alter table audit_log drop constraint audit_log_actor_id_fkey;
+alter table audit_log drop constraint audit_log_impersonator_id_fkey;
+
+create index audit_log_action_idx on audit_log (action, created_at desc);
+create index audit_log_request_id_idx on audit_log (request_id) where request_id <> '';
+
+-- the log is append-only
+create function audit_log_append_only() returns trigger as
+$$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_log_no_update_delete
+    before update or delete
+    on audit_log
+    for each row
+execute procedure audit_log_append_only();
+
+create trigger audit_log_no_truncate
+    before truncate
+    on audit_log
+    for each statement
+execute procedure audit_log_append_only();
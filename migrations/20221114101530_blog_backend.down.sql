@@ -0,0 +1,4 @@
+-- migration down file for blog_backend database
+
+drop index notifications_dedup_idx;
+create unique index notifications_user_id_type_comment_id_idx on notifications (user_id, type, comment_id);
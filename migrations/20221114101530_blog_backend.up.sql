@@ -0,0 +1,12 @@
+-- migration up file for blog_backend database
+
+-- nulls are distinct in a unique index, so follow and favorite notifications without a comment
+-- were never deduplicated; an event delivered twice creates one notification of the same actor and target
+drop index notifications_user_id_type_comment_id_idx;
+create unique index notifications_dedup_idx on notifications (
+    user_id,
+    type,
+    actor_id,
+    coalesce(article_id, '00000000-0000-0000-0000-000000000000'),
+    coalesce(comment_id, '00000000-0000-0000-0000-000000000000')
+);
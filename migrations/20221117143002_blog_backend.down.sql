@@ -0,0 +1,3 @@
+-- migration down file for blog_backend database
+
+alter table users_followers drop constraint users_followers_follower_id_following_id_key;
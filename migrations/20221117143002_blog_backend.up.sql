@@ -0,0 +1,17 @@
+-- migration up file for blog_backend database
+
+-- concurrent follow requests could store the same pair twice and count it twice;
+-- duplicates are removed and the follow counters recounted before the pair becomes unique
+delete
+from users_followers f
+    using users_followers d
+where f.follower_id = d.follower_id
+  and f.following_id = d.following_id
+  and f.id > d.id;
+
+update users u
+set followers_count  = (select count(*) from users_followers f where f.following_id = u.id),
+    followings_count = (select count(*) from users_followers f where f.follower_id = u.id);
+
+alter table users_followers
+    add constraint users_followers_follower_id_following_id_key unique (follower_id, following_id);
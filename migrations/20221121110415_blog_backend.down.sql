@@ -0,0 +1,3 @@
+-- migration down file for blog_backend database
+
+alter table users_articles_favorites drop constraint users_articles_favorites_user_id_article_id_key;
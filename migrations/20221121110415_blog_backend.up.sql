@@ -0,0 +1,19 @@
+-- migration up file for blog_backend database
+
+-- concurrent favorite requests could store the same pair twice and count it twice;
+-- duplicates are removed and the favorite counters recounted before the pair becomes unique
+delete
+from users_articles_favorites f
+    using users_articles_favorites d
+where f.user_id = d.user_id
+  and f.article_id = d.article_id
+  and f.id > d.id;
+
+update articles a
+set favorites_count = (select count(*) from users_articles_favorites f where f.article_id = a.id);
+
+update users u
+set favorites_articles_count = (select count(*) from users_articles_favorites f where f.user_id = u.id);
+
+alter table users_articles_favorites
+    add constraint users_articles_favorites_user_id_article_id_key unique (user_id, article_id);
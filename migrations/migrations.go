@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const upSuffix = ".up.sql"
+
+// FS - миграции встроены в бинарник, поэтому версия схемы, которую ждет код, известна без доступа к файлам
+//
+//go:embed *.sql
+var FS embed.FS
+
+type Migration struct {
+	Version uint
+	Name    string // file name of the up migration
+}
+
+// List - встроенные миграции по возрастанию версии, имя файла начинается с версии: <version>_<name>.up.sql
+func List() ([]Migration, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrations - List - fs.ReadDir: %w", err)
+	}
+
+	var list []Migration
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, upSuffix) {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrations - List - bad migration name %s: %w", name, err)
+		}
+
+		list = append(list, Migration{Version: uint(version), Name: name})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// LatestVersion - версия последней встроенной миграции
+func LatestVersion() (uint, error) {
+	list, err := List()
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+	return list[len(list)-1].Version, nil
+}
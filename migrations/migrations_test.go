@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	list, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) == 0 {
+		t.Fatal("no migrations are embedded")
+	}
+
+	for i, migration := range list {
+		if i > 0 && migration.Version <= list[i-1].Version {
+			t.Errorf("%s is listed after %s", migration.Name, list[i-1].Name)
+		}
+
+		// every migration can be rolled back by "migrate down"
+		down := strings.TrimSuffix(migration.Name, upSuffix) + ".down.sql"
+		if _, err := fs.Stat(FS, down); err != nil {
+			t.Errorf("%s has no down migration: %v", migration.Name, err)
+		}
+	}
+
+	latest, err := LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != list[len(list)-1].Version {
+		t.Errorf("LatestVersion() = %d, want %d", latest, list[len(list)-1].Version)
+	}
+}
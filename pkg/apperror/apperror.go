@@ -0,0 +1,67 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error - ошибка, которую можно показать клиенту: постоянный код, статус HTTP и безопасные детали.
+// Причина (cause) только логируется и клиенту не отдается
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Details map[string]interface{}
+	cause   error
+}
+
+func New(code string, status int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+// Internal - ошибка, не известная вызывающему коду, клиент видит только общий ответ
+var Internal = New("internal_error", http.StatusInternalServerError, "internal server error")
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is - ошибки с одинаковым кодом равны, поэтому копии с деталями и причиной находятся errors.Is по исходной ошибке
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails - копия ошибки с деталями для клиента, исходная ошибка не меняется
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// Wrap - копия ошибки с причиной для логов
+func (e *Error) Wrap(cause error) *Error {
+	c := *e
+	c.cause = cause
+	return &c
+}
+
+// From - ошибка приложения из цепочки err, любая другая ошибка считается внутренней
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal.Wrap(err)
+}
@@ -0,0 +1,60 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errNotFound = New("not_found", http.StatusNotFound, "not found")
+
+func TestError_Is(t *testing.T) {
+	cause := errors.New("no rows")
+	err := fmt.Errorf("handler: %w", errNotFound.WithDetails(map[string]interface{}{"id": 1}).Wrap(cause))
+
+	if !errors.Is(err, errNotFound) {
+		t.Error("copy with details and cause is not the original error")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("cause is lost")
+	}
+	if errors.Is(err, New("conflict", http.StatusConflict, "not found")) {
+		t.Error("errors with another code are equal")
+	}
+}
+
+func TestError_Copies(t *testing.T) {
+	details := errNotFound.WithDetails(map[string]interface{}{"id": 1})
+	wrapped := details.Wrap(errors.New("no rows"))
+
+	// the package level error is shared by all requests and must stay unchanged
+	if errNotFound.Details != nil || errNotFound.Unwrap() != nil {
+		t.Errorf("original error is changed: %+v", errNotFound)
+	}
+	if wrapped.Details["id"] != 1 {
+		t.Errorf("details are lost on Wrap: %v", wrapped.Details)
+	}
+	if got := wrapped.Error(); got != "not found: no rows" {
+		t.Errorf("Error() = %q, want the message with the cause", got)
+	}
+	if got := errNotFound.Error(); got != "not found" {
+		t.Errorf("Error() = %q, want the message", got)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	if got := From(fmt.Errorf("usecase: %w", errNotFound)); got != errNotFound {
+		t.Errorf("From() = %v, want the application error from the chain", got)
+	}
+
+	cause := errors.New("connection refused")
+	got := From(cause)
+	if !errors.Is(got, Internal) || got.Status != http.StatusInternalServerError || !errors.Is(got, cause) {
+		t.Errorf("From() = %+v, want internal error wrapping the cause", got)
+	}
+	// the cause is logged, not shown to the client
+	if got.Message != Internal.Message {
+		t.Errorf("message = %q, want %q", got.Message, Internal.Message)
+	}
+}
@@ -0,0 +1,19 @@
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Exponential - задержка перед попыткой attempt (с 1): min * 2^(attempt-1), но не больше max, плюс jitter до 20%
+func Exponential(attempt int, min, max time.Duration) time.Duration {
+	delay := min
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
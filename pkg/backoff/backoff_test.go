@@ -0,0 +1,35 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 6, want: 30 * time.Second},
+		{attempt: 100, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			// jitter adds up to 20% of the delay
+			got := Exponential(tt.attempt, time.Second, 30*time.Second)
+			if got < tt.want || got > tt.want+tt.want/5 {
+				t.Fatalf("Exponential(%d) = %s, want %s + up to 20%%", tt.attempt, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestExponential_Zero(t *testing.T) {
+	if got := Exponential(3, 0, 0); got != 0 {
+		t.Errorf("Exponential() = %s without delay, want 0", got)
+	}
+}
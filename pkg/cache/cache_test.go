@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache func(t *testing.T) (Cache, func(time.Duration))
+	}{
+		{"LRU", func(t *testing.T) (Cache, func(time.Duration)) {
+			// the LRU reads the wall clock, so expiry is waited for
+			return NewLRU(10), time.Sleep
+		}},
+		{"Redis", func(t *testing.T) (Cache, func(time.Duration)) {
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			return NewRedis(client), server.FastForward
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, wait := tt.cache(t)
+
+			_, err := c.Get(ctx, "missing")
+			if !errors.Is(err, ErrMiss) {
+				t.Errorf("Get of missing key: err = %v, want %v", err, ErrMiss)
+			}
+
+			for _, key := range []string{"a", "b", "short"} {
+				ttl := time.Hour
+				if key == "short" {
+					ttl = 50 * time.Millisecond
+				}
+				if err := c.Set(ctx, key, []byte("value "+key), ttl); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			value, err := c.Get(ctx, "a")
+			if err != nil || string(value) != "value a" {
+				t.Errorf("Get(a) = %q, %v", value, err)
+			}
+
+			err = c.Delete(ctx, "a", "missing")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+				t.Errorf("Get of deleted key: err = %v, want %v", err, ErrMiss)
+			}
+
+			wait(100 * time.Millisecond)
+			if _, err := c.Get(ctx, "short"); !errors.Is(err, ErrMiss) {
+				t.Errorf("Get of expired key: err = %v, want %v", err, ErrMiss)
+			}
+			if _, err := c.Get(ctx, "b"); err != nil {
+				t.Errorf("Get(b) after another key expired: %v", err)
+			}
+		})
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	_ = c.Set(ctx, "a", []byte("a"), time.Hour)
+	_ = c.Set(ctx, "b", []byte("b"), time.Hour)
+
+	// reading a makes b the least recently used
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set(ctx, "c", []byte("c"), time.Hour)
+
+	if _, err := c.Get(ctx, "b"); !errors.Is(err, ErrMiss) {
+		t.Errorf("least recently used key is not evicted: err = %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, err := c.Get(ctx, key); err != nil {
+			t.Errorf("Get(%s): %v", key, err)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+}
+
+func TestRedisPrefix(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	c := NewRedis(client, RedisPrefix("test:"))
+	if err := c.Set(context.Background(), "key", []byte("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := server.Get("test:key"); err != nil || got != "value" {
+		t.Errorf("stored value = %q, %v", got, err)
+	}
+	if ttl := server.TTL("test:key"); ttl != time.Minute {
+		t.Errorf("ttl = %s, want 1m", ttl)
+	}
+
+	server.Close()
+	if _, err := c.Get(context.Background(), "key"); err == nil || errors.Is(err, ErrMiss) {
+		t.Errorf("Get with redis down: err = %v, want a connection error", err)
+	}
+}
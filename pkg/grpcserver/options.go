@@ -0,0 +1,36 @@
+package grpcserver
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"net"
+	"time"
+)
+
+type Option func(*Server)
+
+func Port(port string) Option {
+	return func(s *Server) {
+		s.address = net.JoinHostPort("", port)
+	}
+}
+
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// Credentials - без них соединения не шифруются
+func Credentials(creds credentials.TransportCredentials) Option {
+	return func(s *Server) {
+		s.serverOptions = append(s.serverOptions, grpc.Creds(creds))
+	}
+}
+
+// ServerOptions - например цепочки перехватчиков
+func ServerOptions(opts ...grpc.ServerOption) Option {
+	return func(s *Server) {
+		s.serverOptions = append(s.serverOptions, opts...)
+	}
+}
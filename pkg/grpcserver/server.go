@@ -0,0 +1,75 @@
+package grpcserver
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"net"
+	"time"
+)
+
+const (
+	defaultAddr            = ":9000"
+	defaultShutdownTimeout = 3 * time.Second
+)
+
+// Server - gRPC сервер с reflection, сервисы регистрируются в register до начала приема соединений
+type Server struct {
+	server          *grpc.Server
+	address         string
+	serverOptions   []grpc.ServerOption
+	notify          chan error
+	shutdownTimeout time.Duration
+}
+
+func New(register func(s *grpc.Server), opts ...Option) *Server {
+	s := &Server{
+		address:         defaultAddr,
+		notify:          make(chan error, 1),
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = grpc.NewServer(s.serverOptions...)
+	reflection.Register(s.server)
+	register(s.server)
+
+	s.start()
+
+	return s
+}
+
+func (s *Server) start() {
+	go func() {
+		listener, err := net.Listen("tcp", s.address)
+		if err == nil {
+			err = s.server.Serve(listener)
+		}
+		s.notify <- err
+		close(s.notify)
+	}()
+}
+
+func (s *Server) Notify() <-chan error {
+	return s.notify
+}
+
+// Shutdown - текущие вызовы завершаются, пока не истечет shutdownTimeout, после этого соединения закрываются
+func (s *Server) Shutdown() error {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-time.After(s.shutdownTimeout):
+		s.server.Stop()
+		return context.DeadlineExceeded
+	}
+}
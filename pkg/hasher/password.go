@@ -5,6 +5,8 @@ import (
 	"fmt"
 )
 
+//go:generate mockgen -source=password.go -destination=mocks/password.go -package=mocks
+
 type PasswordHasher interface {
 	Hash(password string) string
 }
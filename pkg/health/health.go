@@ -0,0 +1,158 @@
+package health
+
+import (
+	"blog-backend/pkg/logger"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTimeout = 2 * time.Second
+
+	shutdownCheck = "shutdown"
+)
+
+var errShuttingDown = errors.New("server is shutting down")
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+// CheckFunc - проверка зависимости, nil - зависимость доступна
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    Status  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Health - набор проверок для liveness и readiness. Проверки выполняются параллельно, каждая со своим таймаутом.
+// Текст ошибок пишется в лог, а не в ответ: эндпоинты доступны снаружи
+type Health struct {
+	timeout time.Duration
+
+	mu        sync.RWMutex
+	liveness  []check
+	readiness []check
+
+	shuttingDown atomic.Bool
+}
+
+func New(opts ...Option) *Health {
+	h := &Health{
+		timeout: defaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// AddLivenessCheck - проверка, при провале которой процесс нужно перезапустить.
+// Внешние зависимости сюда не добавляются, их недоступность перезапуск не исправит
+func (h *Health) AddLivenessCheck(name string, fn CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, check{name: name, fn: fn})
+}
+
+// AddReadinessCheck - проверка, при провале которой на экземпляр не нужно направлять запросы
+func (h *Health) AddReadinessCheck(name string, fn CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, check{name: name, fn: fn})
+}
+
+// Shutdown - с этого момента readiness не проходит, балансировщик перестает направлять запросы до остановки сервера
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *Health) Live(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.liveness
+	h.mu.RUnlock()
+
+	return h.run(ctx, checks)
+}
+
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.readiness
+	h.mu.RUnlock()
+
+	checks = append([]check{{name: shutdownCheck, fn: h.checkShutdown}}, checks...)
+
+	return h.run(ctx, checks)
+}
+
+func (h *Health) checkShutdown(context.Context) error {
+	if h.shuttingDown.Load() {
+		return errShuttingDown
+	}
+	return nil
+}
+
+func (h *Health) run(ctx context.Context, checks []check) Report {
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = h.runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func (h *Health) runCheck(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = StatusFail
+		logger.FromContext(ctx).Warnf("health - check %s: %v", c.name, err)
+	}
+
+	return result
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHealth_Parallel(t *testing.T) {
+	h := New(Timeout(time.Second))
+
+	// every check waits for the other one, sequential checks would time out
+	started := make(chan struct{}, 2)
+	barrier := func(ctx context.Context) error {
+		started <- struct{}{}
+		for len(started) < 2 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Millisecond):
+			}
+		}
+		return nil
+	}
+	h.AddReadinessCheck("postgres", barrier)
+	h.AddReadinessCheck("redis", barrier)
+
+	report := h.Ready(context.Background())
+	if report.Status != StatusOK {
+		t.Fatalf("report = %+v, want ok", report)
+	}
+	for _, name := range []string{shutdownCheck, "postgres", "redis"} {
+		if report.Checks[name].Status != StatusOK {
+			t.Errorf("check %s = %+v, want ok", name, report.Checks[name])
+		}
+	}
+}
+
+func TestHealth_Timeout(t *testing.T) {
+	h := New(Timeout(20 * time.Millisecond))
+	h.AddReadinessCheck("hanging", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	h.AddReadinessCheck("failing", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	start := time.Now()
+	report := h.Ready(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("report took %s, want about the check timeout", elapsed)
+	}
+
+	if report.Status != StatusFail || report.Checks["hanging"].Status != StatusFail || report.Checks["failing"].Status != StatusFail {
+		t.Errorf("report = %+v, want both checks failed", report)
+	}
+	if report.Checks[shutdownCheck].Status != StatusOK {
+		t.Errorf("shutdown check failed before Shutdown")
+	}
+}
+
+func TestHealth_Shutdown(t *testing.T) {
+	h := New()
+	h.AddLivenessCheck("goroutines", func(ctx context.Context) error { return nil })
+	h.AddReadinessCheck("postgres", func(ctx context.Context) error { return nil })
+
+	if report := h.Ready(context.Background()); report.Status != StatusOK {
+		t.Fatalf("ready before shutdown = %+v, want ok", report)
+	}
+
+	h.Shutdown()
+
+	report := h.Ready(context.Background())
+	if report.Status != StatusFail || report.Checks[shutdownCheck].Status != StatusFail {
+		t.Errorf("ready after shutdown = %+v, want failed shutdown check", report)
+	}
+	if report.Checks["postgres"].Status != StatusOK {
+		t.Errorf("dependencies are reported failed on shutdown")
+	}
+
+	// the process is still alive and must not be restarted while draining
+	if report := h.Live(context.Background()); report.Status != StatusOK {
+		t.Errorf("live after shutdown = %+v, want ok", report)
+	}
+}
@@ -0,0 +1,12 @@
+package health
+
+import "time"
+
+type Option func(*Health)
+
+// Timeout - время на одну проверку, зависшая зависимость не должна задерживать ответ пробы
+func Timeout(timeout time.Duration) Option {
+	return func(h *Health) {
+		h.timeout = timeout
+	}
+}
@@ -30,3 +30,11 @@ func ShutdownTimeout(timeout time.Duration) Option {
 		s.shutdownTimeout = timeout
 	}
 }
+
+// OnShutdown - функция вызывается в начале Shutdown, нужна для закрытия долгоживущих соединений (SSE, WebSocket),
+// которые иначе держат сервер до истечения shutdownTimeout
+func OnShutdown(f func()) Option {
+	return func(s *Server) {
+		s.server.RegisterOnShutdown(f)
+	}
+}
@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldMethod    = "method"
+	FieldRoute     = "route"
+	FieldTraceID   = "trace_id"
+)
+
+type loggerKey struct{}
+
+// WithFields - контекст с логгером, дополненным полями, поля логгера из родительского контекста сохраняются
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	return context.WithValue(ctx, loggerKey{}, FromContext(ctx).WithFields(fields))
+}
+
+// FromContext - логгер запроса с его полями (request id, пользователь, маршрут), вне запроса - стандартный логгер
+func FromContext(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(loggerKey{}).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
@@ -0,0 +1,123 @@
+// Package netguard - защита исходящих запросов на адреса, заданные пользователями, от SSRF:
+// разрешены только http и https на публичные адреса. Адрес проверяется при сохранении URL
+// и еще раз при каждом подключении, после разрешения имени, поэтому DNS rebinding не помогает
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrScheme  = errors.New("only http and https urls are allowed")
+	ErrHost    = errors.New("url has no host")
+	ErrAddress = errors.New("address is not public")
+)
+
+// nonPublic - адреса, которые не должны быть доступны по URL от пользователей, кроме тех,
+// что покрывают методы netip.Addr (loopback, private, link-local, multicast, unspecified)
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64 may lead to any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// IsPublic - адрес доступен из интернета, IPv4 в IPv6 проверяется как IPv4
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL - схема http или https, а все адреса хоста публичные. Имя разрешается, чтобы
+// отклонить внутренние имена сразу, подключение все равно проверяется в Control
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("url.Parse: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrScheme
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return ErrHost
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublic(addr) {
+			return ErrAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("net.DefaultResolver.LookupNetIP: %w", err)
+	}
+
+	for _, addr := range addrs {
+		if !IsPublic(addr) {
+			return ErrAddress
+		}
+	}
+
+	return nil
+}
+
+// Control - для net.Dialer.Control: address уже содержит разрешенный IP, поэтому подмена
+// DNS ответа между проверкой URL и подключением не приводит к запросу во внутреннюю сеть
+func Control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("netguard: %s: %w", address, err)
+	}
+
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("netguard: %s: %w", address, ErrAddress)
+	}
+
+	return nil
+}
+
+// Transport - http.Transport по умолчанию, подключающийся только к публичным адресам.
+// Прокси из окружения не используется: подключение к нему обошло бы проверку адреса
+func Transport(dialTimeout time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}).DialContext
+
+	return transport
+}
@@ -0,0 +1,96 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "8.8.8.8", want: true},
+		{addr: "2606:4700:4700::1111", want: true},
+		{addr: "127.0.0.1", want: false},
+		{addr: "10.0.0.1", want: false},
+		{addr: "172.16.0.1", want: false},
+		{addr: "192.168.1.1", want: false},
+		{addr: "169.254.169.254", want: false},
+		{addr: "100.64.0.1", want: false},
+		{addr: "0.0.0.0", want: false},
+		{addr: "255.255.255.255", want: false},
+		{addr: "224.0.0.1", want: false},
+		{addr: "::1", want: false},
+		{addr: "::", want: false},
+		{addr: "fc00::1", want: false},
+		{addr: "fe80::1", want: false},
+		{addr: "::ffff:127.0.0.1", want: false},
+		{addr: "64:ff9b::7f00:1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("IsPublic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url string
+		err error
+	}{
+		{url: "http://8.8.8.8/hook", err: nil},
+		{url: "https://[2606:4700:4700::1111]:8443/hook", err: nil},
+		{url: "ftp://8.8.8.8/hook", err: ErrScheme},
+		{url: "file:///etc/passwd", err: ErrScheme},
+		{url: "http:///hook", err: ErrHost},
+		{url: "http://127.0.0.1:8080/hook", err: ErrAddress},
+		{url: "http://169.254.169.254/latest/meta-data", err: ErrAddress},
+		{url: "http://[::1]/hook", err: ErrAddress},
+		{url: "http://localhost/hook", err: ErrAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := CheckURL(context.Background(), tt.url)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("CheckURL() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestControl(t *testing.T) {
+	if err := Control("tcp", "8.8.8.8:443", nil); err != nil {
+		t.Errorf("public address is rejected: %v", err)
+	}
+	if err := Control("tcp", "10.0.0.1:443", nil); !errors.Is(err, ErrAddress) {
+		t.Errorf("private address error = %v, want %v", err, ErrAddress)
+	}
+}
+
+// the url passed the check earlier, but the name resolves to loopback on connect
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the local server")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(time.Second)}
+	resp, err := client.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrAddress) {
+		t.Errorf("client.Get() error = %v, want %v", err, ErrAddress)
+	}
+}
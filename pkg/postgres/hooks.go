@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"blog-backend/pkg/logger"
+	"context"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+// Query - выполненный запрос. Аргументы в хуки не передаются, в них могут быть пароли и персональные данные
+type Query struct {
+	// Op - операция pgx: Exec, Query, SendBatch и т.д.
+	Op       string
+	SQL      string
+	End      time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// QueryHook - вызывается после каждого запроса к любому узлу, синхронно, поэтому должен быть быстрым
+type QueryHook interface {
+	AfterQuery(ctx context.Context, q Query)
+}
+
+type QueryHookFunc func(ctx context.Context, q Query)
+
+func (f QueryHookFunc) AfterQuery(ctx context.Context, q Query) {
+	f(ctx, q)
+}
+
+// queryHooks - в pgx v4 нет хуков запросов, поэтому они строятся по записям логгера pgx:
+// запись приходит после выполнения запроса вместе с его контекстом и длительностью
+type queryHooks []QueryHook
+
+func (h queryHooks) Log(ctx context.Context, _ pgx.LogLevel, msg string, data map[string]interface{}) {
+	// only entries about executed queries carry their duration
+	duration, ok := data["time"].(time.Duration)
+	if !ok {
+		return
+	}
+
+	q := Query{Op: msg, End: time.Now(), Duration: duration}
+	q.SQL, _ = data["sql"].(string)
+	q.Err, _ = data["err"].(error)
+
+	for _, hook := range h {
+		hook.AfterQuery(ctx, q)
+	}
+}
+
+// slowQueryLogger - запросы дольше порога пишутся в лог запроса, SQL без литералов
+func slowQueryLogger(threshold time.Duration) QueryHook {
+	return QueryHookFunc(func(ctx context.Context, q Query) {
+		if q.Duration < threshold {
+			return
+		}
+		logger.FromContext(ctx).Warnf("Postgres slow %s (%s): %s", q.Op, q.Duration.Round(time.Millisecond), SanitizeSQL(q.SQL))
+	})
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"testing"
+	"time"
+)
+
+func TestQueryHooks(t *testing.T) {
+	var got []Query
+	hooks := queryHooks{QueryHookFunc(func(_ context.Context, q Query) {
+		got = append(got, q)
+	})}
+
+	errQuery := errors.New("query failed")
+	hooks.Log(context.Background(), pgx.LogLevelInfo, "Query", map[string]interface{}{
+		"sql":  "SELECT 1",
+		"args": []interface{}{"secret"},
+		"time": 20 * time.Millisecond,
+	})
+	hooks.Log(context.Background(), pgx.LogLevelError, "Exec", map[string]interface{}{
+		"sql":  "UPDATE users SET name = $1",
+		"err":  errQuery,
+		"time": time.Millisecond,
+	})
+	// entries without a duration are not about executed queries
+	hooks.Log(context.Background(), pgx.LogLevelInfo, "Dialing PostgreSQL server", map[string]interface{}{"host": "db"})
+
+	if len(got) != 2 {
+		t.Fatalf("hooks were called %d times, want 2", len(got))
+	}
+	if got[0].Op != "Query" || got[0].SQL != "SELECT 1" || got[0].Duration != 20*time.Millisecond || got[0].Err != nil {
+		t.Errorf("unexpected query: %+v", got[0])
+	}
+	if got[1].Op != "Exec" || !errors.Is(got[1].Err, errQuery) {
+		t.Errorf("unexpected query: %+v", got[1])
+	}
+	if got[0].End.IsZero() {
+		t.Error("query end is not set")
+	}
+}
@@ -1,6 +1,9 @@
 package postgres
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type Option func(*Postgres)
 
@@ -16,8 +19,125 @@ func ConnAttempts(attempts int) Option {
 	}
 }
 
+// ConnTimeout - задержка перед второй попыткой подключения, дальше она удваивается до ConnMaxDelay
 func ConnTimeout(timeout time.Duration) Option {
 	return func(c *Postgres) {
 		c.connTimeout = timeout
 	}
 }
+
+// ConnMaxDelay - верхняя граница задержки между попытками подключения
+func ConnMaxDelay(delay time.Duration) Option {
+	return func(c *Postgres) {
+		c.connMaxDelay = delay
+	}
+}
+
+// MinConns - сколько соединений пул держит открытыми даже без нагрузки
+func MinConns(conns int) Option {
+	return func(c *Postgres) {
+		c.minConns = conns
+	}
+}
+
+// MaxConnLifetime - соединение старше закрывается и открывается заново, например чтобы перераспределиться за балансировщиком
+func MaxConnLifetime(lifetime time.Duration) Option {
+	return func(c *Postgres) {
+		c.maxConnLifetime = lifetime
+	}
+}
+
+// MaxConnIdleTime - простаивающее дольше соединение закрывается, но не ниже MinConns
+func MaxConnIdleTime(idle time.Duration) Option {
+	return func(c *Postgres) {
+		c.maxConnIdleTime = idle
+	}
+}
+
+// StatementTimeout - statement_timeout сессии: postgres прерывает запрос дольше, даже если контекст запроса не отменен
+func StatementTimeout(timeout time.Duration) Option {
+	return func(c *Postgres) {
+		c.statementTimeout = timeout
+	}
+}
+
+// ApplicationName - имя в pg_stat_activity и логах postgres
+func ApplicationName(name string) Option {
+	return func(c *Postgres) {
+		c.applicationName = name
+	}
+}
+
+// TLS - sslmode и пути к сертификатам, пустые значения берутся из строки подключения
+func TLS(mode, rootCert, cert, key string) Option {
+	return func(c *Postgres) {
+		c.tls = map[string]string{
+			"sslmode":     mode,
+			"sslrootcert": rootCert,
+			"sslcert":     cert,
+			"sslkey":      key,
+		}
+	}
+}
+
+// Tracing - спан на каждый запрос с очищенным от литералов SQL, родителем служит спан из контекста запроса
+func Tracing() Option {
+	return QueryHooks(newQueryTracer())
+}
+
+// SlowQueryThreshold - запросы дольше порога пишутся в лог предупреждением
+func SlowQueryThreshold(threshold time.Duration) Option {
+	return QueryHooks(slowQueryLogger(threshold))
+}
+
+// QueryHooks - хуки после каждого запроса, добавляются к уже заданным
+func QueryHooks(hooks ...QueryHook) Option {
+	return func(c *Postgres) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
+
+// Replicas - реплики для чтений через Reader, записи и транзакции всегда идут в primary
+func Replicas(urls ...string) Option {
+	return func(c *Postgres) {
+		c.replicaURLs = urls
+	}
+}
+
+// ReplicaCheckPeriod - как часто проверяются реплики, это же время ожидает ответа проверка
+func ReplicaCheckPeriod(period time.Duration) Option {
+	return func(c *Postgres) {
+		c.replicaCheckPeriod = period
+	}
+}
+
+// StickyWindow - сколько после записи чтения с тем же ключом идут в primary, должно покрывать отставание реплик
+func StickyWindow(window time.Duration) Option {
+	return func(c *Postgres) {
+		c.stickyWindow = window
+	}
+}
+
+// StickyKey - ключ, по которому запоминается время записи, обычно id пользователя из контекста запроса.
+// Пустой ключ не привязывает чтения к записям, без StickyKey чтения идут в реплики сразу после записи
+func StickyKey(key func(ctx context.Context) string) Option {
+	return func(c *Postgres) {
+		c.stickyKey = key
+	}
+}
+
+type TxOption func(*TxManager)
+
+// TxMaxRetries - число повторов транзакции после serialization failure или deadlock
+func TxMaxRetries(retries int) TxOption {
+	return func(m *TxManager) {
+		m.maxRetries = retries
+	}
+}
+
+// TxRetryDelay - задержка перед первым повтором, дальше она удваивается
+func TxRetryDelay(delay time.Duration) TxOption {
+	return func(m *TxManager) {
+		m.retryDelay = delay
+	}
+}
@@ -1,11 +1,17 @@
 package postgres
 
 import (
+	"blog-backend/pkg/backoff"
 	"context"
 	"fmt"
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
-	"log"
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,22 +19,47 @@ const (
 	defaultMaxPoolSize  = 1
 	defaultConnAttempts = 10
 	defaultConnTimeout  = time.Second
+	defaultConnMaxDelay = 10 * time.Second
 )
 
 type Postgres struct {
-	maxPoolSize  int
-	connAttempts int
-	connTimeout  time.Duration
+	maxPoolSize        int
+	minConns           int
+	maxConnLifetime    time.Duration
+	maxConnIdleTime    time.Duration
+	statementTimeout   time.Duration
+	applicationName    string
+	tls                map[string]string
+	connAttempts       int
+	connTimeout        time.Duration
+	connMaxDelay       time.Duration
+	hooks              []QueryHook
+	replicaURLs        []string
+	replicaCheckPeriod time.Duration
+	stickyWindow       time.Duration
+	stickyKey          func(ctx context.Context) string
 
 	Builder squirrel.StatementBuilderType
-	Pool    *pgxpool.Pool
+	// Pool - primary, все записи и чтения в транзакциях идут в него
+	Pool *pgxpool.Pool
+
+	primary     *Node
+	replicas    []*Node
+	nextReplica atomic.Uint32
+	sticky      *stickiness
+
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 func New(url string, opts ...Option) (*Postgres, error) {
 	pg := &Postgres{
-		maxPoolSize:  defaultMaxPoolSize,
-		connAttempts: defaultConnAttempts,
-		connTimeout:  defaultConnTimeout,
+		maxPoolSize:        defaultMaxPoolSize,
+		connAttempts:       defaultConnAttempts,
+		connTimeout:        defaultConnTimeout,
+		connMaxDelay:       defaultConnMaxDelay,
+		replicaCheckPeriod: defaultReplicaCheckPeriod,
+		stickyWindow:       defaultStickyWindow,
 	}
 
 	for _, opt := range opts {
@@ -37,34 +68,134 @@ func New(url string, opts ...Option) (*Postgres, error) {
 
 	pg.Builder = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
-	poolConfig, err := pgxpool.ParseConfig(url)
+	poolConfig, err := pg.poolConfig(url)
 	if err != nil {
-		return nil, fmt.Errorf("pgdb - New - pgxpool.ParseConfig: %w", err)
+		return nil, fmt.Errorf("pgdb - New - pg.poolConfig: %w", err)
 	}
 
-	poolConfig.MaxConns = int32(pg.maxPoolSize)
-
-	for pg.connAttempts > 0 {
+	// the database may still be starting together with the app, attempts are spread to not hammer it
+	for attempt := 1; ; attempt++ {
 		pg.Pool, err = pgxpool.ConnectConfig(context.Background(), poolConfig)
-		if err == nil {
+		if err == nil || attempt >= pg.connAttempts {
 			break
 		}
 
-		log.Printf("Postgres is trying to connect, attempts left: %d", pg.connAttempts)
+		delay := backoff.Exponential(attempt, pg.connTimeout, pg.connMaxDelay)
+		log.Warnf("Postgres is trying to connect, attempts left: %d, next in %s: %v", pg.connAttempts-attempt, delay.Round(time.Millisecond), err)
 
-		time.Sleep(pg.connTimeout)
-
-		pg.connAttempts--
+		time.Sleep(delay)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("pgdb - New - pgxpool.ConnectConfig: %w", err)
 	}
 
+	pg.primary = &Node{Name: primaryNode, Pool: pg.Pool}
+	pg.primary.healthy.Store(true)
+
+	if len(pg.replicaURLs) > 0 && pg.stickyKey != nil {
+		pg.sticky = newStickiness(pg.stickyWindow, pg.stickyKey)
+	}
+
+	err = pg.connectReplicas()
+	if err != nil {
+		pg.Close()
+		return nil, fmt.Errorf("pgdb - New - pg.connectReplicas: %w", err)
+	}
+
 	return pg, nil
 }
 
+// poolConfig - одни настройки для primary и реплик, параметры TLS из опций заменяют параметры из строки подключения
+func (p *Postgres) poolConfig(connString string) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(withParams(connString, p.tls))
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = int32(p.maxPoolSize)
+	if p.minConns > 0 {
+		poolConfig.MinConns = int32(p.minConns)
+	}
+	if p.maxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = p.maxConnLifetime
+	}
+	if p.maxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = p.maxConnIdleTime
+	}
+
+	params := poolConfig.ConnConfig.RuntimeParams
+	if p.statementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(p.statementTimeout.Milliseconds(), 10)
+	}
+	if p.applicationName != "" {
+		params["application_name"] = p.applicationName
+	}
+
+	if len(p.hooks) > 0 {
+		poolConfig.ConnConfig.Logger = queryHooks(p.hooks)
+		poolConfig.ConnConfig.LogLevel = pgx.LogLevelInfo
+	}
+
+	return poolConfig, nil
+}
+
+// ConnString - строка подключения с параметрами TLS из опций, как у пулов New. Нужна клиентам
+// с собственным соединением, например migrate, остальные опции на строку не влияют
+func ConnString(connString string, opts ...Option) string {
+	p := &Postgres{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return withParams(connString, p.tls)
+}
+
+// withParams - параметры в строке подключения в формате URL или key=value, пустые значения пропускаются
+func withParams(connString string, params map[string]string) string {
+	if len(params) == 0 {
+		return connString
+	}
+
+	if strings.HasPrefix(connString, "postgres://") || strings.HasPrefix(connString, "postgresql://") {
+		u, err := url.Parse(connString)
+		if err != nil {
+			// pgx reports the malformed url itself
+			return connString
+		}
+
+		query := u.Query()
+		for key, value := range params {
+			if value != "" {
+				query.Set(key, value)
+			}
+		}
+		u.RawQuery = query.Encode()
+
+		return u.String()
+	}
+
+	// the last value of a repeated key wins
+	escaper := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	for key, value := range params {
+		if value != "" {
+			connString += fmt.Sprintf(" %s='%s'", key, escaper.Replace(value))
+		}
+	}
+
+	return connString
+}
+
 func (p *Postgres) Close() {
+	if p.cancel != nil {
+		p.cancel()
+		<-p.done
+	}
+
+	for _, node := range p.replicas {
+		node.Pool.Close()
+	}
+
 	if p.Pool != nil {
 		p.Pool.Close()
 	}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolConfig(t *testing.T) {
+	pg := &Postgres{
+		maxPoolSize:      5,
+		minConns:         2,
+		maxConnLifetime:  time.Hour,
+		statementTimeout: 1500 * time.Millisecond,
+		applicationName:  "blog",
+		tls:              map[string]string{"sslmode": "disable", "sslrootcert": ""},
+		hooks:            []QueryHook{slowQueryLogger(time.Second)},
+	}
+
+	config, err := pg.poolConfig("postgres://user@127.0.0.1:1/blog?sslmode=require")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.MaxConns != 5 || config.MinConns != 2 || config.MaxConnLifetime != time.Hour {
+		t.Errorf("unexpected pool settings: max %d, min %d, lifetime %s", config.MaxConns, config.MinConns, config.MaxConnLifetime)
+	}
+	// the default is kept when the option isn't set
+	if config.MaxConnIdleTime != 30*time.Minute {
+		t.Errorf("max conn idle time = %s, want the pgx default", config.MaxConnIdleTime)
+	}
+
+	params := config.ConnConfig.RuntimeParams
+	if params["statement_timeout"] != "1500" || params["application_name"] != "blog" {
+		t.Errorf("unexpected runtime params: %v", params)
+	}
+	// sslmode=disable leaves no TLS config, require from the url would set one
+	if config.ConnConfig.TLSConfig != nil {
+		t.Error("sslmode from the options must override the url")
+	}
+	if config.ConnConfig.Logger == nil {
+		t.Error("query hooks are not installed")
+	}
+}
+
+func TestWithParams(t *testing.T) {
+	tests := []struct {
+		connString string
+		params     map[string]string
+		want       string
+	}{
+		{
+			connString: "postgres://user@db:5432/blog?sslmode=disable",
+			params:     map[string]string{"sslmode": "verify-full", "sslcert": ""},
+			want:       "postgres://user@db:5432/blog?sslmode=verify-full",
+		},
+		{
+			connString: "host=db dbname=blog",
+			params:     map[string]string{"sslrootcert": `/etc/it's\ca.pem`},
+			want:       `host=db dbname=blog sslrootcert='/etc/it\'s\\ca.pem'`,
+		},
+		{
+			connString: "postgres://user@db/blog",
+			params:     nil,
+			want:       "postgres://user@db/blog",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := withParams(tt.connString, tt.params); got != tt.want {
+			t.Errorf("withParams(%q) = %q, want %q", tt.connString, got, tt.want)
+		}
+	}
+}
+
+func TestConnString(t *testing.T) {
+	got := ConnString("postgres://user@db/blog?sslmode=disable",
+		ApplicationName("migrate"), TLS("verify-ca", "/etc/ssl/ca.pem", "", ""))
+	want := "postgres://user@db/blog?sslmode=verify-ca&sslrootcert=%2Fetc%2Fssl%2Fca.pem"
+	if got != want {
+		t.Errorf("ConnString() = %q, want %q", got, want)
+	}
+}
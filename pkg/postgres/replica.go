@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	log "github.com/sirupsen/logrus"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultStickyWindow       = 5 * time.Second
+	defaultReplicaCheckPeriod = 5 * time.Second
+
+	primaryNode = "primary"
+)
+
+// Node - узел postgres со своим пулом. Реплика без ответа на проверку выводится из ротации до следующей удачной
+type Node struct {
+	Name string
+	Pool *pgxpool.Pool
+
+	healthy atomic.Bool
+}
+
+func (n *Node) Healthy() bool {
+	return n.healthy.Load()
+}
+
+// Nodes - primary и реплики, например для метрик пулов по узлам
+func (p *Postgres) Nodes() []*Node {
+	return append([]*Node{p.primary}, p.replicas...)
+}
+
+type primaryKey struct{}
+
+// WithPrimary - чтения с этим контекстом идут в primary. Нужно тем, кто запоминает прочитанное,
+// например кешу: значение с отстающей реплики осталось бы в нем на весь ttl
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Reader - запросы только на чтение идут в здоровую реплику по кругу, без реплик - в primary.
+// В транзакции читается транзакция, а в течение окна после записи с тем же ключом из StickyKey
+// и с контекстом WithPrimary - primary, чтобы пользователь видел свои записи
+func (p *Postgres) Reader(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return p.Pool
+	}
+
+	if len(p.replicas) == 0 || p.sticky.recent(ctx) {
+		return p.Pool
+	}
+
+	healthy := make([]*Node, 0, len(p.replicas))
+	for _, node := range p.replicas {
+		if node.Healthy() {
+			healthy = append(healthy, node)
+		}
+	}
+	if len(healthy) == 0 {
+		return p.Pool
+	}
+
+	return healthy[p.nextReplica.Add(1)%uint32(len(healthy))].Pool
+}
+
+func (p *Postgres) connectReplicas() error {
+	for i, url := range p.replicaURLs {
+		poolConfig, err := p.poolConfig(url)
+		if err != nil {
+			return fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		// a replica that is down at start joins the rotation after a successful check
+		poolConfig.LazyConnect = true
+
+		pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
+		if err != nil {
+			return fmt.Errorf("replica %d: %w", i+1, err)
+		}
+
+		p.replicas = append(p.replicas, &Node{Name: fmt.Sprintf("replica-%d", i+1), Pool: pool})
+	}
+
+	if len(p.replicas) == 0 {
+		return nil
+	}
+
+	p.checkReplicas()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.runReplicaChecks(ctx)
+
+	return nil
+}
+
+func (p *Postgres) runReplicaChecks(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.replicaCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkReplicas()
+		}
+	}
+}
+
+// checkReplicas - реплика здорова, если отвечает в пределах периода проверки и находится в режиме восстановления.
+// Реплика, повышенная до primary, из ротации выводится: ее записи расходятся с настоящим primary
+func (p *Postgres) checkReplicas() {
+	var wg sync.WaitGroup
+	for _, node := range p.replicas {
+		wg.Add(1)
+		go func(node *Node) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), p.replicaCheckPeriod)
+			defer cancel()
+
+			var inRecovery bool
+			err := node.Pool.QueryRow(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery)
+			healthy := err == nil && inRecovery
+
+			if node.healthy.Swap(healthy) != healthy {
+				switch {
+				case healthy:
+					log.Infof("Postgres %s is back in rotation", node.Name)
+				case err != nil:
+					log.Warnf("Postgres %s is out of rotation: %v", node.Name, err)
+				default:
+					log.Warnf("Postgres %s is out of rotation: not in recovery", node.Name)
+				}
+			}
+		}(node)
+	}
+	wg.Wait()
+}
+
+// stickiness - время последней записи по ключу хранится в памяти процесса.
+// При нескольких инстансах окно действует, только если запросы пользователя попадают на один инстанс
+type stickiness struct {
+	window time.Duration
+	key    func(ctx context.Context) string
+
+	mu      sync.Mutex
+	writes  map[string]time.Time
+	sweptAt time.Time
+}
+
+func newStickiness(window time.Duration, key func(ctx context.Context) string) *stickiness {
+	return &stickiness{window: window, key: key, writes: make(map[string]time.Time)}
+}
+
+func (s *stickiness) touch(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	key := s.key(ctx)
+	if key == "" {
+		return
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.writes[key] = now
+
+	// expired keys are dropped at most once per window
+	if now.Sub(s.sweptAt) > s.window {
+		for k, at := range s.writes {
+			if now.Sub(at) > s.window {
+				delete(s.writes, k)
+			}
+		}
+		s.sweptAt = now
+	}
+}
+
+func (s *stickiness) recent(ctx context.Context) bool {
+	if s == nil {
+		return false
+	}
+	key := s.key(ctx)
+	if key == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	at, ok := s.writes[key]
+	s.mu.Unlock()
+
+	return ok && time.Since(at) <= s.window
+}
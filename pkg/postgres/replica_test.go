@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"testing"
+	"time"
+)
+
+type testKey struct{}
+
+// lazyPool - пул без соединений, запросы в нем не выполняются, сравниваются только сами пулы
+func lazyPool(t *testing.T, name string) *pgxpool.Pool {
+	t.Helper()
+
+	config, err := pgxpool.ParseConfig("postgres://user@127.0.0.1:1/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.LazyConnect = true
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func newTestPostgres(t *testing.T, window time.Duration, replicas ...bool) *Postgres {
+	t.Helper()
+
+	pg := &Postgres{Pool: lazyPool(t, "primary")}
+	for i, healthy := range replicas {
+		node := &Node{Name: "replica", Pool: lazyPool(t, "replica"+string(rune('a'+i)))}
+		node.healthy.Store(healthy)
+		pg.replicas = append(pg.replicas, node)
+	}
+	pg.sticky = newStickiness(window, func(ctx context.Context) string {
+		key, _ := ctx.Value(testKey{}).(string)
+		return key
+	})
+
+	return pg
+}
+
+func TestReader_Replicas(t *testing.T) {
+	ctx := context.Background()
+
+	pg := newTestPostgres(t, time.Minute)
+	if pg.Reader(ctx) != pg.Pool {
+		t.Error("without replicas reads must go to the primary")
+	}
+
+	pg = newTestPostgres(t, time.Minute, true, false, true)
+	seen := make(map[Querier]int)
+	for i := 0; i < 6; i++ {
+		seen[pg.Reader(ctx)]++
+	}
+	if seen[pg.replicas[0].Pool] != 3 || seen[pg.replicas[2].Pool] != 3 {
+		t.Errorf("reads are not spread over healthy replicas: %v", seen)
+	}
+	if seen[pg.replicas[1].Pool] != 0 || seen[pg.Pool] != 0 {
+		t.Error("reads went to an unhealthy replica or the primary")
+	}
+
+	pg = newTestPostgres(t, time.Minute, false, false)
+	if pg.Reader(ctx) != pg.Pool {
+		t.Error("without healthy replicas reads must go to the primary")
+	}
+}
+
+func TestReader_Sticky(t *testing.T) {
+	alice := context.WithValue(context.Background(), testKey{}, "alice")
+	bob := context.WithValue(context.Background(), testKey{}, "bob")
+
+	pg := newTestPostgres(t, 50*time.Millisecond, true)
+	replica := pg.replicas[0].Pool
+
+	if pg.Reader(alice) != replica {
+		t.Error("reads before any write must go to the replica")
+	}
+
+	pg.DB(alice)
+
+	if pg.Reader(alice) != pg.Pool {
+		t.Error("reads right after a write must go to the primary")
+	}
+	if pg.Reader(bob) != replica {
+		t.Error("writes of one key must not pin reads of another")
+	}
+	if pg.Reader(context.Background()) != replica {
+		t.Error("reads without a key must go to the replica")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if pg.Reader(alice) != replica {
+		t.Error("reads after the window must go to the replica")
+	}
+}
+
+func TestReader_WithPrimary(t *testing.T) {
+	pg := newTestPostgres(t, time.Minute, true)
+
+	if pg.Reader(WithPrimary(context.Background())) != pg.Pool {
+		t.Error("reads with WithPrimary must go to the primary")
+	}
+	if pg.Reader(context.Background()) != pg.replicas[0].Pool {
+		t.Error("other reads must still go to the replica")
+	}
+}
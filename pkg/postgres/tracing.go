@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"strings"
+)
+
+const tracerName = "blog-backend/pkg/postgres"
+
+// placeholders are kept, string and numeric literals are replaced
+var sqlLiteral = regexp.MustCompile(`\$\d+|'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+
+// queryTracer - спан на каждый запрос, строится задним числом по длительности из Query
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func newQueryTracer() *queryTracer {
+	return &queryTracer{tracer: otel.Tracer(tracerName)}
+}
+
+func (t *queryTracer) AfterQuery(ctx context.Context, q Query) {
+	// background jobs poll the database all the time, their queries are traced only inside their own spans
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+
+	attrs := []attribute.KeyValue{semconv.DBSystemPostgreSQL}
+	if q.SQL != "" {
+		attrs = append(attrs, semconv.DBStatement(SanitizeSQL(q.SQL)), semconv.DBOperation(operation(q.SQL)))
+	}
+
+	_, span := t.tracer.Start(ctx, "pgx."+q.Op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(q.End.Add(-q.Duration)),
+		trace.WithAttributes(attrs...),
+	)
+
+	if err := q.Err; err != nil {
+		// postgres messages may quote row values, only the SQLSTATE is recorded for them
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			span.SetAttributes(attribute.String("db.postgresql.sqlstate", pgErr.Code))
+			span.SetStatus(codes.Error, "SQLSTATE "+pgErr.Code)
+		} else {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	span.End(trace.WithTimestamp(q.End))
+}
+
+// SanitizeSQL - текст запроса без литералов, значения из запроса не должны попадать в трассировку
+func SanitizeSQL(sql string) string {
+	sql = sqlLiteral.ReplaceAllStringFunc(sql, func(s string) string {
+		if strings.HasPrefix(s, "$") {
+			return s
+		}
+		return "?"
+	})
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+func operation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
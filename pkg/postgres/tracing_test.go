@@ -0,0 +1,37 @@
+package postgres
+
+import "testing"
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  "SELECT id, name FROM users WHERE username = $1 AND deleted_at IS NULL",
+			want: "SELECT id, name FROM users WHERE username = $1 AND deleted_at IS NULL",
+		},
+		{
+			sql:  "UPDATE users SET password = 'secret', role = 'admin' WHERE id = $1",
+			want: "UPDATE users SET password = ?, role = ? WHERE id = $1",
+		},
+		{
+			sql:  "SELECT * FROM articles LIMIT 10 OFFSET 20",
+			want: "SELECT * FROM articles LIMIT ? OFFSET ?",
+		},
+		{
+			sql:  "SELECT 'it''s' , 3.14 FROM t1",
+			want: "SELECT ? , ? FROM t1",
+		},
+		{
+			sql:  "DELETE FROM outbox\n\tWHERE created_at < now() - interval '7 days'",
+			want: "DELETE FROM outbox WHERE created_at < now() - interval ?",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeSQL(tt.sql); got != tt.want {
+			t.Errorf("SanitizeSQL(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
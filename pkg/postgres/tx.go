@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"blog-backend/pkg/backoff"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTxMaxRetries = 3
+	defaultTxRetryDelay = 10 * time.Millisecond
+	maxTxRetryDelay     = time.Second
+
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+type (
+	txKey          struct{}
+	afterCommitKey struct{}
+)
+
+// Querier - общие методы пула и транзакции, репозитории выполняют запросы через DB и не знают, в транзакции ли они
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// DB - транзакция из контекста, если ее открыл TxManager, иначе пул primary.
+// Вызов считается записью: следующие чтения с тем же ключом из StickyKey идут в primary, см. Reader
+func (p *Postgres) DB(ctx context.Context) Querier {
+	p.sticky.touch(ctx)
+
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return p.Pool
+}
+
+// InTx - выполняется ли вызов в транзакции TxManager. Кеши не должны запоминать то,
+// что прочитано в транзакции: она может откатиться
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return ok
+}
+
+// afterCommit - функции, отложенные до фиксации внешней транзакции TxManager
+type afterCommit struct {
+	mu  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
+// AfterCommit - fn выполняется после фиксации внешней транзакции TxManager, вне транзакции сразу.
+// При откате fn не выполняется. Так кеши сбрасывают ключи, когда изменения уже видны другим запросам:
+// сброс до фиксации позволил бы им заново закешировать старое значение.
+// Функции из точки сохранения, откатившейся внутри зафиксированной транзакции, тоже выполняются
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	callbacks, ok := ctx.Value(afterCommitKey{}).(*afterCommit)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	callbacks.mu.Lock()
+	callbacks.fns = append(callbacks.fns, fn)
+	callbacks.mu.Unlock()
+}
+
+// Begin - собственная транзакция репозитория. Внутри транзакции из контекста это точка сохранения,
+// поэтому Commit и Rollback репозитория не завершают внешнюю транзакцию
+func (p *Postgres) Begin(ctx context.Context) (pgx.Tx, error) {
+	return p.DB(ctx).Begin(ctx)
+}
+
+// TxManager - выполнение нескольких вызовов репозиториев в одной транзакции
+type TxManager struct {
+	pg         *Postgres
+	maxRetries int
+	retryDelay time.Duration
+}
+
+func NewTxManager(pg *Postgres, opts ...TxOption) *TxManager {
+	m := &TxManager{
+		pg:         pg,
+		maxRetries: defaultTxMaxRetries,
+		retryDelay: defaultTxRetryDelay,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Do - fn выполняется в транзакции с уровнем изоляции по умолчанию, см. DoTx
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.DoTx(ctx, pgx.TxOptions{}, fn)
+}
+
+// DoTx - транзакция кладется в контекст fn и фиксируется, если fn вернула nil. При ошибке или панике откатывается.
+// Вложенный вызов открывает точку сохранения, его ошибка откатывает только его изменения, opts при этом не учитываются.
+// Внешняя транзакция повторяется целиком при serialization failure и deadlock, поэтому fn должна
+// возвращать ошибки репозиториев обернутыми и не иметь побочных эффектов вне базы
+func (m *TxManager) DoTx(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("TxManager.DoTx - tx.Begin: %w", err)
+		}
+		return runInTx(ctx, savepoint, fn)
+	}
+
+	m.pg.sticky.touch(ctx)
+
+	for attempt := 0; ; attempt++ {
+		tx, err := m.pg.Pool.BeginTx(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("TxManager.DoTx - m.pg.Pool.BeginTx: %w", err)
+		}
+
+		err = runOuterTx(ctx, tx, fn)
+		if err == nil || !isRetryable(err) || attempt >= m.maxRetries {
+			return err
+		}
+
+		// jitter keeps the conflicting transactions from colliding again
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Exponential(attempt+1, m.retryDelay, maxTxRetryDelay)):
+		}
+	}
+}
+
+// runOuterTx - функции AfterCommit выполняются только после успешной фиксации, у каждой попытки свой список
+func runOuterTx(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context) error) error {
+	callbacks := &afterCommit{}
+
+	err := runInTx(context.WithValue(ctx, afterCommitKey{}, callbacks), tx, fn)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range callbacks.fns {
+		fn(ctx)
+	}
+	return nil
+}
+
+func runInTx(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+	}
+	return false
+}
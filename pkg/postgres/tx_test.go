@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"testing"
+)
+
+// fakeTx - транзакция без базы, запоминает только фиксацию
+type fakeTx struct {
+	pgx.Tx
+	commitErr error
+	committed bool
+}
+
+func (tx *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = tx.commitErr == nil
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func TestAfterCommit(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		fnErr     error
+		commitErr error
+		want      []string
+	}{
+		{name: "commit", want: []string{"outer", "savepoint"}},
+		{name: "rollback", fnErr: errFailed},
+		{name: "commit failed", commitErr: errFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{commitErr: tt.commitErr}
+
+			var got []string
+			record := func(name string) func(ctx context.Context) {
+				return func(ctx context.Context) {
+					if InTx(ctx) {
+						t.Errorf("%s callback runs in the transaction", name)
+					}
+					if !tx.committed {
+						t.Errorf("%s callback runs before commit", name)
+					}
+					got = append(got, name)
+				}
+			}
+
+			_ = runOuterTx(context.Background(), tx, func(ctx context.Context) error {
+				AfterCommit(ctx, record("outer"))
+
+				savepoint, _ := ctx.Value(txKey{}).(pgx.Tx).Begin(ctx)
+				_ = runInTx(ctx, savepoint, func(ctx context.Context) error {
+					AfterCommit(ctx, record("savepoint"))
+					return nil
+				})
+
+				if len(got) != 0 {
+					t.Error("callbacks run before the transaction ends")
+				}
+				return tt.fnErr
+			})
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("callbacks = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("callbacks = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAfterCommit_WithoutTx(t *testing.T) {
+	called := false
+	AfterCommit(context.Background(), func(ctx context.Context) {
+		called = true
+	})
+
+	if !called {
+		t.Error("callback outside a transaction must run immediately")
+	}
+}
@@ -0,0 +1,74 @@
+//go:build integration
+
+package pubsub_test
+
+import (
+	"blog-backend/internal/testutil/pgtest"
+	"blog-backend/pkg/pubsub"
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Main(m))
+}
+
+// publishUntilReceived - LISTEN начинает действовать не сразу, поэтому сообщение повторяется до получения
+func publishUntilReceived(t *testing.T, p *pubsub.PubSub, sub *pubsub.Subscription) {
+	t.Helper()
+
+	deadline := time.After(10 * time.Second)
+	for {
+		err := p.Publish(context.Background(), pubsub.Message{Topic: "articles", Type: "comment"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case _, ok := <-sub.Messages():
+			if !ok {
+				t.Fatalf("subscription is closed: %v", sub.Err())
+			}
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no message through postgres in 10 seconds")
+		}
+	}
+}
+
+func TestPubSub_Relisten(t *testing.T) {
+	pg := pgtest.New(t)
+
+	p := pubsub.New(pg.Pool, pubsub.Channel("test_events"), pubsub.ReconnectTimeout(50*time.Millisecond))
+	defer p.Close()
+
+	sub, err := p.Subscribe("articles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publishUntilReceived(t, p, sub)
+
+	// the listener connection is lost, e.g. on a postgres restart or failover
+	var terminated int
+	err = pg.Pool.QueryRow(context.Background(),
+		`SELECT count(pg_terminate_backend(pid)) FROM pg_stat_activity
+		WHERE datname = current_database() AND query LIKE 'LISTEN %' AND pid <> pg_backend_pid()`,
+	).Scan(&terminated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if terminated != 1 {
+		t.Fatalf("terminated %d listener connections, want 1", terminated)
+	}
+
+	// drain messages published before the connection was lost
+	for len(sub.Messages()) > 0 {
+		<-sub.Messages()
+	}
+
+	publishUntilReceived(t, p, sub)
+}
@@ -0,0 +1,23 @@
+package pubsub
+
+import "time"
+
+type Option func(*PubSub)
+
+func Channel(channel string) Option {
+	return func(p *PubSub) {
+		p.channel = channel
+	}
+}
+
+func BufferSize(size int) Option {
+	return func(p *PubSub) {
+		p.bufferSize = size
+	}
+}
+
+func ReconnectTimeout(timeout time.Duration) Option {
+	return func(p *PubSub) {
+		p.reconnectTimeout = timeout
+	}
+}
@@ -0,0 +1,306 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	log "github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+const (
+	defaultChannel          = "blog_events"
+	defaultBufferSize       = 64
+	defaultReconnectTimeout = time.Second
+
+	// postgres rejects NOTIFY payloads of 8000 bytes and longer
+	maxPayloadSize = 7999
+)
+
+var (
+	ErrPayloadTooLarge = errors.New("payload too large")
+	ErrSlowConsumer    = errors.New("subscriber is too slow")
+	ErrClosed          = errors.New("pubsub is closed")
+)
+
+type Message struct {
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// PubSub - fan-out of messages between app instances through postgres LISTEN/NOTIFY.
+// Every instance listens on the same channel on a dedicated connection, messages
+// are published only through postgres and delivered to local subscribers of their topic.
+// Without a pool messages are delivered to local subscribers only
+type PubSub struct {
+	pool             *pgxpool.Pool
+	channel          string
+	bufferSize       int
+	reconnectTimeout time.Duration
+
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(pool *pgxpool.Pool, opts ...Option) *PubSub {
+	p := &PubSub{
+		pool:             pool,
+		channel:          defaultChannel,
+		bufferSize:       defaultBufferSize,
+		reconnectTimeout: defaultReconnectTimeout,
+		topics:           make(map[string]map[*Subscription]struct{}),
+		done:             make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	if pool == nil {
+		close(p.done)
+		return p
+	}
+
+	go p.listen(ctx)
+
+	return p
+}
+
+// Publish - отправка сообщения всем подписчикам топика во всех экземплярах приложения
+func (p *PubSub) Publish(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("PubSub.Publish - json.Marshal: %v", err)
+	}
+
+	if len(payload) > maxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+
+	if p.pool == nil {
+		p.dispatch(msg)
+		return nil
+	}
+
+	_, err = p.pool.Exec(ctx, "SELECT pg_notify($1, $2)", p.channel, string(payload))
+	if err != nil {
+		return fmt.Errorf("PubSub.Publish - p.pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// Subscribe - создание подписки на топики, сообщения приходят в Subscription.Messages
+func (p *PubSub) Subscribe(topics ...string) (*Subscription, error) {
+	s := &Subscription{
+		pubSub:   p,
+		topics:   make(map[string]struct{}),
+		messages: make(chan Message, p.bufferSize),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	for _, topic := range topics {
+		p.addLocked(s, topic)
+	}
+
+	return s, nil
+}
+
+// Close - остановка listener'а и закрытие всех подписок
+func (p *PubSub) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+
+	var subs []*Subscription
+	for _, topicSubs := range p.topics {
+		for s := range topicSubs {
+			subs = append(subs, s)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, s := range subs {
+		s.closeWithErr(ErrClosed)
+	}
+
+	p.cancel()
+	<-p.done
+}
+
+func (p *PubSub) listen(ctx context.Context) {
+	defer close(p.done)
+
+	for {
+		err := p.listenConn(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Errorf("PubSub.listen - p.listenConn: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.reconnectTimeout):
+		}
+	}
+}
+
+func (p *PubSub) listenConn(ctx context.Context) error {
+	poolConn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("p.pool.Acquire: %v", err)
+	}
+
+	// connection in LISTEN state must not return to the pool
+	conn := poolConn.Hijack()
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{p.channel}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("conn.Exec: %v", err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("conn.WaitForNotification: %v", err)
+		}
+
+		var msg Message
+		err = json.Unmarshal([]byte(notification.Payload), &msg)
+		if err != nil {
+			log.Errorf("PubSub.listenConn - json.Unmarshal: %v", err)
+			continue
+		}
+
+		p.dispatch(msg)
+	}
+}
+
+func (p *PubSub) dispatch(msg Message) {
+	var slow []*Subscription
+
+	p.mu.RLock()
+	for s := range p.topics[msg.Topic] {
+		select {
+		case s.messages <- msg:
+		default:
+			slow = append(slow, s)
+		}
+	}
+	p.mu.RUnlock()
+
+	// a subscriber that can't keep up is dropped instead of blocking the others,
+	// the client is expected to reconnect and refetch missed data
+	for _, s := range slow {
+		s.closeWithErr(ErrSlowConsumer)
+	}
+}
+
+func (p *PubSub) addLocked(s *Subscription, topic string) {
+	if _, ok := p.topics[topic]; !ok {
+		p.topics[topic] = make(map[*Subscription]struct{})
+	}
+	p.topics[topic][s] = struct{}{}
+	s.topics[topic] = struct{}{}
+}
+
+func (p *PubSub) removeLocked(s *Subscription, topic string) {
+	delete(p.topics[topic], s)
+	if len(p.topics[topic]) == 0 {
+		delete(p.topics, topic)
+	}
+	delete(s.topics, topic)
+}
+
+type Subscription struct {
+	pubSub   *PubSub
+	topics   map[string]struct{}
+	messages chan Message
+
+	once   sync.Once
+	closed bool
+	err    error
+}
+
+// Messages - канал входящих сообщений, закрывается вместе с подпиской
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Err - причина закрытия подписки, nil если подписка закрыта через Close
+func (s *Subscription) Err() error {
+	s.pubSub.mu.RLock()
+	defer s.pubSub.mu.RUnlock()
+
+	return s.err
+}
+
+func (s *Subscription) Subscribe(topic string) error {
+	s.pubSub.mu.Lock()
+	defer s.pubSub.mu.Unlock()
+
+	if s.closed {
+		return s.closedErr()
+	}
+
+	s.pubSub.addLocked(s, topic)
+
+	return nil
+}
+
+func (s *Subscription) closedErr() error {
+	if s.err != nil {
+		return s.err
+	}
+	return ErrClosed
+}
+
+func (s *Subscription) Unsubscribe(topic string) {
+	s.pubSub.mu.Lock()
+	defer s.pubSub.mu.Unlock()
+
+	s.pubSub.removeLocked(s, topic)
+}
+
+func (s *Subscription) Close() {
+	s.closeWithErr(nil)
+}
+
+func (s *Subscription) closeWithErr(err error) {
+	s.once.Do(func() {
+		s.pubSub.mu.Lock()
+		defer s.pubSub.mu.Unlock()
+
+		for topic := range s.topics {
+			s.pubSub.removeLocked(s, topic)
+		}
+		s.closed = true
+		s.err = err
+
+		close(s.messages)
+	})
+}
@@ -0,0 +1,125 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, s *Subscription) (Message, bool) {
+	t.Helper()
+
+	select {
+	case msg, ok := <-s.Messages():
+		return msg, ok
+	case <-time.After(time.Second):
+		t.Fatal("no message in a second")
+		return Message{}, false
+	}
+}
+
+func TestPubSub_Local(t *testing.T) {
+	p := New(nil)
+	defer p.Close()
+
+	sub, err := p.Subscribe("articles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := p.Subscribe("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.Publish(context.Background(), Message{Topic: "articles", Type: "comment", Data: json.RawMessage(`{"id":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, ok := receive(t, sub)
+	if !ok || msg.Type != "comment" || string(msg.Data) != `{"id":1}` {
+		t.Errorf("message = %+v, %v", msg, ok)
+	}
+	if len(other.Messages()) != 0 {
+		t.Error("message is delivered to a subscriber of another topic")
+	}
+
+	sub.Unsubscribe("articles")
+	_ = p.Publish(context.Background(), Message{Topic: "articles", Type: "comment"})
+	if len(sub.Messages()) != 0 {
+		t.Error("message is delivered after Unsubscribe")
+	}
+}
+
+func TestPubSub_PayloadTooLarge(t *testing.T) {
+	p := New(nil)
+	defer p.Close()
+
+	data, _ := json.Marshal(string(make([]byte, maxPayloadSize)))
+	err := p.Publish(context.Background(), Message{Topic: "articles", Data: data})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("err = %v, want %v", err, ErrPayloadTooLarge)
+	}
+}
+
+func TestPubSub_SlowConsumer(t *testing.T) {
+	p := New(nil, BufferSize(1))
+	defer p.Close()
+
+	slow, _ := p.Subscribe("articles")
+	fast, _ := p.Subscribe("articles")
+
+	for i := 0; i < 2; i++ {
+		_ = p.Publish(context.Background(), Message{Topic: "articles", Type: "comment"})
+		// fast keeps its buffer empty, slow doesn't read at all
+		if _, ok := receive(t, fast); !ok {
+			t.Fatal("fast subscriber is closed")
+		}
+	}
+
+	if _, ok := receive(t, slow); !ok {
+		t.Fatal("buffered message is lost")
+	}
+	if _, ok := receive(t, slow); ok {
+		t.Fatal("slow subscriber is not dropped")
+	}
+	if !errors.Is(slow.Err(), ErrSlowConsumer) {
+		t.Errorf("Err() = %v, want %v", slow.Err(), ErrSlowConsumer)
+	}
+	if err := slow.Subscribe("users"); !errors.Is(err, ErrSlowConsumer) {
+		t.Errorf("Subscribe after drop = %v, want %v", err, ErrSlowConsumer)
+	}
+
+	_ = p.Publish(context.Background(), Message{Topic: "articles", Type: "comment"})
+	if _, ok := receive(t, fast); !ok {
+		t.Error("fast subscriber is dropped together with the slow one")
+	}
+}
+
+func TestPubSub_Close(t *testing.T) {
+	p := New(nil)
+
+	sub, _ := p.Subscribe("articles")
+	closed, _ := p.Subscribe("articles")
+	closed.Close()
+	closed.Close()
+
+	p.Close()
+	p.Close()
+
+	if _, ok := receive(t, sub); ok {
+		t.Fatal("subscription is open after Close")
+	}
+	if !errors.Is(sub.Err(), ErrClosed) {
+		t.Errorf("Err() = %v, want %v", sub.Err(), ErrClosed)
+	}
+	// closed by the subscriber before, the reason stays empty
+	if closed.Err() != nil {
+		t.Errorf("Err() of closed subscription = %v, want nil", closed.Err())
+	}
+	if _, err := p.Subscribe("articles"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe after Close = %v, want %v", err, ErrClosed)
+	}
+}
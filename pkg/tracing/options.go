@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type Option func(*Tracing)
+
+// Endpoint - host:port OTLP/HTTP коллектора, переменные OTEL_EXPORTER_OTLP_* тоже учитываются
+func Endpoint(endpoint string) Option {
+	return func(t *Tracing) {
+		t.endpoint = endpoint
+	}
+}
+
+// Insecure - отправка в коллектор без TLS
+func Insecure(insecure bool) Option {
+	return func(t *Tracing) {
+		t.insecure = insecure
+	}
+}
+
+// SampleRatio - доля трассируемых запросов от 0 до 1 для запросов без решения вызывающей стороны
+func SampleRatio(ratio float64) Option {
+	return func(t *Tracing) {
+		t.sampleRatio = ratio
+	}
+}
+
+// Exporter - дополнительный экспортер, например tracetest.InMemoryExporter в тестах
+func Exporter(exporter sdktrace.SpanExporter) Option {
+	return func(t *Tracing) {
+		t.exporter = exporter
+	}
+}
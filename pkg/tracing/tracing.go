@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+const defaultSampleRatio = 1
+
+type Tracing struct {
+	endpoint    string
+	insecure    bool
+	sampleRatio float64
+	exporter    sdktrace.SpanExporter
+
+	provider *sdktrace.TracerProvider
+}
+
+// New - провайдер трассировки устанавливается глобальным, инструментированный код получает трейсер через otel.Tracer.
+// Без OTLP endpoint и без Exporter спаны создаются (trace id попадает в логи), но никуда не отправляются
+func New(serviceName, version string, opts ...Option) (*Tracing, error) {
+	t := &Tracing{
+		sampleRatio: defaultSampleRatio,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing - New - resource.Merge: %w", err)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		// the caller's sampling decision wins, so a trace is either complete or absent
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.sampleRatio))),
+	}
+
+	if t.endpoint != "" {
+		clientOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(t.endpoint)}
+		if t.insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(context.Background(), clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("tracing - New - otlptracehttp.New: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	// spans reach the exporter synchronously, tests can read them right after the call
+	if t.exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(t.exporter))
+	}
+
+	t.provider = sdktrace.NewTracerProvider(providerOpts...)
+
+	otel.SetTracerProvider(t.provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return t, nil
+}
+
+// Shutdown - отправка накопленных спанов, вызывается при остановке приложения
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
@@ -1,10 +1,10 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	log "github.com/sirupsen/logrus"
 	"reflect"
 	"regexp"
 	"strings"
@@ -27,9 +27,31 @@ var (
 	symbolRegexp    = regexp.MustCompile(fmt.Sprintf(`[!@_#$%%^&*]{%d,}`, passwordMinSymbol))
 )
 
+// names of request fields are taken from the first tag that is set
+var nameTags = []string{"json", "query", "param", "form"}
+
+// FieldError - ошибка одного поля запроса, code - правило валидации, которое не прошло
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError - все ошибки полей запроса
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
 type CustomValidator struct {
-	v         *validator.Validate
-	passwdErr error
+	v *validator.Validate
 }
 
 func NewCustomValidator() *CustomValidator {
@@ -37,11 +59,16 @@ func NewCustomValidator() *CustomValidator {
 	cv := &CustomValidator{v: v}
 
 	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
+		for _, tag := range nameTags {
+			name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
 		}
-		return name
+		return ""
 	})
 
 	err := v.RegisterValidation("password", cv.passwordValidate)
@@ -54,64 +81,95 @@ func NewCustomValidator() *CustomValidator {
 	return cv
 }
 
+// Validate - ошибки правил возвращаются как *ValidationError с сообщением для каждого поля
 func (cv *CustomValidator) Validate(i interface{}) error {
 	err := cv.v.Struct(i)
-	if err != nil {
-		// fieldErr := err.(validator.ValidationErrors)[0]
-		log.Debugf("err: %+v", err)
 
-		// return cv.newValidationError(fieldErr.Field(), fieldErr.Value(), fieldErr.Tag(), fieldErr.Param())
-		return err
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		validationErr := &ValidationError{Fields: make([]FieldError, 0, len(fieldErrors))}
+		for _, fieldErr := range fieldErrors {
+			validationErr.Fields = append(validationErr.Fields, newFieldError(fieldErr))
+		}
+		return validationErr
+	}
+
+	return err
+}
+
+func newFieldError(fieldErr validator.FieldError) FieldError {
+	// namespace without the name of the request struct
+	field := fieldErr.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	return FieldError{
+		Field:   field,
+		Code:    fieldErr.Tag(),
+		Message: fieldMessage(field, fieldErr),
 	}
-	return nil
 }
 
-// func (cv *CustomValidator) newValidationError(field string, value interface{}, tag string, param string) error {
-// 	switch tag {
-// 	case "required":
-// 		return fmt.Errorf("field %s is required", field)
-// 	case "email":
-// 		return fmt.Errorf("field %s must be a valid email address", field)
-// 	case "password":
-// 		return cv.passwdErr
-// 	case "min":
-// 		return fmt.Errorf("field %s must be at least %s characters", field, param)
-// 	case "max":
-// 		return fmt.Errorf("field %s must be at most %s characters", field, param)
-// 	default:
-// 		return fmt.Errorf("field %s is invalid", field)
-// 	}
-// }
+func fieldMessage(field string, fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("field %s is required", field)
+	case "email":
+		return fmt.Sprintf("field %s must be a valid email address", field)
+	case "url":
+		return fmt.Sprintf("field %s must be a valid URL", field)
+	case "ip":
+		return fmt.Sprintf("field %s must be a valid IP address", field)
+	case "oneof":
+		return fmt.Sprintf("field %s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
+	case "password":
+		value, _ := fieldErr.Value().(string)
+		return fmt.Sprintf("field %s %s", field, passwordError(value))
+	case "min", "max":
+		bound := "at least"
+		if fieldErr.Tag() == "max" {
+			bound = "at most"
+		}
+
+		switch fieldErr.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("field %s must be %s %s characters", field, bound, param)
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return fmt.Sprintf("field %s must contain %s %s items", field, bound, param)
+		}
+		return fmt.Sprintf("field %s must be %s %s", field, bound, param)
+	}
+
+	return fmt.Sprintf("field %s is invalid", field)
+}
 
 func (cv *CustomValidator) passwordValidate(fl validator.FieldLevel) bool {
 	// check if the field is a string
 	if fl.Field().Kind() != reflect.String {
-		cv.passwdErr = fmt.Errorf("field %s must be a string", fl.FieldName())
 		return false
 	}
 
-	// get the value of the field
-	fieldValue := fl.Field().String()
+	return passwordError(fl.Field().String()) == ""
+}
 
-	// check regexp matching
-	if ok := lengthRegexp.MatchString(fieldValue); !ok {
-		cv.passwdErr = fmt.Errorf("field %s must be between %d and %d characters", fl.FieldName(), passwordMinLength, passwordMaxLength)
-		return false
-	} else if ok = lowerCaseRegexp.MatchString(fieldValue); !ok {
-		cv.passwdErr = fmt.Errorf("field %s must contain at least %d lowercase letter(s)", fl.FieldName(), passwordMinLower)
-		return false
-	} else if ok = upperCaseRegexp.MatchString(fieldValue); !ok {
-		cv.passwdErr = fmt.Errorf("field %s must contain at least %d uppercase letter(s)", fl.FieldName(), passwordMinUpper)
-		return false
-	} else if ok = digitRegexp.MatchString(fieldValue); !ok {
-		cv.passwdErr = fmt.Errorf("field %s must contain at least %d digit(s)", fl.FieldName(), passwordMinDigit)
-		return false
-	} else if ok = symbolRegexp.MatchString(fieldValue); !ok {
-		cv.passwdErr = fmt.Errorf("field %s must contain at least %d special character(s)", fl.FieldName(), passwordMinSymbol)
-		return false
+// passwordError - первое нарушенное правило пароля, пустая строка - пароль подходит
+func passwordError(password string) string {
+	switch {
+	case !lengthRegexp.MatchString(password):
+		return fmt.Sprintf("must be between %d and %d characters", passwordMinLength, passwordMaxLength)
+	case !lowerCaseRegexp.MatchString(password):
+		return fmt.Sprintf("must contain at least %d lowercase letter(s)", passwordMinLower)
+	case !upperCaseRegexp.MatchString(password):
+		return fmt.Sprintf("must contain at least %d uppercase letter(s)", passwordMinUpper)
+	case !digitRegexp.MatchString(password):
+		return fmt.Sprintf("must contain at least %d digit(s)", passwordMinDigit)
+	case !symbolRegexp.MatchString(password):
+		return fmt.Sprintf("must contain at least %d special character(s)", passwordMinSymbol)
 	}
-
-	return true
+	return ""
 }
 
 func validateUUID(field reflect.Value) interface{} {